@@ -0,0 +1,214 @@
+package authctl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/adrianolaselva/dataql/pkg/connections"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	urlParam   = "url"
+	userParam  = "user"
+	forceParam = "force"
+)
+
+// AuthCtl is the interface for the auth controller
+type AuthCtl interface {
+	Command() *cobra.Command
+}
+
+type authCtl struct{}
+
+// New creates a new AuthCtl instance
+func New() AuthCtl {
+	return &authCtl{}
+}
+
+// Command returns the cobra command for the auth subcommand
+func (c *authCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "auth",
+		Short: "Store database and message queue credentials in the OS keyring",
+		Long: `Save named connections in the OS keyring (macOS Keychain, Secret Service on
+Linux, Windows Credential Manager), then read them as <name>://<table>.
+
+The credentials are neither passed on the command line nor written to a
+configuration file: sources are expanded to the URL of the connection, and
+the user and password are read from the keyring when dataql connects.`,
+		Example: `  dataql auth add mydb --url postgres://db.internal:5432/shop --user reader
+  dataql run -f mydb://orders -q "SELECT COUNT(*) FROM orders"
+  dataql auth list
+  dataql auth remove mydb`,
+	}
+
+	command.AddCommand(c.addCommand())
+	command.AddCommand(c.listCommand())
+	command.AddCommand(c.removeCommand())
+
+	return command
+}
+
+func (c *authCtl) addCommand() *cobra.Command {
+	var rawURL, user string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Save a connection",
+		Long: `Save a connection as <name>. The URL, without credentials, and the user are
+prompted for unless given as flags; the password is always prompted for, or
+read from the first line of standard input when it is not a terminal.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			name := args[0]
+			if err := connections.ValidateName(name); err != nil {
+				return err
+			}
+			if _, err := connections.Load(name); err == nil && !force {
+				return fmt.Errorf("connection %s already exists (use --%s to replace it)", name, forceParam)
+			} else if err != nil && !errors.Is(err, connections.ErrNotFound) {
+				return err
+			}
+
+			p := newPrompter(cmd.InOrStdin(), cmd.ErrOrStderr())
+			var err error
+			if rawURL == "" {
+				if rawURL, err = p.line("URL (without credentials): "); err != nil {
+					return err
+				}
+			}
+			u, err := connections.ValidateURL(rawURL)
+			if err != nil {
+				return err
+			}
+			if user == "" && u.User != nil {
+				user = u.User.Username()
+			}
+			u.User = nil
+			if user == "" {
+				if user, err = p.line("User: "); err != nil {
+					return err
+				}
+			}
+			password, err := p.password("Password: ")
+			if err != nil {
+				return err
+			}
+
+			if err := connections.Save(connections.Connection{Name: name, URL: u.String(), User: user, Password: password}); err != nil {
+				return err
+			}
+			fmt.Printf("Saved connection %s: read its tables as %s://<table>\n", name, name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&rawURL, urlParam, "", "connection URL without credentials, e.g. postgres://host:5432/database")
+	cmd.Flags().StringVar(&user, userParam, "", "user name")
+	cmd.Flags().BoolVar(&force, forceParam, false, "replace a saved connection")
+
+	return cmd
+}
+
+func (c *authCtl) listCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the saved connections",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			names, err := connections.List()
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				fmt.Println("No saved connections")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tURL\tUSER")
+			for _, name := range names {
+				conn, err := connections.Load(name)
+				if err != nil {
+					fmt.Fprintf(w, "%s\t(%v)\t\n", name, err)
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", name, conn.URL, conn.User)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func (c *authCtl) removeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a saved connection",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			if err := connections.Remove(args[0]); err != nil {
+				if errors.Is(err, connections.ErrNotFound) {
+					return fmt.Errorf("connection %s not found", args[0])
+				}
+				return err
+			}
+			fmt.Printf("Removed connection %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+// prompter reads answers from a terminal, or lines of piped input
+type prompter struct {
+	in     io.Reader
+	reader *bufio.Reader
+	out    io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: in, reader: bufio.NewReader(in), out: out}
+}
+
+// line prompts for a value
+func (p *prompter) line(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	line, err := p.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read %s", strings.TrimSuffix(strings.ToLower(prompt), ": "))
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// password prompts for a value without echoing it on a terminal
+func (p *prompter) password(prompt string) (string, error) {
+	file, ok := p.in.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		line, err := p.reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(p.out, prompt)
+	password, err := term.ReadPassword(int(file.Fd()))
+	fmt.Fprintln(p.out)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(password), nil
+}
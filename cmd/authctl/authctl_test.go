@@ -0,0 +1,75 @@
+package authctl
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/connections"
+	"github.com/adrianolaselva/dataql/pkg/keyring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runAuth(t *testing.T, input string, args ...string) (string, error) {
+	t.Helper()
+
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	cmd := New().Command()
+	cmd.SetArgs(args)
+	cmd.SetIn(strings.NewReader(input))
+	cmd.SetErr(io.Discard)
+	cmd.SilenceErrors = true
+	runErr := cmd.Execute()
+
+	require.NoError(t, writer.Close())
+	var out bytes.Buffer
+	_, err = io.Copy(&out, reader)
+	require.NoError(t, err)
+	return out.String(), runErr
+}
+
+func TestAddListRemove(t *testing.T) {
+	keyring.MockInit()
+
+	out, err := runAuth(t, "postgres://reader@db:5432/shop\nsecret\n", "add", "mydb")
+	require.NoError(t, err)
+	assert.Contains(t, out, "mydb://<table>")
+
+	conn, err := connections.Load("mydb")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://db:5432/shop", conn.URL, "the user is stored apart from the URL")
+	assert.Equal(t, "reader", conn.User)
+	assert.Equal(t, "secret", conn.Password)
+
+	_, err = runAuth(t, "other\n", "add", "mydb", "--url", "mysql://db:3306/shop", "--user", "app")
+	assert.ErrorContains(t, err, "already exists")
+	_, err = runAuth(t, "other\n", "add", "mydb", "--url", "mysql://db:3306/shop", "--user", "app", "--force")
+	require.NoError(t, err)
+
+	out, err = runAuth(t, "", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "mysql://db:3306/shop")
+	assert.NotContains(t, out, "other", "passwords are not listed")
+
+	_, err = runAuth(t, "", "remove", "mydb")
+	require.NoError(t, err)
+	_, err = runAuth(t, "", "remove", "mydb")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestAddRejectsInvalidInput(t *testing.T) {
+	keyring.MockInit()
+
+	_, err := runAuth(t, "pw\n", "add", "postgres", "--url", "postgres://db/shop", "--user", "app")
+	assert.Error(t, err)
+	_, err = runAuth(t, "pw\n", "add", "mydb", "--url", "postgres://app:pw@db/shop")
+	assert.ErrorContains(t, err, "must not hold the password")
+}
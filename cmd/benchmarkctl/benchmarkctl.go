@@ -0,0 +1,271 @@
+package benchmarkctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	fileParam               = "file"
+	fileShortParam          = "f"
+	queryParam              = "query"
+	queryShortParam         = "q"
+	runsParam               = "runs"
+	runsShortParam          = "n"
+	warmupParam             = "warmup"
+	setParam                = "set"
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	linesParam              = "lines"
+	linesShortParam         = "l"
+	tableNameParam          = "collection"
+	tableNameShortParam     = "c"
+	formatParam             = "format"
+	baselineParam           = "baseline"
+	maxRegressionParam      = "max-regression"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// BenchmarkCtl is the interface for the benchmark controller
+type BenchmarkCtl interface {
+	Command() *cobra.Command
+}
+
+type benchmarkCtl struct {
+	files         []string
+	queries       []string
+	runs          int
+	warmup        int
+	settings      []string
+	delimiter     string
+	lines         int
+	collection    string
+	format        string
+	baseline      string
+	maxRegression float64
+}
+
+// New creates a new BenchmarkCtl instance
+func New() BenchmarkCtl {
+	return &benchmarkCtl{}
+}
+
+// Command returns the cobra command for the benchmark subcommand
+func (c *benchmarkCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "benchmark",
+		Short: "Measure import and query times",
+		Long: `Import sources and run queries several times and report the min, median and
+95th percentile times of the import, each query and the whole run, with the
+memory used, to measure performance regressions and tune settings.
+
+Each --file is benchmarked separately, e.g. the same data as CSV, JSONL and
+Parquet to compare handlers. --set name=value1,value2 applies DuckDB settings
+before the import; several --set flags benchmark every combination.
+
+Memory is the median, per run, of the bytes allocated by Go (parsing and
+import) and of the memory DuckDB holds after the queries.
+
+--format json writes the results as JSON; pass such a file to --baseline to
+fail when a median is slower than the baseline by more than --max-regression
+percent.`,
+		Example: `  dataql benchmark -f sales.csv -q "SELECT region, SUM(amount) FROM sales GROUP BY region"
+  dataql benchmark -f sales.csv -f sales.parquet -q "SELECT COUNT(*) FROM sales" -n 10
+  dataql benchmark -f big.csv --set threads=1,4,8 --set memory_limit=1GB,4GB -q "SELECT ..."
+  dataql benchmark -f sales.csv -q "SELECT ..." --format json > baseline.json
+  dataql benchmark -f sales.csv -q "SELECT ..." --baseline baseline.json --max-regression 20`,
+		Args: cobra.NoArgs,
+		RunE: c.runE,
+	}
+
+	command.Flags().StringArrayVarP(&c.files, fileParam, fileShortParam, []string{}, "source to benchmark; repeat to compare sources or formats")
+	command.Flags().StringArrayVarP(&c.queries, queryParam, queryShortParam, []string{}, "query to time after the import; repeatable")
+	command.Flags().IntVarP(&c.runs, runsParam, runsShortParam, 5, "measured runs per source and settings")
+	command.Flags().IntVar(&c.warmup, warmupParam, 1, "runs before the measured ones, not reported")
+	command.Flags().StringArrayVar(&c.settings, setParam, []string{}, "DuckDB setting as name=value1,value2 (e.g. threads=1,4); repeatable")
+	command.Flags().StringVarP(&c.delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter")
+	command.Flags().IntVarP(&c.lines, linesParam, linesShortParam, 0, "number of lines to be read")
+	command.Flags().StringVarP(&c.collection, tableNameParam, tableNameShortParam, "", "table name of every source (e.g. to run the same queries on sources with different names)")
+	command.Flags().StringVar(&c.format, formatParam, formatTable, "output format: table or json")
+	command.Flags().StringVar(&c.baseline, baselineParam, "", "JSON results of a previous run to compare medians with")
+	command.Flags().Float64Var(&c.maxRegression, maxRegressionParam, 10, "percent a median may exceed the baseline before failing")
+	_ = command.MarkFlagRequired(fileParam)
+
+	return command
+}
+
+func (c *benchmarkCtl) runE(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+
+	if c.runs < 1 {
+		return fmt.Errorf("--%s must be at least 1", runsParam)
+	}
+	if c.warmup < 0 {
+		return fmt.Errorf("--%s must not be negative", warmupParam)
+	}
+	if c.format != formatTable && c.format != formatJSON {
+		return fmt.Errorf("invalid --%s %q (expected %s or %s)", formatParam, c.format, formatTable, formatJSON)
+	}
+	combinations, err := expandSettings(c.settings)
+	if err != nil {
+		return err
+	}
+
+	var baseline []caseResult
+	if c.baseline != "" {
+		if baseline, err = loadResults(c.baseline); err != nil {
+			return err
+		}
+	}
+
+	r := &runner{
+		queries: c.queries,
+		runs:    c.runs,
+		warmup:  c.warmup,
+		opts:    dataql.Options{Delimiter: c.delimiter, Lines: c.lines, Collection: c.collection},
+	}
+
+	var results []caseResult
+	for _, file := range c.files {
+		for _, settings := range combinations {
+			bc := benchCase{source: file, settings: settings}
+			if c.format == formatTable {
+				fmt.Fprintf(os.Stderr, "Benchmarking %s...\n", describeCase(bc.source, bc.settings))
+			}
+			result, err := r.run(cmd.Context(), bc)
+			if err != nil {
+				return fmt.Errorf("%s: %w", describeCase(bc.source, bc.settings), err)
+			}
+			results = append(results, result)
+		}
+	}
+
+	if c.format == formatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		printResults(os.Stdout, results)
+	}
+
+	if baseline == nil {
+		return nil
+	}
+	regressions := compare(baseline, results, c.maxRegression)
+	if c.format == formatTable {
+		fmt.Println()
+	}
+	if len(regressions) == 0 {
+		fmt.Fprintf(os.Stderr, "No regression above %g%% against %s\n", c.maxRegression, c.baseline)
+		return nil
+	}
+	for _, regression := range regressions {
+		fmt.Fprintf(os.Stderr, "%s %s\n", color.RedString("Regression:"), regression)
+	}
+	return fmt.Errorf("%d phase(s) slower than %s by more than %g%%", len(regressions), c.baseline, c.maxRegression)
+}
+
+// printResults prints one row per phase of each case
+func printResults(w io.Writer, results []caseResult) {
+	tbl := table.New("Source", "Settings", "Phase", "Min", "Median", "P95", "Go alloc", "DuckDB memory").
+		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+		WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+		WithWriter(w)
+
+	for _, result := range results {
+		for i, phase := range result.Phases {
+			source, settings, goAlloc, memory := "", "", "", ""
+			if i == 0 {
+				source, settings = result.Source, strings.Join(result.Settings, " ")
+			}
+			if phase.Name == phaseTotal {
+				goAlloc, memory = formatBytes(int64(result.GoAllocBytes)), formatBytes(result.DuckDBMemoryBytes)
+			}
+			tbl.AddRow(source, settings, phase.Name, formatMs(phase.MinMs), formatMs(phase.MedianMs), formatMs(phase.P95Ms), goAlloc, memory)
+		}
+	}
+	tbl.Print()
+}
+
+// loadResults reads the JSON results of a previous benchmark
+func loadResults(path string) ([]caseResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var results []caseResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	return results, nil
+}
+
+// compare lists the phases whose median exceeds the baseline median by more
+// than maxRegression percent. Phases missing from the baseline are skipped.
+func compare(baseline, results []caseResult, maxRegression float64) []string {
+	previous := make(map[string]float64)
+	for _, result := range baseline {
+		for _, phase := range result.Phases {
+			previous[phaseKey(result, phase)] = phase.MedianMs
+		}
+	}
+
+	var regressions []string
+	for _, result := range results {
+		for _, phase := range result.Phases {
+			before, ok := previous[phaseKey(result, phase)]
+			if !ok || before <= 0 {
+				continue
+			}
+			if change := (phase.MedianMs - before) / before * 100; change > maxRegression {
+				regressions = append(regressions, fmt.Sprintf("%s %s: median %s, baseline %s (+%.1f%%)",
+					describeCase(result.Source, result.Settings), phase.Name, formatMs(phase.MedianMs), formatMs(before), change))
+			}
+		}
+	}
+	return regressions
+}
+
+func phaseKey(result caseResult, phase phaseResult) string {
+	return result.Source + "\x00" + strings.Join(result.Settings, " ") + "\x00" + phase.Name
+}
+
+func describeCase(source string, settings []string) string {
+	if len(settings) == 0 {
+		return source
+	}
+	return source + " (" + strings.Join(settings, " ") + ")"
+}
+
+func formatMs(ms float64) string {
+	return time.Duration(ms * float64(time.Millisecond)).Round(10 * time.Microsecond).String()
+}
+
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,114 @@
+package benchmarkctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runBenchmark(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	cmd := New().Command()
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	runErr := cmd.Execute()
+
+	require.NoError(t, writer.Close())
+	var out bytes.Buffer
+	_, err = io.Copy(&out, reader)
+	require.NoError(t, err)
+	return out.String(), runErr
+}
+
+func TestBenchmark_JSON(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "sales.csv")
+	require.NoError(t, os.WriteFile(input, []byte("region,amount\nnorth,10\nsouth,20\n"), 0644))
+
+	out, err := runBenchmark(t, "-f", input, "-q", "SELECT SUM(amount) FROM sales", "-n", "2", "--warmup", "0",
+		"--set", "threads=1,2", "--format", "json")
+	require.NoError(t, err)
+
+	var results []caseResult
+	require.NoError(t, json.Unmarshal([]byte(out), &results))
+	require.Len(t, results, 2)
+	assert.Equal(t, []string{"threads=1"}, results[0].Settings)
+	assert.Equal(t, []string{"threads=2"}, results[1].Settings)
+	for _, result := range results {
+		assert.Equal(t, 2, result.Runs)
+		require.Len(t, result.Phases, 3)
+		assert.Equal(t, []string{phaseImport, "query 1", phaseTotal}, []string{result.Phases[0].Name, result.Phases[1].Name, result.Phases[2].Name})
+		assert.Equal(t, "SELECT SUM(amount) FROM sales", result.Phases[1].Query)
+		assert.Greater(t, result.Phases[2].MedianMs, 0.0)
+		assert.LessOrEqual(t, result.Phases[2].MinMs, result.Phases[2].P95Ms)
+	}
+
+	baseline := filepath.Join(t.TempDir(), "baseline.json")
+	for i := range results {
+		for j := range results[i].Phases {
+			results[i].Phases[j].MedianMs = 0.001
+		}
+	}
+	data, err := json.Marshal(results)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(baseline, data, 0644))
+
+	_, err = runBenchmark(t, "-f", input, "-n", "1", "--set", "threads=1", "--baseline", baseline)
+	assert.ErrorContains(t, err, "slower than")
+}
+
+func TestBenchmark_Errors(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "sales.csv")
+	require.NoError(t, os.WriteFile(input, []byte("region,amount\nnorth,10\n"), 0644))
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no file", []string{"-q", "SELECT 1"}},
+		{"no runs", []string{"-f", input, "-n", "0"}},
+		{"invalid format", []string{"-f", input, "--format", "xml"}},
+		{"invalid setting", []string{"-f", input, "--set", "threads"}},
+		{"unknown setting", []string{"-f", input, "--set", "no_such_setting=1"}},
+		{"failing query", []string{"-f", input, "-q", "SELECT * FROM missing"}},
+		{"missing baseline", []string{"-f", input, "--baseline", filepath.Join(t.TempDir(), "none.json")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runBenchmark(t, tt.args...)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExpandSettings(t *testing.T) {
+	combinations, err := expandSettings([]string{"threads=1,4", "memory_limit=1GB"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"threads=1", "memory_limit=1GB"}, {"threads=4", "memory_limit=1GB"}}, combinations)
+
+	combinations, err = expandSettings(nil)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{nil}, combinations)
+}
+
+func TestStatistics(t *testing.T) {
+	durations := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	assert.Equal(t, time.Duration(10), median(durations[:19]))
+	assert.Equal(t, time.Duration(10), median(durations[:20])) // (10 + 11) / 2 truncated
+	assert.Equal(t, time.Duration(19), percentile(durations, 95))
+	assert.Equal(t, time.Duration(1), percentile(durations[:1], 95))
+}
@@ -0,0 +1,226 @@
+package benchmarkctl
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+)
+
+// Phase names of a run besides its queries
+const (
+	phaseImport = "import"
+	phaseTotal  = "total"
+)
+
+// benchCase is one source benchmarked with one combination of settings
+type benchCase struct {
+	source   string
+	settings []string // name=value
+}
+
+// caseResult holds the timings of a case over its measured runs
+type caseResult struct {
+	Source            string        `json:"source"`
+	Settings          []string      `json:"settings,omitempty"`
+	Runs              int           `json:"runs"`
+	Phases            []phaseResult `json:"phases"`
+	GoAllocBytes      uint64        `json:"go_alloc_bytes"`      // Median bytes allocated by Go per run
+	DuckDBMemoryBytes int64         `json:"duckdb_memory_bytes"` // Median DuckDB memory in use after the queries
+}
+
+// phaseResult holds the statistics of one phase
+type phaseResult struct {
+	Name     string  `json:"name"`
+	Query    string  `json:"query,omitempty"`
+	MinMs    float64 `json:"min_ms"`
+	MedianMs float64 `json:"median_ms"`
+	P95Ms    float64 `json:"p95_ms"`
+}
+
+// runMeasure holds what one run measured
+type runMeasure struct {
+	durations []time.Duration // import, each query, total
+	goAlloc   uint64
+	duckdbMem int64
+}
+
+// runner runs the benchmark cases
+type runner struct {
+	queries []string
+	runs    int
+	warmup  int
+	opts    dataql.Options
+}
+
+// run measures a case: warmup runs are discarded
+func (r *runner) run(ctx context.Context, c benchCase) (caseResult, error) {
+	measures := make([]runMeasure, 0, r.runs)
+	for i := 0; i < r.warmup+r.runs; i++ {
+		measure, err := r.once(ctx, c)
+		if err != nil {
+			return caseResult{}, err
+		}
+		if i >= r.warmup {
+			measures = append(measures, measure)
+		}
+	}
+	return r.summarize(c, measures), nil
+}
+
+// once imports the source, runs every query and closes the database
+func (r *runner) once(ctx context.Context, c benchCase) (runMeasure, error) {
+	opts := r.opts
+	opts.Settings = make(map[string]string, len(c.settings))
+	for _, setting := range c.settings {
+		name, value, _ := strings.Cut(setting, "=")
+		opts.Settings[name] = value
+	}
+
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	started := time.Now()
+	db, err := dataql.OpenWithOptions(opts, c.source)
+	if err != nil {
+		return runMeasure{}, err
+	}
+	defer db.Close()
+
+	measure := runMeasure{durations: []time.Duration{time.Since(started)}}
+	for _, query := range r.queries {
+		queryStarted := time.Now()
+		if err := drain(ctx, db, query); err != nil {
+			return runMeasure{}, fmt.Errorf("query %q failed: %w", query, err)
+		}
+		measure.durations = append(measure.durations, time.Since(queryStarted))
+	}
+	measure.durations = append(measure.durations, time.Since(started))
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	measure.goAlloc = after.TotalAlloc - before.TotalAlloc
+	measure.duckdbMem = duckdbMemory(ctx, db)
+	return measure, nil
+}
+
+// summarize computes the statistics of each phase over the runs
+func (r *runner) summarize(c benchCase, measures []runMeasure) caseResult {
+	result := caseResult{Source: c.source, Settings: c.settings, Runs: len(measures)}
+
+	names := []string{phaseImport}
+	for i := range r.queries {
+		names = append(names, fmt.Sprintf("query %d", i+1))
+	}
+	names = append(names, phaseTotal)
+
+	for phase, name := range names {
+		durations := make([]time.Duration, len(measures))
+		for i, measure := range measures {
+			durations[i] = measure.durations[phase]
+		}
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+		stats := phaseResult{
+			Name:     name,
+			MinMs:    milliseconds(durations[0]),
+			MedianMs: milliseconds(median(durations)),
+			P95Ms:    milliseconds(percentile(durations, 95)),
+		}
+		if phase > 0 && phase <= len(r.queries) {
+			stats.Query = r.queries[phase-1]
+		}
+		result.Phases = append(result.Phases, stats)
+	}
+
+	goAllocs := make([]uint64, len(measures))
+	memories := make([]int64, len(measures))
+	for i, measure := range measures {
+		goAllocs[i], memories[i] = measure.goAlloc, measure.duckdbMem
+	}
+	sort.Slice(goAllocs, func(i, j int) bool { return goAllocs[i] < goAllocs[j] })
+	sort.Slice(memories, func(i, j int) bool { return memories[i] < memories[j] })
+	result.GoAllocBytes = goAllocs[len(goAllocs)/2]
+	result.DuckDBMemoryBytes = memories[len(memories)/2]
+	return result
+}
+
+// drain runs query and reads all its rows
+func drain(ctx context.Context, db *dataql.DB, query string) error {
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if _, err := rows.Values(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// duckdbMemory returns the memory DuckDB holds, or 0 when it is unknown
+func duckdbMemory(ctx context.Context, db *dataql.DB) int64 {
+	rows, err := db.Query(ctx, "SELECT CAST(COALESCE(SUM(memory_usage_bytes), 0) AS BIGINT) FROM duckdb_memory()")
+	if err != nil {
+		return 0
+	}
+	defer rows.Close()
+
+	var bytes int64
+	if rows.Next() {
+		_ = rows.Scan(&bytes)
+	}
+	return bytes
+}
+
+// expandSettings returns every combination of the values of --set flags
+// written as name=value1,value2
+func expandSettings(flags []string) ([][]string, error) {
+	combinations := [][]string{nil}
+	for _, flag := range flags {
+		name, values, ok := strings.Cut(flag, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.TrimSpace(values) == "" {
+			return nil, fmt.Errorf("invalid setting %q (expected name=value[,value...], e.g. threads=1,4,8)", flag)
+		}
+
+		var next [][]string
+		for _, combination := range combinations {
+			for _, value := range strings.Split(values, ",") {
+				setting := name + "=" + strings.TrimSpace(value)
+				next = append(next, append(append([]string(nil), combination...), setting))
+			}
+		}
+		combinations = next
+	}
+	return combinations, nil
+}
+
+// median returns the middle of sorted durations (the mean of the two middle
+// values for an even count)
+func median(sorted []time.Duration) time.Duration {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(0, min(len(sorted)-1, rank-1))]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return math.Round(float64(d.Microseconds())) / 1000
+}
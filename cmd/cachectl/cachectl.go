@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/adrianolaselva/dataql/pkg/cachehandler"
 	"github.com/fatih/color"
@@ -14,6 +15,8 @@ import (
 const (
 	cacheDirParam      = "cache-dir"
 	cacheDirShortParam = "d"
+	ttlParam           = "ttl"
+	maxSizeParam       = "max-size"
 )
 
 // CacheCtl is the interface for the cache controller
@@ -38,7 +41,9 @@ func (c *cacheCtl) Command() *cobra.Command {
 		Long: `Manage the data cache for faster query execution.
 
 The cache stores imported data in DuckDB format, allowing subsequent
-queries on the same files to skip the import step.`,
+queries on the same files to skip the import step. Remote sources (HTTP,
+S3, GCS, Azure) are kept too, and downloaded again only when their ETag or
+version changes.`,
 	}
 
 	// Add cache-dir flag to root command
@@ -48,6 +53,7 @@ queries on the same files to skip the import step.`,
 	command.AddCommand(c.listCommand())
 	command.AddCommand(c.clearCommand())
 	command.AddCommand(c.statsCommand())
+	command.AddCommand(c.pruneCommand())
 
 	return command
 }
@@ -73,7 +79,7 @@ func (c *cacheCtl) listCommand() *cobra.Command {
 			}
 
 			// Create table
-			tbl := table.New("Key", "Files", "Tables", "Rows", "Size", "Cached At").
+			tbl := table.New("Key", "Files", "Tables", "Rows", "Size", "Cached At", "Last Used").
 				WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
 				WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
 				WithWriter(os.Stdout)
@@ -97,6 +103,7 @@ func (c *cacheCtl) listCommand() *cobra.Command {
 					entry.TotalRows,
 					cachehandler.FormatSize(entry.SizeBytes),
 					entry.CachedAt.Format("2006-01-02 15:04:05"),
+					entry.LastUsedAt.Format("2006-01-02 15:04:05"),
 				)
 			}
 
@@ -186,11 +193,81 @@ func (c *cacheCtl) statsCommand() *cobra.Command {
 				return fmt.Errorf("failed to get cache stats: %w", err)
 			}
 
+			downloads, err := handler.Downloads().List()
+			if err != nil {
+				return fmt.Errorf("failed to list cached downloads: %w", err)
+			}
+			var downloadSize int64
+			for _, d := range downloads {
+				downloadSize += d.Size
+			}
+
 			fmt.Printf("Cache directory: %s\n", handler.GetCacheDir())
 			fmt.Printf("Cached entries: %d\n", count)
 			fmt.Printf("Total size: %s\n", cachehandler.FormatSize(size))
+			fmt.Printf("Cached downloads: %d (%s)\n", len(downloads), cachehandler.FormatSize(downloadSize))
 
 			return nil
 		},
 	}
 }
+
+func (c *cacheCtl) pruneCommand() *cobra.Command {
+	var ttl, maxSize string
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove expired and least recently used cache entries",
+		Long: `Remove cache entries older than --ttl, then the least recently used entries
+until the cache fits in --max-size. Downloads of remote sources unused for
+longer than --ttl, and files left behind by interrupted imports, are removed
+too.
+
+The limits default to $` + cachehandler.EnvTTL + ` and $` + cachehandler.EnvMaxSize + `, the same
+variables read by run --cache.`,
+		Example: `  dataql cache prune --ttl 7d
+  dataql cache prune --max-size 10GB`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			handler, err := cachehandler.NewCacheHandler(c.cacheDir, true)
+			if err != nil {
+				return fmt.Errorf("failed to initialize cache handler: %w", err)
+			}
+
+			if ttl == "" {
+				ttl = os.Getenv(cachehandler.EnvTTL)
+			}
+			if maxSize == "" {
+				maxSize = os.Getenv(cachehandler.EnvMaxSize)
+			}
+
+			var ttlValue time.Duration
+			if ttl != "" {
+				if ttlValue, err = cachehandler.ParseTTL(ttl); err != nil {
+					return err
+				}
+			}
+			var maxSizeValue int64
+			if maxSize != "" {
+				if maxSizeValue, err = cachehandler.ParseSize(maxSize); err != nil {
+					return err
+				}
+			}
+			handler.SetLimits(ttlValue, maxSizeValue)
+
+			result, err := handler.Prune()
+			if err != nil {
+				return fmt.Errorf("failed to prune cache: %w", err)
+			}
+
+			fmt.Printf("Pruned %d cache entries (%d expired, %d evicted, %d orphaned files), freed %s\n",
+				result.Removed(), result.Expired, result.Evicted, result.Orphans, cachehandler.FormatSize(result.FreedBytes))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&ttl, ttlParam, "", "remove entries older than this (e.g. 24h, 7d)")
+	cmd.Flags().StringVar(&maxSize, maxSizeParam, "", "evict least recently used entries above this size (e.g. 10GB)")
+
+	return cmd
+}
@@ -0,0 +1,89 @@
+package convertctl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/spf13/cobra"
+)
+
+const (
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	inputFormatParam        = "input-format"
+	inputFormatShortParam   = "i"
+	typeParam               = "type"
+	typeShortParam          = "t"
+	tableParam              = "table"
+)
+
+// ConvertCtl is the interface for the convert controller
+type ConvertCtl interface {
+	Command() *cobra.Command
+}
+
+type convertCtl struct {
+	delimiter   string
+	inputFormat string
+	outputType  string
+	table       string
+}
+
+// New creates a new ConvertCtl instance
+func New() ConvertCtl {
+	return &convertCtl{}
+}
+
+// Command returns the cobra command for the convert subcommand
+func (c *convertCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "convert <input> <output>",
+		Short: "Convert a file to another format",
+		Long: `Convert a data source to another format with all its columns and rows, without
+writing a query. The input can be any supported source (files, "-" for stdin,
+URLs, object storage, databases) and the output format is taken from the
+output file extension unless --type is given.
+
+Rows are streamed from the imported table to the output file.`,
+		Example: `  dataql convert report.xlsx report.parquet
+  dataql convert data.csv data.jsonl -d ';'
+  cat data.json | dataql convert - data.csv -i json
+  dataql convert export.dat export.csv -t csv`,
+		Args: cobra.ExactArgs(2),
+		RunE: c.runE,
+	}
+
+	command.Flags().StringVarP(&c.delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter of the input")
+	command.Flags().StringVarP(&c.inputFormat, inputFormatParam, inputFormatShortParam, "csv", "format of data read from stdin")
+	command.Flags().StringVarP(&c.outputType, typeParam, typeShortParam, "", "output format (default: from the output file extension)")
+	command.Flags().StringVar(&c.table, tableParam, "", "table to convert when the input holds several (e.g. a database)")
+
+	return command
+}
+
+func (c *convertCtl) runE(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	input, output := args[0], args[1]
+
+	db, err := dataql.OpenWithOptions(dataql.Options{Delimiter: c.delimiter, InputFormat: c.inputFormat}, input)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	source, err := db.SourceTable(c.table)
+	if err != nil {
+		return err
+	}
+
+	query := `SELECT * FROM "` + strings.ReplaceAll(source.Name, `"`, `""`) + `"`
+	rows, err := db.Export(cmd.Context(), query, output, c.outputType)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Converted %s to %s (%d rows)\n", input, output, rows)
+	return nil
+}
@@ -0,0 +1,55 @@
+package convertctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "people.csv")
+	require.NoError(t, os.WriteFile(input, []byte("id;name\n1;Ann\n2;Bob\n"), 0644))
+
+	tests := []struct {
+		name     string
+		output   string
+		args     []string
+		expected string
+	}{
+		{"format from extension", "people.jsonl", nil, "{\"id\":1,\"name\":\"Ann\"}\n{\"id\":2,\"name\":\"Bob\"}\n"},
+		{"explicit type", "people.out", []string{"-t", "csv"}, "id,name\n1,Ann\n2,Bob\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(dir, tt.output)
+			cmd := New().Command()
+			cmd.SetArgs(append([]string{input, output, "-d", ";"}, tt.args...))
+			require.NoError(t, cmd.Execute())
+
+			data, err := os.ReadFile(output)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestConvert_Errors(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "people.csv")
+	require.NoError(t, os.WriteFile(input, []byte("id\n1\n"), 0644))
+
+	cmd := New().Command()
+	cmd.SetArgs([]string{input, filepath.Join(dir, "people")})
+	cmd.SilenceErrors = true
+	assert.ErrorContains(t, cmd.Execute(), "export format")
+
+	cmd = New().Command()
+	cmd.SetArgs([]string{input, filepath.Join(dir, "out.csv"), "--table", "missing"})
+	cmd.SilenceErrors = true
+	assert.ErrorContains(t, cmd.Execute(), `table "missing" not found (available: people)`)
+}
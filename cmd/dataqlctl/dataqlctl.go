@@ -2,8 +2,26 @@ package dataqlctl
 
 import (
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/adrianolaselva/dataql/internal/dataql"
+	"github.com/adrianolaselva/dataql/internal/exportdata"
+	"github.com/adrianolaselva/dataql/pkg/auditlog"
+	"github.com/adrianolaselva/dataql/pkg/cachehandler"
+	"github.com/adrianolaselva/dataql/pkg/clierror"
+	"github.com/adrianolaselva/dataql/pkg/encryption"
+	"github.com/adrianolaselva/dataql/pkg/extensions"
+	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/gcshandler"
+	"github.com/adrianolaselva/dataql/pkg/history"
+	"github.com/adrianolaselva/dataql/pkg/mask"
+	"github.com/adrianolaselva/dataql/pkg/rangedownload"
+	"github.com/adrianolaselva/dataql/pkg/s3handler"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/adrianolaselva/dataql/pkg/timezone"
+	"github.com/adrianolaselva/dataql/pkg/tlsconfig"
+	"github.com/adrianolaselva/dataql/pkg/urlhandler"
 	"github.com/spf13/cobra"
 )
 
@@ -24,6 +42,7 @@ const (
 	linesShortParam         = "l"
 	tableNameParam          = "collection"
 	tableNameShortParam     = "c"
+	aliasParam              = "alias"
 	verboseParam            = "verbose"
 	verboseShortParam       = "v"
 	inputFormatParam        = "input-format"
@@ -31,6 +50,8 @@ const (
 	quietParam              = "quiet"
 	quietShortParam         = "Q"
 	noSchemaParam           = "no-schema"
+	plainParam              = "plain"
+	modeParam               = "mode"
 	truncateParam           = "truncate"
 	truncateShortParam      = "T"
 	verticalParam           = "vertical"
@@ -39,6 +60,54 @@ const (
 	paramShortParam         = "p"
 	cacheParam              = "cache"
 	cacheDirParam           = "cache-dir"
+	cacheTTLParam           = "cache-ttl"
+	cacheMaxSizeParam       = "cache-max-size"
+	downloadPartsParam      = "download-parts"
+	downloadPartSizeParam   = "download-part-size"
+	downloadTimeoutParam    = "download-timeout"
+	headerParam             = "header"
+	cookieJarParam          = "cookie-jar"
+	caCertParam             = "ca-cert"
+	insecureParam           = "insecure-skip-verify"
+	s3EndpointParam         = "s3-endpoint"
+	s3ProfileParam          = "s3-profile"
+	s3AssumeRoleParam       = "s3-assume-role"
+	s3RequesterPaysParam    = "s3-requester-pays"
+	gcsCredentialsParam     = "gcs-credentials"
+	azureAccountParam       = "azure-account"
+	azureSASTokenParam      = "azure-sas-token"
+	azureConnParam          = "azure-connection-string"
+	inPlaceParam            = "in-place"
+	encryptParam            = "encrypt"
+	followParam             = "follow"
+	followIntervalParam     = "follow-interval"
+	noAutoCommitParam       = "no-autocommit"
+	noRCParam               = "no-rc"
+	continueOnErrorParam    = "continue-on-error"
+	auditLogParam           = "audit-log"
+	maskParam               = "mask"
+	lineageParam            = "lineage"
+	failOnEmptyParam        = "fail-on-empty"
+	noHistoryParam          = "no-history"
+	udfParam                = "udf"
+	extensionParam          = "extension"
+	offlineExtensionsParam  = "offline-extensions"
+	jsonNestedParam         = "json-nested"
+	engineParam             = "engine"
+	attachParam             = "attach"
+	readOnlyParam           = "read-only"
+	ifExistsParam           = "if-exists"
+	batchSizeParam          = "batch-size"
+	commitIntervalParam     = "commit-interval"
+	tempDirParam            = "temp-dir"
+	maxResultMemoryParam    = "max-result-memory"
+	maxRowsWarnParam        = "max-rows-warn"
+	columnNamesParam        = "column-names"
+	columnReplaceParam      = "column-replace"
+	columnDedupeParam       = "column-dedupe"
+	decimalAsStringParam    = "decimal-as-string"
+	inputTZParam            = "input-tz"
+	outputTZParam           = "output-tz"
 )
 
 // DataQlCtl is the interface for the dataql controller
@@ -48,7 +117,13 @@ type DataQlCtl interface {
 }
 
 type dataQlCtl struct {
-	params dataql.Params
+	params       dataql.Params
+	aliases      []string
+	noHistory    bool
+	cacheTTL     string
+	cacheMaxSize string
+	partSize     string
+	maxResultMem string
 }
 
 // New creates a new DataQlCtl instance
@@ -98,6 +173,10 @@ func (c *dataQlCtl) Command() (*cobra.Command, error) {
 		PersistentFlags().
 		StringVarP(&c.params.Collection, tableNameParam, tableNameShortParam, "", "custom table name (collection) for the imported data")
 
+	command.
+		PersistentFlags().
+		StringArrayVar(&c.aliases, aliasParam, []string{}, "import a source as table name=source, for connection strings whose colons break -f source:alias; repeatable")
+
 	command.
 		PersistentFlags().
 		BoolVarP(&c.params.Verbose, verboseParam, verboseShortParam, false, "enable verbose output with detailed logging")
@@ -108,12 +187,20 @@ func (c *dataQlCtl) Command() (*cobra.Command, error) {
 
 	command.
 		PersistentFlags().
-		BoolVarP(&c.params.Quiet, quietParam, quietShortParam, false, "suppress progress bar output (useful for pipelines)")
+		BoolVarP(&c.params.Quiet, quietParam, quietShortParam, false, "suppress progress output, which is only shown when stderr is a terminal")
 
 	command.
 		PersistentFlags().
 		BoolVar(&c.params.NoSchema, noSchemaParam, false, "suppress table schema display before query results")
 
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.Plain, plainParam, false, "print only the result data, for other programs: no progress, table list, row count, confirmations or colors")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.OutputMode, modeParam, "", "format query results are printed in (table, json, jsonl, csv, markdown, vertical)")
+
 	command.
 		PersistentFlags().
 		IntVarP(&c.params.Truncate, truncateParam, truncateShortParam, 0, "truncate column values longer than N characters (0 = no truncation)")
@@ -134,6 +221,198 @@ func (c *dataQlCtl) Command() (*cobra.Command, error) {
 		PersistentFlags().
 		StringVar(&c.params.CacheDir, cacheDirParam, "", "cache directory (default: ~/.dataql/cache)")
 
+	command.
+		PersistentFlags().
+		StringVar(&c.cacheTTL, cacheTTLParam, "", "re-import cache entries older than this (e.g. 24h, 7d; default: $"+cachehandler.EnvTTL+")")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.cacheMaxSize, cacheMaxSizeParam, "", "evict least recently used cache entries above this size (e.g. 10GB; default: $"+cachehandler.EnvMaxSize+")")
+
+	command.
+		PersistentFlags().
+		IntVar(&c.params.DownloadParts, downloadPartsParam, rangedownload.DefaultConcurrency, "parts of large S3, GCS and Azure objects downloaded at the same time (1: download in one request)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.partSize, downloadPartSizeParam, "", "size of the parts of S3, GCS and Azure downloads (e.g. 64MB; default: 16MB)")
+
+	command.
+		PersistentFlags().
+		DurationVar(&c.params.DownloadTimeout, downloadTimeoutParam, urlhandler.DefaultTimeout, "time allowed for each request of HTTP/HTTPS downloads, resumed where they stopped (0: no limit)")
+
+	command.
+		PersistentFlags().
+		StringArrayVar(&c.params.Headers, headerParam, []string{}, "header sent with HTTP/HTTPS downloads, as 'Name: value' (can be repeated)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.CookieJar, cookieJarParam, "", "Netscape cookie file (as written by curl -c) sent with HTTP/HTTPS downloads and updated with the cookies they set")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.CACert, caCertParam, "", "PEM file of certificate authorities trusted by HTTP/HTTPS, S3, DynamoDB and database sources, besides the system ones")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.InsecureTLS, insecureParam, false, "do not verify the TLS certificates of HTTP/HTTPS, S3, DynamoDB and database sources (insecure)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.S3Endpoint, s3EndpointParam, "", "S3-compatible endpoint of s3:// sources, such as MinIO or Ceph (default: $AWS_ENDPOINT_URL_S3, $AWS_ENDPOINT_URL)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.S3Profile, s3ProfileParam, "", "named profile of the AWS config files used for s3:// sources (default: $AWS_PROFILE)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.S3AssumeRole, s3AssumeRoleParam, "", "ARN of an IAM role assumed to read s3:// sources")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.S3RequesterPays, s3RequesterPaysParam, false, "pay for the requests to requester-pays buckets of s3:// sources")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.GCSCredentials, gcsCredentialsParam, "", "service account key or workload identity federation file of gs:// sources (default: $GOOGLE_APPLICATION_CREDENTIALS)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.AzureAccount, azureAccountParam, "", "storage account of azure:// sources (default: $AZURE_STORAGE_ACCOUNT)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.AzureSASToken, azureSASTokenParam, "", "SAS token of azure:// sources (default: $AZURE_STORAGE_SAS_TOKEN)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.AzureConnString, azureConnParam, "", "connection string of the storage account of azure:// sources (default: $AZURE_STORAGE_CONNECTION_STRING)")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.InPlace, inPlaceParam, false, "query remote Parquet and CSV sources (https://, s3://, gs://) in place with DuckDB's httpfs extension instead of downloading them")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.Encrypt, encryptParam, false, "encrypt new storage (-s) and cache files with AES-256-GCM, keyed by $"+encryption.EnvKey+" or the OS keyring")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.Follow, followParam, false, "keep reading lines appended to CSV and JSONL files (like tail -F) and run the query again, until Ctrl-C")
+
+	command.
+		PersistentFlags().
+		DurationVar(&c.params.FollowInterval, followIntervalParam, 2*time.Second, "interval between checks for appended lines with --follow")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.NoAutoCommit, noAutoCommitParam, false, "run statements in a transaction: -q is committed only if it succeeds, the REPL requires COMMIT")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.NoRC, noRCParam, false, "do not run the REPL startup file (~/.dataqlrc or $DATAQLRC)")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.ContinueOnError, continueOnErrorParam, false, "keep executing piped commands after an error (the exit code still reports the failure)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.AuditLog, auditLogParam, "", "append executed statements to this JSONL audit log (default: $"+auditlog.EnvPath+")")
+
+	command.
+		PersistentFlags().
+		StringArrayVar(&c.params.Mask, maskParam, []string{}, "mask a column of the export as column=method (hash, redact, fake-email, shift-dates, null); repeatable")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.Lineage, lineageParam, false, "record the sources with their hashes, the query and the dataql version in the export (Parquet metadata, Excel properties, or a .lineage.json file)")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.FailOnEmpty, failOnEmptyParam, false, "exit with code 7 when the query returns or exports no rows")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.noHistory, noHistoryParam, false, "do not record the executed statements in the query history (also: $"+history.EnvDisable+")")
+
+	command.
+		PersistentFlags().
+		StringArrayVar(&c.params.UDFs, udfParam, []string{}, "SQL function from a Lua script or WASM module as name[:TYPE]=path (e.g. normalize_phone=./phone.lua); repeatable")
+
+	command.
+		PersistentFlags().
+		StringArrayVar(&c.params.Extensions, extensionParam, []string{}, "load a DuckDB extension (e.g. spatial, fts, iceberg), installing it when missing; repeatable (see dataql ext)")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.OfflineExtensions, offlineExtensionsParam, false, "never download extensions: load only the built-in and installed ones")
+
+	command.
+		PersistentFlags().
+		StringArrayVar(&c.params.Attach, attachParam, []string{}, "attach an existing storage file as path[:alias] to join its tables as alias.table (alias defaults to the file name); repeatable")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.ReadOnly, readOnlyParam, false, "open the storage and attached files read-only and reject statements other than SELECT, WITH, SHOW, DESCRIBE and EXPLAIN")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.IfExists, ifExistsParam, string(storage.IfExistsAppend), "what importing into a table already in the storage file does: append the rows, replace the table or fail")
+
+	command.
+		PersistentFlags().
+		IntVar(&c.params.BatchSize, batchSizeParam, storage.DefaultBatchSize, "imported rows buffered in memory before they are written to a table; lower it for very wide rows")
+
+	command.
+		PersistentFlags().
+		IntVar(&c.params.CommitInterval, commitIntervalParam, storage.DefaultCommitInterval, "imported rows committed per transaction when rows are inserted one by one (--engine sqlite, DECIMAL and timestamp columns)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.TempDir, tempDirParam, "", "directory DuckDB spills sorts, joins and aggregations larger than its memory limit to, also used for large paged results (default: .tmp next to the storage file, or in the working directory)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.maxResultMem, maxResultMemoryParam, "", "memory of the first rows a table is aligned on, and of a result held before JSON is written row by row or the pager input is spilled to --temp-dir (e.g. 1GB; default: 256MB)")
+
+	command.
+		PersistentFlags().
+		IntVar(&c.params.MaxRowsWarn, maxRowsWarnParam, 0, "in the REPL, ask before printing more rows than this to the terminal: print them all, only this many, or none (0: never ask)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.Engine, engineParam, string(storage.EngineDuckDB), "storage engine that imports the sources and runs the queries: duckdb or sqlite (SQLite SQL dialect, without DuckDB extensions, UDFs, cache or time zones)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.JSONNested, jsonNestedParam, string(filehandler.NestedFlatten), "import nested JSON objects and arrays as flattened columns (flatten), STRUCT and LIST columns (struct) or JSON columns (json)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.ColumnNames, columnNamesParam, "", "derive column names from headers and keys as snake_case (snake), lower case (lower) or as in the source, quoted (keep); default: CSV headers as is, other formats lower")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.ColumnReplace, columnReplaceParam, "", "replace characters other than letters, digits and _ in snake and lower column names with this string (default: removed)")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.ColumnDedupe, columnDedupeParam, filehandler.DedupeSuffix, "resolve duplicate column names with a _2, _3... suffix (suffix) or fail the import (error)")
+
+	command.
+		PersistentFlags().
+		BoolVar(&c.params.DecimalAsString, decimalAsStringParam, false, "write DECIMAL and HUGEINT values as strings in JSON and YAML output and exports, for readers that parse numbers as doubles")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.InputTZ, inputTZParam, "", "time zone of timestamps without a UTC offset in the sources (e.g. America/Sao_Paulo, UTC, +02:00); timestamp columns become TIMESTAMPTZ")
+
+	command.
+		PersistentFlags().
+		StringVar(&c.params.OutputTZ, outputTZParam, "", "time zone TIMESTAMPTZ values are displayed and exported in (default UTC)")
+
 	// Note: file flag is no longer required if storage flag points to existing DuckDB file
 	// Validation is done in runE to allow querying existing DuckDB files
 
@@ -147,13 +426,154 @@ func (c *dataQlCtl) Command() (*cobra.Command, error) {
 func (c *dataQlCtl) runE(cmd *cobra.Command, _ []string) error {
 	cmd.SilenceUsage = true
 
+	if c.params.AuditLog == "" {
+		c.params.AuditLog = os.Getenv(auditlog.EnvPath)
+	}
+
+	if !c.noHistory && os.Getenv(history.EnvDisable) == "" {
+		// Without a home directory the history is silently off
+		c.params.History, _ = history.DefaultPath()
+	}
+
+	if len(c.params.Mask) > 0 {
+		if c.params.Export == "" {
+			return clierror.Parse(fmt.Errorf("--%s requires --%s", maskParam, exportParam))
+		}
+		if _, err := mask.ParseRules(c.params.Mask); err != nil {
+			return clierror.Parse(err)
+		}
+		salt, generated, err := mask.ResolveSalt("")
+		if err != nil {
+			return err
+		}
+		if generated {
+			fmt.Fprintf(os.Stderr, "Masking with the generated salt %s; set $%s to reproduce it\n", salt, mask.EnvSalt)
+		}
+		c.params.MaskSalt = salt
+	}
+
+	if c.params.Export != "" {
+		if err := c.checkExport(); err != nil {
+			return err
+		}
+	}
+
+	if c.params.Lineage && c.params.Export == "" {
+		return clierror.Parse(fmt.Errorf("--%s requires --%s", lineageParam, exportParam))
+	}
+
+	if c.params.FailOnEmpty && (c.params.Query == "" || c.params.Follow) {
+		return clierror.Parse(fmt.Errorf("--%s requires --%s and cannot be combined with --%s", failOnEmptyParam, queryParam, followParam))
+	}
+
+	if c.params.Follow {
+		if c.params.Query == "" || c.params.Export != "" {
+			return clierror.Parse(fmt.Errorf("--%s requires --%s and cannot be combined with --%s", followParam, queryParam, exportParam))
+		}
+		if c.params.Cache {
+			return clierror.Parse(fmt.Errorf("--%s cannot be combined with --%s", followParam, cacheParam))
+		}
+	}
+
+	if c.params.OutputMode != "" {
+		mode, err := dataql.ParseOutputMode(c.params.OutputMode)
+		if err != nil {
+			return clierror.Parse(err)
+		}
+		c.params.OutputMode = mode
+	}
+
+	nested, err := filehandler.ParseNested(c.params.JSONNested)
+	if err != nil {
+		return clierror.Parse(err)
+	}
+	c.params.JSONNested = string(nested)
+
+	if _, err := filehandler.ParseColumnNaming(c.params.ColumnNames, c.params.ColumnReplace, c.params.ColumnDedupe); err != nil {
+		return clierror.Parse(err)
+	}
+
+	if _, err := dataql.ParseAttachments(c.params.Attach); err != nil {
+		return clierror.Parse(err)
+	}
+
+	if _, err := urlhandler.ParseHeaders(c.params.Headers); err != nil {
+		return clierror.Parse(err)
+	}
+
+	if _, err := (tlsconfig.Options{CACert: c.params.CACert, InsecureSkipVerify: c.params.InsecureTLS}).Config(); err != nil {
+		return clierror.Parse(err)
+	}
+
+	if err := (s3handler.Options{Endpoint: c.params.S3Endpoint, AssumeRole: c.params.S3AssumeRole}).Check(); err != nil {
+		return clierror.Parse(err)
+	}
+
+	if err := (gcshandler.Options{CredentialsFile: c.params.GCSCredentials}).Check(); err != nil {
+		return clierror.Parse(err)
+	}
+
+	if err := dataql.CheckInPlace(c.params); err != nil {
+		return clierror.Parse(err)
+	}
+
+	if err := storage.CheckBatch(c.params.BatchSize, c.params.CommitInterval); err != nil {
+		return clierror.Parse(err)
+	}
+
+	if _, err := storage.ParseIfExists(c.params.IfExists); err != nil {
+		return clierror.Parse(err)
+	}
+
+	if err := dataql.CheckEngine(c.params); err != nil {
+		return clierror.Parse(err)
+	}
+
+	for _, name := range []string{c.params.InputTZ, c.params.OutputTZ} {
+		if _, err := timezone.Load(name); err != nil {
+			return clierror.Parse(err)
+		}
+	}
+
+	for _, name := range c.params.Extensions {
+		if err := extensions.ValidateName(name); err != nil {
+			return clierror.Parse(err)
+		}
+	}
+
+	if err := c.parseCacheLimits(); err != nil {
+		return clierror.Parse(err)
+	}
+
+	if err := c.parseDownloadParts(); err != nil {
+		return clierror.Parse(err)
+	}
+
+	if c.maxResultMem != "" {
+		value, err := cachehandler.ParseSize(c.maxResultMem)
+		if err != nil {
+			return clierror.Parse(fmt.Errorf("invalid --max-result-memory %q (e.g. 512MB, 2GB)", c.maxResultMem))
+		}
+		c.params.MaxResultMemory = value
+	}
+
+	if c.params.MaxRowsWarn < 0 {
+		return clierror.Parse(fmt.Errorf("--%s must be 0 or greater", maxRowsWarnParam))
+	}
+
+	aliased, err := dataql.ParseAliases(c.aliases)
+	if err != nil {
+		return clierror.Parse(err)
+	}
+	c.params.FileInputs = append(c.params.FileInputs, aliased...)
+
 	// Check if we have file inputs or storage-only mode
 	hasFileInputs := len(c.params.FileInputs) > 0
-	hasStorage := c.params.DataSourceName != ""
+	hasStorage := c.params.DataSourceName != "" || len(c.params.Attach) > 0
 
 	// If no file inputs and no storage, we need at least one source
 	if !hasFileInputs && !hasStorage {
-		return fmt.Errorf("either --file or --storage with an existing DuckDB file is required")
+		return clierror.Parse(fmt.Errorf("either --file or --storage with an existing DuckDB file is required (or --%s)", attachParam))
 	}
 
 	// If no file inputs but storage is provided, check if we can query existing DuckDB
@@ -187,3 +607,61 @@ func (c *dataQlCtl) runE(cmd *cobra.Command, _ []string) error {
 
 	return nil
 }
+
+// checkExport validates the export type and path before the sources are
+// imported, which may take minutes, so a typo fails right away
+func (c *dataQlCtl) checkExport() error {
+	if c.params.Type == "" {
+		err := fmt.Errorf("--%s is required with --%s", typeParam, exportParam)
+		if exportType := exportdata.TypeFromPath(c.params.Export); exportType != "" {
+			err = fmt.Errorf("%w (e.g. -%s %s)", err, typeShortParam, exportType)
+		}
+		return clierror.Parse(err)
+	}
+	if err := exportdata.ValidateType(c.params.Type); err != nil {
+		return clierror.Parse(err)
+	}
+	return clierror.Export(exportdata.CheckPath(c.params.Export))
+}
+
+// parseDownloadParts reads --download-part-size and checks the options of
+// multi-part downloads
+func (c *dataQlCtl) parseDownloadParts() error {
+	if c.partSize != "" {
+		value, err := cachehandler.ParseSize(c.partSize)
+		if err != nil {
+			return fmt.Errorf("invalid --download-part-size %q (e.g. 8MB, 64MB)", c.partSize)
+		}
+		c.params.DownloadPartSize = value
+	}
+	return rangedownload.Options{Concurrency: c.params.DownloadParts, PartSize: c.params.DownloadPartSize}.Check()
+}
+
+// parseCacheLimits reads --cache-ttl and --cache-max-size, falling back to
+// their environment variables
+func (c *dataQlCtl) parseCacheLimits() error {
+	ttl := c.cacheTTL
+	if ttl == "" {
+		ttl = os.Getenv(cachehandler.EnvTTL)
+	}
+	if ttl != "" {
+		value, err := cachehandler.ParseTTL(ttl)
+		if err != nil {
+			return err
+		}
+		c.params.CacheTTL = value
+	}
+
+	size := c.cacheMaxSize
+	if size == "" {
+		size = os.Getenv(cachehandler.EnvMaxSize)
+	}
+	if size != "" {
+		value, err := cachehandler.ParseSize(size)
+		if err != nil {
+			return err
+		}
+		c.params.CacheMaxSize = value
+	}
+	return nil
+}
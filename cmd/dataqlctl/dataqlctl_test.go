@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/adrianolaselva/dataql/cmd/dataqlctl"
+	"github.com/adrianolaselva/dataql/pkg/history"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"path/filepath"
@@ -16,6 +17,8 @@ const (
 )
 
 func TestShouldExecuteWithSuccess(t *testing.T) {
+	t.Setenv(history.EnvPath, filepath.Join(t.TempDir(), "history.db"))
+
 	var tests = []struct {
 		args      []string
 		filePath  string
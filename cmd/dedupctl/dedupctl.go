@@ -0,0 +1,270 @@
+package dedupctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyParam                = "key"
+	keyShortParam           = "k"
+	keepParam               = "keep"
+	byParam                 = "by"
+	exportParam             = "export"
+	exportShortParam        = "e"
+	typeParam               = "type"
+	typeShortParam          = "t"
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	tableParam              = "table"
+	reportParam             = "report"
+)
+
+// Policies choosing the row kept among duplicates
+const (
+	keepFirst  = "first"
+	keepLast   = "last"
+	keepNewest = "newest"
+	keepOldest = "oldest"
+)
+
+// DedupCtl is the interface for the dedup controller
+type DedupCtl interface {
+	Command() *cobra.Command
+}
+
+type dedupCtl struct {
+	keys       []string
+	keep       string
+	by         string
+	export     string
+	exportType string
+	delimiter  string
+	table      string
+	report     int
+}
+
+// New creates a new DedupCtl instance
+func New() DedupCtl {
+	return &dedupCtl{}
+}
+
+// Command returns the cobra command for the dedup subcommand
+func (c *dedupCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "dedup <input>",
+		Short: "Remove duplicate rows by key",
+		Long: `Remove duplicate rows and write the result to a file, keeping one row per
+value of the --key columns (or per distinct row without a key).
+
+--keep chooses the row kept among duplicates:
+
+  first    the first row in input order (default)
+  last     the last row in input order
+  newest   the row with the highest --by value, e.g. an updated_at column
+  oldest   the row with the lowest --by value
+
+Ties and NULL --by values fall back to input order. Kept rows keep the order
+of the input. A report of the duplicates removed is printed.`,
+		Example: `  dataql dedup customers.csv --key email -e customers_clean.csv
+  dataql dedup events.jsonl -k user_id -k event_id --keep last -e events.parquet
+  dataql dedup accounts.csv -k id --keep newest --by updated_at -e latest.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: c.runE,
+	}
+
+	command.Flags().StringSliceVarP(&c.keys, keyParam, keyShortParam, []string{}, "key columns identifying duplicates (repeat or comma-separate; default: whole rows)")
+	command.Flags().StringVar(&c.keep, keepParam, keepFirst, "row kept among duplicates: first, last, newest or oldest")
+	command.Flags().StringVar(&c.by, byParam, "", "column ordering duplicates for --keep newest or oldest")
+	command.Flags().StringVarP(&c.export, exportParam, exportShortParam, "", "output file (required)")
+	command.Flags().StringVarP(&c.exportType, typeParam, typeShortParam, "", "output format (default: from the output file extension)")
+	command.Flags().StringVarP(&c.delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter of the input")
+	command.Flags().StringVar(&c.table, tableParam, "", "table to deduplicate when the input holds several")
+	command.Flags().IntVar(&c.report, reportParam, 10, "number of most duplicated keys to list (0: none)")
+	_ = command.MarkFlagRequired(exportParam)
+
+	return command
+}
+
+func (c *dedupCtl) runE(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	order, err := c.orderBy()
+	if err != nil {
+		return err
+	}
+
+	db, err := dataql.OpenWithOptions(dataql.Options{Delimiter: c.delimiter}, args[0])
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	source, err := db.SourceTable(c.table)
+	if err != nil {
+		return err
+	}
+
+	keys := c.keys
+	if len(keys) == 0 {
+		for _, column := range source.Columns {
+			keys = append(keys, column.Name)
+		}
+	} else if err := checkColumns(source, keys); err != nil {
+		return err
+	}
+	if c.by != "" {
+		if err := checkColumns(source, []string{c.by}); err != nil {
+			return err
+		}
+	}
+
+	tableName := storage.QuoteIdentifier(source.Name)
+	partition := quoteList(keys)
+	query := fmt.Sprintf(`SELECT * EXCLUDE (__dataql_rowid, __dataql_rank) FROM (
+  SELECT *, rowid AS __dataql_rowid, row_number() OVER (PARTITION BY %s ORDER BY %s) AS __dataql_rank FROM %s
+) WHERE __dataql_rank = 1 ORDER BY __dataql_rowid`, partition, order, tableName)
+
+	if _, err := db.Export(cmd.Context(), query, c.export, c.exportType); err != nil {
+		return err
+	}
+
+	return c.printReport(cmd.Context(), os.Stdout, db, tableName, keys)
+}
+
+// orderBy returns the window ordering that ranks the kept row first
+func (c *dedupCtl) orderBy() (string, error) {
+	switch strings.ToLower(c.keep) {
+	case keepFirst, keepLast:
+		if c.by != "" {
+			return "", fmt.Errorf("--%s is only used with --%s %s or %s", byParam, keepParam, keepNewest, keepOldest)
+		}
+		if strings.EqualFold(c.keep, keepLast) {
+			return "rowid DESC", nil
+		}
+		return "rowid", nil
+	case keepNewest, keepOldest:
+		if c.by == "" {
+			return "", fmt.Errorf("--%s %s requires --%s <column>", keepParam, c.keep, byParam)
+		}
+		direction := "ASC"
+		if strings.EqualFold(c.keep, keepNewest) {
+			direction = "DESC"
+		}
+		return fmt.Sprintf("%s %s NULLS LAST, rowid", storage.QuoteIdentifier(c.by), direction), nil
+	}
+	return "", fmt.Errorf("invalid --%s %q (expected %s, %s, %s or %s)", keepParam, c.keep, keepFirst, keepLast, keepNewest, keepOldest)
+}
+
+// printReport prints the rows read, kept and removed and the most
+// duplicated keys
+func (c *dedupCtl) printReport(ctx context.Context, w io.Writer, db *dataql.DB, tableName string, keys []string) error {
+	partition := quoteList(keys)
+	rows, err := db.Query(ctx, fmt.Sprintf(`SELECT COALESCE(SUM(n), 0), COUNT(*), COUNT(*) FILTER (WHERE n > 1)
+FROM (SELECT COUNT(*) AS n FROM %s GROUP BY %s)`, tableName, partition))
+	if err != nil {
+		return fmt.Errorf("failed to count duplicates: %w", err)
+	}
+	var total, kept, duplicated int64
+	if rows.Next() {
+		err = rows.Scan(&total, &kept, &duplicated)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("failed to count duplicates: %w", err)
+	}
+
+	fmt.Fprintf(w, "Read %d rows, kept %d, removed %s duplicates of %d keys\n", total, kept,
+		color.YellowString("%d", total-kept), duplicated)
+	fmt.Fprintf(w, "Written to %s\n", c.export)
+	if c.report <= 0 || duplicated == 0 {
+		return nil
+	}
+
+	rows, err = db.Query(ctx, fmt.Sprintf("SELECT %s, COUNT(*) AS %s FROM %s GROUP BY ALL HAVING COUNT(*) > 1 ORDER BY %s DESC, %s LIMIT %d",
+		partition, storage.QuoteIdentifier("rows"), tableName, storage.QuoteIdentifier("rows"), partition, c.report))
+	if err != nil {
+		return fmt.Errorf("failed to list duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	headers := make([]any, 0, len(keys)+1)
+	for _, column := range rows.Columns() {
+		headers = append(headers, column)
+	}
+	tbl := table.New(headers...).
+		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+		WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+		WithWriter(w)
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return err
+		}
+		cells := make([]any, len(values))
+		for i, value := range values {
+			cells[i] = formatValue(value)
+		}
+		tbl.AddRow(cells...)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if duplicated > int64(c.report) {
+		fmt.Fprintf(w, "\nMost duplicated keys (first %d of %d):\n", c.report, duplicated)
+	} else {
+		fmt.Fprintf(w, "\nDuplicated keys:\n")
+	}
+	tbl.Print()
+	return nil
+}
+
+// checkColumns fails on columns missing from the table
+func checkColumns(source dataql.Table, columns []string) error {
+	for _, name := range columns {
+		found := false
+		for _, column := range source.Columns {
+			if strings.EqualFold(column.Name, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("column %q not found in %s", name, source.Name)
+		}
+	}
+	return nil
+}
+
+// formatValue renders NULL as an empty cell and shortens long values
+func formatValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	text := fmt.Sprint(value)
+	if len(text) > 60 {
+		text = text[:57] + "..."
+	}
+	return text
+}
+
+func quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = storage.QuoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,43 @@
+package dedupctl
+
+import (
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		keep     string
+		by       string
+		expected string
+		err      string
+	}{
+		{keepFirst, "", "rowid", ""},
+		{"LAST", "", "rowid DESC", ""},
+		{keepNewest, "updated_at", `"updated_at" DESC NULLS LAST, rowid`, ""},
+		{keepOldest, "updated_at", `"updated_at" ASC NULLS LAST, rowid`, ""},
+		{keepNewest, "", "", "--keep newest requires --by <column>"},
+		{keepFirst, "updated_at", "", "--by is only used with --keep newest or oldest"},
+		{"random", "", "", `invalid --keep "random"`},
+	}
+
+	for _, tt := range tests {
+		c := &dedupCtl{keep: tt.keep, by: tt.by}
+		orderBy, err := c.orderBy()
+		if tt.err != "" {
+			assert.ErrorContains(t, err, tt.err, tt.keep)
+			continue
+		}
+		assert.NoError(t, err, tt.keep)
+		assert.Equal(t, tt.expected, orderBy, tt.keep)
+	}
+}
+
+func TestCheckColumns(t *testing.T) {
+	source := dataql.Table{Name: "accounts", Columns: []dataql.Column{{Name: "id"}, {Name: "updated_at"}}}
+
+	assert.NoError(t, checkColumns(source, []string{"ID", "updated_at"}))
+	assert.ErrorContains(t, checkColumns(source, []string{"id", "email"}), `column "email" not found in accounts`)
+}
@@ -59,6 +59,21 @@ func TestCommand_Flags(t *testing.T) {
 		{"verbose", "v"},
 		{"input-format", "i"},
 		{"quiet", "Q"},
+		{"export", "e"},
+		{"type", "t"},
+		{"top-values", ""},
+		{"histogram", ""},
+		{"bins", ""},
+		{"outliers", ""},
+		{"completeness", ""},
+		{"cardinality", ""},
+		{"temporal", ""},
+		{"by", ""},
+		{"score", ""},
+		{"score-weights", ""},
+		{"sample", ""},
+		{"cache", ""},
+		{"cache-dir", ""},
 	}
 
 	for _, flag := range flags {
@@ -90,4 +105,9 @@ func TestCommand_Defaults(t *testing.T) {
 	if inputFormatFlag.DefValue != "csv" {
 		t.Errorf("Default input-format should be 'csv', got '%s'", inputFormatFlag.DefValue)
 	}
+
+	binsFlag := cmd.PersistentFlags().Lookup("bins")
+	if binsFlag.DefValue != "10" {
+		t.Errorf("Default bins should be '10', got '%s'", binsFlag.DefValue)
+	}
 }
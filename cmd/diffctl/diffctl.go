@@ -0,0 +1,219 @@
+package diffctl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/datadiff"
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyParam                = "key"
+	keyShortParam           = "k"
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	limitParam              = "limit"
+	exportParam             = "export"
+	exportShortParam        = "e"
+	typeParam               = "type"
+	typeShortParam          = "t"
+)
+
+// Table names the two datasets are imported under
+const (
+	leftTable  = "diff_left"
+	rightTable = "diff_right"
+)
+
+// DiffCtl is the interface for the diff controller
+type DiffCtl interface {
+	Command() *cobra.Command
+}
+
+type diffCtl struct {
+	keys       []string
+	delimiter  string
+	limit      int
+	export     string
+	exportType string
+}
+
+// New creates a new DiffCtl instance
+func New() DiffCtl {
+	return &diffCtl{}
+}
+
+// Command returns the cobra command for the diff subcommand
+func (c *diffCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Compare two datasets row by row",
+		Long: `Compare two datasets of any supported format (e.g. a CSV delivery with
+last month's Parquet file) and print the added, removed and modified rows and
+the number of changes per column.
+
+Rows are matched by the --key columns. Without a key, whole rows are compared
+and only added and removed rows are reported. Columns present on one side
+only are listed; a column with a different type on each side is compared as
+text.
+
+--export writes every change, one row per changed value: the change (added,
+removed or modified), the key columns, the column, and the old and new value.
+Added and removed rows carry the whole row as JSON.`,
+		Example: `  dataql diff customers_2024.csv customers_2025.parquet --key id
+  dataql diff old.csv new.csv --key order_id --key line --limit 50
+  dataql diff old.csv new.csv --key id --export changes.csv`,
+		Args: cobra.ExactArgs(2),
+		RunE: c.runE,
+	}
+
+	command.Flags().StringSliceVarP(&c.keys, keyParam, keyShortParam, []string{}, "key columns matching rows between the datasets (repeat or comma-separate)")
+	command.Flags().StringVarP(&c.delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter")
+	command.Flags().IntVar(&c.limit, limitParam, 20, "number of changes to print (0: none)")
+	command.Flags().StringVarP(&c.export, exportParam, exportShortParam, "", "export every change to a file")
+	command.Flags().StringVarP(&c.exportType, typeParam, typeShortParam, "", "export format (default: from the export file extension)")
+
+	return command
+}
+
+func (c *diffCtl) runE(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	if args[0] == args[1] {
+		return fmt.Errorf("cannot compare %s with itself", args[0])
+	}
+	db, err := dataql.OpenWithOptions(dataql.Options{Delimiter: c.delimiter}, args[0]+":"+leftTable, args[1]+":"+rightTable)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	d, err := db.Diff(leftTable, rightTable, c.keys)
+	if err != nil {
+		return err
+	}
+
+	summary, err := d.Summary(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to compare datasets: %w", err)
+	}
+	printSummary(os.Stdout, args[0], args[1], c.keys, summary)
+
+	total := summary.Added + summary.Removed
+	for _, column := range summary.Columns {
+		total += column.Changed
+	}
+	if c.limit > 0 && total > 0 {
+		if err := c.printChanges(cmd, db, d, total); err != nil {
+			return err
+		}
+	}
+
+	if c.export != "" {
+		if _, err := db.Export(cmd.Context(), d.ChangesQuery(), c.export, c.exportType); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Changes exported to %s\n", c.export)
+	}
+	return nil
+}
+
+// printSummary prints the row counts, schema differences and changes per column
+func printSummary(w io.Writer, left, right string, keys []string, s *datadiff.Summary) {
+	matching := "whole rows"
+	if len(keys) > 0 {
+		matching = "key " + strings.Join(keys, ", ")
+	}
+	fmt.Fprintf(w, "Comparing %s (%d rows) with %s (%d rows) on %s\n\n", left, s.LeftRows, right, s.RightRows, matching)
+
+	fmt.Fprintf(w, "%s  %d\n", color.GreenString("Added:    "), s.Added)
+	fmt.Fprintf(w, "%s  %d\n", color.RedString("Removed:  "), s.Removed)
+	if len(keys) > 0 {
+		fmt.Fprintf(w, "%s  %d\n", color.YellowString("Modified: "), s.Modified)
+	}
+	fmt.Fprintf(w, "%s  %d\n", "Unchanged:", s.Unchanged)
+
+	if len(s.LeftOnlyColumns) > 0 {
+		fmt.Fprintf(w, "\nColumns only in %s: %s\n", left, strings.Join(s.LeftOnlyColumns, ", "))
+	}
+	if len(s.RightOnlyColumns) > 0 {
+		fmt.Fprintf(w, "\nColumns only in %s: %s\n", right, strings.Join(s.RightOnlyColumns, ", "))
+	}
+	if s.LeftDuplicateKeys > 0 || s.RightDuplicateKeys > 0 {
+		fmt.Fprintf(w, "\n%s key values are not unique (%d duplicated in %s, %d in %s); matches may be repeated\n",
+			color.YellowString("Warning:"), s.LeftDuplicateKeys, left, s.RightDuplicateKeys, right)
+	}
+
+	if s.Modified > 0 {
+		fmt.Fprintln(w, "\nChanges by column:")
+		tbl := table.New("Column", "Changed").
+			WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+			WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+			WithWriter(w)
+		for _, column := range s.Columns {
+			if column.Changed > 0 {
+				tbl.AddRow(column.Column, column.Changed)
+			}
+		}
+		tbl.Print()
+	}
+}
+
+// printChanges prints the first changes as a table
+func (c *diffCtl) printChanges(cmd *cobra.Command, db *dataql.DB, d *datadiff.Diff, total int64) error {
+	rows, err := db.Query(cmd.Context(), fmt.Sprintf("%s LIMIT %d", d.ChangesQuery(), c.limit))
+	if err != nil {
+		return fmt.Errorf("failed to list changes: %w", err)
+	}
+	defer rows.Close()
+
+	columns := rows.Columns()
+	headers := make([]any, len(columns))
+	for i, column := range columns {
+		headers[i] = column
+	}
+	tbl := table.New(headers...).
+		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+		WithWriter(os.Stdout)
+
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return err
+		}
+		cells := make([]any, len(values))
+		for i, value := range values {
+			cells[i] = formatValue(value)
+		}
+		tbl.AddRow(cells...)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if total > int64(c.limit) {
+		fmt.Printf("\nChanges (first %d of %d, use --export for all):\n", c.limit, total)
+	} else {
+		fmt.Printf("\nChanges:\n")
+	}
+	tbl.Print()
+	return nil
+}
+
+// formatValue renders NULL as an empty cell and shortens long JSON rows
+func formatValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	text := fmt.Sprint(value)
+	if len(text) > 60 {
+		text = text[:57] + "..."
+	}
+	return text
+}
@@ -0,0 +1,164 @@
+package encryptionctl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/adrianolaselva/dataql/pkg/encryption"
+	"github.com/adrianolaselva/dataql/pkg/keyring"
+	"github.com/spf13/cobra"
+)
+
+const (
+	keyringParam = "keyring"
+	forceParam   = "force"
+)
+
+// EncryptionCtl is the interface for the encryption controller
+type EncryptionCtl interface {
+	Command() *cobra.Command
+}
+
+type encryptionCtl struct{}
+
+// New creates a new EncryptionCtl instance
+func New() EncryptionCtl {
+	return &encryptionCtl{}
+}
+
+// Command returns the cobra command for the encryption subcommand
+func (c *encryptionCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "encryption",
+		Short: "Encrypt storage and cache files at rest",
+		Long: `Manage the encryption of DuckDB storage (-s) and cache files, which may hold
+extracts of sensitive production data.
+
+With run --encrypt, new storage and cache files are encrypted with AES-256-GCM.
+Encrypted files are decrypted into a private temporary copy while a session
+uses them, and encrypted back when it ends. The key is read from
+$` + encryption.EnvKey + ` (32 bytes, base64 or hex encoded), or else from the
+OS keyring (macOS Keychain, Secret Service on Linux, Windows Credential Manager).`,
+		Example: `  dataql encryption keygen --keyring
+  dataql run -f prod_extract.csv -s extract.duckdb --encrypt
+  dataql run -s extract.duckdb -q "SELECT COUNT(*) FROM prod_extract"
+  dataql encryption encrypt old_store.duckdb
+  export ` + encryption.EnvKey + `=$(dataql encryption keygen)`,
+	}
+
+	command.AddCommand(c.keygenCommand())
+	command.AddCommand(c.encryptCommand())
+	command.AddCommand(c.decryptCommand())
+
+	return command
+}
+
+func (c *encryptionCtl) keygenCommand() *cobra.Command {
+	var toKeyring, force bool
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate an encryption key",
+		Long: `Generate a random 256-bit key. It is printed base64 encoded, for
+$` + encryption.EnvKey + `, or stored in the OS keyring with --keyring.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			key, err := encryption.GenerateKey()
+			if err != nil {
+				return err
+			}
+			if !toKeyring {
+				fmt.Println(key)
+				return nil
+			}
+
+			_, err = keyring.Get(encryption.KeyringAccount)
+			if err == nil && !force {
+				return fmt.Errorf("the OS keyring already has an encryption key; replacing it leaves the files encrypted with it unreadable (use --%s)", forceParam)
+			}
+			if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+				return err
+			}
+			if err := keyring.Set(encryption.KeyringAccount, key); err != nil {
+				return err
+			}
+
+			fmt.Println("Stored a new encryption key in the OS keyring")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&toKeyring, keyringParam, false, "store the key in the OS keyring instead of printing it")
+	cmd.Flags().BoolVar(&force, forceParam, false, "replace the key stored in the OS keyring")
+
+	return cmd
+}
+
+func (c *encryptionCtl) encryptCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt <file...>",
+		Short: "Encrypt storage files in place",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return transform(args, true)
+		},
+	}
+}
+
+func (c *encryptionCtl) decryptCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "decrypt <file...>",
+		Short: "Decrypt storage files in place",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return transform(args, false)
+		},
+	}
+}
+
+// transform encrypts or decrypts files in place
+func transform(files []string, encrypt bool) error {
+	key, err := encryption.LoadKey()
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("file does not exist: %s", file)
+		}
+		encrypted, err := encryption.IsEncrypted(file)
+		if err != nil {
+			return err
+		}
+
+		if encrypt {
+			if encrypted {
+				return fmt.Errorf("%s is already encrypted", file)
+			}
+			// The write-ahead log holds changes not yet in the file, which would stay in plaintext
+			if _, err := os.Stat(file + ".wal"); err == nil {
+				return fmt.Errorf("%s has a write-ahead log; close the sessions using it, or open and close it with dataql run -s, first", file)
+			}
+			if err := encryption.EncryptFile(file, file, key); err != nil {
+				return fmt.Errorf("failed to encrypt %s: %w", file, err)
+			}
+			fmt.Printf("Encrypted %s\n", file)
+			continue
+		}
+
+		if !encrypted {
+			return fmt.Errorf("%s is not encrypted", file)
+		}
+		if err := encryption.DecryptFile(file, file, key); err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", file, err)
+		}
+		fmt.Printf("Decrypted %s\n", file)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package encryptionctl
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/encryption"
+	"github.com/adrianolaselva/dataql/pkg/keyring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runEncryption(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	cmd := New().Command()
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	runErr := cmd.Execute()
+
+	require.NoError(t, writer.Close())
+	var out bytes.Buffer
+	_, err = io.Copy(&out, reader)
+	require.NoError(t, err)
+	return out.String(), runErr
+}
+
+func TestKeygen(t *testing.T) {
+	keyring.MockInit()
+
+	out, err := runEncryption(t, "keygen")
+	require.NoError(t, err)
+	_, err = encryption.ParseKey(strings.TrimSpace(out))
+	assert.NoError(t, err)
+
+	out, err = runEncryption(t, "keygen", "--keyring")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Stored a new encryption key")
+	stored, err := keyring.Get(encryption.KeyringAccount)
+	require.NoError(t, err)
+
+	_, err = runEncryption(t, "keygen", "--keyring")
+	assert.ErrorContains(t, err, "already has an encryption key")
+
+	_, err = runEncryption(t, "keygen", "--keyring", "--force")
+	require.NoError(t, err)
+	replaced, err := keyring.Get(encryption.KeyringAccount)
+	require.NoError(t, err)
+	assert.NotEqual(t, stored, replaced)
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := encryption.GenerateKey()
+	require.NoError(t, err)
+	t.Setenv(encryption.EnvKey, key)
+
+	file := filepath.Join(t.TempDir(), "store.duckdb")
+	require.NoError(t, os.WriteFile(file, []byte("plain data"), 0600))
+
+	out, err := runEncryption(t, "encrypt", file)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Encrypted "+file)
+	encrypted, err := encryption.IsEncrypted(file)
+	require.NoError(t, err)
+	assert.True(t, encrypted)
+
+	_, err = runEncryption(t, "encrypt", file)
+	assert.ErrorContains(t, err, "already encrypted")
+
+	out, err = runEncryption(t, "decrypt", file)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Decrypted "+file)
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "plain data", string(data))
+
+	_, err = runEncryption(t, "decrypt", file)
+	assert.ErrorContains(t, err, "not encrypted")
+
+	require.NoError(t, os.WriteFile(file+".wal", []byte("changes"), 0600))
+	_, err = runEncryption(t, "encrypt", file)
+	assert.ErrorContains(t, err, "write-ahead log")
+
+	_, err = runEncryption(t, "encrypt", filepath.Join(t.TempDir(), "missing.duckdb"))
+	assert.ErrorContains(t, err, "does not exist")
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/adrianolaselva/dataql/pkg/clierror"
+	"github.com/spf13/cobra"
+)
+
+const errorFormatParam = "error-format"
+
+// errorFormat is the value of --error-format. In JSON, cobra must not print
+// the error and usage on stderr, which is left to the JSON object alone.
+type errorFormat struct {
+	value   string
+	rootCmd *cobra.Command
+}
+
+func newErrorFormat(rootCmd *cobra.Command) *errorFormat {
+	f := &errorFormat{value: clierror.FormatText, rootCmd: rootCmd}
+	if env := os.Getenv(clierror.EnvFormat); env != "" {
+		// An invalid variable is ignored rather than failing every command
+		_ = f.Set(env)
+	}
+	return f
+}
+
+// String implements pflag.Value
+func (f *errorFormat) String() string {
+	return f.value
+}
+
+// Set implements pflag.Value
+func (f *errorFormat) Set(value string) error {
+	switch value {
+	case clierror.FormatText, clierror.FormatJSON:
+	default:
+		return fmt.Errorf("invalid error format %q (use %s or %s)", value, clierror.FormatText, clierror.FormatJSON)
+	}
+	f.value = value
+	f.rootCmd.SilenceErrors = value == clierror.FormatJSON
+	f.rootCmd.SilenceUsage = value == clierror.FormatJSON
+	return nil
+}
+
+// Type implements pflag.Value
+func (f *errorFormat) Type() string {
+	return "string"
+}
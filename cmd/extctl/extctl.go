@@ -0,0 +1,189 @@
+package extctl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/adrianolaselva/dataql/pkg/extensions"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/adrianolaselva/dataql/pkg/storage/duckdb"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	dirParam        = "dir"
+	repositoryParam = "repository"
+	forceParam      = "force"
+	allParam        = "all"
+)
+
+// ExtCtl is the interface for the extension controller
+type ExtCtl interface {
+	Command() *cobra.Command
+}
+
+type extCtl struct {
+	dir string
+}
+
+// New creates a new ExtCtl instance
+func New() ExtCtl {
+	return &extCtl{}
+}
+
+// Command returns the cobra command for the ext subcommand
+func (c *extCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "ext",
+		Short: "Install DuckDB extensions into the bundled engine",
+		Long: `Install DuckDB extensions, such as httpfs, spatial, fts or iceberg, so their
+functions and readers can be used in queries. json and parquet are built in.
+
+Extensions are installed once into the extension directory (~/.duckdb/extensions,
+or $` + extensions.EnvDir + `) for the DuckDB version and platform of dataql,
+then loaded by queries with --extension, or with LOAD in SQL. DuckDB also
+loads the extensions of known functions and file types by itself.
+
+Without internet access, install from a .duckdb_extension file, or from a
+mirror of the extension repository given with --repository, and run queries
+with --offline-extensions.`,
+		Example: `  dataql ext install spatial fts
+  dataql run -f parcels.csv --extension spatial -q "SELECT ST_Area(ST_GeomFromText(wkt)) FROM parcels"
+  dataql ext install ./spatial.duckdb_extension.gz
+  dataql ext install spatial --repository /mnt/mirror/duckdb-extensions
+  dataql ext list`,
+	}
+
+	command.PersistentFlags().StringVar(&c.dir, dirParam, "", "extension directory (default: $"+extensions.EnvDir+" or ~/.duckdb/extensions)")
+
+	command.AddCommand(c.installCommand())
+	command.AddCommand(c.listCommand())
+
+	return command
+}
+
+// open creates an in-memory engine using the extension directory
+func (c *extCtl) open(offline bool) (storage.Storage, error) {
+	st, err := duckdb.NewDuckDBStorage("")
+	if err != nil {
+		return nil, err
+	}
+	if err := extensions.Configure(st, c.dir, offline); err != nil {
+		_ = st.Close()
+		return nil, err
+	}
+	return st, nil
+}
+
+func (c *extCtl) installCommand() *cobra.Command {
+	var opts extensions.InstallOptions
+
+	cmd := &cobra.Command{
+		Use:   "install <name|file>...",
+		Short: "Install extensions by name or from .duckdb_extension files",
+		Long: `Install extensions by name, from the DuckDB core repository or the one given
+with --repository (a URL or a local mirror directory laid out as
+<repository>/<version>/<platform>/<name>.duckdb_extension.gz), or from local
+.duckdb_extension files built for the DuckDB version and platform shown by
+dataql ext list. Each extension is loaded once installed, to check it works.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			st, err := c.open(false)
+			if err != nil {
+				return err
+			}
+			defer func(st storage.Storage) {
+				_ = st.Close()
+			}(st)
+
+			for _, source := range args {
+				name, err := extensions.Install(st, source, opts)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Installed extension %s\n", name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Repository, repositoryParam, "", "repository URL or local mirror directory (default: the DuckDB core repository)")
+	cmd.Flags().BoolVar(&opts.Force, forceParam, false, "install again extensions already installed, e.g. to update them")
+
+	return cmd
+}
+
+func (c *extCtl) listCommand() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the built-in and installed extensions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			st, err := c.open(false)
+			if err != nil {
+				return err
+			}
+			defer func(st storage.Storage) {
+				_ = st.Close()
+			}(st)
+
+			version, platform, err := extensions.Platform(st)
+			if err != nil {
+				return err
+			}
+			list, err := extensions.List(st)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("DuckDB %s (%s), extensions in %s\n", version, platform, c.directory())
+
+			tbl := table.New("Name", "Status", "Version", "Description").
+				WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+				WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+				WithWriter(os.Stdout)
+
+			for _, e := range list {
+				status := "available"
+				switch {
+				case e.BuiltIn():
+					status = "built-in"
+				case e.Installed:
+					status = "installed"
+				case !all:
+					continue
+				}
+				tbl.AddRow(e.Name, status, e.Version, e.Description)
+			}
+
+			tbl.Print()
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, allParam, false, "also list the extensions available for installation")
+
+	return cmd
+}
+
+// directory returns the extension directory for display
+func (c *extCtl) directory() string {
+	if c.dir != "" {
+		return c.dir
+	}
+	if dir := os.Getenv(extensions.EnvDir); dir != "" {
+		return dir
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".duckdb", "extensions")
+	}
+	return "~/.duckdb/extensions"
+}
@@ -0,0 +1,209 @@
+package generatectl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/internal/exportdata"
+	"github.com/adrianolaselva/dataql/pkg/datagen"
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/storage/duckdb"
+	"github.com/schollz/progressbar/v3"
+	"github.com/spf13/cobra"
+)
+
+const (
+	schemaParam             = "schema"
+	likeParam               = "like"
+	rowsParam               = "rows"
+	rowsShortParam          = "n"
+	seedParam               = "seed"
+	saveSchemaParam         = "save-schema"
+	exportParam             = "export"
+	exportShortParam        = "e"
+	typeParam               = "type"
+	typeShortParam          = "t"
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	tableParam              = "table"
+)
+
+// defaultRows is the number of rows generated when neither --rows nor the
+// schema sets it
+const defaultRows = 100
+
+// GenerateCtl is the interface for the generate controller
+type GenerateCtl interface {
+	Command() *cobra.Command
+}
+
+type generateCtl struct {
+	schema     string
+	like       string
+	rows       int64
+	seed       int64
+	saveSchema string
+	export     string
+	exportType string
+	delimiter  string
+	table      string
+}
+
+// New creates a new GenerateCtl instance
+func New() GenerateCtl {
+	return &generateCtl{}
+}
+
+// Command returns the cobra command for the generate subcommand
+func (c *generateCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate synthetic data from a schema or an existing file",
+		Long: `Generate rows of fake data in any export format, for tests and demos.
+
+The columns come from a YAML schema (--schema) or mimic an existing file
+(--like): its column types, number and date ranges, category frequencies,
+text shapes (e-mail, UUID, length) and null rates. --save-schema writes the
+schema profiled from --like so it can be edited and reused.
+
+Schema example:
+
+  rows: 1000
+  columns:
+    - {name: id, type: sequence}
+    - {name: customer, type: name}
+    - {name: email, type: email, null_rate: 0.05}
+    - {name: country, type: choice, values: [BR, US, DE], weights: [5, 3, 2]}
+    - {name: amount, type: float, min: 1, max: 500, decimals: 2}
+    - {name: created_at, type: timestamp, min: 2024-01-01, max: 2024-12-31}
+
+Types: ` + strings.Join(datagen.Types(), ", ") + `.
+The same --seed generates the same rows.`,
+		Example: `  dataql generate --schema customers.yaml -n 10000 -e customers.parquet
+  dataql generate --like orders.csv -n 1000000 -e orders_fake.csv
+  dataql generate --like orders.csv --save-schema orders.yaml`,
+		Args: cobra.NoArgs,
+		RunE: c.runE,
+	}
+
+	command.Flags().StringVar(&c.schema, schemaParam, "", "YAML schema of the columns to generate")
+	command.Flags().StringVar(&c.like, likeParam, "", "file whose schema and distributions are mimicked")
+	command.Flags().Int64VarP(&c.rows, rowsParam, rowsShortParam, 0, fmt.Sprintf("number of rows (default: rows of the schema, rows of --like, or %d)", defaultRows))
+	command.Flags().Int64Var(&c.seed, seedParam, 1, "seed of the random values")
+	command.Flags().StringVar(&c.saveSchema, saveSchemaParam, "", "write the schema used (e.g. profiled with --like) to a YAML file")
+	command.Flags().StringVarP(&c.export, exportParam, exportShortParam, "", "output file")
+	command.Flags().StringVarP(&c.exportType, typeParam, typeShortParam, "", "output format (default: from the output file extension)")
+	command.Flags().StringVarP(&c.delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter of --like")
+	command.Flags().StringVar(&c.table, tableParam, "", "table of --like to mimic when it holds several")
+
+	return command
+}
+
+func (c *generateCtl) runE(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+
+	if (c.schema == "") == (c.like == "") {
+		return fmt.Errorf("exactly one of --%s or --%s is required", schemaParam, likeParam)
+	}
+	if c.export == "" && c.saveSchema == "" {
+		return fmt.Errorf("--%s is required (or --%s to only write the schema)", exportParam, saveSchemaParam)
+	}
+	if c.rows < 0 {
+		return fmt.Errorf("--%s must not be negative", rowsParam)
+	}
+
+	var spec *datagen.Spec
+	var err error
+	if c.schema != "" {
+		spec, err = datagen.Load(c.schema)
+	} else {
+		spec, err = c.profile(cmd.Context())
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.saveSchema != "" {
+		data, err := spec.Marshal()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(c.saveSchema, data, 0644); err != nil {
+			return fmt.Errorf("failed to write schema: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Schema written to %s\n", c.saveSchema)
+	}
+	if c.export == "" {
+		return nil
+	}
+
+	rows := c.rows
+	if rows == 0 {
+		rows = spec.Rows
+	}
+	if rows == 0 {
+		rows = defaultRows
+	}
+
+	query, err := spec.Query(rows, c.seed)
+	if err != nil {
+		return err
+	}
+	if err := c.write(cmd.Context(), query); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Generated %d rows to %s\n", rows, c.export)
+	return nil
+}
+
+// profile builds a spec mimicking the table of --like
+func (c *generateCtl) profile(ctx context.Context) (*datagen.Spec, error) {
+	db, err := dataql.OpenWithOptions(dataql.Options{Delimiter: c.delimiter}, c.like)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	table, err := db.SourceTable(c.table)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.like, err)
+	}
+	return datagen.Profile(ctx, db, table)
+}
+
+// write runs the generation query in an in-memory database and exports it
+func (c *generateCtl) write(ctx context.Context, query string) error {
+	format := c.exportType
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(c.export)), ".")
+		if format == "" {
+			return fmt.Errorf("export format is required for %s (no file extension)", c.export)
+		}
+	}
+
+	store, err := duckdb.NewDuckDBStorage("")
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	rows, err := store.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to generate rows: %w", err)
+	}
+	defer rows.Close()
+
+	export, err := exportdata.NewExport(format, rows, c.export, progressbar.NewOptions(0, progressbar.OptionSetWriter(io.Discard)))
+	if err != nil {
+		return fmt.Errorf("failed to export: %w", err)
+	}
+	if _, err := export.Export(); err != nil {
+		return fmt.Errorf("failed to export data: %w", err)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,92 @@
+package generatectl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const schemaYAML = `rows: 3
+columns:
+  - {name: id, type: sequence}
+  - {name: country, type: choice, values: [BR]}
+`
+
+func runGenerate(t *testing.T, args ...string) error {
+	t.Helper()
+
+	cmd := New().Command()
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	return cmd.Execute()
+}
+
+func TestGenerate_Schema(t *testing.T) {
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "schema.yaml")
+	require.NoError(t, os.WriteFile(schema, []byte(schemaYAML), 0644))
+
+	output := filepath.Join(dir, "out.csv")
+	require.NoError(t, runGenerate(t, "--schema", schema, "-e", output))
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "id,country\n1,BR\n2,BR\n3,BR\n", string(data))
+
+	require.NoError(t, runGenerate(t, "--schema", schema, "-n", "1", "-e", output))
+	data, err = os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "id,country\n1,BR\n", string(data), "--rows overrides the schema")
+}
+
+func TestGenerate_Like(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "orders.csv")
+	require.NoError(t, os.WriteFile(input, []byte("id,status,amount\n1,paid,10\n2,paid,20\n3,open,15\n4,paid,12\n"), 0644))
+
+	schema := filepath.Join(dir, "orders.yaml")
+	first, second := filepath.Join(dir, "a.jsonl"), filepath.Join(dir, "b.jsonl")
+	require.NoError(t, runGenerate(t, "--like", input, "-n", "50", "-e", first, "--save-schema", schema, "--seed", "3"))
+	require.NoError(t, runGenerate(t, "--schema", schema, "-n", "50", "-e", second, "--seed", "3"))
+
+	a, err := os.ReadFile(first)
+	require.NoError(t, err)
+	b, err := os.ReadFile(second)
+	require.NoError(t, err)
+	assert.Equal(t, string(a), string(b), "the saved schema generates the same rows")
+	assert.Len(t, strings.Split(strings.TrimSpace(string(a)), "\n"), 50)
+
+	saved, err := os.ReadFile(schema)
+	require.NoError(t, err)
+	assert.Contains(t, string(saved), "type: choice")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "schema.yaml")
+	require.NoError(t, os.WriteFile(schema, []byte(schemaYAML), 0644))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("columns: [{name: a, type: money}]"), 0644))
+	output := filepath.Join(dir, "out.csv")
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no source", []string{"-e", output}},
+		{"schema and like", []string{"--schema", schema, "--like", schema, "-e", output}},
+		{"no output", []string{"--schema", schema}},
+		{"invalid schema", []string{"--schema", invalid, "-e", output}},
+		{"missing schema", []string{"--schema", filepath.Join(dir, "missing.yaml"), "-e", output}},
+		{"no format", []string{"--schema", schema, "-e", filepath.Join(dir, "out")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, runGenerate(t, tt.args...))
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package historyctl
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrianolaselva/dataql/internal/dataql"
+	"github.com/adrianolaselva/dataql/pkg/history"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	dbParam          = "db"
+	limitParam       = "limit"
+	limitShortParam  = "n"
+	exportParam      = "export"
+	exportShortParam = "e"
+	typeParam        = "type"
+	typeShortParam   = "t"
+
+	// maxQueryWidth truncates long queries in the listings
+	maxQueryWidth = 80
+)
+
+// HistoryCtl is the interface for the history controller
+type HistoryCtl interface {
+	Command() *cobra.Command
+}
+
+type historyCtl struct {
+	db string
+}
+
+// New creates a new HistoryCtl instance
+func New() HistoryCtl {
+	return &historyCtl{}
+}
+
+// Command returns the cobra command for the history subcommand
+func (c *historyCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "history",
+		Short: "List, search and rerun past queries",
+		Long: `Recall the statements executed by dataql run, with -q or in the REPL.
+
+Every statement is recorded with its time, sources, duration and row count
+in ~/.dataql/history.db (or $` + history.EnvPath + `). Pass --no-history to
+dataql run, or set $` + history.EnvDisable + `, to keep statements out of the
+history. URL passwords of the sources are never recorded.`,
+		Example: `  dataql history list
+  dataql history search "FROM sales"
+  dataql history rerun 42
+  dataql history rerun 42 -e result.csv -t csv`,
+	}
+
+	command.PersistentFlags().StringVar(&c.db, dbParam, "", "history database (default: ~/.dataql/history.db)")
+
+	command.AddCommand(c.listCommand())
+	command.AddCommand(c.searchCommand())
+	command.AddCommand(c.rerunCommand())
+	command.AddCommand(c.clearCommand())
+
+	return command
+}
+
+func (c *historyCtl) listCommand() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the latest queries",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			store, err := history.Open(c.db)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			entries, err := store.List(limit)
+			if err != nil {
+				return err
+			}
+			printEntries(entries)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, limitParam, limitShortParam, 20, "number of queries to show (0: all)")
+
+	return cmd
+}
+
+func (c *historyCtl) searchCommand() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "search <text>",
+		Short: "Find queries by their text or sources",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			store, err := history.Open(c.db)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			entries, err := store.Search(args[0], limit)
+			if err != nil {
+				return err
+			}
+			printEntries(entries)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, limitParam, limitShortParam, 20, "number of queries to show (0: all)")
+
+	return cmd
+}
+
+func (c *historyCtl) rerunCommand() *cobra.Command {
+	var export, exportType string
+
+	cmd := &cobra.Command{
+		Use:   "rerun <id>",
+		Short: "Run a past query again on its sources",
+		Long: `Import the sources of a recorded query again and run it, printing the result
+or exporting it with --export.
+
+Relative sources are resolved against the directory the query was run from.
+Queries over stdin, or over URLs whose password was redacted, cannot be rerun.
+A REPL statement runs on its own, without the statements before it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid history id %q", args[0])
+			}
+
+			store, err := history.Open(c.db)
+			if err != nil {
+				return err
+			}
+			entry, err := store.Get(id)
+			_ = store.Close()
+			if err != nil {
+				return err
+			}
+
+			params, err := rerunParams(entry)
+			if err != nil {
+				return err
+			}
+			params.Export = export
+			params.Type = exportType
+			if os.Getenv(history.EnvDisable) == "" {
+				params.History = c.db
+				if params.History == "" {
+					params.History, _ = history.DefaultPath()
+				}
+			}
+
+			fmt.Fprintf(os.Stderr, "Rerunning #%d: %s\n", entry.ID, entry.Query)
+			return run(params)
+		},
+	}
+
+	cmd.Flags().StringVarP(&export, exportParam, exportShortParam, "", "export the result to this path instead of printing it")
+	cmd.Flags().StringVarP(&exportType, typeParam, typeShortParam, "", "export format type [`jsonl`,`csv`]")
+
+	return cmd
+}
+
+func (c *historyCtl) clearCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove every query from the history",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			store, err := history.Open(c.db)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			removed, err := store.Clear()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Removed %d queries from the history\n", removed)
+			return nil
+		},
+	}
+}
+
+// rerunParams rebuilds the run parameters of a recorded query
+func rerunParams(entry history.Entry) (dataql.Params, error) {
+	params := dataql.Params{
+		Delimiter:  entry.Delimiter,
+		Collection: entry.Collection,
+		Query:      entry.Query,
+		Quiet:      true,
+	}
+	if params.Delimiter == "" {
+		params.Delimiter = ","
+	}
+
+	for _, source := range entry.Sources {
+		if source == "-" {
+			return dataql.Params{}, fmt.Errorf("query #%d read stdin and cannot be rerun", entry.ID)
+		}
+		if redacted(source) {
+			return dataql.Params{}, fmt.Errorf("query #%d reads %s whose password is not recorded; run it again with dataql run", entry.ID, source)
+		}
+		params.FileInputs = append(params.FileInputs, resolve(entry.Directory, source))
+	}
+	if entry.Storage != "" {
+		params.DataSourceName = resolve(entry.Directory, entry.Storage)
+	}
+
+	if len(params.FileInputs) == 0 && params.DataSourceName == "" {
+		return dataql.Params{}, fmt.Errorf("query #%d has no sources to rerun it on", entry.ID)
+	}
+	return params, nil
+}
+
+// run executes the parameters the way dataql run does
+func run(params dataql.Params) error {
+	if len(params.FileInputs) == 0 {
+		dql, err := dataql.NewStorageOnly(params)
+		if err != nil {
+			return fmt.Errorf("failed to initialize dataql: %w", err)
+		}
+		defer dql.Close()
+		return dql.RunStorageOnly()
+	}
+
+	dql, err := dataql.New(params)
+	if err != nil {
+		return fmt.Errorf("failed to initialize dataql: %w", err)
+	}
+	defer dql.Close()
+	return dql.Run()
+}
+
+// resolve makes a relative local source absolute against dir
+func resolve(dir, source string) string {
+	if dir == "" || strings.Contains(source, "://") || filepath.IsAbs(source) {
+		return source
+	}
+	return filepath.Join(dir, source)
+}
+
+// redacted reports whether the password of a URL source was hidden when recorded
+func redacted(source string) bool {
+	if !strings.Contains(source, "://") {
+		return false
+	}
+	u, err := url.Parse(source)
+	if err != nil || u.User == nil {
+		return false
+	}
+	password, ok := u.User.Password()
+	return ok && password == "xxxxx"
+}
+
+// printEntries prints history entries as a table
+func printEntries(entries []history.Entry) {
+	if len(entries) == 0 {
+		fmt.Println("No queries found.")
+		return
+	}
+
+	tbl := table.New("ID", "Time", "Caller", "Duration", "Rows", "Sources", "Query").
+		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+		WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+		WithWriter(os.Stdout)
+
+	for _, entry := range entries {
+		rows := strconv.FormatInt(entry.Rows, 10)
+		if entry.Error != "" {
+			rows = "error"
+		}
+		sources := strings.Join(entry.Sources, ", ")
+		if sources == "" {
+			sources = entry.Storage
+		}
+		duration := time.Duration(entry.DurationMs * float64(time.Millisecond)).Round(time.Millisecond)
+		tbl.AddRow(entry.ID, entry.Timestamp.Local().Format("2006-01-02 15:04:05"), entry.Caller, duration, rows,
+			sources, shorten(entry.Query))
+	}
+
+	tbl.Print()
+}
+
+// shorten puts a query on a single line and truncates it
+func shorten(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxQueryWidth {
+		return query[:maxQueryWidth-3] + "..."
+	}
+	return query
+}
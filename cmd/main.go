@@ -2,13 +2,35 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 
+	"github.com/adrianolaselva/dataql/cmd/authctl"
+	"github.com/adrianolaselva/dataql/cmd/benchmarkctl"
 	"github.com/adrianolaselva/dataql/cmd/cachectl"
+	"github.com/adrianolaselva/dataql/cmd/convertctl"
 	"github.com/adrianolaselva/dataql/cmd/dataqlctl"
+	"github.com/adrianolaselva/dataql/cmd/dedupctl"
 	"github.com/adrianolaselva/dataql/cmd/describectl"
+	"github.com/adrianolaselva/dataql/cmd/diffctl"
+	"github.com/adrianolaselva/dataql/cmd/encryptionctl"
+	"github.com/adrianolaselva/dataql/cmd/extctl"
+	"github.com/adrianolaselva/dataql/cmd/generatectl"
+	"github.com/adrianolaselva/dataql/cmd/historyctl"
+	"github.com/adrianolaselva/dataql/cmd/maskctl"
 	"github.com/adrianolaselva/dataql/cmd/mcpctl"
+	"github.com/adrianolaselva/dataql/cmd/mergectl"
+	"github.com/adrianolaselva/dataql/cmd/modelsctl"
+	"github.com/adrianolaselva/dataql/cmd/mvctl"
+	"github.com/adrianolaselva/dataql/cmd/pipelinectl"
+	"github.com/adrianolaselva/dataql/cmd/pluginctl"
+	"github.com/adrianolaselva/dataql/cmd/queryctl"
+	"github.com/adrianolaselva/dataql/cmd/schedulectl"
+	"github.com/adrianolaselva/dataql/cmd/schemactl"
+	"github.com/adrianolaselva/dataql/cmd/servectl"
 	"github.com/adrianolaselva/dataql/cmd/skillsctl"
+	"github.com/adrianolaselva/dataql/cmd/splitctl"
 	"github.com/adrianolaselva/dataql/internal/dataql"
+	"github.com/adrianolaselva/dataql/pkg/clierror"
 	"github.com/spf13/cobra"
 )
 
@@ -26,10 +48,12 @@ const (
 
 type CliBase interface {
 	Execute() error
+	WriteError(w io.Writer, err error)
 }
 
 type cliBase struct {
-	rootCmd *cobra.Command
+	rootCmd     *cobra.Command
+	errorFormat *errorFormat
 }
 
 func New() CliBase {
@@ -53,7 +77,15 @@ func New() CliBase {
 		},
 	}
 
-	return &cliBase{rootCmd: cmd}
+	// Flag errors are errors of the command line, like invalid arguments
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return clierror.Parse(err)
+	})
+
+	errorFormat := newErrorFormat(cmd)
+	cmd.PersistentFlags().Var(errorFormat, errorFormatParam, "format of the error printed on failure: text or json (default: $"+clierror.EnvFormat+" or text)")
+
+	return &cliBase{rootCmd: cmd, errorFormat: errorFormat}
 }
 
 func (c *cliBase) Execute() error {
@@ -71,6 +103,45 @@ func (c *cliBase) Execute() error {
 	}
 	c.rootCmd.AddCommand(describeCmd)
 
+	// Add convert command for format conversion without SQL
+	c.rootCmd.AddCommand(convertctl.New().Command())
+
+	// Add merge command to concatenate files
+	c.rootCmd.AddCommand(mergectl.New().Command())
+
+	// Add split command for chunked output
+	c.rootCmd.AddCommand(splitctl.New().Command())
+
+	// Add dedup command
+	c.rootCmd.AddCommand(dedupctl.New().Command())
+
+	// Add mask command for PII anonymization
+	c.rootCmd.AddCommand(maskctl.New().Command())
+
+	// Add generate command for synthetic data
+	c.rootCmd.AddCommand(generatectl.New().Command())
+
+	// Add benchmark command
+	c.rootCmd.AddCommand(benchmarkctl.New().Command())
+
+	// Add history command to recall past queries
+	c.rootCmd.AddCommand(historyctl.New().Command())
+
+	// Add saved queries command
+	c.rootCmd.AddCommand(queryctl.New().Command())
+
+	// Add plugin command for external format readers
+	c.rootCmd.AddCommand(pluginctl.New().Command())
+
+	// Add materialized views command for persistent storage
+	c.rootCmd.AddCommand(mvctl.New().Command())
+
+	// Add diff command to compare datasets
+	c.rootCmd.AddCommand(diffctl.New().Command())
+
+	// Add schema command to infer and export schemas
+	c.rootCmd.AddCommand(schemactl.New().Command())
+
 	// Add skills command for Claude Code integration
 	c.rootCmd.AddCommand(skillsctl.New().Command())
 
@@ -80,9 +151,37 @@ func (c *cliBase) Execute() error {
 	// Add cache management command
 	c.rootCmd.AddCommand(cachectl.New().Command())
 
+	// Add encryption command for storage and cache files at rest
+	c.rootCmd.AddCommand(encryptionctl.New().Command())
+
+	// Add DuckDB extension management
+	c.rootCmd.AddCommand(extctl.New().Command())
+
+	// Add connection credentials stored in the OS keyring
+	c.rootCmd.AddCommand(authctl.New().Command())
+
+	// Add REST API server and web UI commands
+	c.rootCmd.AddCommand(servectl.New().Command())
+	c.rootCmd.AddCommand(servectl.NewUI().Command())
+
+	// Add scheduler for recurring query and export jobs
+	c.rootCmd.AddCommand(schedulectl.New().Command())
+
+	// Add multi-step ETL pipelines defined in YAML
+	c.rootCmd.AddCommand(pipelinectl.New().Command())
+
+	// Add SQL model builds with dependency resolution
+	c.rootCmd.AddCommand(modelsctl.New().Command())
+
 	if err := c.rootCmd.Execute(); err != nil {
 		return fmt.Errorf("failed to execute command %w", err)
 	}
 
 	return nil
 }
+
+// WriteError prints the error that stopped the command in the format of
+// --error-format
+func (c *cliBase) WriteError(w io.Writer, err error) {
+	_ = clierror.Write(w, c.errorFormat.value, err)
+}
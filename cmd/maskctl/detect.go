@@ -0,0 +1,146 @@
+package maskctl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/mask"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// detection is a column that probably holds personal data
+type detection struct {
+	mask.Rule
+	Reason string
+}
+
+// namePatterns match column names holding personal data, checked in order
+var namePatterns = []struct {
+	pattern *regexp.Regexp
+	method  string
+	reason  string
+}{
+	{regexp.MustCompile(`(^|_)e_?mail(_|$)|(^|_)mail(_address)?$`), mask.MethodFakeEmail, "e-mail column name"},
+	{regexp.MustCompile(`(^|_)(birth|dob|birthday|birthdate|date_of_birth)(_|$)`), mask.MethodShiftDates, "birth date column name"},
+	{regexp.MustCompile(`(^|_)(ssn|cpf|cnpj|nif|nino|passport|tax_id|national_id|document_number|driver_license)(_|$)`), mask.MethodHash, "national identifier column name"},
+	{regexp.MustCompile(`(^|_)(phone|mobile|cellphone|telephone|tel|fax|whatsapp)(_|$)`), mask.MethodRedact, "phone column name"},
+	{regexp.MustCompile(`(^|_)(first|last|middle|full|given|family|maiden)_?name(_|$)|^(name|surname|customer_name|contact_name)$`), mask.MethodRedact, "person name column name"},
+	{regexp.MustCompile(`(^|_)ip(_address|_addr)?$`), mask.MethodHash, "IP address column name"},
+	{regexp.MustCompile(`(^|_)(address|street|addr|zip|zipcode|postal|postcode|postal_code)(_|$)`), mask.MethodRedact, "address column name"},
+	{regexp.MustCompile(`(^|_)(card_number|credit_card|cc_number|iban|account_number|cvv)(_|$)`), mask.MethodRedact, "payment column name"},
+	{regexp.MustCompile(`(^|_)(password|passwd|secret|api_key|token)(_|$)`), mask.MethodRedact, "credential column name"},
+}
+
+// valuePatterns match the values of text columns holding personal data
+var valuePatterns = []struct {
+	regex  string // DuckDB regular expression (without quotes) matched against whole values
+	method string
+	reason string
+}{
+	{`[^@\s]+@[^@\s]+\.[A-Za-z]{2,}`, mask.MethodFakeEmail, "values look like e-mail addresses"},
+	{`\d{3}\.\d{3}\.\d{3}-\d{2}|\d{3}-\d{2}-\d{4}`, mask.MethodHash, "values look like national identifiers"},
+	{`(\d{1,3}\.){3}\d{1,3}`, mask.MethodHash, "values look like IP addresses"},
+	{`\+\d[\d\s().-]{7,18}|\(\d{2,3}\)\s?[\d\s-]{7,12}`, mask.MethodRedact, "values look like phone numbers"},
+}
+
+const (
+	// sampleRows is the number of non-null values checked per column
+	sampleRows = 1000
+	// matchShare is the share of sampled values that must match a value pattern
+	matchShare = 0.8
+)
+
+var nameSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// detect returns the columns of a table that probably hold personal data,
+// by column name or, for text columns, by the content of a sample of values.
+// It is a starting point to review, not a guarantee: free-text columns can
+// hold personal data no heuristic finds.
+func detect(ctx context.Context, db *dataql.DB, table dataql.Table) ([]detection, error) {
+	var detections []detection
+	for _, column := range table.Columns {
+		if found, ok := detectByName(column.Name); ok {
+			detections = append(detections, found)
+			continue
+		}
+		if !strings.EqualFold(column.Type, "VARCHAR") {
+			continue
+		}
+
+		found, ok, err := detectByValues(ctx, db, table.Name, column.Name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			detections = append(detections, found)
+		}
+	}
+	return detections, nil
+}
+
+// detectByName matches a column name, split in lower-case words
+func detectByName(column string) (detection, bool) {
+	name := strings.Trim(nameSeparators.ReplaceAllString(strings.ToLower(splitCamelCase(column)), "_"), "_")
+	for _, p := range namePatterns {
+		if p.pattern.MatchString(name) {
+			return detection{Rule: mask.Rule{Column: column, Method: p.method}, Reason: p.reason}, true
+		}
+	}
+	return detection{}, false
+}
+
+// detectByValues checks the share of sampled values matching each value pattern
+func detectByValues(ctx context.Context, db *dataql.DB, table, column string) (detection, bool, error) {
+	selects := make([]string, len(valuePatterns))
+	for i, p := range valuePatterns {
+		selects[i] = fmt.Sprintf("avg(CASE WHEN regexp_full_match(trim(v), '%s') THEN 1.0 ELSE 0.0 END)", p.regex)
+	}
+	query := fmt.Sprintf("SELECT %s FROM (SELECT CAST(%s AS VARCHAR) AS v FROM %s WHERE %s IS NOT NULL AND trim(CAST(%s AS VARCHAR)) <> '' LIMIT %d)",
+		strings.Join(selects, ", "), storage.QuoteIdentifier(column), storage.QuoteIdentifier(table), storage.QuoteIdentifier(column), storage.QuoteIdentifier(column), sampleRows)
+
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return detection{}, false, fmt.Errorf("failed to sample %s: %w", column, err)
+	}
+	defer rows.Close()
+
+	shares := make([]*float64, len(valuePatterns))
+	if rows.Next() {
+		dest := make([]any, len(shares))
+		for i := range shares {
+			dest[i] = &shares[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return detection{}, false, fmt.Errorf("failed to sample %s: %w", column, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return detection{}, false, err
+	}
+
+	for i, p := range valuePatterns {
+		if shares[i] != nil && *shares[i] >= matchShare {
+			return detection{Rule: mask.Rule{Column: column, Method: p.method}, Reason: p.reason}, true, nil
+		}
+	}
+	return detection{}, false, nil
+}
+
+// splitCamelCase inserts an underscore between a lower-case letter or digit
+// and an upper-case letter, so "firstName" is read as "first_name"
+func splitCamelCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			prev := name[i-1]
+			if (prev >= 'a' && prev <= 'z') || (prev >= '0' && prev <= '9') {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,208 @@
+package maskctl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/mask"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	maskParam               = "mask"
+	maskShortParam          = "m"
+	detectParam             = "detect"
+	saltParam               = "salt"
+	shiftDaysParam          = "shift-days"
+	exportParam             = "export"
+	exportShortParam        = "e"
+	typeParam               = "type"
+	typeShortParam          = "t"
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	tableParam              = "table"
+)
+
+// MaskCtl is the interface for the mask controller
+type MaskCtl interface {
+	Command() *cobra.Command
+}
+
+type maskCtl struct {
+	masks      []string
+	detect     bool
+	salt       string
+	shiftDays  int
+	export     string
+	exportType string
+	delimiter  string
+	table      string
+}
+
+// New creates a new MaskCtl instance
+func New() MaskCtl {
+	return &maskCtl{}
+}
+
+// Command returns the cobra command for the mask subcommand
+func (c *maskCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "mask <input>",
+		Short: "Anonymize personal data columns",
+		Long: `Write a copy of a data source with personal data masked, so production
+extracts can be shared with developers or LLMs.
+
+Columns are masked with --mask column=method. Without --mask, columns that
+look like personal data (by name, or by the values of text columns) are
+detected and masked; --detect only lists them. Review the detection: free
+text can hold personal data no heuristic finds.
+
+Methods:
+
+  hash         SHA-256 of the salted value; equal values stay equal, so keys still join
+  redact       the text REDACTED
+  fake-email   user_<hash>@example.com, stable for the same address
+  shift-dates  dates moved by the same number of days, keeping intervals between them
+  null         NULL
+
+Hashes and the date shift are reproducible with the same --salt (default:
+$` + mask.EnvSalt + `); without one, a random salt is generated and printed.`,
+		Example: `  dataql mask customers.csv --detect
+  dataql mask customers.csv -e customers_masked.csv
+  dataql mask orders.parquet -e dev.parquet -m email=fake-email -m customer_id=hash -m birth_date=shift-dates`,
+		Args: cobra.ExactArgs(1),
+		RunE: c.runE,
+	}
+
+	command.Flags().StringArrayVarP(&c.masks, maskParam, maskShortParam, []string{}, "column=method to mask (hash, redact, fake-email, shift-dates, null); repeatable")
+	command.Flags().BoolVar(&c.detect, detectParam, false, "only list the columns detected as personal data")
+	command.Flags().StringVar(&c.salt, saltParam, "", "salt of hashes and date shift (default: $"+mask.EnvSalt+", or a random salt)")
+	command.Flags().IntVar(&c.shiftDays, shiftDaysParam, 0, "days added to dates by shift-dates (default: derived from the salt)")
+	command.Flags().StringVarP(&c.export, exportParam, exportShortParam, "", "output file")
+	command.Flags().StringVarP(&c.exportType, typeParam, typeShortParam, "", "output format (default: from the output file extension)")
+	command.Flags().StringVarP(&c.delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter of the input")
+	command.Flags().StringVar(&c.table, tableParam, "", "table to mask when the input holds several")
+
+	return command
+}
+
+func (c *maskCtl) runE(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	if !c.detect && c.export == "" {
+		return fmt.Errorf("--%s is required (or use --%s to list personal data columns)", exportParam, detectParam)
+	}
+	rules, err := mask.ParseRules(c.masks)
+	if err != nil {
+		return err
+	}
+
+	db, err := dataql.OpenWithOptions(dataql.Options{Delimiter: c.delimiter}, args[0])
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	source, err := db.SourceTable(c.table)
+	if err != nil {
+		return err
+	}
+
+	var detections []detection
+	if c.detect || len(rules) == 0 {
+		if detections, err = detect(cmd.Context(), db, source); err != nil {
+			return err
+		}
+	}
+	if c.detect {
+		printDetections(os.Stdout, source.Name, detections)
+		return nil
+	}
+
+	if len(rules) == 0 {
+		if len(detections) == 0 {
+			return fmt.Errorf("no personal data columns detected in %s; choose columns with --%s column=method", source.Name, maskParam)
+		}
+		for _, found := range detections {
+			rules = append(rules, found.Rule)
+		}
+	} else {
+		detections = detectionsFor(rules)
+	}
+	if err := checkColumns(source, rules); err != nil {
+		return err
+	}
+
+	salt, generated, err := mask.ResolveSalt(c.salt)
+	if err != nil {
+		return err
+	}
+	if generated {
+		fmt.Fprintf(os.Stderr, "Masking with the generated salt %s; pass --%s %s to reproduce it\n", salt, saltParam, salt)
+	}
+	opts := mask.NewOptions(salt)
+	if c.shiftDays != 0 {
+		opts.ShiftDays = c.shiftDays
+	}
+
+	query, err := mask.Wrap("SELECT * FROM "+storage.QuoteIdentifier(source.Name), rules, opts)
+	if err != nil {
+		return err
+	}
+	if _, err := db.Export(cmd.Context(), query, c.export, c.exportType); err != nil {
+		return err
+	}
+
+	printDetections(os.Stdout, source.Name, detections)
+	fmt.Printf("\nMasked %d column(s) written to %s\n", len(rules), c.export)
+	return nil
+}
+
+// detectionsFor describes the explicit rules for the report
+func detectionsFor(rules []mask.Rule) []detection {
+	detections := make([]detection, len(rules))
+	for i, rule := range rules {
+		detections[i] = detection{Rule: rule, Reason: "--" + maskParam}
+	}
+	return detections
+}
+
+// checkColumns fails on rules for columns missing from the table
+func checkColumns(source dataql.Table, rules []mask.Rule) error {
+	for _, rule := range rules {
+		found := false
+		for _, column := range source.Columns {
+			if strings.EqualFold(column.Name, rule.Column) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("column %q not found in %s", rule.Column, source.Name)
+		}
+	}
+	return nil
+}
+
+// printDetections lists the masked (or detected) columns
+func printDetections(w io.Writer, tableName string, detections []detection) {
+	if len(detections) == 0 {
+		fmt.Fprintf(w, "No personal data columns detected in %s\n", tableName)
+		return
+	}
+
+	tbl := table.New("Column", "Method", "Reason").
+		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+		WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+		WithWriter(w)
+	for _, found := range detections {
+		tbl.AddRow(found.Column, found.Method, found.Reason)
+	}
+	tbl.Print()
+}
@@ -0,0 +1,66 @@
+package maskctl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/mask"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const customersCSV = `id,firstName,contact,birth_date,server,note
+1,Ana,ana@example.org,1990-05-01,10.0.0.1,likes tea
+2,Bob,bob@example.net,1985-11-23,10.0.0.2,
+`
+
+func TestDetect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "customers.csv")
+	require.NoError(t, os.WriteFile(path, []byte(customersCSV), 0644))
+
+	db, err := dataql.Open(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	tables, err := db.Tables()
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+
+	detections, err := detect(context.Background(), db, tables[0])
+	require.NoError(t, err)
+
+	methods := make(map[string]string)
+	for _, found := range detections {
+		methods[found.Column] = found.Method
+	}
+	assert.Equal(t, map[string]string{
+		"firstName":  mask.MethodRedact,
+		"contact":    mask.MethodFakeEmail,
+		"birth_date": mask.MethodShiftDates,
+		"server":     mask.MethodHash,
+	}, methods)
+}
+
+func TestDetectByName(t *testing.T) {
+	tests := map[string]string{
+		"Email":          mask.MethodFakeEmail,
+		"customer_email": mask.MethodFakeEmail,
+		"DateOfBirth":    mask.MethodShiftDates,
+		"cpf":            mask.MethodHash,
+		"mobile_phone":   mask.MethodRedact,
+		"last_name":      mask.MethodRedact,
+		"postal_code":    mask.MethodRedact,
+		"ip_address":     mask.MethodHash,
+		"product_name":   "",
+		"shipping_cost":  "",
+		"zipper_color":   "",
+	}
+	for name, method := range tests {
+		found, ok := detectByName(name)
+		assert.Equal(t, method != "", ok, name)
+		assert.Equal(t, method, found.Method, name)
+	}
+}
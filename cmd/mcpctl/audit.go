@@ -0,0 +1,92 @@
+package mcpctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/auditlog"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// auditLog records the tool calls of the running server (set by serve --audit-log)
+var auditLog *auditlog.Logger
+
+// auditKey is the context key of the auditRecord of a tool call
+type auditKey struct{}
+
+// auditRecord collects the statement a tool call ran and how many rows it returned
+type auditRecord struct {
+	query string
+	rows  int64
+}
+
+// auditMiddleware writes an audit log entry for every tool call
+func auditMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if auditLog == nil {
+			return next(ctx, request)
+		}
+
+		record := &auditRecord{query: getStringArg(request, "query")}
+		start := time.Now()
+		result, err := next(context.WithValue(ctx, auditKey{}, record), request)
+
+		entry := auditlog.Entry{
+			Timestamp:  start,
+			Caller:     auditlog.CallerMCP + ":" + request.Params.Name,
+			Sources:    auditlog.RedactSources(toolSources(request)),
+			Query:      record.query,
+			Rows:       record.rows,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		} else if result != nil && result.IsError {
+			entry.Error = toolError(result)
+		}
+
+		if logErr := auditLog.Log(entry); logErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", logErr)
+		}
+		return result, err
+	}
+}
+
+// recordQuery stores the statement a tool ran and the rows it returned in
+// the audit record of the call, if the call is audited
+func recordQuery(ctx context.Context, query string, rows int) {
+	if record, ok := ctx.Value(auditKey{}).(*auditRecord); ok {
+		record.query = query
+		record.rows = int64(rows)
+	}
+}
+
+// toolSources returns the sources a tool call reads: its source or sources
+// argument, or the sources of the session it uses
+func toolSources(request mcp.CallToolRequest) []string {
+	if sources := getStringArrayArg(request, "sources"); len(sources) > 0 {
+		return sources
+	}
+	if source := getStringArg(request, "source"); source != "" {
+		return []string{source}
+	}
+	if sessionID := getStringArg(request, "session_id"); sessionID != "" {
+		if sess, err := sessions.get(sessionID); err == nil {
+			return sess.sources
+		}
+	}
+	return nil
+}
+
+// toolError returns the message of a failed tool result
+func toolError(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package mcpctl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/auditlog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAuditLog decodes the entries of a JSONL audit log
+func readAuditLog(t *testing.T, path string) []auditlog.Entry {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var entries []auditlog.Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry auditlog.Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	logger, err := auditlog.New(path)
+	require.NoError(t, err)
+	auditLog = logger
+	defer func() {
+		auditLog = nil
+		_ = logger.Close()
+	}()
+
+	query := auditMiddleware(handleQuery)
+	preview := auditMiddleware(handlePreview)
+
+	result, err := query(context.Background(), callTool("dataql_query", map[string]interface{}{
+		"source": simpleFixture,
+		"query":  "SELECT * FROM simple",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	result, err = query(context.Background(), callTool("dataql_query", map[string]interface{}{
+		"source": simpleFixture,
+		"query":  "DELETE FROM simple",
+	}))
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+
+	_, err = preview(context.Background(), callTool("dataql_preview", map[string]interface{}{
+		"source": simpleFixture,
+		"limit":  float64(2),
+	}))
+	require.NoError(t, err)
+
+	entries := readAuditLog(t, path)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "mcp:dataql_query", entries[0].Caller)
+	assert.Equal(t, []string{simpleFixture}, entries[0].Sources)
+	assert.Equal(t, "SELECT * FROM simple", entries[0].Query)
+	assert.Equal(t, int64(3), entries[0].Rows)
+	assert.Empty(t, entries[0].Error)
+
+	assert.Equal(t, "DELETE FROM simple", entries[1].Query)
+	assert.Contains(t, entries[1].Error, "read-only")
+
+	// Tools that build their own statement record the statement they ran
+	assert.Equal(t, "mcp:dataql_preview", entries[2].Caller)
+	assert.Equal(t, "SELECT * FROM simple LIMIT 2", entries[2].Query)
+	assert.Equal(t, int64(2), entries[2].Rows)
+}
+
+func TestAuditMiddleware_Disabled(t *testing.T) {
+	result, err := auditMiddleware(handleQuery)(context.Background(), callTool("dataql_query", map[string]interface{}{
+		"source": simpleFixture,
+		"query":  "SELECT COUNT(*) AS total FROM simple",
+	}))
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+}
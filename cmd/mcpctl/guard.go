@@ -0,0 +1,47 @@
+package mcpctl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/sqlguard"
+)
+
+// allowWrites disables the read-only guard (set by serve --allow-writes)
+var allowWrites bool
+
+// readOnlyCommands are the REPL dot-commands that only read data
+var readOnlyCommands = map[string]bool{
+	"\\d": true, ".tables": true,
+	"\\dt": true, ".schema": true,
+	"\\dt+": true, "\\ds": true, ".describe": true,
+	"\\c": true, ".count": true,
+	".sample": true,
+	".diff":   true,
+}
+
+// checkReadOnly rejects queries that could modify data or the environment,
+// unless the server was started with --allow-writes
+func checkReadOnly(query string) error {
+	if allowWrites {
+		return nil
+	}
+
+	if isREPLCommand(query) {
+		command := strings.ToLower(strings.Fields(query)[0])
+		if !readOnlyCommands[command] {
+			return readOnlyError(fmt.Sprintf("command %s is not allowed", command))
+		}
+		return nil
+	}
+
+	if err := sqlguard.CheckReadOnly(query); err != nil {
+		return readOnlyError(err.Error())
+	}
+
+	return nil
+}
+
+func readOnlyError(reason string) error {
+	return fmt.Errorf("query rejected: %s (the MCP server is read-only; start it with --allow-writes to permit changes)", reason)
+}
@@ -0,0 +1,82 @@
+package mcpctl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	tests := []struct {
+		query   string
+		allowed bool
+	}{
+		{"SELECT * FROM users", true},
+		{"  select count(*) from users;", true},
+		{"WITH t AS (SELECT 1) SELECT * FROM t", true},
+		{"FROM users LIMIT 5", true},
+		{"(SELECT 1) UNION (SELECT 2)", true},
+		{"DESCRIBE users", true},
+		{"EXPLAIN SELECT * FROM users", true},
+		{"SELECT 'DROP TABLE users' AS text", true},
+		{`SELECT "delete" FROM users`, true},
+		{"SELECT 1 -- DELETE FROM users", true},
+		{"SELECT /* DROP */ 1", true},
+		{"SELECT replace(name, 'a', 'b') FROM users", true},
+		{".schema users", true},
+		{"\\d", true},
+		{"DROP TABLE users", false},
+		{"delete from users", false},
+		{"SELECT 1; DROP TABLE users", false},
+		{"WITH t AS (SELECT 1) INSERT INTO users SELECT * FROM t", false},
+		{"EXPLAIN ANALYZE DELETE FROM users", false},
+		{"COPY users TO 'out.csv'", false},
+		{"INSTALL httpfs", false},
+		{"ATTACH 'other.db'", false},
+		{"SET threads = 1", false},
+		{"PRAGMA enable_profiling", false},
+		{"CREATE TABLE t AS SELECT 1", false},
+		{"BEGIN", false},
+		{".save session.duckdb", false},
+		{".connect postgres://localhost/db", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			err := checkReadOnly(tt.query)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, "--allow-writes")
+			}
+		})
+	}
+}
+
+func TestCheckReadOnly_AllowWrites(t *testing.T) {
+	allowWrites = true
+	defer func() { allowWrites = false }()
+
+	assert.NoError(t, checkReadOnly("DROP TABLE users"))
+}
+
+func TestHandleQuery_RejectsWrites(t *testing.T) {
+	result, err := handleQuery(context.Background(), callTool("dataql_query", map[string]interface{}{
+		"source": simpleFixture,
+		"query":  "DELETE FROM simple",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "--allow-writes")
+
+	result, err = handleAggregate(context.Background(), callTool("dataql_aggregate", map[string]interface{}{
+		"source":    simpleFixture,
+		"column":    "id) FROM simple; DROP TABLE simple; SELECT (1",
+		"operation": "count",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "--allow-writes")
+}
@@ -0,0 +1,134 @@
+package mcpctl
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/httpauth"
+	"github.com/adrianolaselva/dataql/pkg/metrics"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// HTTP endpoints of the MCP server
+const (
+	streamableEndpoint = "/mcp"     // Streamable HTTP transport
+	sseEndpoint        = "/sse"     // SSE transport: event stream
+	messageEndpoint    = "/message" // SSE transport: client messages
+)
+
+// envAuthToken provides the HTTP auth token without exposing it in the process list
+const envAuthToken = "DATAQL_MCP_TOKEN"
+
+// httpOptions configures the HTTP transport
+type httpOptions struct {
+	addr      string
+	authToken string // Required bearer token / API key (empty: no authentication)
+	tlsCert   string
+	tlsKey    string
+}
+
+// shutdownTimeout bounds how long in-flight requests may run after a stop signal
+const shutdownTimeout = 10 * time.Second
+
+// newHTTPHandler routes the streamable HTTP and SSE transports of s, and
+// the Prometheus metrics
+func newHTTPHandler(s *server.MCPServer) http.Handler {
+	sse := server.NewSSEServer(s,
+		server.WithSSEEndpoint(sseEndpoint),
+		server.WithMessageEndpoint(messageEndpoint),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle(streamableEndpoint, server.NewStreamableHTTPServer(s, server.WithEndpointPath(streamableEndpoint)))
+	mux.Handle(sseEndpoint, sse)
+	mux.Handle(messageEndpoint, sse)
+	mux.Handle(metrics.Path, metrics.Default.Handler())
+	return mux
+}
+
+// tlsConfig loads the TLS certificate, or returns nil when TLS is not configured
+func (o httpOptions) tlsConfig() (*tls.Config, error) {
+	if o.tlsCert == "" && o.tlsKey == "" {
+		return nil, nil
+	}
+	if o.tlsCert == "" || o.tlsKey == "" {
+		return nil, fmt.Errorf("both --tls-cert and --tls-key must be provided")
+	}
+
+	cert, err := tls.LoadX509KeyPair(o.tlsCert, o.tlsKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
+
+// serveHTTP serves the MCP server over HTTP until the process is interrupted
+func serveHTTP(ctx context.Context, s *server.MCPServer, opts httpOptions) error {
+	tlsConfig, err := opts.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	handler := newHTTPHandler(s)
+	if opts.authToken != "" {
+		handler = httpauth.RequireToken(opts.authToken, handler)
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: serving MCP over HTTP without authentication; set --auth-token or %s\n", envAuthToken)
+	}
+
+	listener, err := net.Listen("tcp", opts.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", opts.addr, err)
+	}
+
+	srv := &http.Server{
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	go func() {
+		if tlsConfig != nil {
+			// The certificate is already loaded in srv.TLSConfig
+			errCh <- srv.ServeTLS(listener, "", "")
+			return
+		}
+		errCh <- srv.Serve(listener)
+	}()
+
+	// Logs go to stderr: stdout is reserved for the STDIO transport
+	fmt.Fprintf(os.Stderr, "DataQL MCP server listening on %s://%s (streamable HTTP: %s, SSE: %s)\n",
+		scheme, listener.Addr(), streamableEndpoint, sseEndpoint)
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to serve MCP over HTTP: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop MCP HTTP server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package mcpctl
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postJSONRPC sends a JSON-RPC message to the streamable HTTP endpoint and
+// returns the response body and the MCP session id
+func postJSONRPC(t *testing.T, url, sessionID, body string) (string, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url+streamableEndpoint, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(respBody), resp.Header.Get("Mcp-Session-Id")
+}
+
+func TestHTTPHandler_StreamableHTTP(t *testing.T) {
+	srv := httptest.NewServer(newHTTPHandler(newMCPServer()))
+	defer srv.Close()
+
+	body, sessionID := postJSONRPC(t, srv.URL, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	assert.Contains(t, body, `"name":"dataql"`)
+
+	body, _ = postJSONRPC(t, srv.URL, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	assert.Contains(t, body, "dataql_query")
+	assert.Contains(t, body, "dataql_open_session")
+}
+
+func TestHTTPHandler_SSE(t *testing.T) {
+	srv := httptest.NewServer(newHTTPHandler(newMCPServer()))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + sseEndpoint)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/event-stream")
+
+	// The first event tells the client where to post its messages
+	buf := make([]byte, 256)
+	n, err := resp.Body.Read(buf)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf[:n]), messageEndpoint)
+}
+
+func TestHTTPOptions_TLSConfig(t *testing.T) {
+	config, err := httpOptions{}.tlsConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+
+	_, err = httpOptions{tlsCert: "cert.pem"}.tlsConfig()
+	assert.ErrorContains(t, err, "both --tls-cert and --tls-key")
+
+	_, err = httpOptions{tlsCert: "missing.pem", tlsKey: "missing.key"}.tlsConfig()
+	assert.ErrorContains(t, err, "failed to load TLS certificate")
+}
@@ -1,6 +1,7 @@
 package mcpctl
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -10,18 +11,43 @@ import (
 	"strings"
 
 	"github.com/adrianolaselva/dataql/internal/dataql"
+	"github.com/adrianolaselva/dataql/pkg/auditlog"
+	"github.com/adrianolaselva/dataql/pkg/sandbox"
+	"github.com/adrianolaselva/dataql/pkg/storage"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
 )
 
+// exportFormats maps the formats accepted by dataql_export to dataql export types
+var exportFormats = map[string]string{
+	"csv":      "csv",
+	"json":     "json",
+	"jsonl":    "jsonl",
+	"ndjson":   "jsonl",
+	"parquet":  "parquet",
+	"xlsx":     "excel",
+	"excel":    "excel",
+	"xml":      "xml",
+	"yaml":     "yaml",
+	"yml":      "yaml",
+	"markdown": "markdown",
+	"md":       "markdown",
+	"html":     "html",
+}
+
 // McpCtl is the interface for the MCP controller
 type McpCtl interface {
 	Command() *cobra.Command
 }
 
 type mcpCtl struct {
-	debug bool
+	debug          bool
+	maxSessions    int
+	http           httpOptions
+	allowedPaths   []string
+	allowedSchemes []string
+	auditLog       string
 }
 
 // New creates a new McpCtl instance
@@ -55,7 +81,7 @@ func (c *mcpCtl) serveCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Start the MCP server",
-		Long: `Start the MCP server using STDIO transport.
+		Long: `Start the MCP server using STDIO transport, or over HTTP with --http.
 
 Configure in Claude Code (~/.claude/settings.json):
 {
@@ -66,11 +92,31 @@ Configure in Claude Code (~/.claude/settings.json):
       "args": ["mcp", "serve"]
     }
   }
-}`,
+}
+
+Serve remote or containerized clients over HTTP:
+  dataql mcp serve --http :8080
+
+  Streamable HTTP endpoint: http://host:8080/mcp
+  SSE endpoint:             http://host:8080/sse
+  Prometheus metrics:       http://host:8080/metrics
+
+Require a token and TLS for network access:
+  DATAQL_MCP_TOKEN=secret dataql mcp serve --http :8443 --tls-cert cert.pem --tls-key key.pem`,
 		RunE: c.runServe,
 	}
 
 	cmd.Flags().BoolVarP(&c.debug, "debug", "d", false, "Enable debug logging")
+	cmd.Flags().StringVar(&c.http.addr, "http", "", "Serve over HTTP (streamable HTTP and SSE) on this address instead of STDIO, e.g. :8080")
+	cmd.Flags().StringVar(&c.http.authToken, "auth-token", "", "Token HTTP clients must send as 'Authorization: Bearer <token>' or 'X-API-Key' (default: $"+envAuthToken+")")
+	cmd.Flags().StringVar(&c.http.tlsCert, "tls-cert", "", "TLS certificate file for the HTTP transport")
+	cmd.Flags().StringVar(&c.http.tlsKey, "tls-key", "", "TLS private key file for the HTTP transport")
+	cmd.Flags().BoolVar(&allowWrites, "allow-writes", false, "Allow tools to run statements that modify data or the environment (INSERT, CREATE, COPY, ATTACH, ...); by default only read-only queries are accepted")
+	cmd.Flags().StringSliceVar(&c.allowedPaths, "allowed-paths", nil, "Directories the tools may read files from and export to (default: any)")
+	cmd.Flags().StringSliceVar(&c.allowedSchemes, "allowed-schemes", nil, "Source schemes the tools may open, e.g. file,s3,postgres (default: any, or only file when --allowed-paths is set)")
+	cmd.Flags().IntVar(&maxResultBytes, "max-result-bytes", defaultMaxResultBytes, "Truncate tool results larger than this many bytes (0 disables the limit)")
+	cmd.Flags().IntVar(&c.maxSessions, "max-sessions", defaultMaxSessions, "Maximum number of open sessions (least recently used sessions are closed first)")
+	cmd.Flags().StringVar(&c.auditLog, "audit-log", "", "Append every tool call (sources, query, rows, duration) to this JSONL audit log (default: $"+auditlog.EnvPath+")")
 
 	return cmd
 }
@@ -78,15 +124,33 @@ Configure in Claude Code (~/.claude/settings.json):
 func (c *mcpCtl) runServe(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
 
-	// Create MCP server
-	s := server.NewMCPServer(
-		"dataql",
-		"1.0.0",
-		server.WithToolCapabilities(true),
-	)
+	policy, err := sandbox.NewPolicy(c.allowedPaths, c.allowedSchemes)
+	if err != nil {
+		return err
+	}
+	sourcePolicy = policy
 
-	// Register tools
-	registerTools(s)
+	if c.auditLog == "" {
+		c.auditLog = os.Getenv(auditlog.EnvPath)
+	}
+	if c.auditLog != "" {
+		if auditLog, err = auditlog.New(c.auditLog); err != nil {
+			return err
+		}
+		defer auditLog.Close()
+	}
+
+	s := newMCPServer()
+
+	sessions = newSessionManager(c.maxSessions)
+	defer sessions.closeAll()
+
+	if c.http.addr != "" {
+		if c.http.authToken == "" {
+			c.http.authToken = os.Getenv(envAuthToken)
+		}
+		return serveHTTP(cmd.Context(), s, c.http)
+	}
 
 	// Start server with STDIO transport
 	if err := server.ServeStdio(s); err != nil {
@@ -96,19 +160,44 @@ func (c *mcpCtl) runServe(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// newMCPServer creates the MCP server with the dataql tools and resources
+func newMCPServer() *server.MCPServer {
+	s := server.NewMCPServer(
+		"dataql",
+		"1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, false),
+		server.WithToolHandlerMiddleware(auditMiddleware),
+	)
+
+	// Register tools and resources
+	registerTools(s)
+	registerResources(s)
+
+	return s
+}
+
 func registerTools(s *server.MCPServer) {
 	// Tool: dataql_query - Execute SQL queries on data sources
 	s.AddTool(
 		mcp.NewTool("dataql_query",
 			mcp.WithDescription("Execute a SQL query on a data file, URL, or database. Returns query results as JSON."),
 			mcp.WithString("source",
-				mcp.Required(),
-				mcp.Description("Data source: file path (CSV, JSON, Parquet, etc.), URL, S3 URI, or database connection string"),
+				mcp.Description("Data source: file path (CSV, JSON, Parquet, etc.), URL, S3 URI, or database connection string. Required unless session_id is set"),
+			),
+			mcp.WithString("session_id",
+				mcp.Description("Session from dataql_open_session: query its already imported data instead of importing source"),
 			),
 			mcp.WithString("query",
 				mcp.Required(),
 				mcp.Description("SQL query to execute. Use table name derived from filename (e.g., 'users' for users.csv)"),
 			),
+			mcp.WithNumber("max_rows",
+				mcp.Description("Maximum number of rows returned (default: 100, max: 10000). Truncated results say which offset to request next"),
+			),
+			mcp.WithNumber("offset",
+				mcp.Description("Number of rows to skip, for fetching the next page (default: 0)"),
+			),
 			mcp.WithString("delimiter",
 				mcp.Description("CSV delimiter character (default: comma)"),
 			),
@@ -116,6 +205,34 @@ func registerTools(s *server.MCPServer) {
 		handleQuery,
 	)
 
+	// Tool: dataql_open_session - Import sources once for repeated queries
+	s.AddTool(
+		mcp.NewTool("dataql_open_session",
+			mcp.WithDescription("Import one or more data sources once and return a session_id. Pass the session_id to dataql_query or dataql_describe to query the data without importing it again. Use this for large files or when running several queries."),
+			mcp.WithArray("sources",
+				mcp.Required(),
+				mcp.Description("Data sources to import: file paths, URLs, S3 URIs, or database connection strings"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithString("delimiter",
+				mcp.Description("CSV delimiter character (default: comma)"),
+			),
+		),
+		handleOpenSession,
+	)
+
+	// Tool: dataql_close_session - Release a session
+	s.AddTool(
+		mcp.NewTool("dataql_close_session",
+			mcp.WithDescription("Close a session opened with dataql_open_session and release its memory."),
+			mcp.WithString("session_id",
+				mcp.Required(),
+				mcp.Description("Session to close"),
+			),
+		),
+		handleCloseSession,
+	)
+
 	// Tool: dataql_schema - Get schema/structure of a data source
 	s.AddTool(
 		mcp.NewTool("dataql_schema",
@@ -143,6 +260,70 @@ func registerTools(s *server.MCPServer) {
 		handlePreview,
 	)
 
+	// Tool: dataql_sample - Random rows with a reproducible seed
+	s.AddTool(
+		mcp.NewTool("dataql_sample",
+			mcp.WithDescription("Return N random rows of a table instead of the first ones, to see representative data before planning transformations. The same seed always returns the same rows."),
+			mcp.WithString("source",
+				mcp.Description("Data source: file path, URL, S3 URI, or database connection string. Required unless session_id is set"),
+			),
+			mcp.WithString("session_id",
+				mcp.Description("Session from dataql_open_session: sample its already imported data instead of importing source"),
+			),
+			mcp.WithString("table",
+				mcp.Description("Table to sample (default: the table of source, or the only table of the session)"),
+			),
+			mcp.WithNumber("n",
+				mcp.Description("Number of rows to return (default: 10, max: 10000)"),
+			),
+			mcp.WithNumber("seed",
+				mcp.Description("Random seed; change it to get a different sample (default: 42)"),
+			),
+			mcp.WithString("delimiter",
+				mcp.Description("CSV delimiter character (default: comma)"),
+			),
+		),
+		handleSample,
+	)
+
+	// Tool: dataql_validate - Data quality checks
+	s.AddTool(
+		mcp.NewTool("dataql_validate",
+			mcp.WithDescription("Check a table against data quality expectations (required columns, column types, non-null columns, unique keys, value ranges). Returns passed/failed per check with the number of violating rows, for use as a data quality gate."),
+			mcp.WithString("source",
+				mcp.Description("Data source: file path, URL, S3 URI, or database connection string. Required unless session_id is set"),
+			),
+			mcp.WithString("session_id",
+				mcp.Description("Session from dataql_open_session: validate its already imported data instead of importing source"),
+			),
+			mcp.WithString("table",
+				mcp.Description("Table to validate (default: the table of source, or the only table of the session)"),
+			),
+			mcp.WithArray("required_columns",
+				mcp.Description("Columns that must exist"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithObject("column_types",
+				mcp.Description(`Expected SQL type per column, e.g. {"id": "BIGINT", "price": "DECIMAL"}`),
+			),
+			mcp.WithArray("not_null",
+				mcp.Description("Columns that must not contain NULL"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithArray("unique",
+				mcp.Description(`Keys that must be unique; separate the columns of a composite key with commas, e.g. ["id", "order_id,line"]`),
+				mcp.WithStringItems(),
+			),
+			mcp.WithObject("ranges",
+				mcp.Description(`Allowed value range per column (numbers, or strings for dates), e.g. {"age": {"min": 0, "max": 120}}`),
+			),
+			mcp.WithString("delimiter",
+				mcp.Description("CSV delimiter character (default: comma)"),
+			),
+		),
+		handleValidate,
+	)
+
 	// Tool: dataql_aggregate - Common aggregations
 	s.AddTool(
 		mcp.NewTool("dataql_aggregate",
@@ -166,6 +347,82 @@ func registerTools(s *server.MCPServer) {
 		handleAggregate,
 	)
 
+	// Tool: dataql_describe - Column statistics as structured JSON
+	s.AddTool(
+		mcp.NewTool("dataql_describe",
+			mcp.WithDescription("Profile a data source: row count and, per column, type, null %, distinct count, min/max/mean, and most frequent values. Use this to understand a dataset before writing queries."),
+			mcp.WithString("source",
+				mcp.Description("Data source: file path, URL, S3 URI, or database connection string. Required unless session_id is set"),
+			),
+			mcp.WithString("session_id",
+				mcp.Description("Session from dataql_open_session: profile its already imported tables"),
+			),
+			mcp.WithNumber("top_n",
+				mcp.Description("Number of most frequent values returned per column (default: 5, max: 50, 0 to skip)"),
+			),
+			mcp.WithNumber("bins",
+				mcp.Description("Return a histogram with this many equal-width buckets for numeric and date columns (default: 0, no histogram; max: 50)"),
+			),
+			mcp.WithBoolean("completeness",
+				mcp.Description("Count empty strings of text columns apart from NULLs (CSV files import missing text as '') and rows where every column is NULL or empty (default: false)"),
+			),
+			mcp.WithBoolean("cardinality",
+				mcp.Description("Add the uniqueness (distinct values as a percentage of rows) of every column and flag primary-key candidates and constant columns (default: false)"),
+			),
+			mcp.WithString("by",
+				mcp.Description("Add `groups`: the null count, distinct count, min/max and mean of every column per value of this column (the 50 largest groups)"),
+			),
+			mcp.WithBoolean("score",
+				mcp.Description("Add a `quality` scorecard to each table (0-100 for completeness, validity, uniqueness, consistency and their weighted average) and a `quality` rating to each column (default: false)"),
+			),
+			mcp.WithString("score_weights",
+				mcp.Description("Weights of the score dimensions, e.g. \"completeness=2,uniqueness=0\" (implies score; default: 1 each)"),
+			),
+			mcp.WithString("compare",
+				mcp.Description("Baseline source (file path, URL or database) to compare with: adds a `drift` report of added and removed columns to each table and, per column, type changes and a chi-square test of the distribution shift (`shifted` when p < 0.05)"),
+			),
+			mcp.WithNumber("sample",
+				mcp.Description("Profile only the first N rows of source; database sources then read only N rows (default: 0, all rows; ignored with session_id)"),
+			),
+			mcp.WithBoolean("temporal",
+				mcp.Description("Add a temporal report to date and timestamp columns (and text columns holding dates): range, records per day/week/month, gaps without records, and the formats and UTC offsets of text values (default: false)"),
+			),
+			mcp.WithBoolean("outliers",
+				mcp.Description("Report values of numeric columns beyond the IQR fences (Q1 - 1.5*IQR, Q3 + 1.5*IQR) or 3 standard deviations, with up to 5 example rows (default: false)"),
+			),
+			mcp.WithString("delimiter",
+				mcp.Description("CSV delimiter character (default: comma)"),
+			),
+		),
+		handleDescribe,
+	)
+
+	// Tool: dataql_export - Write query results to a file
+	s.AddTool(
+		mcp.NewTool("dataql_export",
+			mcp.WithDescription("Run a SQL query on a data source and write the result to a file. Returns the file path, format, and number of rows written."),
+			mcp.WithString("source",
+				mcp.Required(),
+				mcp.Description("Data source: file path, URL, S3 URI, or database connection string"),
+			),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("SQL query whose result is exported"),
+			),
+			mcp.WithString("output_path",
+				mcp.Required(),
+				mcp.Description("Path of the file to write"),
+			),
+			mcp.WithString("format",
+				mcp.Description("Output format: csv, json, jsonl, parquet, xlsx, xml, yaml, markdown, html (default: from output_path extension, else csv)"),
+			),
+			mcp.WithString("delimiter",
+				mcp.Description("CSV delimiter character of the source (default: comma)"),
+			),
+		),
+		handleExport,
+	)
+
 	// Tool: dataql_mq_peek - Peek at messages in a message queue without consuming them
 	s.AddTool(
 		mcp.NewTool("dataql_mq_peek",
@@ -187,24 +444,58 @@ func registerTools(s *server.MCPServer) {
 
 // Handler functions
 
-func handleQuery(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	source := getStringArg(request, "source")
-	if source == "" {
-		return mcp.NewToolResultError("source parameter is required"), nil
-	}
-
+func handleQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	query := getStringArg(request, "query")
 	if query == "" {
 		return mcp.NewToolResultError("query parameter is required"), nil
 	}
+	if err := checkReadOnly(query); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	sessionID := getStringArg(request, "session_id")
+	source := getStringArg(request, "source")
+	if source == "" && sessionID == "" {
+		return mcp.NewToolResultError("source or session_id parameter is required"), nil
+	}
 
 	delimiter := getStringArg(request, "delimiter")
 	if delimiter == "" {
 		delimiter = ","
 	}
 
-	// Execute query using dataql
-	result, err := executeDataQL(source, query, delimiter)
+	var sess *session
+	if sessionID != "" {
+		var err error
+		if sess, err = sessions.get(sessionID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	// REPL commands such as .schema print text instead of returning rows
+	if isREPLCommand(query) {
+		var output string
+		var err error
+		if sess != nil {
+			output, err = sess.exec(query)
+		} else {
+			output, err = runDataQL(dataql.Params{FileInputs: []string{source}, Query: query, Delimiter: delimiter})
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Query failed: %v", err)), nil
+		}
+		return mcp.NewToolResultText(capResult(output)), nil
+	}
+
+	page := getPageOptions(request)
+
+	var result string
+	var err error
+	if sess != nil {
+		result, err = sess.query(ctx, query, page)
+	} else {
+		result, err = queryDataQL(ctx, source, query, delimiter, page)
+	}
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Query failed: %v", err)), nil
 	}
@@ -212,16 +503,59 @@ func handleQuery(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolR
 	return mcp.NewToolResultText(result), nil
 }
 
-func handleSchema(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func handleOpenSession(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sources := getStringArrayArg(request, "sources")
+	if len(sources) == 0 {
+		return mcp.NewToolResultError("sources parameter is required"), nil
+	}
+
+	delimiter := getStringArg(request, "delimiter")
+	if delimiter == "" {
+		delimiter = ","
+	}
+
+	sess, err := sessions.open(sources, delimiter)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to open session: %v", err)), nil
+	}
+
+	jsonBytes, err := json.MarshalIndent(map[string]interface{}{
+		"session_id": sess.id,
+		"sources":    sess.sources,
+	}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to open session: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+func handleCloseSession(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sessionID := getStringArg(request, "session_id")
+	if sessionID == "" {
+		return mcp.NewToolResultError("session_id parameter is required"), nil
+	}
+
+	if err := sessions.close(sessionID); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Session %s closed", sessionID)), nil
+}
+
+func handleSchema(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	source := getStringArg(request, "source")
 	if source == "" {
 		return mcp.NewToolResultError("source parameter is required"), nil
 	}
 
 	tableName := getTableName(source)
-	query := fmt.Sprintf(".schema %s", tableName)
+	query := fmt.Sprintf(`SELECT column_name AS name, data_type AS type, is_nullable = 'YES' AS nullable
+		FROM information_schema.columns
+		WHERE table_schema = 'main' AND table_name = '%s'
+		ORDER BY ordinal_position`, strings.ReplaceAll(tableName, "'", "''"))
 
-	result, err := executeDataQL(source, query, ",")
+	result, err := queryDataQL(ctx, source, query, ",", pageOptions{maxRows: maxMaxRows})
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get schema: %v", err)), nil
 	}
@@ -229,7 +563,7 @@ func handleSchema(_ context.Context, request mcp.CallToolRequest) (*mcp.CallTool
 	return mcp.NewToolResultText(result), nil
 }
 
-func handlePreview(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func handlePreview(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	source := getStringArg(request, "source")
 	if source == "" {
 		return mcp.NewToolResultError("source parameter is required"), nil
@@ -253,7 +587,7 @@ func handlePreview(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToo
 	tableName := getTableName(source)
 	query := fmt.Sprintf("SELECT * FROM %s LIMIT %d", tableName, limit)
 
-	result, err := executeDataQL(source, query, ",")
+	result, err := queryDataQL(ctx, source, query, ",", pageOptions{maxRows: limit})
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Preview failed: %v", err)), nil
 	}
@@ -261,7 +595,66 @@ func handlePreview(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToo
 	return mcp.NewToolResultText(result), nil
 }
 
-func handleAggregate(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func handleSample(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sessionID := getStringArg(request, "session_id")
+	source := getStringArg(request, "source")
+	if source == "" && sessionID == "" {
+		return mcp.NewToolResultError("source or session_id parameter is required"), nil
+	}
+
+	n := getIntArg(request, "n", defaultSampleRows)
+	if n < 1 {
+		n = 1
+	}
+	if n > maxMaxRows {
+		n = maxMaxRows
+	}
+
+	seed := getIntArg(request, "seed", defaultSampleSeed)
+	if seed < 0 {
+		return mcp.NewToolResultError("seed must not be negative"), nil
+	}
+
+	delimiter := getStringArg(request, "delimiter")
+	if delimiter == "" {
+		delimiter = ","
+	}
+
+	table := getStringArg(request, "table")
+
+	var sess *session
+	if sessionID != "" {
+		var err error
+		if sess, err = sessions.get(sessionID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if table == "" {
+			if table, err = sess.onlyTable(); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+	} else if table == "" {
+		table = getTableName(source)
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s USING SAMPLE reservoir(%d ROWS) REPEATABLE (%d)", storage.QuoteIdentifier(table), n, seed)
+	page := pageOptions{maxRows: n}
+
+	var result string
+	var err error
+	if sess != nil {
+		result, err = sess.query(ctx, query, page)
+	} else {
+		result, err = queryDataQL(ctx, source, query, delimiter, page)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Sample failed: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(result), nil
+}
+
+func handleAggregate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	source := getStringArg(request, "source")
 	if source == "" {
 		return mcp.NewToolResultError("source parameter is required"), nil
@@ -301,8 +694,11 @@ func handleAggregate(_ context.Context, request mcp.CallToolRequest) (*mcp.CallT
 	} else {
 		query = fmt.Sprintf("SELECT %s(%s) as result FROM %s", sqlOp, column, tableName)
 	}
+	if err := checkReadOnly(query); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	result, err := executeDataQL(source, query, ",")
+	result, err := queryDataQL(ctx, source, query, ",", pageOptions{maxRows: defaultMaxRows})
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Aggregation failed: %v", err)), nil
 	}
@@ -310,7 +706,187 @@ func handleAggregate(_ context.Context, request mcp.CallToolRequest) (*mcp.CallT
 	return mcp.NewToolResultText(result), nil
 }
 
-func handleMQPeek(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func handleDescribe(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sessionID := getStringArg(request, "session_id")
+	source := getStringArg(request, "source")
+	if source == "" && sessionID == "" {
+		return mcp.NewToolResultError("source or session_id parameter is required"), nil
+	}
+
+	topN := 5
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if n, exists := args["top_n"]; exists {
+			if nf, ok := n.(float64); ok {
+				topN = int(nf)
+				if topN < 0 {
+					topN = 0
+				}
+				if topN > 50 {
+					topN = 50
+				}
+			}
+		}
+	}
+
+	opts := dataql.DescribeOptions{
+		TopValues:    topN,
+		Outliers:     getBoolArg(request, "outliers"),
+		Completeness: getBoolArg(request, "completeness"),
+		Cardinality:  getBoolArg(request, "cardinality"),
+		Temporal:     getBoolArg(request, "temporal"),
+		By:           getStringArg(request, "by"),
+		Score:        getBoolArg(request, "score"),
+		Compare:      getStringArg(request, "compare"),
+	}
+	if spec := getStringArg(request, "score_weights"); spec != "" {
+		weights, err := dataql.ParseScoreWeights(spec)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		opts.Score = true
+		opts.ScoreWeights = weights
+	}
+	if bins := getIntArg(request, "bins", 0); bins > 0 {
+		if bins > maxHistogramBins {
+			bins = maxHistogramBins
+		}
+		opts.Histogram = true
+		opts.Bins = bins
+	}
+
+	profiles, err := describeSource(sessionID, source, getStringArg(request, "delimiter"), getIntArg(request, "sample", 0), opts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Describe failed: %v", err)), nil
+	}
+
+	jsonBytes, err := json.MarshalIndent(map[string]interface{}{"tables": profiles}, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Describe failed: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// describeSource profiles the tables of a session, or imports source, or
+// its first sample rows, to profile it
+func describeSource(sessionID, source, delimiter string, sample int, opts dataql.DescribeOptions) ([]dataql.TableProfile, error) {
+	if sessionID != "" {
+		sess, err := sessions.get(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		return sess.profile(opts)
+	}
+
+	if delimiter == "" {
+		delimiter = ","
+	}
+
+	if sample < 0 {
+		sample = 0
+	}
+
+	dql, err := newDataQL(dataql.Params{
+		FileInputs: []string{source},
+		Delimiter:  delimiter,
+		Lines:      sample,
+		Quiet:      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer dql.Close()
+
+	return dql.Profile(opts)
+}
+
+func handleExport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	source := getStringArg(request, "source")
+	if source == "" {
+		return mcp.NewToolResultError("source parameter is required"), nil
+	}
+
+	query := getStringArg(request, "query")
+	if query == "" {
+		return mcp.NewToolResultError("query parameter is required"), nil
+	}
+	if err := checkReadOnly(query); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	outputPath := getStringArg(request, "output_path")
+	if outputPath == "" {
+		return mcp.NewToolResultError("output_path parameter is required"), nil
+	}
+
+	format, err := resolveExportFormat(getStringArg(request, "format"), outputPath)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	delimiter := getStringArg(request, "delimiter")
+	if delimiter == "" {
+		delimiter = ","
+	}
+
+	if absPath, err := filepath.Abs(outputPath); err == nil {
+		outputPath = absPath
+	}
+
+	dql, err := newDataQL(dataql.Params{
+		FileInputs: []string{source},
+		Query:      query,
+		Delimiter:  delimiter,
+		Export:     outputPath,
+		Type:       format,
+		Quiet:      true,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Export failed: %v", err)), nil
+	}
+	defer dql.Close()
+
+	// Only the exported row count is reported
+	dql.SetOutput(io.Discard)
+	if err := dql.Run(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Export failed: %v", err)), nil
+	}
+
+	rows := dql.ResultRows()
+	recordQuery(ctx, query, int(rows))
+	result := map[string]interface{}{
+		"path":   outputPath,
+		"format": format,
+		"rows":   rows,
+	}
+
+	jsonBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Export failed: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// resolveExportFormat validates the requested export format, inferring it
+// from the output file extension when no format is given
+func resolveExportFormat(format, outputPath string) (string, error) {
+	if format == "" {
+		ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(outputPath)), ".")
+		if _, ok := exportFormats[ext]; !ok {
+			return "csv", nil
+		}
+		format = ext
+	}
+
+	exportType, ok := exportFormats[strings.ToLower(format)]
+	if !ok {
+		return "", fmt.Errorf("unsupported format: %s (use csv, json, jsonl, parquet, xlsx, xml, yaml, markdown, html)", format)
+	}
+	return exportType, nil
+}
+
+func handleMQPeek(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	source := getStringArg(request, "source")
 	if source == "" {
 		return mcp.NewToolResultError("source parameter is required"), nil
@@ -347,9 +923,12 @@ func handleMQPeek(_ context.Context, request mcp.CallToolRequest) (*mcp.CallTool
 		tableName := getMQTableName(source)
 		query = fmt.Sprintf("SELECT * FROM %s", tableName)
 	}
+	if err := checkReadOnly(query); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	// Execute using dataql
-	result, err := executeDataQL(source, query, ",")
+	result, err := queryDataQL(ctx, source, query, ",", pageOptions{maxRows: maxMaxRows})
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to peek messages: %v", err)), nil
 	}
@@ -414,6 +993,40 @@ func getStringArg(request mcp.CallToolRequest, name string) string {
 	return ""
 }
 
+// getBoolArg extracts a boolean argument from the request, or returns false
+func getBoolArg(request mcp.CallToolRequest, name string) bool {
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if val, ok := args[name].(bool); ok {
+			return val
+		}
+	}
+	return false
+}
+
+// getStringArrayArg extracts a list of strings from the request. A single string is accepted as a one-item list.
+func getStringArrayArg(request mcp.CallToolRequest, name string) []string {
+	args, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	switch val := args[name].(type) {
+	case string:
+		if val != "" {
+			return []string{val}
+		}
+	case []interface{}:
+		var values []string
+		for _, item := range val {
+			if str, ok := item.(string); ok && str != "" {
+				values = append(values, str)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
 // Helper functions
 
 func getTableName(source string) string {
@@ -429,113 +1042,39 @@ func getTableName(source string) string {
 	return name
 }
 
-func executeDataQL(source, query, delimiter string) (string, error) {
-	params := dataql.Params{
+// queryDataQL imports source and returns a page of the query result as JSON
+func queryDataQL(ctx context.Context, source, query, delimiter string, page pageOptions) (string, error) {
+	dql, err := newDataQL(dataql.Params{
 		FileInputs: []string{source},
-		Query:      query,
 		Delimiter:  delimiter,
-	}
-
-	dql, err := dataql.New(params)
+		Quiet:      true,
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to initialize dataql: %w", err)
+		return "", err
 	}
 	defer dql.Close()
 
-	// Capture output
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
-	err = dql.Run()
+	return runPagedQuery(ctx, dql, query, page)
+}
 
-	w.Close()
-	os.Stdout = oldStdout
+// isREPLCommand reports whether query is a REPL command (e.g. .schema) rather than SQL
+func isREPLCommand(query string) bool {
+	query = strings.TrimSpace(query)
+	return strings.HasPrefix(query, ".") || strings.HasPrefix(query, "\\")
+}
 
+// runDataQL runs dataql with the given params and returns what it printed
+func runDataQL(params dataql.Params) (string, error) {
+	dql, err := newDataQL(params)
 	if err != nil {
 		return "", err
 	}
+	defer dql.Close()
 
-	// Read captured output
-	outputBytes, _ := io.ReadAll(r)
-	output := string(outputBytes)
-
-	// Try to convert to JSON for better LLM consumption
-	jsonOutput := tryConvertToJSON(output)
-	if jsonOutput != "" {
-		return jsonOutput, nil
-	}
-
-	return output, nil
-}
-
-func tryConvertToJSON(output string) string {
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	if len(lines) < 2 {
-		return ""
-	}
-
-	// Check if it looks like a table output
-	if !strings.Contains(lines[0], "|") {
-		return ""
-	}
-
-	// Parse table format
-	var headers []string
-	var rows []map[string]interface{}
-
-	for i, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
-			continue
-		}
-
-		parts := strings.Split(line, "|")
-		var values []string
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if p != "" {
-				values = append(values, p)
-			}
-		}
-
-		if len(values) == 0 {
-			continue
-		}
-
-		if len(headers) == 0 {
-			headers = values
-			continue
-		}
-
-		// Skip separator line
-		if i == 1 && strings.Contains(line, "-") {
-			continue
-		}
-
-		row := make(map[string]interface{})
-		for j, v := range values {
-			if j < len(headers) {
-				row[headers[j]] = v
-			}
-		}
-		rows = append(rows, row)
-	}
-
-	if len(rows) == 0 {
-		return ""
-	}
-
-	result := map[string]interface{}{
-		"columns": headers,
-		"rows":    rows,
-		"count":   len(rows),
-	}
-
-	jsonBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return ""
+	var out bytes.Buffer
+	dql.SetOutput(&out)
+	if err := dql.Run(); err != nil {
+		return "", err
 	}
-
-	return string(jsonBytes)
+	return out.String(), nil
 }
@@ -0,0 +1,410 @@
+package mcpctl
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const simpleFixture = "../../tests/fixtures/csv/simple.csv"
+
+// callTool builds a tool request with the given arguments
+func callTool(name string, args map[string]interface{}) mcp.CallToolRequest {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+	return request
+}
+
+// resultText returns the text content of a tool result
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	require.NotEmpty(t, result.Content)
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
+
+func TestResolveExportFormat(t *testing.T) {
+	tests := []struct {
+		format, path, expected string
+	}{
+		{"", "out.csv", "csv"},
+		{"", "out.parquet", "parquet"},
+		{"", "out.xlsx", "excel"},
+		{"", "out.unknown", "csv"},
+		{"json", "out.txt", "json"},
+		{"XLSX", "out", "excel"},
+	}
+
+	for _, tt := range tests {
+		format, err := resolveExportFormat(tt.format, tt.path)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, format, tt.format+" "+tt.path)
+	}
+
+	_, err := resolveExportFormat("pdf", "out.pdf")
+	assert.Error(t, err)
+}
+
+func TestHandleExport(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "result.json")
+
+	result, err := handleExport(context.Background(), callTool("dataql_export", map[string]interface{}{
+		"source":      simpleFixture,
+		"query":       "SELECT * FROM simple WHERE id > 1",
+		"output_path": outputPath,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &response))
+	assert.Equal(t, outputPath, response["path"])
+	assert.Equal(t, "json", response["format"])
+	assert.Equal(t, float64(2), response["rows"])
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Jane")
+}
+
+func TestHandleExport_MissingOutputPath(t *testing.T) {
+	result, err := handleExport(context.Background(), callTool("dataql_export", map[string]interface{}{
+		"source": simpleFixture,
+		"query":  "SELECT * FROM simple",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestHandleDescribe(t *testing.T) {
+	result, err := handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"source": simpleFixture,
+		"top_n":  float64(2),
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var response struct {
+		Tables []struct {
+			Name    string `json:"name"`
+			Rows    int64  `json:"rows"`
+			Columns []struct {
+				Name      string   `json:"name"`
+				Type      string   `json:"type"`
+				NullPct   float64  `json:"null_pct"`
+				Distinct  int64    `json:"distinct"`
+				Mean      *float64 `json:"mean"`
+				TopValues []struct {
+					Value string  `json:"value"`
+					Count int64   `json:"count"`
+					Pct   float64 `json:"pct"`
+				} `json:"top_values"`
+			} `json:"columns"`
+		} `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &response))
+	require.Len(t, response.Tables, 1)
+
+	table := response.Tables[0]
+	assert.Equal(t, "simple", table.Name)
+	assert.Equal(t, int64(3), table.Rows)
+	require.Len(t, table.Columns, 3)
+
+	id := table.Columns[0]
+	assert.Equal(t, "id", id.Name)
+	assert.Equal(t, int64(3), id.Distinct)
+	require.NotNil(t, id.Mean)
+	assert.Equal(t, 2.0, *id.Mean)
+	require.Len(t, id.TopValues, 2)
+	assert.InDelta(t, 33.3, id.TopValues[0].Pct, 0.1)
+
+	// Text columns have no mean
+	assert.Nil(t, table.Columns[1].Mean)
+}
+
+func TestHandleDescribe_Histogram(t *testing.T) {
+	result, err := handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"source": simpleFixture,
+		"top_n":  float64(0),
+		"bins":   float64(2),
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var response struct {
+		Tables []struct {
+			Columns []struct {
+				Name      string `json:"name"`
+				Histogram []struct {
+					Lower float64 `json:"lower"`
+					Upper float64 `json:"upper"`
+					Count int64   `json:"count"`
+				} `json:"histogram"`
+			} `json:"columns"`
+		} `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &response))
+	require.Len(t, response.Tables, 1)
+
+	columns := response.Tables[0].Columns
+	require.Len(t, columns[0].Histogram, 2)
+	assert.Equal(t, 1.0, columns[0].Histogram[0].Lower)
+	assert.Equal(t, 3.0, columns[0].Histogram[1].Upper)
+	assert.Equal(t, int64(1), columns[0].Histogram[0].Count)
+	assert.Equal(t, int64(2), columns[0].Histogram[1].Count)
+
+	// Text columns have no histogram
+	assert.Empty(t, columns[1].Histogram)
+}
+
+func TestHandleDescribe_Outliers(t *testing.T) {
+	result, err := handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"source":   simpleFixture,
+		"top_n":    float64(0),
+		"outliers": true,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var response struct {
+		Tables []struct {
+			Columns []struct {
+				Name     string `json:"name"`
+				Outliers *struct {
+					Q1       float64 `json:"q1"`
+					Q3       float64 `json:"q3"`
+					IQRCount int64   `json:"iqr_outliers"`
+				} `json:"outliers"`
+			} `json:"columns"`
+		} `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &response))
+	require.Len(t, response.Tables, 1)
+
+	columns := response.Tables[0].Columns
+	require.NotNil(t, columns[0].Outliers)
+	assert.Equal(t, 1.5, columns[0].Outliers.Q1)
+	assert.Equal(t, 2.5, columns[0].Outliers.Q3)
+	assert.Zero(t, columns[0].Outliers.IQRCount)
+
+	// Only numeric columns are checked
+	assert.Nil(t, columns[1].Outliers)
+}
+
+func TestHandleDescribe_Completeness(t *testing.T) {
+	result, err := handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"source":       simpleFixture,
+		"top_n":        float64(0),
+		"completeness": true,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	text := resultText(t, result)
+	assert.Contains(t, text, `"blank_rows": 0`)
+	assert.Contains(t, text, `"empty": 0`)
+}
+
+func TestHandleDescribe_Cardinality(t *testing.T) {
+	result, err := handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"source":      simpleFixture,
+		"top_n":       float64(0),
+		"cardinality": true,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var response struct {
+		Tables []struct {
+			Columns []struct {
+				Name        string `json:"name"`
+				Cardinality *struct {
+					Uniqueness   float64 `json:"uniqueness"`
+					KeyCandidate bool    `json:"key_candidate"`
+				} `json:"cardinality"`
+			} `json:"columns"`
+		} `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &response))
+	require.Len(t, response.Tables, 1)
+
+	id := response.Tables[0].Columns[0]
+	require.NotNil(t, id.Cardinality)
+	assert.Equal(t, 100.0, id.Cardinality.Uniqueness)
+	assert.True(t, id.Cardinality.KeyCandidate)
+}
+
+func TestHandleDescribe_Temporal(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "events.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte("id,created\n1,2024-01-01\n2,2024-01-03\n"), 0644))
+
+	result, err := handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"source":   csvPath,
+		"top_n":    float64(0),
+		"temporal": true,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	text := resultText(t, result)
+	assert.Contains(t, text, `"granularity": "day"`)
+	assert.Contains(t, text, `"gaps": 1`)
+}
+
+func TestHandleDescribe_Sample(t *testing.T) {
+	result, err := handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"source": simpleFixture,
+		"top_n":  float64(0),
+		"sample": float64(2),
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	text := resultText(t, result)
+	assert.Contains(t, text, `"rows": 2`)
+	assert.Contains(t, text, `"row_limit": 2`)
+}
+
+func TestHandleDescribe_By(t *testing.T) {
+	result, err := handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"source": simpleFixture,
+		"top_n":  float64(0),
+		"by":     "name",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var response struct {
+		Tables []struct {
+			GroupBy string `json:"group_by"`
+			Groups  []struct {
+				Value   *string           `json:"value"`
+				Rows    int64             `json:"rows"`
+				Columns []json.RawMessage `json:"columns"`
+			} `json:"groups"`
+		} `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &response))
+	require.Len(t, response.Tables, 1)
+
+	assert.Equal(t, "name", response.Tables[0].GroupBy)
+	groups := response.Tables[0].Groups
+	require.Len(t, groups, 3)
+	assert.Equal(t, "Bob", *groups[0].Value)
+	assert.Equal(t, int64(1), groups[0].Rows)
+	assert.Len(t, groups[0].Columns, 2)
+}
+
+func TestHandleDescribe_ByUnknownColumn(t *testing.T) {
+	result, err := handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"source": simpleFixture,
+		"by":     "region",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "group column region not found")
+}
+
+func TestHandleDescribe_Score(t *testing.T) {
+	result, err := handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"source":        simpleFixture,
+		"top_n":         float64(0),
+		"score_weights": "uniqueness=2",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var response struct {
+		Tables []struct {
+			Quality *struct {
+				Score   float64 `json:"score"`
+				Weights struct {
+					Uniqueness float64 `json:"uniqueness"`
+				} `json:"weights"`
+			} `json:"quality"`
+		} `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &response))
+	require.Len(t, response.Tables, 1)
+	require.NotNil(t, response.Tables[0].Quality)
+	assert.Equal(t, 100.0, response.Tables[0].Quality.Score)
+	assert.Equal(t, 2.0, response.Tables[0].Quality.Weights.Uniqueness)
+
+	result, err = handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"source":        simpleFixture,
+		"score_weights": "freshness=1",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestHandleDescribe_Compare(t *testing.T) {
+	result, err := handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"source":  simpleFixture,
+		"top_n":   float64(0),
+		"compare": "../../tests/fixtures/csv/users.csv",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var response struct {
+		Tables []struct {
+			Drift *struct {
+				BaselineTable  string   `json:"baseline_table"`
+				AddedColumns   []string `json:"added_columns"`
+				RemovedColumns []string `json:"removed_columns"`
+			} `json:"drift"`
+			Columns []struct {
+				Name  string `json:"name"`
+				Drift *struct {
+					Shifted bool `json:"shifted"`
+				} `json:"drift"`
+			} `json:"columns"`
+		} `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &response))
+	require.Len(t, response.Tables, 1)
+	require.NotNil(t, response.Tables[0].Drift)
+	assert.Equal(t, "users", response.Tables[0].Drift.BaselineTable)
+	assert.Equal(t, []string{"email"}, response.Tables[0].Drift.AddedColumns)
+	assert.Equal(t, []string{"department_id"}, response.Tables[0].Drift.RemovedColumns)
+	assert.Equal(t, "id", response.Tables[0].Columns[0].Name)
+	assert.NotNil(t, response.Tables[0].Columns[0].Drift)
+	assert.Nil(t, response.Tables[0].Columns[2].Drift)
+}
+
+func TestHandleSchemaPreviewAggregate(t *testing.T) {
+	result, err := handleSchema(context.Background(), callTool("dataql_schema", map[string]interface{}{
+		"source": simpleFixture,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Contains(t, resultText(t, result), `"name": "email"`)
+	assert.Contains(t, resultText(t, result), `"type": "BIGINT"`)
+
+	result, err = handlePreview(context.Background(), callTool("dataql_preview", map[string]interface{}{
+		"source": simpleFixture,
+		"limit":  float64(1),
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Contains(t, resultText(t, result), `"count": 1`)
+	assert.NotContains(t, resultText(t, result), "truncated")
+
+	result, err = handleAggregate(context.Background(), callTool("dataql_aggregate", map[string]interface{}{
+		"source":    simpleFixture,
+		"column":    "id",
+		"operation": "sum",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Contains(t, resultText(t, result), `"result": 6`)
+}
@@ -0,0 +1,170 @@
+package mcpctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/internal/dataql"
+	"github.com/adrianolaselva/dataql/pkg/sqlguard"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	defaultMaxRows        = 100
+	maxMaxRows            = 10000
+	defaultMaxResultBytes = 100 * 1024
+	defaultSampleRows     = 10
+	defaultSampleSeed     = 42
+	maxHistogramBins      = 50
+)
+
+// maxResultBytes caps the size of tool results so they fit in the LLM context (set by serve --max-result-bytes)
+var maxResultBytes = defaultMaxResultBytes
+
+// pageOptions selects the window of rows returned by dataql_query
+type pageOptions struct {
+	maxRows int
+	offset  int
+}
+
+// getPageOptions reads the max_rows and offset arguments
+func getPageOptions(request mcp.CallToolRequest) pageOptions {
+	page := pageOptions{
+		maxRows: getIntArg(request, "max_rows", defaultMaxRows),
+		offset:  getIntArg(request, "offset", 0),
+	}
+	if page.maxRows < 1 {
+		page.maxRows = 1
+	}
+	if page.maxRows > maxMaxRows {
+		page.maxRows = maxMaxRows
+	}
+	if page.offset < 0 {
+		page.offset = 0
+	}
+	return page
+}
+
+// pagedQuery wraps a single SELECT-like statement so that only the requested
+// window is computed. One extra row is fetched to detect whether more rows
+// follow. Other statements (SHOW, DESCRIBE, scripts) are returned unchanged.
+func pagedQuery(query string, page pageOptions) (string, bool) {
+	statements := sqlguard.Statements(query)
+	if len(statements) != 1 {
+		return query, false
+	}
+
+	switch statements[0][0] {
+	case "SELECT", "WITH", "FROM", "VALUES", "TABLE":
+	default:
+		return query, false
+	}
+
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	return fmt.Sprintf("SELECT * FROM (%s) AS page LIMIT %d OFFSET %d", query, page.maxRows+1, page.offset), true
+}
+
+// queryResponse is the JSON returned by the query tools
+type queryResponse struct {
+	Columns    []string                 `json:"columns"`
+	Types      []string                 `json:"types"`
+	Rows       []map[string]interface{} `json:"rows"`
+	Count      int                      `json:"count"`
+	Offset     int                      `json:"offset,omitempty"`
+	Truncated  bool                     `json:"truncated,omitempty"`
+	NextOffset int                      `json:"next_offset,omitempty"`
+	Hint       string                   `json:"hint,omitempty"`
+}
+
+// runPagedQuery runs a query on dql and returns the requested page as JSON
+func runPagedQuery(ctx context.Context, dql dataql.DataQL, query string, page pageOptions) (string, error) {
+	pageQuery, paged := pagedQuery(query, page)
+
+	result, err := dql.Query(pageQuery, page.maxRows)
+	if err != nil {
+		return "", err
+	}
+
+	response := queryResponse{
+		Columns: result.Columns,
+		Types:   result.Types,
+		Rows:    make([]map[string]interface{}, len(result.Rows)),
+	}
+	for i, values := range result.Rows {
+		row := make(map[string]interface{}, len(values))
+		for j, value := range values {
+			row[result.Columns[j]] = value
+		}
+		response.Rows[i] = row
+	}
+	if paged {
+		response.Offset = page.offset
+	}
+	if result.Truncated {
+		response.Truncated = true
+		response.NextOffset = response.Offset + len(response.Rows)
+		response.Hint = fmt.Sprintf("more rows available: call again with offset=%d", response.NextOffset)
+	}
+
+	encoded, count, err := encodeResponse(response)
+	if err != nil {
+		return "", err
+	}
+	recordQuery(ctx, query, count)
+	return encoded, nil
+}
+
+// encodeResponse marshals a query response, dropping trailing rows when it
+// exceeds maxResultBytes so it stays within the LLM context budget. It also
+// returns the number of rows kept.
+func encodeResponse(response queryResponse) (string, int, error) {
+	for {
+		response.Count = len(response.Rows)
+		jsonBytes, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to encode result: %w", err)
+		}
+
+		if maxResultBytes <= 0 || len(jsonBytes) <= maxResultBytes || len(response.Rows) == 0 {
+			return string(jsonBytes), response.Count, nil
+		}
+
+		// Keep the share of rows that fits, and at least one row less than before
+		keep := len(response.Rows) * maxResultBytes / len(jsonBytes)
+		if keep >= len(response.Rows) {
+			keep = len(response.Rows) - 1
+		}
+		response.Rows = response.Rows[:keep]
+		response.Truncated = true
+		response.NextOffset = response.Offset + keep
+		response.Hint = fmt.Sprintf("result exceeds %d bytes: select fewer columns, filter or aggregate rows, or page with offset=%d", maxResultBytes, response.NextOffset)
+	}
+}
+
+// capResult shortens text results (REPL command output) larger than
+// maxResultBytes at a line boundary and tells the LLM how to refine the query
+func capResult(result string) string {
+	if maxResultBytes <= 0 || len(result) <= maxResultBytes {
+		return result
+	}
+
+	cut := result[:maxResultBytes]
+	if idx := strings.LastIndex(cut, "\n"); idx > 0 {
+		cut = cut[:idx+1]
+	}
+	return cut + fmt.Sprintf("[truncated: result exceeds %d bytes; select fewer columns, filter or aggregate rows, or lower max_rows]\n", maxResultBytes)
+}
+
+// getIntArg extracts an integer argument from the request, or returns def
+func getIntArg(request mcp.CallToolRequest, name string, def int) int {
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if val, exists := args[name]; exists {
+			if f, ok := val.(float64); ok {
+				return int(f)
+			}
+		}
+	}
+	return def
+}
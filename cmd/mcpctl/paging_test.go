@@ -0,0 +1,107 @@
+package mcpctl
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagedQuery(t *testing.T) {
+	page := pageOptions{maxRows: 10, offset: 20}
+
+	query, paged := pagedQuery("SELECT * FROM users;", page)
+	assert.True(t, paged)
+	assert.Equal(t, "SELECT * FROM (SELECT * FROM users) AS page LIMIT 11 OFFSET 20", query)
+
+	for _, q := range []string{".tables", "SHOW TABLES", "SELECT 1; SELECT 2", "DESCRIBE users"} {
+		query, paged = pagedQuery(q, page)
+		assert.False(t, paged, q)
+		assert.Equal(t, q, query)
+	}
+}
+
+func TestEncodeResponse_CapsSize(t *testing.T) {
+	previous := maxResultBytes
+	maxResultBytes = 400
+	defer func() { maxResultBytes = previous }()
+
+	response := queryResponse{Columns: []string{"value"}, Types: []string{"VARCHAR"}, Offset: 10}
+	for i := 0; i < 50; i++ {
+		response.Rows = append(response.Rows, map[string]interface{}{"value": strings.Repeat("x", 20)})
+	}
+
+	encoded, count, err := encodeResponse(response)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(encoded), 400)
+
+	var decoded queryResponse
+	require.NoError(t, json.Unmarshal([]byte(encoded), &decoded))
+	assert.True(t, decoded.Truncated)
+	assert.Equal(t, count, decoded.Count)
+	assert.Equal(t, decoded.Count, len(decoded.Rows))
+	assert.Equal(t, 10+decoded.Count, decoded.NextOffset)
+	assert.Contains(t, decoded.Hint, "exceeds 400 bytes")
+}
+
+func TestCapResult(t *testing.T) {
+	previous := maxResultBytes
+	maxResultBytes = 20
+	defer func() { maxResultBytes = previous }()
+
+	assert.Equal(t, "short\n", capResult("short\n"))
+
+	capped := capResult("line one\nline two\nline three\n")
+	assert.True(t, strings.HasPrefix(capped, "line one\nline two\n[truncated: result exceeds 20 bytes"))
+}
+
+// queryPage calls dataql_query on the simple fixture and decodes the result
+func queryPage(t *testing.T, args map[string]interface{}) queryResponse {
+	t.Helper()
+
+	args["source"] = simpleFixture
+	result, err := handleQuery(context.Background(), callTool("dataql_query", args))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var response queryResponse
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &response))
+	return response
+}
+
+func TestHandleQuery_Pagination(t *testing.T) {
+	response := queryPage(t, map[string]interface{}{
+		"query":    "SELECT id, name FROM simple ORDER BY id",
+		"max_rows": float64(2),
+	})
+	assert.Equal(t, []string{"id", "name"}, response.Columns)
+	assert.Equal(t, []string{"BIGINT", "VARCHAR"}, response.Types)
+	require.Len(t, response.Rows, 2)
+	assert.Equal(t, float64(1), response.Rows[0]["id"]) // numbers stay numbers
+	assert.Equal(t, "Jane", response.Rows[1]["name"])
+	assert.True(t, response.Truncated)
+	assert.Equal(t, 2, response.NextOffset)
+
+	response = queryPage(t, map[string]interface{}{
+		"query":    "SELECT id, name FROM simple ORDER BY id",
+		"max_rows": float64(2),
+		"offset":   float64(2),
+	})
+	require.Len(t, response.Rows, 1)
+	assert.Equal(t, "Bob", response.Rows[0]["name"])
+	assert.Equal(t, 2, response.Offset)
+	assert.False(t, response.Truncated)
+}
+
+func TestHandleQuery_REPLCommand(t *testing.T) {
+	result, err := handleQuery(context.Background(), callTool("dataql_query", map[string]interface{}{
+		"source": simpleFixture,
+		"query":  ".schema simple",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Contains(t, resultText(t, result), "email")
+}
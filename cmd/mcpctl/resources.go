@@ -0,0 +1,109 @@
+package mcpctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/internal/dataql"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	tablesResourceURI    = "dataql://tables"
+	schemaResourcePrefix = "dataql://schema/"
+)
+
+// sessionTable is a table of an open session as listed by the dataql://tables resource
+type sessionTable struct {
+	SessionID string `json:"session_id"`
+	Name      string `json:"name"`
+	Columns   int    `json:"columns"`
+}
+
+// sessionSchema is the schema of a table in an open session
+type sessionSchema struct {
+	SessionID string                `json:"session_id"`
+	Name      string                `json:"name"`
+	Columns   []dataql.ColumnSchema `json:"columns"`
+}
+
+func registerResources(s *server.MCPServer) {
+	// Resource: dataql://tables - Tables loaded in the open sessions
+	s.AddResource(
+		mcp.NewResource(tablesResourceURI, "Loaded tables",
+			mcp.WithResourceDescription("Tables available in the open sessions (see dataql_open_session), with their column count"),
+			mcp.WithMIMEType("application/json"),
+		),
+		handleTablesResource,
+	)
+
+	// Resource template: dataql://schema/{table} - Column names and types of a table
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(schemaResourcePrefix+"{table}", "Table schema",
+			mcp.WithTemplateDescription("Column names and types of a table loaded in an open session"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		handleSchemaResource,
+	)
+}
+
+func handleTablesResource(_ context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	tables := []sessionTable{}
+	for _, sess := range sessions.list() {
+		schemas, err := sess.schema()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tables of session %s: %w", sess.id, err)
+		}
+		for _, table := range schemas {
+			tables = append(tables, sessionTable{SessionID: sess.id, Name: table.Name, Columns: len(table.Columns)})
+		}
+	}
+
+	return jsonResource(request.Params.URI, tables)
+}
+
+func handleSchemaResource(_ context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	tableName := strings.TrimPrefix(request.Params.URI, schemaResourcePrefix)
+	if tableName == "" || tableName == request.Params.URI {
+		return nil, fmt.Errorf("invalid schema resource: %s", request.Params.URI)
+	}
+
+	// The same table name may be loaded in several sessions
+	var matches []sessionSchema
+	for _, sess := range sessions.list() {
+		schemas, err := sess.schema()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get schema of session %s: %w", sess.id, err)
+		}
+		for _, table := range schemas {
+			if strings.EqualFold(table.Name, tableName) {
+				matches = append(matches, sessionSchema{SessionID: sess.id, Name: table.Name, Columns: table.Columns})
+			}
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("table %s not found in any open session", tableName)
+	}
+
+	return jsonResource(request.Params.URI, matches)
+}
+
+// jsonResource encodes v as the JSON text content of a resource
+func jsonResource(uri string, v interface{}) ([]mcp.ResourceContents, error) {
+	jsonBytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: "application/json",
+			Text:     string(jsonBytes),
+		},
+	}, nil
+}
@@ -0,0 +1,46 @@
+package mcpctl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readResource builds a resource read request for uri
+func readResource(uri string) mcp.ReadResourceRequest {
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+	return request
+}
+
+func TestResources_TablesAndSchema(t *testing.T) {
+	sessions = newSessionManager(defaultMaxSessions)
+	defer sessions.closeAll()
+
+	contents, err := handleTablesResource(context.Background(), readResource(tablesResourceURI))
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	assert.Equal(t, "[]", contents[0].(mcp.TextResourceContents).Text)
+
+	id := openSession(t)
+
+	contents, err = handleTablesResource(context.Background(), readResource(tablesResourceURI))
+	require.NoError(t, err)
+	text := contents[0].(mcp.TextResourceContents).Text
+	assert.Contains(t, text, id)
+	assert.Contains(t, text, `"name": "simple"`)
+	assert.Contains(t, text, `"columns": 3`)
+	assert.NotContains(t, text, `"schemas"`)
+
+	contents, err = handleSchemaResource(context.Background(), readResource(schemaResourcePrefix+"simple"))
+	require.NoError(t, err)
+	text = contents[0].(mcp.TextResourceContents).Text
+	assert.Contains(t, text, `"name": "email"`)
+	assert.Contains(t, text, `"type": "BIGINT"`)
+
+	_, err = handleSchemaResource(context.Background(), readResource(schemaResourcePrefix+"missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package mcpctl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const largeFixture = "../../tests/fixtures/csv/large.csv"
+
+// sample calls dataql_sample and decodes the result
+func sample(t *testing.T, args map[string]interface{}) queryResponse {
+	t.Helper()
+
+	result, err := handleSample(context.Background(), callTool("dataql_sample", args))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var response queryResponse
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &response))
+	return response
+}
+
+func TestHandleSample_Reproducible(t *testing.T) {
+	first := sample(t, map[string]interface{}{"source": largeFixture, "n": float64(5), "seed": float64(7)})
+	require.Len(t, first.Rows, 5)
+	assert.False(t, first.Truncated)
+
+	again := sample(t, map[string]interface{}{"source": largeFixture, "n": float64(5), "seed": float64(7)})
+	assert.Equal(t, first.Rows, again.Rows)
+
+	other := sample(t, map[string]interface{}{"source": largeFixture, "n": float64(5), "seed": float64(8)})
+	assert.NotEqual(t, first.Rows, other.Rows)
+
+	// Fewer rows than requested returns the whole table
+	all := sample(t, map[string]interface{}{"source": simpleFixture, "n": float64(50)})
+	assert.Len(t, all.Rows, 3)
+}
+
+func TestHandleSample_Session(t *testing.T) {
+	sessions = newSessionManager(defaultMaxSessions)
+	defer sessions.closeAll()
+
+	result, err := handleOpenSession(context.Background(), callTool("dataql_open_session", map[string]interface{}{
+		"sources": []interface{}{simpleFixture, largeFixture},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var opened struct {
+		SessionID string `json:"session_id"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &opened))
+
+	// With several tables the table must be named
+	result, err = handleSample(context.Background(), callTool("dataql_sample", map[string]interface{}{
+		"session_id": opened.SessionID,
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "set table to one of")
+
+	response := sample(t, map[string]interface{}{"session_id": opened.SessionID, "table": "simple", "n": float64(2)})
+	assert.Len(t, response.Rows, 2)
+}
+
+func TestHandleSample_Errors(t *testing.T) {
+	for _, args := range []map[string]interface{}{
+		{},
+		{"source": simpleFixture, "seed": float64(-1)},
+		{"source": simpleFixture, "table": "missing"},
+	} {
+		result, err := handleSample(context.Background(), callTool("dataql_sample", args))
+		require.NoError(t, err)
+		assert.True(t, result.IsError, args)
+	}
+}
@@ -0,0 +1,37 @@
+package mcpctl
+
+import (
+	"fmt"
+
+	"github.com/adrianolaselva/dataql/internal/dataql"
+	"github.com/adrianolaselva/dataql/pkg/sandbox"
+)
+
+// sourcePolicy restricts the sources and output files the tools may open (set by serve --allowed-paths/--allowed-schemes)
+var sourcePolicy sandbox.Policy
+
+// newDataQL creates a dataql instance after checking its sources and export
+// path against the sandbox. With a sandbox, SQL cannot open other files either.
+func newDataQL(params dataql.Params) (dataql.DataQL, error) {
+	for _, source := range params.FileInputs {
+		if err := sourcePolicy.CheckSource(source); err != nil {
+			return nil, err
+		}
+	}
+	if params.Export != "" && sourcePolicy.Enabled() {
+		if !sourcePolicy.AllowsScheme(sandbox.SchemeFile) {
+			return nil, fmt.Errorf("exporting files is not allowed: scheme %s is not in --allowed-schemes", sandbox.SchemeFile)
+		}
+		if err := sourcePolicy.CheckPath(params.Export); err != nil {
+			return nil, err
+		}
+	}
+
+	params.NoExternalAccess = params.NoExternalAccess || sourcePolicy.Enabled()
+
+	dql, err := dataql.New(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize dataql: %w", err)
+	}
+	return dql, nil
+}
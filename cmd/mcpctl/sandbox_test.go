@@ -0,0 +1,54 @@
+package mcpctl
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/sandbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandbox_Tools(t *testing.T) {
+	fixtures, err := filepath.Abs(filepath.Dir(simpleFixture))
+	require.NoError(t, err)
+
+	sourcePolicy, err = sandbox.NewPolicy([]string{fixtures}, nil)
+	require.NoError(t, err)
+	defer func() { sourcePolicy = sandbox.Policy{} }()
+
+	// Allowed source, but SQL cannot reach other files
+	result, err := handleQuery(context.Background(), callTool("dataql_query", map[string]interface{}{
+		"source": simpleFixture,
+		"query":  "SELECT COUNT(*) AS total FROM simple",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Contains(t, resultText(t, result), `"total": 3`)
+
+	result, err = handleQuery(context.Background(), callTool("dataql_query", map[string]interface{}{
+		"source": simpleFixture,
+		"query":  "SELECT * FROM read_csv('/etc/passwd')",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = handleQuery(context.Background(), callTool("dataql_query", map[string]interface{}{
+		"source": "/etc/passwd",
+		"query":  "SELECT 1",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "not allowed")
+
+	result, err = handleExport(context.Background(), callTool("dataql_export", map[string]interface{}{
+		"source":      simpleFixture,
+		"query":       "SELECT * FROM simple",
+		"output_path": filepath.Join(t.TempDir(), "out.csv"),
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "outside --allowed-paths")
+}
@@ -0,0 +1,230 @@
+package mcpctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrianolaselva/dataql/internal/dataql"
+)
+
+// defaultMaxSessions is the number of sessions kept open before the least recently used one is closed
+const defaultMaxSessions = 8
+
+// session keeps a DataQL instance with its sources already imported, so that
+// subsequent tool calls query the data without importing it again
+type session struct {
+	id       string
+	sources  []string
+	dql      dataql.DataQL
+	mu       sync.Mutex // Serializes statements on the instance
+	lastUsed time.Time
+}
+
+// sessionManager holds the open sessions of the MCP server
+type sessionManager struct {
+	mu          sync.Mutex
+	sessions    map[string]*session
+	maxSessions int
+}
+
+// sessions is shared by the tool handlers of the running server
+var sessions = newSessionManager(defaultMaxSessions)
+
+func newSessionManager(maxSessions int) *sessionManager {
+	if maxSessions < 1 {
+		maxSessions = defaultMaxSessions
+	}
+	return &sessionManager{
+		sessions:    make(map[string]*session),
+		maxSessions: maxSessions,
+	}
+}
+
+// open imports the sources and registers a new session. When the limit is
+// reached, the least recently used session is closed to make room.
+func (m *sessionManager) open(sources []string, delimiter string) (*session, error) {
+	dql, err := newDataQL(dataql.Params{
+		FileInputs: sources,
+		Delimiter:  delimiter,
+		Quiet:      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dql.Import(); err != nil {
+		_ = dql.Close()
+		return nil, err
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		_ = dql.Close()
+		return nil, err
+	}
+
+	s := &session{id: id, sources: sources, dql: dql, lastUsed: time.Now()}
+
+	m.mu.Lock()
+	var evicted *session
+	if len(m.sessions) >= m.maxSessions {
+		evicted = m.leastRecentlyUsed()
+		delete(m.sessions, evicted.id)
+	}
+	m.sessions[id] = s
+	m.mu.Unlock()
+
+	if evicted != nil {
+		evicted.close()
+	}
+
+	return s, nil
+}
+
+// get returns an open session and marks it as used
+func (m *sessionManager) get(id string) (*session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("session %s not found (it may have been closed or evicted)", id)
+	}
+	s.lastUsed = time.Now()
+	return s, nil
+}
+
+// close closes a session and releases its storage
+func (m *sessionManager) close(id string) error {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	delete(m.sessions, id)
+	m.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("session %s not found", id)
+	}
+	s.close()
+	return nil
+}
+
+// closeAll closes every open session
+func (m *sessionManager) closeAll() {
+	m.mu.Lock()
+	open := m.sessions
+	m.sessions = make(map[string]*session)
+	m.mu.Unlock()
+
+	for _, s := range open {
+		s.close()
+	}
+}
+
+// leastRecentlyUsed returns the session that was used the longest time ago. The caller holds m.mu.
+func (m *sessionManager) leastRecentlyUsed() *session {
+	var oldest *session
+	for _, s := range m.sessions {
+		if oldest == nil || s.lastUsed.Before(oldest.lastUsed) {
+			oldest = s
+		}
+	}
+	return oldest
+}
+
+// close waits for the running statement, if any, and closes the instance
+func (s *session) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.dql.Close()
+}
+
+// exec runs a statement in the session and returns what it printed
+func (s *session) exec(query string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out bytes.Buffer
+	s.dql.SetOutput(&out)
+	if err := s.dql.Exec(query); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// query runs a statement in the session and returns a page of its result as JSON
+func (s *session) query(ctx context.Context, query string, page pageOptions) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return runPagedQuery(ctx, s.dql, query, page)
+}
+
+// profile returns the column statistics of the session tables
+func (s *session) profile(opts dataql.DescribeOptions) ([]dataql.TableProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.dql.Profile(opts)
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// schema returns the tables of the session and their column types
+func (s *session) schema() ([]dataql.TableSchema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.dql.Schema()
+}
+
+// validate checks a session table against the expectations
+func (s *session) validate(table string, exp expectations) (*validationReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return validateTable(s.dql, table, exp)
+}
+
+// onlyTable returns the name of the session table, or an error naming the
+// tables to choose from when the session has several
+func (s *session) onlyTable() (string, error) {
+	schemas, err := s.schema()
+	if err != nil {
+		return "", err
+	}
+	if len(schemas) == 1 {
+		return schemas[0].Name, nil
+	}
+
+	names := make([]string, len(schemas))
+	for i, table := range schemas {
+		names[i] = table.Name
+	}
+	return "", fmt.Errorf("session %s has %d tables, set table to one of: %s", s.id, len(schemas), strings.Join(names, ", "))
+}
+
+// list returns the open sessions ordered by id
+func (m *sessionManager) list() []*session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	open := make([]*session, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		open = append(open, s)
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].id < open[j].id })
+	return open
+}
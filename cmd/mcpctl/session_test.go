@@ -0,0 +1,137 @@
+package mcpctl
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openSession opens a session on the simple fixture and returns its id
+func openSession(t *testing.T) string {
+	t.Helper()
+
+	result, err := handleOpenSession(context.Background(), callTool("dataql_open_session", map[string]interface{}{
+		"sources": []interface{}{simpleFixture},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var response struct {
+		SessionID string `json:"session_id"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &response))
+	require.NotEmpty(t, response.SessionID)
+	return response.SessionID
+}
+
+func TestSession_QueryWithoutReimport(t *testing.T) {
+	sessions = newSessionManager(defaultMaxSessions)
+	defer sessions.closeAll()
+
+	allowWrites = true
+	defer func() { allowWrites = false }()
+
+	id := openSession(t)
+
+	// State created by one call is visible to the next, so the data is not re-imported
+	result, err := handleQuery(context.Background(), callTool("dataql_query", map[string]interface{}{
+		"session_id": id,
+		"query":      "CREATE TABLE names AS SELECT name FROM simple WHERE id > 1",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	result, err = handleQuery(context.Background(), callTool("dataql_query", map[string]interface{}{
+		"session_id": id,
+		"query":      "SELECT name FROM names ORDER BY name",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Contains(t, resultText(t, result), "Bob")
+	assert.Contains(t, resultText(t, result), "Jane")
+	assert.NotContains(t, resultText(t, result), "John")
+
+	result, err = handleDescribe(context.Background(), callTool("dataql_describe", map[string]interface{}{
+		"session_id": id,
+		"top_n":      float64(0),
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+	assert.Contains(t, resultText(t, result), `"rows": 3`)
+
+	result, err = handleCloseSession(context.Background(), callTool("dataql_close_session", map[string]interface{}{
+		"session_id": id,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	result, err = handleQuery(context.Background(), callTool("dataql_query", map[string]interface{}{
+		"session_id": id,
+		"query":      "SELECT * FROM simple",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "not found")
+}
+
+func TestSession_EvictsLeastRecentlyUsed(t *testing.T) {
+	sessions = newSessionManager(2)
+	defer sessions.closeAll()
+
+	first := openSession(t)
+	second := openSession(t)
+
+	// Using the first session makes the second one the least recently used
+	_, err := sessions.get(first)
+	require.NoError(t, err)
+
+	third := openSession(t)
+
+	_, err = sessions.get(first)
+	assert.NoError(t, err)
+	_, err = sessions.get(second)
+	assert.Error(t, err)
+	_, err = sessions.get(third)
+	assert.NoError(t, err)
+}
+
+func TestSession_ConcurrentCommands(t *testing.T) {
+	sessions = newSessionManager(defaultMaxSessions)
+	defer sessions.closeAll()
+
+	ids := []string{openSession(t), openSession(t)}
+
+	// Every call gets its own output, even when sessions run at the same time
+	var wg sync.WaitGroup
+	outputs := make([]string, 8)
+	errs := make([]error, len(outputs))
+	for i := range outputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s, err := sessions.get(ids[i%len(ids)])
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			outputs[i], errs[i] = s.exec(".schema simple")
+		}(i)
+	}
+	wg.Wait()
+
+	for i, output := range outputs {
+		require.NoError(t, errs[i])
+		assert.Equal(t, 1, strings.Count(output, "email"), output)
+	}
+}
+
+func TestOpenSession_MissingSources(t *testing.T) {
+	result, err := handleOpenSession(context.Background(), callTool("dataql_open_session", map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,336 @@
+package mcpctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/internal/dataql"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// expectations are the data quality rules checked by dataql_validate
+type expectations struct {
+	requiredColumns []string
+	columnTypes     []columnType
+	notNull         []string
+	unique          []string // Key columns; composite keys are comma-separated (e.g. "order_id,line")
+	ranges          []valueRange
+}
+
+// columnType is the SQL type a column is expected to have
+type columnType struct {
+	column   string
+	typeName string
+}
+
+// valueRange bounds the values of a column; a nil bound is not checked
+type valueRange struct {
+	column string
+	min    interface{}
+	max    interface{}
+}
+
+// checkResult is the outcome of one expectation
+type checkResult struct {
+	Check       string `json:"check"` // required_column, type, not_null, unique or range
+	Column      string `json:"column"`
+	Passed      bool   `json:"passed"`
+	Expected    string `json:"expected,omitempty"`
+	Actual      string `json:"actual,omitempty"`
+	FailingRows int64  `json:"failing_rows,omitempty"` // Rows (or duplicated keys) violating the expectation
+	Message     string `json:"message,omitempty"`
+}
+
+// validationReport is the JSON returned by dataql_validate
+type validationReport struct {
+	Table  string        `json:"table"`
+	Rows   int64         `json:"rows"`
+	Passed bool          `json:"passed"`
+	Failed int           `json:"failed"`
+	Checks []checkResult `json:"checks"`
+}
+
+func handleValidate(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sessionID := getStringArg(request, "session_id")
+	source := getStringArg(request, "source")
+	if source == "" && sessionID == "" {
+		return mcp.NewToolResultError("source or session_id parameter is required"), nil
+	}
+
+	exp, err := getExpectations(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	delimiter := getStringArg(request, "delimiter")
+	if delimiter == "" {
+		delimiter = ","
+	}
+
+	table := getStringArg(request, "table")
+
+	var report *validationReport
+	if sessionID != "" {
+		sess, err := sessions.get(sessionID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if table == "" {
+			if table, err = sess.onlyTable(); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+		report, err = sess.validate(table, exp)
+	} else {
+		if table == "" {
+			table = getTableName(source)
+		}
+		report, err = validateSource(source, delimiter, table, exp)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Validation failed: %v", err)), nil
+	}
+
+	jsonBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Validation failed: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonBytes)), nil
+}
+
+// getExpectations reads the expectation arguments of dataql_validate
+func getExpectations(request mcp.CallToolRequest) (expectations, error) {
+	exp := expectations{
+		requiredColumns: getStringArrayArg(request, "required_columns"),
+		notNull:         getStringArrayArg(request, "not_null"),
+		unique:          getStringArrayArg(request, "unique"),
+	}
+
+	args, _ := request.Params.Arguments.(map[string]interface{})
+
+	if types, ok := args["column_types"].(map[string]interface{}); ok {
+		for column, value := range types {
+			typeName, ok := value.(string)
+			if !ok || typeName == "" {
+				return exp, fmt.Errorf("column_types.%s must be a type name such as BIGINT or VARCHAR", column)
+			}
+			exp.columnTypes = append(exp.columnTypes, columnType{column: column, typeName: typeName})
+		}
+		sort.Slice(exp.columnTypes, func(i, j int) bool { return exp.columnTypes[i].column < exp.columnTypes[j].column })
+	}
+
+	if ranges, ok := args["ranges"].(map[string]interface{}); ok {
+		for column, value := range ranges {
+			bounds, ok := value.(map[string]interface{})
+			if !ok || (bounds["min"] == nil && bounds["max"] == nil) {
+				return exp, fmt.Errorf("ranges.%s must be an object with min and/or max", column)
+			}
+			exp.ranges = append(exp.ranges, valueRange{column: column, min: bounds["min"], max: bounds["max"]})
+		}
+		sort.Slice(exp.ranges, func(i, j int) bool { return exp.ranges[i].column < exp.ranges[j].column })
+	}
+
+	if len(exp.requiredColumns) == 0 && len(exp.columnTypes) == 0 && len(exp.notNull) == 0 &&
+		len(exp.unique) == 0 && len(exp.ranges) == 0 {
+		return exp, fmt.Errorf("at least one expectation is required: required_columns, column_types, not_null, unique or ranges")
+	}
+	return exp, nil
+}
+
+// validateSource imports source and checks table against the expectations
+func validateSource(source, delimiter, table string, exp expectations) (*validationReport, error) {
+	dql, err := newDataQL(dataql.Params{
+		FileInputs: []string{source},
+		Delimiter:  delimiter,
+		Quiet:      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer dql.Close()
+
+	return validateTable(dql, table, exp)
+}
+
+// validateTable checks table against the expectations. Failed expectations
+// are reported in the result; an error means the checks could not run.
+func validateTable(dql dataql.DataQL, table string, exp expectations) (*validationReport, error) {
+	schemas, err := dql.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	var columns map[string]dataql.ColumnSchema
+	for _, schema := range schemas {
+		if strings.EqualFold(schema.Name, table) {
+			table = schema.Name
+			columns = make(map[string]dataql.ColumnSchema, len(schema.Columns))
+			for _, col := range schema.Columns {
+				columns[strings.ToLower(col.Name)] = col
+			}
+		}
+	}
+	if columns == nil {
+		return nil, fmt.Errorf("table %s not found", table)
+	}
+
+	report := &validationReport{Table: table, Checks: make([]checkResult, 0)}
+	if report.Rows, err = countRows(dql, fmt.Sprintf("SELECT COUNT(*) FROM %s", storage.QuoteIdentifier(table))); err != nil {
+		return nil, err
+	}
+
+	// lookup returns the column, or adds a failed check when it does not exist
+	lookup := func(check, column string) (dataql.ColumnSchema, bool) {
+		col, ok := columns[strings.ToLower(column)]
+		if !ok {
+			report.Checks = append(report.Checks, checkResult{Check: check, Column: column, Message: "column not found"})
+		}
+		return col, ok
+	}
+
+	for _, column := range exp.requiredColumns {
+		if _, ok := lookup("required_column", column); ok {
+			report.Checks = append(report.Checks, checkResult{Check: "required_column", Column: column, Passed: true})
+		}
+	}
+
+	for _, expectedType := range exp.columnTypes {
+		col, ok := lookup("type", expectedType.column)
+		if !ok {
+			continue
+		}
+		expected := strings.ToUpper(strings.TrimSpace(expectedType.typeName))
+		actual := strings.ToUpper(col.Type)
+		report.Checks = append(report.Checks, checkResult{
+			Check:    "type",
+			Column:   expectedType.column,
+			Passed:   actual == expected || strings.HasPrefix(actual, expected+"("),
+			Expected: expected,
+			Actual:   col.Type,
+		})
+	}
+
+	for _, column := range exp.notNull {
+		col, ok := lookup("not_null", column)
+		if !ok {
+			continue
+		}
+		nulls, err := countRows(dql, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s IS NULL", storage.QuoteIdentifier(table), storage.QuoteIdentifier(col.Name)))
+		if err != nil {
+			return nil, err
+		}
+		report.Checks = append(report.Checks, failingRowsCheck("not_null", column, nulls, "%d null values"))
+	}
+
+	for _, key := range exp.unique {
+		var keyColumns, notNull []string
+		for _, column := range strings.Split(key, ",") {
+			col, ok := columns[strings.ToLower(strings.TrimSpace(column))]
+			if !ok {
+				report.Checks = append(report.Checks, checkResult{Check: "unique", Column: key, Message: fmt.Sprintf("column %s not found", strings.TrimSpace(column))})
+				keyColumns = nil
+				break
+			}
+			keyColumns = append(keyColumns, storage.QuoteIdentifier(col.Name))
+			notNull = append(notNull, storage.QuoteIdentifier(col.Name)+" IS NOT NULL")
+		}
+		if keyColumns == nil {
+			continue
+		}
+
+		// As with SQL UNIQUE constraints, keys containing NULL are not compared
+		keyList := strings.Join(keyColumns, ", ")
+		duplicates, err := countRows(dql, fmt.Sprintf("SELECT COUNT(*) FROM (SELECT %s FROM %s WHERE %s GROUP BY %s HAVING COUNT(*) > 1) AS duplicates",
+			keyList, storage.QuoteIdentifier(table), strings.Join(notNull, " AND "), keyList))
+		if err != nil {
+			return nil, err
+		}
+		report.Checks = append(report.Checks, failingRowsCheck("unique", key, duplicates, "%d duplicated keys"))
+	}
+
+	for _, bounds := range exp.ranges {
+		column := bounds.column
+		col, ok := lookup("range", column)
+		if !ok {
+			continue
+		}
+
+		var conditions, expected []string
+		name := storage.QuoteIdentifier(col.Name)
+		if bounds.min != nil {
+			literal, err := sqlLiteral(bounds.min)
+			if err != nil {
+				return nil, fmt.Errorf("invalid min of ranges.%s: %w", column, err)
+			}
+			conditions = append(conditions, fmt.Sprintf("%s < %s", name, literal))
+			expected = append(expected, ">= "+literal)
+		}
+		if bounds.max != nil {
+			literal, err := sqlLiteral(bounds.max)
+			if err != nil {
+				return nil, fmt.Errorf("invalid max of ranges.%s: %w", column, err)
+			}
+			conditions = append(conditions, fmt.Sprintf("%s > %s", name, literal))
+			expected = append(expected, "<= "+literal)
+		}
+
+		outside, err := countRows(dql, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", storage.QuoteIdentifier(table), strings.Join(conditions, " OR ")))
+		if err != nil {
+			return nil, err
+		}
+		check := failingRowsCheck("range", column, outside, "%d values out of range")
+		check.Expected = strings.Join(expected, " and ")
+		report.Checks = append(report.Checks, check)
+	}
+
+	report.Passed = true
+	for _, check := range report.Checks {
+		if !check.Passed {
+			report.Passed = false
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// failingRowsCheck builds the result of a check that passes when no row violates it
+func failingRowsCheck(check, column string, failing int64, format string) checkResult {
+	result := checkResult{Check: check, Column: column, Passed: failing == 0, FailingRows: failing}
+	if failing > 0 {
+		result.Message = fmt.Sprintf(format, failing)
+	}
+	return result
+}
+
+// countRows runs a COUNT query and returns its value
+func countRows(dql dataql.DataQL, query string) (int64, error) {
+	result, err := dql.Query(query, 1)
+	if err != nil {
+		return 0, err
+	}
+	if len(result.Rows) == 0 || len(result.Rows[0]) == 0 {
+		return 0, nil
+	}
+	count, ok := result.Rows[0][0].(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected count value %v", result.Rows[0][0])
+	}
+	return count, nil
+}
+
+// sqlLiteral formats a JSON number or string as a SQL literal
+func sqlLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'", nil
+	}
+	return "", fmt.Errorf("expected a number or a string, got %v", value)
+}
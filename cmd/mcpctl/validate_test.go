@@ -0,0 +1,96 @@
+package mcpctl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const nullValuesFixture = "../../tests/fixtures/csv/null_values.csv"
+
+// validate calls dataql_validate and decodes the report
+func validate(t *testing.T, args map[string]interface{}) validationReport {
+	t.Helper()
+
+	result, err := handleValidate(context.Background(), callTool("dataql_validate", args))
+	require.NoError(t, err)
+	require.False(t, result.IsError, resultText(t, result))
+
+	var report validationReport
+	require.NoError(t, json.Unmarshal([]byte(resultText(t, result)), &report))
+	return report
+}
+
+func TestHandleValidate_Passes(t *testing.T) {
+	report := validate(t, map[string]interface{}{
+		"source":           simpleFixture,
+		"required_columns": []interface{}{"id", "name", "email"},
+		"column_types":     map[string]interface{}{"id": "bigint", "name": "VARCHAR"},
+		"not_null":         []interface{}{"id", "email"},
+		"unique":           []interface{}{"id", "name,email"},
+		"ranges":           map[string]interface{}{"id": map[string]interface{}{"min": float64(1), "max": float64(3)}},
+	})
+
+	assert.Equal(t, "simple", report.Table)
+	assert.Equal(t, int64(3), report.Rows)
+	assert.True(t, report.Passed)
+	assert.Zero(t, report.Failed)
+	assert.Len(t, report.Checks, 10)
+}
+
+func TestHandleValidate_Failures(t *testing.T) {
+	report := validate(t, map[string]interface{}{
+		"source":           nullValuesFixture,
+		"required_columns": []interface{}{"id", "created_at"},
+		"column_types":     map[string]interface{}{"id": "VARCHAR"},
+		"not_null":         []interface{}{"value"},
+		"unique":           []interface{}{"status"},
+		"ranges":           map[string]interface{}{"value": map[string]interface{}{"min": float64(50)}},
+	})
+
+	assert.False(t, report.Passed)
+	assert.Equal(t, 5, report.Failed)
+
+	checks := make(map[string]checkResult)
+	for _, check := range report.Checks {
+		checks[check.Check+":"+check.Column] = check
+	}
+
+	assert.True(t, checks["required_column:id"].Passed)
+	assert.Equal(t, "column not found", checks["required_column:created_at"].Message)
+	assert.Equal(t, "BIGINT", checks["type:id"].Actual)
+	assert.Equal(t, int64(2), checks["not_null:value"].FailingRows)
+	assert.Equal(t, int64(3), checks["unique:status"].FailingRows) // active, inactive and the empty status repeat
+	assert.Equal(t, int64(1), checks["range:value"].FailingRows)   // 0 is below the minimum
+	assert.Equal(t, ">= 50", checks["range:value"].Expected)
+}
+
+func TestHandleValidate_Session(t *testing.T) {
+	sessions = newSessionManager(defaultMaxSessions)
+	defer sessions.closeAll()
+
+	id := openSession(t)
+
+	report := validate(t, map[string]interface{}{
+		"session_id": id,
+		"unique":     []interface{}{"id"},
+	})
+	assert.Equal(t, "simple", report.Table)
+	assert.True(t, report.Passed)
+}
+
+func TestHandleValidate_Errors(t *testing.T) {
+	for _, args := range []map[string]interface{}{
+		{"required_columns": []interface{}{"id"}},
+		{"source": simpleFixture},
+		{"source": simpleFixture, "ranges": map[string]interface{}{"id": map[string]interface{}{}}},
+		{"source": simpleFixture, "table": "missing", "not_null": []interface{}{"id"}},
+	} {
+		result, err := handleValidate(context.Background(), callTool("dataql_validate", args))
+		require.NoError(t, err)
+		assert.True(t, result.IsError, args)
+	}
+}
@@ -0,0 +1,255 @@
+package mergectl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/spf13/cobra"
+)
+
+const (
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	exportParam             = "export"
+	exportShortParam        = "e"
+	typeParam               = "type"
+	typeShortParam          = "t"
+	schemaParam             = "schema"
+	sourceColumnParam       = "source-column"
+)
+
+// Schema unification modes
+const (
+	schemaUnion        = "union"
+	schemaStrict       = "strict"
+	schemaIntersection = "intersection"
+)
+
+// partPrefix names the table each input is imported as
+const partPrefix = "merge_part_"
+
+// MergeCtl is the interface for the merge controller
+type MergeCtl interface {
+	Command() *cobra.Command
+}
+
+type mergeCtl struct {
+	delimiter    string
+	export       string
+	exportType   string
+	schema       string
+	sourceColumn string
+}
+
+// New creates a new MergeCtl instance
+func New() MergeCtl {
+	return &mergeCtl{}
+}
+
+// Command returns the cobra command for the merge subcommand
+func (c *mergeCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "merge <file or glob>...",
+		Short: "Concatenate many files into one dataset",
+		Long: `Concatenate files, such as daily partitions, into one dataset. Inputs may be
+glob patterns (quote them so the shell does not expand them) and may mix
+formats. Columns are matched by name; their order may differ between files.
+
+--schema decides how files with different columns are combined:
+  union         all columns of all files; missing values are NULL (default)
+  strict        every file must have the same columns
+  intersection  only the columns present in every file
+
+A column with different types in different files gets a type that holds all
+values (e.g. BIGINT and DOUBLE become DOUBLE, anything and VARCHAR becomes
+VARCHAR).`,
+		Example: `  dataql merge 'parts/*.csv' -e combined.parquet
+  dataql merge 'logs/2024-*.jsonl' 'logs/2025-*.jsonl' -e logs.parquet --source-column file
+  dataql merge a.csv b.xlsx -e all.csv --schema intersection`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: c.runE,
+	}
+
+	command.Flags().StringVarP(&c.export, exportParam, exportShortParam, "", "output file (required)")
+	command.Flags().StringVarP(&c.exportType, typeParam, typeShortParam, "", "output format (default: from the output file extension)")
+	command.Flags().StringVarP(&c.delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter")
+	command.Flags().StringVar(&c.schema, schemaParam, schemaUnion, "schema unification: union, strict or intersection")
+	command.Flags().StringVar(&c.sourceColumn, sourceColumnParam, "", "add a column with the input file of each row")
+	_ = command.MarkFlagRequired(exportParam)
+
+	return command
+}
+
+func (c *mergeCtl) runE(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	switch c.schema {
+	case schemaUnion, schemaStrict, schemaIntersection:
+	default:
+		return fmt.Errorf("invalid --%s %q: expected %s, %s or %s", schemaParam, c.schema, schemaUnion, schemaStrict, schemaIntersection)
+	}
+
+	files, err := expandInputs(args)
+	if err != nil {
+		return err
+	}
+
+	sources := make([]string, len(files))
+	for i, file := range files {
+		sources[i] = fmt.Sprintf("%s:%s%d", file, partPrefix, i+1)
+	}
+
+	db, err := dataql.OpenWithOptions(dataql.Options{Delimiter: c.delimiter}, sources...)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tables, err := db.Tables()
+	if err != nil {
+		return err
+	}
+	parts := partColumns(tables, len(files))
+
+	query, err := mergeQuery(files, parts, c.schema, c.sourceColumn)
+	if err != nil {
+		return err
+	}
+	rows, err := db.Export(cmd.Context(), query, c.export, c.exportType)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Merged %d file(s) into %s (%d rows)\n", len(files), c.export, rows)
+	return nil
+}
+
+// expandInputs expands glob patterns into the matching files, sorted, and
+// keeps other inputs (URLs, plain paths) as given
+func expandInputs(inputs []string) ([]string, error) {
+	var files []string
+	for _, input := range inputs {
+		if strings.Contains(input, "://") || !strings.ContainsAny(input, "*?[") {
+			files = append(files, input)
+			continue
+		}
+
+		matches, err := filepath.Glob(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", input, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", input)
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// partColumns returns the column names of each imported part, in input order
+func partColumns(tables []dataql.Table, count int) [][]string {
+	parts := make([][]string, count)
+	for _, table := range tables {
+		var index int
+		if _, err := fmt.Sscanf(table.Name, partPrefix+"%d", &index); err != nil || index < 1 || index > count {
+			continue
+		}
+		for _, column := range table.Columns {
+			parts[index-1] = append(parts[index-1], column.Name)
+		}
+	}
+	return parts
+}
+
+// mergeQuery combines the parts by column name according to the schema mode
+func mergeQuery(files []string, parts [][]string, schema, sourceColumn string) (string, error) {
+	columns := "*"
+	switch schema {
+	case schemaStrict:
+		if err := checkSameColumns(files, parts); err != nil {
+			return "", err
+		}
+	case schemaIntersection:
+		common := commonColumns(parts)
+		if len(common) == 0 {
+			return "", fmt.Errorf("the files have no columns in common")
+		}
+		quoted := make([]string, len(common))
+		for i, column := range common {
+			quoted[i] = storage.QuoteIdentifier(column)
+		}
+		columns = strings.Join(quoted, ", ")
+	}
+
+	selects := make([]string, len(files))
+	for i, file := range files {
+		selects[i] = fmt.Sprintf("SELECT %s FROM %s%d", columns, partPrefix, i+1)
+		if sourceColumn != "" {
+			selects[i] = fmt.Sprintf("SELECT %s, '%s' AS %s FROM %s%d", columns, strings.ReplaceAll(file, "'", "''"), storage.QuoteIdentifier(sourceColumn), partPrefix, i+1)
+		}
+	}
+	query := strings.Join(selects, " UNION ALL BY NAME ")
+	if sourceColumn != "" {
+		// Columns come in order of first appearance; keep the source last
+		query = fmt.Sprintf("SELECT * EXCLUDE (%[1]s), %[1]s FROM (%[2]s)", storage.QuoteIdentifier(sourceColumn), query)
+	}
+	return query, nil
+}
+
+// checkSameColumns reports the first file whose columns differ from the
+// columns of the first file
+func checkSameColumns(files []string, parts [][]string) error {
+	expected := columnSet(parts[0])
+	for i := 1; i < len(parts); i++ {
+		actual := columnSet(parts[i])
+		var missing, extra []string
+		for _, column := range parts[0] {
+			if !actual[strings.ToLower(column)] {
+				missing = append(missing, column)
+			}
+		}
+		for _, column := range parts[i] {
+			if !expected[strings.ToLower(column)] {
+				extra = append(extra, column)
+			}
+		}
+		if len(missing) > 0 || len(extra) > 0 {
+			return fmt.Errorf("%s does not match the columns of %s (missing: [%s], extra: [%s]); use --%s union or intersection",
+				files[i], files[0], strings.Join(missing, ", "), strings.Join(extra, ", "), schemaParam)
+		}
+	}
+	return nil
+}
+
+// commonColumns returns the columns present in every part, in the order of
+// the first part
+func commonColumns(parts [][]string) []string {
+	var common []string
+	for _, column := range parts[0] {
+		inAll := true
+		for _, part := range parts[1:] {
+			if !columnSet(part)[strings.ToLower(column)] {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			common = append(common, column)
+		}
+	}
+	return common
+}
+
+func columnSet(columns []string) map[string]bool {
+	set := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		set[strings.ToLower(column)] = true
+	}
+	return set
+}
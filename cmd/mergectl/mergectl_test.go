@@ -0,0 +1,54 @@
+package mergectl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeQuery(t *testing.T) {
+	files := []string{"day1.csv", "day2.csv"}
+
+	tests := []struct {
+		name         string
+		parts        [][]string
+		schema       string
+		sourceColumn string
+		expected     string
+		err          string
+	}{
+		{"union", [][]string{{"id", "amount"}, {"amount", "id", "note"}}, schemaUnion, "",
+			"SELECT * FROM merge_part_1 UNION ALL BY NAME SELECT * FROM merge_part_2", ""},
+		{"intersection", [][]string{{"id", "amount"}, {"AMOUNT", "note"}}, schemaIntersection, "",
+			`SELECT "amount" FROM merge_part_1 UNION ALL BY NAME SELECT "amount" FROM merge_part_2`, ""},
+		{"source column", [][]string{{"id"}, {"id"}}, schemaUnion, "file",
+			`SELECT * EXCLUDE ("file"), "file" FROM (SELECT *, 'day1.csv' AS "file" FROM merge_part_1 UNION ALL BY NAME SELECT *, 'day2.csv' AS "file" FROM merge_part_2)`, ""},
+		{"strict", [][]string{{"id", "amount"}, {"Amount", "ID"}}, schemaStrict, "",
+			"SELECT * FROM merge_part_1 UNION ALL BY NAME SELECT * FROM merge_part_2", ""},
+		{"strict mismatch", [][]string{{"id", "amount"}, {"amount", "id", "note"}}, schemaStrict, "",
+			"", "day2.csv does not match the columns of day1.csv (missing: [], extra: [note])"},
+		{"nothing in common", [][]string{{"id"}, {"note"}}, schemaIntersection, "",
+			"", "no columns in common"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := mergeQuery(files, tt.parts, tt.schema, tt.sourceColumn)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
+
+func TestExpandInputs(t *testing.T) {
+	files, err := expandInputs([]string{"https://example.com/day*.csv", "plain.csv"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/day*.csv", "plain.csv"}, files)
+
+	_, err = expandInputs([]string{t.TempDir() + "/day*.csv"})
+	assert.ErrorContains(t, err, "no files match")
+}
@@ -0,0 +1,140 @@
+package modelsctl
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/adrianolaselva/dataql/pkg/models"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	dirParam                = "dir"
+	storageParam            = "storage"
+	storageShortParam       = "s"
+	fileParam               = "file"
+	fileShortParam          = "f"
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	fullRefreshParam        = "full-refresh"
+)
+
+// ModelsCtl is the interface for the models controller
+type ModelsCtl interface {
+	Command() *cobra.Command
+}
+
+type modelsCtl struct {
+	dir string
+}
+
+// New creates a new ModelsCtl instance
+func New() ModelsCtl {
+	return &modelsCtl{}
+}
+
+// Command returns the cobra command for the models subcommand
+func (c *modelsCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "models",
+		Short: "Build a directory of SQL models into a storage file",
+		Long: `Build a directory of SQL models into a DuckDB storage file.
+
+Each .sql file holds a SELECT statement materialized as a table named after
+the file. Models reference each other with {{ ref('model') }}: references
+decide the build order and compile to the table of the referenced model.
+
+  -- models/paid_orders.sql
+  SELECT * FROM orders WHERE status = 'paid'
+
+  -- models/revenue.sql
+  SELECT customer_id, SUM(amount) AS total FROM {{ ref('paid_orders') }} GROUP BY 1
+
+Only models whose SQL changed, whose table is missing or whose upstream models
+were rebuilt are built again; use --full-refresh after the source data changes.`,
+		Example: `  dataql models run -s warehouse.duckdb -f orders.csv
+  dataql models run --dir analytics/models -s warehouse.duckdb --full-refresh
+  dataql models list`,
+	}
+
+	command.PersistentFlags().StringVar(&c.dir, dirParam, "models", "directory of the .sql models")
+
+	command.AddCommand(c.runCommand())
+	command.AddCommand(c.listCommand())
+
+	return command
+}
+
+func (c *modelsCtl) runCommand() *cobra.Command {
+	var opts models.BuildOptions
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Build the models in dependency order",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			project, err := models.Load(c.dir)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			results, err := project.Build(ctx, opts, os.Stderr)
+			if err != nil {
+				return err
+			}
+
+			built := 0
+			for _, result := range results {
+				if result.Status == models.StatusBuilt {
+					built++
+				}
+			}
+			fmt.Fprintf(os.Stderr, "Built %d of %d model(s) into %s\n", built, len(results), opts.Storage)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Storage, storageParam, storageShortParam, "", "DuckDB file the models are materialized into")
+	cmd.Flags().StringArrayVarP(&opts.Sources, fileParam, fileShortParam, []string{}, "sources imported before the build (default: read the tables of the storage file)")
+	cmd.Flags().StringVarP(&opts.Delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter")
+	cmd.Flags().BoolVar(&opts.FullRefresh, fullRefreshParam, false, "rebuild every model")
+	_ = cmd.MarkFlagRequired(storageParam)
+
+	return cmd
+}
+
+func (c *modelsCtl) listCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the models in build order with their references",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			project, err := models.Load(c.dir)
+			if err != nil {
+				return err
+			}
+
+			tbl := table.New("#", "Model", "References", "File").
+				WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+				WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+				WithWriter(os.Stdout)
+
+			for i, model := range project.Models {
+				tbl.AddRow(i+1, model.Name, strings.Join(model.Refs, ", "), model.Path)
+			}
+
+			tbl.Print()
+			return nil
+		},
+	}
+}
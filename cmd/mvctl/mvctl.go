@@ -0,0 +1,317 @@
+package mvctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	storageParam            = "storage"
+	storageShortParam       = "s"
+	sqlParam                = "sql"
+	sourcesParam            = "sources"
+	sourcesShortParam       = "f"
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	forceParam              = "force"
+
+	// maxQueryWidth truncates long queries in the listing
+	maxQueryWidth = 60
+)
+
+// MvCtl is the interface for the materialized views controller
+type MvCtl interface {
+	Command() *cobra.Command
+}
+
+type mvCtl struct {
+	storage string
+}
+
+// New creates a new MvCtl instance
+func New() MvCtl {
+	return &mvCtl{}
+}
+
+// Command returns the cobra command for the mv subcommand
+func (c *mvCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "mv",
+		Short: "Materialized views in a DuckDB storage file",
+		Long: `Keep the results of queries as tables of a DuckDB storage file and recompute
+them from their sources on demand, as a lightweight reporting layer.
+
+A materialized view records its query and the sources it reads. Refreshing it
+re-imports the sources, replacing their tables in the storage file, and
+recomputes the view. Views are refreshed in the order they were created, so a
+view can read the views created before it. Without sources, a view reads the
+tables already in the storage file.`,
+		Example: `  dataql mv create daily_rev -s store.duckdb -f sales.csv \
+    --sql "SELECT date, SUM(amount) AS revenue FROM sales GROUP BY date"
+  dataql mv refresh -s store.duckdb
+  dataql mv list -s store.duckdb
+  dataql run -s store.duckdb -q "SELECT * FROM daily_rev ORDER BY date DESC LIMIT 7"
+  dataql mv drop daily_rev -s store.duckdb`,
+	}
+
+	command.PersistentFlags().StringVarP(&c.storage, storageParam, storageShortParam, "", "DuckDB storage file holding the views")
+	_ = command.MarkPersistentFlagRequired(storageParam)
+
+	command.AddCommand(c.createCommand())
+	command.AddCommand(c.refreshCommand())
+	command.AddCommand(c.listCommand())
+	command.AddCommand(c.dropCommand())
+
+	return command
+}
+
+func (c *mvCtl) createCommand() *cobra.Command {
+	var v view
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a materialized view",
+		Long: `Import the sources, if any, into the storage file (created if needed) and
+store the result of the query as a table. Local sources are recorded as
+absolute paths, so the view can be refreshed from any directory.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			ctx := context.Background()
+			v.Name = args[0]
+
+			if !namePattern.MatchString(v.Name) {
+				return fmt.Errorf("invalid view name %q: use letters, digits and '_'", v.Name)
+			}
+			if err := c.checkName(ctx, v.Name, force); err != nil {
+				return err
+			}
+
+			for i, source := range v.Sources {
+				abs, err := storedSource(source)
+				if err != nil {
+					return err
+				}
+				v.Sources[i] = abs
+			}
+
+			start := time.Now()
+			if err := refreshView(ctx, c.storage, &v); err != nil {
+				return err
+			}
+
+			fmt.Printf("Created materialized view %s: %d rows in %s\n", v.Name, v.Rows, time.Since(start).Round(time.Millisecond))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&v.SQL, sqlParam, "", "query computing the view")
+	cmd.Flags().StringArrayVarP(&v.Sources, sourcesParam, sourcesShortParam, []string{}, "source imported before the query (repeatable)")
+	cmd.Flags().StringVarP(&v.Delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter")
+	cmd.Flags().BoolVar(&force, forceParam, false, "replace a view with the same name")
+	_ = cmd.MarkFlagRequired(sqlParam)
+
+	return cmd
+}
+
+func (c *mvCtl) refreshCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "refresh [name...]",
+		Short: "Recompute materialized views from their sources",
+		Long: `Re-import the sources of the views and recompute them, all views or the
+named ones. A failing view stops the refresh; the views refreshed before it
+keep their new data.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			ctx := context.Background()
+
+			views, err := c.views(ctx)
+			if err != nil {
+				return err
+			}
+
+			selected := views
+			if len(args) > 0 {
+				selected = nil
+				for _, name := range args {
+					if !hasView(views, name) {
+						return fmt.Errorf("materialized view %s not found in %s", name, c.storage)
+					}
+				}
+				for _, v := range views {
+					if contains(args, v.Name) {
+						selected = append(selected, v)
+					}
+				}
+			}
+			if len(selected) == 0 {
+				fmt.Println("No materialized views.")
+				return nil
+			}
+
+			for i := range selected {
+				start := time.Now()
+				if err := refreshView(ctx, c.storage, &selected[i]); err != nil {
+					return err
+				}
+				fmt.Printf("Refreshed %s: %d rows in %s\n", selected[i].Name, selected[i].Rows, time.Since(start).Round(time.Millisecond))
+			}
+			return nil
+		},
+	}
+}
+
+func (c *mvCtl) listCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List materialized views",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			views, err := c.views(context.Background())
+			if err != nil {
+				return err
+			}
+			if len(views) == 0 {
+				fmt.Println("No materialized views.")
+				return nil
+			}
+
+			tbl := table.New("Name", "Rows", "Refreshed", "Sources", "SQL").
+				WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+				WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+				WithWriter(os.Stdout)
+
+			for _, v := range views {
+				tbl.AddRow(v.Name, v.Rows, v.RefreshedAt.Local().Format("2006-01-02 15:04:05"),
+					strings.Join(v.Sources, ", "), truncate(v.SQL, maxQueryWidth))
+			}
+
+			tbl.Print()
+			return nil
+		},
+	}
+}
+
+func (c *mvCtl) dropCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "drop <name>",
+		Short: "Drop a materialized view and its table",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			ctx := context.Background()
+
+			s, err := openSession(ctx, c.storage, nil, "")
+			if err != nil {
+				return err
+			}
+			defer s.close(ctx)
+
+			views, err := s.views(ctx)
+			if err != nil {
+				return err
+			}
+			if !hasView(views, args[0]) {
+				return fmt.Errorf("materialized view %s not found in %s", args[0], c.storage)
+			}
+			if err := s.drop(ctx, args[0]); err != nil {
+				return err
+			}
+
+			fmt.Printf("Dropped materialized view: %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+// views returns the views of the storage file
+func (c *mvCtl) views(ctx context.Context) ([]view, error) {
+	s, err := openSession(ctx, c.storage, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer s.close(ctx)
+
+	return s.views(ctx)
+}
+
+// checkName refuses to replace an existing view without --force, and a
+// table that is not a view at all
+func (c *mvCtl) checkName(ctx context.Context, name string, force bool) error {
+	if _, err := os.Stat(c.storage); err != nil {
+		return nil
+	}
+
+	s, err := openSession(ctx, c.storage, nil, "")
+	if err != nil {
+		return err
+	}
+	defer s.close(ctx)
+
+	views, err := s.views(ctx)
+	if err != nil {
+		return err
+	}
+	if hasView(views, name) {
+		if !force {
+			return fmt.Errorf("materialized view %s already exists; use --%s to replace it", name, forceParam)
+		}
+		return nil
+	}
+
+	tables, err := s.storedTables(ctx)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if strings.EqualFold(table, name) {
+			return fmt.Errorf("%s already has a table named %s", c.storage, table)
+		}
+	}
+	return nil
+}
+
+// storedSource returns a local source as an absolute path
+func storedSource(source string) (string, error) {
+	if source == "-" {
+		return "", fmt.Errorf("stdin cannot be the source of a materialized view")
+	}
+	if strings.Contains(source, "://") {
+		return source, nil
+	}
+
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve source %s: %w", source, err)
+	}
+	return abs, nil
+}
+
+func hasView(views []view, name string) bool {
+	for _, v := range views {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate shortens a query to width characters on one line
+func truncate(query string, width int) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len([]rune(query)) <= width {
+		return query
+	}
+	return string([]rune(query)[:width-3]) + "..."
+}
@@ -0,0 +1,115 @@
+package mvctl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runMv(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	cmd := New().Command()
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	runErr := cmd.Execute()
+
+	require.NoError(t, writer.Close())
+	var out bytes.Buffer
+	_, err = io.Copy(&out, reader)
+	require.NoError(t, err)
+	return out.String(), runErr
+}
+
+// queryInt runs a query returning one integer on the storage file
+func queryInt(t *testing.T, storage, query string) int64 {
+	t.Helper()
+
+	db, err := dataql.OpenWithOptions(dataql.Options{Storage: storage})
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query(context.Background(), query)
+	require.NoError(t, err)
+	defer rows.Close()
+	require.True(t, rows.Next())
+	var value int64
+	require.NoError(t, rows.Scan(&value))
+	return value
+}
+
+func TestMv_CreateRefreshDrop(t *testing.T) {
+	dir := t.TempDir()
+	sales := filepath.Join(dir, "sales.csv")
+	require.NoError(t, os.WriteFile(sales, []byte("day,amount\n1,10\n1,5\n2,7\n"), 0644))
+	storage := filepath.Join(dir, "store.duckdb")
+
+	out, err := runMv(t, "create", "daily_rev", "-s", storage, "-f", sales,
+		"--sql", "SELECT day, SUM(amount) AS revenue FROM sales GROUP BY day")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Created materialized view daily_rev: 2 rows")
+
+	// A view without sources reads the tables of the storage file
+	out, err = runMv(t, "create", "total", "-s", storage, "--sql", "SELECT SUM(revenue) AS total FROM daily_rev;")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Created materialized view total: 1 rows")
+
+	_, err = runMv(t, "create", "total", "-s", storage, "--sql", "SELECT 1")
+	assert.ErrorContains(t, err, "already exists")
+	_, err = runMv(t, "create", "sales", "-s", storage, "--sql", "SELECT 1")
+	assert.ErrorContains(t, err, "already has a table named sales")
+
+	require.NoError(t, os.WriteFile(sales, []byte("day,amount\n1,10\n1,5\n2,7\n3,100\n"), 0644))
+	out, err = runMv(t, "refresh", "-s", storage)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Refreshed daily_rev: 3 rows")
+	assert.Contains(t, out, "Refreshed total: 1 rows")
+
+	assert.Equal(t, int64(4), queryInt(t, storage, "SELECT COUNT(*) FROM sales"), "re-imported rows replace the old ones")
+	assert.Equal(t, int64(122), queryInt(t, storage, "SELECT CAST(total AS BIGINT) FROM total"))
+
+	out, err = runMv(t, "list", "-s", storage)
+	require.NoError(t, err)
+	assert.Contains(t, out, "daily_rev")
+	assert.Contains(t, out, sales)
+
+	_, err = runMv(t, "refresh", "missing", "-s", storage)
+	assert.ErrorContains(t, err, "materialized view missing not found")
+
+	out, err = runMv(t, "drop", "total", "-s", storage)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Dropped materialized view: total")
+
+	_, err = runMv(t, "refresh", "total", "-s", storage)
+	assert.ErrorContains(t, err, "materialized view total not found")
+
+	out, err = runMv(t, "list", "-s", storage)
+	require.NoError(t, err)
+	assert.NotContains(t, out, "total")
+}
+
+func TestMv_MissingStorage(t *testing.T) {
+	storage := filepath.Join(t.TempDir(), "missing.duckdb")
+
+	_, err := runMv(t, "create", "v", "-s", storage, "--sql", "SELECT 1")
+	assert.ErrorContains(t, err, "storage file does not exist")
+
+	_, err = runMv(t, "create", "bad-name", "-s", storage, "--sql", "SELECT 1")
+	assert.ErrorContains(t, err, "invalid view name")
+
+	_, err = runMv(t, "list", "-s", storage)
+	assert.ErrorContains(t, err, "storage file does not exist")
+}
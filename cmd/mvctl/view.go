@@ -0,0 +1,303 @@
+package mvctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+const (
+	// viewsTable records the materialized views of a storage file
+	viewsTable = "_dataql_views"
+	// storeCatalog is the storage file attached to a session importing sources
+	storeCatalog = "dataql_store"
+	// schemasTable lists the imported tables of a storage file
+	schemasTable = "schemas"
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// view is a materialized view: a table of the storage file holding the
+// result of its query over re-importable sources
+type view struct {
+	Name        string
+	SQL         string
+	Sources     []string
+	Delimiter   string
+	CreatedAt   time.Time
+	RefreshedAt time.Time
+	Rows        int64
+}
+
+// session is a database where the tables of the storage file can be
+// queried and replaced. Sources are imported into an in-memory database
+// that attaches the storage file, so re-importing them replaces their
+// tables instead of appending rows.
+type session struct {
+	db     *dataql.DB
+	prefix string // Qualifies the tables of the storage file
+}
+
+// openSession imports the sources, if any, and opens the storage file
+func openSession(ctx context.Context, storagePath string, sources []string, delimiter string) (*session, error) {
+	if len(sources) == 0 {
+		if _, err := os.Stat(storagePath); err != nil {
+			return nil, fmt.Errorf("storage file does not exist: %s", storagePath)
+		}
+		db, err := dataql.OpenWithOptions(dataql.Options{Storage: storagePath})
+		if err != nil {
+			return nil, err
+		}
+		return &session{db: db}, nil
+	}
+
+	db, err := dataql.OpenWithOptions(dataql.Options{Delimiter: delimiter}, sources...)
+	if err != nil {
+		return nil, err
+	}
+	s := &session{db: db, prefix: storage.QuoteIdentifier(storeCatalog) + ".main."}
+	if err := s.attach(ctx, storagePath); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// attach attaches the storage file, copies the imported tables to it and
+// makes its other tables visible to queries under their own names
+func (s *session) attach(ctx context.Context, storagePath string) error {
+	imported, err := s.tables(ctx, "current_database()")
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Exec(ctx, fmt.Sprintf("ATTACH %s AS %s", quoteLiteral(storagePath), storage.QuoteIdentifier(storeCatalog))); err != nil {
+		return fmt.Errorf("failed to open %s: %w", storagePath, err)
+	}
+
+	hasSchemas := false
+	for _, table := range imported {
+		if table == schemasTable {
+			hasSchemas = true
+			continue
+		}
+		if err := s.db.Exec(ctx, fmt.Sprintf("CREATE OR REPLACE TABLE %s AS SELECT * FROM main.%s", s.table(table), storage.QuoteIdentifier(table))); err != nil {
+			return fmt.Errorf("failed to copy %s to the storage file: %w", table, err)
+		}
+	}
+	if hasSchemas {
+		if err := s.copySchemas(ctx, imported); err != nil {
+			return err
+		}
+	}
+
+	stored, err := s.tables(ctx, quoteLiteral(storeCatalog))
+	if err != nil {
+		return err
+	}
+	for _, table := range stored {
+		if contains(imported, table) || table == viewsTable || table == schemasTable {
+			continue
+		}
+		if err := s.db.Exec(ctx, fmt.Sprintf("CREATE VIEW main.%s AS SELECT * FROM %s", storage.QuoteIdentifier(table), s.table(table))); err != nil {
+			return fmt.Errorf("failed to read %s from the storage file: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// copySchemas replaces the entries of the imported tables in the schemas
+// table of the storage file, which lists them in the REPL
+func (s *session) copySchemas(ctx context.Context, imported []string) error {
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s ("id" INTEGER, "name" VARCHAR, "columns" VARCHAR, "total_columns" INTEGER)`, s.table(schemasTable)),
+	}
+	for _, table := range imported {
+		if table == schemasTable {
+			continue
+		}
+		statements = append(statements,
+			fmt.Sprintf(`DELETE FROM %s WHERE "name" = %s`, s.table(schemasTable), quoteLiteral(table)),
+			fmt.Sprintf(`INSERT INTO %[1]s SELECT (SELECT COALESCE(MAX("id"), 0) + 1 FROM %[1]s), "name", "columns", "total_columns" FROM main.%[2]s WHERE "name" = %[3]s`,
+				s.table(schemasTable), storage.QuoteIdentifier(schemasTable), quoteLiteral(table)))
+	}
+	for _, statement := range statements {
+		if err := s.db.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("failed to update the tables of the storage file: %w", err)
+		}
+	}
+	return nil
+}
+
+// tables returns the tables and views of a database
+func (s *session) tables(ctx context.Context, catalog string) ([]string, error) {
+	rows, err := s.db.Query(ctx, fmt.Sprintf(
+		"SELECT table_name FROM information_schema.tables WHERE table_catalog = %s AND table_schema = 'main' ORDER BY table_name", catalog))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// storedTables returns the tables of the storage file
+func (s *session) storedTables(ctx context.Context) ([]string, error) {
+	if s.prefix == "" {
+		return s.tables(ctx, "current_database()")
+	}
+	return s.tables(ctx, quoteLiteral(storeCatalog))
+}
+
+// table qualifies a table of the storage file
+func (s *session) table(name string) string {
+	return s.prefix + storage.QuoteIdentifier(name)
+}
+
+// ensureViews creates the table recording the views
+func (s *session) ensureViews(ctx context.Context) error {
+	return s.db.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	"name" VARCHAR PRIMARY KEY,
+	"sql" VARCHAR,
+	"sources" VARCHAR,
+	"delimiter" VARCHAR,
+	"created_at" TIMESTAMP,
+	"refreshed_at" TIMESTAMP,
+	"rows" BIGINT
+)`, s.table(viewsTable)))
+}
+
+// views returns the recorded views in creation order, so views reading
+// other views are refreshed after them
+func (s *session) views(ctx context.Context) ([]view, error) {
+	tables, err := s.storedTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(tables, viewsTable) {
+		return nil, nil
+	}
+
+	rows, err := s.db.Query(ctx, fmt.Sprintf(
+		`SELECT "name", "sql", "sources", "delimiter", "created_at", "refreshed_at", "rows" FROM %s ORDER BY "created_at", "name"`, s.table(viewsTable)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []view
+	for rows.Next() {
+		var v view
+		var sources string
+		if err := rows.Scan(&v.Name, &v.SQL, &sources, &v.Delimiter, &v.CreatedAt, &v.RefreshedAt, &v.Rows); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(sources), &v.Sources); err != nil {
+			return nil, fmt.Errorf("invalid sources of %s: %w", v.Name, err)
+		}
+		views = append(views, v)
+	}
+	return views, rows.Err()
+}
+
+// materialize computes the view and records it
+func (s *session) materialize(ctx context.Context, v *view) error {
+	query := strings.TrimRight(strings.TrimSpace(v.SQL), "; \t\n")
+	if err := s.db.Exec(ctx, fmt.Sprintf("CREATE OR REPLACE TABLE %s AS %s", s.table(v.Name), query)); err != nil {
+		return fmt.Errorf("failed to compute %s: %w", v.Name, err)
+	}
+
+	rows, err := s.db.Query(ctx, "SELECT COUNT(*) FROM "+s.table(v.Name))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return fmt.Errorf("failed to count the rows of %s", v.Name)
+	}
+	if err := rows.Scan(&v.Rows); err != nil {
+		return err
+	}
+
+	sources, err := json.Marshal(v.Sources)
+	if err != nil {
+		return err
+	}
+	v.RefreshedAt = time.Now().UTC()
+	if v.CreatedAt.IsZero() {
+		v.CreatedAt = v.RefreshedAt
+	}
+
+	if err := s.db.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE "name" = %s`, s.table(viewsTable), quoteLiteral(v.Name))); err != nil {
+		return fmt.Errorf("failed to record %s: %w", v.Name, err)
+	}
+	err = s.db.Exec(ctx, fmt.Sprintf(`INSERT INTO %s VALUES (%s, %s, %s, %s, %s, %s, %d)`, s.table(viewsTable),
+		quoteLiteral(v.Name), quoteLiteral(v.SQL), quoteLiteral(string(sources)), quoteLiteral(v.Delimiter),
+		quoteTimestamp(v.CreatedAt), quoteTimestamp(v.RefreshedAt), v.Rows))
+	if err != nil {
+		return fmt.Errorf("failed to record %s: %w", v.Name, err)
+	}
+	return nil
+}
+
+// drop removes the view table and its record
+func (s *session) drop(ctx context.Context, name string) error {
+	if err := s.db.Exec(ctx, "DROP TABLE IF EXISTS "+s.table(name)); err != nil {
+		return fmt.Errorf("failed to drop %s: %w", name, err)
+	}
+	return s.db.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE "name" = %s`, s.table(viewsTable), quoteLiteral(name)))
+}
+
+// close detaches the storage file, so it can be opened again, and closes the session
+func (s *session) close(ctx context.Context) error {
+	if s.prefix != "" {
+		_ = s.db.Exec(ctx, "DETACH "+storage.QuoteIdentifier(storeCatalog))
+	}
+	return s.db.Close()
+}
+
+// refreshView re-imports the sources of the view and recomputes it
+func refreshView(ctx context.Context, storagePath string, v *view) error {
+	s, err := openSession(ctx, storagePath, v.Sources, v.Delimiter)
+	if err != nil {
+		return fmt.Errorf("failed to refresh %s: %w", v.Name, err)
+	}
+	defer s.close(ctx)
+
+	if err := s.ensureViews(ctx); err != nil {
+		return err
+	}
+	return s.materialize(ctx, v)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func quoteTimestamp(t time.Time) string {
+	return "TIMESTAMP '" + t.Format("2006-01-02 15:04:05.000000") + "'"
+}
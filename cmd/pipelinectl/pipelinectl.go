@@ -0,0 +1,95 @@
+package pipelinectl
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/adrianolaselva/dataql/pkg/pipeline"
+	"github.com/spf13/cobra"
+)
+
+// PipelineCtl is the interface for the pipeline controller
+type PipelineCtl interface {
+	Command() *cobra.Command
+}
+
+type pipelineCtl struct{}
+
+// New creates a new PipelineCtl instance
+func New() PipelineCtl {
+	return &pipelineCtl{}
+}
+
+// Command returns the cobra command for the pipeline subcommand
+func (c *pipelineCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "pipeline",
+		Short: "Run multi-step ETL pipelines defined in YAML",
+		Long: `Run reproducible multi-step conversions defined in a YAML file: the sources
+to import, SQL steps that each create a named table from the sources and the
+tables of earlier steps, and the exports of tables or queries.
+
+  sources: [orders.csv, customers.json]
+  steps:
+    - name: paid_orders
+      sql: SELECT * FROM orders WHERE status = 'paid'
+    - name: revenue
+      sql: SELECT customer_id, SUM(amount) AS total FROM paid_orders GROUP BY customer_id
+  exports:
+    - table: revenue
+      path: out/revenue.parquet
+    - query: SELECT * FROM paid_orders LIMIT 100
+      path: out/sample.csv
+
+Relative paths are resolved against the directory of the pipeline file.`,
+		Example: `  dataql pipeline run pipeline.yaml
+  dataql pipeline validate pipeline.yaml`,
+	}
+
+	command.AddCommand(c.runCommand())
+	command.AddCommand(c.validateCommand())
+
+	return command
+}
+
+func (c *pipelineCtl) runCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run <pipeline.yaml>",
+		Short: "Run a pipeline",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			p, err := pipeline.Load(args[0])
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return p.Run(ctx, os.Stderr)
+		},
+	}
+}
+
+func (c *pipelineCtl) validateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate <pipeline.yaml>",
+		Short: "Check a pipeline file without running it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			p, err := pipeline.Load(args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s is valid: %d source(s), %d step(s), %d export(s)\n", args[0], len(p.Sources), len(p.Steps), len(p.Exports))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,163 @@
+package pluginctl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/pluginhandler"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	dirParam = "dir"
+)
+
+// PluginCtl is the interface for the plugin controller
+type PluginCtl interface {
+	Command() *cobra.Command
+}
+
+type pluginCtl struct {
+	dir string
+}
+
+// New creates a new PluginCtl instance
+func New() PluginCtl {
+	return &pluginCtl{}
+}
+
+// Command returns the cobra command for the plugin subcommand
+func (c *pluginCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "plugin",
+		Short: "Install plugins that read other file formats",
+		Long: `Manage plugins: external programs that read formats dataql does not support
+natively (HL7, SAP exports, proprietary binaries...).
+
+A plugin is a directory with a plugin.yaml manifest and the program it runs:
+
+  name: hl7
+  version: 1.0.0
+  description: HL7 v2 messages, one row per segment
+  extensions: [.hl7]
+  command: bin/dataql-hl7     # relative to the plugin directory, or a command in PATH
+  args: [--flatten]
+
+When a source ends with one of the extensions, dataql runs the command with
+the arguments followed by the source path. The command writes the rows to
+stdout as JSON Lines, one object per row, and exits with a non-zero code on
+failure; its stderr is included in the error. The rows are imported into a
+table named after the source file, like any other source.
+
+Plugins are installed in ~/.dataql/plugins (or $` + pluginhandler.EnvDir + `).`,
+		Example: `  dataql plugin install ./dataql-hl7
+  dataql plugin install https://example.com/dataql-hl7-1.0.0.tar.gz
+  dataql plugin list
+  dataql run -f admissions.hl7 -q "SELECT * FROM admissions"
+  dataql plugin remove hl7`,
+	}
+
+	command.PersistentFlags().StringVar(&c.dir, dirParam, "", "plugin directory (default: ~/.dataql/plugins)")
+
+	command.AddCommand(c.installCommand())
+	command.AddCommand(c.listCommand())
+	command.AddCommand(c.removeCommand())
+
+	return command
+}
+
+func (c *pluginCtl) installCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install <dir|archive.tar.gz|url>",
+		Short: "Install or upgrade a plugin",
+		Long: `Install a plugin from its directory, a .tar.gz archive of it, or the http(s)
+URL of such an archive. An installed plugin with the same name is replaced.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			dir, err := c.pluginDir()
+			if err != nil {
+				return err
+			}
+
+			m, err := pluginhandler.Install(args[0], dir)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Installed plugin %s %s reading %s\n", m.Name, m.Version, strings.Join(m.Extensions, ", "))
+			return nil
+		},
+	}
+}
+
+func (c *pluginCtl) listCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List installed plugins",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			dir, err := c.pluginDir()
+			if err != nil {
+				return err
+			}
+
+			plugins, err := pluginhandler.List(dir)
+			if err != nil {
+				return err
+			}
+			if len(plugins) == 0 {
+				fmt.Println("No plugins installed.")
+				return nil
+			}
+
+			tbl := table.New("Name", "Version", "Extensions", "Command", "Description").
+				WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+				WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+				WithWriter(os.Stdout)
+
+			for _, m := range plugins {
+				tbl.AddRow(m.Name, m.Version, strings.Join(m.Extensions, ", "), m.Command, m.Description)
+			}
+
+			tbl.Print()
+			return nil
+		},
+	}
+}
+
+func (c *pluginCtl) removeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Uninstall a plugin",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			dir, err := c.pluginDir()
+			if err != nil {
+				return err
+			}
+			if err := pluginhandler.Remove(dir, args[0]); err != nil {
+				return err
+			}
+
+			fmt.Printf("Removed plugin: %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+// pluginDir returns --dir or the default plugin directory
+func (c *pluginCtl) pluginDir() (string, error) {
+	if c.dir != "" {
+		return c.dir, nil
+	}
+	return pluginhandler.DefaultDir()
+}
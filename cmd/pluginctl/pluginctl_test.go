@@ -0,0 +1,97 @@
+package pluginctl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/pluginhandler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runPlugin(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	cmd := New().Command()
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	runErr := cmd.Execute()
+
+	require.NoError(t, writer.Close())
+	var out bytes.Buffer
+	_, err = io.Copy(&out, reader)
+	require.NoError(t, err)
+	return out.String(), runErr
+}
+
+// writePlugin creates a plugin reading "key=value|key=value" lines
+func writePlugin(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugin is a shell script")
+	}
+
+	dir := filepath.Join(t.TempDir(), "pipes")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, pluginhandler.ManifestFileName),
+		[]byte("name: pipes\nversion: 0.1.0\nextensions: [.pipes]\ncommand: ./read-pipes\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "read-pipes"),
+		[]byte("#!/bin/sh\nsed -e 's/^/{\"/' -e 's/=/\":\"/g' -e 's/|/\",\"/g' -e 's/$/\"}/' \"$1\"\n"), 0755))
+	return dir
+}
+
+func TestPlugin_InstallAndQuery(t *testing.T) {
+	pluginDir := t.TempDir()
+	t.Setenv(pluginhandler.EnvDir, pluginDir)
+
+	out, err := runPlugin(t, "install", writePlugin(t))
+	require.NoError(t, err)
+	assert.Contains(t, out, "Installed plugin pipes 0.1.0 reading .pipes")
+
+	out, err = runPlugin(t, "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "pipes")
+	assert.Contains(t, out, "0.1.0")
+
+	input := filepath.Join(t.TempDir(), "orders.pipes")
+	require.NoError(t, os.WriteFile(input, []byte("id=1|status=paid\nid=2|status=open\nid=3|status=paid\n"), 0644))
+
+	db, err := dataql.Open(input)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query(context.Background(), "SELECT COUNT(*) FROM orders WHERE status = 'paid'")
+	require.NoError(t, err)
+	defer rows.Close()
+	require.True(t, rows.Next())
+	var count int64
+	require.NoError(t, rows.Scan(&count))
+	assert.Equal(t, int64(2), count)
+
+	_, err = runPlugin(t, "remove", "pipes")
+	require.NoError(t, err)
+
+	out, err = runPlugin(t, "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "No plugins installed.")
+
+	_, err = runPlugin(t, "remove", "pipes")
+	assert.ErrorIs(t, err, pluginhandler.ErrNotFound)
+}
+
+func TestPlugin_InstallInvalid(t *testing.T) {
+	_, err := runPlugin(t, "install", t.TempDir(), "--dir", t.TempDir())
+	assert.ErrorContains(t, err, "plugin manifest")
+}
@@ -0,0 +1,420 @@
+package queryctl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/internal/dataql"
+	"github.com/adrianolaselva/dataql/pkg/history"
+	"github.com/adrianolaselva/dataql/pkg/savedquery"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	teamFileParam           = "team-file"
+	teamParam               = "team"
+	sqlParam                = "sql"
+	sourcesParam            = "sources"
+	sourcesShortParam       = "f"
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	descriptionParam        = "description"
+	paramParam              = "param"
+	paramShortParam         = "p"
+	forceParam              = "force"
+	exportParam             = "export"
+	exportShortParam        = "e"
+	typeParam               = "type"
+	typeShortParam          = "t"
+	noHistoryParam          = "no-history"
+
+	// maxQueryWidth truncates long queries in the listing
+	maxQueryWidth = 60
+)
+
+// QueryCtl is the interface for the saved queries controller
+type QueryCtl interface {
+	Command() *cobra.Command
+}
+
+type queryCtl struct {
+	teamFile string
+}
+
+// New creates a new QueryCtl instance
+func New() QueryCtl {
+	return &queryCtl{}
+}
+
+// Command returns the cobra command for the query subcommand
+func (c *queryCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "query",
+		Short: "Save and run named queries",
+		Long: `Keep reusable queries under a name, with the sources they read, and run them
+with parameters.
+
+Personal queries are stored in ~/.dataql/queries.yaml (or $` + savedquery.EnvPath + `).
+Queries saved with --team go to ` + savedquery.TeamFileName + `, found in the
+working directory or its parents (or created in the working directory), so
+they can be committed and shared with the data they query. A team query
+takes precedence over a personal query of the same name.
+
+Placeholders such as :region or $region in the SQL are replaced by --param
+values, falling back to the defaults saved with the query.`,
+		Example: `  dataql query save top_regions -f sales.csv \
+    --sql "SELECT region, SUM(amount) AS total FROM sales GROUP BY region ORDER BY total DESC LIMIT :limit" \
+    --param limit=5
+  dataql query run top_regions --param limit=10
+  dataql query run top_regions -e top.csv -t csv
+  dataql query save daily_errors --team -f logs/app.jsonl --sql "SELECT * FROM app WHERE level = 'ERROR'"
+  dataql query list`,
+	}
+
+	command.PersistentFlags().StringVar(&c.teamFile, teamFileParam, "", "team queries file (default: nearest "+savedquery.TeamFileName+")")
+
+	command.AddCommand(c.saveCommand())
+	command.AddCommand(c.runCommand())
+	command.AddCommand(c.listCommand())
+	command.AddCommand(c.showCommand())
+	command.AddCommand(c.removeCommand())
+
+	return command
+}
+
+func (c *queryCtl) saveCommand() *cobra.Command {
+	var query savedquery.Query
+	var params []string
+	var team, force bool
+
+	cmd := &cobra.Command{
+		Use:   "save <name>",
+		Short: "Save a named query",
+		Long: `Save a query under a name. Local sources are stored relative to the team file
+with --team, and as absolute paths in the personal file.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			name := args[0]
+
+			file, err := c.file(team)
+			if err != nil {
+				return err
+			}
+			if _, exists := file.Queries[name]; exists && !force {
+				return fmt.Errorf("query %q already exists in %s; use --%s to replace it", name, file.Path, forceParam)
+			}
+
+			defaults, err := dataql.ParseQueryParams(params)
+			if err != nil {
+				return err
+			}
+			if len(defaults) > 0 {
+				query.Params = defaults
+			}
+
+			sources := make([]string, len(query.Sources))
+			for i, source := range query.Sources {
+				if sources[i], err = storedSource(file.Dir(), source, team); err != nil {
+					return err
+				}
+			}
+			query.Sources = sources
+
+			if err := file.Set(name, query); err != nil {
+				return err
+			}
+			if err := file.Save(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Saved query %s to %s\n", name, file.Path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&query.SQL, sqlParam, "", "SQL of the query")
+	cmd.Flags().StringArrayVarP(&query.Sources, sourcesParam, sourcesShortParam, []string{}, "source the query reads (file, URL, database); repeatable")
+	cmd.Flags().StringVarP(&query.Delimiter, fileDelimiterParam, fileShortDelimiterParam, "", "csv delimiter of the sources (default: ,)")
+	cmd.Flags().StringVar(&query.Description, descriptionParam, "", "what the query answers")
+	cmd.Flags().StringArrayVarP(&params, paramParam, paramShortParam, []string{}, "default parameter value as name=value; repeatable")
+	cmd.Flags().BoolVar(&team, teamParam, false, "save to the team file instead of the personal one")
+	cmd.Flags().BoolVar(&force, forceParam, false, "replace a query of the same name")
+	_ = cmd.MarkFlagRequired(sqlParam)
+
+	return cmd
+}
+
+func (c *queryCtl) runCommand() *cobra.Command {
+	var params, sources []string
+	var export, exportType, delimiter string
+	var noHistory bool
+
+	cmd := &cobra.Command{
+		Use:   "run <name>",
+		Short: "Run a saved query",
+		Long: `Import the sources of a saved query and run it, printing the result or
+exporting it with --export. --file replaces the saved sources, e.g. to run
+the query on another day's extract.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			catalog, err := c.catalog()
+			if err != nil {
+				return err
+			}
+			saved, err := catalog.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			overrides, err := dataql.ParseQueryParams(params)
+			if err != nil {
+				return err
+			}
+
+			runParams := dataql.Params{
+				FileInputs:  saved.Sources,
+				Delimiter:   saved.Delimiter,
+				Query:       saved.SQL,
+				QueryParams: mergeParams(saved.Params, overrides),
+				Export:      export,
+				Type:        exportType,
+				Quiet:       true,
+			}
+			if len(sources) > 0 {
+				runParams.FileInputs = sources
+			}
+			if delimiter != "" {
+				runParams.Delimiter = delimiter
+			}
+			if runParams.Delimiter == "" {
+				runParams.Delimiter = ","
+			}
+			if len(runParams.FileInputs) == 0 {
+				return fmt.Errorf("query %q has no sources; pass them with --%s", saved.Name, sourcesParam)
+			}
+			if !noHistory && os.Getenv(history.EnvDisable) == "" {
+				runParams.History, _ = history.DefaultPath()
+			}
+
+			dql, err := dataql.New(runParams)
+			if err != nil {
+				return fmt.Errorf("failed to initialize dataql: %w", err)
+			}
+			defer dql.Close()
+			return dql.Run()
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&params, paramParam, paramShortParam, []string{}, "parameter value as name=value; repeatable")
+	cmd.Flags().StringArrayVarP(&sources, sourcesParam, sourcesShortParam, []string{}, "run on these sources instead of the saved ones; repeatable")
+	cmd.Flags().StringVarP(&delimiter, fileDelimiterParam, fileShortDelimiterParam, "", "csv delimiter (default: the saved one, or ,)")
+	cmd.Flags().StringVarP(&export, exportParam, exportShortParam, "", "export the result to this path instead of printing it")
+	cmd.Flags().StringVarP(&exportType, typeParam, typeShortParam, "", "export format type [`jsonl`,`csv`]")
+	cmd.Flags().BoolVar(&noHistory, noHistoryParam, false, "do not record the query in the query history")
+
+	return cmd
+}
+
+func (c *queryCtl) listCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List saved queries",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			catalog, err := c.catalog()
+			if err != nil {
+				return err
+			}
+
+			queries := catalog.List()
+			if len(queries) == 0 {
+				fmt.Println("No saved queries found.")
+				return nil
+			}
+
+			tbl := table.New("Name", "Scope", "Description", "Params", "Query").
+				WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+				WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+				WithWriter(os.Stdout)
+
+			for _, saved := range queries {
+				tbl.AddRow(saved.Name, saved.Scope, saved.Description, strings.Join(sortedKeys(saved.Params), ", "), shorten(saved.SQL))
+			}
+
+			tbl.Print()
+			return nil
+		},
+	}
+}
+
+func (c *queryCtl) showCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <name>",
+		Short: "Print a saved query",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			catalog, err := c.catalog()
+			if err != nil {
+				return err
+			}
+			saved, err := catalog.Get(args[0])
+			if err != nil {
+				return err
+			}
+
+			data, err := yaml.Marshal(map[string]savedquery.Query{saved.Name: saved.Query})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("# %s query\n%s", saved.Scope, data)
+			return nil
+		},
+	}
+}
+
+func (c *queryCtl) removeCommand() *cobra.Command {
+	var team bool
+
+	cmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a saved query",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			file, err := c.file(team)
+			if err != nil {
+				return err
+			}
+			if err := file.Remove(args[0]); err != nil {
+				return err
+			}
+			if err := file.Save(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Removed query %s from %s\n", args[0], file.Path)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&team, teamParam, false, "remove from the team file instead of the personal one")
+
+	return cmd
+}
+
+// file loads the personal file, or the team file with team set. Without a
+// team file, one is created in the working directory when saved.
+func (c *queryCtl) file(team bool) (*savedquery.File, error) {
+	if !team {
+		path, err := savedquery.DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+		return savedquery.Load(path)
+	}
+
+	path := c.teamFile
+	if path == "" {
+		path = savedquery.FindTeamFile(".")
+	}
+	if path == "" {
+		path = savedquery.TeamFileName
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve team file: %w", err)
+	}
+	return savedquery.Load(path)
+}
+
+// catalog loads the personal file and the team file, if any
+func (c *queryCtl) catalog() (savedquery.Catalog, error) {
+	var catalog savedquery.Catalog
+
+	personal, err := c.file(false)
+	if err != nil {
+		return catalog, err
+	}
+	catalog.Personal = personal
+
+	if c.teamFile != "" || savedquery.FindTeamFile(".") != "" {
+		if catalog.Team, err = c.file(true); err != nil {
+			return catalog, err
+		}
+	}
+	return catalog, nil
+}
+
+// storedSource returns a local source as saved in a queries file: relative
+// to the directory of the team file, so it works from any checkout, or
+// absolute in the personal file
+func storedSource(dir, source string, relative bool) (string, error) {
+	if source == "-" {
+		return "", fmt.Errorf("stdin cannot be a saved source")
+	}
+	if strings.Contains(source, "://") {
+		return source, nil
+	}
+
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve source %s: %w", source, err)
+	}
+	if !relative {
+		return abs, nil
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return abs, nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// mergeParams returns the defaults overridden by the given values, as name=value
+func mergeParams(defaults, overrides map[string]string) []string {
+	merged := make(map[string]string, len(defaults)+len(overrides))
+	for name, value := range defaults {
+		merged[name] = value
+	}
+	for name, value := range overrides {
+		merged[name] = value
+	}
+
+	params := make([]string, 0, len(merged))
+	for _, name := range sortedKeys(merged) {
+		params = append(params, name+"="+merged[name])
+	}
+	return params
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// shorten puts a query on a single line and truncates it
+func shorten(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxQueryWidth {
+		return query[:maxQueryWidth-3] + "..."
+	}
+	return query
+}
@@ -0,0 +1,136 @@
+package queryctl
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/history"
+	"github.com/adrianolaselva/dataql/pkg/savedquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runQuery(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	cmd := New().Command()
+	cmd.SetArgs(args)
+	cmd.SilenceErrors = true
+	runErr := cmd.Execute()
+
+	require.NoError(t, writer.Close())
+	var out bytes.Buffer
+	_, err = io.Copy(&out, reader)
+	require.NoError(t, err)
+	return out.String(), runErr
+}
+
+// setup isolates the personal file and the history and works in a new directory
+func setup(t *testing.T) string {
+	t.Helper()
+	t.Setenv(savedquery.EnvPath, filepath.Join(t.TempDir(), "queries.yaml"))
+	t.Setenv(history.EnvDisable, "1")
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.WriteFile("sales.csv", []byte("region;amount\nnorth;10\nsouth;20\neast;30\n"), 0644))
+	return dir
+}
+
+func TestQuery_SaveAndRun(t *testing.T) {
+	dir := setup(t)
+
+	_, err := runQuery(t, "save", "top", "-f", "sales.csv", "-d", ";",
+		"--sql", "SELECT region FROM sales WHERE amount >= :min ORDER BY amount", "--param", "min=20")
+	require.NoError(t, err)
+
+	_, err = runQuery(t, "save", "top", "--sql", "SELECT 1")
+	assert.ErrorContains(t, err, "already exists")
+
+	// Personal sources are absolute, so the query runs from any directory
+	t.Chdir(t.TempDir())
+
+	export := filepath.Join(t.TempDir(), "top.csv")
+	_, err = runQuery(t, "run", "top", "-e", export, "-t", "csv")
+	require.NoError(t, err)
+	data, err := os.ReadFile(export)
+	require.NoError(t, err)
+	assert.Equal(t, "region\nsouth\neast\n", string(data))
+
+	_, err = runQuery(t, "run", "top", "-p", "min=30", "-e", export, "-t", "csv")
+	require.NoError(t, err)
+	data, err = os.ReadFile(export)
+	require.NoError(t, err)
+	assert.Equal(t, "region\neast\n", string(data))
+
+	out, err := runQuery(t, "show", "top")
+	require.NoError(t, err)
+	assert.Contains(t, out, "# personal query")
+	assert.Contains(t, out, filepath.Join(dir, "sales.csv"))
+
+	out, err = runQuery(t, "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "top")
+	assert.Contains(t, out, "min")
+
+	_, err = runQuery(t, "remove", "top")
+	require.NoError(t, err)
+	_, err = runQuery(t, "run", "top")
+	assert.ErrorIs(t, err, savedquery.ErrNotFound)
+}
+
+func TestQuery_TeamFile(t *testing.T) {
+	dir := setup(t)
+
+	_, err := runQuery(t, "save", "north", "--team", "-f", "sales.csv", "-d", ";",
+		"--sql", "SELECT amount FROM sales WHERE region = 'north'")
+	require.NoError(t, err)
+
+	team := filepath.Join(dir, savedquery.TeamFileName)
+	data, err := os.ReadFile(team)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "- sales.csv", "team sources are relative to the team file")
+
+	// The team file is found from a subdirectory
+	sub := filepath.Join(dir, "reports")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+	t.Chdir(sub)
+
+	out, err := runQuery(t, "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "team")
+
+	export := filepath.Join(sub, "north.csv")
+	_, err = runQuery(t, "run", "north", "-e", export, "-t", "csv")
+	require.NoError(t, err)
+	data, err = os.ReadFile(export)
+	require.NoError(t, err)
+	assert.Equal(t, "amount\n10\n", string(data))
+}
+
+func TestQuery_Errors(t *testing.T) {
+	setup(t)
+
+	_, err := runQuery(t, "save", "bad name", "--sql", "SELECT 1")
+	assert.ErrorContains(t, err, "invalid query name")
+
+	_, err = runQuery(t, "save", "stdin", "-f", "-", "--sql", "SELECT 1")
+	assert.ErrorContains(t, err, "stdin")
+
+	_, err = runQuery(t, "save", "nosources", "--sql", "SELECT 1")
+	require.NoError(t, err)
+	_, err = runQuery(t, "run", "nosources")
+	assert.ErrorContains(t, err, "no sources")
+
+	_, err = runQuery(t, "run", "nosources", "-p", "broken")
+	assert.Error(t, err)
+}
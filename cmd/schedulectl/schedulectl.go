@@ -0,0 +1,329 @@
+package schedulectl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/metrics"
+	"github.com/adrianolaselva/dataql/pkg/scheduler"
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+)
+
+const (
+	dirParam   = "dir"
+	cronParam  = "cron"
+	jobParam   = "job"
+	nameParam  = "name"
+	limitParam = "limit"
+	onceParam  = "once"
+
+	metricsAddrParam = "metrics-addr"
+)
+
+// ScheduleCtl is the interface for the schedule controller
+type ScheduleCtl interface {
+	Command() *cobra.Command
+}
+
+type scheduleCtl struct {
+	dir string
+}
+
+// New creates a new ScheduleCtl instance
+func New() ScheduleCtl {
+	return &scheduleCtl{}
+}
+
+// Command returns the cobra command for the schedule subcommand
+func (c *scheduleCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "schedule",
+		Short: "Run query and export jobs on a cron schedule",
+		Long: `Schedule saved query and export jobs and run them with a long-running daemon.
+
+A job is a YAML file with the sources to import, the query to run and the
+file to export the result to. Relative paths are resolved against the
+directory of the job file:
+
+  sources: [sales.csv]
+  query: SELECT region, SUM(amount) AS total FROM sales GROUP BY region
+  export: reports/sales.parquet
+  notify:
+    webhook: https://hooks.example.com/dataql   # receives failed runs as JSON
+
+Schedules use five-field cron expressions (minute hour day-of-month month
+day-of-week) in local time, or @hourly, @daily, @weekly, @monthly, @yearly.
+Schedules and the run history are stored in ~/.dataql (or $` + scheduler.EnvDir + `).`,
+		Example: `  dataql schedule add --cron "0 6 * * *" --job sales.yaml
+  dataql schedule run
+  dataql schedule history --name sales`,
+	}
+
+	command.PersistentFlags().StringVar(&c.dir, dirParam, "", "directory of the schedules and run history (default: ~/.dataql)")
+
+	command.AddCommand(c.addCommand())
+	command.AddCommand(c.listCommand())
+	command.AddCommand(c.removeCommand())
+	command.AddCommand(c.runCommand())
+	command.AddCommand(c.historyCommand())
+
+	return command
+}
+
+func (c *scheduleCtl) addCommand() *cobra.Command {
+	var schedule scheduler.Schedule
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Schedule a job",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			store, err := scheduler.NewStore(c.dir)
+			if err != nil {
+				return err
+			}
+
+			added, err := store.Add(schedule)
+			if err != nil {
+				return fmt.Errorf("failed to add schedule: %w", err)
+			}
+
+			cron, _ := scheduler.ParseCron(added.Cron)
+			fmt.Printf("Scheduled %s (%s), next run at %s\n", added.Name, added.Cron, formatTime(cron.Next(time.Now())))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&schedule.Cron, cronParam, "", "cron expression (e.g. \"0 6 * * *\")")
+	cmd.Flags().StringVar(&schedule.Job, jobParam, "", "job file (YAML)")
+	cmd.Flags().StringVar(&schedule.Name, nameParam, "", "schedule name (default: job file name)")
+	_ = cmd.MarkFlagRequired(cronParam)
+	_ = cmd.MarkFlagRequired(jobParam)
+
+	return cmd
+}
+
+func (c *scheduleCtl) listCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List scheduled jobs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			store, err := scheduler.NewStore(c.dir)
+			if err != nil {
+				return err
+			}
+
+			schedules, err := store.List()
+			if err != nil {
+				return err
+			}
+			if len(schedules) == 0 {
+				fmt.Println("No schedules found.")
+				return nil
+			}
+
+			tbl := table.New("Name", "Cron", "Job", "Next Run").
+				WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+				WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+				WithWriter(os.Stdout)
+
+			now := time.Now()
+			for _, schedule := range schedules {
+				next := "invalid cron expression"
+				if cron, err := scheduler.ParseCron(schedule.Cron); err == nil {
+					next = formatTime(cron.Next(now))
+				}
+				tbl.AddRow(schedule.Name, schedule.Cron, schedule.Job, next)
+			}
+
+			tbl.Print()
+			return nil
+		},
+	}
+}
+
+func (c *scheduleCtl) removeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a scheduled job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			store, err := scheduler.NewStore(c.dir)
+			if err != nil {
+				return err
+			}
+			if err := store.Remove(args[0]); err != nil {
+				return err
+			}
+
+			fmt.Printf("Removed schedule: %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func (c *scheduleCtl) runCommand() *cobra.Command {
+	var once, metricsAddr string
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the scheduler daemon",
+		Long: `Run scheduled jobs when their cron expressions match, until interrupted.
+
+Runs are logged to stderr and recorded in the run history. A job still
+running when it is due again is skipped. With --once, the named schedule
+runs immediately and the command exits with an error if the run fails.
+With --metrics-addr, Prometheus metrics of the runs are served on
+http://<addr>/metrics.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			store, err := scheduler.NewStore(c.dir)
+			if err != nil {
+				return err
+			}
+			s := scheduler.New(store, os.Stderr)
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if once != "" {
+				return c.runOnce(ctx, store, s, once)
+			}
+
+			if metricsAddr != "" {
+				stopMetrics, err := serveMetrics(metricsAddr)
+				if err != nil {
+					return err
+				}
+				defer stopMetrics()
+				fmt.Fprintf(os.Stderr, "Serving metrics on %s%s\n", metricsAddr, metrics.Path)
+			}
+
+			schedules, err := store.List()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "DataQL scheduler started with %d schedule(s) from %s\n", len(schedules), store.Dir())
+			return s.Run(ctx)
+		},
+	}
+
+	cmd.Flags().StringVar(&once, onceParam, "", "run the named schedule now and exit")
+	cmd.Flags().StringVar(&metricsAddr, metricsAddrParam, "", "serve Prometheus metrics on this address, e.g. :9090")
+
+	return cmd
+}
+
+func (c *scheduleCtl) historyCommand() *cobra.Command {
+	var name string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the run history",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			store, err := scheduler.NewStore(c.dir)
+			if err != nil {
+				return err
+			}
+
+			runs, err := store.History(name, limit)
+			if err != nil {
+				return err
+			}
+			if len(runs) == 0 {
+				fmt.Println("No runs found.")
+				return nil
+			}
+
+			tbl := table.New("Schedule", "Started", "Duration", "Status", "Error").
+				WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+				WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+				WithWriter(os.Stdout)
+
+			for _, run := range runs {
+				errText := run.Error
+				if len(errText) > 80 {
+					errText = errText[:77] + "..."
+				}
+				duration := time.Duration(run.DurationMs * float64(time.Millisecond)).Round(time.Millisecond)
+				tbl.AddRow(run.Schedule, formatTime(run.Started.Local()), duration, run.Status, errText)
+			}
+
+			tbl.Print()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, nameParam, "", "only show runs of this schedule")
+	cmd.Flags().IntVar(&limit, limitParam, 20, "number of runs to show (0: all)")
+
+	return cmd
+}
+
+// runOnce executes the named schedule immediately
+// serveMetrics serves the Prometheus metrics on addr until the returned
+// function is called
+func serveMetrics(addr string) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metrics.Path, metrics.Default.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "metrics server stopped: %v\n", err)
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
+
+func (c *scheduleCtl) runOnce(ctx context.Context, store *scheduler.Store, s *scheduler.Scheduler, name string) error {
+	schedules, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		if schedule.Name == name {
+			if run := s.Execute(ctx, schedule); run.Status == scheduler.StatusFailed {
+				return fmt.Errorf("schedule %s failed: %s", name, run.Error)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("schedule %q not found", name)
+}
+
+// formatTime formats a run time, or explains that a cron never matches
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
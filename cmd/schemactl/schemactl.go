@@ -0,0 +1,107 @@
+package schemactl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/schemagen"
+	"github.com/spf13/cobra"
+)
+
+const (
+	fileParam               = "file"
+	fileShortParam          = "f"
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	linesParam              = "lines"
+	linesShortParam         = "l"
+	tableNameParam          = "collection"
+	tableNameShortParam     = "c"
+	typeParam               = "type"
+	typeShortParam          = "t"
+	outputParam             = "output"
+	outputShortParam        = "o"
+)
+
+// SchemaCtl is the interface for the schema controller
+type SchemaCtl interface {
+	Command() *cobra.Command
+}
+
+type schemaCtl struct {
+	fileInputs []string
+	delimiter  string
+	lines      int
+	collection string
+	format     string
+	output     string
+}
+
+// New creates a new SchemaCtl instance
+func New() SchemaCtl {
+	return &schemaCtl{}
+}
+
+// Command returns the cobra command for the schema subcommand
+func (c *schemaCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "schema",
+		Short: "Infer a schema from data and write it as JSON Schema, SQL, Avro or BigQuery",
+		Long: `Infer the schema of any supported input and write it as a formal schema, so
+table DDL and data contracts do not have to be written by hand:
+
+  jsonschema  JSON Schema (draft 2020-12) of one row
+  sql         CREATE TABLE statements with DuckDB types
+  avro        Avro record schema
+  bigquery    BigQuery JSON schema file (bq mk --schema)
+
+Columns without null values are marked as required (NOT NULL, REQUIRED, no
+null in the union). With --lines, the schema only reflects the rows read.`,
+		Example: `  dataql schema -f data.json -t jsonschema
+  dataql schema -f sales.csv -t sql -o sales.sql
+  dataql schema -f events.parquet -t bigquery -o events_schema.json`,
+		RunE: c.runE,
+	}
+
+	command.Flags().StringArrayVarP(&c.fileInputs, fileParam, fileShortParam, []string{}, "origin file (csv, json, etc.)")
+	command.Flags().StringVarP(&c.delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter")
+	command.Flags().IntVarP(&c.lines, linesParam, linesShortParam, 0, "number of lines to read when inferring (0: all)")
+	command.Flags().StringVarP(&c.collection, tableNameParam, tableNameShortParam, "", "table name (default: file name)")
+	command.Flags().StringVarP(&c.format, typeParam, typeShortParam, schemagen.FormatJSONSchema, "schema format: "+strings.Join(schemagen.Formats(), ", "))
+	command.Flags().StringVarP(&c.output, outputParam, outputShortParam, "", "write the schema to a file instead of stdout")
+	_ = command.MarkFlagRequired(fileParam)
+
+	return command
+}
+
+func (c *schemaCtl) runE(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+
+	db, err := dataql.OpenWithOptions(dataql.Options{Delimiter: c.delimiter, Lines: c.lines, Collection: c.collection}, c.fileInputs...)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tables, err := schemagen.Infer(cmd.Context(), db)
+	if err != nil {
+		return err
+	}
+
+	schema, err := schemagen.Generate(c.format, tables)
+	if err != nil {
+		return err
+	}
+
+	if c.output == "" {
+		_, err = os.Stdout.Write(schema)
+		return err
+	}
+	if err := os.WriteFile(c.output, schema, 0644); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Schema written to %s\n", c.output)
+	return nil
+}
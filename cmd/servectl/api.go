@@ -0,0 +1,436 @@
+package servectl
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/metrics"
+	"github.com/adrianolaselva/dataql/pkg/sandbox"
+	"github.com/adrianolaselva/dataql/pkg/sqlguard"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 10000
+	// maxRequestBytes bounds the JSON body of a request
+	maxRequestBytes = 1 << 20
+	// defaultSourceID names the sources given to serve with --file
+	defaultSourceID = "default"
+	// healthPath is the health check, open without the auth token
+	healthPath = "/health"
+)
+
+// sourceRequest registers a set of sources queried together
+type sourceRequest struct {
+	Sources    []string `json:"sources"`
+	Delimiter  string   `json:"delimiter,omitempty"`
+	Collection string   `json:"collection,omitempty"`
+	Lines      int      `json:"lines,omitempty"`
+}
+
+// queryRequest runs a statement against registered sources
+type queryRequest struct {
+	Query   string `json:"query"`
+	Limit   int    `json:"limit,omitempty"`
+	Offset  int    `json:"offset,omitempty"`
+	OrderBy string `json:"order_by,omitempty"` // Result column to sort by (SELECT-like statements only)
+	Desc    bool   `json:"desc,omitempty"`
+}
+
+// exportRequest downloads the result of a statement as a file
+type exportRequest struct {
+	Query  string `json:"query"`
+	Format string `json:"format,omitempty"`
+}
+
+// sourceInfo describes a registered source set
+type sourceInfo struct {
+	ID        string         `json:"id"`
+	Sources   []string       `json:"sources"`
+	CreatedAt time.Time      `json:"created_at"`
+	Tables    []dataql.Table `json:"tables,omitempty"`
+}
+
+// queryResponse is a page of a query result
+type queryResponse struct {
+	Columns    []string `json:"columns"`
+	Types      []string `json:"types"`
+	Rows       [][]any  `json:"rows"`
+	Offset     int      `json:"offset"`
+	NextOffset *int     `json:"next_offset,omitempty"` // Offset of the next page, when more rows follow
+}
+
+// registered is a source set with its imported database
+type registered struct {
+	info sourceInfo
+	db   *dataql.DB
+}
+
+// api serves the REST endpoints over the registered sources
+type api struct {
+	mu      sync.RWMutex
+	sources map[string]*registered
+
+	policy      sandbox.Policy // Sources clients may register (serve --allowed-paths/--allowed-schemes)
+	allowWrites bool           // Run statements that modify data (serve --allow-writes)
+}
+
+func newAPI() *api {
+	return &api{sources: make(map[string]*registered)}
+}
+
+// handler routes the REST endpoints
+func (a *api) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+healthPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /v1/sources", a.listSources)
+	mux.HandleFunc("POST /v1/sources", a.createSource)
+	mux.HandleFunc("GET /v1/sources/{id}", a.getSource)
+	mux.HandleFunc("DELETE /v1/sources/{id}", a.deleteSource)
+	mux.HandleFunc("POST /v1/sources/{id}/query", a.query)
+	mux.HandleFunc("POST /v1/sources/{id}/export", a.export)
+	mux.Handle("GET "+metrics.Path, metrics.Default.Handler())
+	return mux
+}
+
+// register imports the sources and adds them under id, or under a random
+// id when id is empty
+func (a *api) register(id string, req sourceRequest) (*registered, error) {
+	if len(req.Sources) == 0 {
+		return nil, fmt.Errorf("at least one source is required")
+	}
+	if id == "" {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("failed to generate source id: %w", err)
+		}
+		id = hex.EncodeToString(b)
+	}
+
+	// With a sandbox, SQL cannot open other files either
+	opts := dataql.Options{Delimiter: req.Delimiter, Collection: req.Collection, Lines: req.Lines, NoExternalAccess: a.policy.Enabled()}
+	db, err := dataql.OpenWithOptions(opts, req.Sources...)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := db.Tables()
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	src := &registered{
+		info: sourceInfo{ID: id, Sources: req.Sources, CreatedAt: time.Now().UTC(), Tables: tables},
+		db:   db,
+	}
+	a.mu.Lock()
+	a.sources[id] = src
+	a.mu.Unlock()
+	return src, nil
+}
+
+// lookup returns the registered source set of the request path, writing a
+// 404 response when there is none
+func (a *api) lookup(w http.ResponseWriter, r *http.Request) (*registered, bool) {
+	a.mu.RLock()
+	src, ok := a.sources[r.PathValue("id")]
+	a.mu.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("source %s not found", r.PathValue("id")))
+	}
+	return src, ok
+}
+
+// closeAll closes the databases of every registered source set
+func (a *api) closeAll() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for id, src := range a.sources {
+		_ = src.db.Close()
+		delete(a.sources, id)
+	}
+}
+
+func (a *api) listSources(w http.ResponseWriter, _ *http.Request) {
+	a.mu.RLock()
+	infos := make([]sourceInfo, 0, len(a.sources))
+	for _, src := range a.sources {
+		info := src.info
+		info.Tables = nil
+		infos = append(infos, info)
+	}
+	a.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].CreatedAt.Before(infos[j].CreatedAt) })
+	writeJSON(w, http.StatusOK, map[string]any{"sources": infos})
+}
+
+func (a *api) createSource(w http.ResponseWriter, r *http.Request) {
+	var req sourceRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	for _, source := range req.Sources {
+		if err := a.policy.CheckSource(source); err != nil {
+			writeError(w, http.StatusForbidden, err)
+			return
+		}
+	}
+
+	src, err := a.register("", req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, src.info)
+}
+
+func (a *api) getSource(w http.ResponseWriter, r *http.Request) {
+	src, ok := a.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	// Tables created by queries since the registration are listed too
+	info := src.info
+	tables, err := src.db.Tables()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	info.Tables = tables
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (a *api) deleteSource(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	src, ok := a.sources[r.PathValue("id")]
+	delete(a.sources, r.PathValue("id"))
+	a.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("source %s not found", r.PathValue("id")))
+		return
+	}
+
+	_ = src.db.Close()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *api) query(w http.ResponseWriter, r *http.Request) {
+	src, ok := a.lookup(w, r)
+	if !ok {
+		return
+	}
+	var req queryRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("query is required"))
+		return
+	}
+	if err := a.checkReadOnly(req.Query); err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultPageSize
+	}
+	if req.Limit > maxPageSize {
+		req.Limit = maxPageSize
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	query, paged := pagedQuery(req)
+	rows, err := src.db.Query(r.Context(), query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer rows.Close()
+
+	resp := queryResponse{Columns: rows.Columns(), Types: rows.Types(), Rows: make([][]any, 0), Offset: req.Offset}
+	if !paged {
+		// Statements that cannot be wrapped return every row: skip to the page
+		for i := 0; i < req.Offset; i++ {
+			if !rows.Next() {
+				break
+			}
+		}
+	}
+	for rows.Next() {
+		if len(resp.Rows) == req.Limit {
+			next := req.Offset + req.Limit
+			resp.NextOffset = &next
+			break
+		}
+		values, err := rows.Values()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		resp.Rows = append(resp.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// checkReadOnly rejects statements that could modify data or the
+// environment, unless the server was started with --allow-writes
+func (a *api) checkReadOnly(query string) error {
+	if a.allowWrites {
+		return nil
+	}
+	if err := sqlguard.CheckReadOnly(query); err != nil {
+		return fmt.Errorf("query rejected: %w (the server is read-only; start it with --allow-writes to permit changes)", err)
+	}
+	return nil
+}
+
+// pagedQuery wraps a single SELECT-like statement so that only the requested
+// page, sorted by the requested column, plus one row to detect whether more
+// follow, is computed. Other statements are returned unchanged and paged
+// while their rows are read.
+func pagedQuery(req queryRequest) (string, bool) {
+	trimmed := strings.TrimSuffix(strings.TrimSpace(req.Query), ";")
+	fields := strings.Fields(trimmed)
+	if len(fields) == 0 || strings.Contains(trimmed, ";") {
+		return req.Query, false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "WITH", "FROM", "VALUES", "TABLE":
+	default:
+		return req.Query, false
+	}
+
+	orderBy := ""
+	if req.OrderBy != "" {
+		orderBy = fmt.Sprintf(" ORDER BY \"%s\"", strings.ReplaceAll(req.OrderBy, `"`, `""`))
+		if req.Desc {
+			orderBy += " DESC"
+		}
+	}
+	return fmt.Sprintf("SELECT * FROM (%s) AS page%s LIMIT %d OFFSET %d", trimmed, orderBy, req.Limit+1, req.Offset), true
+}
+
+func (a *api) export(w http.ResponseWriter, r *http.Request) {
+	src, ok := a.lookup(w, r)
+	if !ok {
+		return
+	}
+	var req exportRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("query is required"))
+		return
+	}
+	if err := a.checkReadOnly(req.Query); err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+	if req.Format == "" {
+		req.Format = "csv"
+	}
+	contentType, ok := exportContentTypes[req.Format]
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("export format %q not supported", req.Format))
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "dataql-serve-*")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := "export." + exportExtensions[req.Format]
+	path := filepath.Join(dir, fileName)
+	if _, err := src.db.Export(r.Context(), req.Query, path, req.Format); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, file)
+}
+
+// exportContentTypes maps the export formats to their media types
+var exportContentTypes = map[string]string{
+	"csv":      "text/csv",
+	"jsonl":    "application/x-ndjson",
+	"json":     "application/json",
+	"excel":    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"xlsx":     "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"parquet":  "application/vnd.apache.parquet",
+	"xml":      "application/xml",
+	"yaml":     "application/yaml",
+	"yml":      "application/yaml",
+	"markdown": "text/markdown",
+	"md":       "text/markdown",
+	"html":     "text/html",
+}
+
+// exportExtensions maps the export formats to the extension of the downloaded file
+var exportExtensions = map[string]string{
+	"csv": "csv", "jsonl": "jsonl", "json": "json", "excel": "xlsx", "xlsx": "xlsx", "parquet": "parquet",
+	"xml": "xml", "yaml": "yaml", "yml": "yaml", "markdown": "md", "md": "md", "html": "html",
+}
+
+// readJSON decodes the request body into v, writing a 415 response when it
+// is not sent as JSON and a 400 response when it is not valid JSON. HTML
+// forms of other sites cannot send JSON requests.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("invalid request: Content-Type must be application/json"))
+		return false
+	}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
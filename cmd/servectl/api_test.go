@@ -0,0 +1,292 @@
+package servectl
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/httpauth"
+	"github.com/adrianolaselva/dataql/pkg/sandbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const usersFixture = "../../tests/fixtures/csv/users.csv"
+
+// do sends a request with a JSON body and returns the status and body
+func do(t *testing.T, srv *httptest.Server, method, path, body string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(respBody)
+}
+
+func newTestServer(t *testing.T) (*api, *httptest.Server) {
+	t.Helper()
+
+	a := newAPI()
+	srv := httptest.NewServer(a.handler())
+	t.Cleanup(func() {
+		srv.Close()
+		a.closeAll()
+	})
+	return a, srv
+}
+
+func TestAPI_RegisterAndQuery(t *testing.T) {
+	_, srv := newTestServer(t)
+
+	status, body := do(t, srv, http.MethodPost, "/v1/sources", `{"sources": ["`+usersFixture+`"]}`)
+	require.Equal(t, http.StatusCreated, status, body)
+
+	var info sourceInfo
+	require.NoError(t, json.Unmarshal([]byte(body), &info))
+	require.Len(t, info.Tables, 1)
+	assert.Equal(t, "users", info.Tables[0].Name)
+
+	status, body = do(t, srv, http.MethodGet, "/v1/sources", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, info.ID)
+
+	status, body = do(t, srv, http.MethodPost, "/v1/sources/"+info.ID+"/query", `{"query": "SELECT id, name FROM users ORDER BY id", "limit": 2}`)
+	require.Equal(t, http.StatusOK, status, body)
+
+	var page queryResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &page))
+	assert.Equal(t, []string{"id", "name"}, page.Columns)
+	assert.Equal(t, [][]any{{1.0, "Alice"}, {2.0, "Bob"}}, page.Rows)
+	require.NotNil(t, page.NextOffset)
+	assert.Equal(t, 2, *page.NextOffset)
+
+	status, body = do(t, srv, http.MethodPost, "/v1/sources/"+info.ID+"/query", `{"query": "SELECT id, name FROM users ORDER BY id", "limit": 2, "offset": 2}`)
+	require.Equal(t, http.StatusOK, status, body)
+	page = queryResponse{}
+	require.NoError(t, json.Unmarshal([]byte(body), &page))
+	assert.Equal(t, [][]any{{3.0, "Charlie"}}, page.Rows)
+	assert.Nil(t, page.NextOffset)
+
+	status, body = do(t, srv, http.MethodPost, "/v1/sources/"+info.ID+"/query", `{"query": "SELECT * FROM missing"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, `"error"`)
+
+	status, _ = do(t, srv, http.MethodDelete, "/v1/sources/"+info.ID, "")
+	assert.Equal(t, http.StatusNoContent, status)
+	status, _ = do(t, srv, http.MethodPost, "/v1/sources/"+info.ID+"/query", `{"query": "SELECT 1"}`)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestAPI_Metrics(t *testing.T) {
+	_, srv := newTestServer(t)
+
+	status, body := do(t, srv, http.MethodPost, "/v1/sources", `{"sources": ["`+usersFixture+`"]}`)
+	require.Equal(t, http.StatusCreated, status, body)
+
+	status, body = do(t, srv, http.MethodGet, "/metrics", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `dataql_imports_total{status="success"}`)
+	assert.Contains(t, body, "dataql_import_bytes_total")
+	assert.Contains(t, body, "# TYPE dataql_query_duration_seconds histogram")
+}
+
+func TestAPI_InvalidRequests(t *testing.T) {
+	_, srv := newTestServer(t)
+
+	status, _ := do(t, srv, http.MethodPost, "/v1/sources", `{"sources": []}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = do(t, srv, http.MethodPost, "/v1/sources", `{"files": ["a.csv"]}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = do(t, srv, http.MethodGet, "/v1/sources/unknown", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// Only JSON bodies, which HTML forms of other sites cannot send
+	resp, err := http.Post(srv.URL+"/v1/sources", "text/plain", strings.NewReader(`{"sources": ["`+usersFixture+`"]}`))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+}
+
+func TestAPI_Export(t *testing.T) {
+	a, srv := newTestServer(t)
+	_, err := a.register(defaultSourceID, sourceRequest{Sources: []string{usersFixture}})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/v1/sources/default/export", strings.NewReader(`{"query": "SELECT id, name FROM users ORDER BY id LIMIT 2"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, "text/csv", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="export.csv"`, resp.Header.Get("Content-Disposition"))
+	assert.Equal(t, "id,name\n1,Alice\n2,Bob\n", string(body))
+
+	status, _ := do(t, srv, http.MethodPost, "/v1/sources/default/export", `{"query": "SELECT 1", "format": "pdf"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestAPI_ReadOnly(t *testing.T) {
+	a, srv := newTestServer(t)
+	_, err := a.register(defaultSourceID, sourceRequest{Sources: []string{usersFixture}})
+	require.NoError(t, err)
+
+	status, body := do(t, srv, http.MethodPost, "/v1/sources/default/query", `{"query": "DROP TABLE users"}`)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, "--allow-writes")
+
+	status, _ = do(t, srv, http.MethodPost, "/v1/sources/default/export", `{"query": "COPY users TO '/tmp/users.csv'"}`)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	a.allowWrites = true
+	status, body = do(t, srv, http.MethodPost, "/v1/sources/default/query", `{"query": "CREATE TABLE copy AS SELECT * FROM users"}`)
+	assert.Equal(t, http.StatusOK, status, body)
+}
+
+func TestAPI_AllowedPaths(t *testing.T) {
+	fixtures, err := filepath.Abs(filepath.Dir(usersFixture))
+	require.NoError(t, err)
+
+	a, srv := newTestServer(t)
+	a.policy, err = sandbox.NewPolicy([]string{fixtures}, nil)
+	require.NoError(t, err)
+
+	status, body := do(t, srv, http.MethodPost, "/v1/sources", `{"sources": ["`+usersFixture+`"]}`)
+	require.Equal(t, http.StatusCreated, status, body)
+	var info sourceInfo
+	require.NoError(t, json.Unmarshal([]byte(body), &info))
+
+	// SQL cannot reach other files either
+	status, _ = do(t, srv, http.MethodPost, "/v1/sources/"+info.ID+"/query", `{"query": "SELECT * FROM read_csv('/etc/passwd')"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, body = do(t, srv, http.MethodPost, "/v1/sources", `{"sources": ["/etc/passwd"]}`)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, "outside --allowed-paths")
+
+	status, body = do(t, srv, http.MethodPost, "/v1/sources", `{"sources": ["https://example.com/data.csv"]}`)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, "--allowed-schemes")
+}
+
+func TestPagedQuery(t *testing.T) {
+	query, paged := pagedQuery(queryRequest{Query: "SELECT * FROM users;", Limit: 10, Offset: 20})
+	assert.True(t, paged)
+	assert.Equal(t, "SELECT * FROM (SELECT * FROM users) AS page LIMIT 11 OFFSET 20", query)
+
+	query, _ = pagedQuery(queryRequest{Query: "select * from users", Limit: 10, OrderBy: `na"me`, Desc: true})
+	assert.Equal(t, `SELECT * FROM (select * from users) AS page ORDER BY "na""me" DESC LIMIT 11 OFFSET 0`, query)
+
+	_, paged = pagedQuery(queryRequest{Query: "SHOW TABLES", Limit: 10})
+	assert.False(t, paged)
+
+	_, paged = pagedQuery(queryRequest{Query: "CREATE TABLE t AS SELECT 1; SELECT * FROM t", Limit: 10})
+	assert.False(t, paged)
+}
+
+func TestRequireToken(t *testing.T) {
+	handler := httpauth.RequireToken("secret", newAPI().handler(), healthPath)
+
+	tests := []struct {
+		name     string
+		path     string
+		header   string
+		value    string
+		expected int
+	}{
+		{"bearer token", "/v1/sources", "Authorization", "Bearer secret", http.StatusOK},
+		{"api key", "/v1/sources", "X-API-Key", "secret", http.StatusOK},
+		{"wrong token", "/v1/sources", "Authorization", "Bearer wrong", http.StatusUnauthorized},
+		{"missing token", "/v1/sources", "", "", http.StatusUnauthorized},
+		{"health check is open", "/health", "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.expected, rec.Code)
+		})
+	}
+}
+
+func TestUIHandler(t *testing.T) {
+	a := newAPI()
+	defer a.closeAll()
+	_, err := a.register(defaultSourceID, sourceRequest{Sources: []string{usersFixture}})
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(nil)
+	handler, err := uiHandler(a, "session-token", srv.Listener.Addr().String())
+	require.NoError(t, err)
+	srv.Config.Handler = handler
+	srv.Start()
+	defer srv.Close()
+
+	// The page carries the token of the session
+	status, body := do(t, srv, http.MethodGet, "/", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `<textarea id="editor"`)
+	assert.Contains(t, body, `<meta name="dataql-token" content="session-token">`)
+
+	status, body = do(t, srv, http.MethodGet, "/app.js", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "/v1/sources")
+
+	// The app calls the REST API on the same server with the token
+	query := `{"query": "SELECT name FROM users", "order_by": "name", "desc": true, "limit": 1}`
+	send := func(host, origin, token string) (int, string) {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/v1/sources/default/query", strings.NewReader(query))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if host != "" {
+			req.Host = host
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(respBody)
+	}
+
+	status, body = send("", srv.URL, "session-token")
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Contains(t, body, `"rows":[["Charlie"]]`)
+
+	status, _ = send("", "", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// Pages of other sites, and domains rebound to the loopback interface
+	status, _ = send("", "http://evil.example", "session-token")
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = send("evil.example:"+srv.URL[strings.LastIndex(srv.URL, ":")+1:], "", "session-token")
+	assert.Equal(t, http.StatusForbidden, status)
+}
@@ -0,0 +1,209 @@
+// DataQL web UI: talks to the REST API served by the same process
+(function () {
+  'use strict';
+
+  const pageSize = 100;
+  const $ = (id) => document.getElementById(id);
+  const state = { source: '', query: '', offset: 0, orderBy: '', desc: false };
+  // Token of this session, written into the page by the server
+  const token = document.querySelector('meta[name="dataql-token"]').content;
+
+  async function api(method, path, body) {
+    const headers = { 'Authorization': 'Bearer ' + token };
+    if (body) {
+      headers['Content-Type'] = 'application/json';
+    }
+    const resp = await fetch(path, {
+      method,
+      headers,
+      body: body ? JSON.stringify(body) : undefined,
+    });
+    if (!resp.ok) {
+      let message = resp.statusText;
+      try { message = (await resp.json()).error || message; } catch (e) { /* not JSON */ }
+      throw new Error(message);
+    }
+    return resp;
+  }
+
+  function showError(err) {
+    $('error').hidden = !err;
+    $('error').textContent = err ? err.message : '';
+  }
+
+  function insertAtCursor(text) {
+    const editor = $('editor');
+    const start = editor.selectionStart;
+    editor.value = editor.value.slice(0, start) + text + editor.value.slice(editor.selectionEnd);
+    editor.selectionStart = editor.selectionEnd = start + text.length;
+    editor.focus();
+  }
+
+  function quoteIdentifier(name) {
+    return /^[a-z_][a-z0-9_]*$/.test(name) ? name : '"' + name.replace(/"/g, '""') + '"';
+  }
+
+  async function loadSources() {
+    const { sources } = await (await api('GET', '/v1/sources')).json();
+    const select = $('source');
+    select.innerHTML = '';
+    for (const src of sources) {
+      const option = document.createElement('option');
+      option.value = src.id;
+      option.textContent = src.id + ' (' + src.sources.join(', ') + ')';
+      select.appendChild(option);
+    }
+    if (sources.length > 0) {
+      state.source = sources[0].id;
+      await loadSchema();
+    } else {
+      showError(new Error('No sources registered: start with dataql ui -f <file>'));
+    }
+  }
+
+  async function loadSchema() {
+    const info = await (await api('GET', '/v1/sources/' + encodeURIComponent(state.source))).json();
+    const list = $('schema');
+    list.innerHTML = '';
+    for (const table of info.tables || []) {
+      const item = document.createElement('li');
+      const name = document.createElement('div');
+      name.className = 'table';
+      name.textContent = table.name;
+      name.title = 'Click to preview, double-click to insert';
+      name.onclick = () => {
+        $('editor').value = 'SELECT * FROM ' + quoteIdentifier(table.name);
+        run();
+      };
+      name.ondblclick = () => insertAtCursor(quoteIdentifier(table.name));
+      const columns = document.createElement('div');
+      columns.className = 'columns';
+      for (const col of table.columns) {
+        const column = document.createElement('div');
+        column.textContent = col.name;
+        column.title = 'Click to insert';
+        column.onclick = () => insertAtCursor(quoteIdentifier(col.name));
+        const type = document.createElement('span');
+        type.className = 'type';
+        type.textContent = col.type;
+        column.appendChild(type);
+        columns.appendChild(column);
+      }
+      item.append(name, columns);
+      list.appendChild(item);
+    }
+    if (!$('editor').value && info.tables && info.tables.length > 0) {
+      $('editor').value = 'SELECT * FROM ' + quoteIdentifier(info.tables[0].name);
+    }
+  }
+
+  async function run() {
+    state.query = $('editor').value;
+    state.offset = 0;
+    state.orderBy = '';
+    state.desc = false;
+    await fetchPage();
+  }
+
+  async function fetchPage() {
+    if (!state.query.trim()) {
+      return;
+    }
+    showError(null);
+    $('status').textContent = 'Running...';
+    const started = performance.now();
+    try {
+      const page = await (await api('POST', '/v1/sources/' + encodeURIComponent(state.source) + '/query', {
+        query: state.query, limit: pageSize, offset: state.offset, order_by: state.orderBy, desc: state.desc,
+      })).json();
+      renderPage(page);
+      $('status').textContent = Math.round(performance.now() - started) + ' ms';
+    } catch (err) {
+      $('status').textContent = '';
+      showError(err);
+    }
+  }
+
+  function renderPage(page) {
+    const table = $('result');
+    table.innerHTML = '';
+    const header = table.createTHead().insertRow();
+    page.columns.forEach((name, i) => {
+      const th = document.createElement('th');
+      th.textContent = name + (state.orderBy === name ? (state.desc ? ' ▼' : ' ▲') : '');
+      th.title = 'Sort by ' + name;
+      const type = document.createElement('span');
+      type.className = 'type';
+      type.textContent = page.types[i];
+      th.appendChild(type);
+      th.onclick = () => {
+        state.desc = state.orderBy === name && !state.desc;
+        state.orderBy = name;
+        state.offset = 0;
+        fetchPage();
+      };
+      header.appendChild(th);
+    });
+
+    const body = table.createTBody();
+    for (const row of page.rows) {
+      const tr = body.insertRow();
+      for (const value of row) {
+        const td = tr.insertCell();
+        if (value === null) {
+          td.textContent = 'NULL';
+          td.className = 'null';
+        } else if (typeof value === 'object') {
+          td.textContent = JSON.stringify(value);
+        } else {
+          td.textContent = String(value);
+          if (typeof value === 'number') {
+            td.className = 'number';
+          }
+        }
+      }
+    }
+
+    const last = page.offset + page.rows.length;
+    $('page').textContent = page.rows.length ? 'Rows ' + (page.offset + 1) + '-' + last : 'No rows';
+    $('prev').disabled = page.offset === 0;
+    $('next').disabled = page.next_offset === undefined;
+  }
+
+  async function exportResult() {
+    const query = $('editor').value;
+    if (!query.trim()) {
+      return;
+    }
+    showError(null);
+    const format = $('format').value;
+    $('status').textContent = 'Exporting...';
+    try {
+      const resp = await api('POST', '/v1/sources/' + encodeURIComponent(state.source) + '/export', { query, format });
+      const match = /filename="([^"]+)"/.exec(resp.headers.get('Content-Disposition') || '');
+      const link = document.createElement('a');
+      link.href = URL.createObjectURL(await resp.blob());
+      link.download = match ? match[1] : 'export';
+      link.click();
+      URL.revokeObjectURL(link.href);
+      $('status').textContent = '';
+    } catch (err) {
+      $('status').textContent = '';
+      showError(err);
+    }
+  }
+
+  $('run').onclick = run;
+  $('export').onclick = exportResult;
+  $('prev').onclick = () => { state.offset = Math.max(0, state.offset - pageSize); fetchPage(); };
+  $('next').onclick = () => { state.offset += pageSize; fetchPage(); };
+  $('source').onchange = (e) => { state.source = e.target.value; loadSchema().catch(showError); };
+  $('editor').addEventListener('keydown', (e) => {
+    if (e.key === 'Enter' && (e.ctrlKey || e.metaKey)) {
+      e.preventDefault();
+      run();
+    }
+  });
+
+  loadSources().catch(showError);
+})();
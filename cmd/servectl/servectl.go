@@ -0,0 +1,214 @@
+package servectl
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/httpauth"
+	"github.com/adrianolaselva/dataql/pkg/sandbox"
+	"github.com/spf13/cobra"
+)
+
+const (
+	fileParam               = "file"
+	fileShortParam          = "f"
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	addrParam               = "addr"
+	authTokenParam          = "auth-token"
+	tlsCertParam            = "tls-cert"
+	tlsKeyParam             = "tls-key"
+	allowWritesParam        = "allow-writes"
+	allowedPathsParam       = "allowed-paths"
+	allowedSchemesParam     = "allowed-schemes"
+)
+
+// envAuthToken provides the auth token without exposing it in the process list
+const envAuthToken = "DATAQL_SERVE_TOKEN"
+
+// shutdownTimeout bounds how long in-flight requests may run after a stop signal
+const shutdownTimeout = 10 * time.Second
+
+// ServeCtl is the interface for the serve controller
+type ServeCtl interface {
+	Command() *cobra.Command
+}
+
+type serveCtl struct {
+	fileInputs []string
+	delimiter  string
+	addr       string
+	authToken  string
+	tlsCert    string
+	tlsKey     string
+
+	allowWrites    bool
+	allowedPaths   []string
+	allowedSchemes []string
+}
+
+// New creates a new ServeCtl instance
+func New() ServeCtl {
+	return &serveCtl{}
+}
+
+// Command returns the cobra command for the serve subcommand
+func (c *serveCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a REST API to query data sources over HTTP",
+		Long: `Start an HTTP server exposing a REST API to register data sources, run SQL
+queries with paginated JSON results and download exports.
+
+Endpoints:
+  GET    /health                   Health check (no authentication)
+  GET    /v1/sources               List registered source sets
+  POST   /v1/sources               Register sources: {"sources": ["sales.csv"], "delimiter": ","}
+  GET    /v1/sources/{id}          Tables and column types of a source set
+  DELETE /v1/sources/{id}          Close a source set
+  POST   /v1/sources/{id}/query    Run a query: {"query": "SELECT ...", "limit": 100, "offset": 0}
+  POST   /v1/sources/{id}/export   Download a query result: {"query": "SELECT ...", "format": "parquet"}
+  GET    /metrics                  Prometheus metrics: queries, imports, cache and exports
+
+Sources given with --file are registered as the "default" source set.
+Queries and exports may only read data unless --allow-writes is set, and
+--allowed-paths/--allowed-schemes restrict the sources clients register.
+Clients authenticate with 'Authorization: Bearer <token>' or 'X-API-Key'
+when --auth-token (or $` + envAuthToken + `) is set.`,
+		Example: `  dataql serve -f sales.csv --addr :8080
+  DATAQL_SERVE_TOKEN=secret dataql serve --addr :8443 --tls-cert cert.pem --tls-key key.pem
+  curl -H 'Authorization: Bearer secret' -H 'Content-Type: application/json' -d '{"query": "SELECT * FROM sales"}' https://localhost:8443/v1/sources/default/query`,
+		RunE: c.runE,
+	}
+
+	command.Flags().StringArrayVarP(&c.fileInputs, fileParam, fileShortParam, []string{}, "sources to register as the \"default\" source set")
+	command.Flags().StringVarP(&c.delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter of the --file sources")
+	command.Flags().StringVar(&c.addr, addrParam, "127.0.0.1:8080", "address to listen on")
+	command.Flags().StringVar(&c.authToken, authTokenParam, "", "token clients must send as 'Authorization: Bearer <token>' or 'X-API-Key' (default: $"+envAuthToken+")")
+	command.Flags().StringVar(&c.tlsCert, tlsCertParam, "", "TLS certificate file")
+	command.Flags().StringVar(&c.tlsKey, tlsKeyParam, "", "TLS private key file")
+	command.Flags().BoolVar(&c.allowWrites, allowWritesParam, false, "allow queries and exports that modify data or the environment (default: read-only)")
+	command.Flags().StringSliceVar(&c.allowedPaths, allowedPathsParam, nil, "directories clients may register files from (default: any)")
+	command.Flags().StringSliceVar(&c.allowedSchemes, allowedSchemesParam, nil, "source schemes clients may register, e.g. file,s3,postgres (default: any, or only file when --allowed-paths is set)")
+
+	return command
+}
+
+func (c *serveCtl) runE(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return err
+	}
+	if c.authToken == "" {
+		c.authToken = os.Getenv(envAuthToken)
+	}
+
+	policy, err := sandbox.NewPolicy(c.allowedPaths, c.allowedSchemes)
+	if err != nil {
+		return err
+	}
+
+	a := newAPI()
+	a.policy, a.allowWrites = policy, c.allowWrites
+	defer a.closeAll()
+
+	if len(c.fileInputs) > 0 {
+		if _, err := a.register(defaultSourceID, sourceRequest{Sources: c.fileInputs, Delimiter: c.delimiter}); err != nil {
+			return fmt.Errorf("failed to register sources: %w", err)
+		}
+	}
+
+	handler := a.handler()
+	if c.authToken != "" {
+		handler = httpauth.RequireToken(c.authToken, handler, healthPath)
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: serving without authentication; set --auth-token or %s\n", envAuthToken)
+	}
+
+	return listenAndServe(cmd.Context(), handler, c.addr, tlsConfig, "DataQL REST API", nil)
+}
+
+// listenAndServe serves handler on addr until the process is interrupted.
+// onListen, when set, is called with the URL of the server once it accepts
+// connections.
+func listenAndServe(ctx context.Context, handler http.Handler, addr string, tlsConfig *tls.Config, name string, onListen func(url string)) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return serve(ctx, listener, handler, tlsConfig, name, onListen)
+}
+
+// serve serves handler on listener until the process is interrupted
+func serve(ctx context.Context, listener net.Listener, handler http.Handler, tlsConfig *tls.Config, name string, onListen func(url string)) error {
+	srv := &http.Server{
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	go func() {
+		if tlsConfig != nil {
+			// The certificate is already loaded in srv.TLSConfig
+			errCh <- srv.ServeTLS(listener, "", "")
+			return
+		}
+		errCh <- srv.Serve(listener)
+	}()
+
+	url := fmt.Sprintf("%s://%s", scheme, listener.Addr())
+	fmt.Fprintf(os.Stderr, "%s listening on %s\n", name, url)
+	if onListen != nil {
+		onListen(url)
+	}
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to serve: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop server: %w", err)
+	}
+	return nil
+}
+
+// tlsConfig loads the TLS certificate, or returns nil when TLS is not configured
+func (c *serveCtl) tlsConfig() (*tls.Config, error) {
+	if c.tlsCert == "" && c.tlsKey == "" {
+		return nil, nil
+	}
+	if c.tlsCert == "" || c.tlsKey == "" {
+		return nil, fmt.Errorf("both --tls-cert and --tls-key must be provided")
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.tlsCert, c.tlsKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
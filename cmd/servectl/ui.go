@@ -0,0 +1,177 @@
+package servectl
+
+import (
+	"bytes"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/httpauth"
+	"github.com/spf13/cobra"
+)
+
+//go:embed embedded/ui/*
+var uiFS embed.FS
+
+const noBrowserParam = "no-browser"
+
+type uiCtl struct {
+	fileInputs []string
+	delimiter  string
+	addr       string
+	noBrowser  bool
+}
+
+// NewUI creates the controller of the ui subcommand, which serves the REST
+// API together with a web app built on it
+func NewUI() ServeCtl {
+	return &uiCtl{}
+}
+
+// Command returns the cobra command for the ui subcommand
+func (c *uiCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "ui",
+		Short: "Explore data sources in a local web app",
+		Long: `Start a local web app to query and explore data sources: a SQL editor
+(Ctrl+Enter runs the query), a schema browser (click a table to preview it,
+a column to insert its name), a result grid sorted by clicking a column header
+and paged 100 rows at a time, and one-click export to CSV, JSON, Excel,
+Parquet and more.
+
+The app is served on the loopback interface and opened in the default browser.
+Its API requires a token generated for the session, which only the page of
+the app carries.`,
+		Example: `  dataql ui -f data.csv
+  dataql ui -f sales.csv -f customers.json --addr 127.0.0.1:9000 --no-browser`,
+		RunE: c.runE,
+	}
+
+	command.Flags().StringArrayVarP(&c.fileInputs, fileParam, fileShortParam, []string{}, "origin file (csv, json, etc.)")
+	command.Flags().StringVarP(&c.delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter")
+	command.Flags().StringVar(&c.addr, addrParam, "127.0.0.1:8090", "address to listen on")
+	command.Flags().BoolVar(&c.noBrowser, noBrowserParam, false, "do not open the web app in the browser")
+
+	return command
+}
+
+func (c *uiCtl) runE(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+
+	if len(c.fileInputs) == 0 {
+		return fmt.Errorf("at least one --file is required")
+	}
+
+	// The app runs the SQL of the local user, who may create tables
+	a := newAPI()
+	a.allowWrites = true
+	defer a.closeAll()
+
+	if _, err := a.register(defaultSourceID, sourceRequest{Sources: c.fileInputs, Delimiter: c.delimiter}); err != nil {
+		return fmt.Errorf("failed to register sources: %w", err)
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("failed to generate session token: %w", err)
+	}
+	listener, err := net.Listen("tcp", c.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", c.addr, err)
+	}
+	handler, err := uiHandler(a, hex.EncodeToString(b), listener.Addr().String())
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+
+	onListen := func(url string) {
+		if c.noBrowser {
+			return
+		}
+		if err := openBrowser(url); err != nil {
+			fmt.Fprintf(os.Stderr, "Open %s in your browser (%v)\n", url, err)
+		}
+	}
+	return serve(cmd.Context(), listener, handler, nil, "DataQL UI", onListen)
+}
+
+// uiHandler serves the web app at the root and the REST API it calls. The
+// API requires token, which the page of the app carries, and every request
+// must be addressed to addr, where the app is served: other sites cannot
+// call the API from the browser (CSRF), even through a domain resolving to
+// the loopback interface (DNS rebinding).
+func uiHandler(a *api, token, addr string) (http.Handler, error) {
+	static, err := fs.Sub(uiFS, "embedded/ui")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load web app: %w", err)
+	}
+	page, err := template.ParseFS(static, "index.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load web app: %w", err)
+	}
+	var index bytes.Buffer
+	if err := page.Execute(&index, struct{ Token string }{token}); err != nil {
+		return nil, fmt.Errorf("failed to load web app: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	apiHandler := a.handler()
+	mux.Handle("/v1/", httpauth.RequireToken(token, apiHandler))
+	mux.Handle(healthPath, apiHandler)
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write(index.Bytes())
+	})
+	mux.Handle("/", http.FileServer(http.FS(static)))
+	return sameOrigin(addr, mux), nil
+}
+
+// sameOrigin rejects requests whose Host is not addr, or the loopback
+// names of its port, and requests from pages of another origin
+func sameOrigin(addr string, next http.Handler) http.Handler {
+	hosts := map[string]bool{addr: true}
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		if ip := net.ParseIP(host); ip != nil && (ip.IsLoopback() || ip.IsUnspecified()) {
+			for _, name := range []string{"localhost", "127.0.0.1", "::1"} {
+				hosts[net.JoinHostPort(name, port)] = true
+			}
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hosts[strings.ToLower(r.Host)] {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %s is not allowed", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" && origin != "http://"+r.Host {
+			writeError(w, http.StatusForbidden, fmt.Errorf("origin %s is not allowed", origin))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// openBrowser opens url in the default browser of the platform
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
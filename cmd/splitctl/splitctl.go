@@ -0,0 +1,377 @@
+package splitctl
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/spf13/cobra"
+)
+
+const (
+	rowsParam               = "rows"
+	sizeParam               = "size"
+	byColumnParam           = "by-column"
+	outParam                = "out"
+	outShortParam           = "o"
+	typeParam               = "type"
+	typeShortParam          = "t"
+	fileDelimiterParam      = "delimiter"
+	fileShortDelimiterParam = "d"
+	tableParam              = "table"
+	maxFilesParam           = "max-files"
+)
+
+// sizeSampleRows is the number of rows exported to estimate the output size
+// of a row when splitting by size
+const sizeSampleRows = 10000
+
+var (
+	numberVerb = regexp.MustCompile(`%0?\d*d`)
+	// unsafeFileChars matches the characters replaced in column values used
+	// as part of a file name
+	unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// SplitCtl is the interface for the split controller
+type SplitCtl interface {
+	Command() *cobra.Command
+}
+
+type splitCtl struct {
+	rows       int64
+	size       string
+	byColumn   string
+	out        string
+	outputType string
+	delimiter  string
+	table      string
+	maxFiles   int
+}
+
+// New creates a new SplitCtl instance
+func New() SplitCtl {
+	return &splitCtl{}
+}
+
+// Command returns the cobra command for the split subcommand
+func (c *splitCtl) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "split <input>",
+		Short: "Split a file into chunks by row count, size or column value",
+		Long: `Split a data source into several files, the inverse of merge:
+
+  --rows N          chunks of N rows
+  --size 100MB      chunks of about the given size (estimated from a sample)
+  --by-column col   one file per distinct value of a column
+
+--out is the file name pattern: %d (e.g. %04d) is replaced by the chunk
+number starting at 1, or %s by the column value with --by-column. The format
+is taken from the extension (or --type); a .gz suffix compresses each file.
+Rows keep the order of the input.`,
+		Example: `  dataql split big.csv --rows 1000000 --out 'chunk-%04d.csv.gz'
+  dataql split events.jsonl --size 256MB --out 'parts/events-%03d.parquet'
+  dataql split sales.csv --by-column region --out 'sales/region=%s.csv'`,
+		Args: cobra.ExactArgs(1),
+		RunE: c.runE,
+	}
+
+	command.Flags().Int64Var(&c.rows, rowsParam, 0, "rows per chunk")
+	command.Flags().StringVar(&c.size, sizeParam, "", "approximate size per chunk (e.g. 500KB, 100MB, 1GB)")
+	command.Flags().StringVar(&c.byColumn, byColumnParam, "", "write one file per distinct value of this column")
+	command.Flags().StringVarP(&c.out, outParam, outShortParam, "", "output file pattern (default: <input>-%04d.<ext> or <input>-%s.<ext>)")
+	command.Flags().StringVarP(&c.outputType, typeParam, typeShortParam, "", "output format (default: from the output extension)")
+	command.Flags().StringVarP(&c.delimiter, fileDelimiterParam, fileShortDelimiterParam, ",", "csv delimiter of the input")
+	command.Flags().StringVar(&c.table, tableParam, "", "table to split when the input holds several")
+	command.Flags().IntVar(&c.maxFiles, maxFilesParam, 1000, "fail instead of writing more files than this with --by-column")
+
+	return command
+}
+
+func (c *splitCtl) runE(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	input := args[0]
+
+	modes := 0
+	for _, set := range []bool{c.rows > 0, c.size != "", c.byColumn != ""} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		return fmt.Errorf("exactly one of --%s, --%s or --%s is required", rowsParam, sizeParam, byColumnParam)
+	}
+
+	pattern := c.out
+	if pattern == "" {
+		pattern = defaultPattern(input, c.byColumn != "")
+	}
+	if c.byColumn != "" && strings.Count(pattern, "%s") != 1 {
+		return fmt.Errorf("--%s must contain %%s for the column value with --%s", outParam, byColumnParam)
+	}
+	if c.byColumn == "" && len(numberVerb.FindAllString(pattern, -1)) != 1 {
+		return fmt.Errorf("--%s must contain a number verb such as %%04d for the chunk number", outParam)
+	}
+
+	db, err := dataql.OpenWithOptions(dataql.Options{Delimiter: c.delimiter}, input)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	source, err := db.SourceTable(c.table)
+	if err != nil {
+		return err
+	}
+
+	w := &chunkWriter{db: db, table: storage.QuoteIdentifier(source.Name), format: c.outputType}
+	var files int
+	switch {
+	case c.byColumn != "":
+		files, err = w.splitByColumn(cmd.Context(), c.byColumn, pattern, c.maxFiles)
+	case c.size != "":
+		var size int64
+		if size, err = parseSize(c.size); err == nil {
+			files, err = w.splitBySize(cmd.Context(), size, pattern)
+		}
+	default:
+		files, err = w.splitByRows(cmd.Context(), c.rows, pattern)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Split %s into %d file(s)\n", input, files)
+	return nil
+}
+
+// chunkWriter exports parts of a table to files
+type chunkWriter struct {
+	db     *dataql.DB
+	table  string
+	format string
+}
+
+// splitByRows writes chunks of rows rows, in insertion order
+func (w *chunkWriter) splitByRows(ctx context.Context, rows int64, pattern string) (int, error) {
+	total, err := w.count(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	files := 0
+	for start := int64(0); start < total || files == 0; start += rows {
+		files++
+		query := fmt.Sprintf("SELECT * FROM %s WHERE rowid >= %d AND rowid < %d ORDER BY rowid", w.table, start, start+rows)
+		if err := w.write(ctx, query, fmt.Sprintf(pattern, files)); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+// splitBySize estimates the bytes per row by exporting a sample in the
+// output format, then splits by the matching row count
+func (w *chunkWriter) splitBySize(ctx context.Context, size int64, pattern string) (int, error) {
+	sample, err := os.CreateTemp("", "dataql-split-sample-*"+filepath.Ext(pattern))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create sample file: %w", err)
+	}
+	_ = sample.Close()
+	defer os.Remove(sample.Name())
+
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY rowid LIMIT %d", w.table, sizeSampleRows)
+	if err := w.write(ctx, query, sample.Name()); err != nil {
+		return 0, err
+	}
+
+	info, err := os.Stat(sample.Name())
+	if err != nil {
+		return 0, err
+	}
+	sampleRows, err := w.count(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sampleRows = min(sampleRows, sizeSampleRows)
+
+	rows := int64(1)
+	if sampleRows > 0 && info.Size() > 0 {
+		rows = max(1, size*sampleRows/info.Size())
+	}
+	return w.splitByRows(ctx, rows, pattern)
+}
+
+// splitByColumn writes one file per distinct value of column
+func (w *chunkWriter) splitByColumn(ctx context.Context, column, pattern string, maxFiles int) (int, error) {
+	quoted := storage.QuoteIdentifier(column)
+	rows, err := w.db.Query(ctx, fmt.Sprintf("SELECT DISTINCT CAST(%s AS VARCHAR) AS v FROM %s ORDER BY v NULLS LAST", quoted, w.table))
+	if err != nil {
+		return 0, err
+	}
+
+	var values []*string
+	for rows.Next() {
+		var value *string
+		if err := rows.Scan(&value); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		values = append(values, value)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+	if len(values) > maxFiles {
+		return 0, fmt.Errorf("%s has %d distinct values, more than --%s %d", column, len(values), maxFilesParam, maxFiles)
+	}
+
+	paths := make(map[string]string, len(values))
+	for _, value := range values {
+		name, condition := "null", quoted+" IS NULL"
+		if value != nil {
+			name = fileSafe(*value)
+			condition = fmt.Sprintf("CAST(%s AS VARCHAR) = '%s'", quoted, strings.ReplaceAll(*value, "'", "''"))
+		}
+
+		path := fmt.Sprintf(pattern, name)
+		if previous, ok := paths[path]; ok {
+			return len(paths), fmt.Errorf("values %q and %q of %s map to the same file %s", previous, name, column, path)
+		}
+		paths[path] = name
+
+		query := fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY rowid", w.table, condition)
+		if err := w.write(ctx, query, path); err != nil {
+			return len(paths), err
+		}
+	}
+	return len(paths), nil
+}
+
+// write exports query to path, compressing it when path ends with .gz
+func (w *chunkWriter) write(ctx context.Context, query, path string) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	if !strings.HasSuffix(strings.ToLower(path), ".gz") {
+		_, err := w.db.Export(ctx, query, path, w.format)
+		return err
+	}
+
+	plain := path[:len(path)-len(".gz")]
+	format := w.format
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(plain)), ".")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".dataql-split-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	_ = tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if _, err := w.db.Export(ctx, query, tmp.Name(), format); err != nil {
+		return err
+	}
+	return gzipFile(tmp.Name(), path)
+}
+
+func (w *chunkWriter) count(ctx context.Context) (int64, error) {
+	rows, err := w.db.Query(ctx, "SELECT COUNT(*) FROM "+w.table)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var total int64
+	if rows.Next() {
+		if err := rows.Scan(&total); err != nil {
+			return 0, err
+		}
+	}
+	return total, rows.Err()
+}
+
+// gzipFile compresses src into dst
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to compress %s: %w", dst, err)
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to compress %s: %w", dst, err)
+	}
+	return out.Close()
+}
+
+// defaultPattern names the chunks after the input file
+func defaultPattern(input string, byValue bool) string {
+	base := filepath.Base(input)
+	ext := filepath.Ext(base)
+	if strings.EqualFold(ext, ".gz") {
+		ext = filepath.Ext(strings.TrimSuffix(base, ext)) + ext
+	}
+	stem := strings.TrimSuffix(base, ext)
+	if byValue {
+		return stem + "-%s" + ext
+	}
+	return stem + "-%04d" + ext
+}
+
+// parseSize parses sizes such as 500KB, 100MB, 1.5GB or a number of bytes
+func parseSize(text string) (int64, error) {
+	units := []struct {
+		suffix string
+		factor float64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1}}
+
+	value := strings.ToUpper(strings.TrimSpace(text))
+	factor := 1.0
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			factor = unit.factor
+			break
+		}
+	}
+
+	var n float64
+	if _, err := fmt.Sscanf(value, "%g", &n); err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q (e.g. 500KB, 100MB, 1GB)", text)
+	}
+	return int64(n * factor), nil
+}
+
+// fileSafe turns a column value into a file name part
+func fileSafe(value string) string {
+	value = strings.Trim(unsafeFileChars.ReplaceAllString(value, "_"), "_")
+	if value == "" || value == "." || value == ".." {
+		return "empty"
+	}
+	return value
+}
@@ -0,0 +1,24 @@
+package splitctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{"100": 100, "20B": 20, "500KB": 500 << 10, "1.5mb": 3 << 19, "2G": 2 << 30}
+	for text, expected := range tests {
+		size, err := parseSize(text)
+		require.NoError(t, err, text)
+		assert.Equal(t, expected, size, text)
+	}
+}
+
+func TestDefaultPattern(t *testing.T) {
+	assert.Equal(t, "big-%04d.csv", defaultPattern("data/big.csv", false))
+	assert.Equal(t, "big-%s.csv.gz", defaultPattern("big.csv.gz", true))
+	assert.Equal(t, "a_b", fileSafe("a/b"))
+	assert.Equal(t, "empty", fileSafe(".."))
+}
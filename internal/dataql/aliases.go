@@ -0,0 +1,99 @@
+package dataql
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/config"
+)
+
+var (
+	// placeholderPattern matches positional alias arguments such as :1 or $2
+	placeholderPattern = regexp.MustCompile(`[:$](\d+)\b`)
+	// stringLiteralPattern matches single-quoted SQL string literals
+	stringLiteralPattern = regexp.MustCompile(`'(?:[^']|'')*'`)
+)
+
+// loadConfig reads the user configuration file and applies it to the session.
+// A broken config file is reported but does not prevent dataql from running.
+func (d *dataQL) loadConfig() {
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+
+	d.aliases = cfg.Aliases()
+	verboseLog(d.params.Verbose, "Loaded %d command aliases", len(d.aliases))
+}
+
+// lookupAlias returns the query of a user-defined alias invoked as .name
+func (d *dataQL) lookupAlias(command string) (string, bool) {
+	if !strings.HasPrefix(command, ".") {
+		return "", false
+	}
+	query, ok := d.aliases[strings.TrimPrefix(command, ".")]
+	return query, ok
+}
+
+// runAlias expands an alias with its arguments and runs the resulting query
+func (d *dataQL) runAlias(name, query string, args []string) error {
+	expanded, err := expandAlias(query, args)
+	if err != nil {
+		return fmt.Errorf("alias %s: %w", name, err)
+	}
+
+	expanded, run := d.guardRows(expanded)
+	if !run {
+		return nil
+	}
+	if err := d.runQuery(expanded); err != nil {
+		return err
+	}
+
+	d.lastQuery = expanded
+	return nil
+}
+
+// printAliases lists the user-defined aliases
+func (d *dataQL) printAliases() {
+	if len(d.aliases) == 0 {
+		fmt.Fprintf(d.out, "No aliases defined (add an [aliases] section to %s)\n", config.DefaultPath())
+		return
+	}
+
+	names := make([]string, 0, len(d.aliases))
+	for name := range d.aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(d.out, "  .%-18s %s\n", name, d.aliases[name])
+	}
+}
+
+// expandAlias substitutes positional arguments (:1, :2, ... or $1, $2, ...) in an alias query
+func expandAlias(query string, args []string) (string, error) {
+	// Ignore placeholder-like text inside string literals (e.g. '12:30')
+	required := 0
+	for _, match := range placeholderPattern.FindAllStringSubmatch(stringLiteralPattern.ReplaceAllString(query, "''"), -1) {
+		if n, err := strconv.Atoi(match[1]); err == nil && n > required {
+			required = n
+		}
+	}
+	if len(args) < required {
+		return "", fmt.Errorf("expected %d argument(s), got %d", required, len(args))
+	}
+
+	params := make(map[string]string, len(args))
+	for i, arg := range args {
+		params[strconv.Itoa(i+1)] = arg
+	}
+
+	return ApplyQueryParams(query, params), nil
+}
@@ -0,0 +1,30 @@
+package dataql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandAlias(t *testing.T) {
+	query, err := expandAlias("SELECT * FROM logs WHERE level = 'ERROR' LIMIT :1", []string{"50"})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM logs WHERE level = 'ERROR' LIMIT 50", query)
+
+	query, err = expandAlias("SELECT * FROM users WHERE name = $1 AND age > $2", []string{"Ann", "30"})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE name = 'Ann' AND age > 30", query)
+
+	query, err = expandAlias("SELECT * FROM users", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users", query)
+}
+
+func TestExpandAlias_MissingArguments(t *testing.T) {
+	_, err := expandAlias("SELECT * FROM logs LIMIT :2 OFFSET :1", []string{"10"})
+	assert.Error(t, err)
+
+	// Placeholder-like text inside string literals is not an argument
+	_, err = expandAlias("SELECT * FROM logs WHERE time > '12:30'", nil)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,317 @@
+package dataql
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/cachehandler"
+	"github.com/adrianolaselva/dataql/pkg/encryption"
+	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/schollz/progressbar/v3"
+)
+
+// tableNamePattern matches the characters file handlers drop from table names
+var tableNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_ ]+`)
+
+// incrementalImport appends the data added to growing files since they were
+// cached to the tables of the cache entry
+type incrementalImport struct {
+	dir     string            // Temporary directory of the delta files
+	deltas  []string          // Delta files, holding the appended data
+	targets map[string]string // Delta file -> table of its source file
+	tables  []string          // Tables of the cached entry
+}
+
+// appendableFormat reports whether new data of the files can be imported
+// on its own: CSV and JSONL rows are independent lines
+func appendableFormat(params Params) bool {
+	if params.Lines > 0 {
+		return false
+	}
+	format, err := filehandler.DetectFormatFromFiles(params.FileInputs)
+	if err != nil {
+		return false
+	}
+	return format == filehandler.FormatCSV || format == filehandler.FormatJSONL
+}
+
+// resumeCache brings the cache entry of files that only grew since they
+// were cached up to date, moving it to the key of their current version. It
+// returns the import of the appended data, or reports a cache hit when the
+// content of the files did not change.
+func resumeCache(cacheH *cachehandler.CacheHandler, cacheKey string, params Params, aliases map[string]string) (*incrementalImport, bool) {
+	entry, ok := cacheH.FindAppendable(params.FileInputs)
+	if !ok {
+		return nil, false
+	}
+	if encrypted, _ := encryption.IsEncrypted(cacheH.GetCachePath(entry.CacheKey)); encrypted != params.Encrypt {
+		return nil, false
+	}
+
+	var inc *incrementalImport
+	if len(entry.Growth) > 0 {
+		var err error
+		if inc, err = newIncrementalImport(entry, params, aliases); err != nil {
+			verboseLog(params.Verbose, "Cannot read the appended data: %v", err)
+			return nil, false
+		}
+	}
+	if err := cacheH.Adopt(entry.CacheKey, cacheKey); err != nil {
+		verboseLog(params.Verbose, "Cannot reuse cached data: %v", err)
+		if inc != nil {
+			inc.cleanup()
+		}
+		return nil, false
+	}
+	if inc != nil {
+		verboseLog(params.Verbose, "%d files grew since they were cached, importing the appended data only", len(entry.Growth))
+		return inc, false
+	}
+
+	// Only the modification times changed
+	verboseLog(params.Verbose, "Cache hit! The content of the files did not change")
+	if err := cacheH.SaveMetadata(cacheKey, params.FileInputs, entry.Tables, entry.TotalRows); err != nil {
+		return nil, false
+	}
+	_ = cacheH.RecordContents(cacheKey)
+	return nil, true
+}
+
+// newIncrementalImport writes the data appended to each grown file into a
+// delta file. CSV deltas repeat the header line of their source file.
+func newIncrementalImport(entry *cachehandler.Appendable, params Params, aliases map[string]string) (*incrementalImport, error) {
+	dir, err := os.MkdirTemp("", "dataql-append-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	format, _ := filehandler.DetectFormatFromFiles(params.FileInputs)
+	inc := &incrementalImport{
+		dir:     dir,
+		targets: make(map[string]string),
+		tables:  entry.Tables,
+	}
+	for i, growth := range entry.Growth {
+		delta := filepath.Join(dir, fmt.Sprintf("delta_%d%s", i, filepath.Ext(growth.File)))
+		if err := writeDelta(delta, growth, format == filehandler.FormatCSV); err != nil {
+			inc.cleanup()
+			return nil, err
+		}
+		inc.deltas = append(inc.deltas, delta)
+		inc.targets[delta] = sourceTableName(growth.File, aliases, params.Collection)
+	}
+	return inc, nil
+}
+
+// cleanup removes the delta files
+func (inc *incrementalImport) cleanup() {
+	_ = os.RemoveAll(inc.dir)
+}
+
+// writeDelta copies the bytes appended to a file since it was cached
+func writeDelta(path string, growth cachehandler.Growth, header bool) error {
+	src, err := os.Open(growth.File)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", growth.File, err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create delta file: %w", err)
+	}
+	defer dst.Close()
+
+	if header {
+		line, err := bufio.NewReader(src).ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read header of %s: %w", growth.File, err)
+		}
+		if _, err := dst.WriteString(line); err != nil {
+			return fmt.Errorf("failed to write delta file: %w", err)
+		}
+	}
+	if _, err := src.Seek(growth.Offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read %s: %w", growth.File, err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to write delta file: %w", err)
+	}
+	return dst.Close()
+}
+
+// sourceTableName returns the table a file handler imports a file into
+func sourceTableName(file string, aliases map[string]string, collection string) string {
+	name := strings.ReplaceAll(strings.ToLower(filepath.Base(file)), filepath.Ext(file), "")
+	if alias := aliases[file]; alias != "" {
+		name = strings.ToLower(alias)
+	} else if collection != "" {
+		name = strings.ToLower(collection)
+	}
+	return tableNamePattern.ReplaceAllString(strings.ReplaceAll(name, " ", "_"), "")
+}
+
+// importIncremental appends the delta files to the cached tables. When the
+// new rows do not fit the cached tables, e.g. a column changed type, the
+// cached tables are dropped and the source files are imported in full.
+func (d *dataQL) importIncremental() error {
+	inc := d.incremental
+	defer inc.cleanup()
+
+	err := d.appendRows(inc.deltas, inc.targets)
+	if err == nil {
+		verboseLog(d.params.Verbose, "Appended the new data of %d grown files to the cache", len(inc.deltas))
+		return nil
+	}
+	verboseLog(d.params.Verbose, "Cannot append to the cached tables (%v), importing again", err)
+
+	for _, table := range inc.tables {
+		if err := d.execStatement(fmt.Sprintf(`DROP TABLE IF EXISTS "%s"`, table)); err != nil {
+			return err
+		}
+	}
+	if err := d.execStatement(`DROP TABLE IF EXISTS "schemas"`); err != nil {
+		return err
+	}
+	return d.fileHandler.Import()
+}
+
+// appendRows imports files into staging tables, then inserts their rows
+// into the table of each file given by targets, all in a single transaction
+func (d *dataQL) appendRows(files []string, targets map[string]string) (err error) {
+	typed, ok := d.storage.(storage.TypedStorage)
+	if !ok {
+		return fmt.Errorf("storage does not support typed tables")
+	}
+
+	staging := make(map[string]string, len(files))
+	for i, file := range files {
+		staging[file] = fmt.Sprintf("dataql_append_%d", i)
+	}
+	params := d.params
+	params.FileInputs = files
+	bar := progressbar.NewOptions(0, progressbar.OptionSetWriter(io.Discard))
+	handler, err := createFileHandler(params, bar, sharedStorage{typed}, staging)
+	if err != nil {
+		return err
+	}
+	defer handler.Close()
+
+	if tx, ok := d.storage.(storage.TransactionStorage); ok {
+		if err := tx.Begin(); err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				_ = tx.Rollback()
+			} else {
+				err = tx.Commit()
+			}
+		}()
+	}
+
+	if err := handler.Import(); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := d.checkAppendable(staging[file], targets[file]); err != nil {
+			return err
+		}
+		statements := []string{
+			fmt.Sprintf(`INSERT INTO "%s" BY NAME SELECT * FROM "%s"`, targets[file], staging[file]),
+			fmt.Sprintf(`DROP TABLE "%s"`, staging[file]),
+			fmt.Sprintf(`DELETE FROM "schemas" WHERE "name" = '%s'`, staging[file]),
+		}
+		for _, statement := range statements {
+			if err := d.execStatement(statement); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sharedStorage lets a file handler import into the session storage without
+// closing it when the handler is closed
+type sharedStorage struct {
+	storage.TypedStorage
+}
+
+// Close keeps the session storage open
+func (sharedStorage) Close() error {
+	return nil
+}
+
+// checkAppendable verifies that every column of a staging table exists in
+// the target table with a type its values convert to without loss
+func (d *dataQL) checkAppendable(staging, target string) error {
+	from, err := d.columnTypes(staging)
+	if err != nil {
+		return err
+	}
+	to, err := d.columnTypes(target)
+	if err != nil {
+		return err
+	}
+	if len(to) == 0 {
+		return fmt.Errorf("table %s not found", target)
+	}
+
+	for column, fromType := range from {
+		toType, ok := to[column]
+		if !ok {
+			return fmt.Errorf("column %s is not in table %s", column, target)
+		}
+		if fromType != toType && toType != string(storage.TypeVarchar) && !widens(fromType, toType) {
+			return fmt.Errorf("column %s of table %s is %s, new rows are %s", column, target, toType, fromType)
+		}
+	}
+	return nil
+}
+
+// widens reports whether the integers of a column of type from can be
+// appended to a column of type to
+func widens(from, to string) bool {
+	switch from {
+	case string(storage.TypeBigInt):
+		return to == string(storage.TypeHugeInt) || to == string(storage.TypeDouble) || storage.DataType(to).IsDecimal()
+	case string(storage.TypeHugeInt):
+		return storage.DataType(to).IsDecimal()
+	}
+	return false
+}
+
+// columnTypes returns the type of each column of a table
+func (d *dataQL) columnTypes(table string) (map[string]string, error) {
+	rows, err := d.storage.Query(storage.ColumnsQuery(d.engine(), table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := make(map[string]string)
+	for rows.Next() {
+		var tableName, column, dataType string
+		if err := rows.Scan(&tableName, &column, &dataType); err != nil {
+			return nil, err
+		}
+		types[column] = dataType
+	}
+	return types, rows.Err()
+}
+
+// execStatement runs a statement that returns no rows
+func (d *dataQL) execStatement(statement string) error {
+	rows, err := d.storage.Query(statement)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
@@ -0,0 +1,121 @@
+package dataql
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/encryption"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// attachAlias matches the names databases are attached as
+var attachAlias = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// nonIdentifier matches the characters replaced in aliases derived from file names
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// reservedAliases are the database names DuckDB and SQLite use themselves
+var reservedAliases = map[string]bool{"main": true, "memory": true, "temp": true, "system": true}
+
+// Attachment is a storage file attached to the session with --attach
+type Attachment struct {
+	Path  string
+	Alias string // Tables are queried as alias.table
+}
+
+// ParseAttachments parses --attach values written as path[:alias]. Without
+// an alias, the file name is used, e.g. sales_2023 for ./sales-2023.duckdb.
+func ParseAttachments(values []string) ([]Attachment, error) {
+	attachments := make([]Attachment, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		path, alias := value, ""
+		if i := strings.LastIndex(value, ":"); i > 0 && attachAlias.MatchString(value[i+1:]) {
+			path, alias = value[:i], value[i+1:]
+		}
+		if alias == "" {
+			alias = aliasFromPath(path)
+		}
+		if !attachAlias.MatchString(alias) {
+			return nil, fmt.Errorf("invalid --attach value %q (expected path[:alias], e.g. sales.duckdb:sales)", value)
+		}
+		if reservedAliases[strings.ToLower(alias)] {
+			return nil, fmt.Errorf("invalid --attach alias %q: %s is reserved", alias, strings.ToLower(alias))
+		}
+		if seen[strings.ToLower(alias)] {
+			return nil, fmt.Errorf("duplicate --attach alias %q", alias)
+		}
+		seen[strings.ToLower(alias)] = true
+		attachments = append(attachments, Attachment{Path: path, Alias: alias})
+	}
+	return attachments, nil
+}
+
+// aliasFromPath derives an alias from the name of a storage file
+func aliasFromPath(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name = nonIdentifier.ReplaceAllString(strings.ToLower(name), "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// attachDatabases attaches the storage files of --attach, so their tables
+// can be joined with the imported sources as alias.table; read-only with
+// --read-only
+func attachDatabases(st storage.Storage, params Params) error {
+	attachments, err := ParseAttachments(params.Attach)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		if err := attachFile(st, params, attachment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachFile attaches the storage file of attachment, read-only with
+// --read-only
+func attachFile(st storage.Storage, params Params, attachment Attachment) error {
+	// ATTACH creates missing files, which would hide a mistyped path
+	if _, err := os.Stat(attachment.Path); err != nil {
+		return fmt.Errorf("attached storage file does not exist: %s", attachment.Path)
+	}
+	if encrypted, err := encryption.IsEncrypted(attachment.Path); err == nil && encrypted {
+		return fmt.Errorf("cannot attach encrypted storage file %s; decrypt it with dataql encryption decrypt", attachment.Path)
+	}
+
+	// SQLite takes the read-only mode in the file URI, DuckDB as an option
+	path, options := attachment.Path, ""
+	if engine, _ := storage.ParseEngine(params.Engine); params.ReadOnly && engine == storage.EngineSQLite {
+		path = readOnlyPath(engine, path)
+	} else if params.ReadOnly {
+		options = " (READ_ONLY)"
+	}
+	statement := fmt.Sprintf(`ATTACH '%s' AS "%s"%s`, strings.ReplaceAll(path, "'", "''"), attachment.Alias, options)
+	if err := execStatement(st, statement); err != nil {
+		return fmt.Errorf("failed to attach %s: %w", attachment.Path, err)
+	}
+	return nil
+}
+
+// execStatement runs a statement that returns no rows. SQLite only runs a
+// statement passed to Query once its rows are read, so Exec is preferred.
+func execStatement(st storage.Storage, statement string) error {
+	if ctxStorage, ok := st.(storage.ContextStorage); ok {
+		return ctxStorage.ExecContext(context.Background(), statement)
+	}
+	rows, err := st.Query(statement)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
@@ -0,0 +1,27 @@
+package dataql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAttachments(t *testing.T) {
+	attachments, err := ParseAttachments([]string{"sales.duckdb:s", "./data/2023-orders.duckdb"})
+	require.NoError(t, err)
+	assert.Equal(t, []Attachment{
+		{Path: "sales.duckdb", Alias: "s"},
+		{Path: "./data/2023-orders.duckdb", Alias: "_2023_orders"},
+	}, attachments)
+
+	for _, values := range [][]string{
+		{"a.duckdb:main"},
+		{"a.duckdb", "b/a.duckdb"},
+		{"a.duckdb:A", "b.duckdb:a"},
+		{".duckdb"},
+	} {
+		_, err := ParseAttachments(values)
+		assert.Error(t, err, values)
+	}
+}
@@ -0,0 +1,41 @@
+package dataql
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/auditlog"
+)
+
+// openAuditLog opens the audit log configured by --audit-log, or returns nil when auditing is off
+func openAuditLog(path string) (*auditlog.Logger, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return auditlog.New(path)
+}
+
+// auditQuery records an executed statement in the audit log, if enabled.
+// A failure to write the log is reported but does not fail the query.
+func (d *dataQL) auditQuery(query string, rows int64, start time.Time, queryErr error) {
+	if d.audit == nil {
+		return
+	}
+
+	entry := auditlog.Entry{
+		Timestamp:  start,
+		Caller:     auditlog.CallerCLI,
+		Sources:    d.auditSources,
+		Query:      query,
+		Rows:       rows,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if queryErr != nil {
+		entry.Error = queryErr.Error()
+	}
+
+	if err := d.audit.Log(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
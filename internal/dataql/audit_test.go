@@ -0,0 +1,54 @@
+package dataql
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/auditlog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog_RecordsStatements(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	dql, err := New(Params{
+		FileInputs: []string{"../../tests/fixtures/csv/simple.csv"},
+		Delimiter:  ",",
+		Quiet:      true,
+		AuditLog:   path,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, dql.Exec("SELECT * FROM simple WHERE id < 3"))
+	assert.Error(t, dql.Exec("SELECT * FROM missing_table"))
+	assert.NoError(t, dql.Close())
+
+	file, err := os.Open(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer file.Close()
+
+	var entries []auditlog.Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry auditlog.Entry
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+
+	if !assert.Len(t, entries, 2) {
+		return
+	}
+	assert.Equal(t, auditlog.CallerCLI, entries[0].Caller)
+	assert.Equal(t, []string{"../../tests/fixtures/csv/simple.csv"}, entries[0].Sources)
+	assert.Equal(t, "SELECT * FROM simple WHERE id < 3", entries[0].Query)
+	assert.Equal(t, int64(2), entries[0].Rows)
+	assert.Empty(t, entries[0].Error)
+	assert.Contains(t, entries[1].Error, "missing_table")
+}
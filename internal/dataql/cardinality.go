@@ -0,0 +1,83 @@
+package dataql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// CardinalityReport rates how many distinct values a column holds, flagging
+// the columns that could be a primary key and the ones that never change
+type CardinalityReport struct {
+	Uniqueness   float64 `json:"uniqueness"`    // Distinct values as a percentage of all rows
+	KeyCandidate bool    `json:"key_candidate"` // Every row holds a different non-null value
+	Constant     bool    `json:"constant"`      // At most one distinct non-null value
+}
+
+// newCardinalityReport rates a profiled column; empty tables have no report
+func newCardinalityReport(col ColumnProfile, rowCount int64) *CardinalityReport {
+	if rowCount == 0 {
+		return nil
+	}
+
+	return &CardinalityReport{
+		Uniqueness:   float64(col.Distinct) * 100 / float64(rowCount),
+		KeyCandidate: col.Nulls == 0 && col.Distinct == rowCount,
+		Constant:     col.Distinct <= 1,
+	}
+}
+
+// label names the flag of the report: "key candidate", "constant" or ""
+func (r *CardinalityReport) label() string {
+	switch {
+	case r.KeyCandidate && r.Constant:
+		return "key candidate, constant" // A single row
+	case r.KeyCandidate:
+		return "key candidate"
+	case r.Constant:
+		return "constant"
+	}
+	return ""
+}
+
+// printCardinality prints the distinct count and uniqueness of every column
+// of a table, flagging key candidates and constant columns
+func (d *dataQL) printCardinality(tableName string) error {
+	rowCount, columns, err := d.profileTable(tableName)
+	if err != nil {
+		return err
+	}
+
+	sectionColor := color.New(color.FgCyan)
+	sectionColor.Fprintf(d.out, "\nCardinality:\n\n")
+
+	if rowCount == 0 {
+		fmt.Fprintln(d.out, "  (no rows)")
+		return nil
+	}
+
+	nameWidth := len("Column")
+	for _, col := range columns {
+		if len(col.Name) > nameWidth {
+			nameWidth = len(col.Name)
+		}
+	}
+
+	var keys []string
+	fmt.Fprintf(d.out, "  %-*s  %8s  %8s\n", nameWidth, "Column", "Distinct", "Unique")
+	for _, col := range columns {
+		report := newCardinalityReport(col, rowCount)
+		fmt.Fprintf(d.out, "  %-*s  %8d  %7.1f%%  %s\n", nameWidth, col.Name, col.Distinct, report.Uniqueness, report.label())
+		if report.KeyCandidate {
+			keys = append(keys, col.Name)
+		}
+	}
+
+	if len(keys) == 0 {
+		fmt.Fprintln(d.out, "\nNo single-column key candidates.")
+	} else {
+		fmt.Fprintf(d.out, "\nKey candidates: %s\n", strings.Join(keys, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package dataql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCardinalityReport(t *testing.T) {
+	assert.Nil(t, newCardinalityReport(ColumnProfile{}, 0))
+
+	key := newCardinalityReport(ColumnProfile{Distinct: 4}, 4)
+	assert.Equal(t, 100.0, key.Uniqueness)
+	assert.True(t, key.KeyCandidate)
+	assert.False(t, key.Constant)
+	assert.Equal(t, "key candidate", key.label())
+
+	// A NULL rules out a key even when the other values are unique
+	nullable := newCardinalityReport(ColumnProfile{Nulls: 1, Distinct: 3}, 4)
+	assert.False(t, nullable.KeyCandidate)
+	assert.Equal(t, "", nullable.label())
+
+	constant := newCardinalityReport(ColumnProfile{Nulls: 2, Distinct: 1}, 4)
+	assert.Equal(t, 25.0, constant.Uniqueness)
+	assert.True(t, constant.Constant)
+	assert.Equal(t, "constant", constant.label())
+}
+
+func TestProfile_Cardinality(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "orders.csv")
+	assert.NoError(t, os.WriteFile(csvPath, []byte("id,region,status\n1,eu,open\n2,us,open\n3,eu,open\n"), 0644))
+
+	dql, err := New(Params{FileInputs: []string{csvPath}, Delimiter: ",", Quiet: true})
+	assert.NoError(t, err)
+	defer dql.Close()
+
+	tables, err := dql.Profile(DescribeOptions{Cardinality: true})
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+
+	id, region, status := tables[0].Columns[0].Cardinality, tables[0].Columns[1].Cardinality, tables[0].Columns[2].Cardinality
+	assert.True(t, id.KeyCandidate)
+	assert.False(t, region.KeyCandidate)
+	assert.False(t, region.Constant)
+	assert.InDelta(t, 66.7, region.Uniqueness, 0.1)
+	assert.True(t, status.Constant)
+}
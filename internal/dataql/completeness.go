@@ -0,0 +1,111 @@
+package dataql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// columnCompleteness counts the missing values of a column
+type columnCompleteness struct {
+	Name  string
+	Type  string
+	Nulls int64
+	Empty int64 // Empty strings; only text columns can hold them
+}
+
+// getEmptyCount returns the number of empty strings in a text column, or nil
+// for other columns. CSV files import missing text values as empty strings
+// rather than NULL, so they are counted apart from NULLs.
+func (d *dataQL) getEmptyCount(tableName string, col ColumnProfile) (*int64, error) {
+	if !isTextType(col.Type) {
+		return nil, nil
+	}
+
+	count, err := d.queryCount(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE \"%s\" = ''", tableName, col.Name))
+	if err != nil {
+		return nil, err
+	}
+	return &count, nil
+}
+
+// countBlankRows returns the number of rows where every column is NULL or an empty string
+func (d *dataQL) countBlankRows(tableName string, columns []ColumnProfile) (int64, error) {
+	conditions := make([]string, len(columns))
+	for i, col := range columns {
+		conditions[i] = fmt.Sprintf("\"%s\" IS NULL", col.Name)
+		if isTextType(col.Type) {
+			conditions[i] = fmt.Sprintf("(\"%s\" IS NULL OR \"%s\" = '')", col.Name, col.Name)
+		}
+	}
+
+	return d.queryCount(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", tableName, strings.Join(conditions, " AND ")))
+}
+
+// printCompleteness prints the NULL and empty-string counts of every column
+// with a bar of the missing percentage, and the number of blank rows
+func (d *dataQL) printCompleteness(tableName string) error {
+	columns, err := d.getTableColumns(tableName)
+	if err != nil {
+		return err
+	}
+
+	rowCount, err := d.queryCount(fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName))
+	if err != nil {
+		return err
+	}
+
+	stats := make([]columnCompleteness, 0, len(columns))
+	nameWidth := len("Column")
+	for _, col := range columns {
+		stat := columnCompleteness{Name: col.Name, Type: col.Type}
+
+		stat.Nulls, err = d.queryCount(fmt.Sprintf("SELECT COUNT(*) - COUNT(\"%s\") FROM %s", col.Name, tableName))
+		if err != nil {
+			return fmt.Errorf("failed to count null values of %s: %w", col.Name, err)
+		}
+
+		empty, err := d.getEmptyCount(tableName, col)
+		if err != nil {
+			return fmt.Errorf("failed to count empty values of %s: %w", col.Name, err)
+		}
+		if empty != nil {
+			stat.Empty = *empty
+		}
+
+		if len(col.Name) > nameWidth {
+			nameWidth = len(col.Name)
+		}
+		stats = append(stats, stat)
+	}
+
+	blankRows, err := d.countBlankRows(tableName, columns)
+	if err != nil {
+		return err
+	}
+
+	sectionColor := color.New(color.FgCyan)
+	sectionColor.Fprintf(d.out, "\nCompleteness:\n\n")
+
+	fmt.Fprintf(d.out, "  %-*s  %8s  %8s  %9s\n", nameWidth, "Column", "Nulls", "Empty", "Missing")
+	for _, stat := range stats {
+		var missingPct float64
+		if rowCount > 0 {
+			missingPct = float64(stat.Nulls+stat.Empty) * 100 / float64(rowCount)
+		}
+		bar := int(missingPct * histogramBarWidth / 100)
+		if bar == 0 && stat.Nulls+stat.Empty > 0 {
+			bar = 1 // Keep incomplete columns visible
+		}
+
+		empty := "-"
+		if isTextType(stat.Type) {
+			empty = fmt.Sprint(stat.Empty)
+		}
+		fmt.Fprintf(d.out, "  %-*s  %8d  %8s  %8.1f%%  %s\n", nameWidth, stat.Name, stat.Nulls, empty, missingPct, strings.Repeat("█", bar))
+	}
+
+	fmt.Fprintf(d.out, "\nBlank rows (every column NULL or empty): %d\n", blankRows)
+	return nil
+}
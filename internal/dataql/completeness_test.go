@@ -0,0 +1,47 @@
+package dataql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile_Completeness(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "blank.csv")
+	assert.NoError(t, os.WriteFile(csvPath, []byte("id,name,score\n1,Alice,2\n,,\n3,,\n"), 0644))
+
+	dql, err := New(Params{FileInputs: []string{csvPath}, Delimiter: ",", Quiet: true})
+	assert.NoError(t, err)
+	defer dql.Close()
+
+	tables, err := dql.Profile(DescribeOptions{Completeness: true})
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+
+	table := tables[0]
+	if assert.NotNil(t, table.BlankRows) {
+		assert.Equal(t, int64(1), *table.BlankRows)
+	}
+
+	// Numeric columns hold NULLs, text columns hold empty strings
+	id, name := table.Columns[0], table.Columns[1]
+	assert.Equal(t, int64(1), id.Nulls)
+	assert.Nil(t, id.Empty)
+	assert.Equal(t, int64(0), name.Nulls)
+	if assert.NotNil(t, name.Empty) {
+		assert.Equal(t, int64(2), *name.Empty)
+	}
+}
+
+func TestProfile_WithoutCompleteness(t *testing.T) {
+	dql, err := New(Params{FileInputs: []string{"../../tests/fixtures/csv/null_values.csv"}, Delimiter: ",", Quiet: true})
+	assert.NoError(t, err)
+	defer dql.Close()
+
+	tables, err := dql.Profile(DescribeOptions{})
+	assert.NoError(t, err)
+	assert.Nil(t, tables[0].BlankRows)
+	assert.Nil(t, tables[0].Columns[1].Empty)
+}
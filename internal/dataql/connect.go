@@ -0,0 +1,99 @@
+package dataql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/schollz/progressbar/v3"
+
+	"github.com/adrianolaselva/dataql/pkg/connections"
+	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/secrets"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// isDatabaseFormat checks if a format is served by a database handler
+func isDatabaseFormat(format filehandler.Format) bool {
+	switch format {
+	case filehandler.FormatPostgres, filehandler.FormatMySQL, filehandler.FormatDuckDB,
+		filehandler.FormatMongoDB, filehandler.FormatDynamoDB:
+		return true
+	}
+	return false
+}
+
+// connectionURLs resolves the secret placeholders of database and message
+// queue URLs, e.g. postgres://app:{{aws-sm:prod/pg-password}}@db/shop/orders.
+// Only the handler sees the secrets: logs, the cache metadata, the audit log
+// and lineage keep the placeholders.
+func connectionURLs(sources []string) ([]string, error) {
+	resolved := make([]string, len(sources))
+	for i, source := range sources {
+		url, err := secrets.ResolveURL(context.Background(), source)
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = url
+	}
+	return resolved, nil
+}
+
+// savedConnections rewrites the sources naming a connection saved with
+// dataql auth add, e.g. mydb://orders, into its URL, keeping their aliases
+func savedConnections(sources []string, aliases map[string]string) ([]string, error) {
+	expanded := make([]string, len(sources))
+	for i, source := range sources {
+		url, err := connections.Expand(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read connection of %s: %w", source, err)
+		}
+		expanded[i] = url
+		if url != source && aliases[source] != "" {
+			aliases[url] = aliases[source]
+			delete(aliases, source)
+		}
+	}
+	return expanded, nil
+}
+
+// connectSource imports a table from a database URL into the current session,
+// so remote lookup tables can be joined with the data already loaded.
+// The table is named after alias, or after the remote table when alias is empty.
+func (d *dataQL) connectSource(source, alias string) error {
+	url, err := connections.Expand(source)
+	if err != nil {
+		return fmt.Errorf("failed to read connection of %s: %w", source, err)
+	}
+	format, err := filehandler.DetectFormat(url)
+	if err != nil || !isDatabaseFormat(format) {
+		return fmt.Errorf("not a database URL: %s (use postgres://, mysql://, duckdb://, mongodb:// or dynamodb://)", url)
+	}
+
+	params := Params{
+		FileInputs: []string{url},
+		Lines:      d.params.Lines,
+		Collection: alias,
+		Verbose:    d.params.Verbose,
+	}
+
+	// The REPL prompt is active, so the import runs without a progress bar
+	handler, err := createFileHandler(params, progressbar.DefaultSilent(-1), d.storage, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create database handler: %w", err)
+	}
+	defer func() {
+		_ = handler.Close()
+	}()
+
+	if err := handler.Import(); err != nil {
+		return fmt.Errorf("failed to import from %s: %w", format, err)
+	}
+	if err := storage.ReplaceTables(d.storage); err != nil {
+		return err
+	}
+
+	d.refreshCompleter()
+
+	fmt.Fprintf(d.out, "Connected to %s: imported %d rows\n", format, handler.Lines())
+	return nil
+}
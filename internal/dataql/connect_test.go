@@ -0,0 +1,35 @@
+package dataql
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/connections"
+	"github.com/adrianolaselva/dataql/pkg/keyring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedConnectionSource(t *testing.T) {
+	keyring.MockInit()
+	storage := filepath.Join(t.TempDir(), "store.duckdb")
+	dql, err := New(Params{
+		FileInputs:     []string{"../../tests/fixtures/csv/simple.csv"},
+		DataSourceName: storage,
+		Delimiter:      ",",
+		Quiet:          true,
+	})
+	require.NoError(t, err)
+	_, err = dql.Query("SELECT COUNT(*) FROM simple", 0)
+	require.NoError(t, err)
+	require.NoError(t, dql.Close())
+
+	require.NoError(t, connections.Save(connections.Connection{Name: "local", URL: "duckdb://" + storage}))
+
+	dql, err = New(Params{FileInputs: []string{"local://simple"}, Quiet: true})
+	require.NoError(t, err)
+	defer dql.Close()
+	result, err := dql.Query("SELECT name FROM simple ORDER BY id LIMIT 1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{"John"}}, result.Rows)
+}
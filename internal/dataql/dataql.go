@@ -2,6 +2,7 @@ package dataql
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -10,12 +11,16 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/adrianolaselva/dataql/internal/exportdata"
+	"github.com/adrianolaselva/dataql/pkg/auditlog"
 	"github.com/adrianolaselva/dataql/pkg/azurehandler"
 	"github.com/adrianolaselva/dataql/pkg/cachehandler"
+	"github.com/adrianolaselva/dataql/pkg/clierror"
 	"github.com/adrianolaselva/dataql/pkg/compressionhandler"
+	"github.com/adrianolaselva/dataql/pkg/encryption"
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
 	avroHandler "github.com/adrianolaselva/dataql/pkg/filehandler/avro"
 	compositeHandler "github.com/adrianolaselva/dataql/pkg/filehandler/composite"
@@ -33,12 +38,21 @@ import (
 	xmlHandler "github.com/adrianolaselva/dataql/pkg/filehandler/xml"
 	yamlHandler "github.com/adrianolaselva/dataql/pkg/filehandler/yaml"
 	"github.com/adrianolaselva/dataql/pkg/gcshandler"
+	"github.com/adrianolaselva/dataql/pkg/history"
+	"github.com/adrianolaselva/dataql/pkg/lineage"
+	"github.com/adrianolaselva/dataql/pkg/mask"
+	"github.com/adrianolaselva/dataql/pkg/metrics"
+	"github.com/adrianolaselva/dataql/pkg/pluginhandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
 	"github.com/adrianolaselva/dataql/pkg/queryerror"
+	"github.com/adrianolaselva/dataql/pkg/rangedownload"
 	"github.com/adrianolaselva/dataql/pkg/repl"
 	"github.com/adrianolaselva/dataql/pkg/s3handler"
 	"github.com/adrianolaselva/dataql/pkg/stdinhandler"
 	"github.com/adrianolaselva/dataql/pkg/storage"
-	"github.com/adrianolaselva/dataql/pkg/storage/duckdb"
+	"github.com/adrianolaselva/dataql/pkg/timezone"
+	"github.com/adrianolaselva/dataql/pkg/tlsconfig"
+	"github.com/adrianolaselva/dataql/pkg/udf"
 	"github.com/adrianolaselva/dataql/pkg/urlhandler"
 	"github.com/chzyer/readline"
 	"github.com/fatih/color"
@@ -50,6 +64,7 @@ const (
 	cliInterruptPrompt = "^C"
 	cliEOFPrompt       = "exit"
 	defaultPageSize    = 25
+	defaultSampleSize  = 10
 )
 
 // Version is set by the main package during initialization
@@ -61,12 +76,30 @@ type DataQL interface {
 	RunStorageOnly() error
 	RunAndDescribe() error
 	DescribeAll() error
+	Profile(opts DescribeOptions) ([]TableProfile, error)
+	Schema() ([]TableSchema, error)
+	Import() error
+	Exec(query string) error
+	Query(query string, limit int) (*QueryResult, error)
+	QueryContext(ctx context.Context, query string) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string) error
+	ResultRows() int64
+	SetOutput(w io.Writer)
 	Close() error
 }
 
+// stdout writes to the current os.Stdout, so callers redirecting it (such as
+// the MCP server) still capture the output
+type stdout struct{}
+
+func (stdout) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
 type dataQL struct {
 	storage            storage.Storage
 	bar                *progressbar.ProgressBar
+	out                io.Writer // Destination of results and command output
 	params             Params
 	fileHandler        filehandler.FileHandler
 	urlHandler         *urlhandler.URLHandler
@@ -75,15 +108,41 @@ type dataQL struct {
 	azureHandler       *azurehandler.AzureHandler
 	stdinHandler       *stdinhandler.StdinHandler
 	compressionHandler *compressionhandler.CompressionHandler
+	pluginHandler      *pluginhandler.PluginHandler
 	cacheHandler       *cachehandler.CacheHandler
+	incremental        *incrementalImport
+	remote             []remoteSource // Sources read in place with --in-place
+	storageAlias       string         // Alias of the storage file attached by a --read-only session importing files
+	tableAliases       map[string]string
+	encrypted          *encryption.WorkingCopy
+	completer          *repl.SQLCompleter
 	pageSize           int
-	paging             bool              // Enable paging in REPL mode
-	showTiming         bool              // Show query execution time
-	truncate           int               // Truncate column values longer than N characters
-	vertical           bool              // Display results in vertical format
-	queryParams        map[string]string // Parsed query parameters
-	cacheHit           bool              // Whether cache was used
-	cacheKey           string            // Cache key for current session
+	paging             bool               // Enable paging in REPL mode
+	showTiming         bool               // Show query execution time
+	truncate           int                // Truncate column values longer than N characters
+	vertical           bool               // Display results in vertical format
+	pager              string             // Pager command override ("off" disables the external pager)
+	outputMode         string             // Result display format (table, json, jsonl, csv, markdown)
+	autocommit         bool               // Commit each statement immediately (off: statements run in an explicit transaction)
+	aliases            map[string]string  // User-defined REPL command aliases from the config file
+	queryParams        map[string]string  // Parsed query parameters
+	cacheHit           bool               // Whether cache was used
+	imported           bool               // Whether the data sources were already imported
+	cacheKey           string             // Cache key for current session
+	lastQuery          string             // Last SQL statement executed in the REPL (used by \watch)
+	resultRows         int64              // Rows returned or exported by the last query (-1: unknown)
+	mu                 sync.Mutex         // Guards cancel
+	cancel             context.CancelFunc // Cancels the running REPL operation on Ctrl-C
+	confirm            confirmFunc        // Reads an answer at the REPL prompt (nil: not interactive)
+	audit              *auditlog.Logger   // Audit log of executed statements (nil: disabled)
+	auditSources       []string           // Sources as given by the user, with URL passwords redacted
+	sourceNames        []string           // Source of each file input as given by the user, with URL passwords redacted
+	history            *history.Store     // Query history (nil: disabled)
+	historySources     []string           // Sources recorded in the history (none in storage-only mode)
+	historyDir         string             // Working directory the relative sources are resolved against
+	udfs               []*udf.Function    // User-defined functions registered in the storage
+	baseline           *dataQL            // Session holding the source given to describe --compare (nil: not opened)
+	outputTZ           *time.Location     // Time zone TIMESTAMPTZ values are displayed and exported in
 }
 
 // verboseLog prints a message if verbose mode is enabled
@@ -95,15 +154,49 @@ func verboseLog(verbose bool, format string, args ...interface{}) {
 
 // New creates a new DataQL instance
 func New(params Params) (DataQL, error) {
+	params = applyPlain(params)
 	verboseLog(params.Verbose, "Starting DataQL initialization...")
 	verboseLog(params.Verbose, "File inputs: %v", params.FileInputs)
+	auditSources := auditlog.RedactSources(params.FileInputs)
+
+	outputTZ, err := timezone.Load(params.OutputTZ)
+	if err != nil {
+		return nil, clierror.Parse(err)
+	}
 
 	// Parse file inputs to extract paths and aliases (e.g., "file.csv:alias")
 	fileInputs := ParseFileInputs(params.FileInputs)
 	aliases := GetAliasMap(fileInputs)
 	params.FileInputs = GetPaths(fileInputs)
+	sourceNames := auditlog.RedactSources(params.FileInputs)
 	verboseLog(params.Verbose, "Parsed aliases: %v", aliases)
 
+	// Sources naming a saved connection get its URL, with the credentials
+	// left as keyring placeholders until the handler connects
+	expandedFiles, err := savedConnections(params.FileInputs, aliases)
+	if err != nil {
+		return nil, sourceError(err, sourceNames, params.FileInputs)
+	}
+	params.FileInputs = expandedFiles
+
+	if err := CheckInPlace(params); err != nil {
+		return nil, clierror.Parse(err)
+	}
+	// Remote Parquet and CSV sources read in place are neither downloaded
+	// nor cached: views over them are created once the storage is open
+	var remote []remoteSource
+	params.FileInputs, sourceNames, remote = splitInPlace(params.FileInputs, sourceNames, aliases, params)
+
+	// Create cache handler if caching is enabled; remote sources are kept
+	// in it so unchanged objects are not downloaded again
+	cacheH, err := cachehandler.NewCacheHandler(params.CacheDir, params.Cache)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cache handler: %w", err)
+	}
+	cacheH.SetLimits(params.CacheTTL, params.CacheMaxSize)
+	downloads := cacheH.Downloads()
+	parts := rangedownload.Options{Concurrency: params.DownloadParts, PartSize: params.DownloadPartSize}
+
 	// Create stdin handler to resolve any stdin inputs ("-")
 	stdinH := stdinhandler.NewStdinHandler()
 
@@ -112,7 +205,7 @@ func New(params Params) (DataQL, error) {
 	resolvedFiles, err := stdinH.ResolveFiles(params.FileInputs, params.InputFormat)
 	if err != nil {
 		_ = stdinH.Cleanup()
-		return nil, fmt.Errorf("failed to read stdin: %w", err)
+		return nil, clierror.Source("-", fmt.Errorf("failed to read stdin: %w", err))
 	}
 	// Update aliases map with resolved stdin paths
 	for i, original := range params.FileInputs {
@@ -123,8 +216,33 @@ func New(params Params) (DataQL, error) {
 	}
 	params.FileInputs = resolvedFiles
 
+	// Progress goes to stderr, and only on terminals, to keep stdout clean
+	// for pipelines. Each source shows its download, decompression and
+	// import phases in turn.
+	barWriter := progress.Output(params.Quiet)
+	reporter := progress.New(barWriter)
+
 	// Create URL handler to resolve any HTTP/HTTPS URLs in the file inputs
 	urlH := urlhandler.NewURLHandler()
+	urlH.SetProgress(reporter)
+	urlH.SetCache(downloads)
+	urlH.SetTimeout(params.DownloadTimeout)
+	headers, err := urlhandler.ParseHeaders(params.Headers)
+	if err != nil {
+		_ = stdinH.Cleanup()
+		return nil, clierror.Parse(err)
+	}
+	urlH.SetHeaders(headers)
+	if err := urlH.SetTLS(tlsOptions(params)); err != nil {
+		_ = stdinH.Cleanup()
+		return nil, clierror.Parse(err)
+	}
+	if params.CookieJar != "" {
+		if err := urlH.SetCookieJar(params.CookieJar); err != nil {
+			_ = stdinH.Cleanup()
+			return nil, clierror.Parse(err)
+		}
+	}
 
 	// Check if any file inputs are HTTP/HTTPS URLs and download them
 	verboseLog(params.Verbose, "Resolving HTTP/HTTPS URLs...")
@@ -132,12 +250,28 @@ func New(params Params) (DataQL, error) {
 	if err != nil {
 		_ = stdinH.Cleanup()
 		_ = urlH.Cleanup() // Clean up any downloaded files on error
-		return nil, fmt.Errorf("failed to resolve file inputs: %w", err)
+		return nil, sourceError(fmt.Errorf("failed to resolve file inputs: %w", err), sourceNames, params.FileInputs)
 	}
 	params.FileInputs = resolvedFiles
 
 	// Create S3 handler to resolve any S3 URLs
 	s3H := s3handler.NewS3Handler()
+	s3H.SetProgress(reporter)
+	s3H.SetCache(downloads)
+	s3H.SetDownload(parts)
+	s3H.SetTLS(tlsOptions(params))
+	s3Options := s3handler.Options{
+		Endpoint:      params.S3Endpoint,
+		Profile:       params.S3Profile,
+		AssumeRole:    params.S3AssumeRole,
+		RequesterPays: params.S3RequesterPays,
+	}
+	if err := s3Options.Check(); err != nil {
+		_ = stdinH.Cleanup()
+		_ = urlH.Cleanup()
+		return nil, clierror.Parse(err)
+	}
+	s3H.SetOptions(s3Options)
 
 	// Check if any file inputs are S3 URLs and download them
 	verboseLog(params.Verbose, "Resolving S3 URLs...")
@@ -146,12 +280,23 @@ func New(params Params) (DataQL, error) {
 		_ = stdinH.Cleanup()
 		_ = urlH.Cleanup()
 		_ = s3H.Cleanup()
-		return nil, fmt.Errorf("failed to resolve S3 inputs: %w", err)
+		return nil, sourceError(fmt.Errorf("failed to resolve S3 inputs: %w", err), sourceNames, params.FileInputs)
 	}
 	params.FileInputs = resolvedFiles
 
 	// Create GCS handler to resolve any GCS URLs
 	gcsH := gcshandler.NewGCSHandler()
+	gcsH.SetProgress(reporter)
+	gcsH.SetCache(downloads)
+	gcsH.SetDownload(parts)
+	gcsOptions := gcshandler.Options{CredentialsFile: params.GCSCredentials}
+	if err := gcsOptions.Check(); err != nil {
+		_ = stdinH.Cleanup()
+		_ = urlH.Cleanup()
+		_ = s3H.Cleanup()
+		return nil, clierror.Parse(err)
+	}
+	gcsH.SetOptions(gcsOptions)
 
 	// Check if any file inputs are GCS URLs and download them
 	verboseLog(params.Verbose, "Resolving GCS URLs...")
@@ -161,12 +306,20 @@ func New(params Params) (DataQL, error) {
 		_ = urlH.Cleanup()
 		_ = s3H.Cleanup()
 		_ = gcsH.Cleanup()
-		return nil, fmt.Errorf("failed to resolve GCS inputs: %w", err)
+		return nil, sourceError(fmt.Errorf("failed to resolve GCS inputs: %w", err), sourceNames, params.FileInputs)
 	}
 	params.FileInputs = resolvedFiles
 
 	// Create Azure handler to resolve any Azure Blob URLs
 	azureH := azurehandler.NewAzureHandler()
+	azureH.SetProgress(reporter)
+	azureH.SetCache(downloads)
+	azureH.SetDownload(parts)
+	azureH.SetOptions(azurehandler.Options{
+		Account:          params.AzureAccount,
+		SASToken:         params.AzureSASToken,
+		ConnectionString: params.AzureConnString,
+	})
 
 	// Check if any file inputs are Azure URLs and download them
 	verboseLog(params.Verbose, "Resolving Azure Blob URLs...")
@@ -177,13 +330,14 @@ func New(params Params) (DataQL, error) {
 		_ = s3H.Cleanup()
 		_ = gcsH.Cleanup()
 		_ = azureH.Cleanup()
-		return nil, fmt.Errorf("failed to resolve Azure inputs: %w", err)
+		return nil, sourceError(fmt.Errorf("failed to resolve Azure inputs: %w", err), sourceNames, params.FileInputs)
 	}
 	params.FileInputs = resolvedFiles
 	verboseLog(params.Verbose, "Resolved file inputs: %v", params.FileInputs)
 
 	// Create compression handler to decompress any compressed files
 	compressionH := compressionhandler.NewCompressionHandler()
+	compressionH.SetProgress(reporter)
 
 	// Check if any file inputs are compressed and decompress them
 	verboseLog(params.Verbose, "Checking for compressed files...")
@@ -198,7 +352,7 @@ func New(params Params) (DataQL, error) {
 		_ = gcsH.Cleanup()
 		_ = azureH.Cleanup()
 		_ = compressionH.Cleanup()
-		return nil, fmt.Errorf("failed to decompress files: %w", err)
+		return nil, sourceError(fmt.Errorf("failed to decompress files: %w", err), sourceNames, params.FileInputs)
 	}
 	// Update aliases map with resolved compressed paths
 	// The table name should be derived from the original file name (without compression extension)
@@ -227,8 +381,8 @@ func New(params Params) (DataQL, error) {
 	params.FileInputs = resolvedFiles
 	verboseLog(params.Verbose, "Decompressed file inputs: %v", params.FileInputs)
 
-	// Create cache handler if caching is enabled
-	cacheH, err := cachehandler.NewCacheHandler(params.CacheDir, params.Cache)
+	// Create plugin handler to read the formats of installed plugins
+	pluginH, err := pluginhandler.NewPluginHandler("")
 	if err != nil {
 		_ = stdinH.Cleanup()
 		_ = urlH.Cleanup()
@@ -236,27 +390,75 @@ func New(params Params) (DataQL, error) {
 		_ = gcsH.Cleanup()
 		_ = azureH.Cleanup()
 		_ = compressionH.Cleanup()
-		return nil, fmt.Errorf("failed to initialize cache handler: %w", err)
+		return nil, fmt.Errorf("failed to load plugins: %w", err)
 	}
 
+	// Convert the files read by plugins to JSON Lines
+	verboseLog(params.Verbose, "Checking for plugin formats...")
+	originalFilesBeforePlugins := make([]string, len(params.FileInputs))
+	copy(originalFilesBeforePlugins, params.FileInputs)
+	resolvedFiles, err = pluginH.ResolveFiles(params.FileInputs)
+	if err != nil {
+		_ = stdinH.Cleanup()
+		_ = urlH.Cleanup()
+		_ = s3H.Cleanup()
+		_ = gcsH.Cleanup()
+		_ = azureH.Cleanup()
+		_ = compressionH.Cleanup()
+		_ = pluginH.Cleanup()
+		return nil, sourceError(err, sourceNames, params.FileInputs)
+	}
+	// The table name is derived from the file read by the plugin, as for decompressed files
+	for i, original := range originalFilesBeforePlugins {
+		if original == resolvedFiles[i] {
+			continue
+		}
+		if aliases[original] != "" {
+			aliases[resolvedFiles[i]] = aliases[original]
+			delete(aliases, original)
+		} else if params.Collection == "" {
+			baseName := filepath.Base(original)
+			aliases[resolvedFiles[i]] = strings.TrimSuffix(baseName, filepath.Ext(baseName))
+		}
+		verboseLog(params.Verbose, "Plugin %s read %s -> %s", pluginH.Find(original).Name, original, resolvedFiles[i])
+	}
+	params.FileInputs = resolvedFiles
+
 	// Check if we can use cached data
 	var cacheHit bool
 	var cacheKey string
 	var storagePath string
 
-	if cacheH.IsEnabled() {
+	// --storage keeps the imported data itself, so it takes precedence over the cache
+	if cacheH.IsEnabled() && len(params.FileInputs) > 0 && params.DataSourceName == "" {
+		// Generate cache key for potential save later
+		cacheKey, _ = cacheH.GenerateCacheKey(params.FileInputs)
+
 		verboseLog(params.Verbose, "Checking for cached data...")
 		valid, cachePath, err := cacheH.IsCacheValid(params.FileInputs)
 		if err != nil {
 			verboseLog(params.Verbose, "Cache validation error: %v", err)
 		} else if valid {
-			verboseLog(params.Verbose, "Cache hit! Using cached data from: %s", cachePath)
-			cacheHit = true
-			storagePath = cachePath
+			if encrypted, _ := encryption.IsEncrypted(cachePath); encrypted != params.Encrypt {
+				// Entries follow --encrypt, so plaintext data is never reused by an encrypted session
+				verboseLog(params.Verbose, "Cached data does not match --encrypt, importing again")
+				_ = cacheH.ClearCacheEntry(cacheKey)
+			} else {
+				verboseLog(params.Verbose, "Cache hit! Using cached data from: %s", cachePath)
+				cacheHit = true
+				storagePath = cachePath
+				_ = cacheH.Touch(cacheKey)
+			}
 		}
+	}
 
-		// Generate cache key for potential save later
-		cacheKey, _ = cacheH.GenerateCacheKey(params.FileInputs)
+	// Files that only grew since they were cached need their new data only
+	var incremental *incrementalImport
+	if cacheH.IsEnabled() && cacheKey != "" && !cacheHit && appendableFormat(params) {
+		incremental, cacheHit = resumeCache(cacheH, cacheKey, params, aliases)
+		if cacheHit {
+			storagePath = cacheH.GetCachePath(cacheKey)
+		}
 	}
 
 	// Determine storage path
@@ -265,47 +467,133 @@ func New(params Params) (DataQL, error) {
 			// Use cache path for new import
 			storagePath = cacheH.GetCachePath(cacheKey)
 			verboseLog(params.Verbose, "Will cache data to: %s", storagePath)
-		} else if params.DataSourceName != "" {
+		} else if params.DataSourceName != "" && !params.ReadOnly {
 			storagePath = params.DataSourceName
 		}
-		// else: empty string means in-memory
+		// else: empty string means in-memory, also with --read-only, where
+		// the storage file is attached next to the imported sources
 	}
+	// --if-exists applies to the tables of the storage file, not to cache entries
+	intoStorage := params.DataSourceName != "" && storagePath == params.DataSourceName
 
-	verboseLog(params.Verbose, "Initializing DuckDB storage...")
-	duckDBStorage, err := duckdb.NewDuckDBStorage(storagePath)
+	storagePath, encrypted, err := openEncrypted(storagePath, params.Encrypt)
 	if err != nil {
+		if incremental != nil {
+			incremental.cleanup()
+		}
 		_ = stdinH.Cleanup()
 		_ = urlH.Cleanup()
 		_ = s3H.Cleanup()
 		_ = gcsH.Cleanup()
 		_ = azureH.Cleanup()
 		_ = compressionH.Cleanup()
+		_ = pluginH.Cleanup()
+		return nil, err
+	}
+	// The decrypted copy and delta files must not outlive a failed initialization
+	initialized := false
+	defer func() {
+		if encrypted != nil && !initialized {
+			_ = encrypted.Discard()
+		}
+		if incremental != nil && !initialized {
+			incremental.cleanup()
+		}
+	}()
+
+	dbStorage, err := openStorage(params, storagePath)
+	if err != nil {
+		_ = stdinH.Cleanup()
+		_ = urlH.Cleanup()
+		_ = s3H.Cleanup()
+		_ = gcsH.Cleanup()
+		_ = azureH.Cleanup()
+		_ = compressionH.Cleanup()
+		_ = pluginH.Cleanup()
 		return nil, fmt.Errorf("failed to initialize storage: %w", err)
 	}
+	if intoStorage {
+		ifExists, err := storage.ParseIfExists(params.IfExists)
+		if err != nil {
+			_ = dbStorage.Close()
+			_ = stdinH.Cleanup()
+			_ = urlH.Cleanup()
+			_ = s3H.Cleanup()
+			_ = gcsH.Cleanup()
+			_ = azureH.Cleanup()
+			_ = compressionH.Cleanup()
+			_ = pluginH.Cleanup()
+			return nil, clierror.Parse(err)
+		}
+		storage.SetIfExists(dbStorage, ifExists)
+	}
+	if err := applySettings(dbStorage, params.Settings); err != nil {
+		_ = dbStorage.Close()
+		_ = stdinH.Cleanup()
+		_ = urlH.Cleanup()
+		_ = s3H.Cleanup()
+		_ = gcsH.Cleanup()
+		_ = azureH.Cleanup()
+		_ = compressionH.Cleanup()
+		_ = pluginH.Cleanup()
+		return nil, err
+	}
+	if err := loadExtensions(dbStorage, params); err != nil {
+		_ = dbStorage.Close()
+		_ = stdinH.Cleanup()
+		_ = urlH.Cleanup()
+		_ = s3H.Cleanup()
+		_ = gcsH.Cleanup()
+		_ = azureH.Cleanup()
+		_ = compressionH.Cleanup()
+		_ = pluginH.Cleanup()
+		return nil, err
+	}
+	if err := attachDatabases(dbStorage, params); err != nil {
+		_ = dbStorage.Close()
+		_ = stdinH.Cleanup()
+		_ = urlH.Cleanup()
+		_ = s3H.Cleanup()
+		_ = gcsH.Cleanup()
+		_ = azureH.Cleanup()
+		_ = compressionH.Cleanup()
+		_ = pluginH.Cleanup()
+		return nil, err
+	}
+	storageAlias, err := attachStorage(dbStorage, params)
+	if err != nil {
+		_ = dbStorage.Close()
+		_ = stdinH.Cleanup()
+		_ = urlH.Cleanup()
+		_ = s3H.Cleanup()
+		_ = gcsH.Cleanup()
+		_ = azureH.Cleanup()
+		_ = compressionH.Cleanup()
+		_ = pluginH.Cleanup()
+		return nil, err
+	}
+	udfs, err := registerUDFs(dbStorage, params.UDFs)
+	if err != nil {
+		_ = dbStorage.Close()
+		_ = stdinH.Cleanup()
+		_ = urlH.Cleanup()
+		_ = s3H.Cleanup()
+		_ = gcsH.Cleanup()
+		_ = azureH.Cleanup()
+		_ = compressionH.Cleanup()
+		_ = pluginH.Cleanup()
+		return nil, err
+	}
+
+	bar := progress.NewBar(barWriter, 0, progress.Description(progress.Import, "loading data..."))
 
-	// Use stderr for progress bar to keep stdout clean for pipelines
-	// Use io.Discard if quiet mode is enabled
-	var barWriter io.Writer = os.Stderr
-	if params.Quiet {
-		barWriter = io.Discard
-	}
-
-	bar := progressbar.NewOptions(0,
-		progressbar.OptionSetWriter(barWriter),
-		progressbar.OptionEnableColorCodes(true),
-		progressbar.OptionShowBytes(true),
-		progressbar.OptionFullWidth(),
-		progressbar.OptionSetDescription("[cyan][1/1][reset] loading data..."),
-		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "[green]=[reset]",
-			SaucerHead:    "[green]>[reset]",
-			SaucerPadding: " ",
-			BarStart:      "[",
-			BarEnd:        "]",
-		}))
-
-	verboseLog(params.Verbose, "Creating file handler...")
-	handler, err := createFileHandler(params, bar, duckDBStorage, aliases)
+	// Without other sources, the sources read in place are all there is,
+	// as in storage-only mode
+	var handler filehandler.FileHandler
+	if len(params.FileInputs) > 0 {
+		verboseLog(params.Verbose, "Creating file handler...")
+		handler, err = createFileHandler(params, bar, dbStorage, aliases)
+	}
 	if err != nil {
 		_ = stdinH.Cleanup()
 		_ = urlH.Cleanup()
@@ -313,7 +601,9 @@ func New(params Params) (DataQL, error) {
 		_ = gcsH.Cleanup()
 		_ = azureH.Cleanup()
 		_ = compressionH.Cleanup()
-		return nil, fmt.Errorf("failed to create file handler: %w", err)
+		_ = pluginH.Cleanup()
+		closeUDFs(udfs)
+		return nil, sourceError(fmt.Errorf("failed to create file handler: %w", err), sourceNames, params.FileInputs)
 	}
 
 	// Parse query parameters if provided
@@ -328,69 +618,121 @@ func New(params Params) (DataQL, error) {
 			_ = gcsH.Cleanup()
 			_ = azureH.Cleanup()
 			_ = compressionH.Cleanup()
-			return nil, fmt.Errorf("failed to parse query parameters: %w", err)
+			_ = pluginH.Cleanup()
+			closeUDFs(udfs)
+			return nil, clierror.Parse(fmt.Errorf("failed to parse query parameters: %w", err))
 		}
 		verboseLog(params.Verbose, "Parsed query parameters: %v", queryParams)
 	}
 
+	audit, err := openAuditLog(params.AuditLog)
+	if err != nil {
+		_ = stdinH.Cleanup()
+		_ = urlH.Cleanup()
+		_ = s3H.Cleanup()
+		_ = gcsH.Cleanup()
+		_ = azureH.Cleanup()
+		_ = compressionH.Cleanup()
+		_ = pluginH.Cleanup()
+		closeUDFs(udfs)
+		return nil, err
+	}
+
 	verboseLog(params.Verbose, "DataQL initialization complete")
+	initialized = true
 	return &dataQL{
 		params:             params,
 		bar:                bar,
+		out:                stdout{},
 		fileHandler:        handler,
-		storage:            duckDBStorage,
+		storage:            dbStorage,
 		urlHandler:         urlH,
 		s3Handler:          s3H,
 		gcsHandler:         gcsH,
 		azureHandler:       azureH,
 		stdinHandler:       stdinH,
 		compressionHandler: compressionH,
+		pluginHandler:      pluginH,
 		cacheHandler:       cacheH,
+		incremental:        incremental,
+		remote:             remote,
+		storageAlias:       storageAlias,
+		tableAliases:       aliases,
+		udfs:               udfs,
+		encrypted:          encrypted,
 		pageSize:           defaultPageSize,
 		truncate:           params.Truncate,
 		vertical:           params.Vertical,
+		outputMode:         params.OutputMode,
+		autocommit:         !params.NoAutoCommit,
 		queryParams:        queryParams,
 		cacheHit:           cacheHit,
 		cacheKey:           cacheKey,
+		audit:              audit,
+		auditSources:       auditSources,
+		sourceNames:        sourceNames,
+		history:            openHistory(params.History),
+		historySources:     auditSources,
+		historyDir:         workingDir(),
+		outputTZ:           outputTZ,
 	}, nil
 }
 
 // NewStorageOnly creates a DataQL instance that only uses an existing DuckDB storage file
 // This mode allows querying previously saved data without specifying input files
 func NewStorageOnly(params Params) (DataQL, error) {
+	params = applyPlain(params)
 	verboseLog(params.Verbose, "Starting DataQL initialization in storage-only mode...")
 
-	// Verify the DuckDB file exists
-	if _, err := os.Stat(params.DataSourceName); os.IsNotExist(err) {
+	outputTZ, err := timezone.Load(params.OutputTZ)
+	if err != nil {
+		return nil, clierror.Parse(err)
+	}
+
+	// Verify the DuckDB file exists; with only --attach files the session
+	// database is in memory
+	if _, err := os.Stat(params.DataSourceName); params.DataSourceName != "" && os.IsNotExist(err) {
 		return nil, fmt.Errorf("storage file does not exist: %s (use --file to create a new database)", params.DataSourceName)
 	}
 
-	verboseLog(params.Verbose, "Opening existing DuckDB storage: %s", params.DataSourceName)
-	duckDBStorage, err := duckdb.NewDuckDBStorage(params.DataSourceName)
+	storagePath, encrypted, err := openEncrypted(params.DataSourceName, params.Encrypt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+		return nil, err
 	}
+	// The decrypted copy must not outlive a failed initialization
+	initialized := false
+	defer func() {
+		if encrypted != nil && !initialized {
+			_ = encrypted.Discard()
+		}
+	}()
 
-	// Use stderr for progress bar to keep stdout clean for pipelines
-	// Use io.Discard if quiet mode is enabled
-	var barWriter io.Writer = os.Stderr
-	if params.Quiet {
-		barWriter = io.Discard
+	verboseLog(params.Verbose, "Opening existing storage: %s", params.DataSourceName)
+	dbStorage, err := openStorage(params, storagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	if err := applySettings(dbStorage, params.Settings); err != nil {
+		_ = dbStorage.Close()
+		return nil, err
+	}
+	if err := loadExtensions(dbStorage, params); err != nil {
+		_ = dbStorage.Close()
+		return nil, err
+	}
+	if err := attachDatabases(dbStorage, params); err != nil {
+		_ = dbStorage.Close()
+		return nil, err
+	}
+	udfs, err := registerUDFs(dbStorage, params.UDFs)
+	if err != nil {
+		_ = dbStorage.Close()
+		return nil, err
 	}
 
-	bar := progressbar.NewOptions(0,
-		progressbar.OptionSetWriter(barWriter),
-		progressbar.OptionEnableColorCodes(true),
-		progressbar.OptionShowBytes(true),
-		progressbar.OptionFullWidth(),
-		progressbar.OptionSetDescription("[cyan][storage][reset] querying existing data..."),
-		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "[green]=[reset]",
-			SaucerHead:    "[green]>[reset]",
-			SaucerPadding: " ",
-			BarStart:      "[",
-			BarEnd:        "]",
-		}))
+	// Progress goes to stderr, and only on terminals, to keep stdout clean
+	// for pipelines
+	bar := progress.NewBar(progress.Output(params.Quiet), 0, "[cyan][storage][reset] querying existing data...")
 
 	// Parse query parameters if provided
 	var queryParams map[string]string
@@ -398,30 +740,82 @@ func NewStorageOnly(params Params) (DataQL, error) {
 		var err error
 		queryParams, err = ParseQueryParams(params.QueryParams)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse query parameters: %w", err)
+			closeUDFs(udfs)
+			return nil, clierror.Parse(fmt.Errorf("failed to parse query parameters: %w", err))
 		}
 		verboseLog(params.Verbose, "Parsed query parameters: %v", queryParams)
 	}
 
+	audit, err := openAuditLog(params.AuditLog)
+	if err != nil {
+		closeUDFs(udfs)
+		return nil, err
+	}
+
 	verboseLog(params.Verbose, "DataQL storage-only initialization complete")
+	initialized = true
 	return &dataQL{
-		params:      params,
-		bar:         bar,
-		storage:     duckDBStorage,
-		pageSize:    defaultPageSize,
-		truncate:    params.Truncate,
-		vertical:    params.Vertical,
-		queryParams: queryParams,
+		params:       params,
+		bar:          bar,
+		out:          stdout{},
+		storage:      dbStorage,
+		udfs:         udfs,
+		encrypted:    encrypted,
+		pageSize:     defaultPageSize,
+		truncate:     params.Truncate,
+		vertical:     params.Vertical,
+		outputMode:   params.OutputMode,
+		autocommit:   !params.NoAutoCommit,
+		queryParams:  queryParams,
+		audit:        audit,
+		auditSources: []string{params.DataSourceName},
+		history:      openHistory(params.History),
+		historyDir:   workingDir(),
+		outputTZ:     outputTZ,
 	}, nil
 }
 
-// createFileHandler creates the appropriate file handler based on file format
+// createFileHandler creates the appropriate file handler based on file format,
+// naming columns as --column-names, --column-replace and --column-dedupe say
 func createFileHandler(params Params, bar *progressbar.ProgressBar, storage storage.Storage, aliases map[string]string) (filehandler.FileHandler, error) {
+	naming, err := filehandler.ParseColumnNaming(params.ColumnNames, params.ColumnReplace, params.ColumnDedupe)
+	if err != nil {
+		return nil, err
+	}
+
+	handler, err := newFileHandler(params, bar, storage, aliases)
+	if err != nil {
+		return nil, err
+	}
+	filehandler.SetColumnNaming(handler, naming)
+	filehandler.SetTLS(handler, tlsOptions(params))
+	return handler, nil
+}
+
+// tlsOptions returns the TLS options of --ca-cert and --insecure-skip-verify
+func tlsOptions(params Params) tlsconfig.Options {
+	return tlsconfig.Options{CACert: params.CACert, InsecureSkipVerify: params.InsecureTLS}
+}
+
+// newFileHandler creates the handler of the format of the file inputs
+func newFileHandler(params Params, bar *progressbar.ProgressBar, storage storage.Storage, aliases map[string]string) (filehandler.FileHandler, error) {
 	// Detect format from file extensions
 	format, err := filehandler.DetectFormatFromFiles(params.FileInputs)
 	if err != nil {
 		return nil, err
 	}
+	if isDatabaseFormat(format) || format == filehandler.FormatMQ {
+		if len(params.FileInputs) > 1 {
+			// Each connection imports its own table
+			return newMixedHandler(params, bar, storage, aliases)
+		}
+		if alias := aliases[params.FileInputs[0]]; alias != "" {
+			params.Collection = alias
+		}
+		if params.FileInputs, err = connectionURLs(params.FileInputs); err != nil {
+			return nil, err
+		}
+	}
 
 	switch format {
 	case filehandler.FormatCSV:
@@ -432,10 +826,10 @@ func createFileHandler(params Params, bar *progressbar.ProgressBar, storage stor
 		return csvHandler.NewCsvHandlerWithAliases(params.FileInputs, delimiter, bar, storage, params.Lines, params.Collection, aliases), nil
 
 	case filehandler.FormatJSON:
-		return jsonHandler.NewJsonHandlerWithAliases(params.FileInputs, bar, storage, params.Lines, params.Collection, aliases), nil
+		return jsonHandler.NewJsonHandlerWithNested(params.FileInputs, bar, storage, params.Lines, params.Collection, aliases, filehandler.Nested(params.JSONNested)), nil
 
 	case filehandler.FormatJSONL:
-		return jsonlHandler.NewJsonlHandlerWithAliases(params.FileInputs, bar, storage, params.Lines, params.Collection, aliases), nil
+		return jsonlHandler.NewJsonlHandlerWithNested(params.FileInputs, bar, storage, params.Lines, params.Collection, aliases, filehandler.Nested(params.JSONNested)), nil
 
 	case filehandler.FormatXML:
 		return xmlHandler.NewXmlHandlerWithAliases(params.FileInputs, bar, storage, params.Lines, params.Collection, aliases), nil
@@ -498,16 +892,53 @@ func createFileHandler(params Params, bar *progressbar.ProgressBar, storage stor
 		return mqHandler.NewMQHandler(params.FileInputs[0], bar, storage, params.Lines, params.Collection)
 
 	case filehandler.FormatMixed:
+		return newMixedHandler(params, bar, storage, aliases)
+
+	default:
+		return nil, fmt.Errorf("unsupported file format: %s", format)
+	}
+}
+
+// newMixedHandler creates the handler of sources of several formats: files
+// are imported by the handler of their format, and each database or message
+// queue source by its own, into the table named after its alias
+func newMixedHandler(params Params, bar *progressbar.ProgressBar, storage storage.Storage, aliases map[string]string) (filehandler.FileHandler, error) {
+	var files []string
+	var handlers []filehandler.FileHandler
+	for _, source := range params.FileInputs {
+		format, err := filehandler.DetectFormat(source)
+		if err != nil {
+			_ = compositeHandler.NewCompositeHandlerWithHandlers(handlers...).Close()
+			return nil, err
+		}
+		if !isDatabaseFormat(format) && format != filehandler.FormatMQ {
+			files = append(files, source)
+			continue
+		}
+		single := params
+		single.FileInputs = []string{source}
+		handler, err := newFileHandler(single, bar, storage, aliases)
+		if err != nil {
+			_ = compositeHandler.NewCompositeHandlerWithHandlers(handlers...).Close()
+			return nil, err
+		}
+		handlers = append(handlers, handler)
+	}
+
+	if len(files) > 0 {
 		// Mixed formats - use composite handler to process each file with its appropriate handler
 		delimiter := ','
 		if params.Delimiter != "" {
 			delimiter = rune(params.Delimiter[0])
 		}
-		return compositeHandler.NewCompositeHandlerWithAliases(params.FileInputs, delimiter, bar, storage, params.Lines, params.Collection, aliases)
-
-	default:
-		return nil, fmt.Errorf("unsupported file format: %s", format)
+		handler, err := compositeHandler.NewCompositeHandlerWithNested(files, delimiter, bar, storage, params.Lines, params.Collection, aliases, filehandler.Nested(params.JSONNested))
+		if err != nil {
+			_ = compositeHandler.NewCompositeHandlerWithHandlers(handlers...).Close()
+			return nil, err
+		}
+		handlers = append(handlers, handler)
 	}
+	return compositeHandler.NewCompositeHandlerWithHandlers(handlers...), nil
 }
 
 // Run imports file content and runs the command
@@ -516,15 +947,106 @@ func (d *dataQL) Run() error {
 		_ = bar.Clear()
 	}(d.bar)
 
+	if err := d.importData(); err != nil {
+		return err
+	}
+
+	defer func(fileHandler filehandler.FileHandler) {
+		if fileHandler != nil {
+			_ = fileHandler.Close()
+		}
+	}(d.fileHandler)
+
+	// Show table schema unless --no-schema is set or a query is specified (non-REPL mode)
+	// Schema is useful in REPL mode but adds noise when running one-off queries
+	if !d.params.NoSchema && d.params.Query == "" {
+		verboseLog(d.params.Verbose, "Listing available tables...")
+		rows, err := d.storage.ShowTables()
+		if err != nil {
+			return fmt.Errorf("failed to list tables: %w", err)
+		}
+
+		if _, err := d.printResult(rows); err != nil {
+			return fmt.Errorf("failed to print tables: %w", err)
+		}
+	}
+
+	return d.execute()
+}
+
+// Import loads the data sources into storage without running any query.
+// It is a no-op once the data was imported, so long-lived callers can import
+// once and then run any number of statements with Exec.
+func (d *dataQL) Import() error {
+	defer func(bar *progressbar.ProgressBar) {
+		_ = bar.Clear()
+	}(d.bar)
+
+	return d.importData()
+}
+
+// Exec runs a single SQL statement or REPL command against the imported data
+// and prints its result. With autocommit off, the transaction opened for the
+// statement is finished with it; a transaction the caller began with BEGIN
+// stays open across calls until its COMMIT or ROLLBACK.
+func (d *dataQL) Exec(query string) error {
+	if err := d.importData(); err != nil {
+		return err
+	}
+
+	explicit := d.inTransaction() || transactionCommand(ApplyQueryParams(query, d.queryParams)) == txBegin
+	err := d.executeQuery(query)
+	if explicit {
+		return err
+	}
+	return d.finishTransaction(err)
+}
+
+// importData imports the file content into storage unless it was already
+// imported or cached
+func (d *dataQL) importData() error {
+	if d.imported {
+		return nil
+	}
+	if d.fileHandler == nil {
+		// Storage-only mode, or only sources read in place: nothing to import
+		if err := d.readInPlace(); err != nil {
+			return err
+		}
+		d.imported = true
+		d.loadMacros()
+		if d.params.NoExternalAccess {
+			return d.disableExternalAccess()
+		}
+		return nil
+	}
+
 	// Skip import if using cached data
 	if d.cacheHit {
 		verboseLog(d.params.Verbose, "Using cached data, skipping import...")
 	} else {
 		verboseLog(d.params.Verbose, "Starting data import...")
-		if err := d.fileHandler.Import(); err != nil {
-			return fmt.Errorf("failed to import data %w", err)
+		importFn := d.fileHandler.Import
+		files := d.params.FileInputs
+		if d.incremental != nil {
+			importFn = d.importIncremental
+			files = d.incremental.deltas
+		}
+		size := fileBytes(files)
+		start := time.Now()
+		err := importFn()
+		metrics.ObserveImport(size, time.Since(start), err)
+		if err != nil {
+			return sourceError(fmt.Errorf("failed to import data %w", err), d.sourceNames, d.params.FileInputs)
+		}
+		// Tables imported with --if-exists replace are swapped in only now
+		if err := storage.ReplaceTables(d.storage); err != nil {
+			return err
 		}
 		verboseLog(d.params.Verbose, "Data import complete. Lines imported: %d", d.fileHandler.Lines())
+		if err := d.localizeTimestamps(); err != nil {
+			return err
+		}
 
 		// Save cache metadata if caching is enabled
 		if d.cacheHandler != nil && d.cacheHandler.IsEnabled() && d.cacheKey != "" {
@@ -536,28 +1058,63 @@ func (d *dataQL) Run() error {
 			}
 		}
 	}
+	d.observeCache()
+	d.pruneCache()
 
-	defer func(fileHandler filehandler.FileHandler) {
-		if fileHandler != nil {
-			_ = fileHandler.Close()
-		}
-	}(d.fileHandler)
+	if err := d.readInPlace(); err != nil {
+		return err
+	}
+	if err := d.linkStorage(); err != nil {
+		return err
+	}
 
-	// Show table schema unless --no-schema is set or a query is specified (non-REPL mode)
-	// Schema is useful in REPL mode but adds noise when running one-off queries
-	if !d.params.NoSchema && d.params.Query == "" {
-		verboseLog(d.params.Verbose, "Listing available tables...")
-		rows, err := d.storage.ShowTables()
-		if err != nil {
-			return fmt.Errorf("failed to list tables: %w", err)
+	// Macros may read the imported tables, which DuckDB binds on creation
+	d.loadMacros()
+
+	if d.params.NoExternalAccess {
+		if err := d.disableExternalAccess(); err != nil {
+			return err
 		}
+	}
 
-		if _, err := d.printResult(rows); err != nil {
-			return fmt.Errorf("failed to print tables: %w", err)
+	d.imported = true
+	return nil
+}
+
+// fileBytes returns the total size of the files among paths, leaving out
+// database and message queue URLs
+func fileBytes(paths []string) int64 {
+	var total int64
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
 		}
 	}
+	return total
+}
 
-	return d.execute()
+// observeCache records in the metrics whether the cache held the data
+func (d *dataQL) observeCache() {
+	switch {
+	case d.cacheHandler == nil || !d.cacheHandler.IsEnabled() || d.cacheKey == "":
+	case d.cacheHit:
+		metrics.ObserveCache(metrics.CacheHit)
+	case d.incremental != nil:
+		metrics.ObserveCache(metrics.CacheAppend)
+	default:
+		metrics.ObserveCache(metrics.CacheMiss)
+	}
+}
+
+// disableExternalAccess stops SQL statements from touching files and URLs
+// (read_csv, COPY, ...) so only the imported tables can be queried. DuckDB
+// does not allow re-enabling it for the lifetime of the storage.
+func (d *dataQL) disableExternalAccess() error {
+	rows, err := d.storage.Query("SET enable_external_access = false")
+	if err != nil {
+		return fmt.Errorf("failed to disable external access: %w", err)
+	}
+	return rows.Close()
 }
 
 // RunStorageOnly executes queries on an existing DuckDB storage file without importing new data
@@ -570,7 +1127,7 @@ func (d *dataQL) RunStorageOnly() error {
 
 	// Show table schema unless --no-schema is set or a query is specified (non-REPL mode)
 	// Schema is useful in REPL mode but adds noise when running one-off queries
-	if !d.params.NoSchema && d.params.Query == "" {
+	if !d.params.NoSchema && d.params.Query == "" && d.params.DataSourceName != "" {
 		verboseLog(d.params.Verbose, "Listing available tables in storage...")
 		rows, err := d.storage.ShowTables()
 		if err != nil {
@@ -587,11 +1144,24 @@ func (d *dataQL) RunStorageOnly() error {
 
 // execute runs the execution after data import
 func (d *dataQL) execute() error {
+	d.loadConfig()
+
 	switch {
+	case d.params.Follow && d.params.Query != "":
+		return d.follow(d.params.Query)
 	case d.params.Query != "" && d.params.Export == "":
-		return d.executeQuery(d.params.Query)
+		// With autocommit off the whole query is committed only if it succeeds
+		d.resultRows = -1
+		if err := d.finishTransaction(d.executeQuery(d.params.Query)); err != nil {
+			return err
+		}
+		return d.checkEmpty()
 	case d.params.Query != "" && d.params.Export != "":
-		return d.executeQueryAndExport(d.params.Query)
+		d.resultRows = -1
+		if err := d.executeQueryAndExport(d.params.Query); err != nil {
+			return err
+		}
+		return d.checkEmpty()
 	default:
 		if err := d.initializePrompt(); err != nil {
 			return err
@@ -601,12 +1171,53 @@ func (d *dataQL) execute() error {
 	return nil
 }
 
+// ResultRows returns the number of rows returned or exported by the query of
+// Run (-1: unknown)
+func (d *dataQL) ResultRows() int64 {
+	return d.resultRows
+}
+
+// SetOutput sets where query results and command output are written
+// (default: stdout)
+func (d *dataQL) SetOutput(w io.Writer) {
+	d.out = w
+}
+
+// checkEmpty fails with --fail-on-empty when the query returned no rows. The
+// export file is written all the same.
+func (d *dataQL) checkEmpty() error {
+	if d.params.FailOnEmpty && d.resultRows == 0 {
+		return clierror.ErrEmptyResult
+	}
+	return nil
+}
+
 // Close cleans up resources
 func (d *dataQL) Close() error {
+	// Never leave changes half-applied: discard a transaction that was not committed
+	d.rollbackPending()
+
 	// Close file handler if present (not present in storage-only mode)
 	if d.fileHandler != nil {
 		_ = d.fileHandler.Close()
 	}
+	// Release the storage file so it can be opened again (and encrypted back):
+	// not every handler closes it, and closing it twice is harmless
+	if d.storage != nil {
+		_ = d.storage.Close()
+	}
+
+	if d.incremental != nil {
+		d.incremental.cleanup()
+	}
+
+	// Encrypt the storage file back once DuckDB has released it
+	var err error
+	if d.encrypted != nil {
+		if err = d.encrypted.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
 
 	// Clean up any temp files from stdin
 	if d.stdinHandler != nil {
@@ -638,7 +1249,26 @@ func (d *dataQL) Close() error {
 		_ = d.compressionHandler.Cleanup()
 	}
 
-	return nil
+	// Clean up any files converted by plugins
+	if d.pluginHandler != nil {
+		_ = d.pluginHandler.Cleanup()
+	}
+
+	closeUDFs(d.udfs)
+
+	if d.audit != nil {
+		_ = d.audit.Close()
+	}
+
+	if d.history != nil {
+		_ = d.history.Close()
+	}
+
+	if d.baseline != nil {
+		_ = d.baseline.Close()
+	}
+
+	return err
 }
 
 // getHistoryFilePath returns the path to the history file
@@ -661,12 +1291,24 @@ func getHistoryFilePath() string {
 }
 
 func (d *dataQL) initializePrompt() error {
+	// Apply the user's preferred settings before the first prompt
+	d.runStartupFile()
+
+	// Piped input (scripts) runs without prompt, stops on errors and reports failure
+	if !isInteractive() {
+		return d.runScript(os.Stdin)
+	}
+
 	// Create SQL completer with autocomplete support
-	completer := repl.NewSQLCompleter(d.storage)
+	completer := repl.NewSQLCompleter(d.storage, d.engine())
 	if err := completer.RefreshSchema(); err != nil {
 		// Non-fatal: continue without autocomplete if schema refresh fails
 		fmt.Fprintf(os.Stderr, "Warning: autocomplete disabled (%v)\n", err)
 	}
+	for name := range d.aliases {
+		completer.AddCommands("." + name)
+	}
+	d.completer = completer
 
 	// Create colored prompt
 	promptColor := color.New(color.FgCyan, color.Bold)
@@ -675,24 +1317,47 @@ func (d *dataQL) initializePrompt() error {
 	// Get history file path for persistent history
 	historyFile := getHistoryFilePath()
 
+	// Fuzzy incremental reverse search (Ctrl-R) over the history file
+	historySearch := repl.NewHistorySearch(historyFile)
+
 	l, err := readline.NewEx(&readline.Config{
-		Prompt:            cliPrompt,
-		InterruptPrompt:   cliInterruptPrompt,
-		EOFPrompt:         cliEOFPrompt,
-		AutoComplete:      completer,
-		HistoryFile:       historyFile,
-		HistorySearchFold: true,
-		HistoryLimit:      1000,
+		Prompt:              cliPrompt,
+		InterruptPrompt:     cliInterruptPrompt,
+		EOFPrompt:           cliEOFPrompt,
+		AutoComplete:        completer,
+		HistoryFile:         historyFile,
+		HistorySearchFold:   true,
+		HistoryLimit:        1000,
+		Listener:            historySearch,
+		FuncFilterInputRune: historySearch.FilterInputRune,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to initialize cli: %w", err)
 	}
+	historySearch.Attach(l, cliPrompt)
+
+	// Queries printing many rows to the terminal are confirmed first
+	if readline.IsTerminal(int(os.Stdout.Fd())) {
+		d.confirm = func(prompt string) (string, error) {
+			l.SetPrompt(prompt)
+			l.HistoryDisable()
+			defer func() {
+				l.SetPrompt(cliPrompt)
+				l.HistoryEnable()
+			}()
+			return l.Readline()
+		}
+		defer func() {
+			d.confirm = nil
+		}()
+	}
 
 	defer func(l *readline.Instance) {
 		_ = l.Close()
 	}(l)
 
-	l.CaptureExitSignal()
+	stopSignals := d.captureSignals(l)
+	defer stopSignals()
 
 	for {
 		line, err := l.Readline()
@@ -728,14 +1393,48 @@ func (d *dataQL) executeQueryAndExport(line string) error {
 		_ = bar.Finish()
 	}(d.bar)
 
+	startTime := time.Now()
+
 	// Apply query parameters if provided
 	query := ApplyQueryParams(line, d.queryParams)
 
+	count, err := d.exportQuery(query)
+	if err != nil {
+		metrics.ObserveExport(time.Since(startTime), err)
+		d.recordQuery(query, 0, startTime, err)
+		return err
+	}
+	metrics.ObserveExport(time.Since(startTime), nil)
+
+	d.resultRows = count
+	if !d.params.Plain {
+		fmt.Fprintf(d.out, "[%s] file successfully exported (%d rows)\n", d.params.Export, count)
+	}
+	d.recordQuery(query, count, startTime, nil)
+
+	return nil
+}
+
+// exportQuery writes the result of query to the export path, masking the
+// columns of --mask, and returns the number of rows written
+func (d *dataQL) exportQuery(query string) (int64, error) {
+	if err := d.rejectWrites(query); err != nil {
+		return 0, err
+	}
+
+	rules, err := mask.ParseRules(d.params.Mask)
+	if err != nil {
+		return 0, err
+	}
+	if query, err = mask.Wrap(query, rules, mask.NewOptions(d.params.MaskSalt)); err != nil {
+		return 0, err
+	}
+
 	rows, err := d.storage.Query(query)
 	if err != nil {
 		// Enhance error with user-friendly hints
 		enhancedErr := queryerror.EnhanceError(err)
-		return fmt.Errorf("failed to execute query: %w", enhancedErr)
+		return 0, clierror.Query(fmt.Errorf("failed to execute query: %w", enhancedErr))
 	}
 	defer func(rows *sql.Rows) {
 		_ = rows.Close()
@@ -743,18 +1442,49 @@ func (d *dataQL) executeQueryAndExport(line string) error {
 
 	export, err := exportdata.NewExport(d.params.Type, rows, d.params.Export, d.bar)
 	if err != nil {
-		return fmt.Errorf("failed to export: %w", err)
+		return 0, clierror.Export(fmt.Errorf("failed to export: %w", err))
 	}
+	exportdata.SetDecimalAsString(export, d.params.DecimalAsString)
+	exportdata.SetTimeZone(export, d.outputTZ)
 
-	if err := export.Export(); err != nil {
-		return fmt.Errorf("failed to export data: %w", err)
+	var sidecar *lineage.Lineage
+	if d.params.Lineage {
+		l, err := d.lineage(query)
+		if err != nil {
+			return 0, err
+		}
+		if !exportdata.SetLineage(export, l) {
+			sidecar = l
+		}
 	}
 
-	_ = d.bar.Clear()
+	count, err := export.Export()
+	if err != nil {
+		return 0, clierror.Export(fmt.Errorf("failed to export data: %w", err))
+	}
+
+	if sidecar != nil {
+		if err := sidecar.WriteSidecar(d.params.Export); err != nil {
+			return 0, clierror.Export(err)
+		}
+	}
 
-	fmt.Printf("[%s] file successfully exported\n", d.params.Export)
+	_ = d.bar.Clear()
+	return count, nil
+}
 
-	return nil
+// lineage describes the export of query: the files it read, or the storage
+// file in storage-only mode
+func (d *dataQL) lineage(query string) (*lineage.Lineage, error) {
+	files, names := d.params.FileInputs, d.sourceNames
+	for _, source := range d.remote {
+		files = append(files[:len(files):len(files)], source.URL)
+		names = append(names[:len(names):len(names)], source.Name)
+	}
+	if len(files) == 0 && d.params.DataSourceName != "" {
+		files = []string{d.params.DataSourceName}
+	}
+	return lineage.New(names, files, query, Version)
 }
 
 // handleREPLCommand handles special REPL commands (aliases)
@@ -766,6 +1496,8 @@ func (d *dataQL) handleREPLCommand(line string) (bool, error) {
 	if len(parts) == 0 {
 		return true, nil // Empty line, no action needed
 	}
+	// rawParts keeps the original casing for arguments such as file paths
+	rawParts := strings.Fields(strings.TrimSpace(line))
 
 	switch parts[0] {
 	case "\\q", ".quit", ".exit":
@@ -792,17 +1524,27 @@ func (d *dataQL) handleREPLCommand(line string) (bool, error) {
 		tableName := parts[1]
 		return true, d.describeTable(tableName)
 
+	case "\\dt+":
+		if len(parts) < 2 {
+			return true, fmt.Errorf("usage: \\dt+ <table_name> or .describe <table_name>")
+		}
+		return true, d.describeColumns(parts[1])
+
 	case ".clear":
-		fmt.Print("\033[H\033[2J")
+		fmt.Fprint(d.out, "\033[H\033[2J")
+		return true, nil
+
+	case ".aliases":
+		d.printAliases()
 		return true, nil
 
 	case ".version":
-		fmt.Printf("dataql version %s\n", Version)
+		fmt.Fprintf(d.out, "dataql version %s\n", Version)
 		return true, nil
 
 	case ".pagesize":
 		if len(parts) < 2 {
-			fmt.Printf("Current page size: %d\n", d.pageSize)
+			fmt.Fprintf(d.out, "Current page size: %d\n", d.pageSize)
 			return true, nil
 		}
 		size, err := strconv.Atoi(parts[1])
@@ -810,7 +1552,7 @@ func (d *dataQL) handleREPLCommand(line string) (bool, error) {
 			return true, fmt.Errorf("invalid page size: %s (must be a positive integer)", parts[1])
 		}
 		d.pageSize = size
-		fmt.Printf("Page size set to %d\n", size)
+		fmt.Fprintf(d.out, "Page size set to %d\n", size)
 		return true, nil
 
 	case ".paging":
@@ -819,42 +1561,129 @@ func (d *dataQL) handleREPLCommand(line string) (bool, error) {
 			if d.paging {
 				status = "on"
 			}
-			fmt.Printf("Paging is %s (page size: %d)\n", status, d.pageSize)
+			fmt.Fprintf(d.out, "Paging is %s (page size: %d)\n", status, d.pageSize)
 			return true, nil
 		}
 		switch strings.ToLower(parts[1]) {
 		case "on", "true", "1":
 			d.paging = true
-			fmt.Println("Paging enabled")
+			fmt.Fprintln(d.out, "Paging enabled")
 		case "off", "false", "0":
 			d.paging = false
-			fmt.Println("Paging disabled")
+			fmt.Fprintln(d.out, "Paging disabled")
 		default:
 			return true, fmt.Errorf("invalid paging value: %s (use on/off)", parts[1])
 		}
 		return true, nil
 
+	case ".pager":
+		if len(rawParts) < 2 {
+			if pager := d.pagerCommand(); pager != nil {
+				fmt.Fprintf(d.out, "Pager: %s\n", strings.Join(pager, " "))
+			} else {
+				fmt.Fprintln(d.out, "Pager: none (using built-in paging)")
+			}
+			return true, nil
+		}
+		d.pager = strings.Join(rawParts[1:], " ")
+		if strings.EqualFold(d.pager, pagerOff) {
+			fmt.Fprintln(d.out, "External pager disabled")
+		} else {
+			fmt.Fprintf(d.out, "Pager set to %s\n", d.pager)
+		}
+		return true, nil
+
 	case ".timing":
 		if len(parts) < 2 {
 			status := "off"
 			if d.showTiming {
 				status = "on"
 			}
-			fmt.Printf("Timing is %s\n", status)
+			fmt.Fprintf(d.out, "Timing is %s\n", status)
 			return true, nil
 		}
 		switch strings.ToLower(parts[1]) {
 		case "on", "true", "1":
 			d.showTiming = true
-			fmt.Println("Timing enabled")
+			fmt.Fprintln(d.out, "Timing enabled")
 		case "off", "false", "0":
 			d.showTiming = false
-			fmt.Println("Timing disabled")
+			fmt.Fprintln(d.out, "Timing disabled")
 		default:
 			return true, fmt.Errorf("invalid timing value: %s (use on/off)", parts[1])
 		}
 		return true, nil
 
+	case ".autocommit":
+		if len(parts) < 2 {
+			status := "on"
+			if !d.autocommit {
+				status = "off"
+			}
+			if d.inTransaction() {
+				fmt.Fprintf(d.out, "Autocommit is %s (transaction open)\n", status)
+			} else {
+				fmt.Fprintf(d.out, "Autocommit is %s\n", status)
+			}
+			return true, nil
+		}
+		switch parts[1] {
+		case "on", "true", "1":
+			if err := d.setAutocommit(true); err != nil {
+				return true, err
+			}
+			fmt.Fprintln(d.out, "Autocommit enabled")
+		case "off", "false", "0":
+			if err := d.setAutocommit(false); err != nil {
+				return true, err
+			}
+			fmt.Fprintln(d.out, "Autocommit disabled: run COMMIT to save changes or ROLLBACK to discard them")
+		default:
+			return true, fmt.Errorf("invalid autocommit value: %s (use on/off)", parts[1])
+		}
+		return true, nil
+
+	case ".mode":
+		if len(parts) < 2 {
+			fmt.Fprintf(d.out, "Current mode: %s (available: %s)\n", d.currentOutputMode(), strings.Join(outputModes, ", "))
+			return true, nil
+		}
+		if err := d.setOutputMode(parts[1]); err != nil {
+			return true, err
+		}
+		fmt.Fprintf(d.out, "Output mode set to %s\n", d.currentOutputMode())
+		return true, nil
+
+	case "\\watch", ".watch":
+		interval, count, err := parseWatchArgs(parts[1:])
+		if err != nil {
+			return true, err
+		}
+		return true, d.watchQuery(interval, count)
+
+	case ".diff":
+		tableA, tableB, key, err := parseDiffArgs(parts[1:])
+		if err != nil {
+			return true, err
+		}
+		return true, d.diffTables(tableA, tableB, key)
+
+	case ".connect":
+		if len(rawParts) < 2 {
+			return true, fmt.Errorf("usage: .connect <database_url> [alias]")
+		}
+		alias := ""
+		if len(rawParts) > 2 {
+			alias = rawParts[2]
+		}
+		return true, d.connectSource(rawParts[1], alias)
+
+	case ".save":
+		if len(rawParts) < 2 {
+			return true, fmt.Errorf("usage: .save <file.duckdb>")
+		}
+		return true, d.saveSession(rawParts[1])
+
 	case "\\c", ".count":
 		if len(parts) < 2 {
 			return true, fmt.Errorf("usage: \\c <table_name> or .count <table_name>")
@@ -862,12 +1691,26 @@ func (d *dataQL) handleREPLCommand(line string) (bool, error) {
 		tableName := parts[1]
 		return true, d.countTable(tableName)
 
+	case ".sample":
+		if len(parts) < 2 {
+			return true, fmt.Errorf("usage: .sample <table_name> [n]")
+		}
+		n := defaultSampleSize
+		if len(parts) > 2 {
+			size, err := strconv.Atoi(parts[2])
+			if err != nil || size < 1 {
+				return true, fmt.Errorf("invalid sample size: %s (must be a positive integer)", parts[2])
+			}
+			n = size
+		}
+		return true, d.sampleTable(parts[1], n)
+
 	case ".truncate":
 		if len(parts) < 2 {
 			if d.truncate > 0 {
-				fmt.Printf("Truncation is enabled at %d characters\n", d.truncate)
+				fmt.Fprintf(d.out, "Truncation is enabled at %d characters\n", d.truncate)
 			} else {
-				fmt.Println("Truncation is disabled (0)")
+				fmt.Fprintln(d.out, "Truncation is disabled (0)")
 			}
 			return true, nil
 		}
@@ -877,9 +1720,9 @@ func (d *dataQL) handleREPLCommand(line string) (bool, error) {
 		}
 		d.truncate = size
 		if size > 0 {
-			fmt.Printf("Truncation set to %d characters\n", size)
+			fmt.Fprintf(d.out, "Truncation set to %d characters\n", size)
 		} else {
-			fmt.Println("Truncation disabled")
+			fmt.Fprintln(d.out, "Truncation disabled")
 		}
 		return true, nil
 
@@ -889,16 +1732,16 @@ func (d *dataQL) handleREPLCommand(line string) (bool, error) {
 			if d.vertical {
 				status = "on"
 			}
-			fmt.Printf("Vertical display is %s\n", status)
+			fmt.Fprintf(d.out, "Vertical display is %s\n", status)
 			return true, nil
 		}
 		switch strings.ToLower(parts[1]) {
 		case "on", "true", "1":
 			d.vertical = true
-			fmt.Println("Vertical display enabled")
+			fmt.Fprintln(d.out, "Vertical display enabled")
 		case "off", "false", "0":
 			d.vertical = false
-			fmt.Println("Vertical display disabled")
+			fmt.Fprintln(d.out, "Vertical display disabled")
 		default:
 			return true, fmt.Errorf("invalid vertical value: %s (use on/off)", parts[1])
 		}
@@ -910,7 +1753,12 @@ func (d *dataQL) handleREPLCommand(line string) (bool, error) {
 			return true, d.DescribeAll()
 		}
 		tableName := parts[1]
-		return true, d.describeTableStats(tableName)
+		return true, d.describeColumns(tableName)
+	}
+
+	// User-defined aliases from the config file ([aliases] section)
+	if query, ok := d.lookupAlias(parts[0]); ok {
+		return true, d.runAlias(parts[0], query, rawParts[1:])
 	}
 
 	return false, nil // Not a REPL command, should be executed as SQL
@@ -923,28 +1771,42 @@ DataQL REPL Commands:
   \d, .tables          List all tables
   \dt <table>, .schema <table>  Show table schema
   \ds [table], .describe [table]  Show exploratory statistics
+  \dt+ <table>         Show per-column profile (nulls, distinct, min/max, samples)
   \c <table>, .count <table>    Count rows in table
+  .sample <table> [n]  Show n random rows (default: 10)
   \q, .quit, .exit     Exit the REPL
   \h, .help, \?        Show this help message
   .clear               Clear the screen
   .version             Show version
+  .aliases             List user-defined command aliases (config [aliases] section)
   .paging [on|off]     Enable/disable result pagination
   .pagesize [n]        Set/show page size (default: 25)
+  .pager [cmd|off]     Set/show the pager used when paging is on (default: $PAGER or less -S)
   .timing [on|off]     Enable/disable query timing display
   .truncate [n]        Truncate columns at n chars (0 to disable)
   .vertical [on|off], \G  Toggle vertical display (like MySQL \G)
+  .autocommit [on|off] Enable/disable autocommit (off: statements run in a transaction until COMMIT/ROLLBACK)
+  .mode [mode]         Set result format (table, json, jsonl, csv, markdown, vertical)
+  .save <file>         Save all loaded tables to a DuckDB file
+  .diff <a> <b> [--key col]  Compare two tables (added/removed/changed rows)
+  .connect <url> [alias]  Import a database table (postgres://, mysql://, duckdb://, ...)
+  \watch [sec] [n]     Re-run the previous query every sec seconds (Ctrl-C to stop)
+
+Transactions:
+  BEGIN; ... COMMIT;   Apply several statements atomically (ROLLBACK to discard)
 
 SQL Examples:
   SELECT * FROM <table>
   SELECT * FROM <table> WHERE <column> = '<value>'
   SELECT * FROM <table> ORDER BY <column> DESC LIMIT 10
 `
-	fmt.Println(helpText)
+	fmt.Fprintln(d.out, helpText)
 }
 
 // describeTable shows the schema of a table
 func (d *dataQL) describeTable(tableName string) error {
-	// Use DuckDB's information_schema to get column information
+	// Use DuckDB's information_schema to get column information, or the
+	// table_info pragma of SQLite
 	query := fmt.Sprintf(`SELECT
 		column_name AS name,
 		data_type AS type,
@@ -953,6 +1815,9 @@ func (d *dataQL) describeTable(tableName string) error {
 		FROM information_schema.columns
 		WHERE table_schema = 'main' AND table_name = '%s'
 		ORDER BY ordinal_position`, tableName)
+	if d.engine() == storage.EngineSQLite {
+		query = fmt.Sprintf(`SELECT name, type, "notnull", dflt_value FROM pragma_table_info('%s')`, strings.ReplaceAll(tableName, "'", "''"))
+	}
 	rows, err := d.storage.Query(query)
 	if err != nil {
 		return fmt.Errorf("failed to describe table: %w", err)
@@ -963,6 +1828,57 @@ func (d *dataQL) describeTable(tableName string) error {
 	return err
 }
 
+// saveSession persists all loaded tables into a DuckDB file that can be
+// reopened later with storage-only mode (dataql run -s <file>)
+func (d *dataQL) saveSession(path string) error {
+	snapshot, ok := d.storage.(storage.SnapshotStorage)
+	if !ok {
+		return fmt.Errorf("current storage does not support saving sessions")
+	}
+
+	if err := snapshot.SaveTo(path); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	fmt.Fprintf(d.out, "Session saved to %s (reopen with: dataql run -s %s)\n", path, path)
+	return nil
+}
+
+// refreshCompleter reloads table and column names used by REPL autocomplete
+func (d *dataQL) refreshCompleter() {
+	if d.completer == nil {
+		return
+	}
+	if err := d.completer.RefreshSchema(); err != nil {
+		verboseLog(d.params.Verbose, "Warning: failed to refresh autocomplete: %v", err)
+	}
+}
+
+// isSchemaChange checks if a statement may create, drop, or alter tables
+func isSchemaChange(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "CREATE", "DROP", "ALTER", "ATTACH", "DETACH", "IMPORT":
+		return true
+	}
+	return false
+}
+
+// sampleTable shows n random rows of a table
+func (d *dataQL) sampleTable(tableName string, n int) error {
+	rows, err := d.storage.Query(storage.SampleQuery(d.engine(), tableName, n))
+	if err != nil {
+		return fmt.Errorf("failed to sample table: %w", err)
+	}
+	defer rows.Close()
+
+	_, err = d.printResult(rows)
+	return err
+}
+
 // countTable shows the row count for a table
 func (d *dataQL) countTable(tableName string) error {
 	query := fmt.Sprintf("SELECT COUNT(*) as count FROM %s", tableName)
@@ -977,7 +1893,7 @@ func (d *dataQL) countTable(tableName string) error {
 		if err := rows.Scan(&count); err != nil {
 			return fmt.Errorf("failed to read count: %w", err)
 		}
-		fmt.Printf("%s: %d rows\n", tableName, count)
+		fmt.Fprintf(d.out, "%s: %d rows\n", tableName, count)
 	}
 	return nil
 }
@@ -988,34 +1904,94 @@ func (d *dataQL) executeQuery(line string) error {
 		return err
 	}
 
+	line, run := d.guardRows(line)
+	if !run {
+		return nil
+	}
+	if err := d.runQuery(line); err != nil {
+		return err
+	}
+
+	d.lastQuery = line
+	return nil
+}
+
+// runQuery executes a SQL statement and prints its result
+func (d *dataQL) runQuery(line string) error {
 	startTime := time.Now()
 
 	// Apply query parameters if provided
 	query := ApplyQueryParams(line, d.queryParams)
 
-	rows, err := d.storage.Query(query)
+	if command := transactionCommand(query); command != "" {
+		err := d.runTransactionCommand(command)
+		d.recordQuery(query, 0, startTime, err)
+		return err
+	}
+
+	rowCount, err := d.printQuery(query)
+	d.recordQuery(query, int64(rowCount), startTime, err)
+	if err != nil {
+		return err
+	}
+
+	d.resultRows = int64(rowCount)
+	elapsed := time.Since(startTime)
+	switch {
+	case d.params.Plain:
+	case d.showTiming:
+		fmt.Fprintf(d.out, "(%d rows in %v)\n", rowCount, elapsed.Round(time.Millisecond))
+	default:
+		fmt.Fprintf(d.out, "(%d rows)\n", rowCount)
+	}
+
+	return nil
+}
+
+// printQuery executes a SQL statement in the current transaction, prints its
+// result and returns the number of rows printed
+func (d *dataQL) printQuery(query string) (int, error) {
+	if err := d.rejectWrites(query); err != nil {
+		return 0, err
+	}
+	if err := d.ensureTransaction(); err != nil {
+		return 0, err
+	}
+
+	// Ctrl-C in the REPL interrupts the query, or the printing of its rows
+	ctx, done := d.withInterrupt()
+	defer done()
+
+	rows, err := d.queryContext(ctx, query)
+	if ctx.Err() != nil {
+		return 0, errQueryCancelled
+	}
 	if err != nil {
 		// Enhance error with user-friendly hints
 		enhancedErr := queryerror.EnhanceError(err)
-		return fmt.Errorf("failed to execute query: %w", enhancedErr)
+		return 0, clierror.Query(fmt.Errorf("failed to execute query: %w", enhancedErr))
 	}
 	defer func(rows *sql.Rows) {
 		_ = rows.Close()
 	}(rows)
 
 	rowCount, err := d.printResult(rows)
+	if ctx.Err() != nil {
+		return rowCount, errQueryCancelled
+	}
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if err := rows.Err(); err != nil {
+		return rowCount, clierror.Query(fmt.Errorf("failed to read query results: %w", err))
 	}
 
-	elapsed := time.Since(startTime)
-	if d.showTiming {
-		fmt.Printf("(%d rows in %v)\n", rowCount, elapsed.Round(time.Millisecond))
-	} else {
-		fmt.Printf("(%d rows)\n", rowCount)
+	// Keep autocomplete in sync with tables created or dropped by this statement
+	if isSchemaChange(query) {
+		d.refreshCompleter()
 	}
 
-	return nil
+	return rowCount, nil
 }
 
 func (d *dataQL) printResult(rows *sql.Rows) (int, error) {
@@ -1031,17 +2007,29 @@ func (d *dataQL) printResult(rows *sql.Rows) (int, error) {
 
 	_ = d.bar.Clear()
 
-	// Vertical display mode (like MySQL \G)
-	if d.vertical {
+	switch d.currentOutputMode() {
+	case OutputModeVertical:
+		// Vertical display mode (like MySQL \G)
 		return d.printVerticalRows(rows, columns)
+	case OutputModeJSON:
+		return d.printJSONRows(rows, columns)
+	case OutputModeJSONL:
+		return d.printJSONLRows(rows, columns)
+	case OutputModeCSV:
+		return d.printCSVRows(rows, columns)
+	case OutputModeMarkdown:
+		return d.printMarkdownRows(rows, columns)
 	}
 
-	// If paging is disabled, print all results at once
-	if !d.paging {
-		return d.printAllRows(rows, columns, cols)
+	// If paging is disabled or the output is not the terminal, print all results at once
+	if !d.paging || d.out != (stdout{}) {
+		return d.printAllRows(rows, columns)
 	}
 
-	// Paging enabled: print page by page
+	// Paging enabled: use the external pager when available, otherwise print page by page
+	if pager := d.pagerCommand(); pager != nil {
+		return d.printWithPager(pager, rows, columns)
+	}
 	return d.printPaginatedRows(rows, columns, cols)
 }
 
@@ -1073,6 +2061,11 @@ func (d *dataQL) truncateValues(values []interface{}) []interface{} {
 
 // printVerticalRows prints rows in vertical format (like MySQL \G)
 func (d *dataQL) printVerticalRows(rows *sql.Rows, columns []string) (int, error) {
+	types, err := exportdata.ColumnTypes(rows)
+	if err != nil {
+		return 0, err
+	}
+
 	// Find the longest column name for alignment
 	maxColLen := 0
 	for _, col := range columns {
@@ -1089,24 +2082,19 @@ func (d *dataQL) printVerticalRows(rows *sql.Rows, columns []string) (int, error
 	for rows.Next() {
 		rowCount++
 
-		values := make([]interface{}, len(columns))
-		pointers := make([]interface{}, len(columns))
-		for i := range values {
-			pointers[i] = &values[i]
-		}
-
-		if err := rows.Scan(pointers...); err != nil {
-			return rowCount, fmt.Errorf("failed to read row: %w", err)
+		values, err := d.readRow(rows, types)
+		if err != nil {
+			return rowCount, err
 		}
 
 		// Print row separator
-		headerColor.Printf("*************************** %d. row ***************************\n", rowCount)
+		headerColor.Fprintf(d.out, "*************************** %d. row ***************************\n", rowCount)
 
 		// Print each column as key-value pair
 		for i, col := range columns {
 			val := d.truncateValue(values[i])
-			colColor.Printf("%*s: ", maxColLen, col)
-			valColor.Printf("%v\n", val)
+			colColor.Fprintf(d.out, "%*s: ", maxColLen, col)
+			valColor.Fprintf(d.out, "%v\n", val)
 		}
 	}
 
@@ -1114,35 +2102,43 @@ func (d *dataQL) printVerticalRows(rows *sql.Rows, columns []string) (int, error
 }
 
 // printAllRows prints all rows without pagination
-func (d *dataQL) printAllRows(rows *sql.Rows, columns []string, cols []interface{}) (int, error) {
-	tbl := table.New(cols...).
-		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
-		WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
-		WithWriter(os.Stdout)
+func (d *dataQL) printAllRows(rows *sql.Rows, columns []string) (int, error) {
+	return d.writeAllRows(d.out, rows, columns)
+}
+
+// writeAllRows renders all rows as a table to the given writer, streaming
+// them in chunks aligned on the first rows
+func (d *dataQL) writeAllRows(w io.Writer, rows *sql.Rows, columns []string) (int, error) {
+	types, err := exportdata.ColumnTypes(rows)
+	if err != nil {
+		return 0, err
+	}
 
+	stream := newTableStream(w, columns, d.maxResultMemory())
 	rowCount := 0
 	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		pointers := make([]interface{}, len(columns))
-		for i := range values {
-			pointers[i] = &values[i]
-		}
-
-		if err := rows.Scan(pointers...); err != nil {
-			return rowCount, fmt.Errorf("failed to read row: %w", err)
+		values, err := d.readRow(rows, types)
+		if err != nil {
+			return rowCount, err
 		}
 
 		// Apply truncation if enabled
-		tbl.AddRow(d.truncateValues(values)...)
+		if err := stream.add(d.truncateValues(values)); err != nil {
+			return rowCount, err
+		}
 		rowCount++
 	}
 
-	tbl.Print()
-	return rowCount, nil
+	return rowCount, stream.close()
 }
 
 // printPaginatedRows prints rows with pagination
 func (d *dataQL) printPaginatedRows(rows *sql.Rows, columns []string, cols []interface{}) (int, error) {
+	types, err := exportdata.ColumnTypes(rows)
+	if err != nil {
+		return 0, err
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 	rowCount := 0
 	pageNum := 1
@@ -1155,7 +2151,7 @@ func (d *dataQL) printPaginatedRows(rows *sql.Rows, columns []string, cols []int
 		tbl := table.New(cols...).
 			WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
 			WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
-			WithWriter(os.Stdout)
+			WithWriter(d.out)
 
 		// Collect rows for this page
 		pageRows := 0
@@ -1169,14 +2165,9 @@ func (d *dataQL) printPaginatedRows(rows *sql.Rows, columns []string, cols []int
 
 		// Read more rows for this page
 		for pageRows < d.pageSize && rows.Next() {
-			values := make([]interface{}, len(columns))
-			pointers := make([]interface{}, len(columns))
-			for i := range values {
-				pointers[i] = &values[i]
-			}
-
-			if err := rows.Scan(pointers...); err != nil {
-				return rowCount, fmt.Errorf("failed to read row: %w", err)
+			values, err := d.readRow(rows, types)
+			if err != nil {
+				return rowCount, err
 			}
 
 			// Apply truncation if enabled
@@ -1198,18 +2189,14 @@ func (d *dataQL) printPaginatedRows(rows *sql.Rows, columns []string, cols []int
 		// Peek ahead to see if there are more rows
 		if rows.Next() {
 			// Save this row for the next page
-			values := make([]interface{}, len(columns))
-			pointers := make([]interface{}, len(columns))
-			for i := range values {
-				pointers[i] = &values[i]
-			}
-			if err := rows.Scan(pointers...); err != nil {
-				return rowCount, fmt.Errorf("failed to read row: %w", err)
+			values, err := d.readRow(rows, types)
+			if err != nil {
+				return rowCount, err
 			}
 			pendingRow = values
 
 			// Prompt user for next page
-			fmt.Printf("\n-- Page %d (%d rows shown) -- Press Enter for more, q to quit --\n", pageNum, rowCount)
+			fmt.Fprintf(d.out, "\n-- Page %d (%d rows shown) -- Press Enter for more, q to quit --\n", pageNum, rowCount)
 			input, _ := reader.ReadString('\n')
 			input = strings.TrimSpace(strings.ToLower(input))
 			if input == "q" || input == "quit" {
@@ -1223,304 +2210,21 @@ func (d *dataQL) printPaginatedRows(rows *sql.Rows, columns []string, cols []int
 	}
 }
 
-// RunAndDescribe imports file content and shows descriptive statistics
-func (d *dataQL) RunAndDescribe() error {
-	defer func(bar *progressbar.ProgressBar) {
-		_ = bar.Clear()
-	}(d.bar)
-
-	verboseLog(d.params.Verbose, "Starting data import...")
-	if err := d.fileHandler.Import(); err != nil {
-		return fmt.Errorf("failed to import data %w", err)
-	}
-	verboseLog(d.params.Verbose, "Data import complete. Lines imported: %d", d.fileHandler.Lines())
-	defer func(fileHandler filehandler.FileHandler) {
-		_ = fileHandler.Close()
-	}(d.fileHandler)
-
-	return d.DescribeAll()
-}
-
-// DescribeAll shows descriptive statistics for all tables
-func (d *dataQL) DescribeAll() error {
-	_ = d.bar.Clear()
-
-	// Get all tables - the schemas table has columns: id, name, columns, total_columns
-	rows, err := d.storage.ShowTables()
-	if err != nil {
-		return fmt.Errorf("failed to list tables: %w", err)
-	}
-
-	var tables []string
-	for rows.Next() {
-		var id int
-		var tableName, columns string
-		var totalColumns int
-		if err := rows.Scan(&id, &tableName, &columns, &totalColumns); err != nil {
-			rows.Close()
-			return fmt.Errorf("failed to read table name: %w", err)
-		}
-		tables = append(tables, tableName)
-	}
-	rows.Close()
-
-	if len(tables) == 0 {
-		fmt.Println("No tables found.")
-		return nil
-	}
-
-	for i, tableName := range tables {
-		if i > 0 {
-			fmt.Println() // Separator between tables
-		}
-		if err := d.describeTableStats(tableName); err != nil {
-			return fmt.Errorf("failed to describe table %s: %w", tableName, err)
-		}
-	}
-
-	return nil
-}
-
-// describeTableStats shows comprehensive statistics for a table
-func (d *dataQL) describeTableStats(tableName string) error {
-	headerColor := color.New(color.FgCyan, color.Bold)
-	headerColor.Printf("=== Table: %s ===\n\n", tableName)
-
-	// Get row count
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)
-	countRows, err := d.storage.Query(countQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get row count: %w", err)
-	}
-	var rowCount int64
-	if countRows.Next() {
-		if err := countRows.Scan(&rowCount); err != nil {
-			countRows.Close()
-			return fmt.Errorf("failed to read row count: %w", err)
-		}
-	}
-	countRows.Close()
-
-	fmt.Printf("Total rows: %d\n\n", rowCount)
-
-	// Get column information with statistics
-	// Use DuckDB's SUMMARIZE command which provides comprehensive statistics
-	summarizeQuery := fmt.Sprintf("SUMMARIZE SELECT * FROM %s", tableName)
-	rows, err := d.storage.Query(summarizeQuery)
-	if err != nil {
-		// Fallback to manual statistics if SUMMARIZE is not available
-		return d.describeTableStatsManual(tableName)
-	}
-	defer rows.Close()
-
-	columns, err := rows.Columns()
-	if err != nil {
-		return fmt.Errorf("failed to get columns: %w", err)
-	}
-
-	// Print the SUMMARIZE results in a table format
-	cols := make([]interface{}, 0)
-	for _, c := range columns {
-		cols = append(cols, c)
-	}
-
-	tbl := table.New(cols...).
-		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
-		WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
-		WithWriter(os.Stdout)
-
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		pointers := make([]interface{}, len(columns))
-		for i := range values {
-			pointers[i] = &values[i]
-		}
-
-		if err := rows.Scan(pointers...); err != nil {
-			return fmt.Errorf("failed to read row: %w", err)
-		}
-
-		tbl.AddRow(values...)
+// pruneCache keeps the cache within its TTL and size limit, sparing the
+// entry of the current session
+func (d *dataQL) pruneCache() {
+	if d.cacheHandler == nil || !d.cacheHandler.IsEnabled() || !d.cacheHandler.HasLimits() {
+		return
 	}
 
-	tbl.Print()
-	return nil
-}
-
-// describeTableStatsManual provides manual statistics when SUMMARIZE is not available
-func (d *dataQL) describeTableStatsManual(tableName string) error {
-	// Get column information from information_schema
-	schemaQuery := fmt.Sprintf(`
-		SELECT column_name, data_type
-		FROM information_schema.columns
-		WHERE table_schema = 'main' AND table_name = '%s'
-		ORDER BY ordinal_position`, tableName)
-
-	schemaRows, err := d.storage.Query(schemaQuery)
+	result, err := d.cacheHandler.Prune(d.cacheKey)
 	if err != nil {
-		return fmt.Errorf("failed to get schema: %w", err)
-	}
-
-	type columnInfo struct {
-		Name     string
-		DataType string
-	}
-	var columns []columnInfo
-
-	for schemaRows.Next() {
-		var col columnInfo
-		if err := schemaRows.Scan(&col.Name, &col.DataType); err != nil {
-			schemaRows.Close()
-			return fmt.Errorf("failed to read column info: %w", err)
-		}
-		columns = append(columns, col)
-	}
-	schemaRows.Close()
-
-	// Create table for output
-	tbl := table.New("Column", "Type", "Nulls", "Unique", "Min", "Max", "Mean", "Std").
-		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
-		WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
-		WithWriter(os.Stdout)
-
-	for _, col := range columns {
-		// Get statistics for each column
-		stats := d.getColumnStats(tableName, col.Name, col.DataType)
-		tbl.AddRow(col.Name, col.DataType, stats.Nulls, stats.Unique, stats.Min, stats.Max, stats.Mean, stats.Std)
-	}
-
-	tbl.Print()
-	return nil
-}
-
-// columnStats holds statistics for a column
-type columnStats struct {
-	Nulls  interface{}
-	Unique interface{}
-	Min    interface{}
-	Max    interface{}
-	Mean   interface{}
-	Std    interface{}
-}
-
-// getColumnStats retrieves statistics for a specific column
-func (d *dataQL) getColumnStats(tableName, columnName, dataType string) *columnStats {
-	stats := &columnStats{
-		Nulls:  "-",
-		Unique: "-",
-		Min:    "-",
-		Max:    "-",
-		Mean:   "-",
-		Std:    "-",
-	}
-
-	// Escape column name for safety
-	escapedColumn := fmt.Sprintf("\"%s\"", columnName)
-
-	// Get null count
-	nullQuery := fmt.Sprintf("SELECT COUNT(*) - COUNT(%s) FROM %s", escapedColumn, tableName)
-	nullRows, err := d.storage.Query(nullQuery)
-	if err == nil && nullRows.Next() {
-		var nullCount int64
-		nullRows.Scan(&nullCount)
-		stats.Nulls = nullCount
-		nullRows.Close()
-	}
-
-	// Get unique count
-	uniqueQuery := fmt.Sprintf("SELECT COUNT(DISTINCT %s) FROM %s", escapedColumn, tableName)
-	uniqueRows, err := d.storage.Query(uniqueQuery)
-	if err == nil && uniqueRows.Next() {
-		var uniqueCount int64
-		uniqueRows.Scan(&uniqueCount)
-		stats.Unique = uniqueCount
-		uniqueRows.Close()
-	}
-
-	// Check if numeric type for min/max/mean/std
-	isNumeric := isNumericType(dataType)
-	isDateTime := isDateTimeType(dataType)
-
-	if isNumeric || isDateTime {
-		// Get min
-		minQuery := fmt.Sprintf("SELECT MIN(%s) FROM %s", escapedColumn, tableName)
-		minRows, err := d.storage.Query(minQuery)
-		if err == nil && minRows.Next() {
-			var minVal interface{}
-			minRows.Scan(&minVal)
-			if minVal != nil {
-				stats.Min = minVal
-			}
-			minRows.Close()
-		}
-
-		// Get max
-		maxQuery := fmt.Sprintf("SELECT MAX(%s) FROM %s", escapedColumn, tableName)
-		maxRows, err := d.storage.Query(maxQuery)
-		if err == nil && maxRows.Next() {
-			var maxVal interface{}
-			maxRows.Scan(&maxVal)
-			if maxVal != nil {
-				stats.Max = maxVal
-			}
-			maxRows.Close()
-		}
-	}
-
-	if isNumeric {
-		// Get mean
-		meanQuery := fmt.Sprintf("SELECT AVG(%s) FROM %s", escapedColumn, tableName)
-		meanRows, err := d.storage.Query(meanQuery)
-		if err == nil && meanRows.Next() {
-			var meanVal interface{}
-			meanRows.Scan(&meanVal)
-			if meanVal != nil {
-				stats.Mean = fmt.Sprintf("%.2f", meanVal)
-			}
-			meanRows.Close()
-		}
-
-		// Get standard deviation
-		stdQuery := fmt.Sprintf("SELECT STDDEV(%s) FROM %s", escapedColumn, tableName)
-		stdRows, err := d.storage.Query(stdQuery)
-		if err == nil && stdRows.Next() {
-			var stdVal interface{}
-			stdRows.Scan(&stdVal)
-			if stdVal != nil {
-				stats.Std = fmt.Sprintf("%.2f", stdVal)
-			}
-			stdRows.Close()
-		}
+		verboseLog(d.params.Verbose, "Warning: failed to prune cache: %v", err)
+		return
 	}
-
-	return stats
-}
-
-// isNumericType checks if a DuckDB data type is numeric
-func isNumericType(dataType string) bool {
-	numericTypes := []string{
-		"INTEGER", "BIGINT", "SMALLINT", "TINYINT", "UBIGINT", "UINTEGER", "USMALLINT", "UTINYINT",
-		"DOUBLE", "FLOAT", "REAL", "DECIMAL", "NUMERIC", "HUGEINT", "INT", "INT4", "INT8", "INT2", "INT1",
-	}
-	upperType := strings.ToUpper(dataType)
-	for _, nt := range numericTypes {
-		if strings.Contains(upperType, nt) {
-			return true
-		}
-	}
-	return false
-}
-
-// isDateTimeType checks if a DuckDB data type is date/time
-func isDateTimeType(dataType string) bool {
-	dateTypes := []string{"DATE", "TIME", "TIMESTAMP", "DATETIME", "INTERVAL"}
-	upperType := strings.ToUpper(dataType)
-	for _, dt := range dateTypes {
-		if strings.Contains(upperType, dt) {
-			return true
-		}
+	if result.Removed() > 0 {
+		verboseLog(d.params.Verbose, "Pruned %d cache entries, freed %s", result.Removed(), cachehandler.FormatSize(result.FreedBytes))
 	}
-	return false
 }
 
 // saveCacheMetadata saves metadata about the cached data
@@ -1556,5 +2260,13 @@ func (d *dataQL) saveCacheMetadata() error {
 	rows.Close()
 
 	// Save the metadata
-	return d.cacheHandler.SaveMetadata(d.cacheKey, d.params.FileInputs, tables, totalRows)
+	if err := d.cacheHandler.SaveMetadata(d.cacheKey, d.params.FileInputs, tables, totalRows); err != nil {
+		return err
+	}
+
+	// Growing files can then be brought up to date with their appended data
+	if appendableFormat(d.params) {
+		return d.cacheHandler.RecordContents(d.cacheKey)
+	}
+	return nil
 }
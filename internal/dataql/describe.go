@@ -0,0 +1,827 @@
+package dataql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/schollz/progressbar/v3"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// RunAndDescribe imports file content and shows descriptive statistics
+func (d *dataQL) RunAndDescribe() error {
+	defer func(bar *progressbar.ProgressBar) {
+		_ = bar.Clear()
+	}(d.bar)
+
+	// importData reuses cached imports, so remote sources are not downloaded again
+	if err := d.importData(); err != nil {
+		return err
+	}
+
+	return d.DescribeAll()
+}
+
+// DescribeAll shows descriptive statistics for all tables, or writes them
+// to the export file when one is set
+func (d *dataQL) DescribeAll() error {
+	_ = d.bar.Clear()
+
+	if d.params.Export != "" {
+		return d.exportProfile()
+	}
+
+	tables, err := d.listTables()
+	if err != nil {
+		return err
+	}
+
+	if len(tables) == 0 {
+		fmt.Fprintln(d.out, "No tables found.")
+		return nil
+	}
+
+	for i, tableName := range tables {
+		if i > 0 {
+			fmt.Fprintln(d.out) // Separator between tables
+		}
+		if err := d.describeTableStats(tableName); err != nil {
+			return fmt.Errorf("failed to describe table %s: %w", tableName, err)
+		}
+	}
+
+	return nil
+}
+
+// listTables returns the names of the loaded tables
+func (d *dataQL) listTables() ([]string, error) {
+	// The schemas table has columns: id, name, columns, total_columns
+	rows, err := d.storage.ShowTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var id int
+		var tableName, columns string
+		var totalColumns int
+		if err := rows.Scan(&id, &tableName, &columns, &totalColumns); err != nil {
+			return nil, fmt.Errorf("failed to read table name: %w", err)
+		}
+		tables = append(tables, tableName)
+	}
+
+	return tables, nil
+}
+
+// DescribeOptions selects the optional analyses of dataql describe and Profile
+type DescribeOptions struct {
+	TopValues    int          // Most frequent values per column (0: none)
+	Histogram    bool         // Bucket counts of numeric and date columns
+	Bins         int          // Number of histogram buckets (0: default)
+	Outliers     bool         // Values of numeric columns beyond the IQR fences or z-score threshold
+	Completeness bool         // Empty strings per text column and rows with no values
+	Cardinality  bool         // Uniqueness of every column, key candidates and constant columns
+	Temporal     bool         // Range, records per period, gaps and formats of date columns
+	By           string       // Column to split the statistics by (empty: no groups)
+	Score        bool         // Completeness, validity, uniqueness and consistency scorecard
+	ScoreWeights ScoreWeights // Weights of the score dimensions (zero: equal weights)
+	Compare      string       // Baseline source to report schema and distribution drift against (empty: none)
+}
+
+// scoreWeights returns the configured score weights or the default ones
+func (o DescribeOptions) scoreWeights() ScoreWeights {
+	if o.ScoreWeights == (ScoreWeights{}) {
+		return DefaultScoreWeights
+	}
+	return o.ScoreWeights
+}
+
+// Profile imports the data (unless running on existing storage only) and
+// returns structured statistics for every table, with the optional analyses
+// selected by opts.
+func (d *dataQL) Profile(opts DescribeOptions) ([]TableProfile, error) {
+	defer func(bar *progressbar.ProgressBar) {
+		_ = bar.Clear()
+	}(d.bar)
+
+	if err := d.importData(); err != nil {
+		return nil, err
+	}
+
+	return d.profileTables(opts)
+}
+
+// profileTables returns the statistics of every loaded table
+func (d *dataQL) profileTables(opts DescribeOptions) ([]TableProfile, error) {
+	tables, err := d.listTables()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]TableProfile, 0, len(tables))
+	for _, tableName := range tables {
+		rowCount, columns, err := d.profileTable(tableName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to profile table %s: %w", tableName, err)
+		}
+
+		for i := range columns {
+			if opts.TopValues > 0 {
+				columns[i].TopValues, err = d.getTopValues(tableName, fmt.Sprintf("\"%s\"", columns[i].Name), opts.TopValues)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if opts.Histogram {
+				columns[i].Histogram, err = d.getHistogram(tableName, columns[i], opts.Bins)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if opts.Outliers {
+				columns[i].Outliers, err = d.getOutliers(tableName, columns[i])
+				if err != nil {
+					return nil, err
+				}
+			}
+			if opts.Completeness {
+				columns[i].Empty, err = d.getEmptyCount(tableName, columns[i])
+				if err != nil {
+					return nil, err
+				}
+			}
+			if opts.Cardinality {
+				columns[i].Cardinality = newCardinalityReport(columns[i], rowCount)
+			}
+			if opts.Temporal {
+				columns[i].Temporal, err = d.getTemporal(tableName, columns[i])
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		profile := TableProfile{Name: tableName, Rows: rowCount, RowLimit: d.rowLimit(), Columns: columns}
+		if opts.By != "" {
+			if profile.Groups, err = d.getGroupProfiles(tableName, opts.By, columns); err != nil {
+				return nil, err
+			}
+			profile.GroupBy = opts.By
+		}
+		if opts.Score {
+			if profile.Quality, err = d.getQualityScore(tableName, columns, rowCount, opts.scoreWeights()); err != nil {
+				return nil, err
+			}
+		}
+		if opts.Compare != "" {
+			baseline, err := d.openBaseline(opts.Compare)
+			if err != nil {
+				return nil, err
+			}
+			if profile.Drift, err = d.getDrift(baseline, opts.Compare, tableName, tables, columns); err != nil {
+				return nil, err
+			}
+		}
+		if opts.Completeness {
+			blankRows, err := d.countBlankRows(tableName, columns)
+			if err != nil {
+				return nil, err
+			}
+			profile.BlankRows = &blankRows
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, nil
+}
+
+// rowLimit returns the number of rows read from each source when the import
+// was limited by --lines or --sample, or 0 when the sources were read in full
+// or the tables come from existing storage
+func (d *dataQL) rowLimit() int {
+	if d.fileHandler == nil {
+		return 0
+	}
+	return d.params.Lines
+}
+
+// getTopValues returns the n most frequent non-null values of a column and
+// their percentage of all rows
+func (d *dataQL) getTopValues(tableName, escapedColumn string, n int) ([]ValueCount, error) {
+	query := fmt.Sprintf(`SELECT CAST(%s AS VARCHAR) AS value, COUNT(*) AS count,
+		CAST(COUNT(*) * 100.0 / (SELECT COUNT(*) FROM %s) AS DOUBLE) AS pct
+		FROM %s WHERE %s IS NOT NULL
+		GROUP BY 1 ORDER BY count DESC, value LIMIT %d`, escapedColumn, tableName, tableName, escapedColumn, n)
+	rows, err := d.storage.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top values: %w", err)
+	}
+	defer rows.Close()
+
+	var values []ValueCount
+	for rows.Next() {
+		var vc ValueCount
+		if err := rows.Scan(&vc.Value, &vc.Count, &vc.Pct); err != nil {
+			return nil, fmt.Errorf("failed to read top values: %w", err)
+		}
+		values = append(values, vc)
+	}
+
+	return values, nil
+}
+
+// isCategoricalType checks if a DuckDB data type holds labels rather than
+// measurements, so its most frequent values are worth listing
+func isCategoricalType(dataType string) bool {
+	upperType := strings.ToUpper(dataType)
+	return isTextType(dataType) || upperType == "BOOLEAN" || upperType == "UUID" || strings.HasPrefix(upperType, "ENUM")
+}
+
+// printTopValues prints the n most frequent values of every categorical column of a table
+func (d *dataQL) printTopValues(tableName string, n int) error {
+	columns, err := d.getTableColumns(tableName)
+	if err != nil {
+		return err
+	}
+
+	sectionColor := color.New(color.FgCyan)
+	sectionColor.Fprintf(d.out, "\nTop values:\n")
+
+	for _, col := range columns {
+		if !isCategoricalType(col.Type) {
+			continue
+		}
+		values, err := d.getTopValues(tableName, fmt.Sprintf("\"%s\"", col.Name), n)
+		if err != nil {
+			return fmt.Errorf("failed to get top values of %s: %w", col.Name, err)
+		}
+
+		fmt.Fprintf(d.out, "\n%s (%s)\n", color.YellowString(col.Name), col.Type)
+		if len(values) == 0 {
+			fmt.Fprintln(d.out, "  (all values are NULL)")
+			continue
+		}
+
+		labels := make([]string, len(values))
+		labelWidth, countWidth := 0, 0
+		for i, vc := range values {
+			labels[i] = fmt.Sprintf("%v", d.truncateValue(vc.Value))
+			if labels[i] == "" {
+				labels[i] = "(empty)"
+			}
+			if len(labels[i]) > labelWidth {
+				labelWidth = len(labels[i])
+			}
+			if w := len(fmt.Sprint(vc.Count)); w > countWidth {
+				countWidth = w
+			}
+		}
+		for i, vc := range values {
+			fmt.Fprintf(d.out, "  %-*s  %*d  %5.1f%%\n", labelWidth, labels[i], countWidth, vc.Count, vc.Pct)
+		}
+	}
+
+	return nil
+}
+
+// describeTableStats shows comprehensive statistics for a table
+func (d *dataQL) describeTableStats(tableName string) error {
+	headerColor := color.New(color.FgCyan, color.Bold)
+	headerColor.Fprintf(d.out, "=== Table: %s ===\n\n", tableName)
+
+	// Get row count
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)
+	countRows, err := d.storage.Query(countQuery)
+	if err != nil {
+		return fmt.Errorf("failed to get row count: %w", err)
+	}
+	var rowCount int64
+	if countRows.Next() {
+		if err := countRows.Scan(&rowCount); err != nil {
+			countRows.Close()
+			return fmt.Errorf("failed to read row count: %w", err)
+		}
+	}
+	countRows.Close()
+
+	if limit := d.rowLimit(); limit > 0 {
+		fmt.Fprintf(d.out, "Total rows: %d (sample: first %d rows of each source)\n\n", rowCount, limit)
+	} else {
+		fmt.Fprintf(d.out, "Total rows: %d\n\n", rowCount)
+	}
+
+	// Get column information with statistics
+	// Use DuckDB's SUMMARIZE command which provides comprehensive statistics
+	summarizeQuery := fmt.Sprintf("SUMMARIZE SELECT * FROM %s", tableName)
+	rows, err := d.storage.Query(summarizeQuery)
+	if err != nil {
+		// Fallback to manual statistics if SUMMARIZE is not available
+		return d.describeTableStatsManual(tableName)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	// Print the SUMMARIZE results in a table format
+	cols := make([]interface{}, 0)
+	for _, c := range columns {
+		cols = append(cols, c)
+	}
+
+	tbl := table.New(cols...).
+		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+		WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+		WithWriter(d.out)
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return fmt.Errorf("failed to read row: %w", err)
+		}
+
+		tbl.AddRow(values...)
+	}
+
+	tbl.Print()
+	return d.describeSections(tableName)
+}
+
+// describeSections prints the optional analyses selected by the describe flags
+func (d *dataQL) describeSections(tableName string) error {
+	opts := d.params.Describe
+	if opts.By != "" {
+		if err := d.printGroups(tableName, opts.By); err != nil {
+			return err
+		}
+	}
+	if opts.TopValues > 0 {
+		if err := d.printTopValues(tableName, opts.TopValues); err != nil {
+			return err
+		}
+	}
+	if opts.Histogram {
+		if err := d.printHistograms(tableName, opts.Bins); err != nil {
+			return err
+		}
+	}
+	if opts.Completeness {
+		if err := d.printCompleteness(tableName); err != nil {
+			return err
+		}
+	}
+	if opts.Cardinality {
+		if err := d.printCardinality(tableName); err != nil {
+			return err
+		}
+	}
+	if opts.Temporal {
+		if err := d.printTemporal(tableName); err != nil {
+			return err
+		}
+	}
+	if opts.Outliers {
+		if err := d.printOutliers(tableName); err != nil {
+			return err
+		}
+	}
+	if opts.Score {
+		if err := d.printQualityScore(tableName, opts.scoreWeights()); err != nil {
+			return err
+		}
+	}
+	if opts.Compare != "" {
+		if err := d.printDrift(tableName, opts.Compare); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// describeTableStatsManual provides manual statistics when SUMMARIZE is not available
+func (d *dataQL) describeTableStatsManual(tableName string) error {
+	schemaRows, err := d.storage.Query(storage.ColumnsQuery(d.engine(), tableName))
+	if err != nil {
+		return fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	type columnInfo struct {
+		Name     string
+		DataType string
+	}
+	var columns []columnInfo
+
+	for schemaRows.Next() {
+		var col columnInfo
+		var table string
+		if err := schemaRows.Scan(&table, &col.Name, &col.DataType); err != nil {
+			schemaRows.Close()
+			return fmt.Errorf("failed to read column info: %w", err)
+		}
+		columns = append(columns, col)
+	}
+	schemaRows.Close()
+
+	// Create table for output
+	tbl := table.New("Column", "Type", "Nulls", "Unique", "Min", "Max", "Mean", "Std").
+		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+		WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+		WithWriter(d.out)
+
+	for _, col := range columns {
+		// Get statistics for each column
+		stats := d.getColumnStats(tableName, col.Name, col.DataType)
+		tbl.AddRow(col.Name, col.DataType, stats.Nulls, stats.Unique, stats.Min, stats.Max, stats.Mean, stats.Std)
+	}
+
+	tbl.Print()
+	return d.describeSections(tableName)
+}
+
+// columnStats holds statistics for a column
+type columnStats struct {
+	Nulls  interface{}
+	Unique interface{}
+	Min    interface{}
+	Max    interface{}
+	Mean   interface{}
+	Std    interface{}
+}
+
+// getColumnStats retrieves statistics for a specific column
+func (d *dataQL) getColumnStats(tableName, columnName, dataType string) *columnStats {
+	stats := &columnStats{
+		Nulls:  "-",
+		Unique: "-",
+		Min:    "-",
+		Max:    "-",
+		Mean:   "-",
+		Std:    "-",
+	}
+
+	// Escape column name for safety
+	escapedColumn := fmt.Sprintf("\"%s\"", columnName)
+
+	// Get null count
+	nullQuery := fmt.Sprintf("SELECT COUNT(*) - COUNT(%s) FROM %s", escapedColumn, tableName)
+	nullRows, err := d.storage.Query(nullQuery)
+	if err == nil && nullRows.Next() {
+		var nullCount int64
+		nullRows.Scan(&nullCount)
+		stats.Nulls = nullCount
+		nullRows.Close()
+	}
+
+	// Get unique count
+	uniqueQuery := fmt.Sprintf("SELECT COUNT(DISTINCT %s) FROM %s", escapedColumn, tableName)
+	uniqueRows, err := d.storage.Query(uniqueQuery)
+	if err == nil && uniqueRows.Next() {
+		var uniqueCount int64
+		uniqueRows.Scan(&uniqueCount)
+		stats.Unique = uniqueCount
+		uniqueRows.Close()
+	}
+
+	// Check if numeric type for min/max/mean/std
+	isNumeric := isNumericType(dataType)
+	isDateTime := isDateTimeType(dataType)
+
+	if isNumeric || isDateTime {
+		// Get min
+		minQuery := fmt.Sprintf("SELECT MIN(%s) FROM %s", escapedColumn, tableName)
+		minRows, err := d.storage.Query(minQuery)
+		if err == nil && minRows.Next() {
+			var minVal interface{}
+			minRows.Scan(&minVal)
+			if minVal != nil {
+				stats.Min = minVal
+			}
+			minRows.Close()
+		}
+
+		// Get max
+		maxQuery := fmt.Sprintf("SELECT MAX(%s) FROM %s", escapedColumn, tableName)
+		maxRows, err := d.storage.Query(maxQuery)
+		if err == nil && maxRows.Next() {
+			var maxVal interface{}
+			maxRows.Scan(&maxVal)
+			if maxVal != nil {
+				stats.Max = maxVal
+			}
+			maxRows.Close()
+		}
+	}
+
+	if isNumeric {
+		// Get mean
+		meanQuery := fmt.Sprintf("SELECT AVG(%s) FROM %s", escapedColumn, tableName)
+		meanRows, err := d.storage.Query(meanQuery)
+		if err == nil && meanRows.Next() {
+			var meanVal interface{}
+			meanRows.Scan(&meanVal)
+			if meanVal != nil {
+				stats.Mean = fmt.Sprintf("%.2f", meanVal)
+			}
+			meanRows.Close()
+		}
+
+		// Get standard deviation
+		stdQuery := fmt.Sprintf("SELECT STDDEV(%s) FROM %s", escapedColumn, tableName)
+		stdRows, err := d.storage.Query(stdQuery)
+		if err == nil && stdRows.Next() {
+			var stdVal interface{}
+			stdRows.Scan(&stdVal)
+			if stdVal != nil {
+				stats.Std = fmt.Sprintf("%.2f", stdVal)
+			}
+			stdRows.Close()
+		}
+	}
+
+	return stats
+}
+
+// isNumericType checks if a DuckDB data type is numeric
+func isNumericType(dataType string) bool {
+	numericTypes := []string{
+		"INTEGER", "BIGINT", "SMALLINT", "TINYINT", "UBIGINT", "UINTEGER", "USMALLINT", "UTINYINT",
+		"DOUBLE", "FLOAT", "REAL", "DECIMAL", "NUMERIC", "HUGEINT", "INT", "INT4", "INT8", "INT2", "INT1",
+	}
+	upperType := strings.ToUpper(dataType)
+	for _, nt := range numericTypes {
+		if strings.Contains(upperType, nt) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDateTimeType checks if a DuckDB data type is date/time
+func isDateTimeType(dataType string) bool {
+	dateTypes := []string{"DATE", "TIME", "TIMESTAMP", "DATETIME", "INTERVAL"}
+	upperType := strings.ToUpper(dataType)
+	for _, dt := range dateTypes {
+		if strings.Contains(upperType, dt) {
+			return true
+		}
+	}
+	return false
+}
+
+// sampleValuesLimit is the number of sample values shown per column in a profile
+const sampleValuesLimit = 3
+
+// ColumnProfile holds per-column statistics used by the REPL .describe command
+// and returned as structured data by Profile
+type ColumnProfile struct {
+	Name        string             `json:"name"`
+	Type        string             `json:"type"`
+	Nulls       int64              `json:"nulls"`
+	NullPct     float64            `json:"null_pct"`
+	Empty       *int64             `json:"empty,omitempty"` // Empty strings of text columns (completeness analysis)
+	Distinct    int64              `json:"distinct"`
+	Min         interface{}        `json:"min"`
+	Max         interface{}        `json:"max"`
+	Mean        *float64           `json:"mean,omitempty"`
+	Samples     []string           `json:"samples,omitempty"`
+	TopValues   []ValueCount       `json:"top_values,omitempty"`
+	Histogram   []HistogramBucket  `json:"histogram,omitempty"`
+	Outliers    *OutlierReport     `json:"outliers,omitempty"`
+	Cardinality *CardinalityReport `json:"cardinality,omitempty"`
+	Temporal    *TemporalReport    `json:"temporal,omitempty"`
+	Quality     *ColumnQuality     `json:"quality,omitempty"`
+	Drift       *ColumnDrift       `json:"drift,omitempty"`
+}
+
+// ValueCount is a column value, the number of rows holding it and their
+// percentage of all rows
+type ValueCount struct {
+	Value string  `json:"value"`
+	Count int64   `json:"count"`
+	Pct   float64 `json:"pct"`
+}
+
+// TableProfile holds the row count and column statistics of a table
+type TableProfile struct {
+	Name      string          `json:"name"`
+	Rows      int64           `json:"rows"`
+	RowLimit  int             `json:"row_limit,omitempty"`  // Rows read from each source when the profile covers a sample
+	BlankRows *int64          `json:"blank_rows,omitempty"` // Rows where every column is NULL or empty (completeness analysis)
+	Columns   []ColumnProfile `json:"columns"`
+	Quality   *QualityScore   `json:"quality,omitempty"` // Quality scorecard (score analysis)
+	Drift     *DriftReport    `json:"drift,omitempty"`   // Schema changes against the baseline (compare analysis)
+	GroupBy   string          `json:"group_by,omitempty"`
+	Groups    []GroupProfile  `json:"groups,omitempty"` // Statistics per value of the GroupBy column
+}
+
+// ColumnSchema holds the name and data type of a column
+type ColumnSchema struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TableSchema holds the columns of a table in ordinal order
+type TableSchema struct {
+	Name    string         `json:"name"`
+	Columns []ColumnSchema `json:"columns"`
+}
+
+// Schema returns the tables available in storage and their column types,
+// including tables created after the import. Unlike Profile it does not scan
+// the data, so it stays cheap on large tables.
+func (d *dataQL) Schema() ([]TableSchema, error) {
+	if err := d.importData(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.storage.Query(storage.ColumnsQuery(d.engine(), ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []TableSchema
+	for rows.Next() {
+		var tableName string
+		var col ColumnSchema
+		if err := rows.Scan(&tableName, &col.Name, &col.Type); err != nil {
+			return nil, fmt.Errorf("failed to read column info: %w", err)
+		}
+
+		if len(tables) == 0 || tables[len(tables)-1].Name != tableName {
+			tables = append(tables, TableSchema{Name: tableName})
+		}
+		tables[len(tables)-1].Columns = append(tables[len(tables)-1].Columns, col)
+	}
+
+	return tables, rows.Err()
+}
+
+// getTableColumns returns the columns and data types of a table in ordinal order
+func (d *dataQL) getTableColumns(tableName string) ([]ColumnProfile, error) {
+	rows, err := d.storage.Query(storage.ColumnsQuery(d.engine(), tableName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []ColumnProfile
+	for rows.Next() {
+		var col ColumnProfile
+		var table string
+		if err := rows.Scan(&table, &col.Name, &col.Type); err != nil {
+			return nil, fmt.Errorf("failed to read column info: %w", err)
+		}
+		columns = append(columns, col)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table not found: %s", tableName)
+	}
+
+	return columns, nil
+}
+
+// profileTable collects null %, distinct count, min/max and sample values for every column
+func (d *dataQL) profileTable(tableName string) (int64, []ColumnProfile, error) {
+	columns, err := d.getTableColumns(tableName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var rowCount int64
+	countRows, err := d.storage.Query(fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get row count: %w", err)
+	}
+	if countRows.Next() {
+		if err := countRows.Scan(&rowCount); err != nil {
+			countRows.Close()
+			return 0, nil, fmt.Errorf("failed to read row count: %w", err)
+		}
+	}
+	countRows.Close()
+
+	for i := range columns {
+		col := &columns[i]
+		escapedColumn := fmt.Sprintf("\"%s\"", col.Name)
+
+		// The mean only makes sense for numeric columns
+		meanExpr := "NULL"
+		if isNumericType(col.Type) && !isDateTimeType(col.Type) {
+			meanExpr = fmt.Sprintf("CAST(AVG(%s) AS DOUBLE)", escapedColumn)
+		}
+
+		statsQuery := fmt.Sprintf("SELECT COUNT(*) - COUNT(%s), COUNT(DISTINCT %s), MIN(%s), MAX(%s), %s FROM %s",
+			escapedColumn, escapedColumn, escapedColumn, escapedColumn, meanExpr, tableName)
+		statsRows, err := d.storage.Query(statsQuery)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to get statistics for column %s: %w", col.Name, err)
+		}
+		if statsRows.Next() {
+			var mean sql.NullFloat64
+			if err := statsRows.Scan(&col.Nulls, &col.Distinct, &col.Min, &col.Max, &mean); err != nil {
+				statsRows.Close()
+				return 0, nil, fmt.Errorf("failed to read statistics for column %s: %w", col.Name, err)
+			}
+			if mean.Valid {
+				col.Mean = &mean.Float64
+			}
+		}
+		statsRows.Close()
+
+		// Keep text values readable when the profile is encoded as JSON
+		if b, ok := col.Min.([]byte); ok {
+			col.Min = string(b)
+		}
+		if b, ok := col.Max.([]byte); ok {
+			col.Max = string(b)
+		}
+
+		if rowCount > 0 {
+			col.NullPct = float64(col.Nulls) * 100 / float64(rowCount)
+		}
+
+		col.Samples = d.getSampleValues(tableName, escapedColumn)
+	}
+
+	return rowCount, columns, nil
+}
+
+// getSampleValues returns a few distinct non-null values of a column
+func (d *dataQL) getSampleValues(tableName, escapedColumn string) []string {
+	sampleQuery := fmt.Sprintf("SELECT DISTINCT CAST(%s AS VARCHAR) FROM %s WHERE %s IS NOT NULL LIMIT %d",
+		escapedColumn, tableName, escapedColumn, sampleValuesLimit)
+	rows, err := d.storage.Query(sampleQuery)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var samples []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			continue
+		}
+		samples = append(samples, value)
+	}
+
+	return samples
+}
+
+// describeColumns prints a per-column profile of a table for the REPL
+func (d *dataQL) describeColumns(tableName string) error {
+	rowCount, columns, err := d.profileTable(tableName)
+	if err != nil {
+		return fmt.Errorf("failed to describe table: %w", err)
+	}
+
+	headerColor := color.New(color.FgCyan, color.Bold)
+	headerColor.Fprintf(d.out, "=== Table: %s (%d rows) ===\n\n", tableName, rowCount)
+
+	tbl := table.New("Column", "Type", "Null %", "Distinct", "Min", "Max", "Samples").
+		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+		WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+		WithWriter(d.out)
+
+	for _, col := range columns {
+		tbl.AddRow(
+			col.Name,
+			col.Type,
+			fmt.Sprintf("%.1f%%", col.NullPct),
+			col.Distinct,
+			d.truncateValue(displayValue(col.Min)),
+			d.truncateValue(displayValue(col.Max)),
+			d.truncateValue(strings.Join(col.Samples, ", ")),
+		)
+	}
+
+	tbl.Print()
+	return nil
+}
+
+// displayValue renders a statistic value, showing "-" for NULL
+func displayValue(value interface{}) interface{} {
+	if value == nil {
+		return "-"
+	}
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
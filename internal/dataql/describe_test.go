@@ -0,0 +1,60 @@
+package dataql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsCategoricalType(t *testing.T) {
+	assert.True(t, isCategoricalType("VARCHAR"))
+	assert.True(t, isCategoricalType("BOOLEAN"))
+	assert.True(t, isCategoricalType("ENUM('a', 'b')"))
+	assert.False(t, isCategoricalType("BIGINT"))
+	assert.False(t, isCategoricalType("DATE"))
+}
+
+func TestProfile_TopValues(t *testing.T) {
+	dql, err := New(Params{
+		FileInputs: []string{"../../tests/fixtures/csv/null_values.csv"},
+		Delimiter:  ",",
+		Quiet:      true,
+	})
+	assert.NoError(t, err)
+	defer dql.Close()
+
+	tables, err := dql.Profile(DescribeOptions{TopValues: 2})
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+
+	var status ColumnProfile
+	for _, col := range tables[0].Columns {
+		if col.Name == "status" {
+			status = col
+		}
+		assert.LessOrEqual(t, len(col.TopValues), 2)
+	}
+
+	// Ties are ordered by value; the empty string sorts first
+	assert.Equal(t, []ValueCount{
+		{Value: "", Count: 2, Pct: 200.0 / 7},
+		{Value: "active", Count: 2, Pct: 200.0 / 7},
+	}, status.TopValues)
+}
+
+func TestProfile_RowLimit(t *testing.T) {
+	dql, err := New(Params{
+		FileInputs: []string{"../../tests/fixtures/csv/null_values.csv"},
+		Delimiter:  ",",
+		Lines:      3,
+		Quiet:      true,
+	})
+	assert.NoError(t, err)
+	defer dql.Close()
+
+	tables, err := dql.Profile(DescribeOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+	assert.Equal(t, int64(3), tables[0].Rows)
+	assert.Equal(t, 3, tables[0].RowLimit)
+}
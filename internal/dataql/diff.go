@@ -0,0 +1,153 @@
+package dataql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+
+	"github.com/adrianolaselva/dataql/pkg/datadiff"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// parseDiffArgs parses the arguments of the .diff command: tableA tableB [--key column]
+func parseDiffArgs(args []string) (tableA, tableB, key string, err error) {
+	var tables []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--key":
+			if i+1 >= len(args) {
+				return "", "", "", fmt.Errorf("missing column name after --key")
+			}
+			key = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--key="):
+			key = strings.TrimPrefix(arg, "--key=")
+		default:
+			tables = append(tables, arg)
+		}
+	}
+
+	if len(tables) != 2 {
+		return "", "", "", fmt.Errorf("usage: .diff <table_a> <table_b> [--key <column>]")
+	}
+
+	return tables[0], tables[1], key, nil
+}
+
+// diffQuerier runs the queries of pkg/datadiff on the session
+type diffQuerier struct {
+	d *dataQL
+}
+
+func (q diffQuerier) Query(ctx context.Context, query string) (datadiff.Rows, error) {
+	rows, err := q.d.queryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// diffTables compares two tables and prints added, removed and changed rows
+func (d *dataQL) diffTables(tableA, tableB, key string) error {
+	summary, err := d.computeDiff(tableA, tableB, key)
+	if err != nil {
+		return fmt.Errorf("failed to diff tables: %w", err)
+	}
+
+	headerColor := color.New(color.FgCyan, color.Bold)
+	headerColor.Fprintf(d.out, "=== Diff: %s (%d rows) -> %s (%d rows) ===\n\n", tableA, summary.LeftRows, tableB, summary.RightRows)
+
+	tbl := table.New("Change", "Rows").
+		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+		WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+		WithWriter(d.out)
+	tbl.AddRow("Added", summary.Added)
+	tbl.AddRow("Removed", summary.Removed)
+	if key != "" {
+		tbl.AddRow("Changed", summary.Modified)
+	}
+	tbl.Print()
+
+	if key == "" {
+		fmt.Fprintln(d.out, "\nNo key given: changed rows are reported as removed + added (use --key <column>)")
+	}
+
+	if len(summary.Columns) > 0 {
+		fmt.Fprintln(d.out)
+		colTbl := table.New("Column", "Changed").
+			WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+			WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+			WithWriter(d.out)
+		for _, c := range summary.Columns {
+			colTbl.AddRow(c.Column, c.Changed)
+		}
+		colTbl.Print()
+	}
+
+	if len(summary.LeftOnlyColumns) > 0 {
+		fmt.Fprintf(d.out, "\nColumns only in %s: %s\n", tableA, strings.Join(summary.LeftOnlyColumns, ", "))
+	}
+	if len(summary.RightOnlyColumns) > 0 {
+		fmt.Fprintf(d.out, "\nColumns only in %s: %s\n", tableB, strings.Join(summary.RightOnlyColumns, ", "))
+	}
+
+	return nil
+}
+
+// computeDiff compares two tables of the session with pkg/datadiff. With a
+// key, rows are matched by key and compared column by column; without one,
+// whole rows are compared using EXCEPT ALL.
+func (d *dataQL) computeDiff(tableA, tableB, key string) (*datadiff.Summary, error) {
+	var keys []string
+	if key != "" {
+		keys = []string{key}
+	} else if d.engine() == storage.EngineSQLite {
+		// SQLite has no EXCEPT ALL to count duplicated rows
+		return nil, fmt.Errorf(".diff without --key requires --engine duckdb (the %s engine does not support it)", storage.EngineSQLite)
+	}
+
+	tables := make([]datadiff.Table, 2)
+	for i, name := range []string{tableA, tableB} {
+		columns, err := d.getTableColumns(name)
+		if err != nil {
+			return nil, err
+		}
+		tables[i] = datadiff.Table{Name: name}
+		for _, column := range columns {
+			tables[i].Columns = append(tables[i].Columns, datadiff.Column{Name: column.Name, Type: column.Type})
+		}
+	}
+
+	diff, err := datadiff.New(diffQuerier{d: d}, tables[0], tables[1], keys)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Summary(context.Background())
+}
+
+// queryCount runs a query returning a single count
+func (d *dataQL) queryCount(query string) (int64, error) {
+	return d.queryCountContext(context.Background(), query)
+}
+
+// queryCountContext runs a query returning a single count, interrupting it
+// when ctx is done
+func (d *dataQL) queryCountContext(ctx context.Context, query string) (int64, error) {
+	rows, err := d.queryContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+	defer rows.Close()
+
+	var count int64
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return 0, fmt.Errorf("failed to read count: %w", err)
+		}
+	}
+	return count, nil
+}
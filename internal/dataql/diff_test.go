@@ -0,0 +1,36 @@
+package dataql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDiffArgs(t *testing.T) {
+	tableA, tableB, key, err := parseDiffArgs([]string{"old", "new"})
+	assert.NoError(t, err)
+	assert.Equal(t, "old", tableA)
+	assert.Equal(t, "new", tableB)
+	assert.Empty(t, key)
+
+	tableA, tableB, key, err = parseDiffArgs([]string{"old", "--key", "id", "new"})
+	assert.NoError(t, err)
+	assert.Equal(t, "old", tableA)
+	assert.Equal(t, "new", tableB)
+	assert.Equal(t, "id", key)
+
+	_, _, key, err = parseDiffArgs([]string{"old", "new", "--key=user_id"})
+	assert.NoError(t, err)
+	assert.Equal(t, "user_id", key)
+}
+
+func TestParseDiffArgs_Invalid(t *testing.T) {
+	_, _, _, err := parseDiffArgs([]string{"old"})
+	assert.Error(t, err)
+
+	_, _, _, err = parseDiffArgs([]string{"old", "new", "--key"})
+	assert.Error(t, err)
+
+	_, _, _, err = parseDiffArgs([]string{"a", "b", "c"})
+	assert.Error(t, err)
+}
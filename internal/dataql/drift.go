@@ -0,0 +1,459 @@
+package dataql
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+const (
+	// driftSignificance is the p-value below which a distribution shift is reported
+	driftSignificance = 0.05
+	// driftBins is the number of equal-width buckets numeric columns are compared on
+	driftBins = 10
+	// driftCategories is the number of most frequent values compared per
+	// text column; the remaining values are compared as a single bucket
+	driftCategories = 20
+)
+
+// DriftReport describes how a table differs from the matching table of the
+// baseline source given to --compare
+type DriftReport struct {
+	Baseline       string   `json:"baseline"`
+	BaselineTable  string   `json:"baseline_table"`
+	BaselineRows   int64    `json:"baseline_rows"`
+	AddedColumns   []string `json:"added_columns,omitempty"`
+	RemovedColumns []string `json:"removed_columns,omitempty"`
+}
+
+// ColumnDrift compares a column with the same column of the baseline. The
+// distributions are compared with a chi-square test over equal-width
+// buckets (numeric columns) or the most frequent values (text and boolean
+// columns); Shifted is set when the p-value is below 0.05.
+type ColumnDrift struct {
+	BaselineType    string   `json:"baseline_type"`
+	TypeChanged     bool     `json:"type_changed"`
+	BaselineNullPct float64  `json:"baseline_null_pct"`
+	BaselineMean    *float64 `json:"baseline_mean,omitempty"`
+	ChiSquare       *float64 `json:"chi_square,omitempty"` // Not set when the column is not compared
+	PValue          *float64 `json:"p_value,omitempty"`
+	Shifted         bool     `json:"shifted"`
+}
+
+// openBaseline imports the source given to --compare into a separate
+// session, reading it the same way as the described sources. The session is
+// kept until Close, or until another baseline is requested, so every table
+// is compared with the same import.
+func (d *dataQL) openBaseline(source string) (*dataQL, error) {
+	if d.baseline != nil {
+		if d.baseline.params.FileInputs[0] == source {
+			return d.baseline, nil
+		}
+		_ = d.baseline.Close()
+		d.baseline = nil
+	}
+
+	baseline, err := New(Params{
+		FileInputs:  []string{source},
+		Delimiter:   d.params.Delimiter,
+		Lines:       d.params.Lines,
+		InputFormat: d.params.InputFormat,
+		Verbose:     d.params.Verbose,
+		Quiet:       true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open baseline %s: %w", source, err)
+	}
+
+	b := baseline.(*dataQL)
+	if err := b.importData(); err != nil {
+		_ = b.Close()
+		return nil, fmt.Errorf("failed to import baseline %s: %w", source, err)
+	}
+	d.baseline = b
+	return b, nil
+}
+
+// matchBaselineTable returns the baseline table to compare tableName with:
+// the table of the same name, or the only table when both sides have one
+func matchBaselineTable(tableName string, tables, baselineTables []string) string {
+	for _, t := range baselineTables {
+		if strings.EqualFold(t, tableName) {
+			return t
+		}
+	}
+	if len(tables) == 1 && len(baselineTables) == 1 {
+		return baselineTables[0]
+	}
+	return ""
+}
+
+// getDrift compares a profiled table with its baseline table and fills
+// the drift of every column both tables have. It returns nil when the
+// baseline has no matching table.
+func (d *dataQL) getDrift(baseline *dataQL, source, tableName string, tables []string, columns []ColumnProfile) (*DriftReport, error) {
+	baselineTables, err := baseline.listTables()
+	if err != nil {
+		return nil, err
+	}
+	baselineTable := matchBaselineTable(tableName, tables, baselineTables)
+	if baselineTable == "" {
+		return nil, nil
+	}
+
+	baselineRows, baselineColumns, err := baseline.profileTable(baselineTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to profile baseline table %s: %w", baselineTable, err)
+	}
+
+	report := &DriftReport{Baseline: source, BaselineTable: baselineTable, BaselineRows: baselineRows}
+	for _, col := range baselineColumns {
+		if _, ok := findColumn(columns, col.Name); !ok {
+			report.RemovedColumns = append(report.RemovedColumns, col.Name)
+		}
+	}
+
+	for i := range columns {
+		base, ok := findColumn(baselineColumns, columns[i].Name)
+		if !ok {
+			report.AddedColumns = append(report.AddedColumns, columns[i].Name)
+			continue
+		}
+
+		drift := &ColumnDrift{
+			BaselineType:    base.Type,
+			TypeChanged:     !strings.EqualFold(columns[i].Type, base.Type),
+			BaselineNullPct: base.NullPct,
+			BaselineMean:    base.Mean,
+		}
+		observed, expected, err := d.driftCounts(baseline, tableName, baselineTable, columns[i], base)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s: %w", columns[i].Name, err)
+		}
+		if statistic, df := chiSquareHomogeneity(observed, expected); df > 0 {
+			pValue := chiSquarePValue(statistic, df)
+			drift.ChiSquare, drift.PValue = &statistic, &pValue
+			drift.Shifted = pValue < driftSignificance
+		}
+		columns[i].Drift = drift
+	}
+
+	return report, nil
+}
+
+// driftCounts returns the bucket counts of a column in the table and in the
+// baseline table, over the same buckets. Both are nil when the column is
+// neither numeric nor categorical on both sides.
+func (d *dataQL) driftCounts(baseline *dataQL, tableName, baselineTable string, col, base ColumnProfile) ([]int64, []int64, error) {
+	switch {
+	case isMeasureType(col.Type) && isMeasureType(base.Type):
+		return d.driftBuckets(baseline, tableName, baselineTable, col, base)
+	case isCategoricalType(col.Type) && isCategoricalType(base.Type):
+		return d.driftCategories(baseline, tableName, baselineTable, col, base)
+	}
+	return nil, nil, nil
+}
+
+// driftBuckets counts the values of a numeric column of both tables in
+// equal-width buckets spanning the range of both
+func (d *dataQL) driftBuckets(baseline *dataQL, tableName, baselineTable string, col, base ColumnProfile) ([]int64, []int64, error) {
+	lower, upper := math.Inf(1), math.Inf(-1)
+	for _, side := range []struct {
+		dql   *dataQL
+		table string
+		name  string
+	}{{d, tableName, col.Name}, {baseline, baselineTable, base.Name}} {
+		minValue, maxValue, err := side.dql.numericRange(side.table, side.name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if minValue.Valid {
+			lower, upper = math.Min(lower, minValue.Float64), math.Max(upper, maxValue.Float64)
+		}
+	}
+	if math.IsInf(lower, 0) {
+		return nil, nil, nil // Only NULL values
+	}
+
+	width := (upper - lower) / driftBins
+	observed, err := d.bucketCounts(tableName, col.Name, lower, width)
+	if err != nil {
+		return nil, nil, err
+	}
+	expected, err := baseline.bucketCounts(baselineTable, base.Name, lower, width)
+	if err != nil {
+		return nil, nil, err
+	}
+	return observed, expected, nil
+}
+
+// numericRange returns the smallest and largest value of a numeric column
+func (d *dataQL) numericRange(tableName, column string) (sql.NullFloat64, sql.NullFloat64, error) {
+	var minValue, maxValue sql.NullFloat64
+	rows, err := d.storage.Query(fmt.Sprintf("SELECT MIN(CAST(\"%s\" AS DOUBLE)), MAX(CAST(\"%s\" AS DOUBLE)) FROM %s",
+		column, column, tableName))
+	if err != nil {
+		return minValue, maxValue, fmt.Errorf("failed to get range: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&minValue, &maxValue); err != nil {
+			return minValue, maxValue, fmt.Errorf("failed to read range: %w", err)
+		}
+	}
+	return minValue, maxValue, rows.Err()
+}
+
+// bucketCounts counts the non-null values of a numeric column in driftBins
+// buckets of the given width starting at lower
+func (d *dataQL) bucketCounts(tableName, column string, lower, width float64) ([]int64, error) {
+	bucketExpr := "0"
+	if width > 0 {
+		bucketExpr = fmt.Sprintf("LEAST(GREATEST(CAST(FLOOR((CAST(\"%s\" AS DOUBLE) - %v) / %v) AS BIGINT), 0), %d)",
+			column, lower, width, driftBins-1)
+	}
+	rows, err := d.storage.Query(fmt.Sprintf("SELECT %s, COUNT(*) FROM %s WHERE \"%s\" IS NOT NULL GROUP BY 1",
+		bucketExpr, tableName, column))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count buckets: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]int64, driftBins)
+	for rows.Next() {
+		var bucket, count int64
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, fmt.Errorf("failed to read buckets: %w", err)
+		}
+		counts[bucket] = count
+	}
+	return counts, rows.Err()
+}
+
+// driftCategories counts the most frequent values of both tables, and the
+// remaining values as one bucket, in a text or boolean column of both tables
+func (d *dataQL) driftCategories(baseline *dataQL, tableName, baselineTable string, col, base ColumnProfile) ([]int64, []int64, error) {
+	seen := make(map[string]bool)
+	var values []string
+	for _, side := range []struct {
+		dql   *dataQL
+		table string
+		name  string
+	}{{d, tableName, col.Name}, {baseline, baselineTable, base.Name}} {
+		top, err := side.dql.getTopValues(side.table, fmt.Sprintf("\"%s\"", side.name), driftCategories)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, vc := range top {
+			if !seen[vc.Value] {
+				seen[vc.Value] = true
+				values = append(values, vc.Value)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil, nil // Only NULL values
+	}
+
+	observed, err := d.categoryCounts(tableName, col.Name, values)
+	if err != nil {
+		return nil, nil, err
+	}
+	expected, err := baseline.categoryCounts(baselineTable, base.Name, values)
+	if err != nil {
+		return nil, nil, err
+	}
+	return observed, expected, nil
+}
+
+// categoryCounts counts the rows holding each of values in a column, with a
+// last bucket for the other non-null values
+func (d *dataQL) categoryCounts(tableName, column string, values []string) ([]int64, error) {
+	index := make(map[string]int, len(values))
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		index[v] = i
+		quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+
+	valueExpr := fmt.Sprintf("CAST(\"%s\" AS VARCHAR)", column)
+	rows, err := d.storage.Query(fmt.Sprintf(
+		"SELECT CASE WHEN %s IN (%s) THEN %s END AS v, COUNT(*) FROM %s WHERE \"%s\" IS NOT NULL GROUP BY 1",
+		valueExpr, strings.Join(quoted, ", "), valueExpr, tableName, column))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count values: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]int64, len(values)+1)
+	for rows.Next() {
+		var value sql.NullString
+		var count int64
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, fmt.Errorf("failed to read values: %w", err)
+		}
+		if value.Valid {
+			counts[index[value.String]] = count
+		} else {
+			counts[len(values)] = count
+		}
+	}
+	return counts, rows.Err()
+}
+
+// chiSquareHomogeneity tests whether two sets of bucket counts come from the
+// same distribution. It returns the statistic and degrees of freedom; df is
+// 0 when either side is empty or fewer than two buckets hold values.
+func chiSquareHomogeneity(observed, expected []int64) (float64, int) {
+	var totalA, totalB int64
+	for i := range observed {
+		totalA += observed[i]
+		totalB += expected[i]
+	}
+	if totalA == 0 || totalB == 0 {
+		return 0, 0
+	}
+
+	total := float64(totalA + totalB)
+	statistic, buckets := 0.0, 0
+	for i := range observed {
+		bucketTotal := float64(observed[i] + expected[i])
+		if bucketTotal == 0 {
+			continue
+		}
+		buckets++
+		for _, side := range []struct{ count, total int64 }{{observed[i], totalA}, {expected[i], totalB}} {
+			e := bucketTotal * float64(side.total) / total
+			statistic += (float64(side.count) - e) * (float64(side.count) - e) / e
+		}
+	}
+	return statistic, buckets - 1
+}
+
+// chiSquarePValue returns the probability of a chi-square statistic at least
+// as large as x with df degrees of freedom, i.e. the regularized upper
+// incomplete gamma function Q(df/2, x/2)
+func chiSquarePValue(x float64, df int) float64 {
+	a, x := float64(df)/2, x/2
+	if x <= 0 {
+		return 1
+	}
+	lgamma, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lgamma)
+
+	if x < a+1 {
+		// Series expansion of the lower function P, then Q = 1 - P
+		sum, term := 1/a, 1/a
+		for n := 1.0; n < 500 && math.Abs(term) > math.Abs(sum)*1e-15; n++ {
+			term *= x / (a + n)
+			sum += term
+		}
+		return math.Max(0, 1-sum*prefix)
+	}
+
+	// Continued fraction for Q (modified Lentz's method)
+	const tiny = 1e-300
+	b := x + 1 - a
+	c, dd := 1/tiny, 1/b
+	h := dd
+	for i := 1.0; i < 500; i++ {
+		an := -i * (i - a)
+		b += 2
+		dd = an*dd + b
+		if math.Abs(dd) < tiny {
+			dd = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		dd = 1 / dd
+		delta := dd * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return prefix * h
+}
+
+// printDrift prints how a table differs from the matching table of the
+// baseline source
+func (d *dataQL) printDrift(tableName, source string) error {
+	baseline, err := d.openBaseline(source)
+	if err != nil {
+		return err
+	}
+	tables, err := d.listTables()
+	if err != nil {
+		return err
+	}
+	rowCount, columns, err := d.profileTable(tableName)
+	if err != nil {
+		return err
+	}
+
+	report, err := d.getDrift(baseline, source, tableName, tables, columns)
+	if err != nil {
+		return err
+	}
+
+	sectionColor := color.New(color.FgCyan)
+	if report == nil {
+		sectionColor.Fprintf(d.out, "\nDrift against %s:\n", source)
+		fmt.Fprintf(d.out, "\nNo table matching %s in the baseline.\n", tableName)
+		return nil
+	}
+	sectionColor.Fprintf(d.out, "\nDrift against %s (table %s, %d rows -> %d rows):\n", source, report.BaselineTable, report.BaselineRows, rowCount)
+
+	if len(report.AddedColumns) > 0 {
+		fmt.Fprintf(d.out, "\nAdded columns: %s\n", strings.Join(report.AddedColumns, ", "))
+	}
+	if len(report.RemovedColumns) > 0 {
+		fmt.Fprintf(d.out, "\nRemoved columns: %s\n", strings.Join(report.RemovedColumns, ", "))
+	}
+	fmt.Fprintln(d.out)
+
+	tbl := table.New("Column", "Type", "Null %", "Mean", "p-value", "Drift").
+		WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+		WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+		WithWriter(d.out)
+
+	shifted := 0
+	for _, col := range columns {
+		drift := col.Drift
+		if drift == nil {
+			continue
+		}
+
+		typeLabel := col.Type
+		var changes []string
+		if drift.TypeChanged {
+			typeLabel = drift.BaselineType + " -> " + col.Type
+			changes = append(changes, "type changed")
+		}
+		mean := "-"
+		if col.Mean != nil && drift.BaselineMean != nil {
+			mean = formatBound(*drift.BaselineMean) + " -> " + formatBound(*col.Mean)
+		}
+		pValue := "-"
+		if drift.PValue != nil {
+			pValue = fmt.Sprintf("%.4f", *drift.PValue)
+		}
+		if drift.Shifted {
+			changes = append(changes, "distribution shifted")
+			shifted++
+		}
+
+		tbl.AddRow(col.Name, typeLabel, fmt.Sprintf("%.1f -> %.1f", drift.BaselineNullPct, col.NullPct), mean, pValue, strings.Join(changes, ", "))
+	}
+	tbl.Print()
+
+	fmt.Fprintf(d.out, "\n%d columns with a significant distribution shift (p < %v)\n", shifted, driftSignificance)
+	return nil
+}
@@ -0,0 +1,71 @@
+package dataql
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChiSquarePValue(t *testing.T) {
+	// Reference values of the chi-square survival function
+	assert.InDelta(t, 0.05, chiSquarePValue(3.841, 1), 0.0005)
+	assert.InDelta(t, 0.05, chiSquarePValue(16.919, 9), 0.0005)
+	assert.InDelta(t, 0.5724, chiSquarePValue(2, 3), 0.0005)
+	assert.Equal(t, 1.0, chiSquarePValue(0, 4))
+}
+
+func TestChiSquareHomogeneity(t *testing.T) {
+	statistic, df := chiSquareHomogeneity([]int64{10, 20, 0}, []int64{20, 40, 0})
+	assert.Equal(t, 1, df) // Empty buckets are left out
+	assert.InDelta(t, 0, statistic, 1e-9)
+
+	_, df = chiSquareHomogeneity([]int64{0, 0}, []int64{5, 5})
+	assert.Equal(t, 0, df)
+}
+
+func TestProfile_Drift(t *testing.T) {
+	dir := t.TempDir()
+	var baseline, current strings.Builder
+	baseline.WriteString("id,amount,status,legacy\n")
+	current.WriteString("id,amount,status,channel\n")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&baseline, "%d,%d,%s,x\n", i, i%50, []string{"open", "closed"}[i%2])
+		fmt.Fprintf(&current, "%d,%d,%s,web\n", i, 100+i%50, []string{"open", "closed"}[i%2])
+	}
+	baselinePath := filepath.Join(dir, "orders_2024_01.csv")
+	currentPath := filepath.Join(dir, "orders_2024_02.csv")
+	assert.NoError(t, os.WriteFile(baselinePath, []byte(baseline.String()), 0644))
+	assert.NoError(t, os.WriteFile(currentPath, []byte(current.String()), 0644))
+
+	dql, err := New(Params{FileInputs: []string{currentPath}, Delimiter: ",", Quiet: true})
+	assert.NoError(t, err)
+	defer dql.Close()
+
+	tables, err := dql.Profile(DescribeOptions{Compare: baselinePath})
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+
+	// The single tables are paired although their names differ
+	report := tables[0].Drift
+	assert.NotNil(t, report)
+	assert.Equal(t, "orders_2024_01", report.BaselineTable)
+	assert.Equal(t, int64(200), report.BaselineRows)
+	assert.Equal(t, []string{"channel"}, report.AddedColumns)
+	assert.Equal(t, []string{"legacy"}, report.RemovedColumns)
+
+	columns := tables[0].Columns
+	amount, _ := findColumn(columns, "amount")
+	assert.True(t, amount.Drift.Shifted)
+	assert.Less(t, *amount.Drift.PValue, 0.05)
+
+	status, _ := findColumn(columns, "status")
+	assert.False(t, status.Drift.Shifted)
+	assert.InDelta(t, 1, *status.Drift.PValue, 1e-9)
+
+	channel, _ := findColumn(columns, "channel")
+	assert.Nil(t, channel.Drift)
+}
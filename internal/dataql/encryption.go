@@ -0,0 +1,40 @@
+package dataql
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/adrianolaselva/dataql/pkg/encryption"
+)
+
+// openEncrypted returns the path DuckDB opens for a storage file: a
+// decrypted working copy when the file is encrypted, or when encrypt asks
+// for a new file to be encrypted. Other files are opened in place.
+func openEncrypted(path string, encrypt bool) (string, *encryption.WorkingCopy, error) {
+	if path == "" {
+		return path, nil, nil
+	}
+
+	encrypted, err := encryption.IsEncrypted(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !encrypted {
+		if !encrypt {
+			return path, nil, nil
+		}
+		if _, err := os.Stat(path); err == nil {
+			return "", nil, fmt.Errorf("%s is not encrypted; encrypt it first with: dataql encryption encrypt %s", path, path)
+		}
+	}
+
+	key, err := encryption.LoadKey()
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", path, err)
+	}
+	working, err := encryption.Open(path, key)
+	if err != nil {
+		return "", nil, err
+	}
+	return working.Path(), working, nil
+}
@@ -0,0 +1,110 @@
+package dataql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedStorage(t *testing.T) {
+	key, err := encryption.GenerateKey()
+	require.NoError(t, err)
+	t.Setenv(encryption.EnvKey, key)
+	storage := filepath.Join(t.TempDir(), "store.duckdb")
+
+	dql, err := New(Params{
+		FileInputs:     []string{"../../tests/fixtures/csv/simple.csv"},
+		DataSourceName: storage,
+		Delimiter:      ",",
+		Quiet:          true,
+		Encrypt:        true,
+	})
+	require.NoError(t, err)
+	_, err = dql.Query("SELECT COUNT(*) FROM simple", 0)
+	require.NoError(t, err)
+	require.NoError(t, dql.Close())
+
+	encrypted, err := encryption.IsEncrypted(storage)
+	require.NoError(t, err)
+	assert.True(t, encrypted)
+
+	// Encrypted files are decrypted without --encrypt
+	dql, err = NewStorageOnly(Params{DataSourceName: storage, Quiet: true})
+	require.NoError(t, err)
+	result, err := dql.Query("SELECT name FROM simple ORDER BY id LIMIT 1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{"John"}}, result.Rows)
+	require.NoError(t, dql.Close())
+
+	other, err := encryption.GenerateKey()
+	require.NoError(t, err)
+	t.Setenv(encryption.EnvKey, other)
+	_, err = NewStorageOnly(Params{DataSourceName: storage, Quiet: true})
+	assert.ErrorContains(t, err, "wrong key")
+}
+
+func TestEncryptedStorage_PlaintextFile(t *testing.T) {
+	key, err := encryption.GenerateKey()
+	require.NoError(t, err)
+	t.Setenv(encryption.EnvKey, key)
+
+	storage := filepath.Join(t.TempDir(), "store.duckdb")
+	dql, err := New(Params{
+		FileInputs:     []string{"../../tests/fixtures/csv/simple.csv"},
+		DataSourceName: storage,
+		Delimiter:      ",",
+		Quiet:          true,
+	})
+	require.NoError(t, err)
+	_, err = dql.Query("SELECT 1", 0)
+	require.NoError(t, err)
+	require.NoError(t, dql.Close())
+
+	// An existing plaintext file is never silently encrypted
+	_, err = NewStorageOnly(Params{DataSourceName: storage, Quiet: true, Encrypt: true})
+	assert.ErrorContains(t, err, "is not encrypted")
+
+	data, err := os.ReadFile(storage)
+	require.NoError(t, err)
+	assert.NotEqual(t, "DQLENC1", string(data[:7]))
+}
+
+func TestEncryptedStorage_DatabaseSource(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "src.duckdb")
+	dql, err := New(Params{
+		FileInputs:     []string{"../../tests/fixtures/csv/simple.csv"},
+		DataSourceName: source,
+		Delimiter:      ",",
+		Quiet:          true,
+	})
+	require.NoError(t, err)
+	_, err = dql.Query("SELECT 1", 0)
+	require.NoError(t, err)
+	require.NoError(t, dql.Close())
+
+	key, err := encryption.GenerateKey()
+	require.NoError(t, err)
+	t.Setenv(encryption.EnvKey, key)
+	storage := filepath.Join(dir, "store.duckdb")
+
+	// Database handlers do not close the storage themselves
+	dql, err = New(Params{
+		FileInputs:     []string{"duckdb://" + source + "/simple"},
+		DataSourceName: storage,
+		Quiet:          true,
+		Encrypt:        true,
+	})
+	require.NoError(t, err)
+	_, err = dql.Query("SELECT COUNT(*) FROM simple", 0)
+	require.NoError(t, err)
+	require.NoError(t, dql.Close())
+
+	encrypted, err := encryption.IsEncrypted(storage)
+	require.NoError(t, err)
+	assert.True(t, encrypted)
+}
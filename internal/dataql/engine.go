@@ -0,0 +1,75 @@
+package dataql
+
+import (
+	"fmt"
+
+	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/adrianolaselva/dataql/pkg/storage/duckdb"
+	"github.com/adrianolaselva/dataql/pkg/storage/sqlite"
+)
+
+// CheckEngine rejects the options that only the DuckDB engine supports when
+// params select another engine
+func CheckEngine(params Params) error {
+	engine, err := storage.ParseEngine(params.Engine)
+	if err != nil || engine == storage.EngineDuckDB {
+		return err
+	}
+
+	unsupported := map[string]bool{
+		"--cache":       params.Cache,
+		"--extension":   len(params.Extensions) > 0,
+		"--in-place":    params.InPlace,
+		"--udf":         len(params.UDFs) > 0,
+		"--input-tz":    params.InputTZ != "",
+		"--temp-dir":    params.TempDir != "",
+		"--json-nested": params.JSONNested != "" && params.JSONNested != string(filehandler.NestedFlatten),
+		"settings":      len(params.Settings) > 0,
+	}
+	for _, option := range []string{"--cache", "--extension", "--in-place", "--udf", "--input-tz", "--temp-dir", "--json-nested", "settings"} {
+		if unsupported[option] {
+			return fmt.Errorf("%s requires --engine duckdb (the %s engine does not support it)", option, engine)
+		}
+	}
+	return nil
+}
+
+// engine returns the storage engine of the session
+func (d *dataQL) engine() storage.Engine {
+	engine, _ := storage.ParseEngine(d.params.Engine)
+	return engine
+}
+
+// openStorage opens the storage of the engine of params at path, in memory
+// when path is empty
+func openStorage(params Params, path string) (storage.Storage, error) {
+	if err := CheckEngine(params); err != nil {
+		return nil, err
+	}
+
+	engine, _ := storage.ParseEngine(params.Engine)
+	verboseLog(params.Verbose, "Initializing %s storage...", engine)
+	if params.ReadOnly && path != "" {
+		path = readOnlyPath(engine, path)
+	}
+	var st storage.Storage
+	var err error
+	if engine == storage.EngineSQLite {
+		st, err = sqlite.NewSqLiteStorage(path)
+	} else {
+		st, err = duckdb.NewDuckDBStorage(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	storage.SetBatch(st, params.BatchSize, params.CommitInterval)
+	if params.TempDir != "" {
+		// Sorts, joins and aggregations larger than memory_limit are spilled there
+		if err := execStatement(st, "SET GLOBAL temp_directory = "+sqlString(params.TempDir)); err != nil {
+			_ = st.Close()
+			return nil, fmt.Errorf("failed to set --temp-dir: %w", err)
+		}
+	}
+	return st, nil
+}
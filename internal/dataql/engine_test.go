@@ -0,0 +1,52 @@
+package dataql
+
+import (
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/repl"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogCommands(t *testing.T) {
+	for _, engine := range storage.Engines {
+		t.Run(string(engine), func(t *testing.T) {
+			dql, err := New(Params{
+				FileInputs: []string{"../../tests/fixtures/csv/users.csv", "../../tests/fixtures/csv/simple.csv"},
+				Delimiter:  ",",
+				Engine:     string(engine),
+				Quiet:      true,
+			})
+			require.NoError(t, err)
+			defer dql.Close()
+			require.NoError(t, dql.Import())
+			d := dql.(*dataQL)
+
+			schema, err := d.Schema()
+			require.NoError(t, err)
+			require.Len(t, schema, 2)
+			assert.Equal(t, "simple", schema[0].Name)
+			assert.Equal(t, "users", schema[1].Name)
+
+			columns, err := d.getTableColumns("users")
+			require.NoError(t, err)
+			assert.Equal(t, "id", columns[0].Name)
+
+			for _, command := range []string{".describe users", ".sample users 1", ".diff users users --key id"} {
+				assert.NoError(t, dql.Exec(command), command)
+			}
+			if engine == storage.EngineSQLite {
+				assert.ErrorContains(t, dql.Exec(".diff users users"), "requires --engine duckdb")
+			} else {
+				assert.NoError(t, dql.Exec(".diff users users"))
+			}
+
+			completer := repl.NewSQLCompleter(d.storage, d.engine())
+			require.NoError(t, completer.RefreshSchema())
+			candidates, _ := completer.Do([]rune("SELECT * FROM us"), len("SELECT * FROM us"))
+			require.Len(t, candidates, 1)
+			assert.Equal(t, "ers", string(candidates[0]))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package dataql
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/clierror"
+)
+
+// sourceError classifies an error reading sources, naming the source the
+// message mentions. names are the sources as given, with credentials
+// redacted, and paths the same sources as read, e.g. downloaded files.
+func sourceError(err error, names, paths []string) error {
+	if err == nil {
+		return nil
+	}
+	return clierror.Source(offendingSource(err, names, paths), err)
+}
+
+// offendingSource returns the name of the source err is about, or an empty
+// string. The longest match wins, so data.csv is not taken for a.csv.
+func offendingSource(err error, names, paths []string) string {
+	message := err.Error()
+	var source string
+	var longest int
+	match := func(i int, candidate string) {
+		if candidate != "" && len(candidate) > longest && strings.Contains(message, candidate) {
+			source, longest = names[i], len(candidate)
+		}
+	}
+	for i, name := range names {
+		match(i, name)
+		if len(paths) == len(names) {
+			match(i, paths[i])
+		}
+	}
+
+	var pathErr *fs.PathError
+	if source == "" && errors.As(err, &pathErr) {
+		return pathErr.Path
+	}
+	return source
+}
@@ -0,0 +1,16 @@
+package dataql
+
+import (
+	"github.com/adrianolaselva/dataql/pkg/extensions"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// loadExtensions points the storage at the extension directory and loads the
+// extensions of --extension, before the sources are imported so that imports
+// and queries can use them
+func loadExtensions(st storage.Storage, params Params) error {
+	if err := extensions.Configure(st, "", params.OfflineExtensions); err != nil {
+		return err
+	}
+	return extensions.Load(st, params.Extensions, params.OfflineExtensions)
+}
@@ -0,0 +1,239 @@
+package dataql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/chzyer/readline"
+)
+
+// defaultFollowInterval is the interval used by --follow when none is given
+const defaultFollowInterval = 2 * time.Second
+
+// followedFile is a file input read as it grows, like tail -F
+type followedFile struct {
+	path   string
+	table  string
+	csv    bool
+	header []byte   // CSV header line, repeated in each chunk of appended lines
+	file   *os.File // Kept open to finish reading the file after a rotation renames it
+	offset int64    // End of the last complete line read
+}
+
+// follow runs query, then runs it again each time lines are appended to the
+// file inputs, until Ctrl-C is pressed. Files replaced by a rotation or
+// truncated are read again from their start.
+func (d *dataQL) follow(query string) error {
+	if !appendableFormat(d.params) {
+		return fmt.Errorf("--follow supports CSV and JSONL files without --lines")
+	}
+
+	files, err := d.openFollowed()
+	defer func() {
+		for _, f := range files {
+			_ = f.file.Close()
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	interval := d.params.FollowInterval
+	if interval <= 0 {
+		interval = defaultFollowInterval
+	}
+
+	// Only redraw in place when writing to a terminal
+	redraw := readline.IsTerminal(int(os.Stdout.Fd()))
+
+	for changed := true; ; {
+		if changed {
+			if redraw {
+				fmt.Fprint(d.out, "\033[H\033[2J")
+			}
+			if !d.params.Plain {
+				fmt.Fprintf(d.out, "Following every %v: %s\t%s\n\n", interval, query, time.Now().Format(time.RFC1123))
+			}
+			if err := d.finishTransaction(d.runQuery(query)); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+
+		appended, err := d.importAppended(files)
+		if err != nil {
+			return err
+		}
+		changed = appended > 0
+	}
+}
+
+// openFollowed opens the file inputs at their current end
+func (d *dataQL) openFollowed() ([]*followedFile, error) {
+	format, _ := filehandler.DetectFormatFromFiles(d.params.FileInputs)
+
+	var files []*followedFile
+	for i, path := range d.params.FileInputs {
+		// Downloaded, decompressed and stdin inputs are temporary copies
+		if i >= len(d.sourceNames) || d.sourceNames[i] != path {
+			return files, fmt.Errorf("--follow reads local files only")
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return files, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		f := &followedFile{
+			path:  path,
+			table: sourceTableName(path, d.tableAliases, d.params.Collection),
+			csv:   format == filehandler.FormatCSV,
+			file:  file,
+		}
+		files = append(files, f)
+
+		if f.csv {
+			if f.header, err = bufio.NewReader(file).ReadBytes('\n'); err != nil && err != io.EOF {
+				return files, fmt.Errorf("failed to read header of %s: %w", path, err)
+			}
+		}
+		info, err := file.Stat()
+		if err != nil {
+			return files, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		f.offset = info.Size()
+	}
+	return files, nil
+}
+
+// importAppended imports the lines appended to the files since they were
+// last read, returning the number of chunks of lines imported
+func (d *dataQL) importAppended(files []*followedFile) (int, error) {
+	dir, err := os.MkdirTemp("", "dataql-follow-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var chunks []string
+	targets := make(map[string]string)
+	for _, f := range files {
+		appended, err := f.read()
+		if err != nil {
+			return 0, err
+		}
+		for _, data := range appended {
+			chunk := filepath.Join(dir, fmt.Sprintf("chunk_%d%s", len(chunks), filepath.Ext(f.path)))
+			if err := os.WriteFile(chunk, data, 0600); err != nil {
+				return 0, fmt.Errorf("failed to write appended lines: %w", err)
+			}
+			chunks = append(chunks, chunk)
+			targets[chunk] = f.table
+		}
+	}
+	if len(chunks) == 0 {
+		return 0, nil
+	}
+
+	if err := d.appendRows(chunks, targets); err != nil {
+		return 0, fmt.Errorf("failed to import appended lines: %w", err)
+	}
+	verboseLog(d.params.Verbose, "Imported %d chunks of appended lines", len(chunks))
+	return len(chunks), nil
+}
+
+// read returns the complete lines appended since the last read, in one chunk
+// per file read: after a rotation, the end of the old file and the start of
+// the new one
+func (f *followedFile) read() ([][]byte, error) {
+	chunks, err := f.next(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		// Rotated away, and the new file is not created yet
+		return chunks, nil
+	}
+	current, err := f.file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", f.path, err)
+	}
+
+	switch {
+	case !os.SameFile(info, current):
+		file, err := os.Open(f.path)
+		if err != nil {
+			return chunks, nil
+		}
+		_ = f.file.Close()
+		f.file = file
+	case info.Size() < f.offset:
+		// Truncated in place
+	default:
+		return chunks, nil
+	}
+
+	f.offset = 0
+	return f.next(chunks)
+}
+
+// next adds the complete lines appended to the open file to chunks. CSV
+// chunks start with the header line, which is taken from the file when it
+// is read from its start.
+func (f *followedFile) next(chunks [][]byte) ([][]byte, error) {
+	start := f.offset
+	data, err := f.readLines()
+	if err != nil {
+		return nil, err
+	}
+	if f.csv && start == 0 && len(data) > 0 {
+		header, rest, _ := bytes.Cut(data, []byte("\n"))
+		f.header = append(header, '\n')
+		data = rest
+	}
+	if len(data) == 0 {
+		return chunks, nil
+	}
+	if f.csv {
+		data = append(append([]byte{}, f.header...), data...)
+	}
+	return append(chunks, data), nil
+}
+
+// readLines reads from the offset up to the end of the last complete line
+func (f *followedFile) readLines() ([]byte, error) {
+	info, err := f.file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", f.path, err)
+	}
+	if info.Size() <= f.offset {
+		return nil, nil
+	}
+
+	data := make([]byte, info.Size()-f.offset)
+	n, err := f.file.ReadAt(data, f.offset)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read %s: %w", f.path, err)
+	}
+	end := bytes.LastIndexByte(data[:n], '\n') + 1
+	f.offset += int64(end)
+	return data[:end], nil
+}
@@ -0,0 +1,109 @@
+package dataql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func followFile(t *testing.T, path string) *followedFile {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = file.Close() })
+	info, err := file.Stat()
+	require.NoError(t, err)
+	return &followedFile{path: path, csv: true, header: []byte("id,level\n"), file: file, offset: info.Size()}
+}
+
+func readChunks(t *testing.T, f *followedFile) []string {
+	t.Helper()
+	chunks, err := f.read()
+	require.NoError(t, err)
+	var text []string
+	for _, chunk := range chunks {
+		text = append(text, string(chunk))
+	}
+	return text
+}
+
+func TestFollowedFile_CompleteLinesOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csv")
+	require.NoError(t, os.WriteFile(path, []byte("id,level\n1,info\n"), 0644))
+	f := followFile(t, path)
+
+	assert.Empty(t, readChunks(t, f))
+
+	appendFile(t, path, "2,warn\n3,err")
+	assert.Equal(t, []string{"id,level\n2,warn\n"}, readChunks(t, f))
+
+	appendFile(t, path, "or\n")
+	assert.Equal(t, []string{"id,level\n3,error\n"}, readChunks(t, f))
+}
+
+func TestFollowedFile_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.csv")
+	require.NoError(t, os.WriteFile(path, []byte("id,level\n1,info\n"), 0644))
+	f := followFile(t, path)
+
+	// Lines written before the rotation are read from the renamed file
+	appendFile(t, path, "2,warn\n")
+	require.NoError(t, os.Rename(path, filepath.Join(dir, "app.csv.1")))
+	assert.Equal(t, []string{"id,level\n2,warn\n"}, readChunks(t, f))
+
+	appendFile(t, filepath.Join(dir, "app.csv.1"), "3,warn\n")
+	require.NoError(t, os.WriteFile(path, []byte("id,level\n4,debug\n"), 0644))
+	assert.Equal(t, []string{"id,level\n3,warn\n", "id,level\n4,debug\n"}, readChunks(t, f))
+}
+
+func TestFollowedFile_Truncation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.csv")
+	require.NoError(t, os.WriteFile(path, []byte("id,level\n1,info\n2,warn\n"), 0644))
+	f := followFile(t, path)
+
+	require.NoError(t, os.WriteFile(path, []byte("id,level\n3,error\n"), 0644))
+	assert.Equal(t, []string{"id,level\n3,error\n"}, readChunks(t, f))
+}
+
+func TestImportAppended(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":1,"level":"info"}`+"\n"), 0644))
+
+	dql, err := New(Params{FileInputs: []string{path}, Quiet: true})
+	require.NoError(t, err)
+	defer dql.Close()
+	_, err = dql.Query("SELECT COUNT(*) FROM events", 0)
+	require.NoError(t, err)
+
+	d := dql.(*dataQL)
+	files, err := d.openFollowed()
+	require.NoError(t, err)
+	defer files[0].file.Close()
+
+	appendFile(t, path, `{"id":2,"level":"warn"}`+"\n"+`{"id":3,"level":"error"}`+"\n")
+	appended, err := d.importAppended(files)
+	require.NoError(t, err)
+	assert.Equal(t, 1, appended)
+
+	result, err := dql.Query("SELECT COUNT(*), CAST(SUM(id) AS BIGINT) FROM events", 0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, result.Rows[0][0])
+	assert.EqualValues(t, 6, result.Rows[0][1])
+
+	tables, err := dql.Query("SELECT name FROM schemas", 0)
+	require.NoError(t, err)
+	assert.Len(t, tables.Rows, 1, "staging tables should not be listed")
+}
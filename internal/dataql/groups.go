@@ -0,0 +1,183 @@
+package dataql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+// groupLimit is the number of groups profiled by --by; the largest groups come first
+const groupLimit = 50
+
+// GroupProfile holds the statistics of the rows sharing a value of the
+// --by column. Value is nil for the rows where the column is NULL.
+type GroupProfile struct {
+	Value   *string         `json:"value"`
+	Rows    int64           `json:"rows"`
+	Columns []ColumnProfile `json:"columns"`
+}
+
+// label renders the group value for the terminal and exports
+func (g GroupProfile) label() string {
+	switch {
+	case g.Value == nil:
+		return "(null)"
+	case *g.Value == "":
+		return "(empty)"
+	}
+	return *g.Value
+}
+
+// findColumn returns the column named name, ignoring case
+func findColumn(columns []ColumnProfile, name string) (ColumnProfile, bool) {
+	for _, col := range columns {
+		if strings.EqualFold(col.Name, name) {
+			return col, true
+		}
+	}
+	return ColumnProfile{}, false
+}
+
+// getGroupProfiles splits a table by the values of the by column and
+// collects the null count, distinct count, min/max and mean of every other
+// column within each group
+func (d *dataQL) getGroupProfiles(tableName, by string, columns []ColumnProfile) ([]GroupProfile, error) {
+	byColumn, ok := findColumn(columns, by)
+	if !ok {
+		return nil, fmt.Errorf("group column %s not found in table %s", by, tableName)
+	}
+	groupExpr := fmt.Sprintf("CAST(\"%s\" AS VARCHAR)", byColumn.Name)
+
+	rows, err := d.storage.Query(fmt.Sprintf("SELECT %s AS grp, COUNT(*) AS n FROM %s GROUP BY 1 ORDER BY n DESC, grp NULLS LAST LIMIT %d",
+		groupExpr, tableName, groupLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	var groups []GroupProfile
+	index := make(map[sql.NullString]int)
+	for rows.Next() {
+		var value sql.NullString
+		var group GroupProfile
+		if err := rows.Scan(&value, &group.Rows); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read groups: %w", err)
+		}
+		if value.Valid {
+			group.Value = &value.String
+		}
+		index[value] = len(groups)
+		groups = append(groups, group)
+	}
+	rows.Close()
+
+	for _, col := range columns {
+		if col.Name == byColumn.Name {
+			continue
+		}
+		if err := d.profileGroups(tableName, groupExpr, col, groups, index); err != nil {
+			return nil, fmt.Errorf("failed to profile %s by %s: %w", col.Name, byColumn.Name, err)
+		}
+	}
+
+	return groups, nil
+}
+
+// profileGroups appends the statistics of col within each group to the groups
+func (d *dataQL) profileGroups(tableName, groupExpr string, col ColumnProfile, groups []GroupProfile, index map[sql.NullString]int) error {
+	escapedColumn := fmt.Sprintf("\"%s\"", col.Name)
+
+	meanExpr := "NULL"
+	if isMeasureType(col.Type) {
+		meanExpr = fmt.Sprintf("CAST(AVG(%s) AS DOUBLE)", escapedColumn)
+	}
+
+	query := fmt.Sprintf("SELECT %s, COUNT(*) - COUNT(%s), COUNT(DISTINCT %s), MIN(%s), MAX(%s), %s FROM %s GROUP BY 1",
+		groupExpr, escapedColumn, escapedColumn, escapedColumn, escapedColumn, meanExpr, tableName)
+	rows, err := d.storage.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value sql.NullString
+		var mean sql.NullFloat64
+		stats := ColumnProfile{Name: col.Name, Type: col.Type}
+		if err := rows.Scan(&value, &stats.Nulls, &stats.Distinct, &stats.Min, &stats.Max, &mean); err != nil {
+			return err
+		}
+
+		i, ok := index[value]
+		if !ok {
+			continue // Beyond the group limit
+		}
+		if mean.Valid {
+			stats.Mean = &mean.Float64
+		}
+		// Keep text values readable when the profile is encoded as JSON
+		if b, ok := stats.Min.([]byte); ok {
+			stats.Min = string(b)
+		}
+		if b, ok := stats.Max.([]byte); ok {
+			stats.Max = string(b)
+		}
+		if groups[i].Rows > 0 {
+			stats.NullPct = float64(stats.Nulls) * 100 / float64(groups[i].Rows)
+		}
+		groups[i].Columns = append(groups[i].Columns, stats)
+	}
+
+	return rows.Err()
+}
+
+// printGroups prints, for every column of a table, one row of statistics
+// per value of the by column
+func (d *dataQL) printGroups(tableName, by string) error {
+	columns, err := d.getTableColumns(tableName)
+	if err != nil {
+		return err
+	}
+
+	groups, err := d.getGroupProfiles(tableName, by, columns)
+	if err != nil {
+		return err
+	}
+
+	sectionColor := color.New(color.FgCyan)
+	sectionColor.Fprintf(d.out, "\nGrouped by %s (%d groups):\n", by, len(groups))
+	if len(groups) == groupLimit {
+		fmt.Fprintf(d.out, "(only the %d largest groups are shown)\n", groupLimit)
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	for i, col := range groups[0].Columns {
+		fmt.Fprintf(d.out, "\n%s (%s)\n", color.YellowString(col.Name), col.Type)
+
+		tbl := table.New(by, "Rows", "Null %", "Distinct", "Min", "Max", "Mean").
+			WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+			WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+			WithWriter(d.out)
+
+		for _, group := range groups {
+			stats := group.Columns[i]
+			tbl.AddRow(
+				d.truncateValue(group.label()),
+				group.Rows,
+				fmt.Sprintf("%.1f%%", stats.NullPct),
+				stats.Distinct,
+				d.truncateValue(formatProfileValue(stats.Min)),
+				d.truncateValue(formatProfileValue(stats.Max)),
+				formatMean(stats.Mean),
+			)
+		}
+		tbl.Print()
+	}
+
+	return nil
+}
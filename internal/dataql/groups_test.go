@@ -0,0 +1,49 @@
+package dataql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile_Groups(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "sales.csv")
+	assert.NoError(t, os.WriteFile(csvPath, []byte("region,amount,product\neu,10,a\neu,30,b\nus,5,a\n,7,c\n"), 0644))
+
+	dql, err := New(Params{FileInputs: []string{csvPath}, Delimiter: ",", Quiet: true})
+	assert.NoError(t, err)
+	defer dql.Close()
+
+	tables, err := dql.Profile(DescribeOptions{By: "REGION"})
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+
+	groups := tables[0].Groups
+	assert.Equal(t, "REGION", tables[0].GroupBy)
+	assert.Len(t, groups, 3)
+
+	// The largest group comes first and the grouping column is left out
+	eu := groups[0]
+	assert.Equal(t, "eu", eu.label())
+	assert.Equal(t, int64(2), eu.Rows)
+	assert.Len(t, eu.Columns, 2)
+	assert.Equal(t, "amount", eu.Columns[0].Name)
+	assert.Equal(t, int64(10), eu.Columns[0].Min)
+	assert.Equal(t, 20.0, *eu.Columns[0].Mean)
+	assert.Equal(t, "b", eu.Columns[1].Max)
+	assert.Nil(t, eu.Columns[1].Mean)
+
+	// CSV files import a missing text value as an empty string
+	assert.Equal(t, "(empty)", groups[1].label())
+}
+
+func TestProfile_GroupsUnknownColumn(t *testing.T) {
+	dql, err := New(Params{FileInputs: []string{"../../tests/fixtures/csv/simple.csv"}, Delimiter: ",", Quiet: true})
+	assert.NoError(t, err)
+	defer dql.Close()
+
+	_, err = dql.Profile(DescribeOptions{By: "region"})
+	assert.ErrorContains(t, err, "group column region not found")
+}
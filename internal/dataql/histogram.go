@@ -0,0 +1,246 @@
+package dataql
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// defaultHistogramBins is the number of buckets when --bins is not set
+	defaultHistogramBins = 10
+	// histogramBarWidth is the length of the longest bar in the terminal
+	histogramBarWidth = 40
+)
+
+// HistogramBucket counts the values of a column within [Lower, Upper). The
+// last bucket includes its upper bound. Bounds are numbers for numeric
+// columns and formatted dates/timestamps for temporal columns.
+type HistogramBucket struct {
+	Lower interface{} `json:"lower"`
+	Upper interface{} `json:"upper"`
+	Count int64       `json:"count"`
+}
+
+// isTextType checks if a DuckDB data type holds strings
+func isTextType(dataType string) bool {
+	upperType := strings.ToUpper(dataType)
+	return upperType == "VARCHAR" || upperType == "TEXT" || upperType == "STRING"
+}
+
+// inferTemporalType returns DATE or TIMESTAMP when every non-empty value of a
+// text column parses as one (CSV files import dates as text), or "" otherwise
+func (d *dataQL) inferTemporalType(tableName, escapedColumn, dataType string) string {
+	if !isTextType(dataType) {
+		return ""
+	}
+
+	// Timestamps also cast to DATE, so values with a time of day are counted apart
+	dateExpr := fmt.Sprintf("TRY_CAST(%s AS DATE)", escapedColumn)
+	timestampExpr := fmt.Sprintf("TRY_CAST(%s AS TIMESTAMP)", escapedColumn)
+	query := fmt.Sprintf("SELECT COUNT(*), COUNT(%s), COUNT(%s), COUNT(*) FILTER (WHERE %s <> %s) FROM %s WHERE %s <> ''",
+		dateExpr, timestampExpr, timestampExpr, dateExpr, tableName, escapedColumn)
+	rows, err := d.storage.Query(query)
+	if err != nil {
+		return ""
+	}
+	defer rows.Close()
+
+	var total, dates, timestamps, withTime int64
+	if !rows.Next() || rows.Scan(&total, &dates, &timestamps, &withTime) != nil || total == 0 {
+		return ""
+	}
+	if timestamps == total && withTime > 0 {
+		return "TIMESTAMP"
+	}
+	if dates == total {
+		return "DATE"
+	}
+	if timestamps == total {
+		return "TIMESTAMP"
+	}
+	return ""
+}
+
+// histogramColumn returns the expression and type to bucket a column by:
+// the column itself for numeric and date types, or the column cast to the
+// inferred type for text holding dates. ok is false for other columns.
+func (d *dataQL) histogramColumn(tableName string, col ColumnProfile) (expr, dataType string, ok bool) {
+	escapedColumn := fmt.Sprintf("\"%s\"", col.Name)
+
+	upperType := strings.ToUpper(col.Type)
+	if strings.Contains(upperType, "INTERVAL") || upperType == "TIME" {
+		return "", "", false
+	}
+	if isNumericType(col.Type) || isDateTimeType(col.Type) {
+		return escapedColumn, col.Type, true
+	}
+	if inferred := d.inferTemporalType(tableName, escapedColumn, col.Type); inferred != "" {
+		return fmt.Sprintf("TRY_CAST(%s AS %s)", escapedColumn, inferred), inferred, true
+	}
+	return "", "", false
+}
+
+// getHistogram splits the range of a numeric or date column into bins
+// equal-width buckets and counts the non-null values in each. Other columns
+// have no histogram.
+func (d *dataQL) getHistogram(tableName string, col ColumnProfile, bins int) ([]HistogramBucket, error) {
+	if bins < 1 {
+		bins = defaultHistogramBins
+	}
+
+	columnExpr, dataType, ok := d.histogramColumn(tableName, col)
+	if !ok {
+		return nil, nil
+	}
+	temporal := isDateTimeType(dataType)
+
+	// Dates and timestamps are bucketed by their epoch seconds
+	valueExpr := fmt.Sprintf("CAST(%s AS DOUBLE)", columnExpr)
+	if temporal {
+		valueExpr = fmt.Sprintf("CAST(epoch(%s) AS DOUBLE)", columnExpr)
+	}
+	source := fmt.Sprintf("(SELECT %s AS v FROM %s WHERE %s IS NOT NULL) AS hist", valueExpr, tableName, columnExpr)
+
+	var minValue, maxValue *float64
+	rangeRows, err := d.storage.Query(fmt.Sprintf("SELECT MIN(v), MAX(v) FROM %s", source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get histogram range: %w", err)
+	}
+	if rangeRows.Next() {
+		if err := rangeRows.Scan(&minValue, &maxValue); err != nil {
+			rangeRows.Close()
+			return nil, fmt.Errorf("failed to read histogram range: %w", err)
+		}
+	}
+	rangeRows.Close()
+
+	if minValue == nil || maxValue == nil {
+		return nil, nil // Only NULL values
+	}
+
+	width := (*maxValue - *minValue) / float64(bins)
+	if width == 0 {
+		bins = 1 // Constant column: a single bucket holds every value
+	}
+
+	counts := make([]int64, bins)
+	bucketExpr := "0"
+	if width > 0 {
+		bucketExpr = fmt.Sprintf("LEAST(CAST(FLOOR((v - %v) / %v) AS BIGINT), %d)", *minValue, width, bins-1)
+	}
+	rows, err := d.storage.Query(fmt.Sprintf("SELECT %s AS bucket, COUNT(*) FROM %s GROUP BY 1", bucketExpr, source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute histogram: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket, count int64
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, fmt.Errorf("failed to read histogram: %w", err)
+		}
+		if bucket >= 0 && bucket < int64(bins) {
+			counts[bucket] = count
+		}
+	}
+
+	buckets := make([]HistogramBucket, bins)
+	for i := range buckets {
+		lower := *minValue + width*float64(i)
+		upper := lower + width
+		if i == bins-1 {
+			upper = *maxValue
+		}
+		buckets[i] = HistogramBucket{
+			Lower: histogramBound(lower, dataType, temporal),
+			Upper: histogramBound(upper, dataType, temporal),
+			Count: counts[i],
+		}
+	}
+
+	return buckets, rows.Err()
+}
+
+// histogramBound converts a bucket bound back to the column domain: epoch
+// seconds become a date or timestamp string
+func histogramBound(value float64, dataType string, temporal bool) interface{} {
+	if !temporal {
+		return value
+	}
+	t := time.Unix(0, int64(value*float64(time.Second))).UTC()
+	if strings.ToUpper(dataType) == "DATE" {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// formatBound renders a histogram bound for the terminal
+func formatBound(value interface{}) string {
+	if f, ok := value.(float64); ok {
+		s := strconv.FormatFloat(f, 'f', 2, 64)
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+		if s == "-0" {
+			s = "0"
+		}
+		return s
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// printHistograms prints a text bar chart for every numeric and date column of a table
+func (d *dataQL) printHistograms(tableName string, bins int) error {
+	columns, err := d.getTableColumns(tableName)
+	if err != nil {
+		return err
+	}
+
+	sectionColor := color.New(color.FgCyan)
+	sectionColor.Fprintf(d.out, "\nHistograms:\n")
+
+	for _, col := range columns {
+		buckets, err := d.getHistogram(tableName, col, bins)
+		if err != nil {
+			return fmt.Errorf("failed to compute histogram of %s: %w", col.Name, err)
+		}
+		if len(buckets) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(d.out, "\n%s (%s)\n", color.YellowString(col.Name), col.Type)
+		printBars(d.out, buckets)
+	}
+
+	return nil
+}
+
+// printBars prints one line per bucket with a bar proportional to its count
+func printBars(w io.Writer, buckets []HistogramBucket) {
+	labels := make([]string, len(buckets))
+	labelWidth := 0
+	var maxCount int64
+	for i, bucket := range buckets {
+		labels[i] = formatBound(bucket.Lower) + " - " + formatBound(bucket.Upper)
+		if len(labels[i]) > labelWidth {
+			labelWidth = len(labels[i])
+		}
+		if bucket.Count > maxCount {
+			maxCount = bucket.Count
+		}
+	}
+
+	for i, bucket := range buckets {
+		bar := 0
+		if maxCount > 0 {
+			bar = int(bucket.Count * histogramBarWidth / maxCount)
+		}
+		if bar == 0 && bucket.Count > 0 {
+			bar = 1 // Keep non-empty buckets visible
+		}
+		fmt.Fprintf(w, "  %-*s  %-*s %d\n", labelWidth, labels[i], histogramBarWidth, strings.Repeat("█", bar), bucket.Count)
+	}
+}
@@ -0,0 +1,59 @@
+package dataql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogramBound(t *testing.T) {
+	assert.Equal(t, 2.5, histogramBound(2.5, "DOUBLE", false))
+	assert.Equal(t, "2024-01-15", histogramBound(1705276800, "DATE", true))
+	assert.Equal(t, "2024-01-15 10:30:00", histogramBound(1705314600, "TIMESTAMP", true))
+}
+
+func TestFormatBound(t *testing.T) {
+	assert.Equal(t, "60", formatBound(60.0))
+	assert.Equal(t, "2.2", formatBound(2.2))
+	assert.Equal(t, "0.33", formatBound(1.0/3))
+	assert.Equal(t, "0", formatBound(-0.001))
+	assert.Equal(t, "2024-01-15", formatBound("2024-01-15"))
+}
+
+func TestProfile_Histogram(t *testing.T) {
+	dql, err := New(Params{
+		FileInputs: []string{"../../tests/fixtures/csv/dates_data.csv"},
+		Delimiter:  ",",
+		Quiet:      true,
+	})
+	assert.NoError(t, err)
+	defer dql.Close()
+
+	tables, err := dql.Profile(DescribeOptions{Histogram: true, Bins: 4})
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+
+	histograms := make(map[string][]HistogramBucket)
+	for _, col := range tables[0].Columns {
+		histograms[col.Name] = col.Histogram
+	}
+
+	// Text columns are only bucketed when they hold dates
+	assert.Empty(t, histograms["event_name"])
+	assert.Empty(t, histograms["event_time"])
+
+	id := histograms["id"]
+	assert.Len(t, id, 4)
+	var total int64
+	for _, bucket := range id {
+		total += bucket.Count
+	}
+	assert.Equal(t, tables[0].Rows, total)
+
+	assert.Equal(t, "2024-01-01", histograms["event_date"][0].Lower)
+
+	created := histograms["created_at"]
+	assert.Len(t, created, 4)
+	assert.Equal(t, "2023-12-15 10:30:00", created[0].Lower)
+	assert.Equal(t, "2024-07-10 12:00:00", created[3].Upper)
+}
@@ -0,0 +1,73 @@
+package dataql
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/history"
+	"github.com/adrianolaselva/dataql/pkg/metrics"
+)
+
+// openHistory opens the query history configured by the run command, or
+// returns nil when the history is off. A history that cannot be opened is
+// reported but does not prevent running queries.
+func openHistory(path string) *history.Store {
+	if path == "" {
+		return nil
+	}
+	store, err := history.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+	return store
+}
+
+// recordQuery records an executed statement in the audit log, the query
+// history and the metrics
+func (d *dataQL) recordQuery(query string, rows int64, start time.Time, queryErr error) {
+	d.auditQuery(query, rows, start, queryErr)
+	d.addHistory(query, rows, start, queryErr)
+	metrics.ObserveQuery(time.Since(start), queryErr)
+}
+
+// addHistory records an executed statement in the query history, if enabled.
+// A failure to write the history is reported but does not fail the query.
+func (d *dataQL) addHistory(query string, rows int64, start time.Time, queryErr error) {
+	if d.history == nil {
+		return
+	}
+
+	entry := history.Entry{
+		Timestamp:  start,
+		Caller:     history.CallerREPL,
+		Directory:  d.historyDir,
+		Sources:    d.historySources,
+		Storage:    d.params.DataSourceName,
+		Delimiter:  d.params.Delimiter,
+		Collection: d.params.Collection,
+		Query:      query,
+		Rows:       rows,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if d.params.Query != "" {
+		entry.Caller = history.CallerCLI
+	}
+	if queryErr != nil {
+		entry.Error = queryErr.Error()
+	}
+
+	if _, err := d.history.Add(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// workingDir returns the current directory, or "" when it cannot be determined
+func workingDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
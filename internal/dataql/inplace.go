@@ -0,0 +1,256 @@
+package dataql
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/auditlog"
+	"github.com/adrianolaselva/dataql/pkg/azurehandler"
+	"github.com/adrianolaselva/dataql/pkg/clierror"
+	"github.com/adrianolaselva/dataql/pkg/extensions"
+	"github.com/adrianolaselva/dataql/pkg/gcshandler"
+	"github.com/adrianolaselva/dataql/pkg/s3handler"
+	"github.com/adrianolaselva/dataql/pkg/urlhandler"
+)
+
+// remoteSource is a source read in place by DuckDB's httpfs extension
+// with --in-place, instead of being downloaded and imported
+type remoteSource struct {
+	URL    string
+	Name   string // URL as given by the user, with passwords redacted
+	Format string // parquet or csv
+	Table  string
+}
+
+// CheckInPlace rejects the options --in-place cannot honor: httpfs reads
+// the sources when they are queried, and uses its own credentials
+func CheckInPlace(params Params) error {
+	if !params.InPlace {
+		return nil
+	}
+	unsupported := map[string]bool{
+		"--no-external-access": params.NoExternalAccess,
+		"--cookie-jar":         params.CookieJar != "",
+		"--s3-profile":         params.S3Profile != "",
+		"--s3-assume-role":     params.S3AssumeRole != "",
+		"--s3-requester-pays":  params.S3RequesterPays,
+		"--gcs-credentials":    params.GCSCredentials != "",
+	}
+	for _, option := range []string{"--no-external-access", "--cookie-jar", "--s3-profile", "--s3-assume-role", "--s3-requester-pays", "--gcs-credentials"} {
+		if unsupported[option] {
+			return fmt.Errorf("--in-place cannot be combined with %s", option)
+		}
+	}
+	return nil
+}
+
+// CheckSample rejects the remote files a sample of params.Lines rows would
+// download in full. Parquet and CSV files are read in place, fetching only
+// the row groups and bytes the sample needs; other formats are parsed from a
+// local copy, so the whole object would be downloaded for the first rows.
+func CheckSample(params Params) error {
+	for _, source := range GetPaths(ParseFileInputs(params.FileInputs)) {
+		if !urlhandler.IsURL(source) && !s3handler.IsS3URL(source) && !gcshandler.IsGCSURL(source) && !azurehandler.IsAzureURL(source) {
+			continue
+		}
+		name := auditlog.RedactSources([]string{source})[0]
+		if !params.InPlace || inPlaceFormat(source, params.InputFormat) == "" {
+			return fmt.Errorf("--sample cannot read %s without downloading it in full: only remote Parquet and CSV files are read in place; download it first or profile every row", name)
+		}
+	}
+	return nil
+}
+
+// inPlaceFormat returns the format httpfs reads source in, or an empty
+// string when source is not a remote Parquet or CSV source
+func inPlaceFormat(source, inputFormat string) string {
+	if !urlhandler.IsURL(source) && !s3handler.IsS3URL(source) && !gcshandler.IsGCSURL(source) {
+		return ""
+	}
+	if inputFormat == "parquet" || inputFormat == "csv" {
+		return inputFormat
+	}
+	if inputFormat != "" {
+		return ""
+	}
+
+	name := strings.ToLower(remoteName(source))
+	switch {
+	case strings.HasSuffix(name, ".parquet"):
+		return "parquet"
+	case strings.HasSuffix(name, ".csv"), strings.HasSuffix(name, ".tsv"), strings.HasSuffix(name, ".csv.gz"):
+		return "csv"
+	}
+	return ""
+}
+
+// remoteName returns the object name of a remote source, without the
+// query string of signed URLs
+func remoteName(source string) string {
+	if u, err := url.Parse(source); err == nil {
+		return path.Base(u.Path)
+	}
+	return path.Base(source)
+}
+
+// splitInPlace separates the remote Parquet and CSV sources read in place
+// from the sources that are downloaded and imported, along with their
+// names. Tables are named like the imported ones: after the alias, then the
+// collection, then the file.
+func splitInPlace(paths, names []string, aliases map[string]string, params Params) ([]string, []string, []remoteSource) {
+	if !params.InPlace {
+		return paths, names, nil
+	}
+	local := make([]string, 0, len(paths))
+	localNames := make([]string, 0, len(names))
+	var remote []remoteSource
+	for i, source := range paths {
+		format := inPlaceFormat(source, params.InputFormat)
+		if format == "" {
+			local = append(local, source)
+			localNames = append(localNames, names[i])
+			continue
+		}
+		table := aliases[source]
+		if table == "" {
+			table = params.Collection
+		}
+		if table == "" {
+			table = strings.TrimSuffix(remoteName(source), ".gz")
+			table = strings.TrimSuffix(table, path.Ext(table))
+		}
+		table = strings.ReplaceAll(strings.ToLower(table), " ", "_")
+		table = nonIdentifier.ReplaceAllString(table, "")
+		remote = append(remote, remoteSource{URL: source, Name: names[i], Format: format, Table: table})
+	}
+	return local, localNames, remote
+}
+
+// viewStatement returns the statement creating the view of a remote
+// source. Views keep the data remote, so httpfs fetches only the row
+// groups and columns each query needs.
+func viewStatement(source remoteSource, params Params) string {
+	reader := fmt.Sprintf("read_parquet(%s)", sqlString(source.URL))
+	if source.Format == "csv" {
+		options := ", header = true"
+		if params.Delimiter != "" && params.Delimiter != "," {
+			options += ", delim = " + sqlString(params.Delimiter)
+		}
+		reader = fmt.Sprintf("read_csv(%s%s)", sqlString(source.URL), options)
+	}
+	statement := fmt.Sprintf(`CREATE OR REPLACE VIEW "%s" AS SELECT * FROM %s`, source.Table, reader)
+	if params.Lines > 0 {
+		statement += fmt.Sprintf(" LIMIT %d", params.Lines)
+	}
+	return statement
+}
+
+// httpfsStatements returns the statements passing the headers, TLS and S3
+// options of params to httpfs. S3 credentials are read from the AWS_*
+// environment variables, as httpfs does not use the AWS configuration files.
+func httpfsStatements(params Params, remote []remoteSource) ([]string, error) {
+	var statements []string
+
+	headers, err := urlhandler.ParseHeaders(params.Headers)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		names := make([]string, 0, len(headers))
+		for name := range headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		entries := make([]string, 0, len(names))
+		for _, name := range names {
+			entries = append(entries, sqlString(name)+": "+sqlString(headers.Get(name)))
+		}
+		statements = append(statements, fmt.Sprintf("CREATE OR REPLACE SECRET dataql_http (TYPE HTTP, EXTRA_HTTP_HEADERS MAP {%s})", strings.Join(entries, ", ")))
+	}
+
+	if params.CACert != "" {
+		statements = append(statements, "SET ca_cert_file = "+sqlString(params.CACert), "SET enable_server_cert_verification = true")
+	}
+	if params.InsecureTLS {
+		statements = append(statements, "SET enable_server_cert_verification = false")
+	}
+
+	readsS3 := false
+	for _, source := range remote {
+		readsS3 = readsS3 || s3handler.IsS3URL(source.URL)
+	}
+	if !readsS3 {
+		return statements, nil
+	}
+
+	options := []string{"TYPE S3"}
+	for _, credential := range [][2]string{{"KEY_ID", "AWS_ACCESS_KEY_ID"}, {"SECRET", "AWS_SECRET_ACCESS_KEY"}, {"SESSION_TOKEN", "AWS_SESSION_TOKEN"}} {
+		if value := os.Getenv(credential[1]); value != "" {
+			options = append(options, credential[0]+" "+sqlString(value))
+		}
+	}
+	region := firstSet(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))
+	if params.S3Endpoint != "" {
+		endpoint, err := url.Parse(params.S3Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --s3-endpoint %q: %w", params.S3Endpoint, err)
+		}
+		// Custom endpoints, such as MinIO, expect path-style requests
+		options = append(options, "ENDPOINT "+sqlString(endpoint.Host), "URL_STYLE 'path'", fmt.Sprintf("USE_SSL %t", endpoint.Scheme == "https"))
+		region = firstSet(region, "us-east-1")
+	}
+	if region != "" {
+		options = append(options, "REGION "+sqlString(region))
+	}
+	statements = append(statements, fmt.Sprintf("CREATE OR REPLACE SECRET dataql_s3 (%s)", strings.Join(options, ", ")))
+	return statements, nil
+}
+
+// readInPlace loads httpfs and creates the views of the sources read in
+// place
+func (d *dataQL) readInPlace() error {
+	if len(d.remote) == 0 {
+		return nil
+	}
+	verboseLog(d.params.Verbose, "Loading httpfs to read %d remote sources in place...", len(d.remote))
+	if err := extensions.Load(d.storage, []string{"httpfs"}, d.params.OfflineExtensions); err != nil {
+		return fmt.Errorf("--in-place requires the httpfs extension: %w", err)
+	}
+
+	statements, err := httpfsStatements(d.params, d.remote)
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if err := execStatement(d.storage, statement); err != nil {
+			return fmt.Errorf("failed to configure httpfs: %w", err)
+		}
+	}
+
+	for _, source := range d.remote {
+		verboseLog(d.params.Verbose, "Reading %s in place as %s", source.Name, source.Table)
+		if err := execStatement(d.storage, viewStatement(source, d.params)); err != nil {
+			return clierror.Source(source.Name, fmt.Errorf("failed to read %s in place: %w", source.Name, err))
+		}
+	}
+	return nil
+}
+
+// sqlString returns value as a SQL string literal
+func sqlString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// firstSet returns the first non-empty value
+func firstSet(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
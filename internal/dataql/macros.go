@@ -0,0 +1,162 @@
+package dataql
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/config"
+)
+
+var (
+	// macroStatementPattern matches CREATE [OR REPLACE] [TEMP] MACRO and captures the definition
+	macroStatementPattern = regexp.MustCompile(`(?is)^CREATE\s+(?:OR\s+REPLACE\s+)?(?:TEMP\s+|TEMPORARY\s+)?(?:MACRO|FUNCTION)\s+(.+)$`)
+	// inlineMacroPattern matches the name(args) key of a macro defined in the config file
+	inlineMacroPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*\s*\([a-z0-9_,\s]*\)$`)
+)
+
+// macro is a CREATE MACRO statement and where it was defined
+type macro struct {
+	origin    string
+	statement string
+}
+
+// loadMacros creates the macros of the [macros] section of the config file,
+// so queries can share expressions such as clean_phone(x). Macros live in the
+// database: a --storage file keeps them. A macro that fails is reported and
+// skipped; a broken config file is reported by loadConfig.
+func (d *dataQL) loadMacros() {
+	path := config.DefaultPath()
+	cfg, err := config.Load(path)
+	if err != nil {
+		return
+	}
+
+	macros := configMacros(cfg, filepath.Dir(path))
+	created := 0
+	for _, m := range macros {
+		rows, err := d.storage.Query(m.statement)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", m.origin, err)
+			continue
+		}
+		_ = rows.Close()
+		created++
+	}
+	if len(macros) > 0 {
+		verboseLog(d.params.Verbose, "Created %d macros from %s", created, path)
+	}
+}
+
+// configMacros returns the macros of the macro files followed by the ones
+// defined inline, which take precedence. Relative files are resolved against dir.
+func configMacros(cfg *config.Config, dir string) []macro {
+	var macros []macro
+
+	for _, file := range cfg.MacroFiles() {
+		file = resolveConfigPath(file, dir)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read macro file: %v\n", err)
+			continue
+		}
+
+		for i, statement := range splitStatements(string(data)) {
+			origin := fmt.Sprintf("%s (statement %d)", file, i+1)
+			match := macroStatementPattern.FindStringSubmatch(statement)
+			if match == nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s: only CREATE MACRO statements are allowed in macro files\n", origin)
+				continue
+			}
+			// Replacing keeps a --storage file that already holds the macro working
+			macros = append(macros, macro{origin: origin, statement: "CREATE OR REPLACE MACRO " + match[1]})
+		}
+	}
+
+	inline := cfg.Macros()
+	names := make([]string, 0, len(inline))
+	for name := range inline {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		origin := "macro " + name
+		if !inlineMacroPattern.MatchString(name) {
+			fmt.Fprintf(os.Stderr, "Warning: %s: expected name(arguments) = expression\n", origin)
+			continue
+		}
+		macros = append(macros, macro{origin: origin, statement: fmt.Sprintf("CREATE OR REPLACE MACRO %s AS %s", name, inline[name])})
+	}
+
+	return macros
+}
+
+// resolveConfigPath expands a leading ~ and resolves a relative path against dir
+func resolveConfigPath(path, dir string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, path[1:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return path
+}
+
+// splitStatements splits SQL text on the semicolons outside quotes and
+// drops the comments
+func splitStatements(text string) []string {
+	var statements []string
+	var current strings.Builder
+
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\'' || c == '"':
+			// A doubled quote inside the literal closes and reopens it
+			end := strings.IndexByte(text[i+1:], c)
+			if end < 0 {
+				current.WriteString(text[i:])
+				i = len(text)
+				continue
+			}
+			current.WriteString(text[i : i+end+2])
+			i += end + 1
+		case c == '-' && strings.HasPrefix(text[i:], "--"):
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				i = len(text)
+				continue
+			}
+			current.WriteByte('\n')
+			i += end
+		case c == '/' && strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				i = len(text)
+				continue
+			}
+			current.WriteByte(' ')
+			i += end + 3
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	return statements
+}
@@ -0,0 +1,68 @@
+package dataql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatements(t *testing.T) {
+	statements := splitStatements(`-- helpers; shared by the team
+CREATE MACRO a(x) AS x || ';';
+/* block; comment */ CREATE MACRO b(x) AS "x;y" + 1 ;
+CREATE MACRO c() AS 'it''s; fine'
+;;`)
+	assert.Equal(t, []string{
+		"CREATE MACRO a(x) AS x || ';'",
+		`CREATE MACRO b(x) AS "x;y" + 1`,
+		"CREATE MACRO c() AS 'it''s; fine'",
+	}, statements)
+}
+
+func TestLoadMacros(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "macros.sql"), []byte(`
+CREATE MACRO fiscal_quarter(d) AS 'Q' || quarter(CAST(d AS DATE) + INTERVAL 3 MONTH);
+CREATE TEMP MACRO domain(email) AS split_part(email, '@', 2);
+DROP TABLE simple;
+`), 0644))
+	configPath := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configPath, []byte(`[macros]
+files = macros.sql
+clean_phone(x) = regexp_replace(x, '[^0-9]', '', 'g')
+upper_name(key) = (SELECT upper(name) FROM simple WHERE id = key)
+`), 0644))
+	t.Setenv(config.EnvConfigPath, configPath)
+
+	dql, err := New(Params{
+		FileInputs: []string{"../../tests/fixtures/csv/simple.csv"},
+		Delimiter:  ",",
+		Quiet:      true,
+	})
+	require.NoError(t, err)
+	defer dql.Close()
+
+	result, err := dql.Query(`SELECT clean_phone('(555) 123-4567'), fiscal_quarter(DATE '2024-11-02'),
+		domain(email), upper_name(2), COUNT(*) OVER () FROM simple ORDER BY id LIMIT 1`, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"5551234567", "Q1", "example.com", "JANE", int64(3)}, result.Rows[0])
+}
+
+func TestConfigMacros_Invalid(t *testing.T) {
+	cfg, err := config.Load(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, configMacros(cfg, t.TempDir()))
+
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte("[macros]\nfiles = missing.sql\nbad name = 1\nok(x) = x + 1\n"), 0644))
+	cfg, err = config.Load(path)
+	require.NoError(t, err)
+
+	macros := configMacros(cfg, filepath.Dir(path))
+	require.Len(t, macros, 1)
+	assert.Equal(t, "CREATE OR REPLACE MACRO ok(x) AS x + 1", macros[0].statement)
+}
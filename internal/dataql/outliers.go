@@ -0,0 +1,149 @@
+package dataql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+)
+
+const (
+	// outlierIQRFactor places the fences at Q1 - 1.5*IQR and Q3 + 1.5*IQR (Tukey's rule)
+	outlierIQRFactor = 1.5
+	// outlierZScore flags values more than 3 standard deviations from the mean
+	outlierZScore = 3.0
+	// outlierExamplesLimit is the number of example rows reported per column
+	outlierExamplesLimit = 5
+)
+
+// OutlierReport counts the values of a numeric column beyond the IQR fences
+// or the z-score threshold and keeps the most extreme rows as examples
+type OutlierReport struct {
+	Q1          float64      `json:"q1"`
+	Q3          float64      `json:"q3"`
+	LowerFence  float64      `json:"lower_fence"`
+	UpperFence  float64      `json:"upper_fence"`
+	IQRCount    int64        `json:"iqr_outliers"`
+	ZScoreCount int64        `json:"zscore_outliers"` // 0 when the column has no spread
+	Examples    *QueryResult `json:"examples,omitempty"`
+}
+
+// isMeasureType checks if a column holds numbers (DuckDB INTERVAL names contain "INT")
+func isMeasureType(dataType string) bool {
+	return isNumericType(dataType) && !isDateTimeType(dataType)
+}
+
+// getOutliers detects the outliers of a numeric column; other columns, and
+// columns with only NULL values, have no report
+func (d *dataQL) getOutliers(tableName string, col ColumnProfile) (*OutlierReport, error) {
+	if !isMeasureType(col.Type) {
+		return nil, nil
+	}
+
+	escapedColumn := fmt.Sprintf("\"%s\"", col.Name)
+	valueExpr := fmt.Sprintf("CAST(%s AS DOUBLE)", escapedColumn)
+
+	statsQuery := fmt.Sprintf(`SELECT quantile_cont(%s, 0.25), quantile_cont(%s, 0.75), AVG(%s), STDDEV_SAMP(%s)
+		FROM %s WHERE %s IS NOT NULL`, valueExpr, valueExpr, valueExpr, valueExpr, tableName, escapedColumn)
+	rows, err := d.storage.Query(statsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quartiles: %w", err)
+	}
+	var q1, q3, mean, stddev sql.NullFloat64
+	if rows.Next() {
+		if err := rows.Scan(&q1, &q3, &mean, &stddev); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read quartiles: %w", err)
+		}
+	}
+	rows.Close()
+
+	if !q1.Valid || !q3.Valid {
+		return nil, nil // Only NULL values
+	}
+
+	iqr := q3.Float64 - q1.Float64
+	report := &OutlierReport{
+		Q1:         q1.Float64,
+		Q3:         q3.Float64,
+		LowerFence: q1.Float64 - outlierIQRFactor*iqr,
+		UpperFence: q3.Float64 + outlierIQRFactor*iqr,
+	}
+
+	iqrCondition := fmt.Sprintf("(%s < %v OR %s > %v)", valueExpr, report.LowerFence, valueExpr, report.UpperFence)
+	zCondition := "FALSE"
+	if stddev.Valid && stddev.Float64 > 0 {
+		zCondition = fmt.Sprintf("(ABS(%s - %v) > %v)", valueExpr, mean.Float64, outlierZScore*stddev.Float64)
+	}
+
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FILTER (WHERE %s), COUNT(*) FILTER (WHERE %s) FROM %s",
+		iqrCondition, zCondition, tableName)
+	countRows, err := d.storage.Query(countQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count outliers: %w", err)
+	}
+	if countRows.Next() {
+		if err := countRows.Scan(&report.IQRCount, &report.ZScoreCount); err != nil {
+			countRows.Close()
+			return nil, fmt.Errorf("failed to read outlier counts: %w", err)
+		}
+	}
+	countRows.Close()
+
+	if report.IQRCount == 0 && report.ZScoreCount == 0 {
+		return report, nil
+	}
+
+	// The rows furthest from the mean come first
+	examplesQuery := fmt.Sprintf("SELECT * FROM %s WHERE %s OR %s ORDER BY ABS(%s - %v) DESC LIMIT %d",
+		tableName, iqrCondition, zCondition, valueExpr, mean.Float64, outlierExamplesLimit)
+	if report.Examples, err = d.queryResult(examplesQuery, outlierExamplesLimit); err != nil {
+		return nil, fmt.Errorf("failed to get outlier rows: %w", err)
+	}
+
+	return report, nil
+}
+
+// printOutliers prints the outlier counts and example rows of every numeric column of a table
+func (d *dataQL) printOutliers(tableName string) error {
+	columns, err := d.getTableColumns(tableName)
+	if err != nil {
+		return err
+	}
+
+	sectionColor := color.New(color.FgCyan)
+	sectionColor.Fprintf(d.out, "\nOutliers (IQR x%.1f, |z| > %.0f):\n", outlierIQRFactor, outlierZScore)
+
+	found := false
+	for _, col := range columns {
+		report, err := d.getOutliers(tableName, col)
+		if err != nil {
+			return fmt.Errorf("failed to detect outliers of %s: %w", col.Name, err)
+		}
+		if report == nil || report.Examples == nil {
+			continue
+		}
+		found = true
+
+		fmt.Fprintf(d.out, "\n%s (%s): %d outside [%s, %s], %d by z-score\n", color.YellowString(col.Name), col.Type,
+			report.IQRCount, formatBound(report.LowerFence), formatBound(report.UpperFence), report.ZScoreCount)
+
+		cols := make([]interface{}, len(report.Examples.Columns))
+		for i, c := range report.Examples.Columns {
+			cols[i] = c
+		}
+		tbl := table.New(cols...).
+			WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+			WithWriter(d.out)
+		for _, row := range report.Examples.Rows {
+			tbl.AddRow(d.truncateValues(row)...)
+		}
+		tbl.Print()
+	}
+
+	if !found {
+		fmt.Fprintln(d.out, "\nNo outliers found.")
+	}
+	return nil
+}
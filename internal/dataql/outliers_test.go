@@ -0,0 +1,50 @@
+package dataql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMeasureType(t *testing.T) {
+	assert.True(t, isMeasureType("BIGINT"))
+	assert.True(t, isMeasureType("DECIMAL(10,2)"))
+	assert.False(t, isMeasureType("INTERVAL"))
+	assert.False(t, isMeasureType("VARCHAR"))
+}
+
+func TestProfile_Outliers(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "readings.csv")
+	content := "id,sensor,reading\n1,a,20\n2,a,21\n3,b,19\n4,b,22\n5,a,20\n6,b,950\n7,a,21\n8,b,-40\n9,a,20\n10,b,19\n"
+	assert.NoError(t, os.WriteFile(csvPath, []byte(content), 0644))
+
+	dql, err := New(Params{FileInputs: []string{csvPath}, Delimiter: ",", Quiet: true})
+	assert.NoError(t, err)
+	defer dql.Close()
+
+	tables, err := dql.Profile(DescribeOptions{Outliers: true})
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+
+	columns := tables[0].Columns
+	assert.Nil(t, columns[1].Outliers, "text columns have no outliers")
+
+	// Evenly spread ids have no outliers and no examples
+	id := columns[0].Outliers
+	assert.NotNil(t, id)
+	assert.Zero(t, id.IQRCount)
+	assert.Nil(t, id.Examples)
+
+	reading := columns[2].Outliers
+	assert.NotNil(t, reading)
+	assert.Equal(t, int64(2), reading.IQRCount)
+	assert.Equal(t, 19.25, reading.Q1)
+	assert.Equal(t, 21.0, reading.Q3)
+	assert.NotNil(t, reading.Examples)
+	assert.Equal(t, []string{"id", "sensor", "reading"}, reading.Examples.Columns)
+	// The most extreme row comes first
+	assert.Equal(t, []interface{}{int64(6), "b", int64(950)}, reading.Examples.Rows[0])
+	assert.Len(t, reading.Examples.Rows, 2)
+}
@@ -0,0 +1,282 @@
+package dataql
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/internal/exportdata"
+	"github.com/fatih/color"
+)
+
+// Output modes supported by the REPL .mode command
+const (
+	OutputModeTable    = "table"
+	OutputModeJSON     = "json"
+	OutputModeJSONL    = "jsonl"
+	OutputModeCSV      = "csv"
+	OutputModeMarkdown = "markdown"
+	OutputModeVertical = "vertical"
+)
+
+// outputModes lists the valid output modes in display order
+var outputModes = []string{
+	OutputModeTable,
+	OutputModeJSON,
+	OutputModeJSONL,
+	OutputModeCSV,
+	OutputModeMarkdown,
+	OutputModeVertical,
+}
+
+// IsValidOutputMode checks if the given mode is a supported output mode
+func IsValidOutputMode(mode string) bool {
+	for _, m := range outputModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseOutputMode returns the output mode named mode, accepting md for
+// markdown
+func ParseOutputMode(mode string) (string, error) {
+	mode = strings.ToLower(mode)
+	if mode == "md" {
+		mode = OutputModeMarkdown
+	}
+	if !IsValidOutputMode(mode) {
+		return "", fmt.Errorf("invalid mode: %s (use %s)", mode, strings.Join(outputModes, ", "))
+	}
+	return mode, nil
+}
+
+// applyPlain returns params with everything --plain leaves out turned off:
+// progress, the table list and colors. Row counts and confirmations are
+// skipped where they are printed.
+func applyPlain(params Params) Params {
+	if params.Plain {
+		params.Quiet = true
+		params.NoSchema = true
+		color.NoColor = true
+	}
+	return params
+}
+
+// currentOutputMode returns the active output mode, taking the vertical toggle into account
+func (d *dataQL) currentOutputMode() string {
+	if d.vertical {
+		return OutputModeVertical
+	}
+	if d.outputMode == "" {
+		return OutputModeTable
+	}
+	return d.outputMode
+}
+
+// setOutputMode changes the active output mode
+func (d *dataQL) setOutputMode(mode string) error {
+	mode, err := ParseOutputMode(mode)
+	if err != nil {
+		return err
+	}
+
+	// Vertical display is kept as a separate toggle so that .vertical and \G keep working
+	d.vertical = mode == OutputModeVertical
+	if mode != OutputModeVertical {
+		d.outputMode = mode
+	}
+
+	return nil
+}
+
+// scanRow reads the current row into a slice of values, with DECIMAL and
+// HUGEINT values as exact json.Number values
+func scanRow(rows *sql.Rows, columnCount int) ([]interface{}, error) {
+	values := make([]interface{}, columnCount)
+	pointers := make([]interface{}, columnCount)
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, fmt.Errorf("failed to read row: %w", err)
+	}
+	for i := range values {
+		values[i] = exportdata.ExactValue(values[i], false)
+	}
+
+	return values, nil
+}
+
+// rowToMap converts a row into a column -> value map suitable for JSON encoding
+func (d *dataQL) rowToMap(columns []string, values []interface{}) map[string]interface{} {
+	row := make(map[string]interface{}, len(columns))
+	for i, c := range columns {
+		if b, ok := values[i].([]byte); ok {
+			row[c] = string(b)
+			continue
+		}
+		row[c] = exportdata.ExactValue(values[i], d.params.DecimalAsString)
+	}
+	return row
+}
+
+// formatValue converts a value to its string representation (NULL becomes empty)
+func formatValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// printJSONRows prints all rows as a single JSON array
+func (d *dataQL) printJSONRows(rows *sql.Rows, columns []string) (int, error) {
+	types, err := exportdata.ColumnTypes(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	// Rows are held to be encoded at once; past --max-result-memory, they
+	// are written as they are read instead, in the same format
+	limit := d.maxResultMemory()
+	var buffered int64
+	var stream *jsonArrayWriter
+	data := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		values, err := d.readRow(rows, types)
+		if err != nil {
+			if stream != nil {
+				return stream.rows, err
+			}
+			return len(data), err
+		}
+		values = d.truncateValues(values)
+		row := d.rowToMap(columns, values)
+		if stream != nil {
+			if err := stream.write(row); err != nil {
+				return stream.rows, fmt.Errorf("failed to encode JSON: %w", err)
+			}
+			continue
+		}
+
+		data = append(data, row)
+		buffered += rowSize(values)
+		if buffered > limit {
+			stream = &jsonArrayWriter{w: d.out}
+			for _, row := range data {
+				if err := stream.write(row); err != nil {
+					return stream.rows, fmt.Errorf("failed to encode JSON: %w", err)
+				}
+			}
+			data = nil
+		}
+	}
+	if stream != nil {
+		return stream.rows, stream.close()
+	}
+
+	encoder := json.NewEncoder(d.out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return len(data), fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	return len(data), nil
+}
+
+// printJSONLRows prints one JSON object per line
+func (d *dataQL) printJSONLRows(rows *sql.Rows, columns []string) (int, error) {
+	types, err := exportdata.ColumnTypes(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	encoder := json.NewEncoder(d.out)
+	rowCount := 0
+	for rows.Next() {
+		values, err := d.readRow(rows, types)
+		if err != nil {
+			return rowCount, err
+		}
+		if err := encoder.Encode(d.rowToMap(columns, d.truncateValues(values))); err != nil {
+			return rowCount, fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		rowCount++
+	}
+
+	return rowCount, nil
+}
+
+// printCSVRows prints rows as CSV with a header line
+func (d *dataQL) printCSVRows(rows *sql.Rows, columns []string) (int, error) {
+	types, err := exportdata.ColumnTypes(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	w := csv.NewWriter(d.out)
+	defer w.Flush()
+
+	if err := w.Write(columns); err != nil {
+		return 0, fmt.Errorf("failed to write headers: %w", err)
+	}
+
+	rowCount := 0
+	for rows.Next() {
+		values, err := d.readRow(rows, types)
+		if err != nil {
+			return rowCount, err
+		}
+
+		record := make([]string, len(values))
+		for i, v := range d.truncateValues(values) {
+			record[i] = formatValue(v)
+		}
+		if err := w.Write(record); err != nil {
+			return rowCount, fmt.Errorf("failed to write row: %w", err)
+		}
+		rowCount++
+	}
+
+	return rowCount, nil
+}
+
+// printMarkdownRows prints rows as a Markdown table
+func (d *dataQL) printMarkdownRows(rows *sql.Rows, columns []string) (int, error) {
+	types, err := exportdata.ColumnTypes(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	fmt.Fprintln(d.out, "| "+strings.Join(columns, " | ")+" |")
+	fmt.Fprintln(d.out, "| "+strings.Join(separators, " | ")+" |")
+
+	rowCount := 0
+	for rows.Next() {
+		values, err := d.readRow(rows, types)
+		if err != nil {
+			return rowCount, err
+		}
+
+		cells := make([]string, len(values))
+		for i, v := range d.truncateValues(values) {
+			// Escape pipe characters so they don't break the table layout
+			cells[i] = strings.ReplaceAll(formatValue(v), "|", "\\|")
+		}
+		fmt.Fprintln(d.out, "| "+strings.Join(cells, " | ")+" |")
+		rowCount++
+	}
+
+	return rowCount, nil
+}
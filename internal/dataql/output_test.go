@@ -0,0 +1,35 @@
+package dataql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOutputMode(t *testing.T) {
+	d := &dataQL{}
+	assert.Equal(t, OutputModeTable, d.currentOutputMode())
+
+	assert.NoError(t, d.setOutputMode("JSON"))
+	assert.Equal(t, OutputModeJSON, d.currentOutputMode())
+
+	assert.NoError(t, d.setOutputMode("md"))
+	assert.Equal(t, OutputModeMarkdown, d.currentOutputMode())
+
+	assert.NoError(t, d.setOutputMode("vertical"))
+	assert.True(t, d.vertical)
+	assert.Equal(t, OutputModeVertical, d.currentOutputMode())
+
+	assert.NoError(t, d.setOutputMode("table"))
+	assert.False(t, d.vertical)
+	assert.Equal(t, OutputModeTable, d.currentOutputMode())
+
+	assert.Error(t, d.setOutputMode("xml"))
+	assert.Equal(t, OutputModeTable, d.currentOutputMode())
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "", formatValue(nil))
+	assert.Equal(t, "abc", formatValue([]byte("abc")))
+	assert.Equal(t, "42", formatValue(int64(42)))
+}
@@ -0,0 +1,91 @@
+package dataql
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/chzyer/readline"
+)
+
+const (
+	// pagerOff disables the external pager when used with .pager
+	pagerOff = "off"
+	// defaultPager is used when $PAGER is not set; -S enables horizontal scrolling
+	// and -R keeps the colors of the table output
+	defaultPager = "less -S -R"
+)
+
+// pagerCommand returns the external pager command to use, or nil if results
+// should be paged with the built-in Enter-to-continue pagination
+func (d *dataQL) pagerCommand() []string {
+	if strings.EqualFold(d.pager, pagerOff) {
+		return nil
+	}
+
+	// A pager only makes sense when writing to an interactive terminal
+	if d.out != (stdout{}) || !readline.IsTerminal(int(os.Stdout.Fd())) || !readline.IsTerminal(int(os.Stdin.Fd())) {
+		return nil
+	}
+
+	return resolvePager(d.pager, os.Getenv("PAGER"))
+}
+
+// resolvePager picks the pager command from the REPL setting, $PAGER, or the default,
+// returning nil if the resulting program cannot be found
+func resolvePager(setting, env string) []string {
+	command := setting
+	if command == "" {
+		command = env
+	}
+	if command == "" {
+		command = defaultPager
+	}
+
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath(fields[0]); err != nil {
+		return nil
+	}
+
+	return fields
+}
+
+// printWithPager renders all rows and sends them through the pager when they
+// don't fit on the screen
+func (d *dataQL) printWithPager(pager []string, rows *sql.Rows, columns []string) (int, error) {
+	// The rendered table is moved to --temp-dir past --max-result-memory
+	buf := &spillBuffer{limit: d.maxResultMemory(), dir: d.params.TempDir}
+	defer func() {
+		_ = buf.Close()
+	}()
+	rowCount, err := d.writeAllRows(buf, rows, columns)
+	if err != nil {
+		return rowCount, err
+	}
+
+	_, height, err := readline.GetSize(int(os.Stdout.Fd()))
+	if err == nil && !buf.spilled() && bytes.Count(buf.mem.Bytes(), []byte("\n")) < height-1 {
+		_, err := d.out.Write(buf.mem.Bytes())
+		return rowCount, err
+	}
+
+	output, err := buf.reader()
+	if err != nil {
+		return rowCount, err
+	}
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdin = output
+	cmd.Stdout = os.Stdout // pagerCommand only pages output that goes to the terminal
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return rowCount, fmt.Errorf("failed to run pager %s: %w", pager[0], err)
+	}
+
+	return rowCount, nil
+}
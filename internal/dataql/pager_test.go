@@ -0,0 +1,23 @@
+package dataql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePager(t *testing.T) {
+	// REPL setting takes precedence over $PAGER
+	assert.Equal(t, []string{"cat", "-n"}, resolvePager("cat -n", "more"))
+
+	// $PAGER is used when there is no REPL setting
+	assert.Equal(t, []string{"cat"}, resolvePager("", "cat"))
+
+	// Unknown programs fall back to built-in paging
+	assert.Nil(t, resolvePager("dataql-missing-pager", ""))
+}
+
+func TestPagerCommand_Disabled(t *testing.T) {
+	d := &dataQL{pager: "OFF"}
+	assert.Nil(t, d.pagerCommand())
+}
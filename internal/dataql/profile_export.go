@@ -0,0 +1,340 @@
+package dataql
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrianolaselva/dataql/internal/exportdata"
+)
+
+// profileExportHeader lists the CSV columns of an exported profile
+var profileExportHeader = []string{
+	"table", "rows", "column", "type", "nulls", "null_pct", "empty", "distinct", "min", "max", "mean", "top_values", "histogram", "outliers", "cardinality", "temporal", "quality", "drift", "group",
+}
+
+// profileExportType returns the format of an exported profile: the --type
+// flag, or the export file extension when the flag is not set
+func profileExportType(exportType, exportPath string) (string, error) {
+	if exportType == "" {
+		exportType = strings.TrimPrefix(strings.ToLower(filepath.Ext(exportPath)), ".")
+	}
+
+	switch exportType {
+	case exportdata.JSONExportType, exportdata.CSVLineExportType:
+		return exportType, nil
+	case exportdata.MarkdownExportType, exportdata.MarkdownMDExportType:
+		return exportdata.MarkdownExportType, nil
+	}
+	return "", fmt.Errorf("profile export type %q not supported (use json, csv or markdown)", exportType)
+}
+
+// exportProfile writes the statistics of every table to the export file
+func (d *dataQL) exportProfile() error {
+	exportType, err := profileExportType(d.params.Type, d.params.Export)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := d.profileTables(d.params.Describe)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	switch exportType {
+	case exportdata.JSONExportType:
+		content, err = json.MarshalIndent(map[string]interface{}{"tables": profiles}, "", "  ")
+		content = append(content, '\n')
+	case exportdata.CSVLineExportType:
+		content, err = profileCSV(profiles)
+	default:
+		content = profileMarkdown(profiles)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	if err := os.WriteFile(d.params.Export, content, 0644); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+
+	fmt.Fprintf(d.out, "[%s] profile successfully exported (%d tables)\n", d.params.Export, len(profiles))
+	return nil
+}
+
+// profileCSV encodes the profile with one row per column
+func profileCSV(profiles []TableProfile) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(profileExportHeader); err != nil {
+		return nil, err
+	}
+
+	for _, table := range profiles {
+		for _, col := range table.Columns {
+			record := []string{
+				table.Name,
+				strconv.FormatInt(table.Rows, 10),
+				col.Name,
+				col.Type,
+				strconv.FormatInt(col.Nulls, 10),
+				formatBound(col.NullPct),
+				formatCount(col.Empty),
+				strconv.FormatInt(col.Distinct, 10),
+				formatProfileValue(col.Min),
+				formatProfileValue(col.Max),
+				formatMean(col.Mean),
+				formatTopValues(col.TopValues),
+				formatHistogram(col.Histogram),
+				formatOutliers(col.Outliers),
+				formatCardinality(col.Cardinality),
+				formatTemporalReport(col.Temporal),
+				formatColumnQuality(col.Quality),
+				formatColumnDrift(col),
+				"",
+			}
+			if err := w.Write(record); err != nil {
+				return nil, err
+			}
+		}
+
+		// Each group adds a row per column with its own row count
+		for _, group := range table.Groups {
+			for _, col := range group.Columns {
+				record := make([]string, len(profileExportHeader))
+				copy(record, []string{
+					table.Name,
+					strconv.FormatInt(group.Rows, 10),
+					col.Name,
+					col.Type,
+					strconv.FormatInt(col.Nulls, 10),
+					formatBound(col.NullPct),
+					"",
+					strconv.FormatInt(col.Distinct, 10),
+					formatProfileValue(col.Min),
+					formatProfileValue(col.Max),
+					formatMean(col.Mean),
+				})
+				record[len(record)-1] = table.GroupBy + "=" + group.label()
+				if err := w.Write(record); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// profileMarkdown renders the profile as a Markdown document with a table per data table
+func profileMarkdown(profiles []TableProfile) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Data Profile\n")
+
+	for _, table := range profiles {
+		fmt.Fprintf(&buf, "\n## %s\n\nRows: %d\n", table.Name, table.Rows)
+		if table.RowLimit > 0 {
+			fmt.Fprintf(&buf, "\nSample: first %d rows of each source\n", table.RowLimit)
+		}
+		if q := table.Quality; q != nil {
+			fmt.Fprintf(&buf, "\nQuality score: %s (completeness %s, validity %s, uniqueness %s, consistency %s)\n",
+				formatBound(q.Score), formatBound(q.Completeness), formatBound(q.Validity), formatBound(q.Uniqueness), formatBound(q.Consistency))
+		}
+		if table.BlankRows != nil {
+			fmt.Fprintf(&buf, "\nBlank rows: %d\n", *table.BlankRows)
+		}
+		if drift := table.Drift; drift != nil {
+			fmt.Fprintf(&buf, "\nCompared with: %s (table %s, %d rows)\n", drift.Baseline, drift.BaselineTable, drift.BaselineRows)
+			if len(drift.AddedColumns) > 0 {
+				fmt.Fprintf(&buf, "\nAdded columns: %s\n", strings.Join(drift.AddedColumns, ", "))
+			}
+			if len(drift.RemovedColumns) > 0 {
+				fmt.Fprintf(&buf, "\nRemoved columns: %s\n", strings.Join(drift.RemovedColumns, ", "))
+			}
+		}
+		buf.WriteString("\n")
+		buf.WriteString("| Column | Type | Nulls | Null % | Empty | Distinct | Min | Max | Mean | Top values | Histogram | Outliers | Cardinality | Temporal | Quality | Drift |\n")
+		buf.WriteString("| --- | --- | --- | --- | --- | --- | --- | --- | --- | --- | --- | --- | --- | --- | --- | --- |\n")
+
+		for _, col := range table.Columns {
+			cells := []string{
+				col.Name,
+				col.Type,
+				strconv.FormatInt(col.Nulls, 10),
+				formatBound(col.NullPct),
+				formatCount(col.Empty),
+				strconv.FormatInt(col.Distinct, 10),
+				formatProfileValue(col.Min),
+				formatProfileValue(col.Max),
+				formatMean(col.Mean),
+				formatTopValues(col.TopValues),
+				formatHistogram(col.Histogram),
+				formatOutliers(col.Outliers),
+				formatCardinality(col.Cardinality),
+				formatTemporalReport(col.Temporal),
+				formatColumnQuality(col.Quality),
+				formatColumnDrift(col),
+			}
+			buf.WriteString(markdownRow(cells))
+		}
+
+		for _, group := range table.Groups {
+			fmt.Fprintf(&buf, "\n### %s = %s\n\nRows: %d\n\n", table.GroupBy, group.label(), group.Rows)
+			buf.WriteString("| Column | Type | Nulls | Null % | Distinct | Min | Max | Mean |\n")
+			buf.WriteString("| --- | --- | --- | --- | --- | --- | --- | --- |\n")
+
+			for _, col := range group.Columns {
+				cells := []string{
+					col.Name,
+					col.Type,
+					strconv.FormatInt(col.Nulls, 10),
+					formatBound(col.NullPct),
+					strconv.FormatInt(col.Distinct, 10),
+					formatProfileValue(col.Min),
+					formatProfileValue(col.Max),
+					formatMean(col.Mean),
+				}
+				buf.WriteString(markdownRow(cells))
+			}
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// markdownRow renders a table row, escaping pipes in values so they do not break the table
+func markdownRow(cells []string) string {
+	for i, cell := range cells {
+		cells[i] = strings.ReplaceAll(cell, "|", "\\|")
+	}
+	return "| " + strings.Join(cells, " | ") + " |\n"
+}
+
+// formatCount renders an optional count, leaving it empty when it was not computed
+func formatCount(count *int64) string {
+	if count == nil {
+		return ""
+	}
+	return strconv.FormatInt(*count, 10)
+}
+
+// formatMean renders the mean with at most two decimals; only numeric columns have one
+func formatMean(mean *float64) string {
+	if mean == nil {
+		return ""
+	}
+	return formatBound(*mean)
+}
+
+// formatProfileValue renders a min/max value, leaving NULL empty
+func formatProfileValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return v.Format("2006-01-02")
+		}
+		return v.Format("2006-01-02 15:04:05")
+	}
+	return fmt.Sprintf("%v", displayValue(value))
+}
+
+// formatTopValues renders the most frequent values as "value=count; ..."
+func formatTopValues(values []ValueCount) string {
+	parts := make([]string, len(values))
+	for i, vc := range values {
+		parts[i] = fmt.Sprintf("%s=%d", vc.Value, vc.Count)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatHistogram renders the buckets as "lower..upper=count; ..."
+func formatHistogram(buckets []HistogramBucket) string {
+	parts := make([]string, len(buckets))
+	for i, bucket := range buckets {
+		parts[i] = fmt.Sprintf("%s..%s=%d", formatBound(bucket.Lower), formatBound(bucket.Upper), bucket.Count)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatOutliers renders the outlier counts as "N outside [lower, upper]; M by z-score"
+func formatOutliers(report *OutlierReport) string {
+	if report == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d outside [%s, %s]; %d by z-score", report.IQRCount,
+		formatBound(report.LowerFence), formatBound(report.UpperFence), report.ZScoreCount)
+}
+
+// formatCardinality renders the uniqueness and flag as "N% unique; key candidate"
+func formatCardinality(report *CardinalityReport) string {
+	if report == nil {
+		return ""
+	}
+	if label := report.label(); label != "" {
+		return fmt.Sprintf("%s%% unique; %s", formatBound(report.Uniqueness), label)
+	}
+	return fmt.Sprintf("%s%% unique", formatBound(report.Uniqueness))
+}
+
+// formatTemporalReport renders the range, gaps and formats as
+// "min..max; N day gaps; formats date=3, other=1"
+func formatTemporalReport(report *TemporalReport) string {
+	if report == nil {
+		return ""
+	}
+	parts := []string{
+		fmt.Sprintf("%s..%s", report.Min, report.Max),
+		fmt.Sprintf("%d %s gaps", report.Gaps, report.Granularity),
+	}
+	if len(report.Formats) > 0 {
+		parts = append(parts, "formats "+strings.ReplaceAll(formatTopValues(report.Formats), "; ", ", "))
+	}
+	if len(report.TimeZones) > 0 {
+		parts = append(parts, "offsets "+strings.ReplaceAll(formatTopValues(report.TimeZones), "; ", ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatColumnQuality renders the column ratings as "complete 100; valid 80 as DOUBLE; consistent 100"
+func formatColumnQuality(quality *ColumnQuality) string {
+	if quality == nil {
+		return ""
+	}
+	valid := "valid " + formatBound(quality.Validity)
+	if quality.ParsedAs != "" {
+		valid += " as " + quality.ParsedAs
+	}
+	return fmt.Sprintf("complete %s; %s; consistent %s", formatBound(quality.Completeness), valid, formatBound(quality.Consistency))
+}
+
+// formatColumnDrift renders the changes against the baseline as
+// "type VARCHAR -> BIGINT; null % 0 -> 25; p 0.0012 shifted"
+func formatColumnDrift(col ColumnProfile) string {
+	drift := col.Drift
+	if drift == nil {
+		return ""
+	}
+	var parts []string
+	if drift.TypeChanged {
+		parts = append(parts, fmt.Sprintf("type %s -> %s", drift.BaselineType, col.Type))
+	}
+	parts = append(parts, fmt.Sprintf("null %% %s -> %s", formatBound(drift.BaselineNullPct), formatBound(col.NullPct)))
+	if drift.PValue != nil {
+		test := fmt.Sprintf("p %.4f", *drift.PValue)
+		if drift.Shifted {
+			test += " shifted"
+		}
+		parts = append(parts, test)
+	}
+	return strings.Join(parts, "; ")
+}
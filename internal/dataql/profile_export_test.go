@@ -0,0 +1,108 @@
+package dataql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileExportType(t *testing.T) {
+	exportType, err := profileExportType("json", "profile.out")
+	assert.NoError(t, err)
+	assert.Equal(t, "json", exportType)
+
+	exportType, err = profileExportType("", "profile.CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, "csv", exportType)
+
+	exportType, err = profileExportType("", "profile.md")
+	assert.NoError(t, err)
+	assert.Equal(t, "markdown", exportType)
+
+	_, err = profileExportType("parquet", "profile.parquet")
+	assert.Error(t, err)
+
+	_, err = profileExportType("", "profile")
+	assert.Error(t, err)
+}
+
+func testProfiles() []TableProfile {
+	mean, pValue := 150.0, 0.00123
+	empty, blankRows := int64(1), int64(0)
+	return []TableProfile{{
+		Name:      "orders",
+		Rows:      4,
+		BlankRows: &blankRows,
+		Columns: []ColumnProfile{
+			{
+				Name: "total", Type: "BIGINT", Nulls: 1, NullPct: 25, Distinct: 3, Min: int64(100), Max: int64(200), Mean: &mean,
+				Histogram: []HistogramBucket{{Lower: 100.0, Upper: 150.0, Count: 2}, {Lower: 150.0, Upper: 200.0, Count: 1}},
+				Outliers:  &OutlierReport{LowerFence: 25, UpperFence: 275},
+				Drift:     &ColumnDrift{BaselineType: "VARCHAR", TypeChanged: true, BaselineNullPct: 0, PValue: &pValue, Shifted: true},
+			},
+			{
+				Name: "status", Type: "VARCHAR", Empty: &empty, Distinct: 2, Min: "a|b", Max: "done",
+				TopValues: []ValueCount{{Value: "done", Count: 3, Pct: 75}},
+				Quality:   &ColumnQuality{Completeness: 75, Validity: 100, Consistency: 50},
+			},
+			{
+				Name: "created", Type: "DATE", Distinct: 4,
+				Min: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Max: time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+				Cardinality: &CardinalityReport{Uniqueness: 100, KeyCandidate: true},
+				Temporal: &TemporalReport{Min: "2024-01-01", Max: "2024-03-01", Granularity: "week", Gaps: 2,
+					Formats: []ValueCount{{Value: "date", Count: 3}, {Value: "other", Count: 1}}},
+			},
+		},
+	}}
+}
+
+func TestProfileCSV(t *testing.T) {
+	content, err := profileCSV(testProfiles())
+	assert.NoError(t, err)
+	assert.Equal(t, "table,rows,column,type,nulls,null_pct,empty,distinct,min,max,mean,top_values,histogram,outliers,cardinality,temporal,quality,drift,group\n"+
+		"orders,4,total,BIGINT,1,25,,3,100,200,150,,100..150=2; 150..200=1,\"0 outside [25, 275]; 0 by z-score\",,,,type VARCHAR -> BIGINT; null % 0 -> 25; p 0.0012 shifted,\n"+
+		"orders,4,status,VARCHAR,0,0,1,2,a|b,done,,done=3,,,,,complete 75; valid 100; consistent 50,,\n"+
+		"orders,4,created,DATE,0,0,,4,2024-01-01,2024-03-01 12:30:00,,,,,100% unique; key candidate,\"2024-01-01..2024-03-01; 2 week gaps; formats date=3, other=1\",,,\n", string(content))
+}
+
+func TestProfileMarkdown(t *testing.T) {
+	content := string(profileMarkdown(testProfiles()))
+	assert.Contains(t, content, "## orders\n\nRows: 4\n\nBlank rows: 0\n")
+	assert.Contains(t, content, "| Column | Type | Nulls | Null % | Empty |")
+	assert.Contains(t, content, "| total | BIGINT | 1 | 25 |  | 3 | 100 | 200 | 150 |  | 100..150=2; 150..200=1 | 0 outside [25, 275]; 0 by z-score |  |  |  | type VARCHAR -> BIGINT; null % 0 -> 25; p 0.0012 shifted |")
+	// Pipes in values are escaped so they do not break the table
+	assert.Contains(t, content, "| status | VARCHAR | 0 | 0 | 1 | 2 | a\\|b | done |  | done=3 |  |  |  |  | complete 75; valid 100; consistent 50 |  |")
+}
+
+func TestProfileExport_Groups(t *testing.T) {
+	eu, mean := "eu", 20.0
+	profiles := []TableProfile{{
+		Name:    "sales",
+		Rows:    3,
+		GroupBy: "region",
+		Columns: []ColumnProfile{{Name: "amount", Type: "BIGINT", Distinct: 3}},
+		Groups: []GroupProfile{
+			{Value: &eu, Rows: 2, Columns: []ColumnProfile{{Name: "amount", Type: "BIGINT", Distinct: 2, Min: int64(10), Max: int64(30), Mean: &mean}}},
+			{Rows: 1, Columns: []ColumnProfile{{Name: "amount", Type: "BIGINT", Nulls: 1, NullPct: 100, Distinct: 0}}},
+		},
+	}}
+
+	content, err := profileCSV(profiles)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "sales,2,amount,BIGINT,0,0,,2,10,30,20,,,,,,,,region=eu\n")
+	assert.Contains(t, string(content), "sales,1,amount,BIGINT,1,100,,0,,,,,,,,,,,region=(null)\n")
+
+	markdown := string(profileMarkdown(profiles))
+	assert.Contains(t, markdown, "### region = eu\n\nRows: 2\n")
+	assert.Contains(t, markdown, "| amount | BIGINT | 0 | 0 | 2 | 10 | 30 | 20 |")
+}
+
+func TestProfileMarkdown_Drift(t *testing.T) {
+	profiles := testProfiles()
+	profiles[0].Drift = &DriftReport{Baseline: "orders_old.csv", BaselineTable: "orders_old", BaselineRows: 3,
+		AddedColumns: []string{"created"}, RemovedColumns: []string{"legacy"}}
+
+	content := string(profileMarkdown(profiles))
+	assert.Contains(t, content, "Compared with: orders_old.csv (table orders_old, 3 rows)\n\nAdded columns: created\n\nRemoved columns: legacy\n")
+}
@@ -0,0 +1,264 @@
+package dataql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Quality dimensions, as named in --score-weights
+const (
+	dimensionCompleteness = "completeness"
+	dimensionValidity     = "validity"
+	dimensionUniqueness   = "uniqueness"
+	dimensionConsistency  = "consistency"
+)
+
+// ScoreWeights sets how much each dimension counts in the overall quality score
+type ScoreWeights struct {
+	Completeness float64 `json:"completeness"`
+	Validity     float64 `json:"validity"`
+	Uniqueness   float64 `json:"uniqueness"`
+	Consistency  float64 `json:"consistency"`
+}
+
+// DefaultScoreWeights weighs every dimension equally
+var DefaultScoreWeights = ScoreWeights{Completeness: 1, Validity: 1, Uniqueness: 1, Consistency: 1}
+
+// ParseScoreWeights parses "dimension=weight" pairs separated by commas, e.g.
+// "completeness=2,uniqueness=0". Dimensions that are not listed keep weight 1.
+func ParseScoreWeights(spec string) (ScoreWeights, error) {
+	weights := DefaultScoreWeights
+	if strings.TrimSpace(spec) == "" {
+		return weights, nil
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return weights, fmt.Errorf("invalid score weight %q (use dimension=weight)", pair)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight < 0 {
+			return weights, fmt.Errorf("invalid score weight %q: weight must be a number >= 0", pair)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case dimensionCompleteness:
+			weights.Completeness = weight
+		case dimensionValidity:
+			weights.Validity = weight
+		case dimensionUniqueness:
+			weights.Uniqueness = weight
+		case dimensionConsistency:
+			weights.Consistency = weight
+		default:
+			return weights, fmt.Errorf("unknown quality dimension %q (use completeness, validity, uniqueness or consistency)", name)
+		}
+	}
+
+	if weights.Completeness+weights.Validity+weights.Uniqueness+weights.Consistency == 0 {
+		return weights, fmt.Errorf("at least one score weight must be greater than 0")
+	}
+	return weights, nil
+}
+
+// QualityScore rates a table from 0 to 100 on each quality dimension and
+// overall, as the weighted average of the dimensions
+type QualityScore struct {
+	Score         float64      `json:"score"`
+	Completeness  float64      `json:"completeness"` // Cells that are not NULL or empty
+	Validity      float64      `json:"validity"`     // Text values that parse as the type most of the column holds
+	Uniqueness    float64      `json:"uniqueness"`   // Rows that do not repeat an earlier row
+	Consistency   float64      `json:"consistency"`  // Text values written like the other values with the same content
+	DuplicateRows int64        `json:"duplicate_rows"`
+	Weights       ScoreWeights `json:"weights"`
+}
+
+// ColumnQuality rates a column on the column-level quality dimensions.
+// ParsedAs is the type most text values parse as, or empty for free text.
+type ColumnQuality struct {
+	Completeness float64 `json:"completeness"`
+	Validity     float64 `json:"validity"`
+	Consistency  float64 `json:"consistency"`
+	ParsedAs     string  `json:"parsed_as,omitempty"`
+}
+
+// percentOf returns part as a percentage of total, or 100 when there is nothing to rate
+func percentOf(part, total int64) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(part) * 100 / float64(total)
+}
+
+// getColumnQuality rates the completeness, validity and consistency of a column
+func (d *dataQL) getColumnQuality(tableName string, col ColumnProfile, rowCount int64) (*ColumnQuality, error) {
+	escapedColumn := fmt.Sprintf("\"%s\"", col.Name)
+	quality := &ColumnQuality{Validity: 100, Consistency: 100}
+
+	missingExpr := fmt.Sprintf("%s IS NULL", escapedColumn)
+	if isTextType(col.Type) {
+		missingExpr = fmt.Sprintf("(%s IS NULL OR %s = '')", escapedColumn, escapedColumn)
+	}
+	missing, err := d.queryCount(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", tableName, missingExpr))
+	if err != nil {
+		return nil, err
+	}
+	quality.Completeness = percentOf(rowCount-missing, rowCount)
+
+	if !isTextType(col.Type) {
+		return quality, nil // Typed columns only hold valid values
+	}
+
+	if err := d.rateValidity(tableName, escapedColumn, quality); err != nil {
+		return nil, err
+	}
+	if err := d.rateConsistency(tableName, escapedColumn, quality); err != nil {
+		return nil, err
+	}
+	return quality, nil
+}
+
+// rateValidity finds the type most non-empty values of a text column parse
+// as and rates the share of values that do. Columns where no type parses
+// for a majority of the values are free text and fully valid.
+func (d *dataQL) rateValidity(tableName, escapedColumn string, quality *ColumnQuality) error {
+	// Candidate types in order of preference when they parse the same values
+	types := []string{"DOUBLE", "TIMESTAMP", "BOOLEAN"}
+
+	counts := make([]string, len(types))
+	for i, t := range types {
+		counts[i] = fmt.Sprintf("COUNT(TRY_CAST(%s AS %s))", escapedColumn, t)
+	}
+	rows, err := d.storage.Query(fmt.Sprintf("SELECT COUNT(*), %s FROM %s WHERE %s <> ''",
+		strings.Join(counts, ", "), tableName, escapedColumn))
+	if err != nil {
+		return fmt.Errorf("failed to check value types: %w", err)
+	}
+	defer rows.Close()
+
+	var total int64
+	parsed := make([]int64, len(types))
+	targets := []interface{}{&total}
+	for i := range parsed {
+		targets = append(targets, &parsed[i])
+	}
+	if rows.Next() {
+		if err := rows.Scan(targets...); err != nil {
+			return fmt.Errorf("failed to read value types: %w", err)
+		}
+	}
+
+	best := 0
+	for i := range parsed {
+		if parsed[i] > parsed[best] {
+			best = i
+		}
+	}
+	if parsed[best]*2 > total {
+		quality.ParsedAs = types[best]
+		quality.Validity = percentOf(parsed[best], total)
+	}
+	return rows.Err()
+}
+
+// rateConsistency rates the share of non-empty text values written exactly
+// like the most frequent spelling of the same content, ignoring case and
+// surrounding spaces, so "EU", "eu" and " eu" count as inconsistent spellings
+func (d *dataQL) rateConsistency(tableName, escapedColumn string, quality *ColumnQuality) error {
+	query := fmt.Sprintf(`WITH vals AS (SELECT %s AS v, lower(trim(%s)) AS k FROM %s WHERE %s <> ''),
+		spellings AS (SELECT k, v, COUNT(*) AS n FROM vals GROUP BY k, v),
+		canonical AS (SELECT k, arg_max(v, n) AS v FROM spellings GROUP BY k)
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE vals.v = canonical.v AND vals.v = trim(vals.v))
+		FROM vals JOIN canonical ON vals.k = canonical.k`, escapedColumn, escapedColumn, tableName, escapedColumn)
+	rows, err := d.storage.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to check spellings: %w", err)
+	}
+	defer rows.Close()
+
+	var total, consistent int64
+	if rows.Next() {
+		if err := rows.Scan(&total, &consistent); err != nil {
+			return fmt.Errorf("failed to read spellings: %w", err)
+		}
+	}
+	quality.Consistency = percentOf(consistent, total)
+	return rows.Err()
+}
+
+// getQualityScore rates every column of a table and combines the ratings
+// with the share of distinct rows into the table score
+func (d *dataQL) getQualityScore(tableName string, columns []ColumnProfile, rowCount int64, weights ScoreWeights) (*QualityScore, error) {
+	score := &QualityScore{Weights: weights}
+
+	for i := range columns {
+		quality, err := d.getColumnQuality(tableName, columns[i], rowCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rate %s: %w", columns[i].Name, err)
+		}
+		columns[i].Quality = quality
+		score.Completeness += quality.Completeness / float64(len(columns))
+		score.Validity += quality.Validity / float64(len(columns))
+		score.Consistency += quality.Consistency / float64(len(columns))
+	}
+
+	distinctRows, err := d.queryCount(fmt.Sprintf("SELECT COUNT(*) FROM (SELECT DISTINCT * FROM %s)", tableName))
+	if err != nil {
+		return nil, err
+	}
+	score.DuplicateRows = rowCount - distinctRows
+	score.Uniqueness = percentOf(distinctRows, rowCount)
+
+	total := weights.Completeness + weights.Validity + weights.Uniqueness + weights.Consistency
+	score.Score = (score.Completeness*weights.Completeness + score.Validity*weights.Validity +
+		score.Uniqueness*weights.Uniqueness + score.Consistency*weights.Consistency) / total
+	return score, nil
+}
+
+// printQualityScore prints the quality scorecard of a table
+func (d *dataQL) printQualityScore(tableName string, weights ScoreWeights) error {
+	columns, err := d.getTableColumns(tableName)
+	if err != nil {
+		return err
+	}
+	rowCount, err := d.queryCount(fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName))
+	if err != nil {
+		return err
+	}
+
+	score, err := d.getQualityScore(tableName, columns, rowCount, weights)
+	if err != nil {
+		return err
+	}
+
+	sectionColor := color.New(color.FgCyan)
+	sectionColor.Fprintf(d.out, "\nQuality score: %.1f / 100\n\n", score.Score)
+
+	fmt.Fprintf(d.out, "  %-12s  %5.1f  (weight %s)\n", "Completeness", score.Completeness, formatBound(weights.Completeness))
+	fmt.Fprintf(d.out, "  %-12s  %5.1f  (weight %s)\n", "Validity", score.Validity, formatBound(weights.Validity))
+	fmt.Fprintf(d.out, "  %-12s  %5.1f  (weight %s, %d duplicate rows)\n", "Uniqueness", score.Uniqueness, formatBound(weights.Uniqueness), score.DuplicateRows)
+	fmt.Fprintf(d.out, "  %-12s  %5.1f  (weight %s)\n\n", "Consistency", score.Consistency, formatBound(weights.Consistency))
+
+	nameWidth := len("Column")
+	for _, col := range columns {
+		if len(col.Name) > nameWidth {
+			nameWidth = len(col.Name)
+		}
+	}
+
+	fmt.Fprintf(d.out, "  %-*s  %8s  %8s  %10s\n", nameWidth, "Column", "Complete", "Valid", "Consistent")
+	for _, col := range columns {
+		q := col.Quality
+		parsedAs := ""
+		if q.ParsedAs != "" {
+			parsedAs = "as " + q.ParsedAs
+		}
+		line := fmt.Sprintf("  %-*s  %7.1f%%  %7.1f%%  %9.1f%%  %s", nameWidth, col.Name, q.Completeness, q.Validity, q.Consistency, parsedAs)
+		fmt.Fprintln(d.out, strings.TrimRight(line, " "))
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package dataql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScoreWeights(t *testing.T) {
+	weights, err := ParseScoreWeights("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultScoreWeights, weights)
+
+	weights, err = ParseScoreWeights("Completeness=2, uniqueness=0")
+	assert.NoError(t, err)
+	assert.Equal(t, ScoreWeights{Completeness: 2, Validity: 1, Uniqueness: 0, Consistency: 1}, weights)
+
+	_, err = ParseScoreWeights("freshness=1")
+	assert.ErrorContains(t, err, "unknown quality dimension")
+
+	_, err = ParseScoreWeights("validity=-1")
+	assert.ErrorContains(t, err, "invalid score weight")
+
+	_, err = ParseScoreWeights("completeness=0,validity=0,uniqueness=0,consistency=0")
+	assert.Error(t, err)
+}
+
+func TestProfile_Score(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "sales.csv")
+	content := "id,region,amount\n1,eu,10\n2,EU,12\n3,eu,n/a\n4,,14\n4,,14\n"
+	assert.NoError(t, os.WriteFile(csvPath, []byte(content), 0644))
+
+	dql, err := New(Params{FileInputs: []string{csvPath}, Delimiter: ",", Quiet: true})
+	assert.NoError(t, err)
+	defer dql.Close()
+
+	tables, err := dql.Profile(DescribeOptions{Score: true, ScoreWeights: ScoreWeights{Completeness: 1, Uniqueness: 1}})
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+
+	columns := tables[0].Columns
+	assert.Equal(t, &ColumnQuality{Completeness: 100, Validity: 100, Consistency: 100}, columns[0].Quality)
+
+	// "EU" is a minority spelling of "eu"
+	region := columns[1].Quality
+	assert.Equal(t, 60.0, region.Completeness)
+	assert.InDelta(t, 66.7, region.Consistency, 0.1)
+	assert.Empty(t, region.ParsedAs)
+
+	// "n/a" does not parse as the numbers the rest of the column holds
+	amount := columns[2].Quality
+	assert.Equal(t, "DOUBLE", amount.ParsedAs)
+	assert.Equal(t, 80.0, amount.Validity)
+
+	score := tables[0].Quality
+	assert.Equal(t, int64(1), score.DuplicateRows)
+	assert.Equal(t, 80.0, score.Uniqueness)
+	assert.InDelta(t, (86.67+80)/2, score.Score, 0.01)
+}
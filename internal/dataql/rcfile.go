@@ -0,0 +1,77 @@
+package dataql
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	rcFileName = ".dataqlrc"
+	envRCFile  = "DATAQLRC" // Overrides the startup file location
+)
+
+// rcFilePath returns the REPL startup file path: $DATAQLRC or ~/.dataqlrc
+func rcFilePath() string {
+	if path := os.Getenv(envRCFile); path != "" {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, rcFileName)
+}
+
+// runStartupFile executes the dot-commands and SQL statements of the startup
+// file before the REPL starts, one per line (like psqlrc). Lines starting with
+// "--" or "#" are comments. Output is hidden unless verbose mode is on; errors
+// are reported with their line number and do not stop the remaining lines.
+func (d *dataQL) runStartupFile() {
+	if d.params.NoRC {
+		return
+	}
+
+	path := rcFilePath()
+	file, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Warning: failed to open %s: %v\n", path, err)
+		}
+		return
+	}
+	defer file.Close()
+
+	verboseLog(d.params.Verbose, "Running startup file %s", path)
+
+	// Settings such as .mode or SET statements print confirmations that are noise at startup
+	if !d.params.Verbose {
+		out := d.out
+		d.out = io.Discard
+		defer func() {
+			d.out = out
+		}()
+	}
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "--") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if err := d.executeQuery(line); err != nil {
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "%s:%d: %v\n", path, lineNumber, err)
+		}
+	}
+}
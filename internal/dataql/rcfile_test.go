@@ -0,0 +1,74 @@
+package dataql
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRCFilePath(t *testing.T) {
+	t.Setenv(envRCFile, "/tmp/custom-dataqlrc")
+	assert.Equal(t, "/tmp/custom-dataqlrc", rcFilePath())
+
+	t.Setenv(envRCFile, "")
+	t.Setenv("HOME", "/home/tester")
+	assert.Equal(t, "/home/tester/.dataqlrc", rcFilePath())
+}
+
+func TestRunStartupFile_HidesOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), rcFileName)
+	assert.NoError(t, os.WriteFile(path, []byte("-- startup\n.timing on\n.vertical on\n"), 0o600))
+	t.Setenv(envRCFile, path)
+
+	var out bytes.Buffer
+	d := &dataQL{out: &out}
+	d.runStartupFile()
+
+	assert.True(t, d.showTiming)
+	assert.True(t, d.vertical)
+	assert.Empty(t, out.String())
+	assert.Same(t, &out, d.out)
+
+	d.params.Verbose = true
+	d.runStartupFile()
+	assert.Contains(t, out.String(), "Timing enabled")
+}
+
+func TestRunStartupFile_HidesResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), rcFileName)
+	rc := ".mode vertical\nSELECT 42 AS answer;\n.describe simple\n.describe\n"
+	require.NoError(t, os.WriteFile(path, []byte(rc), 0o600))
+	t.Setenv(envRCFile, path)
+
+	dql, err := New(Params{
+		FileInputs: []string{"../../tests/fixtures/csv/simple.csv"},
+		Delimiter:  ",",
+		Quiet:      true,
+		Describe: DescribeOptions{
+			TopValues: 2, Histogram: true, Outliers: true, Completeness: true,
+			Cardinality: true, Temporal: true, Score: true,
+		},
+	})
+	require.NoError(t, err)
+	defer dql.Close()
+	require.NoError(t, dql.Import())
+
+	var out bytes.Buffer
+	d := dql.(*dataQL)
+	d.out = &out
+	d.runStartupFile()
+	assert.Empty(t, out.String())
+
+	// Vertical rows and command headers go to the session output
+	require.NoError(t, d.executeQuery("SELECT 42 AS answer"))
+	require.NoError(t, d.executeQuery(".describe"))
+	assert.Contains(t, out.String(), "answer: 42")
+	for _, header := range []string{"=== Table: simple", "Top values:", "Histograms:", "Completeness:",
+		"Cardinality:", "Temporal:", "Outliers", "Quality score:"} {
+		assert.Contains(t, out.String(), header)
+	}
+}
@@ -0,0 +1,99 @@
+package dataql
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/clierror"
+	"github.com/adrianolaselva/dataql/pkg/sqlguard"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// attachStorage attaches the storage file read-only when a --read-only
+// session imports --file sources, which are imported in memory instead so
+// the file is never written. It returns the alias of the storage file, or
+// an empty string when it is not attached.
+func attachStorage(st storage.Storage, params Params) (string, error) {
+	if !params.ReadOnly || params.DataSourceName == "" || len(params.FileInputs) == 0 {
+		return "", nil
+	}
+	if _, err := os.Stat(params.DataSourceName); err != nil {
+		return "", fmt.Errorf("storage file does not exist: %s (drop --read-only to create it)", params.DataSourceName)
+	}
+
+	alias := aliasFromPath(params.DataSourceName)
+	if !attachAlias.MatchString(alias) || reservedAliases[alias] {
+		alias = "storage"
+	}
+	attachments, err := ParseAttachments(params.Attach)
+	if err != nil {
+		return "", err
+	}
+	for _, attachment := range attachments {
+		if strings.EqualFold(attachment.Alias, alias) {
+			return "", fmt.Errorf("--attach alias %q is the alias of --storage %s; give the attached file another alias", attachment.Alias, params.DataSourceName)
+		}
+	}
+	if err := attachFile(st, params, Attachment{Path: params.DataSourceName, Alias: alias}); err != nil {
+		return "", err
+	}
+	return alias, nil
+}
+
+// linkStorage creates views of the tables saved in the storage file
+// attached by attachStorage, so they are queried by name like in the file
+// itself. An imported table keeps its name over a saved one. SQLite looks
+// tables up in attached databases itself.
+func (d *dataQL) linkStorage() error {
+	if engine, _ := storage.ParseEngine(d.params.Engine); d.storageAlias == "" || engine == storage.EngineSQLite {
+		return nil
+	}
+
+	rows, err := d.storage.Query(fmt.Sprintf("SELECT table_name FROM duckdb_tables() WHERE database_name = %[1]s AND schema_name = 'main' "+
+		"UNION ALL SELECT view_name FROM duckdb_views() WHERE database_name = %[1]s AND schema_name = 'main' AND NOT internal", sqlString(d.storageAlias)))
+	if err != nil {
+		return fmt.Errorf("failed to list the tables of %s: %w", d.params.DataSourceName, err)
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to list the tables of %s: %w", d.params.DataSourceName, err)
+		}
+		tables = append(tables, strings.ReplaceAll(table, `"`, `""`))
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to list the tables of %s: %w", d.params.DataSourceName, err)
+	}
+
+	for _, table := range tables {
+		statement := fmt.Sprintf(`CREATE VIEW IF NOT EXISTS "%s" AS SELECT * FROM "%s"."%s"`, table, d.storageAlias, table)
+		if err := execStatement(d.storage, statement); err != nil {
+			return fmt.Errorf("failed to link table %s of %s: %w", table, d.params.DataSourceName, err)
+		}
+	}
+	return nil
+}
+
+// readOnlyPath returns the data source name that opens the storage file at
+// path read-only with engine
+func readOnlyPath(engine storage.Engine, path string) string {
+	if engine == storage.EngineSQLite {
+		return "file:" + path + "?mode=ro"
+	}
+	return path + "?access_mode=read_only"
+}
+
+// rejectWrites fails with --read-only when query is not a read-only
+// statement, so database-backed sources are never modified
+func (d *dataQL) rejectWrites(query string) error {
+	if !d.params.ReadOnly {
+		return nil
+	}
+	if err := sqlguard.CheckReadOnly(query); err != nil {
+		return clierror.Query(fmt.Errorf("query rejected: %w (--read-only allows only SELECT, WITH, SHOW, DESCRIBE and EXPLAIN)", err))
+	}
+	return nil
+}
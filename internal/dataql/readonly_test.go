@@ -0,0 +1,120 @@
+package dataql
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyStorage(t *testing.T) {
+	for _, engine := range storage.Engines {
+		t.Run(string(engine), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "store.db")
+			dql, err := New(Params{
+				FileInputs:     []string{"../../tests/fixtures/csv/simple.csv"},
+				DataSourceName: path,
+				Delimiter:      ",",
+				Engine:         string(engine),
+				Quiet:          true,
+			})
+			require.NoError(t, err)
+			require.NoError(t, dql.Import())
+			require.NoError(t, dql.Close())
+
+			dql, err = NewStorageOnly(Params{DataSourceName: path, Engine: string(engine), ReadOnly: true, Quiet: true})
+			require.NoError(t, err)
+			defer dql.Close()
+
+			result, err := dql.Query("SELECT name FROM simple ORDER BY id LIMIT 1", 0)
+			require.NoError(t, err)
+			assert.Equal(t, [][]interface{}{{"John"}}, result.Rows)
+
+			err = dql.ExecContext(context.Background(), "DELETE FROM simple")
+			assert.ErrorContains(t, err, "DELETE statements are not allowed")
+			_, err = dql.Query("WITH gone AS (SELECT 1) INSERT INTO simple SELECT * FROM simple", 0)
+			assert.ErrorContains(t, err, "INSERT is not allowed")
+
+			// The file itself is opened read-only, below the statement check
+			rows, err := dql.(*dataQL).storage.Query("CREATE TABLE other (id INTEGER)")
+			if err == nil {
+				for rows.Next() {
+				}
+				err = rows.Err()
+				_ = rows.Close()
+			}
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReadOnlyStorageWithFiles(t *testing.T) {
+	for _, engine := range storage.Engines {
+		t.Run(string(engine), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "store.db")
+			dql, err := New(Params{
+				FileInputs:     []string{"../../tests/fixtures/csv/users.csv", "../../tests/fixtures/csv/simple.csv"},
+				DataSourceName: path,
+				Delimiter:      ",",
+				Engine:         string(engine),
+				Quiet:          true,
+			})
+			require.NoError(t, err)
+			require.NoError(t, dql.Import())
+			require.NoError(t, dql.Close())
+
+			// simple.csv is imported as users, over the users table saved in the file
+			dql, err = New(Params{
+				FileInputs:     []string{"../../tests/fixtures/csv/simple.csv"},
+				Collection:     "users",
+				DataSourceName: path,
+				Delimiter:      ",",
+				Engine:         string(engine),
+				ReadOnly:       true,
+				Quiet:          true,
+			})
+			require.NoError(t, err)
+			result, err := dql.Query("SELECT s.name, u.email FROM simple s JOIN users u ON s.id = u.id ORDER BY s.id LIMIT 1", 0)
+			require.NoError(t, err)
+			assert.Equal(t, [][]interface{}{{"John", "john@example.com"}}, result.Rows)
+			require.NoError(t, dql.Close())
+
+			dql, err = NewStorageOnly(Params{DataSourceName: path, Engine: string(engine), ReadOnly: true, Quiet: true})
+			require.NoError(t, err)
+			defer dql.Close()
+			result, err = dql.Query("SELECT name FROM users ORDER BY id LIMIT 1", 0)
+			require.NoError(t, err)
+			assert.Equal(t, [][]interface{}{{"Alice"}}, result.Rows)
+		})
+	}
+}
+
+func TestAttachStorageAlias(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	dql, err := New(Params{FileInputs: []string{"../../tests/fixtures/csv/simple.csv"}, DataSourceName: path, Delimiter: ",", Quiet: true})
+	require.NoError(t, err)
+	require.NoError(t, dql.Import())
+	require.NoError(t, dql.Close())
+
+	_, err = New(Params{
+		FileInputs:     []string{"../../tests/fixtures/csv/users.csv"},
+		DataSourceName: path,
+		Attach:         []string{path + ":store"},
+		Delimiter:      ",",
+		ReadOnly:       true,
+		Quiet:          true,
+	})
+	assert.ErrorContains(t, err, `--attach alias "store" is the alias of --storage`)
+
+	_, err = New(Params{
+		FileInputs:     []string{"../../tests/fixtures/csv/users.csv"},
+		DataSourceName: filepath.Join(t.TempDir(), "missing.db"),
+		Delimiter:      ",",
+		ReadOnly:       true,
+		Quiet:          true,
+	})
+	assert.ErrorContains(t, err, "storage file does not exist")
+}
@@ -0,0 +1,213 @@
+package dataql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/clierror"
+	"github.com/adrianolaselva/dataql/pkg/metrics"
+	"github.com/adrianolaselva/dataql/pkg/queryerror"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/marcboeker/go-duckdb"
+)
+
+// QueryResult holds the columns and typed rows returned by a query
+type QueryResult struct {
+	Columns   []string        `json:"columns"`
+	Types     []string        `json:"types"`
+	Rows      [][]interface{} `json:"rows"`
+	Truncated bool            `json:"truncated,omitempty"` // More rows than the requested limit were available
+}
+
+// Query imports the data if needed and runs a SQL statement, returning its
+// rows as JSON-friendly Go values instead of printing them. When limit is
+// positive, at most limit rows are read and Truncated reports whether more
+// rows were available.
+func (d *dataQL) Query(query string, limit int) (*QueryResult, error) {
+	if err := d.importData(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := d.queryResult(ApplyQueryParams(query, d.queryParams), limit)
+	metrics.ObserveQuery(time.Since(start), err)
+	return result, err
+}
+
+// QueryContext imports the data if needed and runs a SQL statement, returning
+// its rows for the caller to read and close. The statement is interrupted
+// when ctx is done.
+func (d *dataQL) QueryContext(ctx context.Context, query string) (*sql.Rows, error) {
+	if err := d.importData(); err != nil {
+		return nil, err
+	}
+
+	query = ApplyQueryParams(query, d.queryParams)
+	if err := d.rejectWrites(query); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	rows, err := d.queryContext(ctx, query)
+	metrics.ObserveQuery(time.Since(start), err)
+	if err != nil {
+		return nil, clierror.Query(fmt.Errorf("failed to execute query: %w", queryerror.EnhanceError(err)))
+	}
+	return rows, nil
+}
+
+// queryContext runs query, interrupting it when ctx is done if the storage
+// can
+func (d *dataQL) queryContext(ctx context.Context, query string) (*sql.Rows, error) {
+	if ctxStorage, ok := d.storage.(storage.ContextStorage); ok {
+		return ctxStorage.QueryContext(ctx, query)
+	}
+	return d.storage.Query(query)
+}
+
+// ExecContext imports the data if needed and runs a SQL statement that
+// returns no rows, such as CREATE TABLE or INSERT, without printing anything
+func (d *dataQL) ExecContext(ctx context.Context, query string) (err error) {
+	if err := d.importData(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	defer func() {
+		metrics.ObserveQuery(time.Since(start), err)
+	}()
+
+	query = ApplyQueryParams(query, d.queryParams)
+	if err := d.rejectWrites(query); err != nil {
+		return err
+	}
+	if ctxStorage, ok := d.storage.(storage.ContextStorage); ok {
+		if err := ctxStorage.ExecContext(ctx, query); err != nil {
+			return clierror.Query(fmt.Errorf("failed to execute statement: %w", queryerror.EnhanceError(err)))
+		}
+		return nil
+	}
+
+	rows, err := d.storage.Query(query)
+	if err != nil {
+		return clierror.Query(fmt.Errorf("failed to execute statement: %w", queryerror.EnhanceError(err)))
+	}
+	return rows.Close()
+}
+
+// ScanValues reads the current row of rows as JSON-friendly Go values, the
+// same values Query returns. types holds the database type of each column.
+func ScanValues(rows *sql.Rows, types []string) ([]interface{}, error) {
+	values, err := scanRow(rows, len(types))
+	if err != nil {
+		return nil, err
+	}
+	for i := range values {
+		values[i] = jsonValue(values[i], types[i])
+	}
+	return values, nil
+}
+
+// queryResult runs a query on the imported data and reads its rows as
+// JSON-friendly values
+func (d *dataQL) queryResult(query string, limit int) (*QueryResult, error) {
+	if err := d.rejectWrites(query); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.storage.Query(query)
+	if err != nil {
+		return nil, clierror.Query(fmt.Errorf("failed to execute query: %w", queryerror.EnhanceError(err)))
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+	types := make([]string, len(columnTypes))
+	for i, columnType := range columnTypes {
+		types[i] = columnType.DatabaseTypeName()
+	}
+
+	result := &QueryResult{Columns: columns, Types: types, Rows: make([][]interface{}, 0)}
+	for rows.Next() {
+		if limit > 0 && len(result.Rows) == limit {
+			result.Truncated = true
+			break
+		}
+
+		values, err := ScanValues(rows, types)
+		if err != nil {
+			return nil, err
+		}
+		result.Rows = append(result.Rows, values)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// jsonValue converts a value scanned from DuckDB into a value encoding/json
+// can marshal without losing precision: decimals and huge integers become
+// JSON numbers, maps get string keys and non-finite floats become strings.
+func jsonValue(value interface{}, dbType string) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		if dbType == "UUID" && len(v) == 16 {
+			return formatUUID(v)
+		}
+		return string(v)
+	case duckdb.Decimal:
+		return json.Number(v.String())
+	case *big.Int:
+		return json.Number(v.String())
+	case float32:
+		return jsonValue(float64(v), dbType)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Sprintf("%v", v)
+		}
+		return v
+	case duckdb.Map:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprintf("%v", key)] = jsonValue(item, "")
+		}
+		return m
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = jsonValue(item, "")
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonValue(item, "")
+		}
+		return v
+	}
+	return value
+}
+
+// formatUUID formats 16 bytes in the canonical 8-4-4-4-12 form
+func formatUUID(b []byte) string {
+	s := hex.EncodeToString(b)
+	return strings.Join([]string{s[0:8], s[8:12], s[12:16], s[16:20], s[20:32]}, "-")
+}
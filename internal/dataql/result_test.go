@@ -0,0 +1,59 @@
+package dataql
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/marcboeker/go-duckdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_TypedRows(t *testing.T) {
+	dql, err := New(Params{
+		FileInputs: []string{"../../tests/fixtures/csv/simple.csv"},
+		Delimiter:  ",",
+		Quiet:      true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer dql.Close()
+
+	result, err := dql.Query("SELECT id, name, CAST(id AS DECIMAL(10,2)) / 4 AS ratio FROM simple ORDER BY id", 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{"id", "name", "ratio"}, result.Columns)
+	assert.Equal(t, "BIGINT", result.Types[0])
+	assert.True(t, result.Truncated)
+	assert.Len(t, result.Rows, 2)
+	assert.Equal(t, int64(1), result.Rows[0][0])
+	assert.Equal(t, "John", result.Rows[0][1])
+
+	encoded, err := json.Marshal(result.Rows[1])
+	assert.NoError(t, err)
+	assert.Equal(t, `[2,"Jane",0.5]`, string(encoded))
+
+	result, err = dql.Query("SELECT * FROM simple", 0)
+	assert.NoError(t, err)
+	assert.False(t, result.Truncated)
+	assert.Len(t, result.Rows, 3)
+
+	_, err = dql.Query("SELECT * FROM missing", 0)
+	assert.Error(t, err)
+}
+
+func TestJSONValue(t *testing.T) {
+	assert.Equal(t, "text", jsonValue([]byte("text"), "VARCHAR"))
+	assert.Equal(t, "01234567-89ab-cdef-0123-456789abcdef",
+		jsonValue([]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}, "UUID"))
+	assert.Equal(t, json.Number("12.5"), jsonValue(duckdb.Decimal{Width: 4, Scale: 2, Value: big.NewInt(1250)}, "DECIMAL(4,2)"))
+	assert.Equal(t, json.Number("170141183460469231731687303715884105727"),
+		jsonValue(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1)), "HUGEINT"))
+	assert.Equal(t, "NaN", jsonValue(math.NaN(), "DOUBLE"))
+	assert.Equal(t, map[string]interface{}{"1": "a"}, jsonValue(duckdb.Map{int32(1): []byte("a")}, "MAP"))
+	assert.Nil(t, jsonValue(nil, "VARCHAR"))
+}
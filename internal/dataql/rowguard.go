@@ -0,0 +1,72 @@
+package dataql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/sqlguard"
+)
+
+// guardedStatements are the leading keywords of the queries --max-rows-warn
+// counts the rows of
+var guardedStatements = map[string]bool{
+	"SELECT": true,
+	"WITH":   true,
+	"FROM":   true,
+	"VALUES": true,
+	"TABLE":  true,
+}
+
+// confirmFunc shows prompt and returns the answer typed. The REPL sets one
+// when results are printed to a terminal.
+type confirmFunc func(prompt string) (string, error)
+
+// guardRows asks before a REPL query prints more than --max-rows-warn rows
+// to the terminal: the user prints them all, only the first ones, or none.
+// It returns the statement to run, with a LIMIT when asked for, and false
+// when the query is cancelled.
+func (d *dataQL) guardRows(line string) (string, bool) {
+	limit := d.params.MaxRowsWarn
+	if limit <= 0 || d.confirm == nil {
+		return line, true
+	}
+
+	// Only single read queries are counted, as counting runs them once more
+	// and other statements cannot be wrapped
+	query := ApplyQueryParams(line, d.queryParams)
+	statements := sqlguard.Statements(query)
+	if len(statements) != 1 || !guardedStatements[statements[0][0]] || sqlguard.CheckReadOnly(query) != nil {
+		return line, true
+	}
+	ctx, done := d.withInterrupt()
+	count, err := d.queryCountContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 FROM (%s) AS guarded LIMIT %d) AS counted", trimStatement(query), limit+1))
+	cancelled := ctx.Err() != nil
+	done()
+	if cancelled {
+		// Ctrl-C while counting cancels the query
+		return "", false
+	}
+	if err != nil || count <= int64(limit) {
+		// A query that fails is run as is to report its error
+		return line, true
+	}
+
+	answer, err := d.confirm(fmt.Sprintf("The query returns more than %d rows (--max-rows-warn). Print them? [y]es, [l]imit to %d, [N]o: ", limit, limit))
+	if err != nil {
+		// Ctrl-C or Ctrl-D cancel the query
+		return "", false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return line, true
+	case "l", "limit":
+		return fmt.Sprintf("SELECT * FROM (%s) AS guarded LIMIT %d", trimStatement(line), limit), true
+	}
+	return "", false
+}
+
+// trimStatement returns statement without its spaces and final semicolon, to
+// be wrapped as a subquery
+func trimStatement(statement string) string {
+	return strings.TrimSuffix(strings.TrimSpace(statement), ";")
+}
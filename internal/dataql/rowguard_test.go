@@ -0,0 +1,59 @@
+package dataql
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardRows(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "orders.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte("id\n1\n2\n3\n"), 0644))
+
+	dql, err := New(Params{FileInputs: []string{csvPath}, Delimiter: ",", Quiet: true, MaxRowsWarn: 2})
+	require.NoError(t, err)
+	defer dql.Close()
+	d := dql.(*dataQL)
+	require.NoError(t, d.importData())
+
+	var prompts int
+	answer := ""
+	d.confirm = func(string) (string, error) {
+		prompts++
+		return answer, nil
+	}
+
+	for _, tc := range []struct {
+		answer   string
+		expected string
+		run      bool
+	}{
+		{"y", "SELECT * FROM orders;", true},
+		{"l", "SELECT * FROM (SELECT * FROM orders) AS guarded LIMIT 2", true},
+		{"", "", false},
+	} {
+		answer = tc.answer
+		query, run := d.guardRows("SELECT * FROM orders;")
+		assert.Equal(t, tc.expected, query)
+		assert.Equal(t, tc.run, run)
+	}
+	assert.Equal(t, 3, prompts)
+
+	// Small results, statements other than queries and failing queries run
+	// without asking
+	for _, query := range []string{"SELECT * FROM orders LIMIT 2", "SHOW TABLES", "CREATE TABLE t AS SELECT * FROM orders", "SELECT * FROM missing"} {
+		guarded, run := d.guardRows(query)
+		assert.Equal(t, query, guarded)
+		assert.True(t, run)
+	}
+	assert.Equal(t, 3, prompts)
+
+	// Ctrl-C at the prompt cancels the query
+	d.confirm = func(string) (string, error) { return "", errors.New("interrupt") }
+	_, run := d.guardRows("SELECT * FROM orders")
+	assert.False(t, run)
+}
@@ -0,0 +1,61 @@
+package dataql
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+)
+
+// isInteractive reports whether the REPL input comes from a terminal
+func isInteractive() bool {
+	return readline.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// runScript executes dot-commands and SQL statements read from a
+// non-interactive input (e.g. a pipe), one per line, until EOF. No prompt or
+// colors are printed. Execution stops at the first failing line unless
+// --continue-on-error is set; in both cases a failure is returned so the
+// process exits with a non-zero status.
+func (d *dataQL) runScript(r io.Reader) error {
+	color.NoColor = true
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	lineNumber := 0
+	failed := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+
+		if err := d.executeQuery(line); err != nil {
+			if errors.Is(err, io.EOF) {
+				break // \q or .quit ends the script
+			}
+			if !d.params.ContinueOnError {
+				return fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			fmt.Fprintf(os.Stderr, "line %d: %s\n", lineNumber, err.Error())
+			failed++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d statement(s) failed", failed)
+	}
+
+	return nil
+}
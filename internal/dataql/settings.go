@@ -0,0 +1,44 @@
+package dataql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+var settingName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseSettings parses DuckDB settings written as name=value, such as
+// threads=4 or memory_limit=2GB
+func ParseSettings(settings []string) ([][2]string, error) {
+	parsed := make([][2]string, 0, len(settings))
+	for _, setting := range settings {
+		name, value, ok := strings.Cut(setting, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || !settingName.MatchString(name) || value == "" {
+			return nil, fmt.Errorf("invalid setting %q (expected name=value, e.g. threads=4)", setting)
+		}
+		parsed = append(parsed, [2]string{name, value})
+	}
+	return parsed, nil
+}
+
+// applySettings sets DuckDB settings for every connection of the storage,
+// before the sources are imported so that imports use them too
+func applySettings(st storage.Storage, settings []string) error {
+	parsed, err := ParseSettings(settings)
+	if err != nil {
+		return err
+	}
+
+	for _, setting := range parsed {
+		rows, err := st.Query(fmt.Sprintf("SET GLOBAL %s = '%s'", setting[0], strings.ReplaceAll(setting[1], "'", "''")))
+		if err != nil {
+			return fmt.Errorf("failed to set %s: %w", setting[0], err)
+		}
+		_ = rows.Close()
+	}
+	return nil
+}
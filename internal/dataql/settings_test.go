@@ -0,0 +1,19 @@
+package dataql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSettings(t *testing.T) {
+	settings, err := ParseSettings([]string{"threads=4", " memory_limit = 2GB "})
+	require.NoError(t, err)
+	assert.Equal(t, [][2]string{{"threads", "4"}, {"memory_limit", "2GB"}}, settings)
+
+	for _, setting := range []string{"threads", "threads=", "=4", "bad name=1", "x;DROP=1"} {
+		_, err := ParseSettings([]string{setting})
+		assert.Error(t, err, setting)
+	}
+}
@@ -0,0 +1,75 @@
+package dataql
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/chzyer/readline"
+)
+
+// errQueryCancelled is reported when Ctrl-C interrupts a REPL query
+var errQueryCancelled = errors.New("query cancelled")
+
+// captureSignals installs the REPL signal handler. Ctrl-C cancels the running
+// operation (a query or \watch) when there is one; otherwise, like SIGTERM,
+// it closes the readline instance so the REPL exits gracefully.
+// The returned function removes the handler.
+func (d *dataQL) captureSignals(l *readline.Instance) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig == os.Interrupt && d.cancelRunning() {
+					continue
+				}
+				_ = l.Close()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// withInterrupt returns a context that is cancelled when the user presses Ctrl-C,
+// together with a function that must be called once the operation finishes.
+// Operations nest: Ctrl-C cancels the innermost one, such as a query run by
+// \watch, and then the enclosing one again.
+func (d *dataQL) withInterrupt() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d.mu.Lock()
+	enclosing := d.cancel
+	d.cancel = cancel
+	d.mu.Unlock()
+
+	return ctx, func() {
+		d.mu.Lock()
+		d.cancel = enclosing
+		d.mu.Unlock()
+		cancel()
+	}
+}
+
+// cancelRunning cancels the running operation, reporting whether there was one
+func (d *dataQL) cancelRunning() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cancel == nil {
+		return false
+	}
+	d.cancel()
+	return true
+}
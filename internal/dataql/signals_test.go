@@ -0,0 +1,57 @@
+package dataql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithInterruptNests(t *testing.T) {
+	d := &dataQL{}
+	assert.False(t, d.cancelRunning())
+
+	watch, doneWatch := d.withInterrupt()
+	query, doneQuery := d.withInterrupt()
+
+	// Ctrl-C cancels the query, then the enclosing operation
+	assert.True(t, d.cancelRunning())
+	assert.Error(t, query.Err())
+	assert.NoError(t, watch.Err())
+	doneQuery()
+
+	assert.True(t, d.cancelRunning())
+	assert.Error(t, watch.Err())
+	doneWatch()
+	assert.False(t, d.cancelRunning())
+}
+
+func TestPrintQueryCancelled(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "orders.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte("id\n1\n"), 0644))
+
+	dql, err := New(Params{FileInputs: []string{csvPath}, Delimiter: ",", Quiet: true})
+	require.NoError(t, err)
+	defer dql.Close()
+	d := dql.(*dataQL)
+	require.NoError(t, d.importData())
+
+	// Press Ctrl-C once the query runs
+	go func() {
+		for !d.cancelRunning() {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	start := time.Now()
+	_, err = d.printQuery("SELECT COUNT(*) FROM range(1000000000000) AS a(n) WHERE n % 7 = 3")
+	assert.ErrorIs(t, err, errQueryCancelled)
+	assert.Less(t, time.Since(start), 30*time.Second)
+
+	// The session keeps working
+	count, err := d.queryCount("SELECT COUNT(*) FROM orders")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
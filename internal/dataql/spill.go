@@ -0,0 +1,128 @@
+package dataql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// DefaultMaxResultMemory is the memory results are buffered in before they
+// are streamed or spilled to disk
+const DefaultMaxResultMemory = 256 << 20
+
+// maxResultMemory returns the limit of --max-result-memory
+func (d *dataQL) maxResultMemory() int64 {
+	if d.params.MaxResultMemory > 0 {
+		return d.params.MaxResultMemory
+	}
+	return DefaultMaxResultMemory
+}
+
+// rowSize estimates the memory a buffered row takes
+func rowSize(values []interface{}) int64 {
+	var size int64
+	for _, value := range values {
+		// Interface header plus the content of variable-length values
+		size += 16
+		switch v := value.(type) {
+		case string:
+			size += int64(len(v))
+		case []byte:
+			size += int64(len(v))
+		default:
+			size += 8
+		}
+	}
+	return size
+}
+
+// jsonArrayWriter writes the rows of printJSONRows one at a time, in the
+// format json.Encoder writes the whole array in
+type jsonArrayWriter struct {
+	w    io.Writer
+	rows int
+}
+
+// write appends row to the array
+func (j *jsonArrayWriter) write(row map[string]interface{}) error {
+	data, err := json.MarshalIndent(row, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	separator := "[\n  "
+	if j.rows > 0 {
+		separator = ",\n  "
+	}
+	j.rows++
+	if _, err := io.WriteString(j.w, separator); err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+// close ends the array
+func (j *jsonArrayWriter) close() error {
+	end := "\n]\n"
+	if j.rows == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+// spillBuffer keeps output in memory up to limit bytes, then moves it to a
+// temporary file in dir (the system one when empty), so rendering a huge
+// result for the pager does not exhaust memory
+type spillBuffer struct {
+	limit int64
+	dir   string
+	mem   bytes.Buffer
+	file  *os.File
+}
+
+// Write implements io.Writer
+func (s *spillBuffer) Write(p []byte) (int, error) {
+	if s.file == nil && int64(s.mem.Len()+len(p)) > s.limit {
+		file, err := os.CreateTemp(s.dir, "dataql-result-*")
+		if err != nil {
+			return 0, fmt.Errorf("failed to spill result to disk: %w", err)
+		}
+		s.file = file
+		if _, err := s.file.Write(s.mem.Bytes()); err != nil {
+			return 0, fmt.Errorf("failed to spill result to disk: %w", err)
+		}
+		s.mem = bytes.Buffer{}
+	}
+	if s.file != nil {
+		return s.file.Write(p)
+	}
+	return s.mem.Write(p)
+}
+
+// spilled reports whether the output was moved to disk
+func (s *spillBuffer) spilled() bool {
+	return s.file != nil
+}
+
+// reader returns the written output from its start
+func (s *spillBuffer) reader() (io.Reader, error) {
+	if s.file == nil {
+		return bytes.NewReader(s.mem.Bytes()), nil
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return s.file, nil
+}
+
+// Close removes the temporary file
+func (s *spillBuffer) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	_ = s.file.Close()
+	return os.Remove(s.file.Name())
+}
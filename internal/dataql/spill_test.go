@@ -0,0 +1,64 @@
+package dataql
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONArrayWriter(t *testing.T) {
+	for _, rows := range [][]map[string]interface{}{
+		{},
+		{{"id": 1, "name": "<Ana>"}},
+		{{"id": 1, "tags": []string{"a"}}, {"id": 2, "tags": nil}},
+	} {
+		var expected bytes.Buffer
+		encoder := json.NewEncoder(&expected)
+		encoder.SetIndent("", "  ")
+		require.NoError(t, encoder.Encode(rows))
+
+		var actual bytes.Buffer
+		writer := &jsonArrayWriter{w: &actual}
+		for _, row := range rows {
+			require.NoError(t, writer.write(row))
+		}
+		require.NoError(t, writer.close())
+		assert.Equal(t, expected.String(), actual.String())
+		assert.Equal(t, len(rows), writer.rows)
+	}
+}
+
+func TestSpillBuffer(t *testing.T) {
+	dir := t.TempDir()
+	buf := &spillBuffer{limit: 8, dir: dir}
+	_, err := buf.Write([]byte("12345"))
+	require.NoError(t, err)
+	assert.False(t, buf.spilled())
+
+	_, err = buf.Write([]byte("67890"))
+	require.NoError(t, err)
+	assert.True(t, buf.spilled())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	reader, err := buf.reader()
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "1234567890", string(content))
+
+	require.NoError(t, buf.Close())
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRowSize(t *testing.T) {
+	assert.Equal(t, int64(16+5+16+8+16+3), rowSize([]interface{}{"hello", int64(1), []byte("abc")}))
+}
@@ -0,0 +1,141 @@
+package dataql
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// tableChunkRows is the number of rows the columns of a table are aligned
+	// on, and the number of rows written to the output at a time
+	tableChunkRows = 1000
+	// tablePadding is the space after the values of a column, as in
+	// rodaine/table
+	tablePadding = 2
+)
+
+// tableStream prints a table as its rows are read, in the layout of
+// rodaine/table: the columns are as wide as the widest values of the first
+// rows, so a result of any size starts printing at once and only a chunk of
+// it is held in memory. A later value wider than its column pushes the rest
+// of its line to the right.
+type tableStream struct {
+	w       *bufio.Writer
+	header  []string
+	widths  []int
+	limit   int64      // Bytes of the first rows held to align the columns
+	sample  [][]string // First rows, until the widths are set
+	sampled int64
+	lines   int
+}
+
+// newTableStream returns a table of columns written to w, aligned on the
+// first rows that fit in limit bytes
+func newTableStream(w io.Writer, columns []string, limit int64) *tableStream {
+	return &tableStream{w: bufio.NewWriter(w), header: columns, limit: limit}
+}
+
+// add prints the row of values, or holds it until the widths are set
+func (t *tableStream) add(values []interface{}) error {
+	lines := tableLines(values, len(t.header))
+	if t.widths == nil {
+		t.sample = append(t.sample, lines...)
+		t.sampled += rowSize(values)
+		if len(t.sample) < tableChunkRows && t.sampled < t.limit {
+			return nil
+		}
+		return t.align()
+	}
+
+	for _, line := range lines {
+		t.printLine(line)
+	}
+	if t.lines >= tableChunkRows {
+		t.lines = 0
+		return t.w.Flush()
+	}
+	return nil
+}
+
+// close prints the rows held and flushes the output
+func (t *tableStream) close() error {
+	if t.widths == nil {
+		return t.align()
+	}
+	return t.w.Flush()
+}
+
+// align sets the widths of the columns from the header and the first rows,
+// then prints them
+func (t *tableStream) align() error {
+	t.widths = make([]int, len(t.header))
+	for _, line := range append([][]string{t.header}, t.sample...) {
+		for i, value := range line {
+			if width := utf8.RuneCountInString(value) + tablePadding; width > t.widths[i] {
+				t.widths[i] = width
+			}
+		}
+	}
+
+	headerFormatter := color.New(color.FgGreen, color.Underline).SprintfFunc()
+	fmt.Fprint(t.w, headerFormatter(strings.Repeat("%s", len(t.header))+"\n", t.pad(t.header)...))
+	for _, line := range t.sample {
+		t.printLine(line)
+	}
+	t.sample = nil
+	t.lines = 0
+	return t.w.Flush()
+}
+
+// printLine prints one line of a row, with its first column highlighted
+func (t *tableStream) printLine(line []string) {
+	values := t.pad(line)
+	if len(values) > 0 {
+		values[0] = color.New(color.FgYellow).Sprintf("%s", values[0])
+	}
+	fmt.Fprintf(t.w, strings.Repeat("%s", len(values))+"\n", values...)
+	t.lines++
+}
+
+// pad fills the values of line to the widths of their columns, keeping a
+// space after values wider than theirs
+func (t *tableStream) pad(line []string) []interface{} {
+	values := make([]interface{}, len(line))
+	for i, value := range line {
+		padding := t.widths[i] - utf8.RuneCountInString(value)
+		if padding < 1 {
+			padding = 1
+		}
+		values[i] = value + strings.Repeat(" ", padding)
+	}
+	return values
+}
+
+// tableLines returns the lines a row takes in a table of columns: values
+// with line breaks continue on the following lines
+func tableLines(values []interface{}, columns int) [][]string {
+	cells := make([][]string, columns)
+	count := 1
+	for i := 0; i < columns && i < len(values); i++ {
+		cells[i] = strings.Split(fmt.Sprint(values[i]), "\n")
+		if len(cells[i]) > count {
+			count = len(cells[i])
+		}
+	}
+
+	lines := make([][]string, count)
+	for n := range lines {
+		lines[n] = make([]string, columns)
+		for i, cell := range cells {
+			if n < len(cell) {
+				lines[n][i] = cell[n]
+			}
+		}
+	}
+	return lines
+}
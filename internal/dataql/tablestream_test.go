@@ -0,0 +1,76 @@
+package dataql
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/rodaine/table"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableStreamLayout(t *testing.T) {
+	for _, noColor := range []bool{true, false} {
+		previous := color.NoColor
+		color.NoColor = noColor
+
+		rows := [][]interface{}{
+			{int64(1), "Ana", nil},
+			{int64(22), "multi\nline", "ação"},
+		}
+
+		var expected bytes.Buffer
+		tbl := table.New("id", "name", "note").
+			WithHeaderFormatter(color.New(color.FgGreen, color.Underline).SprintfFunc()).
+			WithFirstColumnFormatter(color.New(color.FgYellow).SprintfFunc()).
+			WithWriter(&expected)
+		for _, row := range rows {
+			tbl.AddRow(row...)
+		}
+		tbl.Print()
+
+		var actual bytes.Buffer
+		stream := newTableStream(&actual, []string{"id", "name", "note"}, DefaultMaxResultMemory)
+		for _, row := range rows {
+			require.NoError(t, stream.add(row))
+		}
+		require.NoError(t, stream.close())
+
+		color.NoColor = previous
+		assert.Equal(t, expected.String(), actual.String())
+	}
+}
+
+func TestTableStreamChunks(t *testing.T) {
+	previous := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = previous }()
+
+	var out bytes.Buffer
+	stream := newTableStream(&out, []string{"id", "name"}, DefaultMaxResultMemory)
+	for i := 0; i < tableChunkRows; i++ {
+		require.NoError(t, stream.add([]interface{}{i, "a"}))
+	}
+	// The first rows are printed once they set the widths
+	assert.Equal(t, tableChunkRows+1, strings.Count(out.String(), "\n"))
+
+	require.NoError(t, stream.add([]interface{}{tableChunkRows, "wider than the column"}))
+	require.NoError(t, stream.close())
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, tableChunkRows+2)
+	assert.Equal(t, "id   name  ", lines[0])
+	assert.Equal(t, fmt.Sprintf("%-5d%s ", tableChunkRows, "wider than the column"), lines[len(lines)-1])
+}
+
+func TestTableStreamMemoryLimit(t *testing.T) {
+	var out bytes.Buffer
+	stream := newTableStream(&out, []string{"name"}, 64)
+	require.NoError(t, stream.add([]interface{}{strings.Repeat("x", 100)}))
+	// Past the limit, the widths are set from the rows held so far
+	assert.NotEmpty(t, out.String())
+	require.NoError(t, stream.close())
+}
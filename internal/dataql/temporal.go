@@ -0,0 +1,301 @@
+package dataql
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// temporalDailySpan is the longest span, in days, counted per day; longer
+	// spans are counted per week up to temporalWeeklySpan, then per month
+	temporalDailySpan  = 62
+	temporalWeeklySpan = 730
+	// isoDatePattern matches the date part of ISO 8601 values
+	isoDatePattern = `\d{4}-\d{2}-\d{2}`
+	// isoTimePattern matches the time of day that may follow an ISO date
+	isoTimePattern = `[ T]\d{2}:\d{2}(:\d{2}(\.\d+)?)?`
+	// zonePattern matches a trailing UTC designator or offset
+	zonePattern = `(Z|[+-]\d{2}(:?\d{2})?)`
+	// temporalFormatsLimit is the number of formats and UTC offsets reported per column
+	temporalFormatsLimit = 10
+)
+
+// TemporalReport describes the range of a date or timestamp column, the
+// records per period with the periods that have none, and, for text columns
+// holding dates, the formats and UTC offsets the values are written in
+type TemporalReport struct {
+	Min         string        `json:"min"`
+	Max         string        `json:"max"`
+	SpanDays    float64       `json:"span_days"`
+	Granularity string        `json:"granularity"` // day, week or month
+	Periods     []PeriodCount `json:"periods"`
+	Gaps        int64         `json:"gaps"` // Periods without records between min and max
+	LargestGap  *TemporalGap  `json:"largest_gap,omitempty"`
+	Formats     []ValueCount  `json:"formats,omitempty"`
+	TimeZones   []ValueCount  `json:"time_zones,omitempty"`
+}
+
+// PeriodCount is the number of records in the day, week or month starting at Period
+type PeriodCount struct {
+	Period string `json:"period"`
+	Count  int64  `json:"count"`
+}
+
+// TemporalGap is a run of consecutive periods without records
+type TemporalGap struct {
+	From    string `json:"from"` // First missing period
+	To      string `json:"to"`   // Last missing period
+	Periods int64  `json:"periods"`
+}
+
+// inconsistent reports whether the values mix formats or UTC offsets
+func (r *TemporalReport) inconsistent() bool {
+	return len(r.Formats) > 1 || len(r.TimeZones) > 1
+}
+
+// temporalColumn returns the column as a TIMESTAMP expression for date and
+// timestamp columns, and for text columns where at least half of the
+// non-empty values parse as dates. dateOnly is set when no value has a
+// time of day. ok is false for other columns.
+func (d *dataQL) temporalColumn(tableName string, col ColumnProfile) (expr string, dateOnly, ok bool) {
+	escapedColumn := fmt.Sprintf("\"%s\"", col.Name)
+
+	upperType := strings.ToUpper(col.Type)
+	if isDateTimeType(col.Type) && !strings.Contains(upperType, "INTERVAL") && upperType != "TIME" {
+		return fmt.Sprintf("CAST(%s AS TIMESTAMP)", escapedColumn), upperType == "DATE", true
+	}
+	if !isTextType(col.Type) {
+		return "", false, false
+	}
+
+	expr = fmt.Sprintf("TRY_CAST(%s AS TIMESTAMP)", escapedColumn)
+	total, err := d.queryCount(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s <> ''", tableName, escapedColumn))
+	if err != nil || total == 0 {
+		return "", false, false
+	}
+	parsed, err := d.queryCount(fmt.Sprintf("SELECT COUNT(%s) FROM %s WHERE %s <> ''", expr, tableName, escapedColumn))
+	if err != nil || parsed*2 < total {
+		return "", false, false
+	}
+
+	return expr, d.inferTemporalType(tableName, escapedColumn, col.Type) == "DATE", true
+}
+
+// temporalGranularity picks the period to count records by from the span
+func temporalGranularity(spanDays float64) string {
+	switch {
+	case spanDays <= temporalDailySpan:
+		return "day"
+	case spanDays <= temporalWeeklySpan:
+		return "week"
+	}
+	return "month"
+}
+
+// nextPeriod returns the start of the period following t
+func nextPeriod(t time.Time, granularity string) time.Time {
+	switch granularity {
+	case "day":
+		return t.AddDate(0, 0, 1)
+	case "week":
+		return t.AddDate(0, 0, 7)
+	}
+	return t.AddDate(0, 1, 0)
+}
+
+// formatTemporal renders a date, or a timestamp when the column has times of day
+func formatTemporal(t time.Time, dateOnly bool) string {
+	if dateOnly {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// getTemporal profiles a date or timestamp column; other columns, and
+// columns with only NULL values, have no report
+func (d *dataQL) getTemporal(tableName string, col ColumnProfile) (*TemporalReport, error) {
+	expr, dateOnly, ok := d.temporalColumn(tableName, col)
+	if !ok {
+		return nil, nil
+	}
+
+	rangeRows, err := d.storage.Query(fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", expr, expr, tableName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get date range: %w", err)
+	}
+	var minValue, maxValue *time.Time
+	if rangeRows.Next() {
+		if err := rangeRows.Scan(&minValue, &maxValue); err != nil {
+			rangeRows.Close()
+			return nil, fmt.Errorf("failed to read date range: %w", err)
+		}
+	}
+	rangeRows.Close()
+
+	if minValue == nil || maxValue == nil {
+		return nil, nil // Only NULL or unparsable values
+	}
+
+	spanDays := maxValue.Sub(*minValue).Hours() / 24
+	report := &TemporalReport{
+		Min:         formatTemporal(minValue.UTC(), dateOnly),
+		Max:         formatTemporal(maxValue.UTC(), dateOnly),
+		SpanDays:    spanDays,
+		Granularity: temporalGranularity(spanDays),
+	}
+
+	if err := d.countPeriods(tableName, expr, report); err != nil {
+		return nil, err
+	}
+
+	if isTextType(col.Type) {
+		escapedColumn := fmt.Sprintf("\"%s\"", col.Name)
+		formatExpr := fmt.Sprintf(`CASE
+			WHEN regexp_matches(%[1]s, '^%[2]s$') THEN 'date'
+			WHEN regexp_matches(%[1]s, '^%[2]s%[3]s$') THEN 'timestamp'
+			WHEN regexp_matches(%[1]s, '^%[2]s%[3]s\s*%[4]s$') THEN 'timestamp with offset'
+			ELSE 'other' END`, escapedColumn, isoDatePattern, isoTimePattern, zonePattern)
+		if report.Formats, err = d.getTopValues(fmt.Sprintf("(SELECT %s AS format FROM %s WHERE %s <> '') AS formats",
+			formatExpr, tableName, escapedColumn), "format", temporalFormatsLimit); err != nil {
+			return nil, fmt.Errorf("failed to classify date formats: %w", err)
+		}
+
+		zoneExpr := fmt.Sprintf("regexp_extract(%s, '%s%s\\s*%s$', 3)", escapedColumn, isoDatePattern, isoTimePattern, zonePattern)
+		if report.TimeZones, err = d.getTopValues(fmt.Sprintf("(SELECT NULLIF(%s, '') AS zone FROM %s) AS zones",
+			zoneExpr, tableName), "zone", temporalFormatsLimit); err != nil {
+			return nil, fmt.Errorf("failed to read UTC offsets: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+// countPeriods fills the records per period of the report, including the
+// empty periods between the first and the last record, and counts the gaps
+func (d *dataQL) countPeriods(tableName, expr string, report *TemporalReport) error {
+	query := fmt.Sprintf("SELECT date_trunc('%s', %s) AS period, COUNT(*) FROM %s WHERE %s IS NOT NULL GROUP BY 1 ORDER BY 1",
+		report.Granularity, expr, tableName, expr)
+	rows, err := d.storage.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to count records per %s: %w", report.Granularity, err)
+	}
+	defer rows.Close()
+
+	var next time.Time
+	var gap *TemporalGap
+	for rows.Next() {
+		var period time.Time
+		var count int64
+		if err := rows.Scan(&period, &count); err != nil {
+			return fmt.Errorf("failed to read records per %s: %w", report.Granularity, err)
+		}
+		period = period.UTC()
+
+		// Fill the periods skipped since the previous record
+		for !next.IsZero() && next.Before(period) {
+			label := next.Format("2006-01-02")
+			report.Periods = append(report.Periods, PeriodCount{Period: label})
+			report.Gaps++
+			if gap == nil {
+				gap = &TemporalGap{From: label}
+			}
+			gap.To = label
+			gap.Periods++
+			next = nextPeriod(next, report.Granularity)
+		}
+		if gap != nil && (report.LargestGap == nil || gap.Periods > report.LargestGap.Periods) {
+			report.LargestGap = gap
+		}
+		gap = nil
+
+		report.Periods = append(report.Periods, PeriodCount{Period: period.Format("2006-01-02"), Count: count})
+		next = nextPeriod(period, report.Granularity)
+	}
+
+	return rows.Err()
+}
+
+// printTemporal prints the range, records per period, gaps and formats of
+// every date and timestamp column of a table
+func (d *dataQL) printTemporal(tableName string) error {
+	columns, err := d.getTableColumns(tableName)
+	if err != nil {
+		return err
+	}
+
+	sectionColor := color.New(color.FgCyan)
+	sectionColor.Fprintf(d.out, "\nTemporal:\n")
+
+	found := false
+	for _, col := range columns {
+		report, err := d.getTemporal(tableName, col)
+		if err != nil {
+			return fmt.Errorf("failed to profile dates of %s: %w", col.Name, err)
+		}
+		if report == nil {
+			continue
+		}
+		found = true
+
+		fmt.Fprintf(d.out, "\n%s (%s)\n", color.YellowString(col.Name), col.Type)
+		fmt.Fprintf(d.out, "  Range: %s .. %s (%s days)\n", report.Min, report.Max, formatBound(report.SpanDays))
+		fmt.Fprintf(d.out, "  Records per %s:\n", report.Granularity)
+		printPeriods(d.out, report.Periods)
+
+		if report.LargestGap != nil {
+			fmt.Fprintf(d.out, "  Gaps: %d %ss without records, longest %s .. %s (%d %ss)\n", report.Gaps, report.Granularity,
+				report.LargestGap.From, report.LargestGap.To, report.LargestGap.Periods, report.Granularity)
+		} else {
+			fmt.Fprintln(d.out, "  Gaps: none")
+		}
+
+		if len(report.Formats) > 0 {
+			fmt.Fprintf(d.out, "  Formats: %s\n", formatValueCounts(report.Formats))
+		}
+		if len(report.TimeZones) > 0 {
+			fmt.Fprintf(d.out, "  UTC offsets: %s\n", formatValueCounts(report.TimeZones))
+		}
+		if report.inconsistent() {
+			fmt.Fprintln(d.out, color.YellowString("  Warning: values mix formats or UTC offsets"))
+		}
+	}
+
+	if !found {
+		fmt.Fprintln(d.out, "\nNo date or timestamp columns found.")
+	}
+	return nil
+}
+
+// printPeriods prints one line per period with a bar proportional to its count
+func printPeriods(w io.Writer, periods []PeriodCount) {
+	var maxCount int64
+	for _, p := range periods {
+		if p.Count > maxCount {
+			maxCount = p.Count
+		}
+	}
+
+	for _, p := range periods {
+		bar := 0
+		if maxCount > 0 {
+			bar = int(p.Count * histogramBarWidth / maxCount)
+		}
+		if bar == 0 && p.Count > 0 {
+			bar = 1 // Keep non-empty periods visible
+		}
+		fmt.Fprintf(w, "    %s  %-*s %d\n", p.Period, histogramBarWidth, strings.Repeat("█", bar), p.Count)
+	}
+}
+
+// formatValueCounts renders values and their counts as "value N (P%), ..."
+func formatValueCounts(values []ValueCount) string {
+	parts := make([]string, len(values))
+	for i, vc := range values {
+		parts[i] = fmt.Sprintf("%s %d (%.1f%%)", vc.Value, vc.Count, vc.Pct)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,59 @@
+package dataql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemporalGranularity(t *testing.T) {
+	assert.Equal(t, "day", temporalGranularity(30))
+	assert.Equal(t, "week", temporalGranularity(365))
+	assert.Equal(t, "month", temporalGranularity(3650))
+}
+
+func TestProfile_Temporal(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "events.csv")
+	content := "id,created,logged\n" +
+		"1,2024-01-01,2024-01-01T10:00:00Z\n" +
+		"2,2024-01-02,2024-01-02 11:00:00+02:00\n" +
+		"3,2024-01-06,2024-01-03 08:00:00\n" +
+		"4,2024-01-06,01/05/2024\n"
+	assert.NoError(t, os.WriteFile(csvPath, []byte(content), 0644))
+
+	dql, err := New(Params{FileInputs: []string{csvPath}, Delimiter: ",", Quiet: true})
+	assert.NoError(t, err)
+	defer dql.Close()
+
+	tables, err := dql.Profile(DescribeOptions{Temporal: true})
+	assert.NoError(t, err)
+	assert.Len(t, tables, 1)
+
+	columns := tables[0].Columns
+	assert.Nil(t, columns[0].Temporal, "numbers are not dates")
+
+	created := columns[1].Temporal
+	if assert.NotNil(t, created) {
+		assert.Equal(t, "2024-01-01", created.Min)
+		assert.Equal(t, "2024-01-06", created.Max)
+		assert.Equal(t, "day", created.Granularity)
+		assert.Len(t, created.Periods, 6)
+		assert.Equal(t, PeriodCount{Period: "2024-01-06", Count: 2}, created.Periods[5])
+		assert.Equal(t, int64(3), created.Gaps)
+		assert.Equal(t, &TemporalGap{From: "2024-01-03", To: "2024-01-05", Periods: 3}, created.LargestGap)
+		assert.False(t, created.inconsistent())
+	}
+
+	// Offsets are converted to UTC and unparsable values are left out of the range
+	logged := columns[2].Temporal
+	if assert.NotNil(t, logged) {
+		assert.Equal(t, "2024-01-01 10:00:00", logged.Min)
+		assert.Equal(t, "2024-01-03 08:00:00", logged.Max)
+		assert.Zero(t, logged.Gaps)
+		assert.Len(t, logged.Formats, 3)
+		assert.Len(t, logged.TimeZones, 2)
+		assert.True(t, logged.inconsistent())
+	}
+}
@@ -0,0 +1,136 @@
+package dataql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adrianolaselva/dataql/internal/exportdata"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/adrianolaselva/dataql/pkg/timezone"
+)
+
+// localTimestampFunction is the SQL function reading timestamps in --input-tz
+const localTimestampFunction = "dataql_local_timestamp"
+
+// textTimestampPattern matches the text values read as timestamps: ISO
+// dates with a time of day and an optional UTC offset
+const textTimestampPattern = "^" + isoDatePattern + isoTimePattern + zonePattern + "?$"
+
+// localizeTimestamps converts the timestamp columns of the imported tables
+// to TIMESTAMPTZ when --input-tz is set: TIMESTAMP columns and text
+// columns whose values are all ISO timestamps. Naive values are read as
+// wall clock times in the input time zone, values with a UTC offset keep
+// it, so local times and UTC logs compare as the same instants.
+func (d *dataQL) localizeTimestamps() error {
+	if d.params.InputTZ == "" {
+		return nil
+	}
+	location, err := timezone.Load(d.params.InputTZ)
+	if err != nil {
+		return err
+	}
+
+	fs, ok := d.storage.(storage.FunctionStorage)
+	if !ok {
+		return fmt.Errorf("--input-tz is not supported by the storage")
+	}
+	if err := fs.RegisterFunction(storage.ScalarFunction{
+		Name:   localTimestampFunction,
+		Result: storage.TypeTimestampTZ,
+		Call: func(args []any) (any, error) {
+			return localTimestamp(args[0], location)
+		},
+	}); err != nil {
+		return err
+	}
+
+	rows, err := d.storage.Query(storage.ColumnsQuery(d.engine(), ""))
+	if err != nil {
+		return fmt.Errorf("failed to list timestamp columns: %w", err)
+	}
+	var columns [][3]string
+	for rows.Next() {
+		var column [3]string
+		if err := rows.Scan(&column[0], &column[1], &column[2]); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to list timestamp columns: %w", err)
+		}
+		columns = append(columns, column)
+	}
+	_ = rows.Close()
+
+	for _, column := range columns {
+		tableName, columnName, dataType := column[0], column[1], column[2]
+		escapedColumn := fmt.Sprintf("\"%s\"", columnName)
+		switch {
+		case strings.HasPrefix(dataType, "TIMESTAMP") && !exportdata.IsTimestampTZ(dataType):
+		case isTextType(dataType):
+			timestamps, err := d.textTimestamps(tableName, escapedColumn)
+			if err != nil {
+				return err
+			}
+			if !timestamps {
+				continue
+			}
+		default:
+			continue
+		}
+
+		verboseLog(d.params.Verbose, "Reading %s.%s as TIMESTAMPTZ in %s", tableName, columnName, location)
+		statement := fmt.Sprintf("ALTER TABLE \"%s\" ALTER COLUMN %s SET DATA TYPE TIMESTAMPTZ USING %s(%s)",
+			tableName, escapedColumn, localTimestampFunction, escapedColumn)
+		if err := d.execStatement(statement); err != nil {
+			return fmt.Errorf("failed to read %s.%s in time zone %s: %w", tableName, columnName, location, err)
+		}
+	}
+	return nil
+}
+
+// textTimestamps reports whether the non-empty values of a text column are
+// all ISO timestamps, at least one of them
+func (d *dataQL) textTimestamps(tableName, escapedColumn string) (bool, error) {
+	rows, err := d.storage.Query(fmt.Sprintf(
+		"SELECT COUNT(*) FILTER (WHERE %[1]s <> ''), COUNT(*) FILTER (WHERE %[1]s <> '' AND NOT regexp_full_match(%[1]s, '%[2]s')) FROM \"%[3]s\"",
+		escapedColumn, textTimestampPattern, tableName))
+	if err != nil {
+		return false, fmt.Errorf("failed to check timestamps of %s: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	var values, others int64
+	if rows.Next() {
+		if err := rows.Scan(&values, &others); err != nil {
+			return false, fmt.Errorf("failed to check timestamps of %s: %w", tableName, err)
+		}
+	}
+	return values > 0 && others == 0, rows.Err()
+}
+
+// localTimestamp reads a text or naive timestamp in location; empty text is NULL
+func localTimestamp(value any, location *time.Location) (any, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return timezone.Localize(v, location), nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		return timezone.Parse(v, location)
+	}
+	return nil, fmt.Errorf("unsupported timestamp value %v (%T)", value, value)
+}
+
+// readRow reads the current row for display, with dates and timestamps
+// formatted by type and TIMESTAMPTZ values in --output-tz
+func (d *dataQL) readRow(rows *sql.Rows, types []string) ([]interface{}, error) {
+	values, err := scanRow(rows, len(types))
+	if err != nil {
+		return nil, err
+	}
+	for i := range values {
+		values[i] = exportdata.TimeValue(values[i], types[i], d.outputTZ)
+	}
+	return values, nil
+}
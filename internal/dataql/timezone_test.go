@@ -0,0 +1,61 @@
+package dataql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalizeTimestamps(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "events.csv")
+	content := "id,local,logged,note\n" +
+		"1,2024-03-10 08:30:00,2024-03-10T11:30:00Z,2024-03-10 08:30:00\n" +
+		"2,2024-07-01T09:00,2024-07-01 09:00:00-03:00,later\n" +
+		"3,,,\n"
+	require.NoError(t, os.WriteFile(csvPath, []byte(content), 0644))
+
+	dql, err := New(Params{FileInputs: []string{csvPath}, Delimiter: ",", Quiet: true, InputTZ: "America/Sao_Paulo"})
+	require.NoError(t, err)
+	defer dql.Close()
+	require.NoError(t, dql.Import())
+
+	d := dql.(*dataQL)
+	types, err := d.columnTypes("events")
+	require.NoError(t, err)
+	assert.Equal(t, "TIMESTAMP WITH TIME ZONE", types["local"])
+	assert.Equal(t, "TIMESTAMP WITH TIME ZONE", types["logged"])
+	assert.Equal(t, "VARCHAR", types["note"], "text with other values is left alone")
+
+	rows, err := d.storage.Query("SELECT COUNT(*) FILTER (WHERE local = logged), COUNT(*) FILTER (WHERE local IS NULL) FROM events")
+	require.NoError(t, err)
+	defer rows.Close()
+	require.True(t, rows.Next())
+	var same, nulls int
+	require.NoError(t, rows.Scan(&same, &nulls))
+	assert.Equal(t, 2, same, "local times in the input time zone match the logged instants")
+	assert.Equal(t, 1, nulls)
+}
+
+func TestLocalTimestamp(t *testing.T) {
+	location := time.FixedZone("-03:00", -3*3600)
+	want := time.Date(2024, 3, 10, 11, 30, 0, 0, time.UTC)
+
+	got, err := localTimestamp(time.Date(2024, 3, 10, 8, 30, 0, 0, time.UTC), location)
+	require.NoError(t, err)
+	assert.True(t, want.Equal(got.(time.Time)))
+
+	got, err = localTimestamp("2024-03-10 08:30:00", location)
+	require.NoError(t, err)
+	assert.True(t, want.Equal(got.(time.Time)))
+
+	got, err = localTimestamp(" ", location)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	_, err = localTimestamp(int64(1), location)
+	assert.Error(t, err)
+}
@@ -0,0 +1,149 @@
+package dataql
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// Transaction control statements handled by dataql instead of being sent to
+// the pooled connection, so the transaction stays on a single connection
+const (
+	txBegin    = "BEGIN"
+	txCommit   = "COMMIT"
+	txRollback = "ROLLBACK"
+)
+
+// transactionCommand returns the transaction control statement in query
+// (BEGIN, COMMIT or ROLLBACK), or an empty string for any other statement
+func transactionCommand(query string) string {
+	query = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(query), ";"))
+	if strings.Contains(query, ";") {
+		// Multi-statement scripts run as a whole on a single connection
+		return ""
+	}
+
+	fields := strings.Fields(strings.ToUpper(query))
+	if len(fields) == 0 || len(fields) > 2 {
+		return ""
+	}
+
+	switch fields[0] {
+	case "BEGIN":
+		if len(fields) == 1 || fields[1] == "TRANSACTION" {
+			return txBegin
+		}
+	case "START":
+		if len(fields) == 2 && fields[1] == "TRANSACTION" {
+			return txBegin
+		}
+	case "COMMIT", "END":
+		if len(fields) == 1 || fields[1] == "TRANSACTION" {
+			return txCommit
+		}
+	case "ROLLBACK", "ABORT":
+		if len(fields) == 1 || fields[1] == "TRANSACTION" {
+			return txRollback
+		}
+	}
+
+	return ""
+}
+
+// transactionStorage returns the storage if it supports explicit transactions
+func (d *dataQL) transactionStorage() (storage.TransactionStorage, error) {
+	txStorage, ok := d.storage.(storage.TransactionStorage)
+	if !ok {
+		return nil, fmt.Errorf("current storage does not support transactions")
+	}
+	return txStorage, nil
+}
+
+// inTransaction reports whether an explicit transaction is open
+func (d *dataQL) inTransaction() bool {
+	txStorage, ok := d.storage.(storage.TransactionStorage)
+	return ok && txStorage.InTransaction()
+}
+
+// runTransactionCommand executes a BEGIN, COMMIT or ROLLBACK statement
+func (d *dataQL) runTransactionCommand(command string) error {
+	txStorage, err := d.transactionStorage()
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case txBegin:
+		err = txStorage.Begin()
+	case txCommit:
+		err = txStorage.Commit()
+	case txRollback:
+		err = txStorage.Rollback()
+	}
+	if err != nil {
+		return err
+	}
+
+	if !d.params.Plain {
+		fmt.Fprintln(d.out, command)
+	}
+	return nil
+}
+
+// ensureTransaction opens a transaction before a statement when autocommit is off
+func (d *dataQL) ensureTransaction() error {
+	if d.autocommit || d.inTransaction() {
+		return nil
+	}
+
+	txStorage, err := d.transactionStorage()
+	if err != nil {
+		return err
+	}
+	return txStorage.Begin()
+}
+
+// setAutocommit enables or disables autocommit mode
+func (d *dataQL) setAutocommit(enabled bool) error {
+	if enabled && d.inTransaction() {
+		return fmt.Errorf("a transaction is open: run COMMIT or ROLLBACK first")
+	}
+	d.autocommit = enabled
+	return nil
+}
+
+// finishTransaction commits the transaction opened for a single -q query,
+// or rolls it back when the query failed
+func (d *dataQL) finishTransaction(queryErr error) error {
+	if !d.inTransaction() {
+		return queryErr
+	}
+
+	txStorage, err := d.transactionStorage()
+	if err != nil {
+		return err
+	}
+
+	if queryErr != nil {
+		_ = txStorage.Rollback()
+		return queryErr
+	}
+	return txStorage.Commit()
+}
+
+// rollbackPending discards an uncommitted transaction when the session ends
+func (d *dataQL) rollbackPending() {
+	if !d.inTransaction() {
+		return
+	}
+
+	txStorage, err := d.transactionStorage()
+	if err != nil {
+		return
+	}
+	if err := txStorage.Rollback(); err == nil {
+		fmt.Fprintln(os.Stderr, "Warning: uncommitted transaction was rolled back")
+	}
+}
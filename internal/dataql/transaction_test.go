@@ -0,0 +1,60 @@
+package dataql
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionCommand(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"BEGIN", txBegin},
+		{"begin;", txBegin},
+		{"BEGIN TRANSACTION;", txBegin},
+		{"START TRANSACTION", txBegin},
+		{"COMMIT;", txCommit},
+		{"end", txCommit},
+		{"ROLLBACK", txRollback},
+		{"abort;", txRollback},
+		{"SELECT * FROM users", ""},
+		{"BEGIN; UPDATE users SET a = 1; COMMIT;", ""},
+		{"START users", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, transactionCommand(tt.query), tt.query)
+	}
+}
+
+func TestExec_Transactions(t *testing.T) {
+	dql, err := New(Params{
+		FileInputs:   []string{"../../tests/fixtures/csv/simple.csv"},
+		Delimiter:    ",",
+		Quiet:        true,
+		NoAutoCommit: true,
+	})
+	require.NoError(t, err)
+	defer dql.Close()
+	d := dql.(*dataQL)
+	d.SetOutput(io.Discard)
+
+	// A transaction begun by the caller stays open across calls
+	require.NoError(t, d.Exec("BEGIN"))
+	require.NoError(t, d.Exec("CREATE TABLE pending AS SELECT 1 AS x"))
+	require.NoError(t, d.Exec("SELECT * FROM pending"))
+	assert.True(t, d.inTransaction())
+	require.NoError(t, d.Exec("ROLLBACK"))
+	assert.False(t, d.inTransaction())
+	assert.Error(t, d.Exec("SELECT * FROM pending"))
+
+	// With autocommit off, a statement run on its own is committed with it
+	require.NoError(t, d.Exec("CREATE TABLE kept AS SELECT 1 AS x"))
+	assert.False(t, d.inTransaction())
+	require.NoError(t, d.Exec("SELECT * FROM kept"))
+}
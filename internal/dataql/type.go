@@ -1,25 +1,91 @@
 package dataql
 
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
 type Params struct {
-	FileInputs     []string
-	DataSourceName string
-	Delimiter      string
-	Query          string
-	Export         string
-	Type           string
-	Lines          int
-	Collection     string
-	Verbose        bool
-	Quiet          bool     // Suppress progress bar output
-	NoSchema       bool     // Suppress table schema display before query results
-	InputFormat    string   // Input format for stdin (csv, json, jsonl, xml, yaml)
-	Truncate       int      // Truncate column values longer than N characters (0 = no truncation)
-	Vertical       bool     // Display results in vertical format (like MySQL \G)
-	QueryParams    []string // Query parameters in format "name=value"
-	Cache          bool     // Enable data caching for faster subsequent queries
-	CacheDir       string   // Cache directory path (default: ~/.dataql/cache)
+	FileInputs        []string
+	DataSourceName    string
+	Delimiter         string
+	Query             string
+	Export            string
+	Type              string
+	Lines             int
+	Collection        string
+	Verbose           bool
+	Quiet             bool            // Suppress progress bar output
+	NoSchema          bool            // Suppress table schema display before query results
+	Plain             bool            // Print only the result data: no progress, table list, row count, confirmations or colors
+	OutputMode        string          // Format query results are printed in (see outputModes; default: table)
+	Attach            []string        // Storage files attached as path[:alias], queried as alias.table (see Attachment)
+	Engine            string          // Storage engine that runs the queries: duckdb (default) or sqlite (see storage.Engine)
+	ReadOnly          bool            // Open the storage and attached files read-only and reject statements that write
+	IfExists          string          // What imports do with tables already in the storage file: append (default), replace or fail (see storage.IfExists)
+	BatchSize         int             // Imported rows buffered before they are written (0: storage.DefaultBatchSize)
+	CommitInterval    int             // Imported rows inserted per transaction (0: storage.DefaultCommitInterval)
+	InputFormat       string          // Input format for stdin (csv, json, jsonl, xml, yaml)
+	JSONNested        string          // How JSON and JSONL import nested values: flatten (default), struct or json (see filehandler.Nested)
+	ColumnNames       string          // How column names are derived from headers and keys: snake, lower or keep (see filehandler.NameCase; default: the rule of each format)
+	ColumnReplace     string          // Replaces characters other than letters, digits and _ in snake and lower column names (default: removed)
+	ColumnDedupe      string          // How duplicate column names are resolved: suffix (default) or error
+	DecimalAsString   bool            // Write DECIMAL and HUGEINT values as strings in JSON and YAML output and exports
+	InputTZ           string          // Time zone of naive timestamps in the sources; set, timestamp columns become TIMESTAMPTZ
+	OutputTZ          string          // Time zone TIMESTAMPTZ values are displayed and exported in (default: UTC)
+	Truncate          int             // Truncate column values longer than N characters (0 = no truncation)
+	Vertical          bool            // Display results in vertical format (like MySQL \G)
+	QueryParams       []string        // Query parameters in format "name=value"
+	Cache             bool            // Enable data caching for faster subsequent queries
+	CacheDir          string          // Cache directory path (default: ~/.dataql/cache)
+	CacheTTL          time.Duration   // Age after which cache entries are re-imported (0: no limit)
+	CacheMaxSize      int64           // Size in bytes above which least recently used cache entries are evicted (0: no limit)
+	DownloadParts     int             // S3, GCS and Azure object parts downloaded at the same time (0: rangedownload.DefaultConcurrency)
+	DownloadPartSize  int64           // Size in bytes of the parts of S3, GCS and Azure downloads (0: rangedownload.DefaultPartSize)
+	DownloadTimeout   time.Duration   // Time allowed for each request of HTTP/HTTPS downloads (0: no limit)
+	Headers           []string        // Headers sent with HTTP/HTTPS downloads, as "Name: value"
+	CookieJar         string          // Netscape cookie file sent with HTTP/HTTPS downloads and updated with the cookies they set
+	CACert            string          // PEM file of CAs trusted by remote sources besides the system ones
+	InsecureTLS       bool            // Accept any certificate of remote sources
+	S3Endpoint        string          // S3-compatible endpoint, such as MinIO or Ceph
+	S3Profile         string          // Named AWS profile of S3 requests
+	S3AssumeRole      string          // ARN of a role assumed for S3 requests
+	S3RequesterPays   bool            // Bill S3 requests to the caller, for requester-pays buckets
+	GCSCredentials    string          // Service account key or workload identity federation file of gs:// sources
+	AzureAccount      string          // Storage account of azure:// sources
+	AzureSASToken     string          // SAS token of Azure sources
+	AzureConnString   string          // Connection string of Azure sources
+	InPlace           bool            // Query remote Parquet and CSV sources through httpfs instead of downloading them
+	Encrypt           bool            // Encrypt new storage and cache files at rest (encrypted files are always decrypted)
+	Follow            bool            // Run the query again as lines are appended to the file inputs, until Ctrl-C
+	FollowInterval    time.Duration   // Interval between checks for appended lines of --follow
+	NoAutoCommit      bool            // Run statements in an explicit transaction committed only on success
+	NoRC              bool            // Skip the REPL startup file (~/.dataqlrc)
+	ContinueOnError   bool            // Keep executing piped REPL input after a failing line
+	NoExternalAccess  bool            // Block SQL from reading or writing files and URLs once the sources are imported
+	AuditLog          string          // Append executed statements to this JSONL audit log (empty: no audit log)
+	History           string          // Record executed statements in this query history database (empty: no history)
+	Mask              []string        // Columns masked in exports, as column=method (see pkg/mask)
+	MaskSalt          string          // Salt of masked hashes and date shift
+	Lineage           bool            // Record the sources, query and dataql version in the export (see pkg/lineage)
+	FailOnEmpty       bool            // Fail with clierror.ErrEmptyResult when -q returns no rows
+	MaxRowsWarn       int             // Ask before a REPL query prints more rows than this to the terminal (0: never)
+	Settings          []string        // DuckDB settings (name=value) applied before the sources are imported
+	TempDir           string          // Directory DuckDB spills operators exceeding its memory limit to, and large paged results are written to
+	MaxResultMemory   int64           // Bytes of a result held in memory before it is streamed or spilled to TempDir (0: DefaultMaxResultMemory)
+	UDFs              []string        // User-defined functions (name[:TYPE]=path to a .lua script or .wasm module)
+	Extensions        []string        // DuckDB extensions loaded before the sources are imported (see pkg/extensions)
+	OfflineExtensions bool            // Never download extensions: load only built-in and installed ones
+	Describe          DescribeOptions // Optional analyses of dataql describe
 }
 
+// fileAlias matches the aliases of file inputs: names, so the ports and
+// query strings of connection strings are not taken for one
+var fileAlias = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
 // FileInput represents a file path with an optional table alias
 type FileInput struct {
 	Path  string // File path
@@ -27,12 +93,27 @@ type FileInput struct {
 }
 
 // ParseFileInput parses a file input string that may contain an alias
-// Format: "path" or "path:alias"
+// Format: "path", "path:alias", "path::alias" or "path=alias"
 // Examples:
 //   - "data.csv" -> FileInput{Path: "data.csv", Alias: ""}
 //   - "data.csv:users" -> FileInput{Path: "data.csv", Alias: "users"}
 //   - "/path/to/file.csv:my_table" -> FileInput{Path: "/path/to/file.csv", Alias: "my_table"}
+//   - "postgres://u:p@host:5432/shop?table=orders::orders" -> FileInput{Path: "postgres://u:p@host:5432/shop?table=orders", Alias: "orders"}
+//   - "sales_2024.csv=sales" -> FileInput{Path: "sales_2024.csv", Alias: "sales"}
 func ParseFileInput(input string) FileInput {
+	// The double colon cannot be mistaken for the colons of connection strings
+	if i := strings.LastIndex(input, "::"); i > 0 && fileAlias.MatchString(input[i+2:]) {
+		return FileInput{Path: input[:i], Alias: input[i+2:]}
+	}
+
+	// "path=alias" names local files only: the = of URLs, connection strings
+	// (country=br partitions, query strings) and file names is not an alias
+	if i := strings.LastIndex(input, "="); i > 0 && fileAlias.MatchString(input[i+1:]) && !strings.Contains(input, "://") {
+		if _, err := os.Stat(input); err != nil && localFile(input[:i]) {
+			return FileInput{Path: input[:i], Alias: input[i+1:]}
+		}
+	}
+
 	// Handle Windows paths (e.g., C:\path\file.csv)
 	// Look for the last colon that is followed by a valid alias (no slashes/backslashes)
 	lastColonIdx := -1
@@ -57,7 +138,7 @@ func ParseFileInput(input string) FileInput {
 		}
 	}
 
-	if lastColonIdx > 0 && lastColonIdx < len(input)-1 {
+	if lastColonIdx > 0 && fileAlias.MatchString(input[lastColonIdx+1:]) {
 		return FileInput{
 			Path:  input[:lastColonIdx],
 			Alias: input[lastColonIdx+1:],
@@ -67,6 +148,26 @@ func ParseFileInput(input string) FileInput {
 	return FileInput{Path: input, Alias: ""}
 }
 
+// localFile reports whether path is an existing local file
+func localFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// ParseAliases parses --alias values written as name=source into file
+// inputs importing source as table name, e.g. orders=postgres://host/shop?table=orders
+func ParseAliases(values []string) ([]string, error) {
+	inputs := make([]string, 0, len(values))
+	for _, value := range values {
+		name, source, ok := strings.Cut(value, "=")
+		if !ok || source == "" || !fileAlias.MatchString(name) {
+			return nil, fmt.Errorf("invalid --alias value %q (expected name=source, e.g. orders=postgres://host/shop?table=orders)", value)
+		}
+		inputs = append(inputs, source+"::"+name)
+	}
+	return inputs, nil
+}
+
 // ParseFileInputs parses multiple file input strings
 func ParseFileInputs(inputs []string) []FileInput {
 	result := make([]FileInput, len(inputs))
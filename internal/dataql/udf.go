@@ -0,0 +1,49 @@
+package dataql
+
+import (
+	"fmt"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/adrianolaselva/dataql/pkg/udf"
+)
+
+// registerUDFs loads the user-defined functions (name[:TYPE]=path) and
+// registers them in the storage, so SQL can call them
+func registerUDFs(st storage.Storage, specs []string) ([]*udf.Function, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	parsed, err := udf.ParseSpecs(specs)
+	if err != nil {
+		return nil, err
+	}
+
+	fs, ok := st.(storage.FunctionStorage)
+	if !ok {
+		return nil, fmt.Errorf("the storage does not support user-defined functions")
+	}
+
+	var functions []*udf.Function
+	for _, spec := range parsed {
+		fn, err := udf.Load(spec)
+		if err != nil {
+			closeUDFs(functions)
+			return nil, err
+		}
+		functions = append(functions, fn)
+
+		if err := fs.RegisterFunction(fn.ScalarFunction); err != nil {
+			closeUDFs(functions)
+			return nil, err
+		}
+	}
+	return functions, nil
+}
+
+// closeUDFs releases the interpreters and runtimes of the functions
+func closeUDFs(functions []*udf.Function) {
+	for _, fn := range functions {
+		_ = fn.Close()
+	}
+}
@@ -0,0 +1,42 @@
+package dataql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUDF_CallableFromSQL(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "mail.lua")
+	require.NoError(t, os.WriteFile(script, []byte(`
+function mail_domain(email)
+  return string.match(email, "@(.+)$")
+end
+`), 0644))
+
+	dql, err := New(Params{
+		FileInputs: []string{"../../tests/fixtures/csv/simple.csv"},
+		Delimiter:  ",",
+		Quiet:      true,
+		UDFs:       []string{"mail_domain=" + script},
+	})
+	require.NoError(t, err)
+	defer dql.Close()
+
+	result, err := dql.Query("SELECT mail_domain(email), mail_domain(NULL) FROM simple ORDER BY id LIMIT 1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{"example.com", nil}}, result.Rows)
+}
+
+func TestUDF_InvalidFunction(t *testing.T) {
+	_, err := New(Params{
+		FileInputs: []string{"../../tests/fixtures/csv/simple.csv"},
+		Delimiter:  ",",
+		Quiet:      true,
+		UDFs:       []string{"missing=" + filepath.Join(t.TempDir(), "missing.lua")},
+	})
+	assert.ErrorContains(t, err, "function missing")
+}
@@ -0,0 +1,75 @@
+package dataql
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/chzyer/readline"
+)
+
+// defaultWatchInterval is the interval used by \watch when none is given
+const defaultWatchInterval = 2 * time.Second
+
+// parseWatchArgs parses the optional "\watch [seconds] [count]" arguments
+func parseWatchArgs(args []string) (time.Duration, int, error) {
+	interval := defaultWatchInterval
+	count := 0
+
+	if len(args) > 0 {
+		seconds, err := strconv.ParseFloat(args[0], 64)
+		if err != nil || seconds <= 0 {
+			return 0, 0, fmt.Errorf("invalid watch interval: %s (must be a positive number of seconds)", args[0])
+		}
+		interval = time.Duration(seconds * float64(time.Second))
+	}
+
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("invalid watch count: %s (must be a positive integer)", args[1])
+		}
+		count = n
+	}
+
+	return interval, count, nil
+}
+
+// watchQuery re-runs the last executed query every interval until Ctrl-C is
+// pressed or, when count is greater than zero, count times
+func (d *dataQL) watchQuery(interval time.Duration, count int) error {
+	if d.lastQuery == "" {
+		return fmt.Errorf("no previous query to watch")
+	}
+
+	ctx, done := d.withInterrupt()
+	defer done()
+
+	// Only redraw in place when writing to a terminal
+	redraw := readline.IsTerminal(int(os.Stdout.Fd()))
+
+	for i := 1; count == 0 || i <= count; i++ {
+		if redraw {
+			fmt.Fprint(d.out, "\033[H\033[2J")
+		}
+		fmt.Fprintf(d.out, "Every %v: %s\t%s\n\n", interval, d.lastQuery, time.Now().Format(time.RFC1123))
+
+		if err := d.runQuery(d.lastQuery); err != nil {
+			return err
+		}
+
+		if count > 0 && i == count {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(d.out)
+			return nil
+		case <-time.After(interval):
+		}
+	}
+
+	return nil
+}
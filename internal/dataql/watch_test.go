@@ -0,0 +1,31 @@
+package dataql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWatchArgs(t *testing.T) {
+	interval, count, err := parseWatchArgs(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultWatchInterval, interval)
+	assert.Equal(t, 0, count)
+
+	interval, count, err = parseWatchArgs([]string{"0.5", "3"})
+	assert.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, interval)
+	assert.Equal(t, 3, count)
+
+	_, _, err = parseWatchArgs([]string{"0"})
+	assert.Error(t, err)
+
+	_, _, err = parseWatchArgs([]string{"1", "abc"})
+	assert.Error(t, err)
+}
+
+func TestWatchQuery_NoPreviousQuery(t *testing.T) {
+	d := &dataQL{}
+	assert.Error(t, d.watchQuery(time.Second, 1))
+}
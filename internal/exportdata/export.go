@@ -2,7 +2,14 @@ package exportdata
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/adrianolaselva/dataql/pkg/exportdata"
 	"github.com/adrianolaselva/dataql/pkg/exportdata/csv"
@@ -14,6 +21,7 @@ import (
 	"github.com/adrianolaselva/dataql/pkg/exportdata/parquet"
 	"github.com/adrianolaselva/dataql/pkg/exportdata/xml"
 	exportyaml "github.com/adrianolaselva/dataql/pkg/exportdata/yaml"
+	"github.com/adrianolaselva/dataql/pkg/lineage"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -32,6 +40,79 @@ const (
 	HTMLExportType       = "html"
 )
 
+// Types are the export types, as given to -t
+var Types = []string{
+	CSVLineExportType, JSONLineExportType, JSONExportType, ExcelExportType, ExcelXLSXExportType, ParquetExportType,
+	XMLExportType, YAMLExportType, YMLExportType, MarkdownExportType, MarkdownMDExportType, HTMLExportType,
+}
+
+// ValidateType checks that exportType is one of Types
+func ValidateType(exportType string) error {
+	if !slices.Contains(Types, exportType) {
+		return fmt.Errorf("export type %s not defined (use %s)", exportType, strings.Join(Types, ", "))
+	}
+	return nil
+}
+
+// TypeFromPath returns the export type matching the extension of
+// exportPath, or an empty string
+func TypeFromPath(exportPath string) string {
+	exportType := strings.TrimPrefix(strings.ToLower(filepath.Ext(exportPath)), ".")
+	if ValidateType(exportType) != nil {
+		return ""
+	}
+	return exportType
+}
+
+// CheckPath checks, before any data is read, that exportPath can be
+// written: an existing file must be writable and not a directory, otherwise
+// the file must be creatable in the nearest existing directory, since the
+// exports create the missing ones.
+func CheckPath(exportPath string) error {
+	if info, err := os.Stat(exportPath); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("export path %s is a directory", exportPath)
+		}
+		// Opened without truncating, so a failed run leaves the file intact
+		file, err := os.OpenFile(exportPath, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("export path %s is not writable: %w", exportPath, err)
+		}
+		return file.Close()
+	}
+
+	dir := filepath.Dir(exportPath)
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("export path %s is not in a directory: %s is a file", exportPath, dir)
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("export path %s is not writable: %w", exportPath, err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	probe, err := os.CreateTemp(dir, ".dataql-export-*")
+	if err != nil {
+		// The name of the probe file means nothing to the user
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return fmt.Errorf("export path %s is not writable: cannot create files in %s: %w", exportPath, dir, err)
+	}
+	_ = probe.Close()
+	return os.Remove(probe.Name())
+}
+
 func NewExport(exportType string, rows *sql.Rows, exportPath string, bar *progressbar.ProgressBar) (exportdata.Export, error) {
 	switch exportType {
 	case CSVLineExportType:
@@ -56,3 +137,51 @@ func NewExport(exportType string, rows *sql.Rows, exportPath string, bar *progre
 
 	return nil, fmt.Errorf("export type %s not defined", exportType)
 }
+
+// SetLineage embeds the lineage in exports whose file format supports it,
+// reporting whether it did
+func SetLineage(export exportdata.Export, l *lineage.Lineage) bool {
+	embedded, ok := export.(exportdata.LineageExport)
+	if ok {
+		embedded.SetLineage(l)
+	}
+	return ok
+}
+
+// SetDecimalAsString makes exports writing typed numbers write DECIMAL and
+// HUGEINT values as strings
+func SetDecimalAsString(export exportdata.Export, asString bool) {
+	if typed, ok := export.(exportdata.DecimalExport); ok {
+		typed.SetDecimalAsString(asString)
+	}
+}
+
+// ExactValue converts DECIMAL and HUGEINT values to exact json.Number
+// values, or strings with decimalAsString (see exportdata.ExactValue)
+func ExactValue(value any, decimalAsString bool) any {
+	return exportdata.ExactValue(value, decimalAsString)
+}
+
+// SetTimeZone makes exports writing dates and timestamps write TIMESTAMPTZ
+// values in location
+func SetTimeZone(export exportdata.Export, location *time.Location) {
+	if zoned, ok := export.(exportdata.TimeZoneExport); ok {
+		zoned.SetTimeZone(location)
+	}
+}
+
+// TimeValue formats DATE, TIME and TIMESTAMP values by type, TIMESTAMPTZ
+// values in location (see exportdata.TimeValue)
+func TimeValue(value any, dbType string, location *time.Location) any {
+	return exportdata.TimeValue(value, dbType, location)
+}
+
+// IsTimestampTZ reports whether dbType is TIMESTAMP WITH TIME ZONE
+func IsTimestampTZ(dbType string) bool {
+	return exportdata.IsTimestampTZ(dbType)
+}
+
+// ColumnTypes returns the database type names of the columns of rows
+func ColumnTypes(rows *sql.Rows) ([]string, error) {
+	return exportdata.ColumnTypes(rows)
+}
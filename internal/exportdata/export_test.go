@@ -0,0 +1,46 @@
+package exportdata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateType(t *testing.T) {
+	for _, exportType := range Types {
+		assert.NoError(t, ValidateType(exportType))
+	}
+	assert.ErrorContains(t, ValidateType("cvs"), "export type cvs not defined")
+	assert.Error(t, ValidateType(""))
+}
+
+func TestTypeFromPath(t *testing.T) {
+	assert.Equal(t, "parquet", TypeFromPath("out/report.PARQUET"))
+	assert.Equal(t, "md", TypeFromPath("report.md"))
+	assert.Empty(t, TypeFromPath("report.txt"))
+	assert.Empty(t, TypeFromPath("report"))
+}
+
+func TestCheckPath(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.NoError(t, CheckPath(filepath.Join(dir, "out.csv")))
+	assert.NoError(t, CheckPath(filepath.Join(dir, "missing", "nested", "out.csv")), "the exports create missing directories")
+	assert.ErrorContains(t, CheckPath(dir), "is a directory")
+
+	existing := filepath.Join(dir, "existing.csv")
+	require.NoError(t, os.WriteFile(existing, []byte("id\n1\n"), 0644))
+	assert.NoError(t, CheckPath(existing))
+	content, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "id\n1\n", string(content), "checking an existing file leaves it intact")
+
+	assert.ErrorContains(t, CheckPath(filepath.Join(existing, "out.csv")), "is a file")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no probe file is left behind")
+}
@@ -1,14 +1,15 @@
 package main
 
 import (
-	"fmt"
 	"github.com/adrianolaselva/dataql/cmd"
+	"github.com/adrianolaselva/dataql/pkg/clierror"
 	"os"
 )
 
 func main() {
-	if err := cmd.New().Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
-		os.Exit(1)
+	cli := cmd.New()
+	if err := cli.Execute(); err != nil {
+		cli.WriteError(os.Stderr, err)
+		os.Exit(clierror.ExitCode(err))
 	}
 }
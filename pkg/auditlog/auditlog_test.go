@@ -0,0 +1,86 @@
+package auditlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLogger_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for i := 0; i < 2; i++ {
+		logger, err := New(path)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		if err := logger.Log(Entry{Caller: CallerCLI, Sources: []string{"users.csv"}, Query: "SELECT 1", Rows: 1, DurationMs: 1.5}); err != nil {
+			t.Fatalf("Log failed: %v", err)
+		}
+		if err := logger.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid audit line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Query != "SELECT 1" || entries[0].Caller != CallerCLI || entries[0].Rows != 1 {
+		t.Errorf("unexpected entry: %+v", entries[0])
+	}
+	if entries[0].Timestamp.IsZero() || entries[0].Timestamp.Location() != time.UTC {
+		t.Errorf("expected a UTC timestamp, got %v", entries[0].Timestamp)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestNew_InvalidPath(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "missing", "audit.jsonl")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestRedactSource(t *testing.T) {
+	tests := []struct {
+		source, expected string
+	}{
+		{"data/users.csv", "data/users.csv"},
+		{"users.csv:people", "users.csv:people"},
+		{"postgres://admin:secret@db:5432/shop?table=orders", "postgres://admin:xxxxx@db:5432/shop?table=orders"},
+		{"s3://bucket/key.parquet", "s3://bucket/key.parquet"},
+		{"https://acct.blob.core.windows.net/c/data.csv?sv=2022-11-02&sp=r&sig=abc%2Bdef", "https://acct.blob.core.windows.net/c/data.csv?sig=xxxxx&sp=r&sv=2022-11-02"},
+		{"https://bucket.s3.amazonaws.com/key.csv?X-Amz-Signature=abc&X-Amz-Expires=60", "https://bucket.s3.amazonaws.com/key.csv?X-Amz-Expires=60&X-Amz-Signature=xxxxx"},
+	}
+
+	for _, tt := range tests {
+		if got := RedactSource(tt.source); got != tt.expected {
+			t.Errorf("RedactSource(%q) = %q, expected %q", tt.source, got, tt.expected)
+		}
+	}
+}
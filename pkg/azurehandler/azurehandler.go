@@ -10,6 +10,10 @@ import (
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/adrianolaselva/dataql/pkg/cachehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
+	"github.com/adrianolaselva/dataql/pkg/rangedownload"
 )
 
 // AzureHandler handles downloading files from Azure Blob Storage
@@ -17,6 +21,19 @@ type AzureHandler struct {
 	tempDir   string
 	tempFiles []string
 	client    *azblob.Client
+	cache     *cachehandler.DownloadCache // Keeps downloads between runs (nil: disabled)
+	download  rangedownload.Options       // Parts of large blobs
+	progress  *progress.Reporter          // Shows the downloads (nil: not shown)
+	options   Options
+}
+
+// Options are the explicit credentials of --azure-account,
+// --azure-sas-token and --azure-connection-string, which take precedence
+// over the AZURE_STORAGE_* environment variables
+type Options struct {
+	Account          string // Storage account of azure:// URLs
+	SASToken         string // Shared access signature, with or without the leading "?"
+	ConnectionString string // Connection string of the storage account
 }
 
 // AzureLocation represents a parsed Azure Blob URL
@@ -26,8 +43,8 @@ type AzureLocation struct {
 	BlobName      string
 }
 
-// azureURLRegex matches azure://container/blob format
-var azureURLRegex = regexp.MustCompile(`^azure://([^/]+)/(.+)$`)
+// azureURLRegex matches azure://container/blob and az://container/blob formats
+var azureURLRegex = regexp.MustCompile(`^(?:azure|az)://([^/]+)/(.+)$`)
 
 // azureBlobURLRegex matches https://<account>.blob.core.windows.net/<container>/<blob> format
 var azureBlobURLRegex = regexp.MustCompile(`^https://([^.]+)\.blob\.core\.windows\.net/([^/]+)/(.+)$`)
@@ -37,21 +54,43 @@ func NewAzureHandler() *AzureHandler {
 	return &AzureHandler{}
 }
 
+// SetCache keeps downloads in a cache, reused while the blob keeps its ETag
+func (h *AzureHandler) SetCache(cache *cachehandler.DownloadCache) {
+	h.cache = cache
+}
+
+// SetDownload sets how many parts of large blobs are downloaded at the same
+// time and their size
+func (h *AzureHandler) SetDownload(opts rangedownload.Options) {
+	h.download = opts
+}
+
+// SetProgress shows the progress of the downloads with reporter
+func (h *AzureHandler) SetProgress(reporter *progress.Reporter) {
+	h.progress = reporter
+}
+
+// SetOptions sets the credentials of the storage account
+func (h *AzureHandler) SetOptions(opts Options) {
+	h.options = opts
+}
+
 // IsAzureURL checks if a string is an Azure Blob URL
 func IsAzureURL(path string) bool {
 	return strings.HasPrefix(path, "azure://") ||
+		strings.HasPrefix(path, "az://") ||
 		strings.Contains(path, ".blob.core.windows.net/")
 }
 
 // ParseAzureURL parses an Azure Blob URL into account, container and blob
 func ParseAzureURL(azureURL string) (*AzureLocation, error) {
-	// Try azure:// format first
-	if strings.HasPrefix(azureURL, "azure://") {
+	// Try azure:// and az:// formats first
+	if strings.HasPrefix(azureURL, "azure://") || strings.HasPrefix(azureURL, "az://") {
 		matches := azureURLRegex.FindStringSubmatch(azureURL)
 		if matches == nil {
 			return nil, fmt.Errorf("invalid Azure URL format: %s (expected azure://container/blob)", azureURL)
 		}
-		// For azure:// format, we need the account from environment
+		// For azure:// format, we need the account from --azure-account or the environment
 		return &AzureLocation{
 			ContainerName: matches[1],
 			BlobName:      matches[2],
@@ -125,6 +164,20 @@ func (h *AzureHandler) downloadAzureFile(azureURL string) (string, error) {
 	// Get blob client
 	blobClient := h.client.ServiceClient().NewContainerClient(loc.ContainerName).NewBlobClient(loc.BlobName)
 
+	props, propsErr := blobClient.GetProperties(ctx, nil)
+
+	// Reuse a cached download of the same version of the blob
+	if h.cache != nil && propsErr == nil && props.ETag != nil {
+		if cached, ok := h.cache.Lookup(azureURL); ok && cached.Matches(string(*props.ETag), stringValue(props.VersionID)) {
+			return h.cache.Use(cached), nil
+		}
+	}
+
+	// Download large blobs in parts
+	if propsErr == nil && props.ContentLength != nil && h.download.Parallel(*props.ContentLength) {
+		return h.downloadParts(ctx, azureURL, blobClient, props, localPath)
+	}
+
 	// Download
 	downloadResponse, err := blobClient.DownloadStream(ctx, nil)
 	if err != nil {
@@ -132,6 +185,23 @@ func (h *AzureHandler) downloadAzureFile(azureURL string) (string, error) {
 	}
 	defer downloadResponse.Body.Close()
 
+	var size int64 = -1
+	if downloadResponse.ContentLength != nil {
+		size = *downloadResponse.ContentLength
+	}
+	phase := h.progress.Start(progress.Download, filename, size)
+	defer phase.Done()
+	body := phase.Reader(downloadResponse.Body)
+
+	if h.cache != nil && downloadResponse.ETag != nil {
+		return h.cache.Store(cachehandler.Download{
+			Source:    azureURL,
+			File:      filename,
+			ETag:      string(*downloadResponse.ETag),
+			VersionID: stringValue(downloadResponse.VersionID),
+		}, body)
+	}
+
 	// Create local file
 	file, err := os.Create(localPath)
 	if err != nil {
@@ -140,7 +210,7 @@ func (h *AzureHandler) downloadAzureFile(azureURL string) (string, error) {
 	defer file.Close()
 
 	// Copy content
-	_, err = io.Copy(file, downloadResponse.Body)
+	_, err = io.Copy(file, body)
 	if err != nil {
 		return "", fmt.Errorf("failed to write file content: %w", err)
 	}
@@ -149,10 +219,53 @@ func (h *AzureHandler) downloadAzureFile(azureURL string) (string, error) {
 	return localPath, nil
 }
 
-// initClient initializes the Azure Blob client
+// downloadParts downloads the version of the blob props describes with
+// ranged downloads
+func (h *AzureHandler) downloadParts(ctx context.Context, azureURL string, blobClient *blob.Client, props blob.GetPropertiesResponse, localPath string) (string, error) {
+	size := *props.ContentLength
+	openRange := func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+		resp, err := blobClient.DownloadStream(ctx, &blob.DownloadStreamOptions{
+			Range: blob.HTTPRange{Offset: offset, Count: length},
+			AccessConditions: &blob.AccessConditions{
+				ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfMatch: props.ETag},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to download Azure blob: %w", err)
+		}
+		return resp.Body, nil
+	}
+
+	opts := h.download
+	if phase := h.progress.Start(progress.Download, filepath.Base(localPath), size); phase != nil {
+		defer phase.Done()
+		opts.Progress = phase
+	}
+
+	if h.cache != nil && props.ETag != nil {
+		return h.cache.StoreFile(cachehandler.Download{
+			Source:    azureURL,
+			File:      filepath.Base(localPath),
+			ETag:      string(*props.ETag),
+			VersionID: stringValue(props.VersionID),
+		}, func(file *os.File) error {
+			return rangedownload.Download(ctx, file, size, openRange, opts)
+		})
+	}
+
+	if err := rangedownload.DownloadFile(ctx, localPath, size, openRange, opts); err != nil {
+		return "", fmt.Errorf("failed to write file content: %w", err)
+	}
+	h.tempFiles = append(h.tempFiles, localPath)
+	return localPath, nil
+}
+
+// initClient initializes the Azure Blob client, with the first credentials
+// found among a connection string, an account key, a SAS token and Azure
+// workload identity
 func (h *AzureHandler) initClient(loc *AzureLocation) error {
 	// Try connection string first (from environment)
-	connStr := os.Getenv("AZURE_STORAGE_CONNECTION_STRING")
+	connStr := firstNonEmpty(h.options.ConnectionString, os.Getenv("AZURE_STORAGE_CONNECTION_STRING"))
 	if connStr != "" {
 		client, err := azblob.NewClientFromConnectionString(connStr, nil)
 		if err != nil {
@@ -163,19 +276,19 @@ func (h *AzureHandler) initClient(loc *AzureLocation) error {
 	}
 
 	// Try account name and key
-	accountName := loc.AccountName
+	accountName := firstNonEmpty(loc.AccountName, h.options.Account, os.Getenv("AZURE_STORAGE_ACCOUNT"))
+	accountKey := os.Getenv("AZURE_STORAGE_KEY")
 	if accountName == "" {
-		accountName = os.Getenv("AZURE_STORAGE_ACCOUNT")
+		return fmt.Errorf("Azure storage account not found. Use an https://<account>.blob.core.windows.net URL, --azure-account or AZURE_STORAGE_ACCOUNT")
 	}
-	accountKey := os.Getenv("AZURE_STORAGE_KEY")
+	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", accountName)
 
-	if accountName != "" && accountKey != "" {
+	if accountKey != "" {
 		cred, err := azblob.NewSharedKeyCredential(accountName, accountKey)
 		if err != nil {
 			return fmt.Errorf("failed to create Azure credentials: %w", err)
 		}
 
-		serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", accountName)
 		client, err := azblob.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
 		if err != nil {
 			return fmt.Errorf("failed to create Azure client: %w", err)
@@ -184,7 +297,44 @@ func (h *AzureHandler) initClient(loc *AzureLocation) error {
 		return nil
 	}
 
-	return fmt.Errorf("Azure credentials not found. Set AZURE_STORAGE_CONNECTION_STRING or AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY")
+	// A SAS token signs every request in its query string
+	if sas := firstNonEmpty(h.options.SASToken, os.Getenv("AZURE_STORAGE_SAS_TOKEN")); sas != "" {
+		client, err := azblob.NewClientWithNoCredential(serviceURL+"?"+strings.TrimPrefix(sas, "?"), nil)
+		if err != nil {
+			return fmt.Errorf("failed to create Azure client with SAS token: %w", err)
+		}
+		h.client = client
+		return nil
+	}
+
+	// Pods of AKS clusters with workload identity get a federated token
+	if cred, ok := newWorkloadIdentity(); ok {
+		client, err := azblob.NewClient(serviceURL, cred, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create Azure client: %w", err)
+		}
+		h.client = client
+		return nil
+	}
+
+	return fmt.Errorf("Azure credentials not found. Set --azure-connection-string, --azure-sas-token, AZURE_STORAGE_CONNECTION_STRING, AZURE_STORAGE_KEY, AZURE_STORAGE_SAS_TOKEN or use Azure workload identity")
+}
+
+// firstNonEmpty returns the first value that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
 
 // Cleanup removes all downloaded temp files
@@ -0,0 +1,118 @@
+package azurehandler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAzureURL(t *testing.T) {
+	for _, azureURL := range []string{"azure://container/dir/data.csv", "az://container/dir/data.csv"} {
+		assert.True(t, IsAzureURL(azureURL))
+		loc, err := ParseAzureURL(azureURL)
+		require.NoError(t, err)
+		assert.Equal(t, &AzureLocation{ContainerName: "container", BlobName: "dir/data.csv"}, loc)
+	}
+
+	loc, err := ParseAzureURL("https://acct.blob.core.windows.net/container/data.csv")
+	require.NoError(t, err)
+	assert.Equal(t, "acct", loc.AccountName)
+
+	_, err = ParseAzureURL("az://container-only")
+	assert.ErrorContains(t, err, "invalid Azure URL")
+}
+
+func TestConnectionStringWithSAS(t *testing.T) {
+	content := []byte("id,name\n1,alice\n")
+	var mu sync.Mutex
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		signatures = append(signatures, r.URL.Query().Get("sig"))
+		mu.Unlock()
+		if r.URL.Path != "/acct/container/data.csv" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("x-ms-blob-type", "BlockBlob")
+		http.ServeContent(w, r, "data.csv", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	t.Setenv("AZURE_STORAGE_CONNECTION_STRING", "")
+	h := NewAzureHandler()
+	defer h.Cleanup()
+	h.SetOptions(Options{ConnectionString: "BlobEndpoint=" + server.URL + "/acct;SharedAccessSignature=sv=2022-11-02&sp=r&sig=abc"})
+	paths, err := h.ResolveFiles([]string{"az://container/data.csv"})
+	require.NoError(t, err)
+
+	downloaded, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+	for _, sig := range signatures {
+		assert.Equal(t, "abc", sig)
+	}
+}
+
+func TestMissingCredentials(t *testing.T) {
+	for _, name := range []string{"AZURE_STORAGE_CONNECTION_STRING", "AZURE_STORAGE_ACCOUNT", "AZURE_STORAGE_KEY", "AZURE_STORAGE_SAS_TOKEN", "AZURE_FEDERATED_TOKEN_FILE"} {
+		t.Setenv(name, "")
+	}
+
+	h := NewAzureHandler()
+	defer h.Cleanup()
+	_, err := h.ResolveFiles([]string{"az://container/data.csv"})
+	assert.ErrorContains(t, err, "--azure-account")
+
+	h.SetOptions(Options{Account: "acct"})
+	_, err = h.ResolveFiles([]string{"az://container/data.csv"})
+	assert.ErrorContains(t, err, "Azure credentials not found")
+}
+
+func TestWorkloadIdentity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if r.URL.Path != "/tenant/oauth2/v2.0/token" || r.Form.Get("client_id") != "client" || r.Form.Get("client_assertion") != "federated" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error_description": "AADSTS700016: invalid client"}`))
+			return
+		}
+		assert.Equal(t, "https://storage.azure.com/.default", r.Form.Get("scope"))
+		_, _ = w.Write([]byte(`{"access_token": "token", "expires_in": 3600}`))
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("federated\n"), 0600))
+	t.Setenv("AZURE_AUTHORITY_HOST", server.URL)
+	t.Setenv("AZURE_TENANT_ID", "tenant")
+	t.Setenv("AZURE_CLIENT_ID", "client")
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", tokenFile)
+
+	cred, ok := newWorkloadIdentity()
+	require.True(t, ok)
+	opts := policy.TokenRequestOptions{Scopes: []string{"https://storage.azure.com/.default"}}
+	token, err := cred.GetToken(t.Context(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, "token", token.Token)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresOn, time.Minute)
+
+	t.Setenv("AZURE_CLIENT_ID", "other")
+	cred, _ = newWorkloadIdentity()
+	_, err = cred.GetToken(t.Context(), opts)
+	assert.ErrorContains(t, err, "AADSTS700016")
+
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "")
+	_, ok = newWorkloadIdentity()
+	assert.False(t, ok)
+}
@@ -0,0 +1,86 @@
+package azurehandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// defaultAuthorityHost is the Microsoft Entra endpoint of the public cloud
+const defaultAuthorityHost = "https://login.microsoftonline.com/"
+
+// workloadIdentity exchanges the federated token Azure workload identity
+// mounts in pods for Microsoft Entra access tokens
+type workloadIdentity struct {
+	authorityHost string
+	tenantID      string
+	clientID      string
+	tokenFile     string
+	client        *http.Client
+}
+
+// newWorkloadIdentity returns the credential of the environment variables
+// set by the workload identity webhook, if they are all set
+func newWorkloadIdentity() (*workloadIdentity, bool) {
+	w := &workloadIdentity{
+		authorityHost: firstNonEmpty(os.Getenv("AZURE_AUTHORITY_HOST"), defaultAuthorityHost),
+		tenantID:      os.Getenv("AZURE_TENANT_ID"),
+		clientID:      os.Getenv("AZURE_CLIENT_ID"),
+		tokenFile:     os.Getenv("AZURE_FEDERATED_TOKEN_FILE"),
+		client:        &http.Client{Timeout: 30 * time.Second},
+	}
+	return w, w.tenantID != "" && w.clientID != "" && w.tokenFile != ""
+}
+
+// GetToken requests an access token for the scopes of opts
+func (w *workloadIdentity) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	// The kubelet renews the federated token, so it is read for every request
+	assertion, err := os.ReadFile(w.tokenFile)
+	if err != nil {
+		return azcore.AccessToken{}, fmt.Errorf("failed to read federated token: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {w.clientID},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+		"scope":                 {strings.Join(opts.Scopes, " ")},
+	}
+	tokenURL := strings.TrimSuffix(w.authorityHost, "/") + "/" + url.PathEscape(w.tenantID) + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return azcore.AccessToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return azcore.AccessToken{}, fmt.Errorf("failed to request workload identity token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return azcore.AccessToken{}, fmt.Errorf("failed to read workload identity token: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return azcore.AccessToken{}, fmt.Errorf("workload identity token request failed with status %d: %s", resp.StatusCode, token.ErrorDescription)
+	}
+	return azcore.AccessToken{
+		Token:     token.AccessToken,
+		ExpiresOn: time.Now().Add(time.Duration(token.ExpiresIn) * time.Second),
+	}, nil
+}
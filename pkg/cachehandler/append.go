@@ -0,0 +1,189 @@
+package cachehandler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Growth is a source file that grew since it was cached
+type Growth struct {
+	File   string // Source file
+	Offset int64  // Size when cached: the bytes from Offset on were appended
+}
+
+// Appendable is a cache entry of files that only grew since it was cached,
+// so importing the appended data brings its tables up to date
+type Appendable struct {
+	CacheKey  string
+	Tables    []string
+	TotalRows int64
+	Growth    []Growth // Files that grew; unchanged files are left out
+}
+
+// RecordContents stores the size and hash of each source file of an entry,
+// letting a later import of the grown files append only the new data
+func (h *CacheHandler) RecordContents(cacheKey string) error {
+	metadata, err := h.ReadMetadata(cacheKey)
+	if err != nil {
+		return err
+	}
+
+	metadata.Sizes = make([]int64, len(metadata.SourceFiles))
+	metadata.Hashes = make([]string, len(metadata.SourceFiles))
+	for i, file := range metadata.SourceFiles {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("failed to stat file: %w", err)
+		}
+		hash, err := hashPrefix(file, info.Size())
+		if err != nil {
+			return err
+		}
+		metadata.Sizes[i] = info.Size()
+		metadata.Hashes[i] = hash
+	}
+	return h.writeMetadata(cacheKey, metadata)
+}
+
+// FindAppendable looks for an entry of the same files whose cached content
+// is still an unchanged prefix of each file. A file that grew must have
+// been cached up to the end of a line, so no row was imported partially.
+// The most recent matching entry is returned.
+func (h *CacheHandler) FindAppendable(files []string) (*Appendable, bool) {
+	if !h.enabled {
+		return nil, false
+	}
+
+	entries, err := os.ReadDir(h.cacheDir)
+	if err != nil {
+		return nil, false
+	}
+
+	var found *Appendable
+	var foundAt time.Time
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		cacheKey := strings.TrimSuffix(entry.Name(), ".json")
+		metadata, err := h.ReadMetadata(cacheKey)
+		if err != nil || metadata.FormatVersion != cacheFormatVersion || h.expired(metadata, time.Now()) {
+			continue
+		}
+		if found != nil && !metadata.CachedAt.After(foundAt) {
+			continue
+		}
+		if _, err := os.Stat(h.GetCachePath(cacheKey)); err != nil {
+			continue
+		}
+		growth, ok := h.growth(files, metadata)
+		if !ok {
+			continue
+		}
+		found = &Appendable{CacheKey: cacheKey, Tables: metadata.Tables, TotalRows: metadata.TotalRows, Growth: growth}
+		foundAt = metadata.CachedAt
+	}
+	return found, found != nil
+}
+
+// growth compares the files with the contents recorded for an entry
+func (h *CacheHandler) growth(files []string, metadata *CacheMetadata) ([]Growth, bool) {
+	if len(files) != len(metadata.SourceFiles) ||
+		len(metadata.Sizes) != len(metadata.SourceFiles) ||
+		len(metadata.Hashes) != len(metadata.SourceFiles) {
+		return nil, false
+	}
+
+	recorded := make(map[string]int)
+	for i, file := range metadata.SourceFiles {
+		recorded[file] = i
+	}
+
+	var growth []Growth
+	for _, file := range files {
+		absPath, err := filepath.Abs(file)
+		if err != nil {
+			return nil, false
+		}
+		i, ok := recorded[absPath]
+		if !ok {
+			return nil, false
+		}
+		info, err := os.Stat(file)
+		if err != nil || info.Size() < metadata.Sizes[i] {
+			return nil, false
+		}
+		hash, err := hashPrefix(file, metadata.Sizes[i])
+		if err != nil || hash != metadata.Hashes[i] {
+			return nil, false
+		}
+		if info.Size() == metadata.Sizes[i] {
+			continue
+		}
+		if !endsLine(file, metadata.Sizes[i]) {
+			return nil, false
+		}
+		growth = append(growth, Growth{File: file, Offset: metadata.Sizes[i]})
+	}
+	return growth, true
+}
+
+// Adopt moves the data of an entry to a new key, for the import that brings
+// it up to date. The metadata of the old entry is removed; the new one is
+// saved once the import completes.
+func (h *CacheHandler) Adopt(oldKey, newKey string) error {
+	if !h.enabled {
+		return fmt.Errorf("cache not enabled")
+	}
+
+	oldPath, newPath := h.GetCachePath(oldKey), h.GetCachePath(newKey)
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return fmt.Errorf("failed to move cache file: %w", err)
+	}
+	if err := os.Rename(oldPath+walSuffix, newPath+walSuffix); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to move cache file: %w", err)
+	}
+	if err := os.Remove(h.GetMetadataPath(oldKey)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove metadata file: %w", err)
+	}
+	return nil
+}
+
+// hashPrefix returns the SHA-256 of the first size bytes of a file
+func hashPrefix(path string, size int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.CopyN(hash, f, size); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// endsLine reports whether the first size bytes of a file end with a newline
+func endsLine(path string, size int64) bool {
+	if size == 0 {
+		return false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, size-1); err != nil {
+		return false
+	}
+	return last[0] == '\n'
+}
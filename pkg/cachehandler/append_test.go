@@ -0,0 +1,145 @@
+package cachehandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// cacheFile saves an entry for a source file with its contents recorded
+func cacheFile(t *testing.T, handler *CacheHandler, file string) string {
+	t.Helper()
+	key, err := handler.GenerateCacheKey([]string{file})
+	if err != nil {
+		t.Fatalf("GenerateCacheKey failed: %v", err)
+	}
+	if err := os.WriteFile(handler.GetCachePath(key), []byte("db"), 0644); err != nil {
+		t.Fatalf("failed to create cache file: %v", err)
+	}
+	if err := handler.SaveMetadata(key, []string{file}, []string{"events"}, 2); err != nil {
+		t.Fatalf("SaveMetadata failed: %v", err)
+	}
+	if err := handler.RecordContents(key); err != nil {
+		t.Fatalf("RecordContents failed: %v", err)
+	}
+	return key
+}
+
+func appendTo(t *testing.T, file, content string) {
+	t.Helper()
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("failed to append: %v", err)
+	}
+}
+
+func TestFindAppendable_Grown(t *testing.T) {
+	handler, _ := NewCacheHandler(t.TempDir(), true)
+	file := filepath.Join(t.TempDir(), "events.jsonl")
+	prefix := "{\"id\":1}\n{\"id\":2}\n"
+	if err := os.WriteFile(file, []byte(prefix), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	key := cacheFile(t, handler, file)
+	appendTo(t, file, "{\"id\":3}\n")
+
+	entry, ok := handler.FindAppendable([]string{file})
+	if !ok {
+		t.Fatal("expected an appendable entry")
+	}
+	if entry.CacheKey != key || entry.TotalRows != 2 || len(entry.Tables) != 1 {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if len(entry.Growth) != 1 || entry.Growth[0].File != file || entry.Growth[0].Offset != int64(len(prefix)) {
+		t.Errorf("unexpected growth: %+v", entry.Growth)
+	}
+}
+
+func TestFindAppendable_Unchanged(t *testing.T) {
+	handler, _ := NewCacheHandler(t.TempDir(), true)
+	file := filepath.Join(t.TempDir(), "events.csv")
+	if err := os.WriteFile(file, []byte("id\n1\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	cacheFile(t, handler, file)
+
+	entry, ok := handler.FindAppendable([]string{file})
+	if !ok {
+		t.Fatal("expected an appendable entry")
+	}
+	if len(entry.Growth) != 0 {
+		t.Errorf("expected no growth, got %+v", entry.Growth)
+	}
+}
+
+func TestFindAppendable_Rejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		initial  string
+		modified string
+	}{
+		{"rewritten prefix", "id\n1\n2\n", "id\n1\n9\n3\n"},
+		{"truncated", "id\n1\n2\n", "id\n1\n"},
+		{"partial last line", "id\n1\n2", "id\n1\n23\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := NewCacheHandler(t.TempDir(), true)
+			file := filepath.Join(t.TempDir(), "events.csv")
+			if err := os.WriteFile(file, []byte(tt.initial), 0644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+			cacheFile(t, handler, file)
+			if err := os.WriteFile(file, []byte(tt.modified), 0644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+
+			if entry, ok := handler.FindAppendable([]string{file}); ok {
+				t.Errorf("expected no appendable entry, got %+v", entry)
+			}
+		})
+	}
+}
+
+func TestFindAppendable_WithoutRecordedContents(t *testing.T) {
+	handler, _ := NewCacheHandler(t.TempDir(), true)
+	file := filepath.Join(t.TempDir(), "events.csv")
+	if err := os.WriteFile(file, []byte("id\n1\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	key, _ := handler.GenerateCacheKey([]string{file})
+	_ = os.WriteFile(handler.GetCachePath(key), []byte("db"), 0644)
+	if err := handler.SaveMetadata(key, []string{file}, []string{"events"}, 1); err != nil {
+		t.Fatalf("SaveMetadata failed: %v", err)
+	}
+
+	if _, ok := handler.FindAppendable([]string{file}); ok {
+		t.Error("entries without recorded contents should not be appendable")
+	}
+}
+
+func TestAdopt(t *testing.T) {
+	handler, _ := NewCacheHandler(t.TempDir(), true)
+	writeEntry(t, handler, "old", 10, time.Now(), time.Now())
+	_ = os.WriteFile(handler.GetCachePath("old")+walSuffix, []byte("wal"), 0644)
+
+	if err := handler.Adopt("old", "new"); err != nil {
+		t.Fatalf("Adopt failed: %v", err)
+	}
+
+	if !exists(handler.GetCachePath("new")) || !exists(handler.GetCachePath("new")+walSuffix) {
+		t.Error("cache file and write-ahead log should have moved to the new key")
+	}
+	if exists(handler.GetCachePath("old")) || exists(handler.GetMetadataPath("old")) {
+		t.Error("old entry should have been removed")
+	}
+	if exists(handler.GetMetadataPath("new")) {
+		t.Error("metadata of the new entry should be saved after the import")
+	}
+}
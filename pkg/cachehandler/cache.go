@@ -16,13 +16,18 @@ import (
 type CacheHandler struct {
 	cacheDir string
 	enabled  bool
+	ttl      time.Duration // Entries older than ttl are stale (0: no limit)
+	maxSize  int64         // Least recently used entries are evicted above maxSize bytes (0: no limit)
 }
 
 // CacheMetadata stores information about a cached file
 type CacheMetadata struct {
 	SourceFiles   []string  `json:"source_files"`
 	ModTimes      []int64   `json:"mod_times"`
+	Sizes         []int64   `json:"sizes,omitempty"`  // Size of each source file, for append-only sources
+	Hashes        []string  `json:"hashes,omitempty"` // SHA-256 of each source file, for append-only sources
 	CachedAt      time.Time `json:"cached_at"`
+	LastUsedAt    time.Time `json:"last_used_at,omitempty"` // Last cache hit, for LRU eviction
 	CacheFile     string    `json:"cache_file"`
 	TotalRows     int64     `json:"total_rows"`
 	Tables        []string  `json:"tables"`
@@ -33,6 +38,7 @@ type CacheMetadata struct {
 const (
 	cacheFormatVersion = 1
 	metadataFileName   = "metadata.json"
+	walSuffix          = ".wal" // DuckDB write-ahead log next to a cache file
 )
 
 // NewCacheHandler creates a new cache handler
@@ -160,6 +166,12 @@ func (h *CacheHandler) IsCacheValid(files []string) (bool, string, error) {
 		return false, "", nil
 	}
 
+	// A stale entry is removed so the import does not append to its tables
+	if h.expired(metadata, time.Now()) {
+		_ = h.ClearCacheEntry(cacheKey)
+		return false, "", nil
+	}
+
 	return true, cachePath, nil
 }
 
@@ -272,6 +284,7 @@ type CacheEntry struct {
 	CacheKey    string
 	SourceFiles []string
 	CachedAt    time.Time
+	LastUsedAt  time.Time
 	TotalRows   int64
 	Tables      []string
 	SizeBytes   int64
@@ -300,20 +313,14 @@ func (h *CacheHandler) ListCache() ([]CacheEntry, error) {
 			continue // Skip invalid entries
 		}
 
-		// Get cache file size
-		var sizeBytes int64
-		cachePath := h.GetCachePath(cacheKey)
-		if info, err := os.Stat(cachePath); err == nil {
-			sizeBytes = info.Size()
-		}
-
 		cacheEntries = append(cacheEntries, CacheEntry{
 			CacheKey:    cacheKey,
 			SourceFiles: metadata.SourceFiles,
 			CachedAt:    metadata.CachedAt,
+			LastUsedAt:  metadata.lastUsed(),
 			TotalRows:   metadata.TotalRows,
 			Tables:      metadata.Tables,
-			SizeBytes:   sizeBytes,
+			SizeBytes:   h.entrySize(cacheKey),
 		})
 	}
 
@@ -339,7 +346,7 @@ func (h *CacheHandler) ClearCache() error {
 	var cleared int
 	for _, entry := range entries {
 		path := filepath.Join(h.cacheDir, entry.Name())
-		if err := os.Remove(path); err != nil {
+		if err := os.RemoveAll(path); err != nil {
 			// Continue on error, but log it
 			fmt.Fprintf(os.Stderr, "Warning: failed to remove %s: %v\n", path, err)
 		} else {
@@ -360,9 +367,11 @@ func (h *CacheHandler) ClearCacheEntry(cacheKey string) error {
 	cachePath := h.GetCachePath(cacheKey)
 	metadataPath := h.GetMetadataPath(cacheKey)
 
-	// Remove cache file
-	if err := os.Remove(cachePath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove cache file: %w", err)
+	// Remove cache file and its write-ahead log
+	for _, path := range []string{cachePath, cachePath + walSuffix} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove cache file: %w", err)
+		}
 	}
 
 	// Remove metadata file
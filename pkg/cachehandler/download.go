@@ -0,0 +1,200 @@
+package cachehandler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// downloadsDir holds the remote sources kept in the cache directory
+	downloadsDir = "downloads"
+	// downloadMetadataFile records the version of a download next to its file
+	downloadMetadataFile = "download.json"
+)
+
+// Download is a remote source kept in the cache, with the version of the
+// object it was downloaded from
+type Download struct {
+	Source       string    `json:"source"`
+	File         string    `json:"file"` // Name of the downloaded file, from which the table name is derived
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"` // HTTP Last-Modified header, for servers without ETag
+	VersionID    string    `json:"version_id,omitempty"`    // S3 version id or GCS generation
+	Size         int64     `json:"size"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	LastUsedAt   time.Time `json:"last_used_at"`
+
+	dir string
+}
+
+// Path returns the path of the downloaded file
+func (d *Download) Path() string {
+	return filepath.Join(d.dir, d.File)
+}
+
+// Matches reports whether a remote object is the version that was downloaded.
+// A version id identifies an object better than its ETag, which S3 computes
+// differently for multipart uploads.
+func (d *Download) Matches(etag, versionID string) bool {
+	if d.VersionID != "" && versionID != "" {
+		return d.VersionID == versionID
+	}
+	return d.ETag != "" && d.ETag == etag
+}
+
+// DownloadCache keeps remote sources, so repeated queries against an
+// unchanged object skip the download. Downloaded files keep their path and
+// modification time, so the cached imports of these files are reused too.
+type DownloadCache struct {
+	dir string
+}
+
+// Downloads returns the cache of remote sources, or nil when caching is disabled
+func (h *CacheHandler) Downloads() *DownloadCache {
+	if !h.enabled {
+		return nil
+	}
+	return &DownloadCache{dir: filepath.Join(h.cacheDir, downloadsDir)}
+}
+
+// Lookup returns the download of a source, if its file is still in the cache
+func (c *DownloadCache) Lookup(source string) (*Download, bool) {
+	d, err := c.read(filepath.Join(c.dir, downloadKey(source)))
+	if err != nil || d.Source != source {
+		return nil, false
+	}
+	if _, err := os.Stat(d.Path()); err != nil {
+		return nil, false
+	}
+	return d, true
+}
+
+// Use records that a download was reused and returns the path of its file
+func (c *DownloadCache) Use(d *Download) string {
+	d.LastUsedAt = time.Now()
+	_ = d.write()
+	return d.Path()
+}
+
+// Store saves the content of a source, replacing a previous version, and
+// returns the path of the downloaded file
+func (c *DownloadCache) Store(d Download, content io.Reader) (string, error) {
+	return c.StoreFile(d, func(file *os.File) error {
+		_, err := io.Copy(file, content)
+		return err
+	})
+}
+
+// StoreFile saves a source written to file by write, for downloads that
+// write their parts at their offsets, and returns the path of the file
+func (c *DownloadCache) StoreFile(d Download, write func(file *os.File) error) (string, error) {
+	d.dir = filepath.Join(c.dir, downloadKey(d.Source))
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download cache directory: %w", err)
+	}
+
+	// Written aside and renamed, so an interrupted download never replaces a valid one
+	tmp, err := os.CreateTemp(d.dir, ".download-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create cached download: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	err = write(tmp)
+	if err == nil {
+		var info os.FileInfo
+		if info, err = tmp.Stat(); err == nil {
+			d.Size = info.Size()
+		}
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download file content: %w", err)
+	}
+
+	if previous, err := c.read(d.dir); err == nil && previous.File != d.File {
+		_ = os.Remove(previous.Path())
+	}
+	if err := os.Rename(tmp.Name(), d.Path()); err != nil {
+		return "", fmt.Errorf("failed to save cached download: %w", err)
+	}
+
+	d.FetchedAt = time.Now()
+	d.LastUsedAt = d.FetchedAt
+	if err := d.write(); err != nil {
+		return "", err
+	}
+	return d.Path(), nil
+}
+
+// List returns the cached downloads
+func (c *DownloadCache) List() ([]Download, error) {
+	entries, err := os.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read download cache directory: %w", err)
+	}
+
+	var downloads []Download
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		d, err := c.read(filepath.Join(c.dir, entry.Name()))
+		if err != nil {
+			continue // Skip downloads in progress or invalid entries
+		}
+		downloads = append(downloads, *d)
+	}
+	return downloads, nil
+}
+
+// Remove deletes a download and its file
+func (c *DownloadCache) Remove(d *Download) error {
+	if err := os.RemoveAll(d.dir); err != nil {
+		return fmt.Errorf("failed to remove cached download: %w", err)
+	}
+	return nil
+}
+
+// read loads the download kept in dir
+func (c *DownloadCache) read(dir string) (*Download, error) {
+	data, err := os.ReadFile(filepath.Join(dir, downloadMetadataFile))
+	if err != nil {
+		return nil, err
+	}
+	var d Download
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	d.dir = dir
+	return &d, nil
+}
+
+// write saves the metadata of a download
+func (d *Download) write() error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal download metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(d.dir, downloadMetadataFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write download metadata: %w", err)
+	}
+	return nil
+}
+
+// downloadKey names the directory of a source
+func downloadKey(source string) string {
+	hash := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(hash[:16])
+}
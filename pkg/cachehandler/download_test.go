@@ -0,0 +1,108 @@
+package cachehandler
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadCache_StoreAndLookup(t *testing.T) {
+	handler, _ := NewCacheHandler(t.TempDir(), true)
+	downloads := handler.Downloads()
+	source := "https://example.com/data/users.csv"
+
+	if _, ok := downloads.Lookup(source); ok {
+		t.Fatal("expected no cached download")
+	}
+
+	path, err := downloads.Store(Download{Source: source, File: "users.csv", ETag: `"v1"`}, strings.NewReader("id\n1\n"))
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if !strings.HasSuffix(path, "users.csv") {
+		t.Errorf("expected the file name of the source, got %s", path)
+	}
+
+	cached, ok := downloads.Lookup(source)
+	if !ok {
+		t.Fatal("expected a cached download")
+	}
+	if cached.Path() != path || cached.Size != 5 || cached.ETag != `"v1"` {
+		t.Errorf("unexpected download %+v", cached)
+	}
+
+	// A new version replaces the file at the same path
+	path2, err := downloads.Store(Download{Source: source, File: "users.csv", ETag: `"v2"`}, strings.NewReader("id\n1\n2\n"))
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	data, _ := os.ReadFile(path2)
+	if path2 != path || string(data) != "id\n1\n2\n" {
+		t.Errorf("expected the new version at %s, got %q at %s", path, data, path2)
+	}
+
+	// Reusing a download keeps its file untouched, so the import cache key is stable
+	cached, _ = downloads.Lookup(source)
+	before, _ := os.Stat(path)
+	if downloads.Use(cached) != path {
+		t.Error("Use should return the path of the download")
+	}
+	after, _ := os.Stat(path)
+	if !after.ModTime().Equal(before.ModTime()) {
+		t.Error("Use should not modify the downloaded file")
+	}
+
+	if _, ok := downloads.Lookup("https://example.com/other.csv"); ok {
+		t.Error("expected no cached download for another source")
+	}
+}
+
+func TestDownload_Matches(t *testing.T) {
+	d := &Download{ETag: `"abc"`, VersionID: "42"}
+	if !d.Matches(`"abc"`, "42") || !d.Matches(`"other"`, "42") {
+		t.Error("the version id should identify the object")
+	}
+	if d.Matches(`"abc"`, "43") {
+		t.Error("another version id should not match")
+	}
+
+	d = &Download{ETag: `"abc"`}
+	if !d.Matches(`"abc"`, "") || d.Matches(`"def"`, "") {
+		t.Error("the ETag should identify an unversioned object")
+	}
+	if (&Download{}).Matches("", "") {
+		t.Error("a download without ETag should never match")
+	}
+}
+
+func TestPrune_Downloads(t *testing.T) {
+	handler, _ := NewCacheHandler(t.TempDir(), true)
+	downloads := handler.Downloads()
+	for _, source := range []string{"s3://bucket/old.csv", "s3://bucket/new.csv"} {
+		if _, err := downloads.Store(Download{Source: source, File: "data.csv", ETag: "e"}, strings.NewReader("id\n1\n")); err != nil {
+			t.Fatalf("Store failed: %v", err)
+		}
+	}
+	old, _ := downloads.Lookup("s3://bucket/old.csv")
+	old.LastUsedAt = time.Now().Add(-48 * time.Hour)
+	if err := old.write(); err != nil {
+		t.Fatalf("failed to write metadata: %v", err)
+	}
+
+	handler.SetLimits(24*time.Hour, 0)
+	result, err := handler.Prune()
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	if result.Expired != 1 {
+		t.Errorf("expected 1 expired download, got %+v", result)
+	}
+	if _, ok := downloads.Lookup("s3://bucket/old.csv"); ok {
+		t.Error("download unused for longer than the TTL should have been removed")
+	}
+	if _, ok := downloads.Lookup("s3://bucket/new.csv"); !ok {
+		t.Error("recently used download should still exist")
+	}
+}
@@ -0,0 +1,280 @@
+package cachehandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// EnvTTL sets the default --cache-ttl
+	EnvTTL = "DATAQL_CACHE_TTL"
+	// EnvMaxSize sets the default --cache-max-size
+	EnvMaxSize = "DATAQL_CACHE_MAX_SIZE"
+
+	// orphanGrace keeps files without metadata that an import may still be writing
+	orphanGrace = time.Hour
+)
+
+// PruneResult reports what Prune removed
+type PruneResult struct {
+	Expired    int   // Entries older than the TTL
+	Evicted    int   // Least recently used entries removed to fit the size limit
+	Orphans    int   // Cache files left without metadata by interrupted imports
+	FreedBytes int64 // Size of the removed files
+}
+
+// Removed returns the number of removed entries and orphan files
+func (r PruneResult) Removed() int {
+	return r.Expired + r.Evicted + r.Orphans
+}
+
+// SetLimits sets the age after which entries are stale and the size above
+// which least recently used entries are evicted; zero disables a limit
+func (h *CacheHandler) SetLimits(ttl time.Duration, maxSize int64) {
+	h.ttl = ttl
+	h.maxSize = maxSize
+}
+
+// HasLimits reports whether a TTL or a size limit is set
+func (h *CacheHandler) HasLimits() bool {
+	return h.ttl > 0 || h.maxSize > 0
+}
+
+// Touch records a cache hit, so the entry is evicted after the ones used less recently
+func (h *CacheHandler) Touch(cacheKey string) error {
+	metadata, err := h.ReadMetadata(cacheKey)
+	if err != nil {
+		return err
+	}
+	metadata.LastUsedAt = time.Now()
+	return h.writeMetadata(cacheKey, metadata)
+}
+
+// Prune removes expired entries and orphan files, then the least recently
+// used entries until the cache fits the size limit. Downloads of remote
+// sources count as entries, expiring when unused for longer than the TTL.
+// Entries in keep, such as the one in use, are never removed.
+func (h *CacheHandler) Prune(keep ...string) (PruneResult, error) {
+	var result PruneResult
+	if !h.enabled {
+		return result, fmt.Errorf("cache not enabled")
+	}
+
+	now := time.Now()
+	result.Orphans, result.FreedBytes = h.removeOrphans(now, keep)
+
+	items, err := h.prunables()
+	if err != nil {
+		return result, err
+	}
+
+	var total int64
+	var remaining []prunable
+	for _, item := range items {
+		if !contains(keep, item.key) && h.ttl > 0 && now.Sub(item.age) > h.ttl {
+			if err := item.remove(); err != nil {
+				return result, err
+			}
+			result.Expired++
+			result.FreedBytes += item.size
+			continue
+		}
+		total += item.size
+		remaining = append(remaining, item)
+	}
+
+	if h.maxSize <= 0 || total <= h.maxSize {
+		return result, nil
+	}
+
+	// Least recently used first
+	sort.Slice(remaining, func(i, j int) bool {
+		return remaining[i].lastUsed.Before(remaining[j].lastUsed)
+	})
+	for _, item := range remaining {
+		if total <= h.maxSize {
+			break
+		}
+		if contains(keep, item.key) {
+			continue
+		}
+		if err := item.remove(); err != nil {
+			return result, err
+		}
+		result.Evicted++
+		result.FreedBytes += item.size
+		total -= item.size
+	}
+	return result, nil
+}
+
+// prunable is a cache entry or download that Prune may remove
+type prunable struct {
+	key      string
+	size     int64
+	age      time.Time // Start of the TTL
+	lastUsed time.Time
+	remove   func() error
+}
+
+// prunables returns the cache entries and downloads
+func (h *CacheHandler) prunables() ([]prunable, error) {
+	entries, err := h.ListCache()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []prunable
+	for _, entry := range entries {
+		key := entry.CacheKey
+		items = append(items, prunable{
+			key:      key,
+			size:     entry.SizeBytes,
+			age:      entry.CachedAt,
+			lastUsed: entry.LastUsedAt,
+			remove:   func() error { return h.ClearCacheEntry(key) },
+		})
+	}
+
+	downloads := h.Downloads()
+	list, err := downloads.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		d := &list[i]
+		items = append(items, prunable{
+			key:      d.Source,
+			size:     d.Size,
+			age:      d.LastUsedAt,
+			lastUsed: d.LastUsedAt,
+			remove:   func() error { return downloads.Remove(d) },
+		})
+	}
+	return items, nil
+}
+
+// removeOrphans removes cache files without metadata, older than orphanGrace
+func (h *CacheHandler) removeOrphans(now time.Time, keep []string) (int, int64) {
+	files, err := os.ReadDir(h.cacheDir)
+	if err != nil {
+		return 0, 0
+	}
+
+	var removed int
+	var freed int64
+	for _, file := range files {
+		name := file.Name()
+		key := strings.TrimSuffix(strings.TrimSuffix(name, walSuffix), ".duckdb")
+		if key == name || contains(keep, key) {
+			continue
+		}
+		if _, err := os.Stat(h.GetMetadataPath(key)); err == nil {
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil || now.Sub(info.ModTime()) < orphanGrace {
+			continue
+		}
+		if err := os.Remove(filepath.Join(h.cacheDir, name)); err == nil {
+			removed++
+			freed += info.Size()
+		}
+	}
+	return removed, freed
+}
+
+// expired reports whether an entry is older than the TTL
+func (h *CacheHandler) expired(metadata *CacheMetadata, now time.Time) bool {
+	return h.ttl > 0 && now.Sub(metadata.CachedAt) > h.ttl
+}
+
+// entrySize returns the size of the files of an entry
+func (h *CacheHandler) entrySize(cacheKey string) int64 {
+	var size int64
+	cachePath := h.GetCachePath(cacheKey)
+	for _, path := range []string{cachePath, cachePath + walSuffix, h.GetMetadataPath(cacheKey)} {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// writeMetadata replaces the metadata of an entry
+func (h *CacheHandler) writeMetadata(cacheKey string, metadata *CacheMetadata) error {
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err := os.WriteFile(h.GetMetadataPath(cacheKey), data, 0644); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return nil
+}
+
+// lastUsed returns the last cache hit, or the creation time of an entry never hit
+func (m *CacheMetadata) lastUsed() time.Time {
+	if m.LastUsedAt.IsZero() {
+		return m.CachedAt
+	}
+	return m.LastUsedAt
+}
+
+// ParseTTL parses durations such as 24h, 90m or 7d
+func ParseTTL(text string) (time.Duration, error) {
+	text = strings.TrimSpace(text)
+	if days, ok := strings.CutSuffix(text, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid cache TTL %q (e.g. 24h, 90m, 7d)", text)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+
+	ttl, err := time.ParseDuration(text)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid cache TTL %q (e.g. 24h, 90m, 7d)", text)
+	}
+	return ttl, nil
+}
+
+// ParseSize parses sizes such as 500MB, 10GB, 1.5TB or a number of bytes
+func ParseSize(text string) (int64, error) {
+	units := []struct {
+		suffix string
+		factor float64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"TB", 1 << 40}, {"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40}, {"B", 1}}
+
+	value := strings.ToUpper(strings.TrimSpace(text))
+	factor := 1.0
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			factor = unit.factor
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid cache size %q (e.g. 500MB, 10GB)", text)
+	}
+	return int64(n * factor), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package cachehandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeEntry creates a cache entry with a database of size bytes
+func writeEntry(t *testing.T, handler *CacheHandler, key string, size int, cachedAt, lastUsedAt time.Time) {
+	t.Helper()
+	if err := os.WriteFile(handler.GetCachePath(key), make([]byte, size), 0644); err != nil {
+		t.Fatalf("failed to create cache file: %v", err)
+	}
+	metadata := &CacheMetadata{CachedAt: cachedAt, LastUsedAt: lastUsedAt, FileHash: key, FormatVersion: cacheFormatVersion}
+	if err := handler.writeMetadata(key, metadata); err != nil {
+		t.Fatalf("failed to write metadata: %v", err)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestPrune_TTL(t *testing.T) {
+	handler, _ := NewCacheHandler(t.TempDir(), true)
+	now := time.Now()
+	writeEntry(t, handler, "old", 10, now.Add(-48*time.Hour), time.Time{})
+	writeEntry(t, handler, "kept", 10, now.Add(-48*time.Hour), time.Time{})
+	writeEntry(t, handler, "fresh", 10, now.Add(-time.Hour), time.Time{})
+	handler.SetLimits(24*time.Hour, 0)
+
+	result, err := handler.Prune("kept")
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	if result.Expired != 1 || result.Removed() != 1 {
+		t.Errorf("expected 1 expired entry, got %+v", result)
+	}
+	if exists(handler.GetCachePath("old")) || exists(handler.GetMetadataPath("old")) {
+		t.Error("expired entry should have been removed")
+	}
+	if !exists(handler.GetCachePath("kept")) || !exists(handler.GetCachePath("fresh")) {
+		t.Error("kept and fresh entries should still exist")
+	}
+}
+
+func TestPrune_LRU(t *testing.T) {
+	handler, _ := NewCacheHandler(t.TempDir(), true)
+	now := time.Now()
+	writeEntry(t, handler, "a", 1000, now.Add(-3*time.Hour), now.Add(-time.Minute))
+	writeEntry(t, handler, "b", 1000, now.Add(-2*time.Hour), time.Time{})
+	writeEntry(t, handler, "c", 1000, now.Add(-time.Hour), now.Add(-30*time.Minute))
+
+	size := handler.entrySize("b")
+	handler.SetLimits(0, handler.entrySize("a")+handler.entrySize("c"))
+
+	result, err := handler.Prune()
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	if result.Evicted != 1 || result.FreedBytes != size {
+		t.Errorf("expected 1 evicted entry of %d bytes, got %+v", size, result)
+	}
+	// b was never hit, so it was last used when cached, before a and c
+	if exists(handler.GetCachePath("b")) {
+		t.Error("least recently used entry should have been evicted")
+	}
+	if !exists(handler.GetCachePath("a")) || !exists(handler.GetCachePath("c")) {
+		t.Error("recently used entries should still exist")
+	}
+}
+
+func TestPrune_Orphans(t *testing.T) {
+	tmpDir := t.TempDir()
+	handler, _ := NewCacheHandler(tmpDir, true)
+
+	orphan := filepath.Join(tmpDir, "orphan.duckdb")
+	importing := filepath.Join(tmpDir, "importing.duckdb")
+	for _, path := range []string{orphan, orphan + walSuffix, importing} {
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+	old := time.Now().Add(-2 * orphanGrace)
+	_ = os.Chtimes(orphan, old, old)
+	_ = os.Chtimes(orphan+walSuffix, old, old)
+
+	result, err := handler.Prune()
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	if result.Orphans != 2 {
+		t.Errorf("expected 2 orphan files, got %+v", result)
+	}
+	if exists(orphan) || exists(orphan+walSuffix) {
+		t.Error("orphan files should have been removed")
+	}
+	if !exists(importing) {
+		t.Error("a recent file may belong to an import in progress and should still exist")
+	}
+}
+
+func TestIsCacheValid_Expired(t *testing.T) {
+	tmpDir := t.TempDir()
+	handler, _ := NewCacheHandler(tmpDir, true)
+
+	tmpFile := filepath.Join(tmpDir, "test.csv")
+	if err := os.WriteFile(tmpFile, []byte("a,b,c\n1,2,3"), 0644); err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	cacheKey, _ := handler.GenerateCacheKey([]string{tmpFile})
+	if err := os.WriteFile(handler.GetCachePath(cacheKey), []byte("duckdb data"), 0644); err != nil {
+		t.Fatalf("failed to create cache file: %v", err)
+	}
+	if err := handler.SaveMetadata(cacheKey, []string{tmpFile}, []string{"test"}, 1); err != nil {
+		t.Fatalf("SaveMetadata failed: %v", err)
+	}
+
+	handler.SetLimits(time.Hour, 0)
+	if valid, _, _ := handler.IsCacheValid([]string{tmpFile}); !valid {
+		t.Fatal("fresh cache should be valid")
+	}
+
+	handler.SetLimits(time.Nanosecond, 0)
+	if valid, _, _ := handler.IsCacheValid([]string{tmpFile}); valid {
+		t.Error("expired cache should not be valid")
+	}
+	if exists(handler.GetCachePath(cacheKey)) {
+		t.Error("expired cache file should have been removed before re-import")
+	}
+}
+
+func TestTouch(t *testing.T) {
+	handler, _ := NewCacheHandler(t.TempDir(), true)
+	cachedAt := time.Now().Add(-time.Hour)
+	writeEntry(t, handler, "entry", 10, cachedAt, time.Time{})
+
+	if err := handler.Touch("entry"); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+
+	entries, err := handler.ListCache()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListCache failed: %v", err)
+	}
+	if !entries[0].LastUsedAt.After(cachedAt) {
+		t.Errorf("expected last use after %v, got %v", cachedAt, entries[0].LastUsedAt)
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	tests := map[string]time.Duration{
+		"24h":  24 * time.Hour,
+		"90m":  90 * time.Minute,
+		"7d":   7 * 24 * time.Hour,
+		"1.5d": 36 * time.Hour,
+	}
+	for text, expected := range tests {
+		ttl, err := ParseTTL(text)
+		if err != nil {
+			t.Errorf("ParseTTL(%q) failed: %v", text, err)
+		} else if ttl != expected {
+			t.Errorf("ParseTTL(%q) = %v, expected %v", text, ttl, expected)
+		}
+	}
+
+	for _, text := range []string{"", "day", "-1h", "0d", "3w"} {
+		if _, err := ParseTTL(text); err == nil {
+			t.Errorf("ParseTTL(%q) should fail", text)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"10GB":   10 << 30,
+		"500mb":  500 << 20,
+		"1.5TB":  3 << 39,
+		"64K":    64 << 10,
+		"1024":   1024,
+		"2048 B": 2048,
+	}
+	for text, expected := range tests {
+		size, err := ParseSize(text)
+		if err != nil {
+			t.Errorf("ParseSize(%q) failed: %v", text, err)
+		} else if size != expected {
+			t.Errorf("ParseSize(%q) = %d, expected %d", text, size, expected)
+		}
+	}
+
+	for _, text := range []string{"", "GB", "-1GB", "ten"} {
+		if _, err := ParseSize(text); err == nil {
+			t.Errorf("ParseSize(%q) should fail", text)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Package clierror classifies the errors that stop a command by what failed:
+// the command line, a data source, a SQL statement or the export. Each kind
+// of failure has its own exit code and, with --error-format json, is reported
+// on stderr as a JSON object, so scripts and orchestrators can branch on the
+// failure type instead of parsing messages.
+package clierror
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/queryerror"
+)
+
+// Category is the stage of a command that failed
+type Category string
+
+const (
+	CategoryParse  Category = "parse"  // Invalid flags, arguments or options
+	CategorySource Category = "source" // A source could not be read or imported
+	CategoryQuery  Category = "query"  // A SQL statement failed
+	CategoryExport Category = "export" // The result could not be written
+)
+
+// Error codes, more specific than the categories
+const (
+	CodeInvalidArguments = "invalid_arguments"
+	CodeSourceNotFound   = "source_not_found"
+	CodeSourceError      = "source_error"
+	CodeSQLSyntaxError   = "sql_syntax_error"
+	CodeSQLError         = "sql_error"
+	CodeExportError      = "export_error"
+	CodeEmptyResult      = "empty_result"
+	CodeError            = "error" // Errors of no category
+)
+
+// Exit codes of the process, so scripts can react to the failure
+const (
+	ExitError            = 1 // Errors of no category
+	ExitInvalidArguments = 2
+	ExitSourceNotFound   = 3
+	ExitSourceError      = 4 // Unreachable, unreadable or invalid sources
+	ExitQueryError       = 5
+	ExitExportError      = 6
+	ExitEmptyResult      = 7 // The query returned no rows with --fail-on-empty
+)
+
+// ErrEmptyResult is returned by --fail-on-empty when the query returned no rows
+var ErrEmptyResult = &Error{Category: CategoryQuery, Err: errors.New("the query returned no rows")}
+
+// Formats of --error-format
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// EnvFormat sets the default of --error-format
+const EnvFormat = "DATAQL_ERROR_FORMAT"
+
+// Error is an error classified by category, with the source it is about
+type Error struct {
+	Category Category
+	Source   string // Offending source, when known
+	Err      error
+}
+
+// Error returns the message of the wrapped error
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// wrap classifies err, unless it is nil or already classified, since the
+// innermost classification is the most precise
+func wrap(category Category, source string, err error) error {
+	var classified *Error
+	if err == nil || errors.As(err, &classified) {
+		return err
+	}
+	return &Error{Category: category, Source: source, Err: err}
+}
+
+// Parse classifies an error of the command line
+func Parse(err error) error {
+	return wrap(CategoryParse, "", err)
+}
+
+// Source classifies an error reading source, which may be empty when unknown
+func Source(source string, err error) error {
+	return wrap(CategorySource, source, err)
+}
+
+// Query classifies an error of a SQL statement
+func Query(err error) error {
+	return wrap(CategoryQuery, "", err)
+}
+
+// Export classifies an error writing the result
+func Export(err error) error {
+	return wrap(CategoryExport, "", err)
+}
+
+// Report is the JSON object describing an error
+type Report struct {
+	Code     string   `json:"code"`
+	ExitCode int      `json:"exit_code"`
+	Category Category `json:"category,omitempty"`
+	Message  string   `json:"message"`
+	Hint     string   `json:"hint,omitempty"`
+	Example  string   `json:"example,omitempty"`
+	Detail   string   `json:"detail,omitempty"` // Original error of an enhanced SQL error
+	Source   string   `json:"source,omitempty"`
+}
+
+// NewReport describes err
+func NewReport(err error) Report {
+	report := Report{Code: CodeError, ExitCode: ExitCode(err), Message: err.Error()}
+
+	var classified *Error
+	if errors.As(err, &classified) {
+		// The message starts where the error was classified, without the
+		// context added by the callers, e.g. "failed to execute command"
+		report.Message = classified.Error()
+		report.Category = classified.Category
+		report.Source = classified.Source
+		report.Code = code(classified)
+	}
+
+	var hint *queryerror.ErrorHint
+	if errors.As(err, &hint) {
+		// The hint and example get their own fields
+		report.Message = strings.Replace(report.Message, hint.Error(), hint.Message, 1)
+		report.Hint = hint.Hint
+		report.Example = hint.Example
+		report.Detail = hint.Original
+	}
+	return report
+}
+
+// code returns the error code of a classified error
+func code(e *Error) string {
+	if e == ErrEmptyResult {
+		return CodeEmptyResult
+	}
+	switch e.Category {
+	case CategoryParse:
+		return CodeInvalidArguments
+	case CategorySource:
+		if errors.Is(e.Err, fs.ErrNotExist) {
+			return CodeSourceNotFound
+		}
+		return CodeSourceError
+	case CategoryQuery:
+		if isSyntaxError(e.Err) {
+			return CodeSQLSyntaxError
+		}
+		return CodeSQLError
+	case CategoryExport:
+		return CodeExportError
+	}
+	return CodeError
+}
+
+// ExitCode returns the exit code of the process for err
+func ExitCode(err error) int {
+	var classified *Error
+	if !errors.As(err, &classified) {
+		return ExitError
+	}
+	switch code(classified) {
+	case CodeInvalidArguments:
+		return ExitInvalidArguments
+	case CodeSourceNotFound:
+		return ExitSourceNotFound
+	case CodeSourceError:
+		return ExitSourceError
+	case CodeSQLSyntaxError, CodeSQLError:
+		return ExitQueryError
+	case CodeExportError:
+		return ExitExportError
+	case CodeEmptyResult:
+		return ExitEmptyResult
+	}
+	return ExitError
+}
+
+// isSyntaxError reports whether a SQL error was raised by the parser
+func isSyntaxError(err error) bool {
+	message := err.Error()
+	var hint *queryerror.ErrorHint
+	if errors.As(err, &hint) {
+		message = hint.Original
+	}
+	return strings.Contains(message, "Parser Error") || strings.Contains(message, "syntax error")
+}
+
+// Write writes err to w as text, or as a JSON object on one line
+func Write(w io.Writer, format string, err error) error {
+	if format == FormatJSON {
+		return json.NewEncoder(w).Encode(NewReport(err))
+	}
+	_, writeErr := io.WriteString(w, err.Error()+"\n")
+	return writeErr
+}
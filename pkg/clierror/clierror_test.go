@@ -0,0 +1,95 @@
+package clierror
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/queryerror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReport(t *testing.T) {
+	notFound := fmt.Errorf("open missing.csv: %w", fs.ErrNotExist)
+	syntax := queryerror.EnhanceError(errors.New(`Parser Error: syntax error at or near "selec"`))
+
+	tests := []struct {
+		name     string
+		err      error
+		code     string
+		category Category
+		source   string
+	}{
+		{"parse", Parse(errors.New("unknown flag: --bogus")), CodeInvalidArguments, CategoryParse, ""},
+		{"source not found", Source("missing.csv", notFound), CodeSourceNotFound, CategorySource, "missing.csv"},
+		{"source error", Source("", errors.New("connection refused")), CodeSourceError, CategorySource, ""},
+		{"syntax error", Query(fmt.Errorf("failed to execute query: %w", syntax)), CodeSQLSyntaxError, CategoryQuery, ""},
+		{"sql error", Query(errors.New("Binder Error: ambiguous")), CodeSQLError, CategoryQuery, ""},
+		{"export", Export(errors.New("disk full")), CodeExportError, CategoryExport, ""},
+		{"unclassified", errors.New("boom"), CodeError, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewReport(fmt.Errorf("failed to execute command %w", tt.err))
+			assert.Equal(t, tt.code, report.Code)
+			assert.Equal(t, tt.category, report.Category)
+			assert.Equal(t, tt.source, report.Source)
+		})
+	}
+}
+
+func TestNewReport_SplitsHints(t *testing.T) {
+	hinted := queryerror.EnhanceError(errors.New(`Parser Error: syntax error at or near "selec"`))
+	report := NewReport(fmt.Errorf("failed to run dataql: %w", Query(fmt.Errorf("failed to execute query: %w", hinted))))
+
+	assert.Equal(t, "failed to execute query: SQL syntax error near 'selec'", report.Message, "the message leaves out the callers' context and the hint")
+	assert.Contains(t, report.Hint, "Check your SQL syntax")
+	assert.NotEmpty(t, report.Example)
+	assert.Contains(t, report.Detail, "Parser Error")
+}
+
+func TestWrap_KeepsInnermostClassification(t *testing.T) {
+	err := Export(fmt.Errorf("failed: %w", Source("a.csv", errors.New("bad"))))
+	assert.Equal(t, CategorySource, NewReport(err).Category)
+	assert.Nil(t, Query(nil))
+}
+
+func TestExitCode(t *testing.T) {
+	notFound := fmt.Errorf("open missing.csv: %w", fs.ErrNotExist)
+
+	assert.Equal(t, ExitError, ExitCode(errors.New("boom")))
+	assert.Equal(t, ExitInvalidArguments, ExitCode(Parse(errors.New("unknown flag"))))
+	assert.Equal(t, ExitSourceNotFound, ExitCode(fmt.Errorf("failed to run: %w", Source("missing.csv", notFound))))
+	assert.Equal(t, ExitSourceError, ExitCode(Source("", errors.New("connection refused"))))
+	assert.Equal(t, ExitQueryError, ExitCode(Query(errors.New("Binder Error"))))
+	assert.Equal(t, ExitExportError, ExitCode(Export(errors.New("disk full"))))
+	assert.Equal(t, ExitEmptyResult, ExitCode(fmt.Errorf("failed to run: %w", ErrEmptyResult)))
+
+	report := NewReport(ErrEmptyResult)
+	assert.Equal(t, CodeEmptyResult, report.Code)
+	assert.Equal(t, ExitEmptyResult, report.ExitCode)
+}
+
+func TestWrite(t *testing.T) {
+	err := Source("missing.csv", fmt.Errorf("open missing.csv: %w", fs.ErrNotExist))
+
+	var text bytes.Buffer
+	require.NoError(t, Write(&text, FormatText, err))
+	assert.Equal(t, "open missing.csv: file does not exist\n", text.String())
+
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, FormatJSON, err))
+	var report map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, map[string]any{
+		"code":      CodeSourceNotFound,
+		"exit_code": float64(ExitSourceNotFound),
+		"category":  string(CategorySource),
+		"message":   "open missing.csv: file does not exist",
+		"source":    "missing.csv",
+	}, report)
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/adrianolaselva/dataql/pkg/progress"
 	"github.com/ulikunitz/xz"
 )
 
@@ -36,7 +37,8 @@ var compressionExtensions = map[string]Compression{
 // CompressionHandler handles decompression of compressed files
 type CompressionHandler struct {
 	tempFiles     []string
-	originalPaths map[string]string // maps decompressed path -> original path
+	originalPaths map[string]string  // maps decompressed path -> original path
+	progress      *progress.Reporter // Shows the decompression (nil: not shown)
 }
 
 // NewCompressionHandler creates a new compression handler
@@ -47,6 +49,11 @@ func NewCompressionHandler() *CompressionHandler {
 	}
 }
 
+// SetProgress shows the progress of the decompression with reporter
+func (h *CompressionHandler) SetProgress(reporter *progress.Reporter) {
+	h.progress = reporter
+}
+
 // GetOriginalPath returns the original path for a decompressed file path
 // If the path was not decompressed, returns the same path
 func (h *CompressionHandler) GetOriginalPath(decompressedPath string) string {
@@ -132,6 +139,15 @@ func (h *CompressionHandler) decompressFile(filePath string) (string, error) {
 	}
 	defer inputFile.Close()
 
+	// Progress is measured on the compressed bytes, whose total is known
+	var size int64 = -1
+	if info, err := inputFile.Stat(); err == nil {
+		size = info.Size()
+	}
+	phase := h.progress.Start(progress.Decompress, filePath, size)
+	defer phase.Done()
+	input := phase.Reader(inputFile)
+
 	// Create a temp file with the inner extension
 	innerExt := GetInnerExtension(filePath)
 	tempFile, err := os.CreateTemp("", "dataql_decompressed_*"+innerExt)
@@ -145,7 +161,7 @@ func (h *CompressionHandler) decompressFile(filePath string) (string, error) {
 	var reader io.Reader
 	switch compression {
 	case CompressionGzip:
-		gzReader, err := gzip.NewReader(inputFile)
+		gzReader, err := gzip.NewReader(input)
 		if err != nil {
 			tempFile.Close()
 			return "", fmt.Errorf("failed to create gzip reader: %w", err)
@@ -153,9 +169,9 @@ func (h *CompressionHandler) decompressFile(filePath string) (string, error) {
 		defer gzReader.Close()
 		reader = gzReader
 	case CompressionBzip2:
-		reader = bzip2.NewReader(inputFile)
+		reader = bzip2.NewReader(input)
 	case CompressionXZ:
-		xzReader, err := xz.NewReader(inputFile)
+		xzReader, err := xz.NewReader(input)
 		if err != nil {
 			tempFile.Close()
 			return "", fmt.Errorf("failed to create xz reader: %w", err)
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// EnvConfigPath overrides the location of the configuration file
+	EnvConfigPath = "DATAQL_CONFIG"
+	// SectionAliases holds user-defined REPL command aliases (name = SQL)
+	SectionAliases = "aliases"
+	// SectionMacros holds SQL macros shared across queries (name(args) = expression)
+	SectionMacros = "macros"
+	// MacroFilesKey lists SQL files of CREATE MACRO statements in the macros section
+	MacroFilesKey = "files"
+)
+
+// Config holds the settings read from the user configuration file.
+// The file uses an INI-like format:
+//
+//	# comment
+//	[aliases]
+//	errors = SELECT * FROM logs WHERE level = 'ERROR' LIMIT :1
+//
+//	[macros]
+//	files = ~/team/macros.sql
+//	clean_phone(x) = regexp_replace(x, '[^0-9]', '', 'g')
+type Config struct {
+	sections map[string]map[string]string
+}
+
+// New creates an empty configuration
+func New() *Config {
+	return &Config{sections: make(map[string]map[string]string)}
+}
+
+// DefaultPath returns the configuration file path: $DATAQL_CONFIG or ~/.dataql/config
+func DefaultPath() string {
+	if path := os.Getenv(EnvConfigPath); path != "" {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".dataql", "config")
+}
+
+// Load reads the configuration file at path. A missing file yields an empty configuration.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		return New(), nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return New(), nil
+		}
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	cfg, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse reads a configuration from r
+func Parse(r io.Reader) (*Config, error) {
+	cfg := New()
+	section := ""
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid section header: %s", lineNumber, line)
+			}
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+
+		idx := strings.Index(line, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("line %d: expected key = value: %s", lineNumber, line)
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:idx]))
+		value := strings.TrimSpace(line[idx+1:])
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", lineNumber)
+		}
+
+		if cfg.sections[section] == nil {
+			cfg.sections[section] = make(map[string]string)
+		}
+		cfg.sections[section][key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Section returns the key/value pairs of a section (nil if the section is not defined)
+func (c *Config) Section(name string) map[string]string {
+	return c.sections[strings.ToLower(name)]
+}
+
+// Aliases returns the user-defined REPL command aliases
+func (c *Config) Aliases() map[string]string {
+	return c.Section(SectionAliases)
+}
+
+// Macros returns the macros defined inline as name(args) = expression
+func (c *Config) Macros() map[string]string {
+	macros := make(map[string]string)
+	for key, value := range c.Section(SectionMacros) {
+		if key != MacroFilesKey {
+			macros[key] = value
+		}
+	}
+	return macros
+}
+
+// MacroFiles returns the comma-separated files of the macros section
+func (c *Config) MacroFiles() []string {
+	var files []string
+	for _, file := range strings.Split(c.Section(SectionMacros)[MacroFilesKey], ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
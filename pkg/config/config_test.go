@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	input := `# team shortcuts
+[aliases]
+errors = SELECT * FROM logs WHERE level = 'ERROR' LIMIT :1
+Top = SELECT * FROM sales ORDER BY amount DESC LIMIT 10
+
+; other sections are kept for later use
+[display]
+mode = json
+`
+	cfg, err := Parse(strings.NewReader(input))
+	assert.NoError(t, err)
+
+	aliases := cfg.Aliases()
+	assert.Len(t, aliases, 2)
+	assert.Equal(t, "SELECT * FROM logs WHERE level = 'ERROR' LIMIT :1", aliases["errors"])
+	assert.Equal(t, "SELECT * FROM sales ORDER BY amount DESC LIMIT 10", aliases["top"])
+	assert.Equal(t, "json", cfg.Section("DISPLAY")["mode"])
+	assert.Nil(t, cfg.Section("missing"))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("[aliases\nerrors = SELECT 1"))
+	assert.Error(t, err)
+
+	_, err = Parse(strings.NewReader("[aliases]\njust some text"))
+	assert.Error(t, err)
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Aliases())
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	assert.NoError(t, os.WriteFile(path, []byte("[aliases]\ncount = SELECT COUNT(*) FROM :1\n"), 0644))
+
+	cfg, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM :1", cfg.Aliases()["count"])
+}
+
+func TestDefaultPath_Env(t *testing.T) {
+	t.Setenv(EnvConfigPath, "/tmp/custom-config")
+	assert.Equal(t, "/tmp/custom-config", DefaultPath())
+}
+
+func TestMacros(t *testing.T) {
+	input := `[macros]
+files = ~/team/macros.sql, shared.sql ,
+Clean_Phone(x) = regexp_replace(x, '[^0-9]', '', 'g')
+`
+	cfg, err := Parse(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"~/team/macros.sql", "shared.sql"}, cfg.MacroFiles())
+	assert.Equal(t, map[string]string{"clean_phone(x)": "regexp_replace(x, '[^0-9]', '', 'g')"}, cfg.Macros())
+
+	assert.Empty(t, New().Macros())
+	assert.Empty(t, New().MacroFiles())
+}
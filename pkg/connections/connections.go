@@ -0,0 +1,218 @@
+// Package connections keeps named database and message queue connections
+// in the OS keyring, so a source such as mydb://orders needs neither
+// credentials on the command line nor in a configuration file.
+package connections
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/keyring"
+)
+
+// indexAccount is the keyring account listing the names of the connections,
+// since OS keyrings cannot list their entries
+const indexAccount = "connections"
+
+// ErrNotFound is returned when no connection has the given name
+var ErrNotFound = errors.New("connection not found")
+
+// namePattern matches connection names, which are used as URL schemes
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// reservedNames are the URL schemes of sources read without a connection
+var reservedNames = map[string]bool{"http": true, "https": true, "s3": true, "gs": true, "azure": true, "az": true, "file": true}
+
+// Connection is a URL, e.g. postgres://db:5432/shop, with its credentials
+type Connection struct {
+	Name     string `json:"-"`
+	URL      string `json:"url"` // Without credentials
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// account returns the keyring account of a connection
+func account(name string) string {
+	return "connection:" + name
+}
+
+// ValidateName checks that name can be used as the URL scheme of a source
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid connection name %q: use lowercase letters, digits and dashes, starting with a letter", name)
+	}
+	if reservedNames[name] {
+		return fmt.Errorf("connection name %q is a URL scheme read by dataql", name)
+	}
+	if format, err := filehandler.DetectFormat(name + "://"); err == nil {
+		return fmt.Errorf("connection name %q is the URL scheme of %s", name, format)
+	}
+	return nil
+}
+
+// ValidateURL checks that a connection URL names a database or message
+// queue and holds no password, which is stored apart
+func ValidateURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" && u.Opaque == "" && u.Path == "" {
+		return nil, fmt.Errorf("invalid connection URL %q", rawURL)
+	}
+	format, err := filehandler.DetectFormat(rawURL)
+	if err != nil || !isConnectionFormat(format) {
+		return nil, fmt.Errorf("unsupported connection URL %q (use postgres://, mysql://, mongodb://, kafka://, rabbitmq:// ...)", u.Redacted())
+	}
+	if _, ok := u.User.Password(); ok {
+		return nil, fmt.Errorf("the connection URL must not hold the password, which is prompted for")
+	}
+	return u, nil
+}
+
+// isConnectionFormat reports whether a format is read from a server
+func isConnectionFormat(format filehandler.Format) bool {
+	switch format {
+	case filehandler.FormatPostgres, filehandler.FormatMySQL, filehandler.FormatMongoDB,
+		filehandler.FormatDynamoDB, filehandler.FormatDuckDB, filehandler.FormatMQ:
+		return true
+	}
+	return false
+}
+
+// Save stores a connection, replacing the one with the same name
+func Save(c Connection) error {
+	if err := ValidateName(c.Name); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode connection: %w", err)
+	}
+	if err := keyring.Set(account(c.Name), string(data)); err != nil {
+		return err
+	}
+
+	names, err := List()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name == c.Name {
+			return nil
+		}
+	}
+	return saveIndex(append(names, c.Name))
+}
+
+// Load reads a connection
+func Load(name string) (*Connection, error) {
+	data, err := keyring.Get(account(name))
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Connection{Name: name}
+	if err := json.Unmarshal([]byte(data), c); err != nil {
+		return nil, fmt.Errorf("failed to decode connection %s: %w", name, err)
+	}
+	return c, nil
+}
+
+// Remove deletes a connection
+func Remove(name string) error {
+	if err := keyring.Delete(account(name)); err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	names, err := List()
+	if err != nil {
+		return err
+	}
+	kept := names[:0]
+	for _, n := range names {
+		if n != name {
+			kept = append(kept, n)
+		}
+	}
+	return saveIndex(kept)
+}
+
+// List returns the names of the connections, sorted
+func List() ([]string, error) {
+	data, err := keyring.Get(indexAccount)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if err := json.Unmarshal([]byte(data), &names); err != nil {
+		return nil, fmt.Errorf("failed to decode connection names: %w", err)
+	}
+	return names, nil
+}
+
+// saveIndex stores the names of the connections
+func saveIndex(names []string) error {
+	sort.Strings(names)
+	data, err := json.Marshal(names)
+	if err != nil {
+		return fmt.Errorf("failed to encode connection names: %w", err)
+	}
+	return keyring.Set(indexAccount, string(data))
+}
+
+// Expand rewrites a source naming a connection, e.g. mydb://orders, into
+// the URL of the connection followed by the rest of the source:
+// postgres://{{keyring:connection:mydb#user}}:{{keyring:connection:mydb#password}}@db:5432/shop/orders.
+// The credentials are left as secret placeholders, resolved when connecting.
+// Other sources are returned unchanged.
+func Expand(source string) (string, error) {
+	name, rest, ok := strings.Cut(source, "://")
+	if !ok || ValidateName(name) != nil {
+		return source, nil
+	}
+	c, err := Load(name)
+	if errors.Is(err, ErrNotFound) {
+		return source, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL of connection %s: %w", name, err)
+	}
+	restPath, restQuery, _ := strings.Cut(rest, "?")
+
+	var expanded strings.Builder
+	expanded.WriteString(u.Scheme + "://")
+	if c.User != "" {
+		expanded.WriteString("{{keyring:" + account(name) + "#user}}")
+		if c.Password != "" {
+			expanded.WriteString(":{{keyring:" + account(name) + "#password}}")
+		}
+		expanded.WriteString("@")
+	}
+	expanded.WriteString(u.Host + strings.TrimSuffix(u.EscapedPath(), "/"))
+	if restPath != "" {
+		expanded.WriteString("/" + strings.TrimPrefix(restPath, "/"))
+	}
+	if query := strings.Trim(u.RawQuery+"&"+restQuery, "&"); query != "" {
+		expanded.WriteString("?" + query)
+	}
+	return expanded.String(), nil
+}
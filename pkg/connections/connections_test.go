@@ -0,0 +1,83 @@
+package connections
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/keyring"
+	"github.com/adrianolaselva/dataql/pkg/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveListRemove(t *testing.T) {
+	keyring.MockInit()
+
+	names, err := List()
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	require.NoError(t, Save(Connection{Name: "warehouse", URL: "postgres://dw:5432/analytics", User: "reader", Password: "pw"}))
+	require.NoError(t, Save(Connection{Name: "app", URL: "mysql://db:3306/shop", User: "app", Password: "pw"}))
+	require.NoError(t, Save(Connection{Name: "app", URL: "mysql://db2:3306/shop", User: "app", Password: "new"}))
+
+	names, err = List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "warehouse"}, names)
+
+	conn, err := Load("app")
+	require.NoError(t, err)
+	assert.Equal(t, &Connection{Name: "app", URL: "mysql://db2:3306/shop", User: "app", Password: "new"}, conn)
+
+	require.NoError(t, Remove("app"))
+	_, err = Load("app")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, Remove("app"), ErrNotFound)
+	names, err = List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"warehouse"}, names)
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"mydb", "prod-pg", "dw2"} {
+		assert.NoError(t, ValidateName(name), name)
+	}
+	for _, name := range []string{"", "MyDB", "2db", "my_db", "postgres", "kafka", "s3", "https"} {
+		assert.Error(t, ValidateName(name), name)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	_, err := ValidateURL("postgres://reader@db:5432/shop")
+	assert.NoError(t, err)
+
+	for _, rawURL := range []string{"postgres://reader:secret@db:5432/shop", "https://example.com/data.csv", "db:5432"} {
+		_, err := ValidateURL(rawURL)
+		assert.Error(t, err, rawURL)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	keyring.MockInit()
+	require.NoError(t, Save(Connection{Name: "mydb", URL: "postgres://db:5432/shop?sslmode=require", User: "reader", Password: "p@ss/word"}))
+
+	expanded, err := Expand("mydb://orders")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://{{keyring:connection:mydb#user}}:{{keyring:connection:mydb#password}}@db:5432/shop/orders?sslmode=require", expanded)
+
+	resolved, err := secrets.ResolveURL(context.Background(), expanded)
+	require.NoError(t, err)
+	u, err := url.Parse(resolved)
+	require.NoError(t, err)
+	password, _ := u.User.Password()
+	assert.Equal(t, "reader", u.User.Username())
+	assert.Equal(t, "p@ss/word", password)
+	assert.Equal(t, "/shop/orders", u.Path)
+
+	for _, source := range []string{"data/orders.csv", "postgres://u:p@db/shop/orders", "other://orders"} {
+		unchanged, err := Expand(source)
+		require.NoError(t, err)
+		assert.Equal(t, source, unchanged)
+	}
+}
@@ -0,0 +1,324 @@
+// Package datadiff compares two tables of a dataql session, such as two
+// datasets of any supported format imported side by side. Rows are matched
+// by key columns and reported as added, removed or modified, with the
+// number of changes per column.
+package datadiff
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// Kinds of change in the changes listing
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// Rows are the rows of a query, as returned by database/sql
+type Rows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	Close() error
+}
+
+// Querier runs the queries of a comparison on the session holding both tables
+type Querier interface {
+	Query(ctx context.Context, query string) (Rows, error)
+}
+
+// Column is a column of a compared table
+type Column struct {
+	Name string
+	Type string
+}
+
+// Table is a compared table. Name is used as is in the queries.
+type Table struct {
+	Name    string
+	Columns []Column
+}
+
+// Diff compares a left (old) table with a right (new) one
+type Diff struct {
+	q         Querier
+	left      string
+	right     string
+	keys      []string
+	columns   []string        // Non-key columns present on both sides
+	retyped   map[string]bool // Columns whose type differs between the sides
+	leftOnly  []string
+	rightOnly []string
+}
+
+// ColumnChange is the number of matched rows whose value of Column changed
+type ColumnChange struct {
+	Column  string
+	Changed int64
+}
+
+// Summary counts the differences between the datasets
+type Summary struct {
+	LeftRows           int64
+	RightRows          int64
+	Added              int64 // Rows only in the right dataset
+	Removed            int64 // Rows only in the left dataset
+	Modified           int64 // Matched rows with at least one changed column
+	Unchanged          int64
+	Columns            []ColumnChange // Changes per common column, in column order
+	LeftOnlyColumns    []string
+	RightOnlyColumns   []string
+	LeftDuplicateKeys  int64 // Key values appearing more than once on the left
+	RightDuplicateKeys int64
+}
+
+// New compares the left table with the right one through q, matching rows
+// by the keys columns (whole rows without keys). The key columns must exist
+// on both sides.
+func New(q Querier, left, right Table, keys []string) (*Diff, error) {
+	d := &Diff{q: q, left: left.Name, right: right.Name, retyped: make(map[string]bool)}
+	if err := d.resolveColumns(left.Columns, right.Columns, keys); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// resolveColumns splits the columns into keys, common columns and columns
+// present on one side only
+func (d *Diff) resolveColumns(leftColumns, rightColumns []Column, keys []string) error {
+	rightTypes := make(map[string]string, len(rightColumns))
+	for _, column := range rightColumns {
+		rightTypes[strings.ToLower(column.Name)] = column.Type
+	}
+	leftTypes := make(map[string]string, len(leftColumns))
+	for _, column := range leftColumns {
+		leftTypes[strings.ToLower(column.Name)] = column.Type
+	}
+
+	isKey := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		name := strings.ToLower(key)
+		if _, ok := leftTypes[name]; !ok {
+			return fmt.Errorf("key column %q not found in the first dataset", key)
+		}
+		if _, ok := rightTypes[name]; !ok {
+			return fmt.Errorf("key column %q not found in the second dataset", key)
+		}
+		isKey[name] = true
+		d.keys = append(d.keys, key)
+		d.retyped[name] = leftTypes[name] != rightTypes[name]
+	}
+
+	for _, column := range leftColumns {
+		name := strings.ToLower(column.Name)
+		rightType, ok := rightTypes[name]
+		switch {
+		case !ok:
+			d.leftOnly = append(d.leftOnly, column.Name)
+		case !isKey[name]:
+			d.columns = append(d.columns, column.Name)
+			d.retyped[name] = column.Type != rightType
+		}
+	}
+	for _, column := range rightColumns {
+		if _, ok := leftTypes[strings.ToLower(column.Name)]; !ok {
+			d.rightOnly = append(d.rightOnly, column.Name)
+		}
+	}
+
+	if len(d.columns) == 0 && len(d.keys) == 0 {
+		return fmt.Errorf("the datasets have no columns in common")
+	}
+	return nil
+}
+
+// Summary counts the added, removed, modified and unchanged rows and the
+// changes of each column
+func (d *Diff) Summary(ctx context.Context) (*Summary, error) {
+	s := &Summary{LeftOnlyColumns: d.leftOnly, RightOnlyColumns: d.rightOnly}
+
+	type count struct {
+		target *int64
+		query  string
+	}
+	counts := []count{
+		{&s.LeftRows, "SELECT COUNT(*) FROM " + d.left},
+		{&s.RightRows, "SELECT COUNT(*) FROM " + d.right},
+		{&s.Added, "SELECT COUNT(*) FROM (" + d.addedQuery() + ")"},
+		{&s.Removed, "SELECT COUNT(*) FROM (" + d.removedQuery() + ")"},
+	}
+	if len(d.keys) > 0 {
+		counts = append(counts,
+			count{&s.LeftDuplicateKeys, d.duplicateKeysQuery(d.left)},
+			count{&s.RightDuplicateKeys, d.duplicateKeysQuery(d.right)},
+		)
+	}
+
+	for _, count := range counts {
+		if err := d.queryRow(ctx, count.query, count.target); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(d.keys) == 0 {
+		s.Unchanged = s.LeftRows - s.Removed
+		return s, nil
+	}
+
+	// Matched rows, rows with any change and changes per column in one scan
+	selects := []string{"COUNT(*)", "COUNT(*) FILTER (WHERE " + d.anyChanged() + ")"}
+	for _, column := range d.columns {
+		selects = append(selects, "COUNT(*) FILTER (WHERE "+d.changed(column)+")")
+	}
+	var matched int64
+	s.Columns = make([]ColumnChange, len(d.columns))
+	dest := []any{&matched, &s.Modified}
+	for i, column := range d.columns {
+		s.Columns[i].Column = column
+		dest = append(dest, &s.Columns[i].Changed)
+	}
+	if err := d.queryRow(ctx, "SELECT "+strings.Join(selects, ", ")+" FROM "+d.matchedFrom(), dest...); err != nil {
+		return nil, err
+	}
+	s.Unchanged = matched - s.Modified
+	return s, nil
+}
+
+// ChangesQuery returns a query listing one row per change: the kind of
+// change, the key columns, the changed column and its old and new values.
+// Added and removed rows have no column and carry the whole row as JSON in
+// new_value or old_value.
+func (d *Diff) ChangesQuery() string {
+	keys := make([]string, len(d.keys))
+	for i, key := range d.keys {
+		keys[i] = storage.QuoteIdentifier(key)
+	}
+	keyList := ""
+	if len(keys) > 0 {
+		keyList = strings.Join(keys, ", ") + ", "
+	}
+
+	parts := []string{
+		fmt.Sprintf(`SELECT '%s' AS "change", %sNULL::VARCHAR AS "column", NULL::VARCHAR AS old_value, to_json(r)::VARCHAR AS new_value FROM (%s) AS r`,
+			ChangeAdded, keyList, d.addedQuery()),
+		fmt.Sprintf(`SELECT '%s' AS "change", %sNULL::VARCHAR AS "column", to_json(l)::VARCHAR AS old_value, NULL::VARCHAR AS new_value FROM (%s) AS l`,
+			ChangeRemoved, keyList, d.removedQuery()),
+	}
+
+	if len(d.keys) > 0 {
+		leftKeys := make([]string, len(d.keys))
+		for i, key := range d.keys {
+			leftKeys[i] = "l." + storage.QuoteIdentifier(key)
+		}
+		for _, column := range d.columns {
+			parts = append(parts, fmt.Sprintf(`SELECT '%s', %s, '%s', CAST(l.%s AS VARCHAR), CAST(r.%s AS VARCHAR) FROM %s WHERE %s`,
+				ChangeModified, strings.Join(leftKeys, ", "), strings.ReplaceAll(column, "'", "''"),
+				storage.QuoteIdentifier(column), storage.QuoteIdentifier(column), d.matchedFrom(), d.changed(column)))
+		}
+	}
+
+	order := `"change", "column"`
+	if len(keys) > 0 {
+		order = strings.Join(keys, ", ") + ", " + order
+	}
+	return "SELECT * FROM (" + strings.Join(parts, " UNION ALL ") + ") AS changes ORDER BY " + order
+}
+
+// addedQuery selects the rows of the right dataset missing on the left
+func (d *Diff) addedQuery() string {
+	if len(d.keys) == 0 {
+		return fmt.Sprintf("SELECT %s FROM %s EXCEPT ALL SELECT %s FROM %s", d.columnList(), d.right, d.columnList(), d.left)
+	}
+	return fmt.Sprintf("SELECT * FROM %s AS r WHERE NOT EXISTS (SELECT 1 FROM %s AS l WHERE %s)", d.right, d.left, d.keyMatch())
+}
+
+// removedQuery selects the rows of the left dataset missing on the right
+func (d *Diff) removedQuery() string {
+	if len(d.keys) == 0 {
+		return fmt.Sprintf("SELECT %s FROM %s EXCEPT ALL SELECT %s FROM %s", d.columnList(), d.left, d.columnList(), d.right)
+	}
+	return fmt.Sprintf("SELECT * FROM %s AS l WHERE NOT EXISTS (SELECT 1 FROM %s AS r WHERE %s)", d.left, d.right, d.keyMatch())
+}
+
+// duplicateKeysQuery counts the key values appearing more than once in table
+func (d *Diff) duplicateKeysQuery(table string) string {
+	keys := make([]string, len(d.keys))
+	for i, key := range d.keys {
+		keys[i] = storage.QuoteIdentifier(key)
+	}
+	list := strings.Join(keys, ", ")
+	return fmt.Sprintf("SELECT COUNT(*) FROM (SELECT %s FROM %s GROUP BY %s HAVING COUNT(*) > 1)", list, table, list)
+}
+
+// matchedFrom joins the rows of both datasets with the same key
+func (d *Diff) matchedFrom() string {
+	return fmt.Sprintf("%s AS l JOIN %s AS r ON %s", d.left, d.right, d.keyMatch())
+}
+
+func (d *Diff) keyMatch() string {
+	conditions := make([]string, len(d.keys))
+	for i, key := range d.keys {
+		conditions[i] = d.side("l", key) + " = " + d.side("r", key)
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// changed is true when a common column differs between the matched rows
+func (d *Diff) changed(column string) string {
+	return d.side("l", column) + " IS DISTINCT FROM " + d.side("r", column)
+}
+
+func (d *Diff) anyChanged() string {
+	if len(d.columns) == 0 {
+		return "false"
+	}
+	conditions := make([]string, len(d.columns))
+	for i, column := range d.columns {
+		conditions[i] = d.changed(column)
+	}
+	return strings.Join(conditions, " OR ")
+}
+
+// side references a column of one side, compared as text when the column
+// has a different type on each side (e.g. BIGINT in a CSV, INTEGER in Parquet)
+func (d *Diff) side(alias, column string) string {
+	ref := alias + "." + storage.QuoteIdentifier(column)
+	if d.retyped[strings.ToLower(column)] {
+		return "CAST(" + ref + " AS VARCHAR)"
+	}
+	return ref
+}
+
+// columnList lists the common columns for whole-row comparisons
+func (d *Diff) columnList() string {
+	columns := make([]string, len(d.columns))
+	for i, column := range d.columns {
+		columns[i] = storage.QuoteIdentifier(column)
+		if d.retyped[strings.ToLower(column)] {
+			columns[i] = "CAST(" + columns[i] + " AS VARCHAR) AS " + columns[i]
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+// queryRow scans the first row of query into dest
+func (d *Diff) queryRow(ctx context.Context, query string, dest ...any) error {
+	rows, err := d.q.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("query returned no rows")
+	}
+	return rows.Scan(dest...)
+}
@@ -0,0 +1,146 @@
+package datadiff_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/datadiff"
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeDatasets writes an old and a new version of a dataset with the same
+// file name in different directories
+func writeDatasets(t *testing.T, old, new string) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old", "users.csv")
+	newPath := filepath.Join(dir, "new", "users.csv")
+	for path, content := range map[string]string{oldPath: old, newPath: new} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return oldPath, newPath
+}
+
+// openDiff imports both datasets and compares them
+func openDiff(t *testing.T, oldPath, newPath string, keys []string) (*dataql.DB, *datadiff.Diff, error) {
+	t.Helper()
+
+	db, err := dataql.Open(oldPath+":old", newPath+":new")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	d, err := db.Diff("old", "new", keys)
+	return db, d, err
+}
+
+func TestSummary_WithKey(t *testing.T) {
+	oldPath, newPath := writeDatasets(t,
+		"id,name,city,legacy\n1,Alice,Paris,x\n2,Bob,Rome,y\n3,Carol,Oslo,z\n",
+		"id,name,city,email\n1,Alice,Lyon,a@x\n2,Bobby,Milan,b@x\n4,Dan,Kyiv,d@x\n",
+	)
+
+	db, d, err := openDiff(t, oldPath, newPath, []string{"id"})
+	require.NoError(t, err)
+
+	s, err := d.Summary(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), s.LeftRows)
+	assert.Equal(t, int64(3), s.RightRows)
+	assert.Equal(t, int64(1), s.Added)
+	assert.Equal(t, int64(1), s.Removed)
+	assert.Equal(t, int64(2), s.Modified)
+	assert.Equal(t, int64(0), s.Unchanged)
+	assert.Equal(t, []datadiff.ColumnChange{{"name", 1}, {"city", 2}}, s.Columns)
+	assert.Equal(t, []string{"legacy"}, s.LeftOnlyColumns)
+	assert.Equal(t, []string{"email"}, s.RightOnlyColumns)
+
+	rows, err := db.Query(context.Background(), d.ChangesQuery())
+	require.NoError(t, err)
+	defer rows.Close()
+	assert.Equal(t, []string{"change", "id", "column", "old_value", "new_value"}, rows.Columns())
+
+	var changes []string
+	for rows.Next() {
+		values, err := rows.Values()
+		require.NoError(t, err)
+		changes = append(changes, strings.TrimSuffix(fmt.Sprintln(values...), "\n"))
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{
+		"modified 1 city Paris Lyon",
+		"modified 2 city Rome Milan",
+		"modified 2 name Bob Bobby",
+		"removed 3 <nil> {\"id\":3,\"name\":\"Carol\",\"city\":\"Oslo\",\"legacy\":\"z\"} <nil>",
+		"added 4 <nil> <nil> {\"id\":4,\"name\":\"Dan\",\"city\":\"Kyiv\",\"email\":\"d@x\"}",
+	}, changes)
+}
+
+func TestSummary_WithoutKey(t *testing.T) {
+	oldPath, newPath := writeDatasets(t,
+		"id,name\n1,Alice\n2,Bob\n2,Bob\n",
+		"id,name\n1,Alice\n2,Bob\n3,Carol\n",
+	)
+
+	_, d, err := openDiff(t, oldPath, newPath, nil)
+	require.NoError(t, err)
+
+	s, err := d.Summary(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), s.Added)
+	assert.Equal(t, int64(1), s.Removed) // The duplicate Bob
+	assert.Equal(t, int64(2), s.Unchanged)
+	assert.Empty(t, s.Columns)
+}
+
+func TestSummary_DuplicateKeysAndTypes(t *testing.T) {
+	oldPath, newPath := writeDatasets(t,
+		"id,amount\n1,10\n1,11\n2,20\n",
+		"id,amount\n1,ten\n2,20\n",
+	)
+
+	_, d, err := openDiff(t, oldPath, newPath, []string{"id"})
+	require.NoError(t, err)
+
+	s, err := d.Summary(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), s.LeftDuplicateKeys)
+	assert.Equal(t, int64(0), s.RightDuplicateKeys)
+	// amount is a number on the left and text on the right: compared as text
+	assert.Equal(t, []datadiff.ColumnChange{{"amount", 2}}, s.Columns)
+}
+
+func TestExport(t *testing.T) {
+	oldPath, newPath := writeDatasets(t, "id,name\n1,Alice\n", "id,name\n1,Alicia\n")
+
+	db, d, err := openDiff(t, oldPath, newPath, []string{"id"})
+	require.NoError(t, err)
+
+	out := filepath.Join(t.TempDir(), "diff.csv")
+	_, err = db.Export(context.Background(), d.ChangesQuery(), out, "")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "change,id,column,old_value,new_value\nmodified,1,name,Alice,Alicia\n", string(data))
+}
+
+func TestNew_Errors(t *testing.T) {
+	oldPath, newPath := writeDatasets(t, "id,name\n1,a\n", "code,name\n1,a\n")
+
+	db, _, err := openDiff(t, oldPath, newPath, []string{"id"})
+	assert.ErrorContains(t, err, `key column "id" not found in the second dataset`)
+
+	_, err = db.Diff("old", "missing", nil)
+	assert.ErrorContains(t, err, "table missing not found")
+
+	_, err = datadiff.New(nil, datadiff.Table{Name: "a", Columns: []datadiff.Column{{Name: "x"}}}, datadiff.Table{Name: "b", Columns: []datadiff.Column{{Name: "y"}}}, nil)
+	assert.ErrorContains(t, err, "no columns in common")
+}
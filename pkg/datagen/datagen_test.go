@@ -0,0 +1,164 @@
+package datagen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openCSV imports a CSV file and returns the database and its only table
+func openCSV(t *testing.T, content string) (*dataql.DB, dataql.Table) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "orders.csv")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	db, err := dataql.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	tables, err := db.Tables()
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+	return db, tables[0]
+}
+
+// generate runs the query of spec and returns its rows as text
+func generate(t *testing.T, db *dataql.DB, spec *Spec, rows, seed int64) [][]string {
+	t.Helper()
+
+	query, err := spec.Query(rows, seed)
+	require.NoError(t, err)
+	result, err := db.Query(context.Background(), query)
+	require.NoError(t, err)
+	defer result.Close()
+
+	var out [][]string
+	for result.Next() {
+		values, err := result.Values()
+		require.NoError(t, err)
+		row := make([]string, len(values))
+		for i, value := range values {
+			if value != nil {
+				row[i] = fmt.Sprint(value)
+			}
+		}
+		out = append(out, row)
+	}
+	require.NoError(t, result.Err())
+	return out
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`rows: 10
+columns:
+  - {name: id, type: sequence, min: 100}
+  - {name: country, type: choice, values: [BR, US], weights: [3, 1]}
+`), 0644))
+
+	spec, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), spec.Rows)
+	assert.Equal(t, Column{Name: "id", Type: TypeSequence, Min: "100"}, spec.Columns[0])
+	assert.Equal(t, []float64{3, 1}, spec.Columns[1].Weights)
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]Column{
+		"unknown type":         {Name: "a", Type: "money"},
+		"no type":              {Name: "a"},
+		"no name":              {Type: TypeName},
+		"invalid integer":      {Name: "a", Type: TypeInteger, Min: "ten"},
+		"min above max":        {Name: "a", Type: TypeFloat, Min: "2", Max: "1"},
+		"choice without value": {Name: "a", Type: TypeChoice},
+		"weights mismatch":     {Name: "a", Type: TypeChoice, Values: []string{"x"}, Weights: []float64{1, 2}},
+		"invalid date":         {Name: "a", Type: TypeDate, Min: "2024-13-01"},
+		"invalid null rate":    {Name: "a", Type: TypeUUID, NullRate: 2},
+	}
+	for name, column := range tests {
+		spec := Spec{Columns: []Column{column}}
+		assert.Error(t, spec.Validate(), name)
+	}
+
+	spec := Spec{Columns: []Column{{Name: "a", Type: TypeUUID}, {Name: "A", Type: TypeUUID}}}
+	assert.Error(t, spec.Validate(), "duplicate column")
+}
+
+func TestQuery(t *testing.T) {
+	db, _ := openCSV(t, "id\n1\n")
+	spec := &Spec{Columns: []Column{
+		{Name: "id", Type: TypeSequence},
+		{Name: "age", Type: TypeInteger, Min: "18", Max: "20"},
+		{Name: "score", Type: TypeFloat, Min: "-1", Max: "1"},
+		{Name: "country", Type: TypeChoice, Values: []string{"BR", "US"}, Weights: []float64{1, 0}},
+		{Name: "code", Type: TypeString, Length: 40},
+		{Name: "email", Type: TypeEmail},
+		{Name: "uid", Type: TypeUUID},
+		{Name: "day", Type: TypeDate, Min: "2024-02-28", Max: "2024-03-01"},
+		{Name: "at", Type: TypeTimestamp, Min: "2024-01-01", Max: "2024-01-01 00:00:10"},
+		{Name: "gone", Type: TypeName, NullRate: 1},
+	}}
+
+	rows := generate(t, db, spec, 200, 7)
+	require.Len(t, rows, 200)
+	for i, row := range rows {
+		assert.Equal(t, strconv.Itoa(i+1), row[0])
+		age, err := strconv.Atoi(row[1])
+		require.NoError(t, err)
+		assert.True(t, age >= 18 && age <= 20, row[1])
+		score, err := strconv.ParseFloat(row[2], 64)
+		require.NoError(t, err)
+		assert.True(t, score >= -1 && score <= 1, row[2])
+		assert.Equal(t, "BR", row[3])
+		assert.Len(t, row[4], 40)
+		assert.True(t, strings.HasSuffix(row[5], "@example.com"), row[5])
+		assert.Len(t, row[6], 36)
+		assert.Contains(t, []string{"2024-02-28", "2024-02-29", "2024-03-01"}, row[7])
+		assert.True(t, row[8] >= "2024-01-01 00:00:00" && row[8] <= "2024-01-01 00:00:10", row[8])
+		assert.Empty(t, row[9])
+	}
+
+	assert.Equal(t, rows, generate(t, db, spec, 200, 7), "the same seed gives the same rows")
+	assert.NotEqual(t, rows, generate(t, db, spec, 200, 8))
+}
+
+func TestProfile(t *testing.T) {
+	db, table := openCSV(t, `id,customer_name,email,status,amount,qty,created,paid,note
+1,Ana,ana@x.com,paid,10.5,1,2024-01-02,true,first order
+2,Bob,bob@y.org,paid,20.25,3,2024-03-05,false,
+3,Cid,cid@z.net,pending,5,2,2024-02-01,true,call before delivery
+4,Dan,dan@w.io,paid,7.75,1,2024-01-20,true,
+`)
+
+	spec, err := Profile(context.Background(), db, table)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), spec.Rows)
+
+	byName := make(map[string]Column)
+	for _, column := range spec.Columns {
+		byName[column.Name] = column
+	}
+	assert.Equal(t, Column{Name: "id", Type: TypeSequence, Min: "1"}, byName["id"])
+	assert.Equal(t, TypeName, byName["customer_name"].Type)
+	assert.Equal(t, TypeEmail, byName["email"].Type)
+	assert.Equal(t, Column{Name: "status", Type: TypeChoice, Values: []string{"paid", "pending"}, Weights: []float64{3, 1}}, byName["status"])
+	assert.Equal(t, TypeFloat, byName["amount"].Type)
+	assert.Equal(t, []string{"5", "20.25"}, []string{byName["amount"].Min, byName["amount"].Max})
+	assert.Equal(t, Column{Name: "qty", Type: TypeInteger, Min: "1", Max: "3"}, byName["qty"])
+	assert.Equal(t, Column{Name: "created", Type: TypeDate, Min: "2024-01-02", Max: "2024-03-05"}, byName["created"])
+	assert.Equal(t, TypeBoolean, byName["paid"].Type)
+	assert.Equal(t, 0.75, *byName["paid"].TrueRate)
+	assert.Equal(t, TypeString, byName["note"].Type)
+	assert.Equal(t, 0.5, byName["note"].NullRate)
+
+	require.NoError(t, spec.Validate())
+	assert.Len(t, generate(t, db, spec, 10, 1), 10)
+}
@@ -0,0 +1,171 @@
+package datagen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// uniformScale maps a 64-bit hash to [0, 1) with the 53 bits a DOUBLE holds
+const uniformScale = "9007199254740992"
+
+var (
+	firstNames = []string{"Ana", "Bruno", "Carla", "Daniel", "Elena", "Felipe", "Grace", "Hugo", "Isabel", "Jonas",
+		"Karen", "Lucas", "Maria", "Nina", "Oscar", "Paula", "Rafael", "Sofia", "Tiago", "Vera"}
+	lastNames = []string{"Silva", "Smith", "Santos", "Johnson", "Oliveira", "Brown", "Costa", "Miller", "Pereira", "Garcia",
+		"Souza", "Davis", "Lima", "Wilson", "Ferreira", "Moore", "Almeida", "Taylor", "Ribeiro", "Martin"}
+)
+
+// Query returns a DuckDB query producing rows rows of the spec. The same
+// seed gives the same rows, whatever the number of threads: values are
+// derived from a hash of the row number, the seed and the column instead
+// of random(). Dates and timestamps are text (2006-01-02 and
+// 2006-01-02 15:04:05), as when read from CSV.
+func (s *Spec) Query(rows int64, seed int64) (string, error) {
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+	if rows < 0 {
+		return "", fmt.Errorf("rows must not be negative")
+	}
+
+	selects := make([]string, len(s.Columns))
+	for i, column := range s.Columns {
+		g := generator{seed: seed, column: i}
+		expr, err := g.expression(column)
+		if err != nil {
+			return "", fmt.Errorf("column %s: %w", column.Name, err)
+		}
+		if column.NullRate > 0 {
+			expr = fmt.Sprintf("CASE WHEN %s < %s THEN NULL ELSE %s END", g.uniform(0), formatFloat(column.NullRate), expr)
+		}
+		selects[i] = expr + " AS " + storage.QuoteIdentifier(column.Name)
+	}
+
+	return fmt.Sprintf("SELECT %s FROM range(%d) AS g(i) ORDER BY i", strings.Join(selects, ", "), rows), nil
+}
+
+// generator builds the expressions of one column
+type generator struct {
+	seed   int64
+	column int
+}
+
+// uniform returns an expression with a number in [0, 1) per row. Each
+// draw of a column is independent.
+func (g generator) uniform(draw int) string {
+	return fmt.Sprintf("(hash(g.i, %d, %d) %% %s) / %s.0", g.seed, g.column*8+draw, uniformScale, uniformScale)
+}
+
+// pick returns an expression choosing one of the values evenly
+func (g generator) pick(values []string, draw int) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quoteLiteral(value)
+	}
+	return fmt.Sprintf("[%s][1 + CAST(floor(%s * %d) AS INTEGER)]", strings.Join(quoted, ", "), g.uniform(draw), len(values))
+}
+
+// digest returns a hexadecimal MD5 per row
+func (g generator) digest(draw int) string {
+	return fmt.Sprintf("md5(CAST(hash(g.i, %d, %d) AS VARCHAR))", g.seed, g.column*8+draw)
+}
+
+func (g generator) expression(c Column) (string, error) {
+	switch c.Type {
+	case TypeSequence:
+		bounds, err := c.intBounds(1, 1)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("CAST(g.i + %d AS BIGINT)", bounds[0]), nil
+
+	case TypeInteger:
+		bounds, err := c.intBounds(0, defaultIntegerMax)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("CAST(%d + floor(%s * (%d::HUGEINT - %d + 1)) AS BIGINT)", bounds[0], g.uniform(1), bounds[1], bounds[0]), nil
+
+	case TypeFloat:
+		bounds, err := c.floatBounds()
+		if err != nil {
+			return "", err
+		}
+		decimals := defaultDecimals
+		if c.Decimals != nil {
+			decimals = *c.Decimals
+		}
+		return fmt.Sprintf("round(%s + %s * (%s - %s), %d)", formatFloat(bounds[0]), g.uniform(1), formatFloat(bounds[1]), formatFloat(bounds[0]), decimals), nil
+
+	case TypeBoolean:
+		rate := 0.5
+		if c.TrueRate != nil {
+			rate = *c.TrueRate
+		}
+		return fmt.Sprintf("(%s < %s)", g.uniform(1), formatFloat(rate)), nil
+
+	case TypeChoice:
+		if len(c.Weights) == 0 {
+			return g.pick(c.Values, 1), nil
+		}
+		total := 0.0
+		for _, weight := range c.Weights {
+			total += weight
+		}
+		var b strings.Builder
+		b.WriteString("CASE")
+		cumulative := 0.0
+		for i, value := range c.Values[:len(c.Values)-1] {
+			cumulative += c.Weights[i]
+			fmt.Fprintf(&b, " WHEN %s < %s THEN %s", g.uniform(1), formatFloat(cumulative/total), quoteLiteral(value))
+		}
+		fmt.Fprintf(&b, " ELSE %s END", quoteLiteral(c.Values[len(c.Values)-1]))
+		return b.String(), nil
+
+	case TypeString:
+		length := c.Length
+		if length == 0 {
+			length = defaultStringLength
+		}
+		return fmt.Sprintf("left(repeat(%s, %d), %d)", g.digest(1), (length+31)/32, length), nil
+
+	case TypeName:
+		return g.pick(firstNames, 1) + " || ' ' || " + g.pick(lastNames, 2), nil
+
+	case TypeEmail:
+		return fmt.Sprintf("lower(%s || '.' || %s) || CAST(CAST(floor(%s * 1000) AS INTEGER) AS VARCHAR) || '@example.com'",
+			g.pick(firstNames, 1), g.pick(lastNames, 2), g.uniform(3)), nil
+
+	case TypeUUID:
+		d := g.digest(1)
+		return fmt.Sprintf("CAST(substr(%[1]s, 1, 8) || '-' || substr(%[1]s, 9, 4) || '-4' || substr(%[1]s, 14, 3) || '-a' || substr(%[1]s, 18, 3) || '-' || substr(%[1]s, 21, 12) AS UUID)", d), nil
+
+	case TypeDate:
+		bounds, err := c.timeBounds(dateLayout)
+		if err != nil {
+			return "", err
+		}
+		days := int64(bounds[1].Sub(bounds[0]).Hours() / 24)
+		return fmt.Sprintf("strftime(DATE '%s' + CAST(floor(%s * %d) AS INTEGER), '%%Y-%%m-%%d')", bounds[0].Format(dateLayout), g.uniform(1), days+1), nil
+
+	case TypeTimestamp:
+		bounds, err := c.timeBounds(timestampLayout)
+		if err != nil {
+			return "", err
+		}
+		seconds := int64(bounds[1].Sub(bounds[0]).Seconds())
+		return fmt.Sprintf("strftime(TIMESTAMP '%s' + to_seconds(CAST(floor(%s * %d) AS BIGINT)), '%%Y-%%m-%%d %%H:%%M:%%S')", bounds[0].Format(timestampLayout), g.uniform(1), seconds+1), nil
+	}
+	return "", fmt.Errorf("unknown type %q", c.Type)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
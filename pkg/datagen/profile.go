@@ -0,0 +1,269 @@
+package datagen
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// personName matches the names of columns holding person names
+var personName = regexp.MustCompile(`(^|[^a-z])(first_?|last_?|full_?|customer_?|user_?|contact_?)?name$|^(customer|person|author|employee)$`)
+
+const (
+	// maxChoices is the largest number of distinct values of a text column
+	// reproduced as a choice
+	maxChoices = 50
+	// patternShare is the share of values matching a pattern (e-mail, UUID,
+	// date) for a text column to be generated as such
+	patternShare = 0.9
+)
+
+// Profile returns a spec mimicking a table: the types of its columns, the
+// ranges of numbers and dates, the frequencies of categories, the shape of
+// text (e-mail, UUID, length) and the null rates. Columns are profiled from
+// all rows; the default row count is the row count of the table.
+func Profile(ctx context.Context, db *dataql.DB, table dataql.Table) (*Spec, error) {
+	total, err := queryInt(ctx, db, "SELECT COUNT(*) FROM "+storage.QuoteIdentifier(table.Name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count %s: %w", table.Name, err)
+	}
+
+	spec := &Spec{Rows: total}
+	for _, source := range table.Columns {
+		p := profiler{db: db, table: storage.QuoteIdentifier(table.Name), column: storage.QuoteIdentifier(source.Name), total: total}
+		column, err := p.profile(ctx, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to profile %s: %w", source.Name, err)
+		}
+		spec.Columns = append(spec.Columns, column)
+	}
+	return spec, nil
+}
+
+// profiler profiles one column
+type profiler struct {
+	db     *dataql.DB
+	table  string
+	column string
+	total  int64
+}
+
+func (p profiler) profile(ctx context.Context, source dataql.Column) (Column, error) {
+	column := Column{Name: source.Name, Type: TypeString}
+
+	// Empty text counts as null, as empty CSV fields are read as empty strings
+	nonNull, err := queryInt(ctx, p.db, fmt.Sprintf("SELECT COUNT(NULLIF(trim(CAST(%s AS VARCHAR)), '')) FROM %s", p.column, p.table))
+	if err != nil {
+		return column, err
+	}
+	if p.total > 0 {
+		column.NullRate = roundRate(float64(p.total-nonNull) / float64(p.total))
+	}
+	if nonNull == 0 {
+		column.Length = 1
+		column.NullRate = 1
+		return column, nil
+	}
+
+	typ := strings.ToUpper(source.Type)
+	switch {
+	case isIntegerType(typ):
+		err = p.integer(ctx, &column, nonNull)
+	case typ == "FLOAT" || typ == "DOUBLE" || typ == "REAL" || typ == "UBIGINT" || typ == "HUGEINT" || strings.HasPrefix(typ, "DECIMAL"):
+		err = p.float(ctx, &column, typ)
+	case typ == "BOOLEAN":
+		err = p.boolean(ctx, &column)
+	case typ == "DATE":
+		column.Type = TypeDate
+		err = p.bounds(ctx, &column, p.column)
+	case strings.HasPrefix(typ, "TIMESTAMP"):
+		column.Type = TypeTimestamp
+		err = p.bounds(ctx, &column, formatTimestamp(p.column))
+	case typ == "UUID":
+		column.Type = TypeUUID
+	default:
+		err = p.text(ctx, &column, nonNull)
+	}
+	return column, err
+}
+
+func (p profiler) integer(ctx context.Context, column *Column, nonNull int64) error {
+	var minimum, maximum, distinct int64
+	query := fmt.Sprintf("SELECT MIN(%[1]s), MAX(%[1]s), COUNT(DISTINCT %[1]s) FROM %[2]s", p.column, p.table)
+	if err := queryRow(ctx, p.db, query, &minimum, &maximum, &distinct); err != nil {
+		return err
+	}
+
+	column.Min = strconv.FormatInt(minimum, 10)
+	if distinct == nonNull && column.NullRate == 0 && maximum-minimum+1 == nonNull {
+		column.Type = TypeSequence
+		return nil
+	}
+	column.Type = TypeInteger
+	column.Max = strconv.FormatInt(maximum, 10)
+	return nil
+}
+
+func (p profiler) float(ctx context.Context, column *Column, typ string) error {
+	var minimum, maximum float64
+	query := fmt.Sprintf("SELECT CAST(MIN(%[1]s) AS DOUBLE), CAST(MAX(%[1]s) AS DOUBLE) FROM %[2]s", p.column, p.table)
+	if err := queryRow(ctx, p.db, query, &minimum, &maximum); err != nil {
+		return err
+	}
+
+	column.Type = TypeFloat
+	column.Min, column.Max = formatFloat(minimum), formatFloat(maximum)
+	if scale := decimalScale(typ); scale >= 0 {
+		column.Decimals = &scale
+	}
+	return nil
+}
+
+func (p profiler) boolean(ctx context.Context, column *Column) error {
+	var rate float64
+	if err := queryRow(ctx, p.db, fmt.Sprintf("SELECT AVG(CAST(%s AS INTEGER)) FROM %s", p.column, p.table), &rate); err != nil {
+		return err
+	}
+	rate = roundRate(rate)
+	column.Type = TypeBoolean
+	column.TrueRate = &rate
+	return nil
+}
+
+// bounds sets min and max from an expression of the column
+func (p profiler) bounds(ctx context.Context, column *Column, value string) error {
+	query := fmt.Sprintf("SELECT CAST(MIN(%[1]s) AS VARCHAR), CAST(MAX(%[1]s) AS VARCHAR) FROM %[2]s", value, p.table)
+	return queryRow(ctx, p.db, query, &column.Min, &column.Max)
+}
+
+// text recognizes e-mail addresses, UUIDs, dates, timestamps and categories
+// in a text column, and otherwise keeps the average length
+func (p profiler) text(ctx context.Context, column *Column, nonNull int64) error {
+	value := fmt.Sprintf("trim(CAST(%s AS VARCHAR))", p.column)
+	var email, uuid, date, timestamp, length float64
+	var distinct int64
+	query := fmt.Sprintf(`SELECT
+  AVG(CASE WHEN regexp_full_match(v, '[^@\s]+@[^@\s]+\.[A-Za-z]{2,}') THEN 1.0 ELSE 0.0 END),
+  AVG(CASE WHEN regexp_full_match(v, '[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}') THEN 1.0 ELSE 0.0 END),
+  AVG(CASE WHEN length(v) = 10 AND TRY_CAST(v AS DATE) IS NOT NULL THEN 1.0 ELSE 0.0 END),
+  AVG(CASE WHEN length(v) > 10 AND TRY_CAST(v AS TIMESTAMP) IS NOT NULL THEN 1.0 ELSE 0.0 END),
+  AVG(length(v)),
+  COUNT(DISTINCT v)
+FROM (SELECT %s AS v FROM %s) WHERE v <> ''`, value, p.table)
+	if err := queryRow(ctx, p.db, query, &email, &uuid, &date, &timestamp, &length, &distinct); err != nil {
+		return err
+	}
+
+	switch {
+	case email >= patternShare:
+		column.Type = TypeEmail
+	case uuid >= patternShare:
+		column.Type = TypeUUID
+	case date >= patternShare:
+		column.Type = TypeDate
+		return p.bounds(ctx, column, fmt.Sprintf("TRY_CAST(%s AS DATE)", value))
+	case timestamp >= patternShare:
+		column.Type = TypeTimestamp
+		return p.bounds(ctx, column, formatTimestamp(fmt.Sprintf("TRY_CAST(%s AS TIMESTAMP)", value)))
+	case distinct <= maxChoices && distinct*2 <= nonNull:
+		return p.choices(ctx, column, value)
+	case personName.MatchString(strings.ToLower(column.Name)):
+		column.Type = TypeName
+	default:
+		column.Type = TypeString
+		column.Length = max(1, min(1000, int(math.Round(length))))
+	}
+	return nil
+}
+
+// choices sets the distinct values and their frequencies
+func (p profiler) choices(ctx context.Context, column *Column, value string) error {
+	rows, err := p.db.Query(ctx, fmt.Sprintf("SELECT %s AS v, COUNT(*) AS n FROM %s WHERE v <> '' GROUP BY v ORDER BY n DESC, v", value, p.table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	column.Type = TypeChoice
+	for rows.Next() {
+		var text string
+		var count int64
+		if err := rows.Scan(&text, &count); err != nil {
+			return err
+		}
+		column.Values = append(column.Values, text)
+		column.Weights = append(column.Weights, float64(count))
+	}
+	return rows.Err()
+}
+
+// decimalScale returns the scale of a DECIMAL(p,s) type, 0 for wide
+// integers, or -1
+func decimalScale(typ string) int {
+	if typ == "UBIGINT" || typ == "HUGEINT" {
+		return 0
+	}
+	open, closing := strings.IndexByte(typ, '('), strings.IndexByte(typ, ')')
+	if !strings.HasPrefix(typ, "DECIMAL") || open < 0 || closing < open {
+		return -1
+	}
+	_, scale, ok := strings.Cut(typ[open+1:closing], ",")
+	if !ok {
+		return -1
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(scale))
+	if err != nil {
+		return -1
+	}
+	return value
+}
+
+func isIntegerType(typ string) bool {
+	switch typ {
+	case "TINYINT", "SMALLINT", "INTEGER", "BIGINT", "UTINYINT", "USMALLINT", "UINTEGER":
+		return true
+	}
+	return false
+}
+
+// formatTimestamp formats a timestamp expression as in specs
+func formatTimestamp(value string) string {
+	return fmt.Sprintf("strftime(%s, '%%Y-%%m-%%d %%H:%%M:%%S')", value)
+}
+
+// roundRate keeps four decimals of a rate
+func roundRate(rate float64) float64 {
+	return math.Round(rate*10000) / 10000
+}
+
+func queryInt(ctx context.Context, db *dataql.DB, query string) (int64, error) {
+	var value int64
+	err := queryRow(ctx, db, query, &value)
+	return value, err
+}
+
+// queryRow scans the first row of query
+func queryRow(ctx context.Context, db *dataql.DB, query string, dest ...any) error {
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("no result")
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	return rows.Err()
+}
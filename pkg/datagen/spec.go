@@ -0,0 +1,241 @@
+// Package datagen generates synthetic rows from a column specification,
+// written by hand or profiled from an existing table so the fake data
+// mimics its types, ranges, categories and null rates.
+package datagen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Column generators
+const (
+	TypeSequence  = "sequence"  // min, min+1, ... (min defaults to 1)
+	TypeInteger   = "integer"   // Uniform integer between min and max
+	TypeFloat     = "float"     // Uniform number between min and max, rounded to decimals
+	TypeBoolean   = "boolean"   // true with probability true_rate (default 0.5)
+	TypeChoice    = "choice"    // One of values, with optional weights
+	TypeString    = "string"    // Random hexadecimal text of length characters
+	TypeName      = "name"      // A person name
+	TypeEmail     = "email"     // An e-mail address at example.com
+	TypeUUID      = "uuid"      // A version 4 style UUID
+	TypeDate      = "date"      // Uniform date between min and max, as YYYY-MM-DD text
+	TypeTimestamp = "timestamp" // Uniform timestamp between min and max, as YYYY-MM-DD HH:MM:SS text
+)
+
+// Defaults of the generators
+const (
+	defaultIntegerMax   = 1000
+	defaultStringLength = 10
+	defaultDecimals     = 2
+	defaultDateMin      = "2020-01-01"
+	defaultDateMax      = "2024-12-31"
+	dateLayout          = "2006-01-02"
+	timestampLayout     = "2006-01-02 15:04:05"
+)
+
+var types = []string{TypeSequence, TypeInteger, TypeFloat, TypeBoolean, TypeChoice, TypeString, TypeName, TypeEmail, TypeUUID, TypeDate, TypeTimestamp}
+
+// Spec describes the rows to generate
+type Spec struct {
+	Rows    int64    `yaml:"rows,omitempty"` // Default number of rows
+	Columns []Column `yaml:"columns"`
+}
+
+// Column describes how the values of a column are generated
+type Column struct {
+	Name     string    `yaml:"name"`
+	Type     string    `yaml:"type"`
+	Min      string    `yaml:"min,omitempty"`
+	Max      string    `yaml:"max,omitempty"`
+	Decimals *int      `yaml:"decimals,omitempty"`
+	Length   int       `yaml:"length,omitempty"`
+	Values   []string  `yaml:"values,omitempty"`
+	Weights  []float64 `yaml:"weights,omitempty"`
+	TrueRate *float64  `yaml:"true_rate,omitempty"`
+	NullRate float64   `yaml:"null_rate,omitempty"`
+}
+
+// Types returns the supported column types
+func Types() []string {
+	return append([]string(nil), types...)
+}
+
+// Load reads and validates a spec file
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	var spec Spec
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to parse schema %s: %w", path, err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+	return &spec, nil
+}
+
+// Marshal returns the spec as YAML, e.g. to edit a profiled spec
+func (s *Spec) Marshal() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// Validate checks the columns and their parameters
+func (s *Spec) Validate() error {
+	if len(s.Columns) == 0 {
+		return fmt.Errorf("at least one column is required")
+	}
+	if s.Rows < 0 {
+		return fmt.Errorf("rows must not be negative")
+	}
+
+	seen := make(map[string]bool, len(s.Columns))
+	for _, column := range s.Columns {
+		if column.Name == "" {
+			return fmt.Errorf("a column has no name")
+		}
+		if seen[strings.ToLower(column.Name)] {
+			return fmt.Errorf("duplicate column %s", column.Name)
+		}
+		seen[strings.ToLower(column.Name)] = true
+
+		if err := column.validate(); err != nil {
+			return fmt.Errorf("column %s: %w", column.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *Column) validate() error {
+	if c.NullRate < 0 || c.NullRate > 1 {
+		return fmt.Errorf("null_rate must be between 0 and 1")
+	}
+
+	switch c.Type {
+	case TypeSequence:
+		_, err := c.intBounds(1, 1)
+		return err
+	case TypeInteger:
+		_, err := c.intBounds(0, defaultIntegerMax)
+		return err
+	case TypeFloat:
+		if c.Decimals != nil && (*c.Decimals < 0 || *c.Decimals > 15) {
+			return fmt.Errorf("decimals must be between 0 and 15")
+		}
+		_, err := c.floatBounds()
+		return err
+	case TypeBoolean:
+		if c.TrueRate != nil && (*c.TrueRate < 0 || *c.TrueRate > 1) {
+			return fmt.Errorf("true_rate must be between 0 and 1")
+		}
+	case TypeChoice:
+		if len(c.Values) == 0 {
+			return fmt.Errorf("choice requires values")
+		}
+		if len(c.Weights) > 0 && len(c.Weights) != len(c.Values) {
+			return fmt.Errorf("%d weights for %d values", len(c.Weights), len(c.Values))
+		}
+		total := 0.0
+		for _, weight := range c.Weights {
+			if weight < 0 {
+				return fmt.Errorf("weights must not be negative")
+			}
+			total += weight
+		}
+		if len(c.Weights) > 0 && total == 0 {
+			return fmt.Errorf("weights must not all be zero")
+		}
+	case TypeString:
+		if c.Length < 0 || c.Length > 1000 {
+			return fmt.Errorf("length must be between 1 and 1000")
+		}
+	case TypeName, TypeEmail, TypeUUID:
+	case TypeDate:
+		_, err := c.timeBounds(dateLayout)
+		return err
+	case TypeTimestamp:
+		_, err := c.timeBounds(timestampLayout)
+		return err
+	case "":
+		return fmt.Errorf("type is required (%s)", strings.Join(types, ", "))
+	default:
+		return fmt.Errorf("unknown type %q (%s)", c.Type, strings.Join(types, ", "))
+	}
+	return nil
+}
+
+// intBounds parses min and max as integers
+func (c *Column) intBounds(defaultMin, defaultMax int64) ([2]int64, error) {
+	bounds := [2]int64{defaultMin, defaultMax}
+	for i, text := range []string{c.Min, c.Max} {
+		if text == "" {
+			continue
+		}
+		value, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+		if err != nil {
+			return bounds, fmt.Errorf("invalid integer %q", text)
+		}
+		bounds[i] = value
+	}
+	if c.Type != TypeSequence && bounds[0] > bounds[1] {
+		return bounds, fmt.Errorf("min %d is greater than max %d", bounds[0], bounds[1])
+	}
+	return bounds, nil
+}
+
+// floatBounds parses min and max as numbers (default 0 and 1)
+func (c *Column) floatBounds() ([2]float64, error) {
+	bounds := [2]float64{0, 1}
+	for i, text := range []string{c.Min, c.Max} {
+		if text == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return bounds, fmt.Errorf("invalid number %q", text)
+		}
+		bounds[i] = value
+	}
+	if bounds[0] > bounds[1] {
+		return bounds, fmt.Errorf("min %g is greater than max %g", bounds[0], bounds[1])
+	}
+	return bounds, nil
+}
+
+// timeBounds parses min and max as dates or timestamps. A date is accepted
+// for a timestamp bound.
+func (c *Column) timeBounds(layout string) ([2]time.Time, error) {
+	var bounds [2]time.Time
+	for i, text := range []string{c.Min, c.Max} {
+		if text == "" {
+			text = []string{defaultDateMin, defaultDateMax}[i]
+		}
+		text = strings.TrimSpace(text)
+
+		value, err := time.Parse(layout, text)
+		if err != nil && layout == timestampLayout {
+			if value, err = time.Parse(time.RFC3339, text); err != nil {
+				value, err = time.Parse(dateLayout, text)
+			}
+		}
+		if err != nil {
+			return bounds, fmt.Errorf("invalid %s %q (expected %s)", c.Type, text, layout)
+		}
+		bounds[i] = value.UTC()
+	}
+	if bounds[0].After(bounds[1]) {
+		return bounds, fmt.Errorf("min %s is after max %s", bounds[0].Format(layout), bounds[1].Format(layout))
+	}
+	return bounds, nil
+}
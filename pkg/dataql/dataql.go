@@ -0,0 +1,276 @@
+// Package dataql embeds the dataql query engine in Go programs. It imports
+// files (CSV, JSON, Parquet, Excel, ...), URLs, object storage and databases
+// into an in-process DuckDB database and runs SQL against them, without the
+// CLI and without writing to stdout.
+//
+//	db, err := dataql.Open("sales.csv", "customers.json")
+//	if err != nil {
+//		return err
+//	}
+//	defer db.Close()
+//
+//	rows, err := db.Query(ctx, "SELECT region, SUM(amount) FROM sales GROUP BY region")
+package dataql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adrianolaselva/dataql/internal/dataql"
+	"github.com/adrianolaselva/dataql/internal/exportdata"
+	"github.com/adrianolaselva/dataql/pkg/metrics"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/adrianolaselva/dataql/pkg/timezone"
+	"github.com/schollz/progressbar/v3"
+)
+
+// Options configures how Open reads the sources
+type Options struct {
+	Delimiter   string // CSV delimiter (default: comma)
+	InputFormat string // Format of data read from stdin with the "-" source (default: csv)
+	JSONNested  string // How JSON and JSONL import nested values: flatten (default), struct or json
+	Lines       int    // Read only the first N rows of each source (0: all rows)
+	Collection  string // Table name for the imported data (default: derived from each file name)
+	Storage     string // DuckDB file to persist the tables to (default: in memory)
+	Cache       bool   // Cache imported data so remote sources are not downloaded again
+	CacheDir    string // Cache directory (default: ~/.dataql/cache)
+	Engine      string // Storage engine that runs the queries: duckdb (default) or sqlite
+	Encrypt     bool   // Encrypt new storage and cache files with the key of $DATAQL_ENCRYPTION_KEY or the OS keyring
+
+	// Attach lists existing DuckDB files attached as "path[:alias]", whose
+	// tables are queried as alias.table (alias: the file name)
+	Attach []string
+
+	// BatchSize is how many imported rows are buffered in memory before they
+	// are written to a table (default: 100000). CommitInterval is how many
+	// rows inserted one by one are committed together (default: 10000).
+	BatchSize      int
+	CommitInterval int
+
+	// ReadOnly opens Storage and the Attach files read-only and makes Query,
+	// Exec and Export reject statements other than SELECT, WITH, SHOW,
+	// DESCRIBE and EXPLAIN
+	ReadOnly bool
+
+	// NoExternalAccess blocks SQL from reading or writing files and URLs
+	// once the sources are imported
+	NoExternalAccess bool
+
+	// ColumnNames is how column names are derived from the headers and keys
+	// of the sources: snake, lower or keep (default: CSV headers as is, the
+	// keys of other formats lower). ColumnReplace replaces characters other
+	// than letters, digits and _ in snake and lower names (default: removed).
+	// ColumnDedupe resolves duplicate names with a _2, _3... suffix
+	// ("suffix", the default) or fails the import ("error").
+	ColumnNames   string
+	ColumnReplace string
+	ColumnDedupe  string
+
+	// DecimalAsString writes DECIMAL and HUGEINT values as strings in JSON,
+	// JSONL and YAML exports, for readers that parse numbers as doubles
+	DecimalAsString bool
+
+	// InputTZ is the time zone of timestamps without a UTC offset in the
+	// sources, e.g. "America/Sao_Paulo"; set, timestamp columns are imported
+	// as TIMESTAMPTZ. OutputTZ is the time zone Export writes TIMESTAMPTZ
+	// values in (default: UTC).
+	InputTZ  string
+	OutputTZ string
+
+	// Settings are DuckDB settings applied before the sources are imported,
+	// e.g. {"threads": "4", "memory_limit": "2GB"}
+	Settings map[string]string
+
+	// Extensions are DuckDB extensions loaded before the sources are
+	// imported, e.g. spatial, installed first when missing
+	Extensions []string
+}
+
+// DB is a set of imported sources that can be queried with SQL. A DB is
+// safe for concurrent queries once Open returns.
+type DB struct {
+	engine          dataql.DataQL
+	decimalAsString bool
+	outputTZ        *time.Location
+}
+
+// Table holds the name and columns of a table
+type Table = dataql.TableSchema
+
+// Column holds the name and data type of a column
+type Column = dataql.ColumnSchema
+
+// Open imports the given sources with the default options. Each source
+// becomes a table named after the file: local paths, "-" for stdin,
+// HTTP(S) URLs, s3://, gs://, az://, compressed files and database URLs
+// (postgres://, mysql://, duckdb://, mongodb://, dynamodb://).
+func Open(sources ...string) (*DB, error) {
+	return OpenWithOptions(Options{}, sources...)
+}
+
+// OpenWithOptions imports the given sources with opts. Without sources,
+// opts.Storage must name an existing DuckDB file whose tables are queried,
+// or opts.Attach at least one.
+func OpenWithOptions(opts Options, sources ...string) (*DB, error) {
+	if opts.Delimiter == "" {
+		opts.Delimiter = ","
+	}
+	if opts.InputFormat == "" {
+		opts.InputFormat = "csv"
+	}
+
+	params := dataql.Params{
+		FileInputs:       sources,
+		Delimiter:        opts.Delimiter,
+		InputFormat:      opts.InputFormat,
+		JSONNested:       opts.JSONNested,
+		ColumnNames:      opts.ColumnNames,
+		ColumnReplace:    opts.ColumnReplace,
+		ColumnDedupe:     opts.ColumnDedupe,
+		Lines:            opts.Lines,
+		Collection:       opts.Collection,
+		DataSourceName:   opts.Storage,
+		Cache:            opts.Cache,
+		CacheDir:         opts.CacheDir,
+		Encrypt:          opts.Encrypt,
+		Engine:           opts.Engine,
+		Attach:           opts.Attach,
+		ReadOnly:         opts.ReadOnly,
+		NoExternalAccess: opts.NoExternalAccess,
+		BatchSize:        opts.BatchSize,
+		CommitInterval:   opts.CommitInterval,
+		Extensions:       opts.Extensions,
+		InputTZ:          opts.InputTZ,
+		OutputTZ:         opts.OutputTZ,
+		Quiet:            true,
+	}
+	for name, value := range opts.Settings {
+		params.Settings = append(params.Settings, name+"="+value)
+	}
+	sort.Strings(params.Settings)
+
+	if err := storage.CheckBatch(opts.BatchSize, opts.CommitInterval); err != nil {
+		return nil, err
+	}
+
+	outputTZ, err := timezone.Load(opts.OutputTZ)
+	if err != nil {
+		return nil, err
+	}
+
+	var engine dataql.DataQL
+	switch {
+	case len(sources) > 0:
+		engine, err = dataql.New(params)
+	case opts.Storage != "" || len(opts.Attach) > 0:
+		engine, err = dataql.NewStorageOnly(params)
+	default:
+		return nil, fmt.Errorf("at least one source or a storage file is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Import up front so queries never race on the first import
+	if err := engine.Import(); err != nil {
+		_ = engine.Close()
+		return nil, err
+	}
+
+	return &DB{engine: engine, decimalAsString: opts.DecimalAsString, outputTZ: outputTZ}, nil
+}
+
+// Query runs a SQL statement and returns its rows, which must be closed.
+// The statement is interrupted when ctx is done.
+func (db *DB) Query(ctx context.Context, query string) (*Rows, error) {
+	rows, err := db.engine.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(rows)
+}
+
+// Exec runs a SQL statement that returns no rows, such as CREATE TABLE,
+// INSERT or UPDATE
+func (db *DB) Exec(ctx context.Context, query string) error {
+	return db.engine.ExecContext(ctx, query)
+}
+
+// Tables returns the tables and their column types, including tables
+// created with Exec after Open
+func (db *DB) Tables() ([]Table, error) {
+	return db.engine.Schema()
+}
+
+// SourceTable returns the table named name, or the only table when name is
+// empty. Commands reading one table of their input pass their --table flag.
+func (db *DB) SourceTable(name string) (Table, error) {
+	tables, err := db.Tables()
+	if err != nil {
+		return Table{}, err
+	}
+
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = table.Name
+		if name != "" && strings.EqualFold(table.Name, name) {
+			return table, nil
+		}
+	}
+
+	switch {
+	case name != "":
+		return Table{}, fmt.Errorf("table %q not found (available: %s)", name, strings.Join(names, ", "))
+	case len(tables) == 0:
+		return Table{}, fmt.Errorf("the input has no data")
+	case len(tables) > 1:
+		return Table{}, fmt.Errorf("the input holds %d tables (%s); choose one with --table", len(tables), strings.Join(names, ", "))
+	}
+	return tables[0], nil
+}
+
+// Export writes the result of query to path. format is one of csv, jsonl,
+// json, excel, parquet, xml, yaml, markdown or html; when it is empty the
+// format is taken from the file extension. Export returns the number of rows
+// written.
+func (db *DB) Export(ctx context.Context, query, path, format string) (count int64, err error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveExport(time.Since(start), err)
+	}()
+
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if format == "" {
+			return 0, fmt.Errorf("export format is required for %s (no file extension)", path)
+		}
+	}
+
+	rows, err := db.engine.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	export, err := exportdata.NewExport(format, rows, path, progressbar.NewOptions(0, progressbar.OptionSetWriter(io.Discard)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to export: %w", err)
+	}
+	exportdata.SetDecimalAsString(export, db.decimalAsString)
+	exportdata.SetTimeZone(export, db.outputTZ)
+	if count, err = export.Export(); err != nil {
+		return 0, fmt.Errorf("failed to export data: %w", err)
+	}
+	return count, rows.Err()
+}
+
+// Close releases the database and removes temporary files of downloaded or
+// decompressed sources
+func (db *DB) Close() error {
+	return db.engine.Close()
+}
@@ -0,0 +1,178 @@
+package dataql_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	usersFixture       = "../../tests/fixtures/csv/users.csv"
+	departmentsFixture = "../../tests/fixtures/csv/departments.csv"
+)
+
+func TestOpenAndQuery(t *testing.T) {
+	db, err := dataql.Open(usersFixture, departmentsFixture)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query(context.Background(), `SELECT u.name, d.department_name
+		FROM users u JOIN departments d ON u.department_id = d.id
+		ORDER BY u.id`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	assert.Equal(t, []string{"name", "department_name"}, rows.Columns())
+	assert.Equal(t, []string{"VARCHAR", "VARCHAR"}, rows.Types())
+
+	var names []string
+	for rows.Next() {
+		var name, department string
+		require.NoError(t, rows.Scan(&name, &department))
+		names = append(names, name+"/"+department)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"Alice/Engineering", "Bob/Sales", "Charlie/Engineering"}, names)
+}
+
+func TestRowsValues(t *testing.T) {
+	db, err := dataql.Open(usersFixture)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query(context.Background(), "SELECT id, name, CAST(1.50 AS DECIMAL(4,2)) AS price FROM users ORDER BY id LIMIT 1")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	require.True(t, rows.Next())
+	values, err := rows.Values()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), values[0])
+	assert.Equal(t, "Alice", values[1])
+	assert.Equal(t, json.Number("1.5"), values[2])
+}
+
+func TestExecAndTables(t *testing.T) {
+	db, err := dataql.OpenWithOptions(dataql.Options{Collection: "people"}, usersFixture)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Exec(ctx, "CREATE TABLE teams AS SELECT DISTINCT department_id FROM people"))
+
+	tables, err := db.Tables()
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+	assert.Equal(t, "people", tables[0].Name)
+	assert.Equal(t, "teams", tables[1].Name)
+	assert.Equal(t, dataql.Column{Name: "department_id", Type: "BIGINT"}, tables[1].Columns[0])
+
+	err = db.Exec(ctx, "SELECT * FROM missing")
+	assert.Error(t, err)
+}
+
+func TestSourceTable(t *testing.T) {
+	db, err := dataql.Open(usersFixture, departmentsFixture)
+	require.NoError(t, err)
+	defer db.Close()
+
+	table, err := db.SourceTable("USERS")
+	require.NoError(t, err)
+	assert.Equal(t, "users", table.Name)
+
+	_, err = db.SourceTable("")
+	assert.ErrorContains(t, err, "the input holds 2 tables")
+	_, err = db.SourceTable("missing")
+	assert.ErrorContains(t, err, `table "missing" not found`)
+
+	single, err := dataql.Open(usersFixture)
+	require.NoError(t, err)
+	defer single.Close()
+	table, err = single.SourceTable("")
+	require.NoError(t, err)
+	assert.Equal(t, "users", table.Name)
+}
+
+func TestQueryCancelled(t *testing.T) {
+	db, err := dataql.Open(usersFixture)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = db.Query(ctx, "SELECT * FROM users")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestExport(t *testing.T) {
+	db, err := dataql.Open(usersFixture)
+	require.NoError(t, err)
+	defer db.Close()
+
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "engineering.csv")
+	rows, err := db.Export(context.Background(), "SELECT id, name FROM users WHERE department_id = 10 ORDER BY id", csvPath, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), rows)
+
+	content, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, "id,name\n1,Alice\n3,Charlie\n", string(content))
+
+	jsonlPath := filepath.Join(dir, "users.out")
+	_, err = db.Export(context.Background(), "SELECT name FROM users ORDER BY id LIMIT 1", jsonlPath, "jsonl")
+	require.NoError(t, err)
+	content, err = os.ReadFile(jsonlPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"name":"Alice"`)
+
+	_, err = db.Export(context.Background(), "SELECT 1", filepath.Join(dir, "noext"), "")
+	assert.Error(t, err)
+}
+
+func TestOpenStorageOnly(t *testing.T) {
+	_, err := dataql.Open()
+	assert.Error(t, err)
+
+	storagePath := filepath.Join(t.TempDir(), "data.duckdb")
+	db, err := dataql.OpenWithOptions(dataql.Options{Storage: storagePath}, usersFixture)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	db, err = dataql.OpenWithOptions(dataql.Options{Storage: storagePath})
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query(context.Background(), "SELECT COUNT(*) FROM users")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var count int
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Scan(&count))
+	assert.Equal(t, 3, count)
+}
+
+func TestOpenWithSettings(t *testing.T) {
+	db, err := dataql.OpenWithOptions(dataql.Options{Settings: map[string]string{"threads": "3"}}, usersFixture)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query(context.Background(), "SELECT current_setting('threads')")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	require.True(t, rows.Next())
+	var threads int64
+	require.NoError(t, rows.Scan(&threads))
+	assert.Equal(t, int64(3), threads)
+
+	_, err = dataql.OpenWithOptions(dataql.Options{Settings: map[string]string{"no_such_setting": "1"}}, usersFixture)
+	assert.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package dataql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/datadiff"
+)
+
+// diffQuerier runs the queries of a comparison on the database
+type diffQuerier struct {
+	db *DB
+}
+
+func (q diffQuerier) Query(ctx context.Context, query string) (datadiff.Rows, error) {
+	rows, err := q.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Diff compares the left (old) table with the right (new) one, matching
+// rows by the keys columns; without keys whole rows are compared
+func (db *DB) Diff(left, right string, keys []string) (*datadiff.Diff, error) {
+	tables, err := db.Tables()
+	if err != nil {
+		return nil, err
+	}
+
+	compared := make([]datadiff.Table, 2)
+	for i, name := range []string{left, right} {
+		found := false
+		for _, table := range tables {
+			if !strings.EqualFold(table.Name, name) {
+				continue
+			}
+			compared[i] = datadiff.Table{Name: table.Name}
+			for _, column := range table.Columns {
+				compared[i].Columns = append(compared[i].Columns, datadiff.Column{Name: column.Name, Type: column.Type})
+			}
+			found = true
+			break
+		}
+		if !found {
+			return nil, fmt.Errorf("table %s not found", name)
+		}
+	}
+	return datadiff.New(diffQuerier{db: db}, compared[0], compared[1], keys)
+}
@@ -0,0 +1,75 @@
+package dataql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/adrianolaselva/dataql/internal/dataql"
+)
+
+// Rows is the result of a query. Call Next before each row is read and
+// Close when done, as with database/sql.
+type Rows struct {
+	rows    *sql.Rows
+	columns []string
+	types   []string
+}
+
+// newRows reads the column names and types of rows
+func newRows(rows *sql.Rows) (*Rows, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		_ = rows.Close()
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		_ = rows.Close()
+		return nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+	types := make([]string, len(columnTypes))
+	for i, columnType := range columnTypes {
+		types[i] = columnType.DatabaseTypeName()
+	}
+
+	return &Rows{rows: rows, columns: columns, types: types}, nil
+}
+
+// Columns returns the column names
+func (r *Rows) Columns() []string {
+	return r.columns
+}
+
+// Types returns the database type of each column, e.g. BIGINT or VARCHAR
+func (r *Rows) Types() []string {
+	return r.types
+}
+
+// Next prepares the next row, returning false when there are no more rows
+// or an error occurred (see Err)
+func (r *Rows) Next() bool {
+	return r.rows.Next()
+}
+
+// Scan copies the columns of the current row into dest, like sql.Rows.Scan
+func (r *Rows) Scan(dest ...any) error {
+	return r.rows.Scan(dest...)
+}
+
+// Values returns the current row as Go values that encoding/json can
+// marshal without losing precision: decimals and huge integers become
+// json.Number, UUIDs and text become strings.
+func (r *Rows) Values() ([]any, error) {
+	return dataql.ScanValues(r.rows, r.types)
+}
+
+// Err returns the error that stopped Next, if any
+func (r *Rows) Err() error {
+	return r.rows.Err()
+}
+
+// Close releases the rows; it is safe to call more than once
+func (r *Rows) Close() error {
+	return r.rows.Close()
+}
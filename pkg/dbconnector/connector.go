@@ -3,6 +3,8 @@ package dbconnector
 import (
 	"database/sql"
 	"fmt"
+
+	"github.com/adrianolaselva/dataql/pkg/tlsconfig"
 )
 
 // DBType represents the type of database
@@ -44,6 +46,7 @@ type Config struct {
 	Password string
 	Database string
 	SSLMode  string
+	TLS      tlsconfig.Options // CAs of --ca-cert, --insecure-skip-verify
 }
 
 // NewConnector creates a new database connector based on the type
@@ -4,10 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net/http"
 	"os"
 	"sort"
 	"time"
 
+	"github.com/adrianolaselva/dataql/pkg/tlsconfig"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -20,6 +22,7 @@ type DynamoDBConnector struct {
 	tableName string
 	region    string
 	endpoint  string
+	tls       tlsconfig.Options
 	ctx       context.Context
 	cancel    context.CancelFunc
 }
@@ -28,7 +31,8 @@ type DynamoDBConnector struct {
 type DynamoDBConfig struct {
 	Region    string
 	TableName string
-	Endpoint  string // Optional: for LocalStack or local DynamoDB
+	Endpoint  string            // Optional: for LocalStack or local DynamoDB
+	TLS       tlsconfig.Options // CAs of --ca-cert, --insecure-skip-verify
 }
 
 // NewDynamoDBConnector creates a new DynamoDB connector
@@ -38,6 +42,7 @@ func NewDynamoDBConnector(cfg DynamoDBConfig) (*DynamoDBConnector, error) {
 		tableName: cfg.TableName,
 		region:    cfg.Region,
 		endpoint:  cfg.Endpoint,
+		tls:       cfg.TLS,
 		ctx:       ctx,
 		cancel:    cancel,
 	}, nil
@@ -59,6 +64,15 @@ func (d *DynamoDBConnector) Connect() error {
 		endpoint = os.Getenv("AWS_ENDPOINT_URL_DYNAMODB")
 	}
 
+	// Trust the CAs of --ca-cert; the transport keeps the proxies of HTTPS_PROXY
+	if !d.tls.IsZero() {
+		transport, err := d.tls.Transport()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, config.WithHTTPClient(&http.Client{Transport: transport}))
+	}
+
 	cfg, err := config.LoadDefaultConfig(d.ctx, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to load AWS config: %w", err)
@@ -39,6 +39,13 @@ func (m *MongoDBConnector) Connect() error {
 	connStr := m.buildConnectionString()
 
 	clientOptions := options.Client().ApplyURI(connStr)
+	tlsConfig, err := m.config.TLS.Config()
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		clientOptions.SetTLSConfig(tlsConfig)
+	}
 	client, err := mongo.Connect(m.ctx, clientOptions)
 	if err != nil {
 		return fmt.Errorf("failed to connect to MongoDB: %w", err)
@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"strings"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
+// mysqlTLSConfig is the name the TLS configuration of --ca-cert and
+// --insecure-skip-verify is registered with in the driver
+const mysqlTLSConfig = "dataql"
+
 // MySQLConnector implements the Connector interface for MySQL
 type MySQLConnector struct {
 	config Config
@@ -23,6 +27,15 @@ func NewMySQLConnector(config Config) (*MySQLConnector, error) {
 
 // Connect establishes a connection to the MySQL database
 func (m *MySQLConnector) Connect() error {
+	if !m.config.TLS.IsZero() {
+		tlsConfig, err := m.config.TLS.Config()
+		if err != nil {
+			return err
+		}
+		if err := mysql.RegisterTLSConfig(mysqlTLSConfig, tlsConfig); err != nil {
+			return fmt.Errorf("failed to register TLS config: %w", err)
+		}
+	}
 	connStr := m.buildConnectionString()
 
 	db, err := sql.Open("mysql", connStr)
@@ -45,13 +58,18 @@ func (m *MySQLConnector) buildConnectionString() string {
 	// charset=utf8mb4: Ensures proper UTF-8 encoding (prevents binary []byte returns for VARCHAR)
 	// collation=utf8mb4_unicode_ci: Standard Unicode collation for utf8mb4
 	// parseTime=true: Parses DATE and DATETIME to time.Time instead of []byte
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&collation=utf8mb4_unicode_ci&parseTime=true",
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&collation=utf8mb4_unicode_ci&parseTime=true",
 		m.config.User,
 		m.config.Password,
 		m.config.Host,
 		m.config.Port,
 		m.config.Database,
 	)
+	// tls=dataql: the configuration registered by Connect
+	if !m.config.TLS.IsZero() {
+		dsn += "&tls=" + mysqlTLSConfig
+	}
+	return dsn
 }
 
 // Close closes the database connection
@@ -3,6 +3,8 @@ package dbconnector
 import (
 	"strings"
 	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/tlsconfig"
 )
 
 func TestMySQLConnector_buildConnectionString(t *testing.T) {
@@ -42,6 +44,19 @@ func TestMySQLConnector_buildConnectionString(t *testing.T) {
 				"charset=utf8mb4",
 			},
 		},
+		{
+			name: "should use the registered TLS config",
+			config: Config{
+				Type:     DBTypeMySQL,
+				Host:     "mysql.example.com",
+				Port:     3306,
+				Database: "production",
+				TLS:      tlsconfig.Options{CACert: "/etc/ssl/corp-ca.pem"},
+			},
+			contains: []string{
+				"&tls=" + mysqlTLSConfig,
+			},
+		},
 	}
 
 	for _, tt := range tests {
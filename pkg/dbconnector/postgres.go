@@ -46,17 +46,36 @@ func (p *PostgresConnector) buildConnectionString() string {
 		sslMode = "disable"
 	}
 
+	// --ca-cert verifies the server certificate and host name against the
+	// CAs; --insecure-skip-verify encrypts without checking the certificate
+	var rootCert string
+	switch {
+	case p.config.TLS.InsecureSkipVerify:
+		sslMode = "require"
+	case p.config.TLS.CACert != "":
+		sslMode = "verify-full"
+		rootCert = " sslrootcert=" + quoteConnValue(p.config.TLS.CACert)
+	}
+
 	return fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s%s",
 		p.config.Host,
 		p.config.Port,
 		p.config.User,
 		p.config.Password,
 		p.config.Database,
 		sslMode,
+		rootCert,
 	)
 }
 
+// quoteConnValue quotes a value of a PostgreSQL connection string, such as
+// a path with spaces
+func quoteConnValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
 // Close closes the database connection
 func (p *PostgresConnector) Close() error {
 	if p.db != nil {
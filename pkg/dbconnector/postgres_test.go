@@ -0,0 +1,45 @@
+package dbconnector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/tlsconfig"
+)
+
+func TestPostgresConnector_buildConnectionString(t *testing.T) {
+	tests := []struct {
+		name     string
+		tls      tlsconfig.Options
+		expected string
+	}{
+		{
+			name:     "should keep sslmode without TLS options",
+			expected: "sslmode=disable",
+		},
+		{
+			name:     "should verify the server with --ca-cert",
+			tls:      tlsconfig.Options{CACert: "/etc/ssl/corp ca's.pem"},
+			expected: `sslmode=verify-full sslrootcert='/etc/ssl/corp ca\'s.pem'`,
+		},
+		{
+			name:     "should encrypt without verifying with --insecure-skip-verify",
+			tls:      tlsconfig.Options{CACert: "/etc/ssl/corp-ca.pem", InsecureSkipVerify: true},
+			expected: "sslmode=require",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := &PostgresConnector{
+				config: Config{Type: DBTypePostgres, Host: "localhost", Port: 5432, Database: "db", SSLMode: "disable", TLS: tt.tls},
+			}
+
+			connStr := connector.buildConnectionString()
+
+			if !strings.HasSuffix(connStr, tt.expected) {
+				t.Errorf("connection string should end with %q, got: %s", tt.expected, connStr)
+			}
+		})
+	}
+}
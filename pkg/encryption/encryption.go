@@ -0,0 +1,227 @@
+// Package encryption encrypts DuckDB storage and cache files at rest with
+// AES-256-GCM.
+//
+// A file is encrypted in chunks, so files larger than memory can be
+// processed: a header (magic and random nonce prefix) is followed by sealed
+// chunks of up to chunkSize bytes. Each chunk nonce holds the chunk index
+// and a flag marking the last chunk, so reordered, dropped or truncated
+// chunks fail authentication.
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/keyring"
+)
+
+const (
+	// EnvKey holds the encryption key, base64 or hex encoded
+	EnvKey = "DATAQL_ENCRYPTION_KEY"
+	// KeyringAccount names the encryption key in the OS keyring
+	KeyringAccount = "encryption-key"
+	// KeySize is the size of an AES-256 key
+	KeySize = 32
+
+	magic      = "DQLENC1\x00"
+	prefixSize = 7
+	chunkSize  = 1 << 20
+)
+
+// ErrNoKey is returned when neither the environment nor the keyring has a key
+var ErrNoKey = errors.New("no encryption key: set $" + EnvKey + " or store one with dataql encryption keygen --keyring")
+
+// LoadKey returns the key of $DATAQL_ENCRYPTION_KEY, or else the one stored in the OS keyring
+func LoadKey() ([]byte, error) {
+	if text := os.Getenv(EnvKey); text != "" {
+		return ParseKey(text)
+	}
+
+	text, err := keyring.Get(KeyringAccount)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, ErrNoKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w (%v)", ErrNoKey, err)
+	}
+	return ParseKey(text)
+}
+
+// GenerateKey returns a random key, base64 encoded
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseKey decodes a base64 or hex encoded 256-bit key
+func ParseKey(text string) ([]byte, error) {
+	text = strings.TrimSpace(text)
+	if key, err := hex.DecodeString(text); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("invalid encryption key: expected %d bytes, base64 or hex encoded (generate one with dataql encryption keygen)", KeySize)
+}
+
+// IsEncrypted reports whether a file was encrypted by this package; a
+// missing file is not encrypted
+func IsEncrypted(path string) (bool, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(file, header); err != nil {
+		return false, nil
+	}
+	return string(header) == magic, nil
+}
+
+// Encrypt writes the encryption of r to w
+func Encrypt(w io.Writer, r io.Reader, key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, len(magic)+prefixSize)
+	copy(header, magic)
+	if _, err := rand.Read(header[len(magic):]); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	// The last chunk is always shorter than chunkSize, empty if needed
+	plain := make([]byte, chunkSize)
+	sealed := make([]byte, 0, chunkSize+aead.Overhead())
+	for index := uint32(0); ; index++ {
+		n, err := io.ReadFull(r, plain)
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return err
+		}
+
+		sealed = aead.Seal(sealed[:0], nonce(header, index, last), plain[:n], header)
+		if _, err := w.Write(sealed); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+// Decrypt writes the decryption of r to w
+func Decrypt(w io.Writer, r io.Reader, key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, len(magic)+prefixSize)
+	if _, err := io.ReadFull(r, header); err != nil || !bytes.HasPrefix(header, []byte(magic)) {
+		return fmt.Errorf("not an encrypted dataql file")
+	}
+
+	sealed := make([]byte, chunkSize+aead.Overhead())
+	plain := make([]byte, 0, chunkSize)
+	for index := uint32(0); ; index++ {
+		n, err := io.ReadFull(r, sealed)
+		if err == io.EOF {
+			return fmt.Errorf("encrypted file is truncated")
+		}
+		last := err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return err
+		}
+
+		plain, err = aead.Open(plain[:0], nonce(header, index, last), sealed[:n], header)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt: wrong key or corrupted file")
+		}
+		if _, err := w.Write(plain); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+// EncryptFile encrypts src into dst, replacing dst only once it is complete
+func EncryptFile(src, dst string, key []byte) error {
+	return transformFile(src, dst, key, Encrypt)
+}
+
+// DecryptFile decrypts src into dst, replacing dst only once it is complete
+func DecryptFile(src, dst string, key []byte) error {
+	return transformFile(src, dst, key, Decrypt)
+}
+
+func transformFile(src, dst string, key []byte, transform func(io.Writer, io.Reader, []byte) error) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+
+	if err := transform(out, in, key); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), dst)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid encryption key: expected %d bytes", KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// nonce is the nonce prefix of the header, the chunk index and the last chunk flag
+func nonce(header []byte, index uint32, last bool) []byte {
+	n := make([]byte, 12)
+	copy(n, header[len(magic):])
+	binary.BigEndian.PutUint32(n[prefixSize:], index)
+	if last {
+		n[11] = 1
+	}
+	return n
+}
@@ -0,0 +1,155 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/keyring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+	text, err := GenerateKey()
+	require.NoError(t, err)
+	key, err := ParseKey(text)
+	require.NoError(t, err)
+	return key
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := testKey(t)
+
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, 2*chunkSize + 5} {
+		plain := make([]byte, size)
+		_, _ = rand.Read(plain)
+
+		var sealed bytes.Buffer
+		require.NoError(t, Encrypt(&sealed, bytes.NewReader(plain), key))
+		if size > 0 {
+			assert.False(t, bytes.Contains(sealed.Bytes(), plain[:min(size, 64)]), "size %d", size)
+		}
+
+		var opened bytes.Buffer
+		require.NoError(t, Decrypt(&opened, bytes.NewReader(sealed.Bytes()), key), "size %d", size)
+		assert.True(t, bytes.Equal(plain, opened.Bytes()), "size %d", size)
+	}
+}
+
+func TestDecrypt_Tampering(t *testing.T) {
+	key := testKey(t)
+	plain := bytes.Repeat([]byte("secret "), chunkSize/3)
+
+	var sealed bytes.Buffer
+	require.NoError(t, Encrypt(&sealed, bytes.NewReader(plain), key))
+	data := sealed.Bytes()
+
+	err := Decrypt(&bytes.Buffer{}, bytes.NewReader(data), testKey(t))
+	assert.ErrorContains(t, err, "wrong key")
+
+	corrupted := bytes.Clone(data)
+	corrupted[len(corrupted)/2] ^= 1
+	assert.Error(t, Decrypt(&bytes.Buffer{}, bytes.NewReader(corrupted), key))
+
+	// Dropping the last chunk leaves a stream ending on a full chunk
+	truncated := data[:len(magic)+prefixSize+chunkSize+16]
+	assert.ErrorContains(t, Decrypt(&bytes.Buffer{}, bytes.NewReader(truncated), key), "truncated")
+
+	assert.ErrorContains(t, Decrypt(&bytes.Buffer{}, bytes.NewReader([]byte("plain text")), key), "not an encrypted")
+}
+
+func TestParseKey(t *testing.T) {
+	key := testKey(t)
+
+	parsed, err := ParseKey(hex.EncodeToString(key))
+	require.NoError(t, err)
+	assert.Equal(t, key, parsed)
+
+	for _, text := range []string{"", "short", hex.EncodeToString(key[:16])} {
+		_, err := ParseKey(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv(EnvKey, "")
+
+	_, err := LoadKey()
+	assert.ErrorIs(t, err, ErrNoKey)
+
+	text, err := GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, keyring.Set(KeyringAccount, text))
+	fromKeyring, err := LoadKey()
+	require.NoError(t, err)
+
+	other, err := GenerateKey()
+	require.NoError(t, err)
+	t.Setenv(EnvKey, other)
+	fromEnv, err := LoadKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, fromKeyring, fromEnv, "the environment takes precedence over the keyring")
+}
+
+func TestWorkingCopy(t *testing.T) {
+	key := testKey(t)
+	path := filepath.Join(t.TempDir(), "store.duckdb")
+
+	// A missing file is created encrypted on Close
+	w, err := Open(path, key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(w.Path(), []byte("first"), 0600))
+	require.NoError(t, w.Close())
+	assert.NoDirExists(t, filepath.Dir(w.Path()))
+
+	encrypted, err := IsEncrypted(path)
+	require.NoError(t, err)
+	assert.True(t, encrypted)
+
+	// An unchanged copy leaves the encrypted file untouched
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+	w, err = Open(path, key)
+	require.NoError(t, err)
+	data, err := os.ReadFile(w.Path())
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(data))
+	require.NoError(t, w.Close())
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	// A changed copy is encrypted back
+	w, err = Open(path, key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(w.Path(), []byte("second"), 0600))
+	require.NoError(t, w.Close())
+
+	var plain bytes.Buffer
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	require.NoError(t, Decrypt(&plain, file, key))
+	assert.Equal(t, "second", plain.String())
+
+	_, err = Open(path, testKey(t))
+	assert.ErrorContains(t, err, "wrong key")
+}
+
+func TestIsEncrypted_PlainFiles(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.duckdb")
+	require.NoError(t, os.WriteFile(plain, []byte("DUCK"), 0600))
+
+	for _, path := range []string{plain, filepath.Join(dir, "missing.duckdb")} {
+		encrypted, err := IsEncrypted(path)
+		require.NoError(t, err)
+		assert.False(t, encrypted, path)
+	}
+}
@@ -0,0 +1,70 @@
+package encryption
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WorkingCopy is a decrypted copy of an encrypted file, in a private
+// temporary directory, for DuckDB to open. The plaintext only exists while
+// the copy is open.
+type WorkingCopy struct {
+	path    string // Encrypted file
+	dir     string
+	plain   string
+	key     []byte
+	size    int64
+	modTime time.Time
+}
+
+// Open decrypts a file into a working copy. A missing file gives an empty
+// copy, encrypted to path on Close once DuckDB has created it.
+func Open(path string, key []byte) (*WorkingCopy, error) {
+	dir, err := os.MkdirTemp("", "dataql-decrypted-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create working directory: %w", err)
+	}
+	w := &WorkingCopy{path: path, dir: dir, plain: filepath.Join(dir, filepath.Base(path)), key: key}
+
+	if _, err := os.Stat(path); err == nil {
+		if err := DecryptFile(path, w.plain, key); err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+		}
+		info, err := os.Stat(w.plain)
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, err
+		}
+		w.size, w.modTime = info.Size(), info.ModTime()
+	}
+	return w, nil
+}
+
+// Path returns the path of the plaintext copy
+func (w *WorkingCopy) Path() string {
+	return w.plain
+}
+
+// Close encrypts the copy back when it changed, then removes it. It must be
+// called once DuckDB has closed the copy. If encryption fails the copy is
+// kept, and the error names it, so no data is lost.
+func (w *WorkingCopy) Close() error {
+	info, err := os.Stat(w.plain)
+	if err == nil && (info.Size() != w.size || !info.ModTime().Equal(w.modTime)) {
+		if _, err := os.Stat(w.plain + ".wal"); err == nil {
+			return fmt.Errorf("%s was not closed cleanly; its decrypted copy is kept in %s", w.path, w.dir)
+		}
+		if err := EncryptFile(w.plain, w.path, w.key); err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w; its decrypted copy is kept in %s", w.path, err, w.dir)
+		}
+	}
+	return w.Discard()
+}
+
+// Discard removes the copy without encrypting it back
+func (w *WorkingCopy) Discard() error {
+	return os.RemoveAll(w.dir)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/schollz/progressbar/v3"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 const (
@@ -20,6 +21,8 @@ type csvExport struct {
 	file       *os.File
 	exportPath string
 	columns    []string
+	types      []string
+	location   *time.Location
 }
 
 func NewCsvExport(rows *sql.Rows, exportPath string, bar *progressbar.ProgressBar) exportdata.Export {
@@ -27,30 +30,32 @@ func NewCsvExport(rows *sql.Rows, exportPath string, bar *progressbar.ProgressBa
 }
 
 // Export rows in file
-func (c *csvExport) Export() error {
+func (c *csvExport) Export() (int64, error) {
 	if err := c.loadColumns(); err != nil {
-		return fmt.Errorf("failed to load columns: %w", err)
+		return 0, fmt.Errorf("failed to load columns: %w", err)
 	}
 
 	if err := c.openFile(); err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return 0, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	w := csv.NewWriter(c.file)
 	defer w.Flush()
 
 	if err := w.Write(c.columns); err != nil {
-		return fmt.Errorf("failed to write headers: %w", err)
+		return 0, fmt.Errorf("failed to write headers: %w", err)
 	}
 
+	var written int64
 	for c.rows.Next() {
 		_ = c.bar.Add(1)
+		written++
 		if err := c.readAndAppendFile(w); err != nil {
-			return fmt.Errorf("failed to read and append line in file: %w", err)
+			return 0, fmt.Errorf("failed to read and append line in file: %w", err)
 		}
 	}
 
-	return nil
+	return written, nil
 }
 
 // readAndAppendFile read line and append in file
@@ -75,17 +80,22 @@ func (c *csvExport) readAndAppendFile(w *csv.Writer) error {
 // convertToStringArray converts interface array to string array
 func (c *csvExport) convertToStringArray(records []interface{}) []string {
 	values := make([]string, 0, len(records))
-	for _, r := range records {
+	for i, r := range records {
 		if r == nil {
 			values = append(values, "")
 		} else {
-			values = append(values, fmt.Sprintf("%v", r))
+			values = append(values, fmt.Sprintf("%v", exportdata.TimeValue(exportdata.ExactValue(r, false), c.types[i], c.location)))
 		}
 	}
 
 	return values
 }
 
+// SetTimeZone writes TIMESTAMPTZ values in location instead of UTC
+func (c *csvExport) SetTimeZone(location *time.Location) {
+	c.location = location
+}
+
 // Close execute in defer
 func (c *csvExport) Close() error {
 	defer func(file *os.File) {
@@ -127,5 +137,11 @@ func (c *csvExport) loadColumns() error {
 
 	c.columns = columns
 
+	types, err := exportdata.ColumnTypes(c.rows)
+	if err != nil {
+		return err
+	}
+	c.types = types
+
 	return nil
 }
@@ -48,8 +48,9 @@ func TestCsvExport_Export_Success(t *testing.T) {
 	exporter := csvExport.NewCsvExport(rows, exportPath, bar)
 	defer exporter.Close()
 
-	err = exporter.Export()
+	written, err := exporter.Export()
 	assert.NoError(t, err)
+	assert.Equal(t, int64(2), written)
 
 	// Verify exported file
 	file, err := os.Open(exportPath)
@@ -92,7 +93,7 @@ func TestCsvExport_Export_EmptyResult(t *testing.T) {
 	exporter := csvExport.NewCsvExport(rows, exportPath, bar)
 	defer exporter.Close()
 
-	err = exporter.Export()
+	_, err = exporter.Export()
 	assert.NoError(t, err)
 
 	// Verify file has only headers
@@ -134,7 +135,7 @@ func TestCsvExport_Export_OverwritesExistingFile(t *testing.T) {
 	exporter := csvExport.NewCsvExport(rows, exportPath, bar)
 	defer exporter.Close()
 
-	err = exporter.Export()
+	_, err = exporter.Export()
 	assert.NoError(t, err)
 
 	// Verify old content is replaced
@@ -173,7 +174,7 @@ func TestCsvExport_Export_CreatesDirectory(t *testing.T) {
 	exporter := csvExport.NewCsvExport(rows, exportPath, bar)
 	defer exporter.Close()
 
-	err = exporter.Export()
+	_, err = exporter.Export()
 	assert.NoError(t, err)
 
 	// Verify file was created
@@ -210,7 +211,7 @@ func TestCsvExport_Export_WithQuery(t *testing.T) {
 	exporter := csvExport.NewCsvExport(rows, exportPath, bar)
 	defer exporter.Close()
 
-	err = exporter.Export()
+	_, err = exporter.Export()
 	assert.NoError(t, err)
 
 	file, err := os.Open(exportPath)
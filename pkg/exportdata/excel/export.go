@@ -2,11 +2,16 @@ package excel
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/adrianolaselva/dataql/pkg/exportdata"
+	"github.com/adrianolaselva/dataql/pkg/lineage"
 	"github.com/schollz/progressbar/v3"
 	"github.com/xuri/excelize/v2"
 )
@@ -16,6 +21,9 @@ type excelExport struct {
 	bar        *progressbar.ProgressBar
 	exportPath string
 	columns    []string
+	types      []string
+	location   *time.Location
+	lineage    *lineage.Lineage
 }
 
 // NewExcelExport creates a new Excel exporter
@@ -24,9 +32,9 @@ func NewExcelExport(rows *sql.Rows, exportPath string, bar *progressbar.Progress
 }
 
 // Export exports rows to an Excel file
-func (e *excelExport) Export() error {
+func (e *excelExport) Export() (int64, error) {
 	if err := e.loadColumns(); err != nil {
-		return fmt.Errorf("failed to load columns: %w", err)
+		return 0, fmt.Errorf("failed to load columns: %w", err)
 	}
 
 	f := excelize.NewFile()
@@ -35,7 +43,7 @@ func (e *excelExport) Export() error {
 	sheetName := "Sheet1"
 	index, err := f.NewSheet(sheetName)
 	if err != nil {
-		return fmt.Errorf("failed to create sheet: %w", err)
+		return 0, fmt.Errorf("failed to create sheet: %w", err)
 	}
 	f.SetActiveSheet(index)
 
@@ -43,7 +51,7 @@ func (e *excelExport) Export() error {
 	for i, col := range e.columns {
 		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
 		if err := f.SetCellValue(sheetName, cell, col); err != nil {
-			return fmt.Errorf("failed to write header cell: %w", err)
+			return 0, fmt.Errorf("failed to write header cell: %w", err)
 		}
 	}
 
@@ -60,8 +68,10 @@ func (e *excelExport) Export() error {
 
 	// Write data rows
 	rowNum := 2
+	var written int64
 	for e.rows.Next() {
 		_ = e.bar.Add(1)
+		written++
 
 		values := make([]interface{}, len(e.columns))
 		pointers := make([]interface{}, len(e.columns))
@@ -70,13 +80,13 @@ func (e *excelExport) Export() error {
 		}
 
 		if err := e.rows.Scan(pointers...); err != nil {
-			return fmt.Errorf("failed to scan row: %w", err)
+			return 0, fmt.Errorf("failed to scan row: %w", err)
 		}
 
 		for i, val := range values {
 			cell, _ := excelize.CoordinatesToCellName(i+1, rowNum)
-			if err := f.SetCellValue(sheetName, cell, val); err != nil {
-				return fmt.Errorf("failed to write cell: %w", err)
+			if err := f.SetCellValue(sheetName, cell, excelValue(val, e.types[i], e.location)); err != nil {
+				return 0, fmt.Errorf("failed to write cell: %w", err)
 			}
 		}
 		rowNum++
@@ -88,24 +98,49 @@ func (e *excelExport) Export() error {
 		_ = f.SetColWidth(sheetName, col, col, 15)
 	}
 
+	// The lineage goes in the document properties
+	if e.lineage != nil {
+		description, err := e.lineage.JSON()
+		if err != nil {
+			return 0, err
+		}
+		if err := f.SetDocProps(&excelize.DocProperties{
+			Creator:     "dataql " + e.lineage.Version,
+			Created:     e.lineage.CreatedAt.Format(time.RFC3339),
+			Description: description,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to set document properties: %w", err)
+		}
+	}
+
 	// Ensure directory exists
 	if err := os.MkdirAll(filepath.Dir(e.exportPath), os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create path: %w", err)
+		return 0, fmt.Errorf("failed to create path: %w", err)
 	}
 
 	// Remove existing file if exists
 	if _, err := os.Stat(e.exportPath); !os.IsNotExist(err) {
 		if err := os.Remove(e.exportPath); err != nil {
-			return fmt.Errorf("failed to remove existing file: %w", err)
+			return 0, fmt.Errorf("failed to remove existing file: %w", err)
 		}
 	}
 
 	// Save the file
 	if err := f.SaveAs(e.exportPath); err != nil {
-		return fmt.Errorf("failed to save Excel file: %w", err)
+		return 0, fmt.Errorf("failed to save Excel file: %w", err)
 	}
 
-	return nil
+	return written, nil
+}
+
+// SetLineage stores the lineage in the document properties of the file
+func (e *excelExport) SetLineage(l *lineage.Lineage) {
+	e.lineage = l
+}
+
+// SetTimeZone writes TIMESTAMPTZ values in location instead of UTC
+func (e *excelExport) SetTimeZone(location *time.Location) {
+	e.location = location
 }
 
 // Close execute in defer
@@ -122,5 +157,37 @@ func (e *excelExport) loadColumns() error {
 
 	e.columns = columns
 
+	types, err := exportdata.ColumnTypes(e.rows)
+	if err != nil {
+		return err
+	}
+	e.types = types
+
 	return nil
 }
+
+// excelValue writes DECIMAL and HUGEINT values as numbers when a double, the
+// only number type of Excel, holds them exactly, and as text otherwise.
+// Excel dates have no time zone: TIMESTAMPTZ values get the wall clock time
+// of location.
+func excelValue(value interface{}, dbType string, location *time.Location) interface{} {
+	if t, ok := value.(time.Time); ok && exportdata.IsTimestampTZ(dbType) && location != nil {
+		local := t.In(location)
+		return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+	}
+
+	number, ok := exportdata.ExactValue(value, false).(json.Number)
+	if !ok {
+		return value
+	}
+	f, err := number.Float64()
+	if err != nil {
+		return number.String()
+	}
+	want, _ := new(big.Rat).SetString(number.String())
+	got, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	if want == nil || got == nil || want.Cmp(got) != 0 {
+		return number.String()
+	}
+	return f
+}
@@ -6,6 +6,7 @@ import (
 	"html"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/adrianolaselva/dataql/pkg/exportdata"
 	"github.com/schollz/progressbar/v3"
@@ -21,6 +22,8 @@ type htmlExport struct {
 	file       *os.File
 	exportPath string
 	columns    []string
+	types      []string
+	location   *time.Location
 }
 
 // NewHTMLExport creates a new HTML table exporter
@@ -29,44 +32,46 @@ func NewHTMLExport(rows *sql.Rows, exportPath string, bar *progressbar.ProgressB
 }
 
 // Export exports rows to an HTML table format
-func (h *htmlExport) Export() error {
+func (h *htmlExport) Export() (int64, error) {
 	if err := h.loadColumns(); err != nil {
-		return fmt.Errorf("failed to load columns: %w", err)
+		return 0, fmt.Errorf("failed to load columns: %w", err)
 	}
 
 	if err := h.openFile(); err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return 0, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	// Write HTML document start and table header
 	if err := h.writeDocumentStart(); err != nil {
-		return fmt.Errorf("failed to write document start: %w", err)
+		return 0, fmt.Errorf("failed to write document start: %w", err)
 	}
 
 	// Write table header row
 	if err := h.writeTableHeader(); err != nil {
-		return fmt.Errorf("failed to write table header: %w", err)
+		return 0, fmt.Errorf("failed to write table header: %w", err)
 	}
 
 	// Write tbody start
 	if _, err := h.file.WriteString("  <tbody>\n"); err != nil {
-		return fmt.Errorf("failed to write tbody start: %w", err)
+		return 0, fmt.Errorf("failed to write tbody start: %w", err)
 	}
 
 	// Write data rows
+	var written int64
 	for h.rows.Next() {
 		_ = h.bar.Add(1)
+		written++
 		if err := h.writeDataRow(); err != nil {
-			return fmt.Errorf("failed to write data row: %w", err)
+			return 0, fmt.Errorf("failed to write data row: %w", err)
 		}
 	}
 
 	// Write document end
 	if err := h.writeDocumentEnd(); err != nil {
-		return fmt.Errorf("failed to write document end: %w", err)
+		return 0, fmt.Errorf("failed to write document end: %w", err)
 	}
 
-	return nil
+	return written, nil
 }
 
 // writeDocumentStart writes the HTML document header and table start
@@ -129,12 +134,12 @@ func (h *htmlExport) writeDataRow() error {
 		return err
 	}
 
-	for _, v := range values {
+	for i, v := range values {
 		var cellValue string
 		if v == nil {
 			cellValue = ""
 		} else {
-			cellValue = html.EscapeString(fmt.Sprintf("%v", v))
+			cellValue = html.EscapeString(fmt.Sprintf("%v", exportdata.TimeValue(exportdata.ExactValue(v, false), h.types[i], h.location)))
 		}
 		if _, err := h.file.WriteString(fmt.Sprintf("      <td>%s</td>\n", cellValue)); err != nil {
 			return err
@@ -161,6 +166,11 @@ func (h *htmlExport) writeDocumentEnd() error {
 	return nil
 }
 
+// SetTimeZone writes TIMESTAMPTZ values in location instead of UTC
+func (h *htmlExport) SetTimeZone(location *time.Location) {
+	h.location = location
+}
+
 // Close closes the file
 func (h *htmlExport) Close() error {
 	if h.file != nil {
@@ -197,5 +207,11 @@ func (h *htmlExport) loadColumns() error {
 		return fmt.Errorf("failed to load columns: %w", err)
 	}
 	h.columns = columns
+
+	types, err := exportdata.ColumnTypes(h.rows)
+	if err != nil {
+		return err
+	}
+	h.types = types
 	return nil
 }
@@ -49,7 +49,7 @@ func TestHTMLExport_BasicTable(t *testing.T) {
 	exporter := NewHTMLExport(rows, exportPath, bar)
 
 	// Export
-	if err := exporter.Export(); err != nil {
+	if _, err := exporter.Export(); err != nil {
 		t.Fatalf("Export failed: %v", err)
 	}
 
@@ -140,7 +140,7 @@ func TestHTMLExport_EscapeHTMLCharacters(t *testing.T) {
 	bar := progressbar.NewOptions(0, progressbar.OptionSetWriter(io.Discard))
 	exporter := NewHTMLExport(rows, exportPath, bar)
 
-	if err := exporter.Export(); err != nil {
+	if _, err := exporter.Export(); err != nil {
 		t.Fatalf("Export failed: %v", err)
 	}
 	exporter.Close()
@@ -191,7 +191,7 @@ func TestHTMLExport_NullValues(t *testing.T) {
 	bar := progressbar.NewOptions(0, progressbar.OptionSetWriter(io.Discard))
 	exporter := NewHTMLExport(rows, exportPath, bar)
 
-	if err := exporter.Export(); err != nil {
+	if _, err := exporter.Export(); err != nil {
 		t.Fatalf("Export failed: %v", err)
 	}
 	exporter.Close()
@@ -236,7 +236,7 @@ func TestHTMLExport_HasStyles(t *testing.T) {
 	bar := progressbar.NewOptions(0, progressbar.OptionSetWriter(io.Discard))
 	exporter := NewHTMLExport(rows, exportPath, bar)
 
-	if err := exporter.Export(); err != nil {
+	if _, err := exporter.Export(); err != nil {
 		t.Fatalf("Export failed: %v", err)
 	}
 	exporter.Close()
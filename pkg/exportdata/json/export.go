@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/adrianolaselva/dataql/pkg/exportdata"
 	"github.com/schollz/progressbar/v3"
@@ -21,7 +22,11 @@ type jsonExport struct {
 	file       *os.File
 	exportPath string
 	columns    []string
+	types      []string
+	location   *time.Location
 	data       []map[string]interface{}
+
+	decimalAsString bool
 }
 
 // NewJsonExport creates a new JSON exporter
@@ -30,25 +35,37 @@ func NewJsonExport(rows *sql.Rows, exportPath string, bar *progressbar.ProgressB
 }
 
 // Export exports rows to a JSON array file
-func (j *jsonExport) Export() error {
+func (j *jsonExport) Export() (int64, error) {
 	if err := j.loadColumns(); err != nil {
-		return fmt.Errorf("failed to load columns: %w", err)
+		return 0, fmt.Errorf("failed to load columns: %w", err)
 	}
 
 	// Read all rows into memory
+	var written int64
 	for j.rows.Next() {
 		_ = j.bar.Add(1)
+		written++
 		if err := j.readRow(); err != nil {
-			return fmt.Errorf("failed to read row: %w", err)
+			return 0, fmt.Errorf("failed to read row: %w", err)
 		}
 	}
 
 	// Write the JSON array to file
 	if err := j.writeFile(); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+		return 0, fmt.Errorf("failed to write file: %w", err)
 	}
 
-	return nil
+	return written, nil
+}
+
+// SetDecimalAsString writes DECIMAL and HUGEINT values as strings
+func (j *jsonExport) SetDecimalAsString(asString bool) {
+	j.decimalAsString = asString
+}
+
+// SetTimeZone writes TIMESTAMPTZ values in location instead of UTC
+func (j *jsonExport) SetTimeZone(location *time.Location) {
+	j.location = location
 }
 
 // Close execute in defer
@@ -73,7 +90,7 @@ func (j *jsonExport) readRow() error {
 
 	row := make(map[string]interface{})
 	for i, c := range j.columns {
-		row[c] = values[i]
+		row[c] = exportdata.TimeValue(exportdata.ExactValue(values[i], j.decimalAsString), j.types[i], j.location)
 	}
 
 	j.data = append(j.data, row)
@@ -119,5 +136,11 @@ func (j *jsonExport) loadColumns() error {
 
 	j.columns = columns
 
+	types, err := exportdata.ColumnTypes(j.rows)
+	if err != nil {
+		return err
+	}
+	j.types = types
+
 	return nil
 }
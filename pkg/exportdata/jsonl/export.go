@@ -9,6 +9,7 @@ import (
 	"github.com/schollz/progressbar/v3"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 const (
@@ -21,6 +22,10 @@ type jsonlExport struct {
 	file       *os.File
 	exportPath string
 	columns    []string
+	types      []string
+	location   *time.Location
+
+	decimalAsString bool
 }
 
 func NewJsonlExport(rows *sql.Rows, exportPath string, bar *progressbar.ProgressBar) exportdata.Export {
@@ -28,23 +33,30 @@ func NewJsonlExport(rows *sql.Rows, exportPath string, bar *progressbar.Progress
 }
 
 // Export rows in file
-func (j *jsonlExport) Export() error {
+func (j *jsonlExport) Export() (int64, error) {
 	if err := j.loadColumns(); err != nil {
-		return fmt.Errorf("failed to load columns: %w", err)
+		return 0, fmt.Errorf("failed to load columns: %w", err)
 	}
 
 	if err := j.openFile(); err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return 0, fmt.Errorf("failed to open file: %w", err)
 	}
 
+	var written int64
 	for j.rows.Next() {
 		_ = j.bar.Add(1)
+		written++
 		if err := j.readAndAppendFile(); err != nil {
-			return fmt.Errorf("failed to read and append line in file: %w", err)
+			return 0, fmt.Errorf("failed to read and append line in file: %w", err)
 		}
 	}
 
-	return nil
+	return written, nil
+}
+
+// SetTimeZone writes TIMESTAMPTZ values in location instead of UTC
+func (j *jsonlExport) SetTimeZone(location *time.Location) {
+	j.location = location
 }
 
 // Close execute in defer
@@ -56,6 +68,11 @@ func (j *jsonlExport) Close() error {
 	return nil
 }
 
+// SetDecimalAsString writes DECIMAL and HUGEINT values as strings
+func (j *jsonlExport) SetDecimalAsString(asString bool) {
+	j.decimalAsString = asString
+}
+
 // readAndAppendFile read line and append in file
 func (j *jsonlExport) readAndAppendFile() error {
 	values := make([]interface{}, len(j.columns))
@@ -70,7 +87,7 @@ func (j *jsonlExport) readAndAppendFile() error {
 
 	attr := map[string]interface{}{}
 	for i, c := range j.columns {
-		attr[c] = values[i]
+		attr[c] = exportdata.TimeValue(exportdata.ExactValue(values[i], j.decimalAsString), j.types[i], j.location)
 	}
 
 	payload, err := json.Marshal(attr)
@@ -122,5 +139,11 @@ func (j *jsonlExport) loadColumns() error {
 
 	j.columns = columns
 
+	types, err := exportdata.ColumnTypes(j.rows)
+	if err != nil {
+		return err
+	}
+	j.types = types
+
 	return nil
 }
@@ -49,8 +49,9 @@ func TestJsonlExport_Export_Success(t *testing.T) {
 	exporter := jsonl.NewJsonlExport(rows, exportPath, bar)
 	defer exporter.Close()
 
-	err = exporter.Export()
+	written, err := exporter.Export()
 	assert.NoError(t, err)
+	assert.Equal(t, int64(2), written)
 
 	// Verify exported file
 	file, err := os.Open(exportPath)
@@ -97,7 +98,7 @@ func TestJsonlExport_Export_EmptyResult(t *testing.T) {
 	exporter := jsonl.NewJsonlExport(rows, exportPath, bar)
 	defer exporter.Close()
 
-	err = exporter.Export()
+	_, err = exporter.Export()
 	assert.NoError(t, err)
 
 	// Verify file is empty
@@ -132,7 +133,7 @@ func TestJsonlExport_Export_OverwritesExistingFile(t *testing.T) {
 	exporter := jsonl.NewJsonlExport(rows, exportPath, bar)
 	defer exporter.Close()
 
-	err = exporter.Export()
+	_, err = exporter.Export()
 	assert.NoError(t, err)
 
 	// Verify old content is replaced
@@ -164,7 +165,7 @@ func TestJsonlExport_Export_CreatesDirectory(t *testing.T) {
 	exporter := jsonl.NewJsonlExport(rows, exportPath, bar)
 	defer exporter.Close()
 
-	err = exporter.Export()
+	_, err = exporter.Export()
 	assert.NoError(t, err)
 
 	// Verify file was created
@@ -196,7 +197,7 @@ func TestJsonlExport_Export_SpecialCharacters(t *testing.T) {
 	exporter := jsonl.NewJsonlExport(rows, exportPath, bar)
 	defer exporter.Close()
 
-	err = exporter.Export()
+	_, err = exporter.Export()
 	assert.NoError(t, err)
 
 	// Verify JSON encoding handles special characters
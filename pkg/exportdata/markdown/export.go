@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/adrianolaselva/dataql/pkg/exportdata"
 	"github.com/schollz/progressbar/v3"
@@ -21,6 +22,8 @@ type markdownExport struct {
 	file       *os.File
 	exportPath string
 	columns    []string
+	types      []string
+	location   *time.Location
 }
 
 // NewMarkdownExport creates a new Markdown table exporter
@@ -29,34 +32,36 @@ func NewMarkdownExport(rows *sql.Rows, exportPath string, bar *progressbar.Progr
 }
 
 // Export exports rows to a Markdown table format
-func (m *markdownExport) Export() error {
+func (m *markdownExport) Export() (int64, error) {
 	if err := m.loadColumns(); err != nil {
-		return fmt.Errorf("failed to load columns: %w", err)
+		return 0, fmt.Errorf("failed to load columns: %w", err)
 	}
 
 	if err := m.openFile(); err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return 0, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	// Write header row
 	if err := m.writeHeader(); err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
+		return 0, fmt.Errorf("failed to write header: %w", err)
 	}
 
 	// Write separator row
 	if err := m.writeSeparator(); err != nil {
-		return fmt.Errorf("failed to write separator: %w", err)
+		return 0, fmt.Errorf("failed to write separator: %w", err)
 	}
 
 	// Write data rows
+	var written int64
 	for m.rows.Next() {
 		_ = m.bar.Add(1)
+		written++
 		if err := m.writeDataRow(); err != nil {
-			return fmt.Errorf("failed to write data row: %w", err)
+			return 0, fmt.Errorf("failed to write data row: %w", err)
 		}
 	}
 
-	return nil
+	return written, nil
 }
 
 // writeHeader writes the Markdown table header row
@@ -99,7 +104,7 @@ func (m *markdownExport) writeDataRow() error {
 		if v == nil {
 			stringValues[i] = ""
 		} else {
-			str := fmt.Sprintf("%v", v)
+			str := fmt.Sprintf("%v", exportdata.TimeValue(exportdata.ExactValue(v, false), m.types[i], m.location))
 			// Escape pipe characters in values
 			str = strings.ReplaceAll(str, "|", "\\|")
 			stringValues[i] = str
@@ -114,6 +119,11 @@ func (m *markdownExport) writeDataRow() error {
 	return nil
 }
 
+// SetTimeZone writes TIMESTAMPTZ values in location instead of UTC
+func (m *markdownExport) SetTimeZone(location *time.Location) {
+	m.location = location
+}
+
 // Close closes the file
 func (m *markdownExport) Close() error {
 	if m.file != nil {
@@ -150,5 +160,11 @@ func (m *markdownExport) loadColumns() error {
 		return fmt.Errorf("failed to load columns: %w", err)
 	}
 	m.columns = columns
+
+	types, err := exportdata.ColumnTypes(m.rows)
+	if err != nil {
+		return err
+	}
+	m.types = types
 	return nil
 }
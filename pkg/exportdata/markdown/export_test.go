@@ -49,7 +49,7 @@ func TestMarkdownExport_BasicTable(t *testing.T) {
 	exporter := NewMarkdownExport(rows, exportPath, bar)
 
 	// Export
-	if err := exporter.Export(); err != nil {
+	if _, err := exporter.Export(); err != nil {
 		t.Fatalf("Export failed: %v", err)
 	}
 
@@ -115,7 +115,7 @@ func TestMarkdownExport_EscapePipeCharacters(t *testing.T) {
 	bar := progressbar.NewOptions(0, progressbar.OptionSetWriter(io.Discard))
 	exporter := NewMarkdownExport(rows, exportPath, bar)
 
-	if err := exporter.Export(); err != nil {
+	if _, err := exporter.Export(); err != nil {
 		t.Fatalf("Export failed: %v", err)
 	}
 	exporter.Close()
@@ -160,7 +160,7 @@ func TestMarkdownExport_NullValues(t *testing.T) {
 	bar := progressbar.NewOptions(0, progressbar.OptionSetWriter(io.Discard))
 	exporter := NewMarkdownExport(rows, exportPath, bar)
 
-	if err := exporter.Export(); err != nil {
+	if _, err := exporter.Export(); err != nil {
 		t.Fatalf("Export failed: %v", err)
 	}
 	exporter.Close()
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/adrianolaselva/dataql/pkg/exportdata"
+	"github.com/adrianolaselva/dataql/pkg/lineage"
 	"github.com/schollz/progressbar/v3"
 	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
 	"github.com/xitongsys/parquet-go/writer"
 )
 
@@ -17,6 +20,9 @@ type parquetExport struct {
 	bar        *progressbar.ProgressBar
 	exportPath string
 	columns    []string
+	types      []string
+	location   *time.Location
+	lineage    *lineage.Lineage
 }
 
 // NewParquetExport creates a new Parquet exporter
@@ -25,27 +31,27 @@ func NewParquetExport(rows *sql.Rows, exportPath string, bar *progressbar.Progre
 }
 
 // Export exports rows to a Parquet file
-func (p *parquetExport) Export() error {
+func (p *parquetExport) Export() (int64, error) {
 	if err := p.loadColumns(); err != nil {
-		return fmt.Errorf("failed to load columns: %w", err)
+		return 0, fmt.Errorf("failed to load columns: %w", err)
 	}
 
 	// Ensure directory exists
 	if err := os.MkdirAll(filepath.Dir(p.exportPath), os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create path: %w", err)
+		return 0, fmt.Errorf("failed to create path: %w", err)
 	}
 
 	// Remove existing file if exists
 	if _, err := os.Stat(p.exportPath); !os.IsNotExist(err) {
 		if err := os.Remove(p.exportPath); err != nil {
-			return fmt.Errorf("failed to remove existing file: %w", err)
+			return 0, fmt.Errorf("failed to remove existing file: %w", err)
 		}
 	}
 
 	// Create local file writer
 	fw, err := local.NewLocalFileWriter(p.exportPath)
 	if err != nil {
-		return fmt.Errorf("failed to create file writer: %w", err)
+		return 0, fmt.Errorf("failed to create file writer: %w", err)
 	}
 	defer fw.Close()
 
@@ -59,12 +65,14 @@ func (p *parquetExport) Export() error {
 	// Create CSV writer for Parquet - handles dynamic schemas better
 	pw, err := writer.NewCSVWriter(schemaCols, fw, 4)
 	if err != nil {
-		return fmt.Errorf("failed to create Parquet writer: %w", err)
+		return 0, fmt.Errorf("failed to create Parquet writer: %w", err)
 	}
 
 	// Write rows
+	var written int64
 	for p.rows.Next() {
 		_ = p.bar.Add(1)
+		written++
 
 		values := make([]interface{}, len(p.columns))
 		pointers := make([]interface{}, len(p.columns))
@@ -73,7 +81,7 @@ func (p *parquetExport) Export() error {
 		}
 
 		if err := p.rows.Scan(pointers...); err != nil {
-			return fmt.Errorf("failed to scan row: %w", err)
+			return 0, fmt.Errorf("failed to scan row: %w", err)
 		}
 
 		// Convert to string slice for CSV writer
@@ -83,21 +91,39 @@ func (p *parquetExport) Export() error {
 				empty := ""
 				row[i] = &empty
 			} else {
-				s := fmt.Sprintf("%v", values[i])
+				s := fmt.Sprintf("%v", exportdata.TimeValue(exportdata.ExactValue(values[i], false), p.types[i], p.location))
 				row[i] = &s
 			}
 		}
 
 		if err := pw.WriteString(row); err != nil {
-			return fmt.Errorf("failed to write row: %w", err)
+			return 0, fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	if p.lineage != nil {
+		value, err := p.lineage.JSON()
+		if err != nil {
+			return 0, err
 		}
+		pw.Footer.KeyValueMetadata = append(pw.Footer.KeyValueMetadata, &parquet.KeyValue{Key: lineage.MetadataKey, Value: &value})
 	}
 
 	if err := pw.WriteStop(); err != nil {
-		return fmt.Errorf("failed to finalize Parquet file: %w", err)
+		return 0, fmt.Errorf("failed to finalize Parquet file: %w", err)
 	}
 
-	return nil
+	return written, nil
+}
+
+// SetLineage stores the lineage in the key-value metadata of the file
+func (p *parquetExport) SetLineage(l *lineage.Lineage) {
+	p.lineage = l
+}
+
+// SetTimeZone writes TIMESTAMPTZ values in location instead of UTC
+func (p *parquetExport) SetTimeZone(location *time.Location) {
+	p.location = location
 }
 
 // Close execute in defer
@@ -120,6 +146,12 @@ func (p *parquetExport) loadColumns() error {
 
 	p.columns = sanitized
 
+	types, err := exportdata.ColumnTypes(p.rows)
+	if err != nil {
+		return err
+	}
+	p.types = types
+
 	return nil
 }
 
@@ -1,6 +1,35 @@
 package exportdata
 
+import (
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/lineage"
+)
+
+// Export writes query results to a file. Export returns the number of rows
+// written.
 type Export interface {
-	Export() error
+	Export() (int64, error)
 	Close() error
 }
+
+// LineageExport is an optional interface for exporters whose file format
+// can embed lineage metadata; other exports get a JSON sidecar instead
+type LineageExport interface {
+	Export
+	SetLineage(l *lineage.Lineage)
+}
+
+// DecimalExport is an optional interface for exporters writing typed
+// numbers, which can write DECIMAL and HUGEINT values as strings instead
+type DecimalExport interface {
+	Export
+	SetDecimalAsString(asString bool)
+}
+
+// TimeZoneExport is an optional interface for exporters writing dates and
+// timestamps, which can write TIMESTAMPTZ values in a time zone other than UTC
+type TimeZoneExport interface {
+	Export
+	SetTimeZone(location *time.Location)
+}
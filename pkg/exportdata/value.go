@@ -0,0 +1,110 @@
+package exportdata
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/marcboeker/go-duckdb"
+)
+
+// ExactValue converts the values DuckDB returns for DECIMAL and HUGEINT
+// columns, duckdb.Decimal and *big.Int, into json.Number holding their exact
+// digits: it prints as the number and encoding/json writes it as a number,
+// so no value goes through float64. Values nested in STRUCT, LIST and MAP
+// columns are converted too. With decimalAsString the numbers become strings,
+// for consumers that read JSON numbers as doubles.
+func ExactValue(value any, decimalAsString bool) any {
+	var number string
+	switch v := value.(type) {
+	case json.Number:
+		number = v.String()
+	case duckdb.Decimal:
+		number = v.String()
+	case *big.Int:
+		if v == nil {
+			return nil
+		}
+		number = v.String()
+	case duckdb.Map:
+		m := make(map[string]any, len(v))
+		for key, item := range v {
+			m[fmt.Sprintf("%v", key)] = ExactValue(item, decimalAsString)
+		}
+		return m
+	case map[string]any:
+		for key, item := range v {
+			v[key] = ExactValue(item, decimalAsString)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = ExactValue(item, decimalAsString)
+		}
+		return v
+	default:
+		return value
+	}
+
+	if decimalAsString {
+		return number
+	}
+	return json.Number(number)
+}
+
+// Layouts of dates and timestamps in exports, ISO 8601 like DuckDB casts
+// them to text: naive timestamps have no UTC offset, so they are not
+// mistaken for UTC
+const (
+	DateLayout        = "2006-01-02"
+	TimeLayout        = "15:04:05.999999999"
+	TimestampLayout   = "2006-01-02T15:04:05.999999999"
+	TimestampTZLayout = "2006-01-02T15:04:05.999999999Z07:00"
+)
+
+// ColumnTypes returns the database type names of the columns of rows, such
+// as TIMESTAMPTZ or DECIMAL(10,2)
+func ColumnTypes(rows *sql.Rows) ([]string, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load column types: %w", err)
+	}
+	types := make([]string, len(columnTypes))
+	for i, columnType := range columnTypes {
+		types[i] = columnType.DatabaseTypeName()
+	}
+	return types, nil
+}
+
+// TimeValue formats the time.Time values the DuckDB driver returns for
+// DATE, TIME and TIMESTAMP columns, which hold wall clock times in UTC, with
+// the layout of dbType. TIMESTAMPTZ values are instants, written with their
+// UTC offset in location (UTC when nil). Other values are returned as is.
+func TimeValue(value any, dbType string, location *time.Location) any {
+	t, ok := value.(time.Time)
+	if !ok {
+		return value
+	}
+	switch {
+	case dbType == "DATE":
+		return t.Format(DateLayout)
+	case dbType == "TIME":
+		return t.Format(TimeLayout)
+	case IsTimestampTZ(dbType):
+		if location == nil {
+			location = time.UTC
+		}
+		return t.In(location).Format(TimestampTZLayout)
+	case strings.HasPrefix(dbType, "TIMESTAMP"):
+		return t.Format(TimestampLayout)
+	}
+	return value
+}
+
+// IsTimestampTZ reports whether dbType is TIMESTAMP WITH TIME ZONE
+func IsTimestampTZ(dbType string) bool {
+	return dbType == "TIMESTAMPTZ" || dbType == "TIMESTAMP WITH TIME ZONE"
+}
@@ -0,0 +1,41 @@
+package exportdata_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/exportdata"
+	"github.com/marcboeker/go-duckdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExactValue(t *testing.T) {
+	huge, _ := new(big.Int).SetString("12345678901234567890", 10)
+	decimal := duckdb.Decimal{Width: 20, Scale: 3, Value: big.NewInt(12345678901234567)}
+
+	assert.Equal(t, json.Number("12345678901234567890"), exportdata.ExactValue(huge, false))
+	assert.Equal(t, json.Number("12345678901234.567"), exportdata.ExactValue(decimal, false))
+	assert.Equal(t, "12345678901234567890", exportdata.ExactValue(huge, true))
+	assert.Equal(t, int64(7), exportdata.ExactValue(int64(7), false))
+	assert.Equal(t, "text", exportdata.ExactValue("text", true))
+
+	nested := exportdata.ExactValue(map[string]any{"total": decimal, "ids": []any{huge}}, false)
+	out, err := json.Marshal(nested)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"ids":[12345678901234567890],"total":12345678901234.567}`, string(out))
+}
+
+func TestTimeValue(t *testing.T) {
+	instant := time.Date(2024, 3, 10, 11, 30, 0, 500000000, time.UTC)
+	saoPaulo := time.FixedZone("-03:00", -3*3600)
+
+	assert.Equal(t, "2024-03-10T11:30:00.5", exportdata.TimeValue(instant, "TIMESTAMP", saoPaulo), "naive timestamps have no offset")
+	assert.Equal(t, "2024-03-10T11:30:00.5", exportdata.TimeValue(instant, "TIMESTAMP_NS", nil))
+	assert.Equal(t, "2024-03-10T11:30:00.5Z", exportdata.TimeValue(instant, "TIMESTAMPTZ", nil))
+	assert.Equal(t, "2024-03-10T08:30:00.5-03:00", exportdata.TimeValue(instant, "TIMESTAMPTZ", saoPaulo))
+	assert.Equal(t, "2024-03-10", exportdata.TimeValue(instant, "DATE", saoPaulo))
+	assert.Equal(t, "11:30:00.5", exportdata.TimeValue(instant, "TIME", nil))
+	assert.Equal(t, "text", exportdata.TimeValue("text", "TIMESTAMPTZ", saoPaulo))
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/adrianolaselva/dataql/pkg/exportdata"
 	"github.com/schollz/progressbar/v3"
@@ -39,6 +40,8 @@ type xmlExport struct {
 	file       *os.File
 	exportPath string
 	columns    []string
+	types      []string
+	location   *time.Location
 	data       Data
 }
 
@@ -53,25 +56,32 @@ func NewXmlExport(rows *sql.Rows, exportPath string, bar *progressbar.ProgressBa
 }
 
 // Export exports rows to an XML file
-func (x *xmlExport) Export() error {
+func (x *xmlExport) Export() (int64, error) {
 	if err := x.loadColumns(); err != nil {
-		return fmt.Errorf("failed to load columns: %w", err)
+		return 0, fmt.Errorf("failed to load columns: %w", err)
 	}
 
 	// Read all rows into memory
+	var written int64
 	for x.rows.Next() {
 		_ = x.bar.Add(1)
+		written++
 		if err := x.readRow(); err != nil {
-			return fmt.Errorf("failed to read row: %w", err)
+			return 0, fmt.Errorf("failed to read row: %w", err)
 		}
 	}
 
 	// Write the XML to file
 	if err := x.writeFile(); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+		return 0, fmt.Errorf("failed to write file: %w", err)
 	}
 
-	return nil
+	return written, nil
+}
+
+// SetTimeZone writes TIMESTAMPTZ values in location instead of UTC
+func (x *xmlExport) SetTimeZone(location *time.Location) {
+	x.location = location
 }
 
 // Close execute in defer
@@ -102,7 +112,7 @@ func (x *xmlExport) readRow() error {
 	for i, col := range x.columns {
 		value := ""
 		if values[i] != nil {
-			value = fmt.Sprintf("%v", values[i])
+			value = fmt.Sprintf("%v", exportdata.TimeValue(exportdata.ExactValue(values[i], false), x.types[i], x.location))
 		}
 		row.Fields[i] = Field{
 			XMLName: xml.Name{Local: col},
@@ -158,5 +168,11 @@ func (x *xmlExport) loadColumns() error {
 
 	x.columns = columns
 
+	types, err := exportdata.ColumnTypes(x.rows)
+	if err != nil {
+		return err
+	}
+	x.types = types
+
 	return nil
 }
@@ -2,9 +2,12 @@ package yaml
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/adrianolaselva/dataql/pkg/exportdata"
 	"github.com/schollz/progressbar/v3"
@@ -21,7 +24,11 @@ type yamlExport struct {
 	file       *os.File
 	exportPath string
 	columns    []string
+	types      []string
+	location   *time.Location
 	data       []map[string]interface{}
+
+	decimalAsString bool
 }
 
 // NewYamlExport creates a new YAML exporter
@@ -35,25 +42,32 @@ func NewYamlExport(rows *sql.Rows, exportPath string, bar *progressbar.ProgressB
 }
 
 // Export exports rows to a YAML file
-func (y *yamlExport) Export() error {
+func (y *yamlExport) Export() (int64, error) {
 	if err := y.loadColumns(); err != nil {
-		return fmt.Errorf("failed to load columns: %w", err)
+		return 0, fmt.Errorf("failed to load columns: %w", err)
 	}
 
 	// Read all rows into memory
+	var written int64
 	for y.rows.Next() {
 		_ = y.bar.Add(1)
+		written++
 		if err := y.readRow(); err != nil {
-			return fmt.Errorf("failed to read row: %w", err)
+			return 0, fmt.Errorf("failed to read row: %w", err)
 		}
 	}
 
 	// Write the YAML to file
 	if err := y.writeFile(); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+		return 0, fmt.Errorf("failed to write file: %w", err)
 	}
 
-	return nil
+	return written, nil
+}
+
+// SetTimeZone writes TIMESTAMPTZ values in location instead of UTC
+func (y *yamlExport) SetTimeZone(location *time.Location) {
+	y.location = location
 }
 
 // Close execute in defer
@@ -64,6 +78,33 @@ func (y *yamlExport) Close() error {
 	return nil
 }
 
+// SetDecimalAsString writes DECIMAL and HUGEINT values as strings
+func (y *yamlExport) SetDecimalAsString(asString bool) {
+	y.decimalAsString = asString
+}
+
+// yamlValue writes exact numbers as YAML numbers: yaml.v3 would convert
+// json.Number through float64
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		tag := "!!float"
+		if _, err := v.Int64(); err == nil || !strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!int"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = yamlValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = yamlValue(item)
+		}
+	}
+	return value
+}
+
 // readRow reads a row and appends it to the data slice
 func (y *yamlExport) readRow() error {
 	values := make([]interface{}, len(y.columns))
@@ -78,7 +119,7 @@ func (y *yamlExport) readRow() error {
 
 	row := make(map[string]interface{})
 	for i, c := range y.columns {
-		row[c] = values[i]
+		row[c] = yamlValue(exportdata.TimeValue(exportdata.ExactValue(values[i], y.decimalAsString), y.types[i], y.location))
 	}
 
 	y.data = append(y.data, row)
@@ -125,5 +166,11 @@ func (y *yamlExport) loadColumns() error {
 
 	y.columns = columns
 
+	types, err := exportdata.ColumnTypes(y.rows)
+	if err != nil {
+		return err
+	}
+	y.types = types
+
 	return nil
 }
@@ -0,0 +1,235 @@
+// Package extensions installs and loads DuckDB extensions, such as httpfs,
+// spatial, fts or iceberg, into the DuckDB engine bundled with dataql.
+//
+// Installed extensions are kept in DuckDB's extension directory
+// (~/.duckdb/extensions, or $DATAQL_EXTENSION_DIR) per DuckDB version and
+// platform, so an extension installed once is loaded by every session. For
+// machines without internet access, extensions can be installed from a local
+// .duckdb_extension file or a mirror of the extension repository, and
+// sessions can be kept from downloading the extensions DuckDB autoloads.
+package extensions
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// EnvDir overrides the directory extensions are installed to and loaded from
+const EnvDir = "DATAQL_EXTENSION_DIR"
+
+// fileSuffixes are the suffixes of extension files, compressed or not
+var fileSuffixes = []string{".duckdb_extension.gz", ".duckdb_extension"}
+
+// namePattern matches extension names
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Extension describes an extension known to the engine
+type Extension struct {
+	Name        string
+	Description string
+	Version     string
+	InstallMode string // STATICALLY_LINKED for the extensions built into dataql
+	InstallPath string
+	Installed   bool
+	Loaded      bool
+}
+
+// BuiltIn reports whether the extension is compiled into dataql
+func (e Extension) BuiltIn() bool {
+	return e.InstallMode == "STATICALLY_LINKED"
+}
+
+// InstallOptions configures Install
+type InstallOptions struct {
+	Repository string // Repository URL or local mirror directory (default: the DuckDB core repository)
+	Force      bool   // Install again an installed extension, e.g. to update it
+}
+
+// ValidateName checks an extension name, which is written into statements
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid extension name %q", name)
+	}
+	return nil
+}
+
+// IsFile reports whether source names an extension file rather than an
+// extension
+func IsFile(source string) bool {
+	for _, suffix := range fileSuffixes {
+		if strings.HasSuffix(source, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// nameOfFile returns the name of the extension in an extension file,
+// e.g. spatial for /tmp/spatial.duckdb_extension.gz
+func nameOfFile(path string) string {
+	base := filepath.Base(path)
+	for _, suffix := range fileSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return strings.TrimSuffix(base, suffix)
+		}
+	}
+	return base
+}
+
+// quote returns value as a SQL string literal
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// exec runs a statement that returns no rows of interest
+func exec(st storage.Storage, statement string) error {
+	rows, err := st.Query(statement)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// Configure points the engine at dir, or $DATAQL_EXTENSION_DIR when dir is
+// empty, and, when offline, stops DuckDB from downloading the extensions it
+// autoloads: only built-in and installed extensions are loaded then
+func Configure(st storage.Storage, dir string, offline bool) error {
+	if dir == "" {
+		dir = os.Getenv(EnvDir)
+	}
+	if dir != "" {
+		if err := exec(st, "SET GLOBAL extension_directory = "+quote(dir)); err != nil {
+			return fmt.Errorf("failed to set the extension directory: %w", err)
+		}
+	}
+	if offline {
+		if err := exec(st, "SET GLOBAL autoinstall_known_extensions = false"); err != nil {
+			return fmt.Errorf("failed to disable the installation of extensions: %w", err)
+		}
+	}
+	return nil
+}
+
+// installStatement returns the statement installing source, an extension
+// name or file
+func installStatement(source string, opts InstallOptions) (string, error) {
+	statement := "INSTALL "
+	if opts.Force {
+		statement = "FORCE INSTALL "
+	}
+
+	if IsFile(source) {
+		if opts.Repository != "" {
+			return "", fmt.Errorf("an extension file cannot be installed from a repository")
+		}
+		path, err := filepath.Abs(source)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("extension file %s not found: %w", source, err)
+		}
+		return statement + quote(path), nil
+	}
+
+	if err := ValidateName(source); err != nil {
+		return "", err
+	}
+	statement += source
+	if opts.Repository != "" {
+		statement += " FROM " + quote(opts.Repository)
+	}
+	return statement, nil
+}
+
+// Install installs an extension, given by name or as a .duckdb_extension
+// file, and loads it to check that it works with the bundled engine. It
+// returns the name of the extension.
+func Install(st storage.Storage, source string, opts InstallOptions) (string, error) {
+	statement, err := installStatement(source, opts)
+	if err != nil {
+		return "", err
+	}
+	name := source
+	if IsFile(source) {
+		name = nameOfFile(source)
+	}
+
+	if err := exec(st, statement); err != nil {
+		return "", fmt.Errorf("failed to install extension %s: %w", name, err)
+	}
+	if err := exec(st, "LOAD "+name); err != nil {
+		return "", fmt.Errorf("extension %s was installed but cannot be loaded: %w", name, err)
+	}
+	return name, nil
+}
+
+// Load loads extensions into the engine, installing the missing ones
+// unless offline
+func Load(st storage.Storage, names []string, offline bool) error {
+	for _, name := range names {
+		if err := ValidateName(name); err != nil {
+			return err
+		}
+		if !offline {
+			// A no-op for installed and built-in extensions
+			if err := exec(st, "INSTALL "+name); err != nil {
+				return fmt.Errorf("failed to install extension %s: %w", name, err)
+			}
+		}
+		if err := exec(st, "LOAD "+name); err != nil {
+			if offline {
+				return fmt.Errorf("failed to load extension %s (install it with dataql ext install %s): %w", name, name, err)
+			}
+			return fmt.Errorf("failed to load extension %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// List returns the extensions known to the engine, sorted by name
+func List(st storage.Storage) ([]Extension, error) {
+	rows, err := st.Query(`SELECT extension_name, loaded, installed, COALESCE(install_path, ''),
+		COALESCE(description, ''), COALESCE(extension_version, ''), COALESCE(CAST(install_mode AS VARCHAR), '')
+		FROM duckdb_extensions() ORDER BY extension_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list extensions: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var list []Extension
+	for rows.Next() {
+		var e Extension
+		if err := rows.Scan(&e.Name, &e.Loaded, &e.Installed, &e.InstallPath, &e.Description, &e.Version, &e.InstallMode); err != nil {
+			return nil, fmt.Errorf("failed to read extensions: %w", err)
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// Platform returns the version and platform of the engine, which extension
+// files must be built for, e.g. v1.1.3 and linux_amd64
+func Platform(st storage.Storage) (version, platform string, err error) {
+	rows, err := st.Query("SELECT library_version, platform FROM pragma_version(), pragma_platform()")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the DuckDB platform: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	if !rows.Next() {
+		return "", "", fmt.Errorf("failed to read the DuckDB platform: %w", rows.Err())
+	}
+	err = rows.Scan(&version, &platform)
+	return version, platform, err
+}
@@ -0,0 +1,72 @@
+package extensions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/storage/duckdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"httpfs", "spatial", "sqlite_scanner", "h3"} {
+		assert.NoError(t, ValidateName(name), name)
+	}
+	for _, name := range []string{"", "Spatial", "1fts", "fts; DROP TABLE t", "'json'"} {
+		assert.Error(t, ValidateName(name), name)
+	}
+}
+
+func TestInstallStatement(t *testing.T) {
+	statement, err := installStatement("spatial", InstallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "INSTALL spatial", statement)
+
+	statement, err = installStatement("spatial", InstallOptions{Repository: "/mnt/it's mirror", Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, "FORCE INSTALL spatial FROM '/mnt/it''s mirror'", statement)
+
+	file := filepath.Join(t.TempDir(), "fts.duckdb_extension.gz")
+	require.NoError(t, os.WriteFile(file, []byte("x"), 0o600))
+	statement, err = installStatement(file, InstallOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "INSTALL '"+file+"'", statement)
+	assert.Equal(t, "fts", nameOfFile(file))
+
+	_, err = installStatement(file, InstallOptions{Repository: "http://mirror"})
+	assert.Error(t, err)
+	_, err = installStatement(filepath.Join(t.TempDir(), "missing.duckdb_extension"), InstallOptions{})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = installStatement("Bad Name", InstallOptions{})
+	assert.Error(t, err)
+}
+
+func TestListAndLoadOffline(t *testing.T) {
+	st, err := duckdb.NewDuckDBStorage("")
+	require.NoError(t, err)
+	defer func() { _ = st.Close() }()
+
+	dir := t.TempDir()
+	require.NoError(t, Configure(st, dir, true))
+
+	version, platform, err := Platform(st)
+	require.NoError(t, err)
+	assert.NotEmpty(t, version)
+	assert.NotEmpty(t, platform)
+
+	list, err := List(st)
+	require.NoError(t, err)
+	builtIn := map[string]bool{}
+	for _, e := range list {
+		builtIn[e.Name] = e.BuiltIn()
+	}
+	assert.True(t, builtIn["json"])
+	assert.True(t, builtIn["parquet"])
+
+	require.NoError(t, Load(st, []string{"json"}, true))
+	// Offline, an extension missing from the directory is not downloaded
+	err = Load(st, []string{"spatial"}, true)
+	assert.ErrorContains(t, err, "dataql ext install spatial")
+}
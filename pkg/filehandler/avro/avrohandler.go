@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
 	"github.com/adrianolaselva/dataql/pkg/storage"
 	"github.com/linkedin/goavro/v2"
 	"github.com/schollz/progressbar/v3"
@@ -24,6 +25,7 @@ type avroHandler struct {
 	currentLine int
 	collection  string
 	aliases     map[string]string // Map of file path -> table alias
+	naming      filehandler.ColumnNaming
 }
 
 // NewAvroHandler creates a new AVRO file handler
@@ -61,6 +63,8 @@ func (a *avroHandler) Import() error {
 
 // importFile imports a single AVRO file
 func (a *avroHandler) importFile(filePath string) error {
+	a.bar.Describe(progress.Description(progress.Import, filePath))
+
 	file, err := os.Open(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to open AVRO file: %w", err)
@@ -102,17 +106,17 @@ func (a *avroHandler) importFile(filePath string) error {
 	}
 
 	// Extract columns from flattened records
-	columnSet := make(map[string]bool)
+	columnSet := make(map[string]struct{})
 	for _, record := range records {
 		flatRecord := a.flattenMap(record, "")
 		for col := range flatRecord {
-			columnSet[col] = true
+			columnSet[col] = struct{}{}
 		}
 	}
 
-	columns := make([]string, 0, len(columnSet))
-	for col := range columnSet {
-		columns = append(columns, col)
+	keys, columns, err := a.naming.Or(filehandler.NameLower).Columns(columnSet)
+	if err != nil {
+		return err
 	}
 
 	// Build table structure
@@ -128,7 +132,7 @@ func (a *avroHandler) importFile(filePath string) error {
 
 		flatRecord := a.flattenMap(record, "")
 		values := make([]any, len(columns))
-		for j, col := range columns {
+		for j, col := range keys {
 			if val, ok := flatRecord[col]; ok {
 				values[j] = val
 			} else {
@@ -157,7 +161,6 @@ func (a *avroHandler) flattenMap(data map[string]interface{}, prefix string) map
 		if prefix != "" {
 			fullKey = prefix + "_" + key
 		}
-		fullKey = a.sanitizeName(fullKey)
 
 		switch v := value.(type) {
 		case map[string]interface{}:
@@ -179,16 +182,6 @@ func (a *avroHandler) flattenMap(data map[string]interface{}, prefix string) map
 	return result
 }
 
-// sanitizeName sanitizes a string to be used as a SQL identifier
-func (a *avroHandler) sanitizeName(name string) string {
-	name = strings.TrimSpace(name)
-	name = strings.ReplaceAll(name, ".", "_")
-	name = strings.ReplaceAll(name, " ", "_")
-	name = strings.ReplaceAll(name, "-", "_")
-	name = strings.ToLower(name)
-	return nonAlphanumericRegex.ReplaceAllString(name, "")
-}
-
 // formatTableName formats table name from file path
 func (a *avroHandler) formatTableName(filePath string) string {
 	// Check if there's an alias for this file
@@ -212,6 +205,11 @@ func (a *avroHandler) formatTableName(filePath string) string {
 	return nonAlphanumericRegex.ReplaceAllString(tableName, "")
 }
 
+// SetColumnNaming sets how column names are derived from field names
+func (a *avroHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	a.naming = naming
+}
+
 // Lines returns total lines count
 func (a *avroHandler) Lines() int {
 	return a.totalLines
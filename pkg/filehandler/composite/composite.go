@@ -83,6 +83,21 @@ func NewCompositeHandlerWithAliases(
 	limitLines int,
 	collection string,
 	aliases map[string]string,
+) (*CompositeHandler, error) {
+	return NewCompositeHandlerWithNested(files, delimiter, bar, storage, limitLines, collection, aliases, filehandler.NestedFlatten)
+}
+
+// NewCompositeHandlerWithNested creates a new composite handler for files with mixed formats and table aliases,
+// importing the nested objects and arrays of JSON and JSONL files as nested says
+func NewCompositeHandlerWithNested(
+	files []string,
+	delimiter rune,
+	bar *progressbar.ProgressBar,
+	storage storage.Storage,
+	limitLines int,
+	collection string,
+	aliases map[string]string,
+	nested filehandler.Nested,
 ) (*CompositeHandler, error) {
 	// Group files by format
 	filesByFormat, err := filehandler.GroupFilesByFormat(files)
@@ -100,9 +115,9 @@ func NewCompositeHandlerWithAliases(
 		case filehandler.FormatCSV:
 			handler = csvHandler.NewCsvHandlerWithAliases(formatFiles, delimiter, bar, storage, limitLines, collection, aliases)
 		case filehandler.FormatJSON:
-			handler = jsonHandler.NewJsonHandlerWithAliases(formatFiles, bar, storage, limitLines, collection, aliases)
+			handler = jsonHandler.NewJsonHandlerWithNested(formatFiles, bar, storage, limitLines, collection, aliases, nested)
 		case filehandler.FormatJSONL:
-			handler = jsonlHandler.NewJsonlHandlerWithAliases(formatFiles, bar, storage, limitLines, collection, aliases)
+			handler = jsonlHandler.NewJsonlHandlerWithNested(formatFiles, bar, storage, limitLines, collection, aliases, nested)
 		case filehandler.FormatXML:
 			handler = xmlHandler.NewXmlHandlerWithAliases(formatFiles, bar, storage, limitLines, collection, aliases)
 		case filehandler.FormatExcel:
@@ -127,6 +142,14 @@ func NewCompositeHandlerWithAliases(
 	}, nil
 }
 
+// NewCompositeHandlerWithHandlers creates a composite handler importing the
+// data of handlers, such as those of database sources imported alongside files
+func NewCompositeHandlerWithHandlers(handlers ...filehandler.FileHandler) *CompositeHandler {
+	return &CompositeHandler{
+		handlers: handlers,
+	}
+}
+
 // Import imports data from all handlers
 func (h *CompositeHandler) Import() error {
 	h.totalLines = 0
@@ -139,6 +162,13 @@ func (h *CompositeHandler) Import() error {
 	return nil
 }
 
+// SetColumnNaming sets the column naming of the handlers of every format
+func (h *CompositeHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	for _, handler := range h.handlers {
+		filehandler.SetColumnNaming(handler, naming)
+	}
+}
+
 // Lines returns the total number of lines imported across all handlers
 func (h *CompositeHandler) Lines() int {
 	return h.totalLines
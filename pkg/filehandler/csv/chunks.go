@@ -0,0 +1,174 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+)
+
+const (
+	// parseChunkSize is the size of the parts of a file parsed in parallel
+	parseChunkSize = 4 * 1024 * 1024
+)
+
+// csvChunk is a part of a CSV file that starts and ends at a record boundary
+type csvChunk struct {
+	offset int64
+	size   int64
+	line   int // Lines before the chunk, to report the file line of parse errors
+}
+
+// parsedChunk holds the records of a chunk, or the error that stopped its parsing
+type parsedChunk struct {
+	records [][]string
+	err     error
+}
+
+// splitChunks splits the records of r after offset into chunks of about
+// chunkSize bytes. Chunks end at a newline outside quoted fields, so quoted
+// fields with line breaks are never split; offset must be a record boundary.
+func splitChunks(r io.Reader, offset, chunkSize int64) ([]csvChunk, error) {
+	var chunks []csvChunk
+	chunk := csvChunk{offset: offset}
+	buf := make([]byte, bufferMaxLength)
+	quoted := false
+	lines := 0
+	var pos int64
+
+	for {
+		n, err := r.Read(buf)
+		data := buf[:n]
+
+		// Buffers before offset, or inside the current chunk, only update the
+		// quote state and the line count
+		if end := pos + int64(n); end <= offset || (pos >= offset && end <= chunk.offset+chunkSize) {
+			quoted = quoted != (bytes.Count(data, []byte{'"'})%2 == 1)
+			lines += bytes.Count(data, []byte{'\n'})
+			if pos+int64(n) <= offset {
+				chunk.line = lines
+			}
+			pos += int64(n)
+		} else {
+			for _, b := range data {
+				pos++
+				switch b {
+				case '"':
+					quoted = !quoted
+				case '\n':
+					lines++
+					if pos <= offset {
+						chunk.line = lines
+					} else if !quoted && pos >= chunk.offset+chunkSize {
+						chunk.size = pos - chunk.offset
+						chunks = append(chunks, chunk)
+						chunk = csvChunk{offset: pos, line: lines}
+					}
+				}
+			}
+		}
+
+		switch {
+		case errors.Is(err, io.EOF):
+			if pos > chunk.offset {
+				chunk.size = pos - chunk.offset
+				chunks = append(chunks, chunk)
+			}
+			return chunks, nil
+		case err != nil:
+			return nil, fmt.Errorf("failed to split file: %w", err)
+		}
+	}
+}
+
+// parallelParse reports whether the rest of a file of size bytes after
+// offset is parsed in chunks, which needs more than one chunk and reading
+// the whole file
+func (c *csvHandler) parallelParse(size, offset int64) bool {
+	return c.limitLines == 0 && c.parseWorkers() > 1 && size-offset > c.parseChunkSize()
+}
+
+func (c *csvHandler) parseWorkers() int {
+	if c.workers > 0 {
+		return c.workers
+	}
+	return runtime.NumCPU()
+}
+
+func (c *csvHandler) parseChunkSize() int64 {
+	if c.chunkSize > 0 {
+		return c.chunkSize
+	}
+	return parseChunkSize
+}
+
+// loadChunks parses the records of file after offset on several goroutines
+// and inserts them in file order. Parsed chunks wait for the writer, so at
+// most one chunk per worker is held in memory besides the one being inserted.
+func (c *csvHandler) loadChunks(file *os.File, offset int64, fields int, insert func(record []string) error) error {
+	chunks, err := splitChunks(io.NewSectionReader(file, 0, 1<<63-1), offset, c.parseChunkSize())
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	ordered := make(chan chan parsedChunk, c.parseWorkers())
+	go func() {
+		defer close(ordered)
+		for _, chunk := range chunks {
+			result := make(chan parsedChunk, 1)
+			select {
+			case ordered <- result:
+			case <-done:
+				return
+			}
+			go func(chunk csvChunk) {
+				records, err := c.parseChunk(file, chunk, fields)
+				result <- parsedChunk{records: records, err: err}
+			}(chunk)
+		}
+	}()
+
+	for result := range ordered {
+		parsed := <-result
+		if parsed.err != nil {
+			return parsed.err
+		}
+		for _, record := range parsed.records {
+			if err := insert(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseChunk reads the records of a chunk, which have fields fields like the header
+func (c *csvHandler) parseChunk(file *os.File, chunk csvChunk, fields int) ([][]string, error) {
+	r := csv.NewReader(io.NewSectionReader(file, chunk.offset, chunk.size))
+	r.Comma = c.delimiter
+	r.FieldsPerRecord = fields
+
+	var records [][]string
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				parseErr.StartLine += chunk.line
+				parseErr.Line += chunk.line
+			}
+			return nil, fmt.Errorf("failed to read line: %w", err)
+		}
+		records = append(records, record)
+	}
+}
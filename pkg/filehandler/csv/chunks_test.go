@@ -0,0 +1,84 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/storage/sqlite"
+	"github.com/schollz/progressbar/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitChunks(t *testing.T) {
+	content := "id,note\n1,a\n2,\"line\nbreak\"\n3,c\n4,d"
+
+	chunks, err := splitChunks(strings.NewReader(content), 8, 4)
+	require.NoError(t, err)
+
+	// The quoted line break is not a boundary
+	var parts []string
+	for _, chunk := range chunks {
+		parts = append(parts, content[chunk.offset:chunk.offset+chunk.size])
+	}
+	assert.Equal(t, []string{"1,a\n", "2,\"line\nbreak\"\n", "3,c\n", "4,d"}, parts)
+	assert.Equal(t, []int{1, 2, 4, 5}, []int{chunks[0].line, chunks[1].line, chunks[2].line, chunks[3].line})
+}
+
+func TestImportChunks(t *testing.T) {
+	var content strings.Builder
+	content.WriteString("id,note\n")
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(&content, "%d,\"note\n%d\"\n", i, i)
+	}
+	path := filepath.Join(t.TempDir(), "large.csv")
+	require.NoError(t, os.WriteFile(path, []byte(content.String()), 0644))
+
+	st, err := sqlite.NewSqLiteStorage(":memory:")
+	require.NoError(t, err)
+	bar := progressbar.NewOptions(0, progressbar.OptionSetWriter(bytes.NewBuffer(nil)))
+	handler := NewCsvHandler([]string{path}, ',', bar, st, 0, "").(*csvHandler)
+	handler.workers, handler.chunkSize = 4, 256
+	defer handler.Close()
+	require.NoError(t, handler.Import())
+
+	// Every row is imported once, in file order
+	rows, err := st.Query("SELECT id, note FROM large")
+	require.NoError(t, err)
+	defer rows.Close()
+	expected := 1
+	for rows.Next() {
+		var id int
+		var note string
+		require.NoError(t, rows.Scan(&id, &note))
+		assert.Equal(t, expected, id)
+		assert.Equal(t, fmt.Sprintf("note\n%d", id), note)
+		expected++
+	}
+	assert.Equal(t, 1001, expected)
+}
+
+func TestImportChunksParseError(t *testing.T) {
+	var content strings.Builder
+	content.WriteString("id,note\n")
+	for i := 1; i <= 500; i++ {
+		fmt.Fprintf(&content, "%d,note\n", i)
+	}
+	content.WriteString("501,note,extra\n")
+	path := filepath.Join(t.TempDir(), "broken.csv")
+	require.NoError(t, os.WriteFile(path, []byte(content.String()), 0644))
+
+	st, err := sqlite.NewSqLiteStorage(":memory:")
+	require.NoError(t, err)
+	bar := progressbar.NewOptions(0, progressbar.OptionSetWriter(bytes.NewBuffer(nil)))
+	handler := NewCsvHandler([]string{path}, ',', bar, st, 0, "").(*csvHandler)
+	handler.workers, handler.chunkSize = 4, 256
+	defer handler.Close()
+
+	// The error names the line of the file, not of the chunk
+	assert.ErrorContains(t, handler.Import(), "record on line 502: wrong number of fields")
+}
@@ -14,6 +14,7 @@ import (
 	"sync"
 
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
 	"github.com/adrianolaselva/dataql/pkg/storage"
 	"github.com/schollz/progressbar/v3"
 )
@@ -36,6 +37,9 @@ type csvHandler struct {
 	delimiter   rune
 	collection  string
 	aliases     map[string]string // Map of file path -> table alias
+	naming      filehandler.ColumnNaming
+	workers     int   // Goroutines parsing large files, 0 for one per CPU
+	chunkSize   int64 // Bytes parsed per goroutine, 0 for parseChunkSize
 }
 
 // NewCsvHandler creates a new CSV file handler
@@ -189,6 +193,11 @@ func (c *csvHandler) formatTableName(file *os.File) string {
 	return nonAlphanumericRegex.ReplaceAllString(tableName, "")
 }
 
+// SetColumnNaming sets how column names are derived from headers
+func (c *csvHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	c.naming = naming
+}
+
 // Query executes SQL statements
 func (c *csvHandler) Query(cmd string) (*sql.Rows, error) {
 	rows, err := c.storage.Query(cmd)
@@ -225,15 +234,20 @@ func (c *csvHandler) loadDataFromFile(tableName string, file *os.File) error {
 	defer c.mx.Unlock()
 
 	c.bar.ChangeMax(c.totalLines)
+	c.bar.Describe(progress.Description(progress.Import, file.Name()))
 
 	r := csv.NewReader(file)
 	r.Comma = c.delimiter
 
 	// Read header
-	columns, err := r.Read()
+	headers, err := r.Read()
 	if err != nil {
 		return fmt.Errorf("failed to load headers: %w", err)
 	}
+	columns, err := c.naming.Or(filehandler.NameKeep).Names(headers)
+	if err != nil {
+		return err
+	}
 
 	// Collect sample rows for type inference (up to 100 rows)
 	const sampleSize = 100
@@ -269,12 +283,7 @@ func (c *csvHandler) loadDataFromFile(tableName string, file *os.File) error {
 	// Check if storage supports type coercion
 	typedStorage, hasTypedStorage := c.storage.(storage.TypedStorage)
 
-	// Insert the sample rows we already read
-	c.currentLine = 0
-	for _, record := range allRecords {
-		if c.limitLines > 0 && c.currentLine >= c.limitLines {
-			return nil
-		}
+	insert := func(record []string) error {
 		_ = c.bar.Add(1)
 		c.currentLine++
 
@@ -288,6 +297,30 @@ func (c *csvHandler) loadDataFromFile(tableName string, file *os.File) error {
 		if insertErr != nil {
 			return fmt.Errorf("failed to process row number %d: %w", c.currentLine, insertErr)
 		}
+		return nil
+	}
+
+	// Insert the sample rows we already read
+	c.currentLine = 0
+	for _, record := range allRecords {
+		if c.limitLines > 0 && c.currentLine >= c.limitLines {
+			return nil
+		}
+		if err := insert(record); err != nil {
+			return err
+		}
+	}
+	if len(allRecords) < sampleSize {
+		return nil
+	}
+
+	// Large files are parsed in chunks on several goroutines
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read file size: %w", err)
+	}
+	if c.parallelParse(info.Size(), r.InputOffset()) {
+		return c.loadChunks(file, r.InputOffset(), len(headers), insert)
 	}
 
 	// Continue reading the rest of the file
@@ -304,18 +337,8 @@ func (c *csvHandler) loadDataFromFile(tableName string, file *os.File) error {
 			return fmt.Errorf("failed to read line: %w", err)
 		}
 
-		_ = c.bar.Add(1)
-		c.currentLine++
-
-		values := c.convertToAnyArrayWithTypes(record, columnDefs)
-		var insertErr error
-		if hasTypedStorage {
-			insertErr = typedStorage.InsertRowWithCoercion(tableName, columns, values, columnDefs)
-		} else {
-			insertErr = c.storage.InsertRow(tableName, columns, values)
-		}
-		if insertErr != nil {
-			return fmt.Errorf("failed to process row number %d: %w", c.currentLine, insertErr)
+		if err := insert(record); err != nil {
+			return err
 		}
 	}
 
@@ -338,7 +361,7 @@ func (c *csvHandler) convertToAnyArrayWithTypes(records []string, columnDefs []s
 	for i, r := range records {
 		if r == "" && i < len(columnDefs) {
 			// For numeric columns, use nil instead of empty string
-			if columnDefs[i].Type == storage.TypeBigInt || columnDefs[i].Type == storage.TypeDouble {
+			if columnDefs[i].Type.IsNumeric() {
 				values[i] = nil
 			} else {
 				values[i] = r
@@ -2,12 +2,14 @@ package database
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
 
 	"github.com/adrianolaselva/dataql/pkg/dbconnector"
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
 	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/adrianolaselva/dataql/pkg/tlsconfig"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -33,6 +35,8 @@ type dbHandler struct {
 	limitLines  int
 	currentLine int
 	collection  string
+	naming      filehandler.ColumnNaming
+	tls         tlsconfig.Options
 }
 
 // NewDBHandler creates a new database file handler
@@ -57,6 +61,7 @@ func (d *dbHandler) Import() error {
 		Password: d.connInfo.Password,
 		Database: d.connInfo.Database,
 		SSLMode:  d.connInfo.SSLMode,
+		TLS:      d.tls,
 	}
 
 	connector, err := dbconnector.NewConnector(config)
@@ -90,9 +95,13 @@ func (d *dbHandler) Import() error {
 	}
 
 	// Convert schema to column names
-	columns := make([]string, len(schema))
+	raw := make([]string, len(schema))
 	for i, col := range schema {
-		columns[i] = d.sanitizeColumnName(col.Name)
+		raw[i] = col.Name
+	}
+	columns, err := d.naming.Or(filehandler.NameLower).Names(raw)
+	if err != nil {
+		return err
 	}
 
 	// Build table structure
@@ -157,16 +166,6 @@ func (d *dbHandler) Import() error {
 	return nil
 }
 
-// sanitizeColumnName sanitizes a string to be used as a SQL column name
-func (d *dbHandler) sanitizeColumnName(name string) string {
-	name = strings.TrimSpace(name)
-	name = strings.ReplaceAll(name, ".", "_")
-	name = strings.ReplaceAll(name, " ", "_")
-	name = strings.ReplaceAll(name, "-", "_")
-	name = strings.ToLower(name)
-	return nonAlphanumericRegex.ReplaceAllString(name, "")
-}
-
 // sanitizeTableName sanitizes a table name
 func (d *dbHandler) sanitizeTableName(name string) string {
 	name = strings.TrimSpace(name)
@@ -175,6 +174,17 @@ func (d *dbHandler) sanitizeTableName(name string) string {
 	return nonAlphanumericRegex.ReplaceAllString(name, "")
 }
 
+// SetColumnNaming sets how column names are derived from table columns
+func (d *dbHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	d.naming = naming
+}
+
+// SetTLS sets the CAs trusted for the server certificate, or turns off its
+// verification
+func (d *dbHandler) SetTLS(opts tlsconfig.Options) {
+	d.tls = opts
+}
+
 // Lines returns total lines count
 func (d *dbHandler) Lines() int {
 	return d.totalLines
@@ -222,10 +232,10 @@ func ParseDatabaseURL(urlStr string) (*ConnectionInfo, error) {
 		rest = rest[idx+1:]
 
 		if colonIdx := strings.Index(userPass, ":"); colonIdx != -1 {
-			info.User = userPass[:colonIdx]
-			info.Password = userPass[colonIdx+1:]
+			info.User = unescapeUserinfo(userPass[:colonIdx])
+			info.Password = unescapeUserinfo(userPass[colonIdx+1:])
 		} else {
-			info.User = userPass
+			info.User = unescapeUserinfo(userPass)
 		}
 	}
 
@@ -253,6 +263,15 @@ func ParseDatabaseURL(urlStr string) (*ConnectionInfo, error) {
 	return info, nil
 }
 
+// unescapeUserinfo decodes percent-encoded characters of a user name or
+// password, such as %40 for @. Values that are not valid escapes are kept.
+func unescapeUserinfo(value string) string {
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
+
 // parseDuckDBURL parses a DuckDB URL
 // Format: duckdb:///path/to/file.db/table or duckdb://:memory:/table
 func parseDuckDBURL(rest string) (*ConnectionInfo, error) {
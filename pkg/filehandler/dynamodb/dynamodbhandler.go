@@ -9,6 +9,7 @@ import (
 	"github.com/adrianolaselva/dataql/pkg/dbconnector"
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
 	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/adrianolaselva/dataql/pkg/tlsconfig"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -29,6 +30,8 @@ type dynamodbHandler struct {
 	limitLines  int
 	currentLine int
 	collection  string
+	naming      filehandler.ColumnNaming
+	tls         tlsconfig.Options
 }
 
 // NewDynamoDBHandler creates a new DynamoDB file handler
@@ -49,6 +52,7 @@ func (d *dynamodbHandler) Import() error {
 		Region:    d.connInfo.Region,
 		TableName: d.connInfo.TableName,
 		Endpoint:  d.connInfo.Endpoint,
+		TLS:       d.tls,
 	}
 
 	connector, err := dbconnector.NewDynamoDBConnector(cfg)
@@ -82,9 +86,13 @@ func (d *dynamodbHandler) Import() error {
 	}
 
 	// Convert schema to column names
-	columns := make([]string, len(schema))
+	raw := make([]string, len(schema))
 	for i, col := range schema {
-		columns[i] = d.sanitizeName(col.Name)
+		raw[i] = col.Name
+	}
+	columns, err := d.naming.Or(filehandler.NameLower).Names(raw)
+	if err != nil {
+		return err
 	}
 
 	// Build table structure
@@ -158,6 +166,17 @@ func (d *dynamodbHandler) sanitizeName(name string) string {
 	return nonAlphanumericRegex.ReplaceAllString(name, "")
 }
 
+// SetColumnNaming sets how column names are derived from item attributes
+func (d *dynamodbHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	d.naming = naming
+}
+
+// SetTLS sets the CAs trusted for the DynamoDB endpoint, or turns off
+// certificate verification
+func (d *dynamodbHandler) SetTLS(opts tlsconfig.Options) {
+	d.tls = opts
+}
+
 // Lines returns total lines count
 func (d *dynamodbHandler) Lines() int {
 	return d.totalLines
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
 	"github.com/adrianolaselva/dataql/pkg/storage"
 	"github.com/schollz/progressbar/v3"
 	"github.com/xuri/excelize/v2"
@@ -23,6 +24,7 @@ type excelHandler struct {
 	currentLine int
 	collection  string
 	aliases     map[string]string // Map of file path -> table alias
+	naming      filehandler.ColumnNaming
 }
 
 // NewExcelHandler creates a new Excel file handler
@@ -60,6 +62,8 @@ func (e *excelHandler) Import() error {
 
 // loadFile loads a single Excel file
 func (e *excelHandler) loadFile(filePath string) error {
+	e.bar.Describe(progress.Description(progress.Import, filePath))
+
 	f, err := excelize.OpenFile(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to open Excel file %s: %w", filePath, err)
@@ -91,12 +95,9 @@ func (e *excelHandler) loadFile(filePath string) error {
 	}
 
 	// First row is the header
-	columns := make([]string, len(rows[0]))
-	for i, col := range rows[0] {
-		columns[i] = e.sanitizeColumnName(col)
-		if columns[i] == "" {
-			columns[i] = fmt.Sprintf("column_%d", i+1)
-		}
+	columns, err := e.naming.Or(filehandler.NameLower).Names(rows[0])
+	if err != nil {
+		return err
 	}
 
 	// Build table structure
@@ -140,15 +141,6 @@ func (e *excelHandler) loadFile(filePath string) error {
 	return nil
 }
 
-// sanitizeColumnName sanitizes a string to be used as a SQL column name
-func (e *excelHandler) sanitizeColumnName(name string) string {
-	name = strings.TrimSpace(name)
-	name = strings.ReplaceAll(name, " ", "_")
-	name = strings.ReplaceAll(name, "-", "_")
-	name = strings.ToLower(name)
-	return nonAlphanumericRegex.ReplaceAllString(name, "")
-}
-
 // formatTableName formats table name from file path
 func (e *excelHandler) formatTableName(filePath string) string {
 	// Check if there's an alias for this file
@@ -171,6 +163,11 @@ func (e *excelHandler) formatTableName(filePath string) string {
 	return nonAlphanumericRegex.ReplaceAllString(tableName, "")
 }
 
+// SetColumnNaming sets how column names are derived from header cells
+func (e *excelHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	e.naming = naming
+}
+
 // Lines returns total lines count
 func (e *excelHandler) Lines() int {
 	return e.totalLines
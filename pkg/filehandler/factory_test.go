@@ -1,6 +1,7 @@
 package filehandler_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
@@ -156,3 +157,24 @@ func TestIsFormatSupported(t *testing.T) {
 		})
 	}
 }
+
+func TestParseNested(t *testing.T) {
+	for value, expected := range map[string]filehandler.Nested{"": filehandler.NestedFlatten, "struct": filehandler.NestedStruct, "JSON": filehandler.NestedJSON} {
+		nested, err := filehandler.ParseNested(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, nested, value)
+	}
+	_, err := filehandler.ParseNested("list")
+	assert.Error(t, err)
+}
+
+func TestDecodeJSON(t *testing.T) {
+	var record map[string]interface{}
+	err := filehandler.DecodeJSON([]byte(`{"id": 9007199254740993, "amount": 0.1}`), &record)
+	assert.NoError(t, err)
+	assert.Equal(t, "9007199254740993", fmt.Sprint(record["id"]))
+	assert.Equal(t, "0.1", fmt.Sprint(record["amount"]))
+
+	err = filehandler.DecodeJSON([]byte(`{"id": 1} {"id": 2}`), &record)
+	assert.Error(t, err)
+}
@@ -7,10 +7,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
 	"github.com/adrianolaselva/dataql/pkg/storage"
 	"github.com/schollz/progressbar/v3"
 )
@@ -26,6 +26,8 @@ type jsonHandler struct {
 	currentLine int
 	collection  string
 	aliases     map[string]string // Map of file path -> table alias
+	nested      filehandler.Nested
+	naming      filehandler.ColumnNaming
 }
 
 // NewJsonHandler creates a new JSON file handler
@@ -41,6 +43,12 @@ func NewJsonHandler(fileInputs []string, bar *progressbar.ProgressBar, storage s
 
 // NewJsonHandlerWithAliases creates a new JSON file handler with table aliases
 func NewJsonHandlerWithAliases(fileInputs []string, bar *progressbar.ProgressBar, storage storage.Storage, limitLines int, collection string, aliases map[string]string) filehandler.FileHandler {
+	return NewJsonHandlerWithNested(fileInputs, bar, storage, limitLines, collection, aliases, filehandler.NestedFlatten)
+}
+
+// NewJsonHandlerWithNested creates a new JSON file handler with table aliases,
+// importing nested objects and arrays as nested says
+func NewJsonHandlerWithNested(fileInputs []string, bar *progressbar.ProgressBar, storage storage.Storage, limitLines int, collection string, aliases map[string]string, nested filehandler.Nested) filehandler.FileHandler {
 	return &jsonHandler{
 		fileInputs: fileInputs,
 		storage:    storage,
@@ -48,6 +56,7 @@ func NewJsonHandlerWithAliases(fileInputs []string, bar *progressbar.ProgressBar
 		limitLines: limitLines,
 		collection: collection,
 		aliases:    aliases,
+		nested:     nested,
 	}
 }
 
@@ -63,6 +72,20 @@ func (j *jsonHandler) Import() error {
 
 // loadFile loads a single JSON file
 func (j *jsonHandler) loadFile(filePath string) error {
+	j.bar.Describe(progress.Description(progress.Import, filePath))
+
+	if j.nested == filehandler.NestedStruct || j.nested == filehandler.NestedJSON {
+		count, err := filehandler.ImportNested(j.storage, j.formatTableName(filePath), filePath, j.nested, false, j.limitLines, j.naming.Or(filehandler.NameLower))
+		if err != nil {
+			return err
+		}
+		j.totalLines += count
+		j.bar.ChangeMax(j.totalLines)
+		_ = j.bar.Add(count)
+		j.currentLine += count
+		return nil
+	}
+
 	file, err := os.Open(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to open file %s: %w", filePath, err)
@@ -78,13 +101,13 @@ func (j *jsonHandler) loadFile(filePath string) error {
 
 	// Try to parse as array first
 	var records []map[string]interface{}
-	if err := json.Unmarshal(content, &records); err == nil {
+	if err := filehandler.DecodeJSON(content, &records); err == nil {
 		return j.importRecords(tableName, records)
 	}
 
 	// Try to parse as single object
 	var record map[string]interface{}
-	if err := json.Unmarshal(content, &record); err == nil {
+	if err := filehandler.DecodeJSON(content, &record); err == nil {
 		return j.importRecords(tableName, []map[string]interface{}{record})
 	}
 
@@ -113,12 +136,11 @@ func (j *jsonHandler) importRecords(tableName string, records []map[string]inter
 		}
 	}
 
-	// Sort columns for consistent ordering
-	columns := make([]string, 0, len(columnsSet))
-	for col := range columnsSet {
-		columns = append(columns, col)
+	// Sort columns for consistent ordering, reading values by key
+	keys, columns, err := j.naming.Or(filehandler.NameLower).Columns(columnsSet)
+	if err != nil {
+		return err
 	}
-	sort.Strings(columns)
 
 	// Collect sample rows for type inference (up to 100 rows)
 	sampleSize := 100
@@ -128,7 +150,7 @@ func (j *jsonHandler) importRecords(tableName string, records []map[string]inter
 	sampleRows := make([][]any, sampleSize)
 	for i := 0; i < sampleSize; i++ {
 		row := make([]any, len(columns))
-		for idx, col := range columns {
+		for idx, col := range keys {
 			if val, ok := flattenedRecords[i][col]; ok {
 				row[idx] = val
 			} else {
@@ -169,13 +191,12 @@ func (j *jsonHandler) importRecords(tableName string, records []map[string]inter
 		}
 
 		values := make([]any, len(columns))
-		for idx, col := range columns {
+		for idx, col := range keys {
 			if val, ok := record[col]; ok && val != "" {
 				values[idx] = val
 			} else {
 				// For numeric/boolean columns, use nil instead of empty string
-				if columnDefs[idx].Type == storage.TypeBigInt ||
-					columnDefs[idx].Type == storage.TypeDouble ||
+				if columnDefs[idx].Type.IsNumeric() ||
 					columnDefs[idx].Type == storage.TypeBoolean {
 					values[idx] = nil
 				} else {
@@ -210,8 +231,6 @@ func (j *jsonHandler) flattenMap(data map[string]interface{}, prefix string) map
 		if prefix != "" {
 			fullKey = prefix + "_" + key
 		}
-		// Sanitize key for SQL column name
-		fullKey = j.sanitizeColumnName(fullKey)
 
 		switch v := value.(type) {
 		case map[string]interface{}:
@@ -226,6 +245,9 @@ func (j *jsonHandler) flattenMap(data map[string]interface{}, prefix string) map
 			result[fullKey] = string(jsonBytes)
 		case nil:
 			result[fullKey] = ""
+		case json.Number:
+			// The exact digits, which float64 may round
+			result[fullKey] = v.String()
 		case float64:
 			// Handle numbers - check if it's an integer
 			if v == float64(int64(v)) {
@@ -247,15 +269,6 @@ func (j *jsonHandler) flattenMap(data map[string]interface{}, prefix string) map
 	return result
 }
 
-// sanitizeColumnName sanitizes a string to be used as a SQL column name
-func (j *jsonHandler) sanitizeColumnName(name string) string {
-	// Replace dots and special characters with underscores
-	name = strings.ReplaceAll(name, ".", "_")
-	name = strings.ReplaceAll(name, " ", "_")
-	name = strings.ToLower(name)
-	return nonAlphanumericRegex.ReplaceAllString(name, "")
-}
-
 // formatTableName formats table name from file path
 func (j *jsonHandler) formatTableName(filePath string) string {
 	// Check if there's an alias for this file
@@ -278,6 +291,11 @@ func (j *jsonHandler) formatTableName(filePath string) string {
 	return nonAlphanumericRegex.ReplaceAllString(tableName, "")
 }
 
+// SetColumnNaming sets how column names are derived from keys
+func (j *jsonHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	j.naming = naming
+}
+
 // Lines returns total lines count
 func (j *jsonHandler) Lines() int {
 	return j.totalLines
@@ -6,7 +6,9 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/adrianolaselva/dataql/pkg/filehandler"
 	"github.com/adrianolaselva/dataql/pkg/filehandler/json"
+	"github.com/adrianolaselva/dataql/pkg/storage/duckdb"
 	"github.com/adrianolaselva/dataql/pkg/storage/sqlite"
 	"github.com/schollz/progressbar/v3"
 	"github.com/stretchr/testify/assert"
@@ -197,3 +199,62 @@ func TestJsonHandler_Close(t *testing.T) {
 	err = handler.Close()
 	assert.NoError(t, err)
 }
+
+func TestJsonHandler_Import_NestedStruct(t *testing.T) {
+	content := `[
+		{"id": 1, "User Info": {"name": "John", "tags": ["a", "b"]}, "items": [{"sku": "x", "qty": 2}]},
+		{"id": 2, "User Info": {"name": "Jane", "tags": []}, "items": []},
+		{"id": 3, "User Info": {"name": "Ann", "tags": ["c"]}, "items": [{"sku": "y", "qty": 1}]}
+	]`
+	filePath := createTestJSON(t, t.TempDir(), "orders.json", content)
+
+	storage, err := duckdb.NewDuckDBStorage("")
+	require.NoError(t, err)
+	defer storage.Close()
+
+	handler := json.NewJsonHandlerWithNested([]string{filePath}, createProgressBar(), storage, 2, "", nil, filehandler.NestedStruct)
+	require.NoError(t, handler.Import())
+	assert.Equal(t, 2, handler.Lines())
+
+	rows, err := storage.Query(`SELECT user_info.name, len(user_info.tags), (SELECT SUM(i.qty) FROM (SELECT unnest(items) AS i)) FROM orders ORDER BY id`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var names []string
+	var tags []int
+	for rows.Next() {
+		var name string
+		var tagCount int
+		var qty *int
+		require.NoError(t, rows.Scan(&name, &tagCount, &qty))
+		names = append(names, name)
+		tags = append(tags, tagCount)
+	}
+	assert.Equal(t, []string{"John", "Jane"}, names)
+	assert.Equal(t, []int{2, 0}, tags)
+}
+
+func TestJsonHandler_Import_NestedJSON(t *testing.T) {
+	content := `{"id": 1, "user": {"name": "John"}, "tags": ["a", "b"]}`
+	filePath := createTestJSON(t, t.TempDir(), "user.json", content)
+
+	storage, err := duckdb.NewDuckDBStorage("")
+	require.NoError(t, err)
+	defer storage.Close()
+
+	handler := json.NewJsonHandlerWithNested([]string{filePath}, createProgressBar(), storage, 0, "", nil, filehandler.NestedJSON)
+	require.NoError(t, handler.Import())
+
+	rows, err := storage.Query(`SELECT typeof("user"), "user"->>'$.name', json_array_length(tags), typeof(id) FROM "user"`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	require.True(t, rows.Next())
+	var userType, name, idType string
+	var tagCount int
+	require.NoError(t, rows.Scan(&userType, &name, &tagCount, &idType))
+	assert.Equal(t, "JSON", userType)
+	assert.Equal(t, "John", name)
+	assert.Equal(t, 2, tagCount)
+	assert.Equal(t, "BIGINT", idType)
+}
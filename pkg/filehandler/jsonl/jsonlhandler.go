@@ -7,10 +7,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
 	"github.com/adrianolaselva/dataql/pkg/storage"
 	"github.com/schollz/progressbar/v3"
 )
@@ -26,6 +26,8 @@ type jsonlHandler struct {
 	currentLine int
 	collection  string
 	aliases     map[string]string // Map of file path -> table alias
+	nested      filehandler.Nested
+	naming      filehandler.ColumnNaming
 }
 
 // NewJsonlHandler creates a new JSONL file handler
@@ -41,6 +43,12 @@ func NewJsonlHandler(fileInputs []string, bar *progressbar.ProgressBar, storage
 
 // NewJsonlHandlerWithAliases creates a new JSONL file handler with table aliases
 func NewJsonlHandlerWithAliases(fileInputs []string, bar *progressbar.ProgressBar, storage storage.Storage, limitLines int, collection string, aliases map[string]string) filehandler.FileHandler {
+	return NewJsonlHandlerWithNested(fileInputs, bar, storage, limitLines, collection, aliases, filehandler.NestedFlatten)
+}
+
+// NewJsonlHandlerWithNested creates a new JSONL file handler with table aliases,
+// importing nested objects and arrays as nested says
+func NewJsonlHandlerWithNested(fileInputs []string, bar *progressbar.ProgressBar, storage storage.Storage, limitLines int, collection string, aliases map[string]string, nested filehandler.Nested) filehandler.FileHandler {
 	return &jsonlHandler{
 		fileInputs: fileInputs,
 		storage:    storage,
@@ -48,6 +56,7 @@ func NewJsonlHandlerWithAliases(fileInputs []string, bar *progressbar.ProgressBa
 		limitLines: limitLines,
 		collection: collection,
 		aliases:    aliases,
+		nested:     nested,
 	}
 }
 
@@ -99,6 +108,25 @@ func (j *jsonlHandler) countLines(filePath string) (int, error) {
 
 // loadFile loads a single JSONL file using streaming
 func (j *jsonlHandler) loadFile(filePath string) error {
+	j.bar.Describe(progress.Description(progress.Import, filePath))
+
+	if j.nested == filehandler.NestedStruct || j.nested == filehandler.NestedJSON {
+		// The limit applies to all the files together
+		limit := 0
+		if j.limitLines > 0 {
+			if limit = j.limitLines - j.currentLine; limit <= 0 {
+				return nil
+			}
+		}
+		count, err := filehandler.ImportNested(j.storage, j.formatTableName(filePath), filePath, j.nested, true, limit, j.naming.Or(filehandler.NameLower))
+		if err != nil {
+			return err
+		}
+		_ = j.bar.Add(count)
+		j.currentLine += count
+		return nil
+	}
+
 	file, err := os.Open(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to open file %s: %w", filePath, err)
@@ -108,7 +136,7 @@ func (j *jsonlHandler) loadFile(filePath string) error {
 	tableName := j.formatTableName(filePath)
 
 	// First pass: detect all columns and their types
-	columnDefs, columns, err := j.detectColumnsWithTypes(filePath)
+	columnDefs, keys, columns, err := j.detectColumnsWithTypes(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to detect columns: %w", err)
 	}
@@ -148,20 +176,19 @@ func (j *jsonlHandler) loadFile(filePath string) error {
 		lineNum++
 
 		var record map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &record); err != nil {
+		if err := filehandler.DecodeJSON([]byte(line), &record); err != nil {
 			return fmt.Errorf("failed to parse JSON at line %d: %w", lineNum, err)
 		}
 
 		flat := j.flattenMap(record, "")
 
 		values := make([]any, len(columns))
-		for idx, col := range columns {
+		for idx, col := range keys {
 			if val, ok := flat[col]; ok && val != "" {
 				values[idx] = val
 			} else {
 				// For numeric/boolean columns, use nil instead of empty string
-				if columnDefs[idx].Type == storage.TypeBigInt ||
-					columnDefs[idx].Type == storage.TypeDouble ||
+				if columnDefs[idx].Type.IsNumeric() ||
 					columnDefs[idx].Type == storage.TypeBoolean {
 					values[idx] = nil
 				} else {
@@ -191,11 +218,12 @@ func (j *jsonlHandler) loadFile(filePath string) error {
 	return nil
 }
 
-// detectColumnsWithTypes scans the file to detect all unique columns and their types
-func (j *jsonlHandler) detectColumnsWithTypes(filePath string) ([]storage.ColumnDef, []string, error) {
+// detectColumnsWithTypes scans the file to detect all unique columns and their
+// types, returning the keys of the columns along with their names
+func (j *jsonlHandler) detectColumnsWithTypes(filePath string) ([]storage.ColumnDef, []string, []string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	defer file.Close()
 
@@ -222,7 +250,7 @@ func (j *jsonlHandler) detectColumnsWithTypes(filePath string) ([]storage.Column
 		}
 
 		var record map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &record); err != nil {
+		if err := filehandler.DecodeJSON([]byte(line), &record); err != nil {
 			continue // Skip invalid lines for schema detection
 		}
 
@@ -234,22 +262,21 @@ func (j *jsonlHandler) detectColumnsWithTypes(filePath string) ([]storage.Column
 		scanned++
 	}
 
-	columns := make([]string, 0, len(columnsSet))
-	for col := range columnsSet {
-		columns = append(columns, col)
+	keys, columns, err := j.naming.Or(filehandler.NameLower).Columns(columnsSet)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	sort.Strings(columns)
 
 	// If no columns detected (empty file), add a placeholder column
 	if len(columns) == 0 {
-		return []storage.ColumnDef{{Name: "_empty", Type: storage.TypeVarchar}}, []string{"_empty"}, nil
+		return []storage.ColumnDef{{Name: "_empty", Type: storage.TypeVarchar}}, []string{"_empty"}, []string{"_empty"}, nil
 	}
 
 	// Convert sample records to [][]any for type inference
 	sampleRows := make([][]any, len(sampleRecords))
 	for i, record := range sampleRecords {
 		row := make([]any, len(columns))
-		for idx, col := range columns {
+		for idx, col := range keys {
 			if val, ok := record[col]; ok {
 				row[idx] = val
 			} else {
@@ -262,7 +289,7 @@ func (j *jsonlHandler) detectColumnsWithTypes(filePath string) ([]storage.Column
 	// Infer column types
 	columnDefs := storage.InferColumnTypes(columns, sampleRows)
 
-	return columnDefs, columns, nil
+	return columnDefs, keys, columns, nil
 }
 
 // flattenMap flattens a nested map into a single-level map with underscore notation keys
@@ -274,7 +301,6 @@ func (j *jsonlHandler) flattenMap(data map[string]interface{}, prefix string) ma
 		if prefix != "" {
 			fullKey = prefix + "_" + key
 		}
-		fullKey = j.sanitizeColumnName(fullKey)
 
 		switch v := value.(type) {
 		case map[string]interface{}:
@@ -287,6 +313,9 @@ func (j *jsonlHandler) flattenMap(data map[string]interface{}, prefix string) ma
 			result[fullKey] = string(jsonBytes)
 		case nil:
 			result[fullKey] = ""
+		case json.Number:
+			// The exact digits, which float64 may round
+			result[fullKey] = v.String()
 		case float64:
 			if v == float64(int64(v)) {
 				result[fullKey] = fmt.Sprintf("%d", int64(v))
@@ -307,12 +336,9 @@ func (j *jsonlHandler) flattenMap(data map[string]interface{}, prefix string) ma
 	return result
 }
 
-// sanitizeColumnName sanitizes a string to be used as a SQL column name
-func (j *jsonlHandler) sanitizeColumnName(name string) string {
-	name = strings.ReplaceAll(name, ".", "_")
-	name = strings.ReplaceAll(name, " ", "_")
-	name = strings.ToLower(name)
-	return nonAlphanumericRegex.ReplaceAllString(name, "")
+// SetColumnNaming sets how column names are derived from keys
+func (j *jsonlHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	j.naming = naming
 }
 
 // formatTableName formats table name from file path
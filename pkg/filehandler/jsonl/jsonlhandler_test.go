@@ -6,7 +6,9 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/adrianolaselva/dataql/pkg/filehandler"
 	"github.com/adrianolaselva/dataql/pkg/filehandler/jsonl"
+	"github.com/adrianolaselva/dataql/pkg/storage/duckdb"
 	"github.com/adrianolaselva/dataql/pkg/storage/sqlite"
 	"github.com/schollz/progressbar/v3"
 	"github.com/stretchr/testify/assert"
@@ -271,3 +273,27 @@ func TestJsonlHandler_Close(t *testing.T) {
 	err = handler.Close()
 	assert.NoError(t, err)
 }
+
+func TestJsonlHandler_Import_NestedStructLimitAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := createTestJSONL(t, dir, "a.jsonl", "{\"id\": 1, \"geo\": {\"lat\": 1.5}}\n{\"id\": 2, \"geo\": {\"lat\": 2.5}}\n")
+	second := createTestJSONL(t, dir, "b.jsonl", "{\"id\": 3, \"geo\": {\"lat\": 3.5}}\n")
+
+	storage, err := duckdb.NewDuckDBStorage("")
+	require.NoError(t, err)
+	defer storage.Close()
+
+	handler := jsonl.NewJsonlHandlerWithNested([]string{first, second}, createProgressBar(), storage, 2, "points", nil, filehandler.NestedStruct)
+	require.NoError(t, handler.Import())
+
+	rows, err := storage.Query("SELECT COUNT(*), SUM(geo.lat) FROM points")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	require.True(t, rows.Next())
+	var count int
+	var sum float64
+	require.NoError(t, rows.Scan(&count, &sum))
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 4.0, sum)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/adrianolaselva/dataql/pkg/dbconnector"
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
 	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/adrianolaselva/dataql/pkg/tlsconfig"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -34,6 +35,8 @@ type mongoHandler struct {
 	limitLines  int
 	currentLine int
 	collection  string
+	naming      filehandler.ColumnNaming
+	tls         tlsconfig.Options
 }
 
 // NewMongoHandler creates a new MongoDB file handler
@@ -57,6 +60,7 @@ func (m *mongoHandler) Import() error {
 		User:     m.connInfo.User,
 		Password: m.connInfo.Password,
 		Database: m.connInfo.Database,
+		TLS:      m.tls,
 	}
 
 	connector, err := dbconnector.NewMongoDBConnector(config)
@@ -90,9 +94,13 @@ func (m *mongoHandler) Import() error {
 	}
 
 	// Convert schema to column names
-	columns := make([]string, len(schema))
+	raw := make([]string, len(schema))
 	for i, col := range schema {
-		columns[i] = m.sanitizeName(col.Name)
+		raw[i] = col.Name
+	}
+	columns, err := m.naming.Or(filehandler.NameLower).Names(raw)
+	if err != nil {
+		return err
 	}
 
 	// Build table structure
@@ -160,6 +168,17 @@ func (m *mongoHandler) sanitizeName(name string) string {
 	return nonAlphanumericRegex.ReplaceAllString(name, "")
 }
 
+// SetColumnNaming sets how column names are derived from document fields
+func (m *mongoHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	m.naming = naming
+}
+
+// SetTLS sets the CAs trusted for the server certificate, or turns off its
+// verification
+func (m *mongoHandler) SetTLS(opts tlsconfig.Options) {
+	m.tls = opts
+}
+
 // Lines returns total lines count
 func (m *mongoHandler) Lines() int {
 	return m.totalLines
@@ -7,7 +7,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
-	"sort"
 	"strings"
 
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
@@ -30,6 +29,7 @@ type MQHandler struct {
 	tableName  string
 	totalLines int
 	limitLines int
+	naming     filehandler.ColumnNaming
 }
 
 // NewMQHandler creates a new message queue file handler
@@ -111,12 +111,11 @@ func (h *MQHandler) Import() error {
 		}
 	}
 
-	// Sort columns for consistent ordering
-	columns := make([]string, 0, len(columnsSet))
-	for col := range columnsSet {
-		columns = append(columns, col)
+	// Sort columns for consistent ordering, reading values by key
+	keys, columns, err := h.naming.Or(filehandler.NameLower).Columns(columnsSet)
+	if err != nil {
+		return err
 	}
-	sort.Strings(columns)
 
 	// Build table structure
 	if err := h.storage.BuildStructure(h.tableName, columns); err != nil {
@@ -131,7 +130,7 @@ func (h *MQHandler) Import() error {
 	// Insert records
 	for i, record := range records {
 		values := make([]any, len(columns))
-		for idx, col := range columns {
+		for idx, col := range keys {
 			if val, ok := record[col]; ok {
 				values[idx] = val
 			} else {
@@ -167,8 +166,7 @@ func (h *MQHandler) messageToRecord(msg mqreader.Message) map[string]string {
 
 	// Add metadata fields with prefix
 	for k, v := range msg.Metadata {
-		colName := sanitizeColumnName("meta_" + k)
-		record[colName] = v
+		record["meta_"+k] = v
 	}
 
 	// Store raw body
@@ -176,7 +174,7 @@ func (h *MQHandler) messageToRecord(msg mqreader.Message) map[string]string {
 
 	// Try to parse body as JSON and flatten
 	var bodyData map[string]interface{}
-	if err := json.Unmarshal([]byte(msg.Body), &bodyData); err == nil {
+	if err := filehandler.DecodeJSON([]byte(msg.Body), &bodyData); err == nil {
 		// Flatten the JSON body
 		flattened := flattenMap(bodyData, "body")
 		for k, v := range flattened {
@@ -196,7 +194,6 @@ func flattenMap(data map[string]interface{}, prefix string) map[string]string {
 		if prefix != "" {
 			fullKey = prefix + "_" + key
 		}
-		fullKey = sanitizeColumnName(fullKey)
 
 		switch v := value.(type) {
 		case map[string]interface{}:
@@ -211,6 +208,9 @@ func flattenMap(data map[string]interface{}, prefix string) map[string]string {
 			result[fullKey] = string(jsonBytes)
 		case nil:
 			result[fullKey] = ""
+		case json.Number:
+			// The exact digits, which float64 may round
+			result[fullKey] = v.String()
 		case float64:
 			// Handle numbers - check if it's an integer
 			if v == float64(int64(v)) {
@@ -232,15 +232,6 @@ func flattenMap(data map[string]interface{}, prefix string) map[string]string {
 	return result
 }
 
-// sanitizeColumnName sanitizes a string to be used as a SQL column name
-func sanitizeColumnName(name string) string {
-	name = strings.ReplaceAll(name, ".", "_")
-	name = strings.ReplaceAll(name, "-", "_")
-	name = strings.ReplaceAll(name, " ", "_")
-	name = strings.ToLower(name)
-	return nonAlphanumericRegex.ReplaceAllString(name, "")
-}
-
 // sanitizeTableName sanitizes a string to be used as a SQL table name
 func sanitizeTableName(name string) string {
 	name = strings.ReplaceAll(name, ".", "_")
@@ -250,6 +241,11 @@ func sanitizeTableName(name string) string {
 	return nonAlphanumericRegex.ReplaceAllString(name, "")
 }
 
+// SetColumnNaming sets how column names are derived from message fields
+func (h *MQHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	h.naming = naming
+}
+
 // Lines returns total lines (messages) count
 func (h *MQHandler) Lines() int {
 	return h.totalLines
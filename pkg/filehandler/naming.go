@@ -0,0 +1,197 @@
+package filehandler
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NameCase is how column names are derived from the headers and keys of
+// the sources
+type NameCase string
+
+const (
+	NameDefault NameCase = ""      // The rule of the format: CSV headers as is, the keys and headers of other formats lower
+	NameSnake   NameCase = "snake" // snake_case: "First Name", "firstName" and "first-name" become first_name
+	NameLower   NameCase = "lower" // Lower case with spaces, dots and dashes as _: "First Name" becomes first_name, "firstName" firstname
+	NameKeep    NameCase = "keep"  // As in the source, quoted in SQL: SELECT "First Name"
+)
+
+// NameCases lists the accepted values of --column-names
+var NameCases = []NameCase{NameSnake, NameLower, NameKeep}
+
+// How duplicate column names are resolved
+const (
+	DedupeSuffix = "suffix" // The second id becomes id_2, the third id_3
+	DedupeError  = "error"  // The import fails
+)
+
+// DedupeModes lists the accepted values of --column-dedupe
+var DedupeModes = []string{DedupeSuffix, DedupeError}
+
+// separators become _ in lower and snake names
+var separators = regexp.MustCompile(`[\s.\-]+`)
+
+// illegalNameCharacters are replaced in lower and snake names
+var illegalNameCharacters = regexp.MustCompile(`[^a-z0-9_]`)
+
+// underscores are collapsed in snake names
+var underscores = regexp.MustCompile(`_+`)
+
+// ColumnNaming is how the handlers turn source headers and keys into
+// column names. The zero value keeps the rule of each format.
+type ColumnNaming struct {
+	Case        NameCase
+	Replacement string // Replaces characters other than letters, digits and _ in lower and snake names (default: removed)
+	Dedupe      string // DedupeSuffix (default) or DedupeError
+}
+
+// ParseColumnNaming validates the values of --column-names,
+// --column-replace and --column-dedupe
+func ParseColumnNaming(nameCase, replacement, dedupe string) (ColumnNaming, error) {
+	naming := ColumnNaming{Replacement: replacement, Dedupe: strings.ToLower(dedupe)}
+	if nameCase != "" {
+		for _, c := range NameCases {
+			if NameCase(strings.ToLower(nameCase)) == c {
+				naming.Case = c
+			}
+		}
+		if naming.Case == NameDefault {
+			return ColumnNaming{}, fmt.Errorf("invalid --column-names value %q (expected snake, lower or keep)", nameCase)
+		}
+	}
+	if illegalNameCharacters.MatchString(replacement) {
+		return ColumnNaming{}, fmt.Errorf("invalid --column-replace value %q (only letters, digits and _ are allowed)", replacement)
+	}
+	if naming.Dedupe != "" && naming.Dedupe != DedupeSuffix && naming.Dedupe != DedupeError {
+		return ColumnNaming{}, fmt.Errorf("invalid --column-dedupe value %q (expected suffix or error)", dedupe)
+	}
+	return naming, nil
+}
+
+// Or returns the naming with nameCase when no case was chosen, so each
+// handler keeps the rule of its format by default
+func (n ColumnNaming) Or(nameCase NameCase) ColumnNaming {
+	if n.Case == NameDefault {
+		n.Case = nameCase
+	}
+	return n
+}
+
+// Name returns the column name of a header or key, before de-duplication
+func (n ColumnNaming) Name(raw string) string {
+	switch n.Case {
+	case NameKeep:
+		return raw
+	case NameSnake:
+		name := separators.ReplaceAllString(strings.TrimSpace(splitWords(raw)), "_")
+		name = illegalNameCharacters.ReplaceAllString(strings.ToLower(name), n.Replacement)
+		return strings.Trim(underscores.ReplaceAllString(name, "_"), "_")
+	default:
+		name := separators.ReplaceAllString(strings.TrimSpace(raw), "_")
+		return illegalNameCharacters.ReplaceAllString(strings.ToLower(name), n.Replacement)
+	}
+}
+
+// Names returns the column names of the headers or keys of a source: empty
+// names become column_1, column_2... by position, and names repeated,
+// regardless of case as SQL compares them, get a _2, _3... suffix, or an
+// error with DedupeError.
+func (n ColumnNaming) Names(raw []string) ([]string, error) {
+	names := make([]string, len(raw))
+	taken := make(map[string]bool, len(raw))
+	for i, header := range raw {
+		name := n.Name(header)
+		if strings.TrimSpace(name) == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		names[i] = name
+	}
+	for _, name := range names {
+		taken[strings.ToLower(name)] = true
+	}
+
+	seen := make(map[string]bool, len(raw))
+	for i, name := range names {
+		key := strings.ToLower(name)
+		if !seen[key] {
+			seen[key] = true
+			continue
+		}
+		if n.Dedupe == DedupeError {
+			if name != raw[i] {
+				name = fmt.Sprintf("%s (from %q)", strconv.Quote(name), raw[i])
+			} else {
+				name = strconv.Quote(name)
+			}
+			return nil, fmt.Errorf("duplicate column name %s; rename it in the source or use --column-dedupe suffix", name)
+		}
+		for suffix := 2; ; suffix++ {
+			candidate := fmt.Sprintf("%s_%d", name, suffix)
+			if !taken[strings.ToLower(candidate)] {
+				names[i] = candidate
+				taken[strings.ToLower(candidate)] = true
+				seen[strings.ToLower(candidate)] = true
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+// Columns orders the keys of flattened records by their column names and
+// returns both, so values are read by key and written by name
+func (n ColumnNaming) Columns(keys map[string]struct{}) ([]string, []string, error) {
+	ordered := make([]string, 0, len(keys))
+	for key := range keys {
+		ordered = append(ordered, key)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		a, b := n.Name(ordered[i]), n.Name(ordered[j])
+		if a != b {
+			return a < b
+		}
+		return ordered[i] < ordered[j]
+	})
+
+	names, err := n.Names(ordered)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ordered, names, nil
+}
+
+// splitWords puts a space between the words of camelCase and PascalCase
+// names: "userID" becomes "user ID", "HTTPServer" "HTTP Server"
+func splitWords(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteRune(' ')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ColumnNamingHandler is an optional interface for handlers deriving
+// column names from the headers or keys of the sources
+type ColumnNamingHandler interface {
+	FileHandler
+	SetColumnNaming(naming ColumnNaming)
+}
+
+// SetColumnNaming sets the column naming of handlers that support it
+func SetColumnNaming(handler FileHandler, naming ColumnNaming) {
+	if named, ok := handler.(ColumnNamingHandler); ok {
+		named.SetColumnNaming(naming)
+	}
+}
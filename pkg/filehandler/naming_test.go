@@ -0,0 +1,81 @@
+package filehandler_test
+
+import (
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnNaming_Name(t *testing.T) {
+	tests := []struct {
+		naming   filehandler.ColumnNaming
+		input    string
+		expected string
+	}{
+		{filehandler.ColumnNaming{Case: filehandler.NameLower}, "First Name", "first_name"},
+		{filehandler.ColumnNaming{Case: filehandler.NameLower}, "  user.e-mail  ", "user_e_mail"},
+		{filehandler.ColumnNaming{Case: filehandler.NameLower}, "firstName", "firstname"},
+		{filehandler.ColumnNaming{Case: filehandler.NameLower}, "price ($)", "price_"},
+		{filehandler.ColumnNaming{Case: filehandler.NameLower, Replacement: "x"}, "a@b", "axb"},
+		{filehandler.ColumnNaming{Case: filehandler.NameSnake}, "firstName", "first_name"},
+		{filehandler.ColumnNaming{Case: filehandler.NameSnake}, "HTTPServer", "http_server"},
+		{filehandler.ColumnNaming{Case: filehandler.NameSnake}, "userID2", "user_id2"},
+		{filehandler.ColumnNaming{Case: filehandler.NameSnake}, "Price ($)", "price"},
+		{filehandler.ColumnNaming{Case: filehandler.NameSnake}, "first -- name", "first_name"},
+		{filehandler.ColumnNaming{Case: filehandler.NameKeep}, "First Name", "First Name"},
+		{filehandler.ColumnNaming{}, "First Name", "first_name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.naming.Case)+"/"+tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.naming.Name(tt.input))
+		})
+	}
+}
+
+func TestColumnNaming_Names(t *testing.T) {
+	t.Run("suffixes duplicates regardless of case", func(t *testing.T) {
+		names, err := filehandler.ColumnNaming{Case: filehandler.NameKeep}.Names([]string{"id", "ID", "id_2", "id"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "ID_3", "id_2", "id_4"}, names)
+	})
+
+	t.Run("names empty headers by position", func(t *testing.T) {
+		names, err := filehandler.ColumnNaming{Case: filehandler.NameLower}.Names([]string{"a", "", "$$"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "column_2", "column_3"}, names)
+	})
+
+	t.Run("fails on duplicates with error", func(t *testing.T) {
+		_, err := filehandler.ColumnNaming{Case: filehandler.NameLower, Dedupe: filehandler.DedupeError}.Names([]string{"First Name", "first_name"})
+		assert.ErrorContains(t, err, `duplicate column name "first_name"`)
+	})
+}
+
+func TestColumnNaming_Columns(t *testing.T) {
+	keys, names, err := filehandler.ColumnNaming{Case: filehandler.NameLower}.Columns(map[string]struct{}{
+		"Name": {}, "age": {}, "name": {},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"age", "Name", "name"}, keys)
+	assert.Equal(t, []string{"age", "name", "name_2"}, names)
+}
+
+func TestParseColumnNaming(t *testing.T) {
+	naming, err := filehandler.ParseColumnNaming("SNAKE", "_", "Error")
+	require.NoError(t, err)
+	assert.Equal(t, filehandler.ColumnNaming{Case: filehandler.NameSnake, Replacement: "_", Dedupe: filehandler.DedupeError}, naming)
+
+	naming, err = filehandler.ParseColumnNaming("", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, filehandler.NameLower, naming.Or(filehandler.NameLower).Case)
+
+	_, err = filehandler.ParseColumnNaming("camel", "", "")
+	assert.ErrorContains(t, err, "invalid --column-names")
+	_, err = filehandler.ParseColumnNaming("", "-", "")
+	assert.ErrorContains(t, err, "invalid --column-replace")
+	_, err = filehandler.ParseColumnNaming("", "", "drop")
+	assert.ErrorContains(t, err, "invalid --column-dedupe")
+}
@@ -0,0 +1,125 @@
+package filehandler
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// Nested is how nested JSON objects and arrays are imported
+type Nested string
+
+const (
+	NestedFlatten Nested = "flatten" // Objects become prefixed columns (user_name), arrays JSON text
+	NestedStruct  Nested = "struct"  // Objects become STRUCT columns (user.name), arrays LIST columns
+	NestedJSON    Nested = "json"    // Objects and arrays become JSON columns
+)
+
+// NestedModes lists the accepted values of --json-nested
+var NestedModes = []Nested{NestedFlatten, NestedStruct, NestedJSON}
+
+// ParseNested parses a --json-nested value, flatten when empty
+func ParseNested(value string) (Nested, error) {
+	if value == "" {
+		return NestedFlatten, nil
+	}
+	for _, mode := range NestedModes {
+		if Nested(strings.ToLower(value)) == mode {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("invalid --json-nested value %q (expected flatten, struct or json)", value)
+}
+
+// nestedType matches the DuckDB types of nested values
+var nestedType = regexp.MustCompile(`^(STRUCT|MAP)\(|\]$`)
+
+// ImportNested imports a JSON array, object or JSONL file into tableName
+// with DuckDB's JSON reader, keeping nested objects and arrays as STRUCT and
+// LIST columns, or as JSON columns with NestedJSON. Top-level keys become
+// column names as naming says, e.g. "User Name" becomes user_name; the
+// fields of structs keep their keys. Rows are appended when
+// the table exists. It returns the number of rows imported.
+func ImportNested(st storage.Storage, tableName, filePath string, mode Nested, newlineDelimited bool, limitLines int, naming ColumnNaming) (int, error) {
+	typedStorage, ok := st.(storage.TypedStorage)
+	if !ok {
+		return 0, fmt.Errorf("nested JSON columns are not supported by the storage")
+	}
+
+	format := "auto"
+	if newlineDelimited {
+		format = "newline_delimited"
+	}
+	source := fmt.Sprintf("read_json('%s', format = '%s')", strings.ReplaceAll(filePath, "'", "''"), format)
+
+	columns, err := describe(st, source)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read nested JSON: %w", err)
+	}
+	if len(columns) == 0 {
+		return 0, st.BuildStructure(tableName, []string{"_empty"})
+	}
+
+	raw := make([]string, len(columns))
+	for i, column := range columns {
+		raw[i] = column.Name
+	}
+	names, err := naming.Names(raw)
+	if err != nil {
+		return 0, err
+	}
+
+	defs := make([]storage.ColumnDef, len(columns))
+	selected := make([]string, len(columns))
+	for i, column := range columns {
+		name := names[i]
+		defs[i] = storage.ColumnDef{Name: name, Type: column.Type}
+		expression := storage.QuoteIdentifier(column.Name)
+		if mode == NestedJSON && nestedType.MatchString(string(column.Type)) {
+			defs[i].Type = "JSON"
+			expression = "to_json(" + expression + ")"
+		}
+		selected[i] = expression + " AS " + storage.QuoteIdentifier(name)
+	}
+
+	if err := typedStorage.BuildStructureWithTypes(tableName, defs); err != nil {
+		return 0, fmt.Errorf("failed to build structure: %w", err)
+	}
+
+	statement := fmt.Sprintf("INSERT INTO %s BY NAME SELECT %s FROM %s", storage.QuoteIdentifier(tableName), strings.Join(selected, ", "), source)
+	if limitLines > 0 {
+		statement += fmt.Sprintf(" LIMIT %d", limitLines)
+	}
+	rows, err := st.Query(statement + " RETURNING 1")
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert nested JSON: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		count++
+	}
+	return count, rows.Err()
+}
+
+// describe returns the columns read by a table function
+func describe(st storage.Storage, source string) ([]storage.ColumnDef, error) {
+	rows, err := st.Query("SELECT column_name, column_type FROM (DESCRIBE SELECT * FROM " + source + ")")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []storage.ColumnDef
+	for rows.Next() {
+		var column storage.ColumnDef
+		if err := rows.Scan(&column.Name, &column.Type); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
 	"github.com/adrianolaselva/dataql/pkg/storage"
 	"github.com/schollz/progressbar/v3"
 	"github.com/scritchley/orc"
@@ -23,6 +24,7 @@ type orcHandler struct {
 	currentLine int
 	collection  string
 	aliases     map[string]string // Map of file path -> table alias
+	naming      filehandler.ColumnNaming
 }
 
 // NewOrcHandler creates a new ORC file handler
@@ -60,6 +62,8 @@ func (o *orcHandler) Import() error {
 
 // importFile imports a single ORC file
 func (o *orcHandler) importFile(filePath string) error {
+	o.bar.Describe(progress.Description(progress.Import, filePath))
+
 	// Open ORC file
 	reader, err := orc.Open(filePath)
 	if err != nil {
@@ -73,9 +77,9 @@ func (o *orcHandler) importFile(filePath string) error {
 	// Get schema
 	schema := reader.Schema()
 	schemaColumns := schema.Columns()
-	columns := make([]string, len(schemaColumns))
-	for i, col := range schemaColumns {
-		columns[i] = o.sanitizeName(col)
+	columns, err := o.naming.Or(filehandler.NameLower).Names(schemaColumns)
+	if err != nil {
+		return err
 	}
 
 	if len(columns) == 0 {
@@ -134,16 +138,6 @@ func (o *orcHandler) importFile(filePath string) error {
 	return nil
 }
 
-// sanitizeName sanitizes a string to be used as a SQL identifier
-func (o *orcHandler) sanitizeName(name string) string {
-	name = strings.TrimSpace(name)
-	name = strings.ReplaceAll(name, ".", "_")
-	name = strings.ReplaceAll(name, " ", "_")
-	name = strings.ReplaceAll(name, "-", "_")
-	name = strings.ToLower(name)
-	return nonAlphanumericRegex.ReplaceAllString(name, "")
-}
-
 // formatTableName formats table name from file path
 func (o *orcHandler) formatTableName(filePath string) string {
 	// Check if there's an alias for this file
@@ -167,6 +161,11 @@ func (o *orcHandler) formatTableName(filePath string) string {
 	return nonAlphanumericRegex.ReplaceAllString(tableName, "")
 }
 
+// SetColumnNaming sets how column names are derived from the schema
+func (o *orcHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	o.naming = naming
+}
+
 // Lines returns total lines count
 func (o *orcHandler) Lines() int {
 	return o.totalLines
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
 	"github.com/adrianolaselva/dataql/pkg/storage"
 	"github.com/schollz/progressbar/v3"
 	"github.com/xitongsys/parquet-go-source/local"
@@ -24,6 +25,7 @@ type parquetHandler struct {
 	currentLine int
 	collection  string
 	aliases     map[string]string // Map of file path -> table alias
+	naming      filehandler.ColumnNaming
 }
 
 // NewParquetHandler creates a new Parquet file handler
@@ -61,6 +63,8 @@ func (p *parquetHandler) Import() error {
 
 // loadFile loads a single Parquet file
 func (p *parquetHandler) loadFile(filePath string) error {
+	p.bar.Describe(progress.Description(progress.Import, filePath))
+
 	// Open the file
 	fr, err := local.NewLocalFileReader(filePath)
 	if err != nil {
@@ -95,14 +99,18 @@ func (p *parquetHandler) loadFile(filePath string) error {
 	for i := 0; i < len(schemaHandler.SchemaElements); i++ {
 		elem := schemaHandler.SchemaElements[i]
 		if elem.GetNumChildren() == 0 { // Leaf node (actual column)
-			colName := p.sanitizeColumnName(elem.GetName())
-			columns = append(columns, colName)
+			columns = append(columns, elem.GetName())
 			// Get the path for this column
 			path := schemaHandler.IndexMap[int32(i)]
 			columnPaths = append(columnPaths, path)
 		}
 	}
 
+	columns, err = p.naming.Or(filehandler.NameLower).Names(columns)
+	if err != nil {
+		return err
+	}
+
 	if len(columns) == 0 {
 		columns = []string{"_empty"}
 		if err := p.storage.BuildStructure(tableName, columns); err != nil {
@@ -156,16 +164,6 @@ func (p *parquetHandler) loadFile(filePath string) error {
 	return nil
 }
 
-// sanitizeColumnName sanitizes a string to be used as a SQL column name
-func (p *parquetHandler) sanitizeColumnName(name string) string {
-	name = strings.TrimSpace(name)
-	name = strings.ReplaceAll(name, ".", "_")
-	name = strings.ReplaceAll(name, " ", "_")
-	name = strings.ReplaceAll(name, "-", "_")
-	name = strings.ToLower(name)
-	return nonAlphanumericRegex.ReplaceAllString(name, "")
-}
-
 // formatTableName formats table name from file path
 func (p *parquetHandler) formatTableName(filePath string) string {
 	// Check if there's an alias for this file
@@ -188,6 +186,11 @@ func (p *parquetHandler) formatTableName(filePath string) string {
 	return nonAlphanumericRegex.ReplaceAllString(tableName, "")
 }
 
+// SetColumnNaming sets how column names are derived from the schema
+func (p *parquetHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	p.naming = naming
+}
+
 // Lines returns total lines count
 func (p *parquetHandler) Lines() int {
 	return p.totalLines
@@ -9,6 +9,7 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/schollz/progressbar/v3"
 
+	"github.com/adrianolaselva/dataql/pkg/filehandler"
 	"github.com/adrianolaselva/dataql/pkg/storage"
 )
 
@@ -20,6 +21,7 @@ type SqliteHandler struct {
 	limitLines int
 	collection string
 	lines      int
+	naming     filehandler.ColumnNaming
 }
 
 // NewSqliteHandler creates a new SQLite file handler
@@ -104,6 +106,12 @@ func (h *SqliteHandler) importTable(sourceDB *sql.DB, sourceTable, targetTable s
 	if err != nil {
 		return fmt.Errorf("failed to get columns for table %s: %w", sourceTable, err)
 	}
+	if columns, err = h.naming.Or(filehandler.NameKeep).Names(columns); err != nil {
+		return fmt.Errorf("failed to name columns for table %s: %w", sourceTable, err)
+	}
+	for i := range columnDefs {
+		columnDefs[i].Name = columns[i]
+	}
 
 	// Build structure in target storage with types if supported
 	if typedStorage, ok := h.storage.(storage.TypedStorage); ok {
@@ -210,6 +218,11 @@ func mapSQLiteType(sqliteType string) storage.DataType {
 	}
 }
 
+// SetColumnNaming sets how column names are derived from the source columns
+func (h *SqliteHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	h.naming = naming
+}
+
 // Lines returns the number of lines imported
 func (h *SqliteHandler) Lines() int {
 	return h.lines
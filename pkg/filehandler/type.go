@@ -1,7 +1,23 @@
 package filehandler
 
+import "github.com/adrianolaselva/dataql/pkg/tlsconfig"
+
 type FileHandler interface {
 	Import() error
 	Lines() int
 	Close() error
 }
+
+// TLSHandler is an optional interface for handlers connecting to databases
+// over TLS
+type TLSHandler interface {
+	FileHandler
+	SetTLS(opts tlsconfig.Options)
+}
+
+// SetTLS sets the TLS options of handlers that support them
+func SetTLS(handler FileHandler, opts tlsconfig.Options) {
+	if remote, ok := handler.(TLSHandler); ok {
+		remote.SetTLS(opts)
+	}
+}
@@ -8,10 +8,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
 	"github.com/adrianolaselva/dataql/pkg/storage"
 	"github.com/schollz/progressbar/v3"
 )
@@ -27,6 +27,7 @@ type xmlHandler struct {
 	currentLine int
 	collection  string
 	aliases     map[string]string // Map of file path -> table alias
+	naming      filehandler.ColumnNaming
 }
 
 // NewXmlHandler creates a new XML file handler
@@ -64,6 +65,8 @@ func (x *xmlHandler) Import() error {
 
 // loadFile loads a single XML file
 func (x *xmlHandler) loadFile(filePath string) error {
+	x.bar.Describe(progress.Description(progress.Import, filePath))
+
 	file, err := os.Open(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to open file %s: %w", filePath, err)
@@ -126,7 +129,7 @@ func (x *xmlHandler) parseXML(content []byte) ([]map[string]string, error) {
 					currentRecord = make(map[string]string)
 					// Process attributes for the item element
 					for _, attr := range t.Attr {
-						key := x.sanitizeColumnName(attr.Name.Local)
+						key := attr.Name.Local
 						currentRecord[key] = attr.Value
 					}
 				}
@@ -136,7 +139,7 @@ func (x *xmlHandler) parseXML(content []byte) ([]map[string]string, error) {
 				// Process attributes
 				for _, attr := range t.Attr {
 					prefix := strings.Join(elementStack, "_")
-					key := x.sanitizeColumnName(prefix + "_" + attr.Name.Local)
+					key := prefix + "_" + attr.Name.Local
 					currentRecord[key] = attr.Value
 				}
 			}
@@ -158,7 +161,7 @@ func (x *xmlHandler) parseXML(content []byte) ([]map[string]string, error) {
 				text := strings.TrimSpace(charData.String())
 				if text != "" {
 					prefix := strings.Join(elementStack, "_")
-					key := x.sanitizeColumnName(prefix)
+					key := prefix
 					currentRecord[key] = text
 				}
 				elementStack = elementStack[:len(elementStack)-1]
@@ -207,13 +210,13 @@ func (x *xmlHandler) parseAsSingleObject(content []byte) (map[string]string, err
 				// Process attributes
 				for _, attr := range t.Attr {
 					prefix := strings.Join(elementStack, "_")
-					key := x.sanitizeColumnName(prefix + "_" + attr.Name.Local)
+					key := prefix + "_" + attr.Name.Local
 					record[key] = attr.Value
 				}
 			} else {
 				// Root element attributes
 				for _, attr := range t.Attr {
-					key := x.sanitizeColumnName(attr.Name.Local)
+					key := attr.Name.Local
 					record[key] = attr.Value
 				}
 			}
@@ -224,7 +227,7 @@ func (x *xmlHandler) parseAsSingleObject(content []byte) (map[string]string, err
 				text := strings.TrimSpace(charData.String())
 				if text != "" {
 					prefix := strings.Join(elementStack, "_")
-					key := x.sanitizeColumnName(prefix)
+					key := prefix
 					record[key] = text
 				}
 				elementStack = elementStack[:len(elementStack)-1]
@@ -258,12 +261,11 @@ func (x *xmlHandler) importRecords(tableName string, records []map[string]string
 		}
 	}
 
-	// Sort columns for consistent ordering
-	columns := make([]string, 0, len(columnsSet))
-	for col := range columnsSet {
-		columns = append(columns, col)
+	// Sort columns for consistent ordering, reading values by key
+	keys, columns, err := x.naming.Or(filehandler.NameLower).Columns(columnsSet)
+	if err != nil {
+		return err
 	}
-	sort.Strings(columns)
 
 	// Collect sample rows for type inference (up to 100 rows)
 	sampleSize := 100
@@ -273,7 +275,7 @@ func (x *xmlHandler) importRecords(tableName string, records []map[string]string
 	sampleRows := make([][]any, sampleSize)
 	for i := 0; i < sampleSize; i++ {
 		row := make([]any, len(columns))
-		for idx, col := range columns {
+		for idx, col := range keys {
 			if val, ok := records[i][col]; ok {
 				row[idx] = val
 			} else {
@@ -314,13 +316,12 @@ func (x *xmlHandler) importRecords(tableName string, records []map[string]string
 		}
 
 		values := make([]any, len(columns))
-		for idx, col := range columns {
+		for idx, col := range keys {
 			if val, ok := record[col]; ok && val != "" {
 				values[idx] = val
 			} else {
 				// For numeric/boolean columns, use nil instead of empty string
-				if columnDefs[idx].Type == storage.TypeBigInt ||
-					columnDefs[idx].Type == storage.TypeDouble ||
+				if columnDefs[idx].Type.IsNumeric() ||
 					columnDefs[idx].Type == storage.TypeBoolean {
 					values[idx] = nil
 				} else {
@@ -346,15 +347,6 @@ func (x *xmlHandler) importRecords(tableName string, records []map[string]string
 	return nil
 }
 
-// sanitizeColumnName sanitizes a string to be used as a SQL column name
-func (x *xmlHandler) sanitizeColumnName(name string) string {
-	name = strings.ReplaceAll(name, ".", "_")
-	name = strings.ReplaceAll(name, " ", "_")
-	name = strings.ReplaceAll(name, "-", "_")
-	name = strings.ToLower(name)
-	return nonAlphanumericRegex.ReplaceAllString(name, "")
-}
-
 // formatTableName formats table name from file path
 func (x *xmlHandler) formatTableName(filePath string) string {
 	// Check if there's an alias for this file
@@ -377,6 +369,11 @@ func (x *xmlHandler) formatTableName(filePath string) string {
 	return nonAlphanumericRegex.ReplaceAllString(tableName, "")
 }
 
+// SetColumnNaming sets how column names are derived from elements and attributes
+func (x *xmlHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	x.naming = naming
+}
+
 // Lines returns total lines count
 func (x *xmlHandler) Lines() int {
 	return x.totalLines
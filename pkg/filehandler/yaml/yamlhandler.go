@@ -5,10 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 
 	"github.com/adrianolaselva/dataql/pkg/filehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
 	"github.com/adrianolaselva/dataql/pkg/storage"
 	"github.com/schollz/progressbar/v3"
 	"gopkg.in/yaml.v3"
@@ -25,6 +25,7 @@ type yamlHandler struct {
 	currentLine int
 	collection  string
 	aliases     map[string]string // Map of file path -> table alias
+	naming      filehandler.ColumnNaming
 }
 
 // NewYamlHandler creates a new YAML file handler
@@ -62,6 +63,8 @@ func (y *yamlHandler) Import() error {
 
 // importFile imports a single YAML file
 func (y *yamlHandler) importFile(filePath string) error {
+	y.bar.Describe(progress.Description(progress.Import, filePath))
+
 	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to read YAML file: %w", err)
@@ -70,11 +73,18 @@ func (y *yamlHandler) importFile(filePath string) error {
 	// Determine collection name
 	collectionName := y.formatTableName(filePath)
 
-	// Parse YAML
-	var data interface{}
-	if err := yaml.Unmarshal(content, &data); err != nil {
+	// Parse YAML, keeping the digits of numbers that float64 may round
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
 		return fmt.Errorf("failed to parse YAML: %w", err)
 	}
+	var data interface{}
+	if root.Kind != 0 {
+		exactNumbers(&root)
+		if err := root.Decode(&data); err != nil {
+			return fmt.Errorf("failed to parse YAML: %w", err)
+		}
+	}
 
 	// Handle different YAML structures
 	var records []map[string]interface{}
@@ -118,19 +128,18 @@ func (y *yamlHandler) importFile(filePath string) error {
 	}
 
 	// Extract and flatten all columns
-	columnSet := make(map[string]bool)
+	columnSet := make(map[string]struct{})
 	for _, record := range records {
 		flatRecord := y.flattenMap(record, "")
 		for col := range flatRecord {
-			columnSet[col] = true
+			columnSet[col] = struct{}{}
 		}
 	}
 
-	columns := make([]string, 0, len(columnSet))
-	for col := range columnSet {
-		columns = append(columns, col)
+	keys, columns, err := y.naming.Or(filehandler.NameLower).Columns(columnSet)
+	if err != nil {
+		return err
 	}
-	sort.Strings(columns)
 
 	// Collect sample rows for type inference (up to 100 rows)
 	sampleSize := 100
@@ -141,7 +150,7 @@ func (y *yamlHandler) importFile(filePath string) error {
 	for i := 0; i < sampleSize; i++ {
 		flatRecord := y.flattenMap(records[i], "")
 		row := make([]any, len(columns))
-		for idx, col := range columns {
+		for idx, col := range keys {
 			if val, ok := flatRecord[col]; ok {
 				row[idx] = val
 			} else {
@@ -176,13 +185,12 @@ func (y *yamlHandler) importFile(filePath string) error {
 
 		flatRecord := y.flattenMap(record, "")
 		values := make([]any, len(columns))
-		for j, col := range columns {
+		for j, col := range keys {
 			if val, ok := flatRecord[col]; ok && val != "" {
 				values[j] = val
 			} else {
 				// For numeric/boolean columns, use nil instead of empty string
-				if columnDefs[j].Type == storage.TypeBigInt ||
-					columnDefs[j].Type == storage.TypeDouble ||
+				if columnDefs[j].Type.IsNumeric() ||
 					columnDefs[j].Type == storage.TypeBoolean {
 					values[j] = nil
 				} else {
@@ -218,7 +226,6 @@ func (y *yamlHandler) flattenMap(data map[string]interface{}, prefix string) map
 		if prefix != "" {
 			fullKey = prefix + "_" + key
 		}
-		fullKey = y.sanitizeName(fullKey)
 
 		switch v := value.(type) {
 		case map[string]interface{}:
@@ -242,6 +249,20 @@ func (y *yamlHandler) flattenMap(data map[string]interface{}, prefix string) map
 	return result
 }
 
+// plainNumber matches numbers in plain notation
+var plainNumber = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]*)?$`)
+
+// exactNumbers makes the numbers in plain notation decode as strings, so
+// that large integers and decimals keep their digits and get exact types
+func exactNumbers(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && (node.Tag == "!!float" || node.Tag == "!!int") && plainNumber.MatchString(node.Value) {
+		node.Tag = "!!str"
+	}
+	for _, child := range node.Content {
+		exactNumbers(child)
+	}
+}
+
 // formatTableName formats table name from file path
 func (y *yamlHandler) formatTableName(filePath string) string {
 	// Check if there's an alias for this file
@@ -265,14 +286,9 @@ func (y *yamlHandler) formatTableName(filePath string) string {
 	return nonAlphanumericRegex.ReplaceAllString(tableName, "")
 }
 
-// sanitizeName sanitizes a string to be used as a SQL identifier
-func (y *yamlHandler) sanitizeName(name string) string {
-	name = strings.TrimSpace(name)
-	name = strings.ReplaceAll(name, ".", "_")
-	name = strings.ReplaceAll(name, " ", "_")
-	name = strings.ReplaceAll(name, "-", "_")
-	name = strings.ToLower(name)
-	return nonAlphanumericRegex.ReplaceAllString(name, "")
+// SetColumnNaming sets how column names are derived from keys
+func (y *yamlHandler) SetColumnNaming(naming filehandler.ColumnNaming) {
+	y.naming = naming
 }
 
 // Lines returns total lines count
@@ -7,9 +7,14 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"cloud.google.com/go/storage"
+	"github.com/adrianolaselva/dataql/pkg/cachehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
+	"github.com/adrianolaselva/dataql/pkg/rangedownload"
+	"google.golang.org/api/option"
 )
 
 // GCSHandler handles downloading files from Google Cloud Storage
@@ -17,6 +22,18 @@ type GCSHandler struct {
 	tempDir   string
 	tempFiles []string
 	client    *storage.Client
+	cache     *cachehandler.DownloadCache // Keeps downloads between runs (nil: disabled)
+	download  rangedownload.Options       // Parts of large objects
+	progress  *progress.Reporter          // Shows the downloads (nil: not shown)
+	options   Options
+}
+
+// Options are the explicit credentials of --gcs-credentials, which take
+// precedence over Application Default Credentials
+type Options struct {
+	// CredentialsFile is a service account key or a workload identity
+	// federation configuration, as created by gcloud
+	CredentialsFile string
 }
 
 // GCSLocation represents a parsed GCS URL
@@ -33,6 +50,37 @@ func NewGCSHandler() *GCSHandler {
 	return &GCSHandler{}
 }
 
+// SetCache keeps downloads in a cache, reused while the object keeps its generation
+func (h *GCSHandler) SetCache(cache *cachehandler.DownloadCache) {
+	h.cache = cache
+}
+
+// SetDownload sets how many parts of large objects are downloaded at the
+// same time and their size
+func (h *GCSHandler) SetDownload(opts rangedownload.Options) {
+	h.download = opts
+}
+
+// SetProgress shows the progress of the downloads with reporter
+func (h *GCSHandler) SetProgress(reporter *progress.Reporter) {
+	h.progress = reporter
+}
+
+// Check validates that the credentials file exists
+func (o Options) Check() error {
+	if o.CredentialsFile != "" {
+		if _, err := os.Stat(o.CredentialsFile); err != nil {
+			return fmt.Errorf("invalid --gcs-credentials: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetOptions sets the credentials of the client
+func (h *GCSHandler) SetOptions(opts Options) {
+	h.options = opts
+}
+
 // IsGCSURL checks if a string is a GCS URL
 func IsGCSURL(path string) bool {
 	return strings.HasPrefix(path, "gs://")
@@ -104,12 +152,43 @@ func (h *GCSHandler) downloadGCSFile(gcsURL string) (string, error) {
 	bucket := h.client.Bucket(loc.Bucket)
 	obj := bucket.Object(loc.Object)
 
+	attrs, err := obj.Attrs(ctx)
+	if err == nil {
+		// Reuse a cached download of the same generation of the object
+		if h.cache != nil {
+			if cached, ok := h.cache.Lookup(gcsURL); ok && cached.Matches(attrs.Etag, strconv.FormatInt(attrs.Generation, 10)) {
+				return h.cache.Use(cached), nil
+			}
+		}
+		// Read the generation that was checked, even if the object is replaced meanwhile
+		obj = obj.Generation(attrs.Generation)
+
+		// Download large objects in parts; ranges of gzip-encoded objects
+		// would be read compressed
+		if attrs.ContentEncoding != "gzip" && h.download.Parallel(attrs.Size) {
+			return h.downloadParts(ctx, gcsURL, obj, attrs, localPath)
+		}
+	}
+
 	reader, err := obj.NewReader(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to get GCS object: %w", err)
 	}
 	defer reader.Close()
 
+	phase := h.progress.Start(progress.Download, filename, reader.Attrs.Size)
+	defer phase.Done()
+	body := phase.Reader(reader)
+
+	if h.cache != nil && attrs != nil {
+		return h.cache.Store(cachehandler.Download{
+			Source:    gcsURL,
+			File:      filename,
+			ETag:      attrs.Etag,
+			VersionID: strconv.FormatInt(attrs.Generation, 10),
+		}, body)
+	}
+
 	// Create local file
 	file, err := os.Create(localPath)
 	if err != nil {
@@ -118,7 +197,7 @@ func (h *GCSHandler) downloadGCSFile(gcsURL string) (string, error) {
 	defer file.Close()
 
 	// Copy content
-	_, err = io.Copy(file, reader)
+	_, err = io.Copy(file, body)
 	if err != nil {
 		return "", fmt.Errorf("failed to write file content: %w", err)
 	}
@@ -127,12 +206,54 @@ func (h *GCSHandler) downloadGCSFile(gcsURL string) (string, error) {
 	return localPath, nil
 }
 
-// initClient initializes the GCS client using default credentials
+// downloadParts downloads the generation of obj attrs describes with
+// range reads
+func (h *GCSHandler) downloadParts(ctx context.Context, gcsURL string, obj *storage.ObjectHandle, attrs *storage.ObjectAttrs, localPath string) (string, error) {
+	openRange := func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+		reader, err := obj.NewRangeReader(ctx, offset, length)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GCS object: %w", err)
+		}
+		return reader, nil
+	}
+
+	opts := h.download
+	if phase := h.progress.Start(progress.Download, filepath.Base(localPath), attrs.Size); phase != nil {
+		defer phase.Done()
+		opts.Progress = phase
+	}
+
+	if h.cache != nil {
+		return h.cache.StoreFile(cachehandler.Download{
+			Source:    gcsURL,
+			File:      filepath.Base(localPath),
+			ETag:      attrs.Etag,
+			VersionID: strconv.FormatInt(attrs.Generation, 10),
+		}, func(file *os.File) error {
+			return rangedownload.Download(ctx, file, attrs.Size, openRange, opts)
+		})
+	}
+
+	if err := rangedownload.DownloadFile(ctx, localPath, attrs.Size, openRange, opts); err != nil {
+		return "", fmt.Errorf("failed to write file content: %w", err)
+	}
+	h.tempFiles = append(h.tempFiles, localPath)
+	return localPath, nil
+}
+
+// initClient initializes the GCS client using the credentials file of
+// --gcs-credentials or default credentials
 func (h *GCSHandler) initClient() error {
 	ctx := context.Background()
 
-	// Create GCS client using Application Default Credentials
-	client, err := storage.NewClient(ctx)
+	// Create GCS client using Application Default Credentials: the file of
+	// GOOGLE_APPLICATION_CREDENTIALS, gcloud's login or the metadata server
+	// of GCE and GKE (workload identity)
+	var opts []option.ClientOption
+	if h.options.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(h.options.CredentialsFile))
+	}
+	client, err := storage.NewClient(ctx, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to create GCS client: %w", err)
 	}
@@ -0,0 +1,26 @@
+package gcshandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsCheck(t *testing.T) {
+	assert.NoError(t, Options{}.Check())
+	assert.ErrorContains(t, Options{CredentialsFile: filepath.Join(t.TempDir(), "missing.json")}.Check(), "invalid --gcs-credentials")
+}
+
+func TestCredentialsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"type": "unknown"}`), 0600))
+
+	h := NewGCSHandler()
+	defer h.Cleanup()
+	h.SetOptions(Options{CredentialsFile: path})
+	_, err := h.ResolveFiles([]string{"gs://bucket/data.csv"})
+	assert.ErrorContains(t, err, "failed to initialize GCS client")
+}
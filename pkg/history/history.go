@@ -0,0 +1,220 @@
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	// EnvPath overrides the location of the history database
+	EnvPath = "DATAQL_HISTORY_FILE"
+	// EnvDisable turns the history off when set to a non-empty value
+	EnvDisable = "DATAQL_NO_HISTORY"
+
+	fileName = "history.db"
+
+	// busyTimeoutMs lets concurrent dataql processes wait for each other's writes
+	busyTimeoutMs = 5000
+)
+
+// Callers recorded in the history
+const (
+	CallerCLI  = "cli"
+	CallerREPL = "repl"
+)
+
+const sqlCreateTable = `CREATE TABLE IF NOT EXISTS queries (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp   TEXT    NOT NULL,
+	caller      TEXT    NOT NULL,
+	directory   TEXT    NOT NULL DEFAULT '',
+	sources     TEXT    NOT NULL DEFAULT '[]',
+	storage     TEXT    NOT NULL DEFAULT '',
+	delimiter   TEXT    NOT NULL DEFAULT '',
+	collection  TEXT    NOT NULL DEFAULT '',
+	query       TEXT    NOT NULL,
+	rows        INTEGER NOT NULL DEFAULT 0,
+	duration_ms REAL    NOT NULL DEFAULT 0,
+	error       TEXT    NOT NULL DEFAULT ''
+)`
+
+const sqlSelect = `SELECT id, timestamp, caller, directory, sources, storage, delimiter, collection, query, rows, duration_ms, error FROM queries`
+
+// ErrNotFound is returned by Get for an unknown entry
+var ErrNotFound = errors.New("history entry not found")
+
+// Entry is one executed query
+type Entry struct {
+	ID         int64
+	Timestamp  time.Time
+	Caller     string   // "cli" or "repl"
+	Directory  string   // Working directory, to resolve relative sources on rerun
+	Sources    []string // Sources as given by the user, with URL passwords redacted
+	Storage    string   // DuckDB file given with --storage
+	Delimiter  string
+	Collection string
+	Query      string
+	Rows       int64
+	DurationMs float64
+	Error      string
+}
+
+// Store keeps the history in a SQLite database, which several dataql
+// processes can write at the same time
+type Store struct {
+	db *sql.DB
+}
+
+// DefaultPath returns $DATAQL_HISTORY_FILE or ~/.dataql/history.db
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dataql", fileName), nil
+}
+
+// Open opens (or creates) the history database at path, or at DefaultPath
+// when path is empty. The file is only readable by its owner since queries
+// may contain sensitive values.
+func Open(path string) (*Store, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	_ = file.Close()
+
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_busy_timeout=%d", path, busyTimeoutMs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	if _, err := db.Exec(sqlCreateTable); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Add records an entry and returns its id
+func (s *Store) Add(entry Entry) (int64, error) {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if entry.Sources == nil {
+		entry.Sources = []string{}
+	}
+	sources, err := json.Marshal(entry.Sources)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	result, err := s.db.Exec(
+		`INSERT INTO queries (timestamp, caller, directory, sources, storage, delimiter, collection, query, rows, duration_ms, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Timestamp.UTC().Format(time.RFC3339Nano), entry.Caller, entry.Directory, string(sources),
+		entry.Storage, entry.Delimiter, entry.Collection, entry.Query, entry.Rows, entry.DurationMs, entry.Error)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write history: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// List returns the latest entries, newest first (limit <= 0: all)
+func (s *Store) List(limit int) ([]Entry, error) {
+	return s.query(sqlSelect+` ORDER BY id DESC LIMIT ?`, limitOrAll(limit))
+}
+
+// Search returns the latest entries whose query or sources contain text,
+// ignoring case, newest first (limit <= 0: all)
+func (s *Store) Search(text string, limit int) ([]Entry, error) {
+	pattern := "%" + escapeLike(strings.ToLower(text)) + "%"
+	return s.query(sqlSelect+` WHERE lower(query) LIKE ? ESCAPE '\' OR lower(sources) LIKE ? ESCAPE '\' ORDER BY id DESC LIMIT ?`,
+		pattern, pattern, limitOrAll(limit))
+}
+
+// Get returns the entry with the given id
+func (s *Store) Get(id int64) (Entry, error) {
+	entries, err := s.query(sqlSelect+` WHERE id = ?`, id)
+	if err != nil {
+		return Entry{}, err
+	}
+	if len(entries) == 0 {
+		return Entry{}, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return entries[0], nil
+}
+
+// Clear removes every entry and returns how many were removed
+func (s *Store) Clear() (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM queries`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear history: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) query(query string, args ...any) ([]Entry, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		var timestamp, sources string
+		if err := rows.Scan(&entry.ID, &timestamp, &entry.Caller, &entry.Directory, &sources, &entry.Storage, &entry.Delimiter,
+			&entry.Collection, &entry.Query, &entry.Rows, &entry.DurationMs, &entry.Error); err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+		if entry.Timestamp, err = time.Parse(time.RFC3339Nano, timestamp); err != nil {
+			return nil, fmt.Errorf("invalid history timestamp %q: %w", timestamp, err)
+		}
+		if err := json.Unmarshal([]byte(sources), &entry.Sources); err != nil {
+			return nil, fmt.Errorf("invalid history sources %q: %w", sources, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// limitOrAll maps a non-positive limit to SQLite's "no limit"
+func limitOrAll(limit int) int {
+	if limit <= 0 {
+		return -1
+	}
+	return limit
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+}
@@ -0,0 +1,152 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "nested", "history.db")
+	store, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store, path
+}
+
+func TestStore_AddAndGet(t *testing.T) {
+	store, path := openTestStore(t)
+
+	started := time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)
+	id, err := store.Add(Entry{
+		Timestamp:  started,
+		Caller:     CallerCLI,
+		Directory:  "/data",
+		Sources:    []string{"users.csv", "orders.json"},
+		Storage:    "data.duckdb",
+		Delimiter:  ";",
+		Query:      "SELECT * FROM users",
+		Rows:       42,
+		DurationMs: 12.5,
+	})
+	require.NoError(t, err)
+
+	entry, err := store.Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, id, entry.ID)
+	assert.True(t, started.Equal(entry.Timestamp))
+	assert.Equal(t, CallerCLI, entry.Caller)
+	assert.Equal(t, "/data", entry.Directory)
+	assert.Equal(t, []string{"users.csv", "orders.json"}, entry.Sources)
+	assert.Equal(t, "data.duckdb", entry.Storage)
+	assert.Equal(t, ";", entry.Delimiter)
+	assert.Equal(t, "SELECT * FROM users", entry.Query)
+	assert.Equal(t, int64(42), entry.Rows)
+	assert.Equal(t, 12.5, entry.DurationMs)
+	assert.Empty(t, entry.Error)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestStore_GetUnknown(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	_, err := store.Get(7)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestStore_ListNewestFirst(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	for _, query := range []string{"SELECT 1", "SELECT 2", "SELECT 3"} {
+		_, err := store.Add(Entry{Caller: CallerREPL, Query: query})
+		require.NoError(t, err)
+	}
+
+	entries, err := store.List(2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "SELECT 3", entries[0].Query)
+	assert.Equal(t, "SELECT 2", entries[1].Query)
+	assert.Equal(t, []string{}, entries[0].Sources)
+
+	all, err := store.List(0)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
+
+func TestStore_Search(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	_, err := store.Add(Entry{Caller: CallerCLI, Sources: []string{"sales.csv"}, Query: "SELECT region FROM sales"})
+	require.NoError(t, err)
+	_, err = store.Add(Entry{Caller: CallerCLI, Sources: []string{"users.csv"}, Query: "SELECT name FROM users"})
+	require.NoError(t, err)
+	_, err = store.Add(Entry{Caller: CallerCLI, Sources: []string{"users.csv"}, Query: "SELECT 100% FROM users"})
+	require.NoError(t, err)
+
+	entries, err := store.Search("REGION", 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "SELECT region FROM sales", entries[0].Query)
+
+	entries, err = store.Search("users.csv", 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// LIKE wildcards in the text are matched literally
+	entries, err = store.Search("100%", 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "SELECT 100% FROM users", entries[0].Query)
+}
+
+func TestStore_Clear(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	_, err := store.Add(Entry{Caller: CallerCLI, Query: "SELECT 1"})
+	require.NoError(t, err)
+
+	removed, err := store.Clear()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), removed)
+
+	entries, err := store.List(0)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestStore_ReopenKeepsEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	store, err := Open(path)
+	require.NoError(t, err)
+	_, err = store.Add(Entry{Caller: CallerCLI, Query: "SELECT 1"})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	store, err = Open(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	entries, err := store.List(0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "SELECT 1", entries[0].Query)
+}
+
+func TestDefaultPath_Env(t *testing.T) {
+	t.Setenv(EnvPath, "/tmp/custom-history.db")
+
+	path, err := DefaultPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/custom-history.db", path)
+}
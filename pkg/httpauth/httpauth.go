@@ -0,0 +1,46 @@
+// Package httpauth authenticates the clients of the HTTP servers (mcp serve
+// --transport http and serve) with a shared token.
+package httpauth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// RequireToken rejects requests that do not carry token, either as
+// "Authorization: Bearer <token>" or in the X-API-Key header, with a 401
+// JSON error. Requests for the public paths, such as health checks, are
+// served without it.
+func RequireToken(token string, next http.Handler, public ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, path := range public {
+			if r.URL.Path == path {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		if subtle.ConstantTimeCompare([]byte(bearerToken(r)), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="dataql"`)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken returns the token r carries as a bearer token or an API key
+func bearerToken(r *http.Request) string {
+	provided := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if scheme, value, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			provided = strings.TrimSpace(value)
+		}
+	}
+	return provided
+}
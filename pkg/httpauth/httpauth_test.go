@@ -0,0 +1,46 @@
+package httpauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireToken(t *testing.T) {
+	handler := RequireToken("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), "/health")
+
+	tests := []struct {
+		name     string
+		path     string
+		header   string
+		value    string
+		expected int
+	}{
+		{"bearer token", "/mcp", "Authorization", "Bearer secret", http.StatusOK},
+		{"bearer scheme is case-insensitive", "/mcp", "Authorization", "bearer secret", http.StatusOK},
+		{"api key", "/mcp", "X-API-Key", "secret", http.StatusOK},
+		{"wrong token", "/mcp", "Authorization", "Bearer wrong", http.StatusUnauthorized},
+		{"basic auth", "/mcp", "Authorization", "Basic secret", http.StatusUnauthorized},
+		{"no token", "/mcp", "", "", http.StatusUnauthorized},
+		{"public path", "/health", "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.expected, rec.Code)
+			if tt.expected == http.StatusUnauthorized {
+				assert.JSONEq(t, `{"error": "unauthorized"}`, rec.Body.String())
+			}
+		})
+	}
+}
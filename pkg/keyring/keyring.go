@@ -0,0 +1,53 @@
+// Package keyring stores secrets in the OS keychain (macOS Keychain, the
+// Secret Service on Linux, Windows Credential Manager) under the dataql service.
+package keyring
+
+import (
+	"errors"
+	"fmt"
+
+	gokeyring "github.com/zalando/go-keyring"
+)
+
+// Service names the dataql entries of the OS keyring
+const Service = "dataql"
+
+// ErrNotFound is returned when the keyring has no secret for an account
+var ErrNotFound = errors.New("secret not found in the OS keyring")
+
+// Get returns the secret of an account
+func Get(account string) (string, error) {
+	secret, err := gokeyring.Get(Service, account)
+	if errors.Is(err, gokeyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the OS keyring: %w", err)
+	}
+	return secret, nil
+}
+
+// Set stores the secret of an account, replacing a previous one
+func Set(account, secret string) error {
+	if err := gokeyring.Set(Service, account, secret); err != nil {
+		return fmt.Errorf("failed to write the OS keyring: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the secret of an account
+func Delete(account string) error {
+	err := gokeyring.Delete(Service, account)
+	if errors.Is(err, gokeyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the OS keyring: %w", err)
+	}
+	return nil
+}
+
+// MockInit replaces the OS keyring with an in-memory one, for tests
+func MockInit() {
+	gokeyring.MockInit()
+}
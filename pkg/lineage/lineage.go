@@ -0,0 +1,113 @@
+package lineage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// MetadataKey names the lineage in the key-value metadata of Parquet files
+	MetadataKey = "dataql.lineage"
+	// SidecarSuffix is appended to the path of exports that cannot embed the lineage
+	SidecarSuffix = ".lineage.json"
+)
+
+// Lineage records how an exported dataset was produced
+type Lineage struct {
+	Sources   []Source  `json:"sources"`
+	Query     string    `json:"query"`
+	Version   string    `json:"dataql_version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Source is a file the dataset was imported from
+type Source struct {
+	Path   string `json:"path"` // As given by the user, with URL passwords redacted
+	Size   int64  `json:"size,omitempty"`
+	SHA256 string `json:"sha256,omitempty"` // Left out for databases and message queues
+}
+
+// New describes the export of query over files. names holds the source of
+// each file as given by the user, e.g. the URL a file was downloaded from;
+// files are named by their path when names does not match them.
+func New(names, files []string, query, version string) (*Lineage, error) {
+	l := &Lineage{Query: query, Version: version, CreatedAt: time.Now().UTC()}
+	for i, file := range files {
+		name := file
+		if len(names) == len(files) {
+			name = names[i]
+		}
+		if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() {
+			l.Sources = append(l.Sources, Source{Path: name})
+			continue
+		}
+		size, hash, err := hashFile(file)
+		if err != nil {
+			return nil, err
+		}
+		l.Sources = append(l.Sources, Source{Path: name, Size: size, SHA256: hash})
+	}
+	return l, nil
+}
+
+// JSON returns the lineage as a single line of JSON, for file metadata
+func (l *Lineage) JSON() (string, error) {
+	data, err := l.encode("")
+	return strings.TrimSuffix(data, "\n"), err
+}
+
+// WriteSidecar writes the lineage next to an export, as exportPath + SidecarSuffix
+func (l *Lineage) WriteSidecar(exportPath string) error {
+	data, err := l.encode("  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(exportPath+SidecarSuffix, []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to write lineage: %w", err)
+	}
+	return nil
+}
+
+// encode returns the lineage as JSON, keeping the comparison operators of
+// the query unescaped
+func (l *Lineage) encode(indent string) (string, error) {
+	var buf strings.Builder
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+	if err := encoder.Encode(l); err != nil {
+		return "", fmt.Errorf("failed to encode lineage: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Parse decodes a lineage written by JSON
+func Parse(data string) (*Lineage, error) {
+	var l Lineage
+	if err := json.Unmarshal([]byte(data), &l); err != nil {
+		return nil, fmt.Errorf("failed to decode lineage: %w", err)
+	}
+	return &l, nil
+}
+
+// hashFile returns the size and SHA-256 of a file
+func hashFile(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
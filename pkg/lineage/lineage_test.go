@@ -0,0 +1,86 @@
+package lineage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNew_HashesSources(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "users.csv")
+	if err := os.WriteFile(file, []byte("id\n1\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	l, err := New([]string{"https://example.com/users.csv"}, []string{file}, "SELECT * FROM users WHERE id > 0", "1.2.3")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if len(l.Sources) != 1 {
+		t.Fatalf("expected 1 source, got %d", len(l.Sources))
+	}
+	source := l.Sources[0]
+	if source.Path != "https://example.com/users.csv" || source.Size != 5 {
+		t.Errorf("unexpected source: %+v", source)
+	}
+	// sha256 of "id\n1\n"
+	if source.SHA256 != "7cde7fb64fd82bd152710cf238e017b9ab46c0592483edc067ba4f6c75fac108" {
+		t.Errorf("unexpected hash: %s", source.SHA256)
+	}
+	if l.Version != "1.2.3" || l.CreatedAt.IsZero() {
+		t.Errorf("unexpected lineage: %+v", l)
+	}
+}
+
+func TestNew_NonFileSources(t *testing.T) {
+	l, err := New(nil, []string{"postgres://localhost/db/users"}, "SELECT 1", "dev")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if l.Sources[0].Path != "postgres://localhost/db/users" || l.Sources[0].SHA256 != "" {
+		t.Errorf("unexpected source: %+v", l.Sources[0])
+	}
+}
+
+func TestJSON_RoundTrip(t *testing.T) {
+	l := &Lineage{Sources: []Source{{Path: "a.csv", Size: 1, SHA256: "abc"}}, Query: "SELECT * FROM a WHERE x < 1", Version: "dev"}
+
+	data, err := l.JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	if strings.Contains(data, "\n") || !strings.Contains(data, "x < 1") {
+		t.Errorf("expected a single line with the query unescaped, got %s", data)
+	}
+
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parsed.Query != l.Query || len(parsed.Sources) != 1 || parsed.Sources[0].SHA256 != "abc" {
+		t.Errorf("unexpected lineage after round trip: %+v", parsed)
+	}
+}
+
+func TestWriteSidecar(t *testing.T) {
+	exportPath := filepath.Join(t.TempDir(), "out.csv")
+	l := &Lineage{Query: "SELECT 1", Version: "dev"}
+
+	if err := l.WriteSidecar(exportPath); err != nil {
+		t.Fatalf("WriteSidecar failed: %v", err)
+	}
+
+	data, err := os.ReadFile(exportPath + SidecarSuffix)
+	if err != nil {
+		t.Fatalf("failed to read sidecar: %v", err)
+	}
+	parsed, err := Parse(string(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parsed.Query != "SELECT 1" {
+		t.Errorf("unexpected query: %s", parsed.Query)
+	}
+}
@@ -0,0 +1,189 @@
+// Package mask anonymizes personal data in query results, replacing columns
+// with hashes, redactions, fake e-mail addresses or shifted dates so that
+// production extracts can be shared with developers or LLMs.
+package mask
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// EnvSalt sets the salt of hashes when no salt is given
+const EnvSalt = "DATAQL_MASK_SALT"
+
+// saltBytes is the size of generated salts
+const saltBytes = 16
+
+// Masking methods
+const (
+	MethodHash       = "hash"        // SHA-256 of the salted value: joins across files still match
+	MethodRedact     = "redact"      // Fixed text, NULL stays NULL
+	MethodFakeEmail  = "fake-email"  // user_<hash>@example.com, stable for the same address
+	MethodShiftDates = "shift-dates" // Dates and timestamps moved by the same number of days
+	MethodNull       = "null"        // NULL
+)
+
+// RedactedText replaces the values of redacted columns
+const RedactedText = "REDACTED"
+
+// maxShiftDays bounds the shift of dates when it is derived from the salt
+const maxShiftDays = 365
+
+var methods = map[string]func(column string, opts Options) string{
+	MethodHash:       hashExpr,
+	MethodRedact:     redactExpr,
+	MethodFakeEmail:  fakeEmailExpr,
+	MethodShiftDates: shiftDatesExpr,
+	MethodNull:       func(string, Options) string { return "NULL" },
+}
+
+// Rule masks one column
+type Rule struct {
+	Column string
+	Method string
+}
+
+// Options configures the masking expressions
+type Options struct {
+	Salt      string // Prepended to values before hashing
+	ShiftDays int    // Days added to dates by shift-dates (may be negative)
+}
+
+// NewOptions returns the options for a salt. The date shift is derived from
+// the salt, so masking with the same salt is reproducible; without a salt
+// it is random.
+func NewOptions(salt string) Options {
+	opts := Options{Salt: salt}
+	if salt != "" {
+		sum := sha256.Sum256([]byte(salt))
+		opts.ShiftDays = int(binary.BigEndian.Uint64(sum[:8])%(2*maxShiftDays)) - maxShiftDays
+	} else if n, err := rand.Int(rand.Reader, big.NewInt(2*maxShiftDays)); err == nil {
+		opts.ShiftDays = int(n.Int64()) - maxShiftDays
+	}
+	if opts.ShiftDays == 0 {
+		opts.ShiftDays = maxShiftDays
+	}
+	return opts
+}
+
+// ResolveSalt returns salt, or $DATAQL_MASK_SALT when salt is empty. Without
+// either, a random salt is generated and generated is true: unsalted hashes
+// of names, e-mails or IDs are reversed by hashing guesses, so the caller
+// prints the salt once for the masking to be reproduced.
+func ResolveSalt(salt string) (resolved string, generated bool, err error) {
+	if salt == "" {
+		salt = os.Getenv(EnvSalt)
+	}
+	if salt != "" {
+		return salt, false, nil
+	}
+
+	buf := make([]byte, saltBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, fmt.Errorf("failed to generate a mask salt: %w", err)
+	}
+	return hex.EncodeToString(buf), true, nil
+}
+
+// Methods returns the supported masking methods
+func Methods() []string {
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseRules parses rules written as column=method
+func ParseRules(specs []string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(specs))
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		column, method, ok := strings.Cut(spec, "=")
+		column, method = strings.TrimSpace(column), strings.ToLower(strings.TrimSpace(method))
+		if !ok || column == "" || method == "" {
+			return nil, fmt.Errorf("invalid mask %q (expected column=method)", spec)
+		}
+		if _, ok := methods[method]; !ok {
+			return nil, fmt.Errorf("unknown mask method %q for %s (supported: %s)", method, column, strings.Join(Methods(), ", "))
+		}
+		if seen[strings.ToLower(column)] {
+			return nil, fmt.Errorf("column %s is masked twice", column)
+		}
+		seen[strings.ToLower(column)] = true
+		rules = append(rules, Rule{Column: column, Method: method})
+	}
+	return rules, nil
+}
+
+// Expression returns the SQL expression masking the column of a rule
+func Expression(rule Rule, opts Options) (string, error) {
+	build, ok := methods[rule.Method]
+	if !ok {
+		return "", fmt.Errorf("unknown mask method %q (supported: %s)", rule.Method, strings.Join(Methods(), ", "))
+	}
+	if opts.Salt == "" && (rule.Method == MethodHash || rule.Method == MethodFakeEmail) {
+		return "", fmt.Errorf("mask method %s of %s requires a salt", rule.Method, rule.Column)
+	}
+	return build(storage.QuoteIdentifier(rule.Column), opts), nil
+}
+
+// Wrap returns query with the columns of the rules masked. Other columns and
+// the column order are unchanged; a rule on a column missing from the
+// result makes the query fail.
+func Wrap(query string, rules []Rule, opts Options) (string, error) {
+	if len(rules) == 0 {
+		return query, nil
+	}
+
+	replaces := make([]string, len(rules))
+	for i, rule := range rules {
+		expr, err := Expression(rule, opts)
+		if err != nil {
+			return "", err
+		}
+		replaces[i] = expr + " AS " + storage.QuoteIdentifier(rule.Column)
+	}
+
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	return fmt.Sprintf("SELECT * REPLACE (%s) FROM (%s) AS masked", strings.Join(replaces, ", "), query), nil
+}
+
+func hashExpr(column string, opts Options) string {
+	return fmt.Sprintf("sha256(%s || CAST(%s AS VARCHAR))", quoteLiteral(opts.Salt), column)
+}
+
+func redactExpr(column string, _ Options) string {
+	return fmt.Sprintf("CASE WHEN %s IS NULL THEN NULL ELSE %s END", column, quoteLiteral(RedactedText))
+}
+
+func fakeEmailExpr(column string, opts Options) string {
+	return fmt.Sprintf("CASE WHEN %s IS NULL THEN NULL ELSE 'user_' || left(%s, 12) || '@example.com' END", column, hashExpr(column, opts))
+}
+
+// shiftDatesExpr moves DATE and TIMESTAMP values, and text holding them, by
+// opts.ShiftDays days. Text is written back in the same layout (date only or
+// date and time); values that are not dates become NULL.
+func shiftDatesExpr(column string, opts Options) string {
+	text := fmt.Sprintf("CAST(%s AS VARCHAR)", column)
+	return fmt.Sprintf(`CASE
+  WHEN %[1]s IS NULL THEN NULL
+  WHEN typeof(%[1]s) = 'DATE' THEN CAST(CAST(CAST(%[1]s AS DATE) + INTERVAL (%[3]d) DAY AS DATE) AS VARCHAR)
+  WHEN TRY_CAST(%[2]s AS DATE) IS NOT NULL AND length(trim(%[2]s)) <= 10 THEN CAST(CAST(TRY_CAST(%[2]s AS DATE) + INTERVAL (%[3]d) DAY AS DATE) AS VARCHAR)
+  ELSE CAST(TRY_CAST(%[2]s AS TIMESTAMP) + INTERVAL (%[3]d) DAY AS VARCHAR)
+END`, column, text, opts.ShiftDays)
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
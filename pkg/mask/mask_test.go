@@ -0,0 +1,122 @@
+package mask_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/mask"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := mask.ParseRules([]string{"email=fake-email", " id = HASH "})
+	require.NoError(t, err)
+	assert.Equal(t, []mask.Rule{{Column: "email", Method: mask.MethodFakeEmail}, {Column: "id", Method: mask.MethodHash}}, rules)
+
+	for _, spec := range []string{"email", "=hash", "email=", "email=scramble"} {
+		_, err := mask.ParseRules([]string{spec})
+		assert.Error(t, err, spec)
+	}
+	_, err = mask.ParseRules([]string{"email=hash", "EMAIL=redact"})
+	assert.Error(t, err, "a column masked twice")
+}
+
+func TestNewOptions(t *testing.T) {
+	opts := mask.NewOptions("secret")
+	assert.Equal(t, opts, mask.NewOptions("secret"), "the same salt gives the same shift")
+	assert.NotZero(t, opts.ShiftDays)
+	assert.LessOrEqual(t, opts.ShiftDays, 365)
+	assert.GreaterOrEqual(t, opts.ShiftDays, -365)
+	assert.NotZero(t, mask.NewOptions("").ShiftDays)
+}
+
+func TestResolveSalt(t *testing.T) {
+	t.Setenv(mask.EnvSalt, "")
+	salt, generated, err := mask.ResolveSalt("")
+	require.NoError(t, err)
+	assert.True(t, generated)
+	assert.Len(t, salt, 32)
+	other, _, err := mask.ResolveSalt("")
+	require.NoError(t, err)
+	assert.NotEqual(t, salt, other)
+
+	t.Setenv(mask.EnvSalt, "from-env")
+	salt, generated, err = mask.ResolveSalt("")
+	require.NoError(t, err)
+	assert.False(t, generated)
+	assert.Equal(t, "from-env", salt)
+
+	salt, _, err = mask.ResolveSalt("given")
+	require.NoError(t, err)
+	assert.Equal(t, "given", salt)
+}
+
+func TestWrap_RequiresSalt(t *testing.T) {
+	for _, method := range []string{mask.MethodHash, mask.MethodFakeEmail} {
+		_, err := mask.Wrap("SELECT 1 AS id", []mask.Rule{{Column: "id", Method: method}}, mask.Options{})
+		assert.ErrorContains(t, err, "requires a salt", method)
+	}
+	_, err := mask.Wrap("SELECT 1 AS id", []mask.Rule{{Column: "id", Method: mask.MethodRedact}}, mask.Options{})
+	assert.NoError(t, err)
+}
+
+func TestWrap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.csv")
+	require.NoError(t, os.WriteFile(path, []byte("id,email,born,seen,note\n1,ana@x.com,1990-05-01,2024-01-02 10:00:00,hi\n2,,,,\n"), 0644))
+
+	db, err := dataql.Open(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rules := []mask.Rule{
+		{Column: "id", Method: mask.MethodHash},
+		{Column: "email", Method: mask.MethodFakeEmail},
+		{Column: "born", Method: mask.MethodShiftDates},
+		{Column: "seen", Method: mask.MethodShiftDates},
+		{Column: "note", Method: mask.MethodRedact},
+	}
+	query, err := mask.Wrap("SELECT * FROM people;", rules, mask.Options{Salt: "s", ShiftDays: -2})
+	require.NoError(t, err)
+
+	rows, err := db.Query(context.Background(), query+" ORDER BY ALL NULLS LAST")
+	require.NoError(t, err)
+	defer rows.Close()
+	assert.Equal(t, []string{"id", "email", "born", "seen", "note"}, rows.Columns())
+
+	var got [][]string
+	for rows.Next() {
+		values, err := rows.Values()
+		require.NoError(t, err)
+		row := make([]string, len(values))
+		for i, value := range values {
+			if value != nil {
+				row[i] = fmt.Sprint(value)
+			}
+		}
+		got = append(got, row)
+	}
+	require.NoError(t, rows.Err())
+	require.Len(t, got, 2)
+
+	masked := got[0]
+	if got[1][1] != "" {
+		masked = got[1]
+	}
+	assert.Len(t, masked[0], 64, "hex SHA-256")
+	assert.True(t, strings.HasPrefix(masked[1], "user_") && strings.HasSuffix(masked[1], "@example.com"), masked[1])
+	assert.Equal(t, "1990-04-29", masked[2])
+	assert.Equal(t, "2023-12-31 10:00:00", masked[3])
+	assert.Equal(t, mask.RedactedText, masked[4])
+}
+
+func TestWrap_NoRules(t *testing.T) {
+	query, err := mask.Wrap("SELECT 1", nil, mask.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT 1", query)
+}
@@ -0,0 +1,240 @@
+// Package metrics counts the queries, imports, exports and scheduled runs of
+// the process and serves them in the Prometheus text exposition format, for
+// the long-running modes: serve, mcp serve over HTTP and schedule run.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Path is where the servers expose the metrics
+const Path = "/metrics"
+
+// Statuses and cache results used as label values
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+
+	CacheHit    = "hit"    // Tables reused from the cache
+	CacheAppend = "append" // Cached tables of grown files, with the new rows imported
+	CacheMiss   = "miss"   // Sources imported in full
+)
+
+// durationBuckets are the upper bounds, in seconds, of the duration histograms
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// Metric types of the exposition format
+const (
+	counterType   = "counter"
+	histogramType = "histogram"
+)
+
+// Registry holds metric families and writes them in the text format
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// family is a metric with its series, one per combination of label values
+type family struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+	series map[string]*series
+}
+
+// series is a counter value, or the buckets, sum and count of a histogram
+type series struct {
+	labelValues []string
+	value       float64
+	buckets     []uint64 // Cumulative counts per durationBuckets bound
+}
+
+// NewRegistry creates a registry with the dataql metrics
+func NewRegistry() *Registry {
+	r := &Registry{families: make(map[string]*family)}
+	r.register("dataql_queries_total", "Executed SQL statements.", counterType, "status")
+	r.register("dataql_query_duration_seconds", "Time to execute SQL statements.", histogramType)
+	r.register("dataql_imports_total", "Imports of data sources.", counterType, "status")
+	r.register("dataql_import_bytes_total", "Bytes of the files read by imports.", counterType)
+	r.register("dataql_import_duration_seconds", "Time to import data sources.", histogramType)
+	r.register("dataql_cache_requests_total", "Imports by whether the cache held the data: hit, append or miss.", counterType, "result")
+	r.register("dataql_exports_total", "Exports of query results to files.", counterType, "status")
+	r.register("dataql_export_duration_seconds", "Time to export query results.", histogramType)
+	r.register("dataql_schedule_runs_total", "Runs of scheduled jobs.", counterType, "schedule", "status")
+	r.register("dataql_schedule_run_duration_seconds", "Time to run scheduled jobs.", histogramType)
+	return r
+}
+
+// Default is the registry of the process
+var Default = NewRegistry()
+
+func (r *Registry) register(name, help, kind string, labels ...string) {
+	r.families[name] = &family{name: name, help: help, kind: kind, labels: labels, series: make(map[string]*series)}
+}
+
+// add increments a counter
+func (r *Registry) add(name string, value float64, labelValues ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.get(name, labelValues).value += value
+}
+
+// observe records a value in a histogram
+func (r *Registry) observe(name string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.get(name, nil)
+	s.value += value
+	for i, bound := range durationBuckets {
+		if value <= bound {
+			s.buckets[i]++
+		}
+	}
+	s.buckets[len(durationBuckets)]++
+}
+
+// get returns the series of a family for the label values, creating it
+func (r *Registry) get(name string, labelValues []string) *series {
+	f, ok := r.families[name]
+	if !ok || len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: unknown metric %s%v", name, labelValues))
+	}
+	key := strings.Join(labelValues, "\x00")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: labelValues}
+		if f.kind == histogramType {
+			// The last bucket is +Inf, which also gives the count
+			s.buckets = make([]uint64, len(durationBuckets)+1)
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Write writes the metrics in the Prometheus text exposition format
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		f := r.families[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		all := make([]*series, 0, len(keys))
+		for _, key := range keys {
+			all = append(all, f.series[key])
+		}
+		if len(all) == 0 && len(f.labels) == 0 {
+			// Metrics without labels are always exposed, so rates start from zero
+			all = append(all, &series{buckets: make([]uint64, len(durationBuckets)+1)})
+		}
+
+		for _, s := range all {
+			labels := formatLabels(f.labels, s.labelValues)
+			if f.kind == counterType {
+				fmt.Fprintf(&b, "%s%s %s\n", f.name, labels, formatValue(s.value))
+				continue
+			}
+			for i, bound := range durationBuckets {
+				fmt.Fprintf(&b, "%s_bucket{le=\"%s\"} %d\n", f.name, formatValue(bound), s.buckets[i])
+			}
+			count := s.buckets[len(durationBuckets)]
+			fmt.Fprintf(&b, "%s_bucket{le=\"+Inf\"} %d\n%s_sum %s\n%s_count %d\n", f.name, count, f.name, formatValue(s.value), f.name, count)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Handler serves the metrics
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.Write(w)
+	})
+}
+
+// labelEscaper escapes label values as the exposition format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels formats label pairs as {name="value",...}
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + labelEscaper.Replace(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatValue formats a sample value, without exponent for whole numbers
+func formatValue(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// status returns the status label of an operation
+func status(err error) string {
+	if err != nil {
+		return StatusError
+	}
+	return StatusSuccess
+}
+
+// ObserveQuery records an executed SQL statement
+func ObserveQuery(duration time.Duration, err error) {
+	Default.add("dataql_queries_total", 1, status(err))
+	Default.observe("dataql_query_duration_seconds", duration.Seconds())
+}
+
+// ObserveImport records an import of data sources that read bytes of files
+func ObserveImport(bytes int64, duration time.Duration, err error) {
+	Default.add("dataql_imports_total", 1, status(err))
+	Default.add("dataql_import_bytes_total", float64(bytes))
+	Default.observe("dataql_import_duration_seconds", duration.Seconds())
+}
+
+// ObserveCache records whether the cache held the data of an import
+func ObserveCache(result string) {
+	Default.add("dataql_cache_requests_total", 1, result)
+}
+
+// ObserveExport records an export of a query result
+func ObserveExport(duration time.Duration, err error) {
+	Default.add("dataql_exports_total", 1, status(err))
+	Default.observe("dataql_export_duration_seconds", duration.Seconds())
+}
+
+// ObserveScheduleRun records a run of a scheduled job
+func ObserveScheduleRun(schedule string, duration time.Duration, err error) {
+	Default.add("dataql_schedule_runs_total", 1, schedule, status(err))
+	Default.observe("dataql_schedule_run_duration_seconds", duration.Seconds())
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func write(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	return b.String()
+}
+
+func TestWrite_Counters(t *testing.T) {
+	r := NewRegistry()
+	r.add("dataql_queries_total", 1, StatusSuccess)
+	r.add("dataql_queries_total", 1, StatusSuccess)
+	r.add("dataql_queries_total", 1, StatusError)
+	r.add("dataql_import_bytes_total", 2048)
+	r.add("dataql_schedule_runs_total", 1, `daily "sales"`, StatusSuccess)
+
+	out := write(t, r)
+	for _, want := range []string{
+		"# TYPE dataql_queries_total counter\n",
+		`dataql_queries_total{status="error"} 1` + "\n",
+		`dataql_queries_total{status="success"} 2` + "\n",
+		"dataql_import_bytes_total 2048\n",
+		`dataql_schedule_runs_total{schedule="daily \"sales\"",status="success"} 1` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "dataql_cache_requests_total{") {
+		t.Errorf("labeled metrics without series must have no samples:\n%s", out)
+	}
+}
+
+func TestWrite_Histograms(t *testing.T) {
+	r := NewRegistry()
+	if out := write(t, r); !strings.Contains(out, "dataql_query_duration_seconds_count 0\n") {
+		t.Errorf("histograms must be exposed before any observation:\n%s", out)
+	}
+
+	r.observe("dataql_query_duration_seconds", 0.02)
+	r.observe("dataql_query_duration_seconds", 3)
+
+	out := write(t, r)
+	for _, want := range []string{
+		`dataql_query_duration_seconds_bucket{le="0.01"} 0` + "\n",
+		`dataql_query_duration_seconds_bucket{le="0.025"} 1` + "\n",
+		`dataql_query_duration_seconds_bucket{le="5"} 2` + "\n",
+		`dataql_query_duration_seconds_bucket{le="+Inf"} 2` + "\n",
+		"dataql_query_duration_seconds_sum 3.02\n",
+		"dataql_query_duration_seconds_count 2\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestObserve(t *testing.T) {
+	ObserveImport(100, time.Millisecond, nil)
+	ObserveCache(CacheHit)
+	ObserveExport(time.Millisecond, errors.New("disk full"))
+
+	rec := httptest.NewRecorder()
+	Default.Handler().ServeHTTP(rec, httptest.NewRequest("GET", Path, nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type: %s", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`dataql_imports_total{status="success"}`,
+		`dataql_cache_requests_total{result="hit"}`,
+		`dataql_exports_total{status="error"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+)
+
+const (
+	// stateTable records the checksum of the SQL each model was built from
+	stateTable = "_dataql_models"
+	// storageAlias is the name the storage file is attached under when
+	// sources are imported in memory
+	storageAlias = "dataql_models"
+)
+
+// Build statuses of a model
+const (
+	StatusBuilt   = "built"
+	StatusSkipped = "skipped"
+)
+
+// BuildOptions configures a build
+type BuildOptions struct {
+	Storage     string   // DuckDB file the models are materialized into (required)
+	Sources     []string // Files imported before the build and readable by the models
+	Delimiter   string   // CSV delimiter of the sources
+	FullRefresh bool     // Rebuild every model, changed or not
+}
+
+// Result is the outcome of one model in a build
+type Result struct {
+	Model    string
+	Status   string
+	Reason   string // Why the model was built
+	Rows     int64
+	Duration time.Duration
+}
+
+// builder holds the state of one build
+type builder struct {
+	db      *dataql.DB
+	catalog string // Prefix of the tables in the storage file ("" or the attached alias)
+}
+
+// Build materializes the models in dependency order into the storage file.
+// A model is rebuilt when its SQL changed since the last build, its table
+// is missing, a model it references was rebuilt, or FullRefresh is
+// set; otherwise it is skipped. Changes to the data of the sources are not
+// detected: use FullRefresh after reloading them.
+//
+// Without sources, models read the tables already in the storage file. With
+// sources, the sources are imported in memory and the models are written to
+// the storage file attached to the same database.
+func (p *Project) Build(ctx context.Context, opts BuildOptions, log io.Writer) ([]Result, error) {
+	if opts.Storage == "" {
+		return nil, fmt.Errorf("a storage file is required to build models")
+	}
+
+	b := &builder{}
+	var err error
+	if len(opts.Sources) == 0 {
+		b.db, err = dataql.OpenWithOptions(dataql.Options{Storage: opts.Storage})
+	} else {
+		b.db, err = dataql.OpenWithOptions(dataql.Options{Delimiter: opts.Delimiter}, opts.Sources...)
+		if err == nil {
+			b.catalog = storageAlias
+			err = b.db.Exec(ctx, fmt.Sprintf("ATTACH '%s' AS %s", strings.ReplaceAll(opts.Storage, "'", "''"), storageAlias))
+		}
+	}
+	if err != nil {
+		if b.db != nil {
+			_ = b.db.Close()
+		}
+		return nil, fmt.Errorf("failed to open %s: %w", opts.Storage, err)
+	}
+	defer b.db.Close()
+
+	if err := b.db.Exec(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (name VARCHAR PRIMARY KEY, checksum VARCHAR, built_at TIMESTAMP)", b.table(stateTable))); err != nil {
+		return nil, fmt.Errorf("failed to create model state table: %w", err)
+	}
+	state, err := b.loadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	built := make(map[string]bool)
+	results := make([]Result, 0, len(p.Models))
+	for i, model := range p.Models {
+		sql := model.Compile(func(ref string) string {
+			target, _ := p.Model(ref)
+			return b.table(target.Name)
+		})
+		sum := checksum(model.SQL)
+
+		reason := b.rebuildReason(ctx, model, sum, state, built, opts.FullRefresh)
+		if reason == "" {
+			results = append(results, Result{Model: model.Name, Status: StatusSkipped})
+			fmt.Fprintf(log, "[%d/%d] %s: unchanged, skipped\n", i+1, len(p.Models), model.Name)
+			continue
+		}
+
+		started := time.Now()
+		rows, err := b.materialize(ctx, model.Name, sql, sum)
+		if err != nil {
+			return results, fmt.Errorf("model %s (%s) failed: %w", model.Name, model.Path, err)
+		}
+		built[strings.ToLower(model.Name)] = true
+
+		result := Result{Model: model.Name, Status: StatusBuilt, Reason: reason, Rows: rows, Duration: time.Since(started).Round(time.Millisecond)}
+		results = append(results, result)
+		fmt.Fprintf(log, "[%d/%d] %s: built %d rows in %s (%s)\n", i+1, len(p.Models), model.Name, rows, result.Duration, reason)
+	}
+
+	return results, nil
+}
+
+// rebuildReason explains why a model must be built, or returns "" when its
+// table is up to date
+func (b *builder) rebuildReason(ctx context.Context, model *Model, sum string, state map[string]string, built map[string]bool, fullRefresh bool) string {
+	if fullRefresh {
+		return "full refresh"
+	}
+
+	previous, ok := state[strings.ToLower(model.Name)]
+	switch {
+	case !ok:
+		return "new"
+	case previous != sum:
+		return "changed"
+	}
+
+	for _, ref := range model.Refs {
+		if built[strings.ToLower(ref)] {
+			return "upstream " + ref + " rebuilt"
+		}
+	}
+
+	rows, err := b.db.Query(ctx, "SELECT 1 FROM "+b.table(model.Name)+" LIMIT 0")
+	if err != nil {
+		return "table missing"
+	}
+	_ = rows.Close()
+	return ""
+}
+
+// materialize replaces the table of a model and records its checksum
+func (b *builder) materialize(ctx context.Context, name, sql, sum string) (int64, error) {
+	table := b.table(name)
+	if err := b.db.Exec(ctx, fmt.Sprintf("CREATE OR REPLACE TABLE %s AS %s", table, sql)); err != nil {
+		return 0, err
+	}
+
+	quotedName := "'" + strings.ReplaceAll(name, "'", "''") + "'"
+	if err := b.db.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE lower(name) = lower(%s)", b.table(stateTable), quotedName)); err != nil {
+		return 0, fmt.Errorf("failed to record model state: %w", err)
+	}
+	if err := b.db.Exec(ctx, fmt.Sprintf("INSERT INTO %s VALUES (%s, '%s', now())", b.table(stateTable), quotedName, sum)); err != nil {
+		return 0, fmt.Errorf("failed to record model state: %w", err)
+	}
+
+	rows, err := b.db.Query(ctx, "SELECT COUNT(*) FROM "+table)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var count int64
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return 0, err
+		}
+	}
+	return count, rows.Err()
+}
+
+// loadState returns the checksum of the last build of each model, keyed by
+// lower-case model name
+func (b *builder) loadState(ctx context.Context) (map[string]string, error) {
+	rows, err := b.db.Query(ctx, "SELECT name, checksum FROM "+b.table(stateTable))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model state: %w", err)
+	}
+	defer rows.Close()
+
+	state := make(map[string]string)
+	for rows.Next() {
+		var name, sum string
+		if err := rows.Scan(&name, &sum); err != nil {
+			return nil, fmt.Errorf("failed to read model state: %w", err)
+		}
+		state[strings.ToLower(name)] = sum
+	}
+	return state, rows.Err()
+}
+
+// table returns the quoted name of a table in the storage file
+func (b *builder) table(name string) string {
+	quoted := `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	if b.catalog == "" {
+		return quoted
+	}
+	return b.catalog + "." + quoted
+}
@@ -0,0 +1,186 @@
+// Package models builds a directory of SQL models into a DuckDB storage
+// file. Each .sql file holds a SELECT statement materialized as a table named
+// after the file; models reference each other with {{ ref('name') }}, which
+// orders the build and compiles to the table of the referenced model.
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// templatePattern matches any {{ ... }} expression
+	templatePattern = regexp.MustCompile(`\{\{(.*?)\}\}`)
+	// refPattern matches the body of a ref('model') expression
+	refPattern = regexp.MustCompile(`^\s*ref\(\s*['"]([^'"]+)['"]\s*\)\s*$`)
+)
+
+// Model is one .sql file of a project
+type Model struct {
+	Name string   // File name without the .sql extension
+	Path string   // Path of the file
+	SQL  string   // Statement as written, with ref() expressions
+	Refs []string // Models referenced with ref(), sorted and deduplicated
+}
+
+// Project is a set of models in dependency order
+type Project struct {
+	Models []*Model // Every model appears after the models it references
+	byName map[string]*Model
+}
+
+// Load reads the .sql files under dir (including subdirectories) and
+// orders them by their references. Unknown references, duplicate model
+// names and reference cycles are reported as errors.
+func Load(dir string) (*Project, error) {
+	byName := make(map[string]*Model)
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(path), ".sql") {
+			return nil
+		}
+
+		model, err := parseModel(path)
+		if err != nil {
+			return err
+		}
+		key := strings.ToLower(model.Name)
+		if existing, ok := byName[key]; ok {
+			return fmt.Errorf("model %s is defined in both %s and %s", model.Name, existing.Path, path)
+		}
+		byName[key] = model
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load models: %w", err)
+	}
+	if len(byName) == 0 {
+		return nil, fmt.Errorf("no .sql models found in %s", dir)
+	}
+
+	ordered, err := sortModels(byName)
+	if err != nil {
+		return nil, err
+	}
+	return &Project{Models: ordered, byName: byName}, nil
+}
+
+// Model returns the model with the given name (case insensitive)
+func (p *Project) Model(name string) (*Model, bool) {
+	model, ok := p.byName[strings.ToLower(name)]
+	return model, ok
+}
+
+// parseModel reads a model file and collects its references
+func parseModel(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Base(path)
+	model := &Model{
+		Name: base[:len(base)-len(filepath.Ext(base))],
+		Path: path,
+		SQL:  strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(string(data)), ";")),
+	}
+	if model.SQL == "" {
+		return nil, fmt.Errorf("model %s is empty", path)
+	}
+
+	refs := make(map[string]bool)
+	for _, match := range templatePattern.FindAllStringSubmatch(model.SQL, -1) {
+		ref := refPattern.FindStringSubmatch(match[1])
+		if ref == nil {
+			return nil, fmt.Errorf("model %s: unsupported expression %s (only {{ ref('model') }} is supported)", path, match[0])
+		}
+		refs[ref[1]] = true
+	}
+	for ref := range refs {
+		model.Refs = append(model.Refs, ref)
+	}
+	sort.Strings(model.Refs)
+
+	return model, nil
+}
+
+// sortModels orders the models so each one follows its references, breaking
+// ties by name so builds are deterministic
+func sortModels(byName map[string]*Model) ([]*Model, error) {
+	pending := make(map[string]int, len(byName)) // Unbuilt references per model
+	dependents := make(map[string][]string)
+	for key, model := range byName {
+		for _, ref := range model.Refs {
+			refKey := strings.ToLower(ref)
+			if _, ok := byName[refKey]; !ok {
+				return nil, fmt.Errorf("model %s references unknown model %q", model.Name, ref)
+			}
+			if refKey == key {
+				return nil, fmt.Errorf("model %s references itself", model.Name)
+			}
+			pending[key]++
+			dependents[refKey] = append(dependents[refKey], key)
+		}
+	}
+
+	var ready []string
+	for key := range byName {
+		if pending[key] == 0 {
+			ready = append(ready, key)
+		}
+	}
+
+	ordered := make([]*Model, 0, len(byName))
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		key := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, byName[key])
+
+		for _, dependent := range dependents[key] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(ordered) < len(byName) {
+		var cycle []string
+		for key, model := range byName {
+			if pending[key] > 0 {
+				cycle = append(cycle, model.Name)
+			}
+		}
+		sort.Strings(cycle)
+		return nil, fmt.Errorf("reference cycle between models: %s", strings.Join(cycle, ", "))
+	}
+	return ordered, nil
+}
+
+// Compile replaces the ref() expressions of the model with the table names
+// returned by table
+func (m *Model) Compile(table func(model string) string) string {
+	return templatePattern.ReplaceAllStringFunc(m.SQL, func(expr string) string {
+		ref := refPattern.FindStringSubmatch(templatePattern.FindStringSubmatch(expr)[1])
+		return table(ref[1])
+	})
+}
+
+// checksum identifies the statement of a model as written, so a model is
+// rebuilt when its SQL changes but not when it is compiled for another catalog
+func checksum(sql string) string {
+	sum := sha256.Sum256([]byte(sql))
+	return hex.EncodeToString(sum[:])
+}
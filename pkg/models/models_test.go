@@ -0,0 +1,152 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeModels writes model files into a new directory
+func writeModels(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, sql := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(sql), 0644))
+	}
+	return dir
+}
+
+func modelNames(p *Project) []string {
+	names := make([]string, len(p.Models))
+	for i, model := range p.Models {
+		names[i] = model.Name
+	}
+	return names
+}
+
+func TestLoad_Order(t *testing.T) {
+	dir := writeModels(t, map[string]string{
+		"revenue.sql":                 "SELECT * FROM {{ ref('paid') }} JOIN {{ref(\"customers_clean\")}} USING (id);",
+		"paid.sql":                    "SELECT * FROM orders",
+		"staging/customers_clean.sql": "SELECT * FROM customers",
+		"README.md":                   "not a model",
+	})
+
+	p, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"customers_clean", "paid", "revenue"}, modelNames(p))
+
+	revenue, ok := p.Model("REVENUE")
+	require.True(t, ok)
+	assert.Equal(t, []string{"customers_clean", "paid"}, revenue.Refs)
+	assert.Equal(t, `SELECT * FROM "paid" JOIN "customers_clean" USING (id)`, revenue.Compile(func(name string) string { return `"` + name + `"` }))
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{"unknown ref", map[string]string{"a.sql": "SELECT * FROM {{ ref('b') }}"}, `unknown model "b"`},
+		{"cycle", map[string]string{"a.sql": "SELECT * FROM {{ ref('b') }}", "b.sql": "SELECT * FROM {{ ref('a') }}", "c.sql": "SELECT 1"}, "reference cycle between models: a, b"},
+		{"self reference", map[string]string{"a.sql": "SELECT * FROM {{ ref('a') }}"}, "references itself"},
+		{"unsupported expression", map[string]string{"a.sql": "SELECT {{ var('x') }}"}, "unsupported expression"},
+		{"duplicate", map[string]string{"a.sql": "SELECT 1", "sub/a.sql": "SELECT 2"}, "defined in both"},
+		{"empty", map[string]string{"a.sql": " ; "}, "is empty"},
+		{"no models", map[string]string{"a.txt": "SELECT 1"}, "no .sql models"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeModels(t, tt.files))
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func statuses(results []Result) map[string]string {
+	byModel := make(map[string]string, len(results))
+	for _, result := range results {
+		byModel[result.Model] = result.Status
+	}
+	return byModel
+}
+
+func TestBuild_Incremental(t *testing.T) {
+	work := t.TempDir()
+	source := filepath.Join(work, "orders.csv")
+	require.NoError(t, os.WriteFile(source, []byte("id,status,amount\n1,paid,10\n2,open,5\n3,paid,7\n"), 0644))
+	storage := filepath.Join(work, "warehouse.duckdb")
+
+	dir := writeModels(t, map[string]string{
+		"paid.sql":    "SELECT * FROM orders WHERE status = 'paid'",
+		"total.sql":   "SELECT SUM(amount) AS total FROM {{ ref('paid') }}",
+		"counted.sql": "SELECT COUNT(*) AS n FROM orders",
+	})
+	opts := BuildOptions{Storage: storage, Sources: []string{source}}
+
+	p, err := Load(dir)
+	require.NoError(t, err)
+	results, err := p.Build(context.Background(), opts, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"counted": StatusBuilt, "paid": StatusBuilt, "total": StatusBuilt}, statuses(results))
+
+	// Nothing changed
+	results, err = p.Build(context.Background(), opts, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"counted": StatusSkipped, "paid": StatusSkipped, "total": StatusSkipped}, statuses(results))
+
+	// A changed model is rebuilt together with its downstream models
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "paid.sql"), []byte("SELECT * FROM orders WHERE status = 'paid' AND amount > 8"), 0644))
+	p, err = Load(dir)
+	require.NoError(t, err)
+	results, err = p.Build(context.Background(), opts, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"counted": StatusSkipped, "paid": StatusBuilt, "total": StatusBuilt}, statuses(results))
+
+	// Models are queryable from the storage file
+	db, err := dataql.OpenWithOptions(dataql.Options{Storage: storage})
+	require.NoError(t, err)
+	defer db.Close()
+	rows, err := db.Query(context.Background(), "SELECT total FROM total")
+	require.NoError(t, err)
+	defer rows.Close()
+	require.True(t, rows.Next())
+	values, err := rows.Values()
+	require.NoError(t, err)
+	assert.Equal(t, "10", fmt.Sprint(values[0]))
+}
+
+func TestBuild_FullRefreshAndFailure(t *testing.T) {
+	work := t.TempDir()
+	source := filepath.Join(work, "orders.csv")
+	require.NoError(t, os.WriteFile(source, []byte("id,amount\n1,10\n"), 0644))
+	opts := BuildOptions{Storage: filepath.Join(work, "warehouse.duckdb"), Sources: []string{source}}
+
+	p, err := Load(writeModels(t, map[string]string{"a.sql": "SELECT * FROM orders"}))
+	require.NoError(t, err)
+	_, err = p.Build(context.Background(), opts, io.Discard)
+	require.NoError(t, err)
+
+	opts.FullRefresh = true
+	results, err := p.Build(context.Background(), opts, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, StatusBuilt, results[0].Status)
+	assert.Equal(t, "full refresh", results[0].Reason)
+
+	p, err = Load(writeModels(t, map[string]string{"bad.sql": "SELECT missing FROM orders"}))
+	require.NoError(t, err)
+	_, err = p.Build(context.Background(), opts, io.Discard)
+	assert.ErrorContains(t, err, "model bad")
+}
@@ -0,0 +1,203 @@
+// Package pipeline runs multi-step ETL pipelines described in YAML: the
+// sources to import, a sequence of SQL steps that each create a table and
+// the exports of the results.
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// Pipeline is a reproducible sequence of transformations:
+//
+//	sources: [orders.csv, customers.json]
+//	steps:
+//	  - name: paid_orders
+//	    sql: SELECT * FROM orders WHERE status = 'paid'
+//	  - name: revenue
+//	    sql: |
+//	      SELECT c.country, SUM(o.amount) AS total
+//	      FROM paid_orders o JOIN customers c ON c.id = o.customer_id
+//	      GROUP BY c.country
+//	exports:
+//	  - table: revenue
+//	    path: out/revenue.parquet
+type Pipeline struct {
+	Sources   []string `yaml:"sources"`
+	Delimiter string   `yaml:"delimiter,omitempty"`
+	Storage   string   `yaml:"storage,omitempty"` // DuckDB file persisting the tables (default: in memory)
+	Steps     []Step   `yaml:"steps"`
+	Exports   []Export `yaml:"exports"`
+}
+
+// Step creates (or replaces) the table Name with the result of SQL. Later
+// steps and exports can read the tables of earlier steps.
+type Step struct {
+	Name string `yaml:"name"`
+	SQL  string `yaml:"sql"`
+}
+
+// Export writes a table, or the result of a query, to Path
+type Export struct {
+	Table string `yaml:"table,omitempty"`
+	Query string `yaml:"query,omitempty"`
+	Path  string `yaml:"path"`
+	Type  string `yaml:"type,omitempty"` // Export format (default: taken from the path extension)
+}
+
+// Load reads and validates the pipeline file at path. Relative local
+// sources, the storage file and export paths are resolved against the
+// directory of the file.
+func Load(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline file: %w", err)
+	}
+
+	var p Pipeline
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline file %s: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pipeline file %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for i, source := range p.Sources {
+		p.Sources[i] = resolvePath(dir, source)
+	}
+	p.Storage = resolvePath(dir, p.Storage)
+	for i := range p.Exports {
+		p.Exports[i].Path = resolvePath(dir, p.Exports[i].Path)
+	}
+
+	return &p, nil
+}
+
+// Validate checks the pipeline without running it
+func (p *Pipeline) Validate() error {
+	if len(p.Sources) == 0 && p.Storage == "" {
+		return fmt.Errorf("at least one source or a storage file is required")
+	}
+	if len(p.Steps) == 0 && len(p.Exports) == 0 {
+		return fmt.Errorf("at least one step or export is required")
+	}
+
+	names := make(map[string]bool, len(p.Steps))
+	for i, step := range p.Steps {
+		switch {
+		case step.Name == "":
+			return fmt.Errorf("step %d: name is required", i+1)
+		case strings.TrimSpace(step.SQL) == "":
+			return fmt.Errorf("step %s: sql is required", step.Name)
+		case names[strings.ToLower(step.Name)]:
+			return fmt.Errorf("step %s: duplicate step name", step.Name)
+		}
+		names[strings.ToLower(step.Name)] = true
+	}
+
+	for i, export := range p.Exports {
+		switch {
+		case export.Path == "":
+			return fmt.Errorf("export %d: path is required", i+1)
+		case (export.Table == "") == (strings.TrimSpace(export.Query) == ""):
+			return fmt.Errorf("export %s: exactly one of table or query is required", export.Path)
+		}
+	}
+	return nil
+}
+
+// Run imports the sources, runs the steps in order and writes the exports,
+// reporting progress to log. It stops at the first failing step or export.
+func (p *Pipeline) Run(ctx context.Context, log io.Writer) error {
+	started := time.Now()
+	db, err := dataql.OpenWithOptions(dataql.Options{Delimiter: p.Delimiter, Storage: p.Storage}, p.Sources...)
+	if err != nil {
+		return fmt.Errorf("failed to import sources: %w", err)
+	}
+	defer db.Close()
+	fmt.Fprintf(log, "Imported %d source(s) in %s\n", len(p.Sources), since(started))
+
+	for i, step := range p.Steps {
+		started = time.Now()
+		query := fmt.Sprintf("CREATE OR REPLACE TABLE %s AS %s", storage.QuoteIdentifier(step.Name), trimStatement(step.SQL))
+		if err := db.Exec(ctx, query); err != nil {
+			return fmt.Errorf("step %d (%s) failed: %w", i+1, step.Name, err)
+		}
+
+		count, err := countRows(ctx, db, step.Name)
+		if err != nil {
+			return fmt.Errorf("step %d (%s) failed: %w", i+1, step.Name, err)
+		}
+		fmt.Fprintf(log, "Step %d/%d %s: %d rows in %s\n", i+1, len(p.Steps), step.Name, count, since(started))
+	}
+
+	for _, export := range p.Exports {
+		started = time.Now()
+		query := trimStatement(export.Query)
+		if export.Table != "" {
+			query = "SELECT * FROM " + storage.QuoteIdentifier(export.Table)
+		}
+
+		if dir := filepath.Dir(export.Path); dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create export directory: %w", err)
+			}
+		}
+		rows, err := db.Export(ctx, query, export.Path, export.Type)
+		if err != nil {
+			return fmt.Errorf("export to %s failed: %w", export.Path, err)
+		}
+		fmt.Fprintf(log, "Exported %s (%d rows) in %s\n", export.Path, rows, since(started))
+	}
+
+	return nil
+}
+
+// countRows returns the number of rows of a table
+func countRows(ctx context.Context, db *dataql.DB, table string) (int64, error) {
+	rows, err := db.Query(ctx, "SELECT COUNT(*) FROM "+storage.QuoteIdentifier(table))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var count int64
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return 0, err
+		}
+	}
+	return count, rows.Err()
+}
+
+// trimStatement removes surrounding whitespace and a trailing semicolon so
+// the statement can be embedded in CREATE TABLE ... AS
+func trimStatement(sql string) string {
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(sql), ";"))
+}
+
+func since(t time.Time) time.Duration {
+	return time.Since(t).Round(time.Millisecond)
+}
+
+// resolvePath makes a relative local path relative to dir, leaving URLs,
+// stdin and absolute paths untouched
+func resolvePath(dir, path string) string {
+	if path == "-" || path == "" || filepath.IsAbs(path) || strings.Contains(path, "://") {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePipeline writes a pipeline file and the CSV sources it reads
+func writePipeline(t *testing.T, definition string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "orders.csv"), []byte("id,customer,status,amount\n1,ann,paid,10\n2,bob,paid,5\n3,ann,paid,7\n4,bob,open,100\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "customers.csv"), []byte("name,country\nann,BR\nbob,US\n"), 0644))
+
+	path := filepath.Join(dir, "pipeline.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(definition), 0644))
+	return path
+}
+
+func TestRun(t *testing.T) {
+	path := writePipeline(t, `
+sources: [orders.csv, customers.csv]
+steps:
+  - name: paid_orders
+    sql: SELECT * FROM orders WHERE status = 'paid';
+  - name: revenue
+    sql: |
+      SELECT c.country, SUM(o.amount) AS total
+      FROM paid_orders o JOIN customers c ON c.name = o.customer
+      GROUP BY c.country
+exports:
+  - table: revenue
+    path: out/revenue.csv
+  - query: SELECT COUNT(*) AS paid FROM paid_orders
+    path: out/count.json
+    type: jsonl
+`)
+
+	p, err := Load(path)
+	require.NoError(t, err)
+
+	var log strings.Builder
+	require.NoError(t, p.Run(context.Background(), &log))
+	assert.Contains(t, log.String(), "Step 1/2 paid_orders: 3 rows")
+	assert.Contains(t, log.String(), "Step 2/2 revenue: 2 rows")
+
+	dir := filepath.Dir(path)
+	revenue, err := os.ReadFile(filepath.Join(dir, "out", "revenue.csv"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"country,total", "BR,17", "US,5"}, strings.Split(strings.TrimSpace(string(revenue)), "\n"))
+
+	count, err := os.ReadFile(filepath.Join(dir, "out", "count.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"paid": 3}`, string(count))
+}
+
+func TestRun_StepFailure(t *testing.T) {
+	path := writePipeline(t, `
+sources: [orders.csv]
+steps:
+  - name: broken
+    sql: SELECT missing_column FROM orders
+exports:
+  - table: broken
+    path: out/broken.csv
+`)
+
+	p, err := Load(path)
+	require.NoError(t, err)
+
+	err = p.Run(context.Background(), io.Discard)
+	assert.ErrorContains(t, err, "step 1 (broken) failed")
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(path), "out", "broken.csv"))
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		expected   string
+	}{
+		{"no sources", "steps: [{name: a, sql: SELECT 1}]", "at least one source"},
+		{"nothing to do", "sources: [orders.csv]", "at least one step or export"},
+		{"step without name", "sources: [orders.csv]\nsteps: [{sql: SELECT 1}]", "step 1: name is required"},
+		{"duplicate step", "sources: [orders.csv]\nsteps: [{name: a, sql: SELECT 1}, {name: A, sql: SELECT 2}]", "duplicate step name"},
+		{"export without path", "sources: [orders.csv]\nexports: [{table: orders}]", "path is required"},
+		{"export with table and query", "sources: [orders.csv]\nexports: [{table: orders, query: SELECT 1, path: out.csv}]", "exactly one of table or query"},
+		{"unknown field", "sources: [orders.csv]\nstep: []", "field step not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writePipeline(t, tt.definition))
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
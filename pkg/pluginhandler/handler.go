@@ -0,0 +1,123 @@
+package pluginhandler
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// maxStderr bounds the plugin error output quoted in error messages
+const maxStderr = 2000
+
+// PluginHandler converts the files read by plugins to JSON Lines
+type PluginHandler struct {
+	plugins       []*Manifest
+	tempFiles     []string
+	originalPaths map[string]string // maps converted path -> original path
+}
+
+// NewPluginHandler creates a handler with the plugins installed in dir, or
+// in DefaultDir when dir is empty
+func NewPluginHandler(dir string) (*PluginHandler, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	plugins, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &PluginHandler{plugins: plugins, originalPaths: make(map[string]string)}, nil
+}
+
+// Plugins returns the installed plugins
+func (h *PluginHandler) Plugins() []*Manifest {
+	return h.plugins
+}
+
+// Find returns the plugin reading the file, or nil
+func (h *PluginHandler) Find(path string) *Manifest {
+	for _, m := range h.plugins {
+		if m.Matches(path) {
+			return m
+		}
+	}
+	return nil
+}
+
+// ResolveFiles runs the plugins of the files they read and returns the paths
+// of the JSON Lines they produced. Other files are returned as-is.
+func (h *PluginHandler) ResolveFiles(files []string) ([]string, error) {
+	result := make([]string, len(files))
+
+	for i, file := range files {
+		m := h.Find(file)
+		if m == nil {
+			result[i] = file
+			continue
+		}
+
+		converted, err := h.convert(m, file)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = converted
+	}
+
+	return result, nil
+}
+
+// GetOriginalPath returns the original path for a converted file path
+// If the path was not converted, returns the same path
+func (h *PluginHandler) GetOriginalPath(convertedPath string) string {
+	if original, ok := h.originalPaths[convertedPath]; ok {
+		return original
+	}
+	return convertedPath
+}
+
+// convert runs the plugin on a file and writes its output to a temp file
+func (h *PluginHandler) convert(m *Manifest, filePath string) (string, error) {
+	tempFile, err := os.CreateTemp("", "dataql_plugin_*.jsonl")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tempPath := tempFile.Name()
+	h.tempFiles = append(h.tempFiles, tempPath)
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(m.CommandPath(), append(append([]string{}, m.Args...), filePath)...)
+	cmd.Dir = m.Dir
+	cmd.Stdout = tempFile
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+	tempFile.Close()
+
+	if runErr != nil {
+		message := strings.TrimSpace(stderr.String())
+		if len(message) > maxStderr {
+			message = message[:maxStderr] + "..."
+		}
+		if message != "" {
+			return "", fmt.Errorf("plugin %s failed to read %s: %w: %s", m.Name, filePath, runErr, message)
+		}
+		return "", fmt.Errorf("plugin %s failed to read %s: %w", m.Name, filePath, runErr)
+	}
+
+	h.originalPaths[tempPath] = filePath
+	return tempPath, nil
+}
+
+// Cleanup removes all temporary converted files
+func (h *PluginHandler) Cleanup() error {
+	for _, path := range h.tempFiles {
+		os.Remove(path)
+	}
+	h.tempFiles = nil
+	return nil
+}
@@ -0,0 +1,201 @@
+package pluginhandler
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// downloadTimeout bounds the download of a plugin archive
+const downloadTimeout = 5 * time.Minute
+
+// Install copies a plugin into dir, replacing an installed plugin of the same
+// name. The source is a plugin directory, a .tar.gz archive of one, or the
+// http(s) URL of such an archive. The archive may hold the plugin at its root
+// or in a single top-level directory.
+func Install(source, dir string) (*Manifest, error) {
+	root := source
+	if isArchive(source) {
+		tmp, err := os.MkdirTemp("", "dataql_plugin_*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		defer os.RemoveAll(tmp)
+
+		if root, err = unpack(source, tmp); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := LoadManifest(root)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ContainsAny(m.Command, `/\`) {
+		if _, err := os.Stat(m.CommandPath()); err != nil {
+			return nil, fmt.Errorf("plugin %s: command %s not found", m.Name, m.Command)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(dir, "."+m.Name+".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to install plugin %s: %w", m.Name, err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := copyTree(root, staging); err != nil {
+		return nil, fmt.Errorf("failed to install plugin %s: %w", m.Name, err)
+	}
+
+	dest := filepath.Join(dir, m.Name)
+	if err := os.RemoveAll(dest); err != nil {
+		return nil, fmt.Errorf("failed to replace plugin %s: %w", m.Name, err)
+	}
+	if err := os.Rename(staging, dest); err != nil {
+		return nil, fmt.Errorf("failed to install plugin %s: %w", m.Name, err)
+	}
+	return LoadManifest(dest)
+}
+
+// isArchive reports whether the source is a .tar.gz archive or its URL
+func isArchive(source string) bool {
+	lower := strings.ToLower(source)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") ||
+		strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// unpack extracts the archive into dir and returns the plugin root
+func unpack(source, dir string) (string, error) {
+	var reader io.Reader
+	if strings.Contains(source, "://") {
+		client := &http.Client{Timeout: downloadTimeout}
+		resp, err := client.Get(source)
+		if err != nil {
+			return "", fmt.Errorf("failed to download plugin: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("failed to download plugin: %s", resp.Status)
+		}
+		reader = resp.Body
+	} else {
+		file, err := os.Open(source)
+		if err != nil {
+			return "", fmt.Errorf("failed to open plugin archive: %w", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	if err := extractTarGz(reader, dir); err != nil {
+		return "", fmt.Errorf("failed to extract plugin archive: %w", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ManifestFileName)); err == nil {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(dir, entries[0].Name()), nil
+	}
+	return "", fmt.Errorf("plugin archive has no %s", ManifestFileName)
+}
+
+// extractTarGz extracts the directories and regular files of a gzipped tar,
+// refusing entries that would land outside dir
+func extractTarGz(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if target != dir && !strings.HasPrefix(target, dir+string(filepath.Separator)) {
+			return fmt.Errorf("entry %s is outside the archive", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr, os.FileMode(header.Mode).Perm()); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("entry %s: only files and directories are supported", header.Name)
+		}
+	}
+}
+
+// copyTree copies the directories and regular files under src into dst,
+// keeping their permissions
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, 0755)
+		case info.Mode().IsRegular():
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			return writeFile(target, file, info.Mode().Perm())
+		default:
+			return fmt.Errorf("%s: only files and directories are supported", rel)
+		}
+	})
+}
+
+func writeFile(path string, r io.Reader, perm os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,182 @@
+// Package pluginhandler reads formats dataql does not support natively with
+// external programs. A plugin is a directory holding a plugin.yaml manifest
+// and the program it names:
+//
+//	name: hl7
+//	version: 1.0.0
+//	description: HL7 v2 messages, one row per segment
+//	extensions: [.hl7]
+//	command: bin/dataql-hl7
+//	args: [--flatten]
+//
+// dataql runs the command with the arguments followed by the path of the
+// source file. The command writes the rows to stdout as JSON Lines (one
+// object per row) and reports problems on stderr with a non-zero exit code.
+package pluginhandler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvDir overrides the directory the plugins are installed in
+	EnvDir = "DATAQL_PLUGIN_DIR"
+	// ManifestFileName is the manifest at the root of a plugin directory
+	ManifestFileName = "plugin.yaml"
+)
+
+// ErrNotFound is returned for a plugin that is not installed
+var ErrNotFound = errors.New("plugin not installed")
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Manifest describes a plugin
+type Manifest struct {
+	Name        string   `yaml:"name"`
+	Version     string   `yaml:"version,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Extensions  []string `yaml:"extensions"`
+	Command     string   `yaml:"command"`
+	Args        []string `yaml:"args,omitempty"`
+
+	// Dir is the directory of the manifest; a relative command is resolved against it
+	Dir string `yaml:"-"`
+}
+
+// DefaultDir returns $DATAQL_PLUGIN_DIR or ~/.dataql/plugins
+func DefaultDir() (string, error) {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dataql", "plugins"), nil
+}
+
+// LoadManifest reads and validates the manifest of the plugin in dir
+func LoadManifest(dir string) (*Manifest, error) {
+	path := filepath.Join(dir, ManifestFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin manifest: %w", err)
+	}
+
+	var m Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	for i, ext := range m.Extensions {
+		m.Extensions[i] = normalizeExtension(ext)
+	}
+	m.Dir = dir
+	return &m, nil
+}
+
+// Validate checks the manifest fields
+func (m *Manifest) Validate() error {
+	if !namePattern.MatchString(m.Name) {
+		return fmt.Errorf("invalid plugin name %q: use lowercase letters, digits, '_' and '-'", m.Name)
+	}
+	if len(m.Extensions) == 0 {
+		return fmt.Errorf("plugin %s declares no extensions", m.Name)
+	}
+	for _, ext := range m.Extensions {
+		if normalizeExtension(ext) == "." {
+			return fmt.Errorf("plugin %s declares an empty extension", m.Name)
+		}
+	}
+	if strings.TrimSpace(m.Command) == "" {
+		return fmt.Errorf("plugin %s has no command", m.Name)
+	}
+	return nil
+}
+
+// CommandPath returns the program to run: a path relative to the plugin
+// directory when it contains a separator, or a command looked up in PATH
+func (m *Manifest) CommandPath() string {
+	if filepath.IsAbs(m.Command) || !strings.ContainsAny(m.Command, `/\`) {
+		return m.Command
+	}
+	return filepath.Join(m.Dir, m.Command)
+}
+
+// Matches reports whether the plugin reads the file, comparing the end of
+// its name with the extensions so multi-part ones such as .sap.txt work
+func (m *Manifest) Matches(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for _, ext := range m.Extensions {
+		if strings.HasSuffix(name, ext) && len(name) > len(ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the plugins installed in dir sorted by name. A missing
+// directory yields none.
+func List(dir string) ([]*Manifest, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	var manifests []*Manifest
+	for _, entry := range entries {
+		// Hidden directories are installs in progress
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		m, err := LoadManifest(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, m)
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Name < manifests[j].Name })
+	return manifests, nil
+}
+
+// Remove uninstalls the named plugin from dir
+func Remove(dir, name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(filepath.Join(path, ManifestFileName)); err != nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to remove plugin %s: %w", name, err)
+	}
+	return nil
+}
+
+// normalizeExtension lowercases an extension and adds its leading dot
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
@@ -0,0 +1,183 @@
+package pluginhandler
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `name: pipes
+version: 1.0.0
+description: Pipe separated key=value records
+extensions: [.pipes, PIPES.TXT]
+command: bin/read-pipes
+`
+
+// testScript turns lines such as "id=1|name=ana" into JSON objects
+const testScript = `#!/bin/sh
+if [ ! -s "$1" ]; then
+  echo "empty input: $1" >&2
+  exit 3
+fi
+sed -e 's/^/{"/' -e 's/=/":"/g' -e 's/|/","/g' -e 's/$/"}/' "$1"
+`
+
+// writePlugin creates a plugin directory and returns its path
+func writePlugin(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugin is a shell script")
+	}
+
+	dir := filepath.Join(t.TempDir(), "pipes")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFileName), []byte(testManifest), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bin", "read-pipes"), []byte(testScript), 0755))
+	return dir
+}
+
+func TestLoadManifest(t *testing.T) {
+	m, err := LoadManifest(writePlugin(t))
+	require.NoError(t, err)
+	assert.Equal(t, "pipes", m.Name)
+	assert.Equal(t, []string{".pipes", ".pipes.txt"}, m.Extensions)
+	assert.Equal(t, filepath.Join(m.Dir, "bin", "read-pipes"), m.CommandPath())
+
+	assert.True(t, m.Matches("/data/orders.pipes"))
+	assert.True(t, m.Matches("ORDERS.PIPES.TXT"))
+	assert.False(t, m.Matches("orders.txt"))
+	assert.False(t, m.Matches(".pipes"))
+
+	onPath := Manifest{Command: "dataql-hl7", Dir: "/plugins/hl7"}
+	assert.Equal(t, "dataql-hl7", onPath.CommandPath())
+}
+
+func TestLoadManifest_Invalid(t *testing.T) {
+	for name, manifest := range map[string]string{
+		"name":       "name: Bad Name\nextensions: [.x]\ncommand: x\n",
+		"extensions": "name: x\ncommand: x\n",
+		"command":    "name: x\nextensions: [.x]\n",
+		"unknown":    "name: x\nextensions: [.x]\ncommand: x\nformat: arrow\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFileName), []byte(manifest), 0644))
+			_, err := LoadManifest(dir)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestInstallListRemove(t *testing.T) {
+	source := writePlugin(t)
+	dir := filepath.Join(t.TempDir(), "plugins")
+
+	plugins, err := List(dir)
+	require.NoError(t, err)
+	assert.Empty(t, plugins)
+
+	m, err := Install(source, dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "pipes"), m.Dir)
+
+	info, err := os.Stat(filepath.Join(dir, "pipes", "bin", "read-pipes"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode().Perm()&0100, "the command stays executable")
+
+	// Installing again replaces the plugin
+	_, err = Install(source, dir)
+	require.NoError(t, err)
+
+	plugins, err = List(dir)
+	require.NoError(t, err)
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "pipes", plugins[0].Name)
+
+	require.NoError(t, Remove(dir, "pipes"))
+	assert.True(t, errors.Is(Remove(dir, "pipes"), ErrNotFound))
+	assert.True(t, errors.Is(Remove(dir, "../etc"), ErrNotFound))
+}
+
+func TestInstall_Archive(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "pipes-1.0.0.tar.gz")
+	writeArchive(t, archive, map[string]string{
+		"pipes-1.0.0/" + ManifestFileName: testManifest,
+		"pipes-1.0.0/bin/read-pipes":      testScript,
+		"pipes-1.0.0/README.md":           "read pipes",
+	})
+
+	dir := t.TempDir()
+	m, err := Install(archive, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "pipes", m.Name)
+	assert.FileExists(t, filepath.Join(dir, "pipes", "README.md"))
+
+	evil := filepath.Join(t.TempDir(), "evil.tar.gz")
+	writeArchive(t, evil, map[string]string{"../outside": "x"})
+	_, err = Install(evil, dir)
+	assert.ErrorContains(t, err, "outside the archive")
+}
+
+func TestInstall_MissingCommand(t *testing.T) {
+	source := writePlugin(t)
+	require.NoError(t, os.Remove(filepath.Join(source, "bin", "read-pipes")))
+
+	_, err := Install(source, t.TempDir())
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestPluginHandler_ResolveFiles(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Install(writePlugin(t), dir)
+	require.NoError(t, err)
+
+	h, err := NewPluginHandler(dir)
+	require.NoError(t, err)
+	defer h.Cleanup()
+
+	input := filepath.Join(t.TempDir(), "orders.pipes")
+	require.NoError(t, os.WriteFile(input, []byte("id=1|name=ana\nid=2|name=bob\n"), 0644))
+
+	resolved, err := h.ResolveFiles([]string{input, "other.csv"})
+	require.NoError(t, err)
+	assert.Equal(t, "other.csv", resolved[1])
+	assert.Equal(t, ".jsonl", filepath.Ext(resolved[0]))
+	assert.Equal(t, input, h.GetOriginalPath(resolved[0]))
+
+	data, err := os.ReadFile(resolved[0])
+	require.NoError(t, err)
+	assert.Equal(t, "{\"id\":\"1\",\"name\":\"ana\"}\n{\"id\":\"2\",\"name\":\"bob\"}\n", string(data))
+
+	require.NoError(t, h.Cleanup())
+	assert.NoFileExists(t, resolved[0])
+
+	empty := filepath.Join(t.TempDir(), "empty.pipes")
+	require.NoError(t, os.WriteFile(empty, nil, 0644))
+	_, err = h.ResolveFiles([]string{empty})
+	assert.ErrorContains(t, err, "plugin pipes failed to read")
+	assert.ErrorContains(t, err, "empty input")
+}
+
+func writeArchive(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+}
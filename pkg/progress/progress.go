@@ -0,0 +1,110 @@
+// Package progress shows on stderr the phases each source goes through
+// before it can be queried: download, decompression and import, with the
+// bytes or rows done and the estimated time left.
+//
+// Progress is only drawn on terminals: when stderr is redirected, as in CI
+// logs, the escape codes that redraw the bar would clutter the output.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/chzyer/readline"
+	"github.com/schollz/progressbar/v3"
+)
+
+// Phases of a source
+const (
+	Download   = "download"
+	Decompress = "decompress"
+	Import     = "import"
+)
+
+// Output returns the writer progress is drawn on: stderr when it is a
+// terminal, io.Discard when quiet or redirected
+func Output(quiet bool) io.Writer {
+	if quiet || !readline.IsTerminal(int(os.Stderr.Fd())) {
+		return io.Discard
+	}
+	return os.Stderr
+}
+
+// Description returns the label of the bar of a phase of source, e.g.
+// "[download] sales.csv.gz"
+func Description(phase, source string) string {
+	return fmt.Sprintf("[cyan][%s][reset] %s", phase, filepath.Base(source))
+}
+
+// NewBar returns a bar of total bytes or rows (-1: unknown) drawn on w
+func NewBar(w io.Writer, total int64, description string, options ...progressbar.Option) *progressbar.ProgressBar {
+	options = append([]progressbar.Option{
+		progressbar.OptionSetWriter(w),
+		progressbar.OptionEnableColorCodes(true),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionFullWidth(),
+		progressbar.OptionSetDescription(description),
+		progressbar.OptionSetTheme(progressbar.Theme{
+			Saucer:        "[green]=[reset]",
+			SaucerHead:    "[green]>[reset]",
+			SaucerPadding: " ",
+			BarStart:      "[",
+			BarEnd:        "]",
+		}),
+	}, options...)
+	return progressbar.NewOptions64(total, options...)
+}
+
+// Reporter starts the bars of the download and decompression phases. A nil
+// Reporter, or one drawing on io.Discard, shows nothing.
+type Reporter struct {
+	w io.Writer
+}
+
+// New returns a reporter drawing on w, usually Output
+func New(w io.Writer) *Reporter {
+	return &Reporter{w: w}
+}
+
+// Start shows the bar of a phase of source, of total bytes (-1: unknown).
+// It returns nil when progress is not shown; Phase methods accept nil.
+func (r *Reporter) Start(phase, source string, total int64) *Phase {
+	if r == nil || r.w == io.Discard {
+		return nil
+	}
+	if total <= 0 {
+		total = -1
+	}
+	return &Phase{bar: NewBar(r.w, total, Description(phase, source), progressbar.OptionClearOnFinish())}
+}
+
+// Phase is the bar of one phase of one source
+type Phase struct {
+	bar *progressbar.ProgressBar
+}
+
+// Write counts the bytes of p as done, so a Phase can be the destination of
+// an io.MultiWriter or io.TeeReader next to the data
+func (p *Phase) Write(b []byte) (int, error) {
+	if p != nil {
+		_ = p.bar.Add(len(b))
+	}
+	return len(b), nil
+}
+
+// Reader returns r counting the bytes read from it as done
+func (p *Phase) Reader(r io.Reader) io.Reader {
+	if p == nil {
+		return r
+	}
+	return io.TeeReader(r, p)
+}
+
+// Done clears the bar, which the next phase takes the place of
+func (p *Phase) Done() {
+	if p != nil {
+		_ = p.bar.Finish()
+	}
+}
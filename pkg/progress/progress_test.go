@@ -0,0 +1,49 @@
+package progress_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/progress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	assert.Equal(t, "[cyan][download][reset] sales.csv.gz", progress.Description(progress.Download, "/tmp/dataql/sales.csv.gz"))
+}
+
+func TestOutputQuiet(t *testing.T) {
+	assert.Equal(t, io.Discard, progress.Output(true))
+}
+
+func TestPhase(t *testing.T) {
+	var out bytes.Buffer
+	phase := progress.New(&out).Start(progress.Download, "data.csv", 5)
+	require.NotNil(t, phase)
+
+	content, err := io.ReadAll(phase.Reader(strings.NewReader("hello")))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+	phase.Done()
+	// The finished bar is cleared for the next phase
+	assert.NotContains(t, out.String(), "data.csv")
+}
+
+func TestPhaseNotShown(t *testing.T) {
+	var reporter *progress.Reporter
+	assert.Nil(t, reporter.Start(progress.Download, "data.csv", 5))
+	assert.Nil(t, progress.New(io.Discard).Start(progress.Decompress, "data.csv", 5))
+
+	// A nil phase passes data through
+	var phase *progress.Phase
+	content, err := io.ReadAll(phase.Reader(strings.NewReader("hello")))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+	n, err := phase.Write([]byte("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	phase.Done()
+}
@@ -0,0 +1,172 @@
+// Package rangedownload downloads large remote objects in parts, with one
+// ranged request per part, so several connections share the transfer.
+package rangedownload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultConcurrency is the number of parts downloaded at the same time
+	DefaultConcurrency = 8
+	// DefaultPartSize is the size of the parts of a download
+	DefaultPartSize = 16 * 1024 * 1024
+
+	// maxAttempts is how many times a part is requested before the download fails
+	maxAttempts = 4
+	// retryDelay is the wait before the second attempt, doubled after each failure
+	retryDelay = 250 * time.Millisecond
+)
+
+// Options configures the downloads of the cloud handlers
+type Options struct {
+	Concurrency int       // Parts downloaded at the same time (0: DefaultConcurrency, 1: no parts)
+	PartSize    int64     // Bytes per ranged request (0: DefaultPartSize)
+	Progress    io.Writer // Told the bytes received by all parts concurrently, e.g. a progress bar (nil: none)
+}
+
+// OpenRange returns the length bytes of an object starting at offset
+type OpenRange func(ctx context.Context, offset, length int64) (io.ReadCloser, error)
+
+// Check validates the options of --download-parts and --download-part-size
+func (o Options) Check() error {
+	if o.Concurrency < 0 {
+		return fmt.Errorf("invalid --download-parts %d (expected a number of parts, 0 for %d)", o.Concurrency, DefaultConcurrency)
+	}
+	if o.PartSize < 0 {
+		return fmt.Errorf("invalid --download-part-size %d (expected a size such as 16MB)", o.PartSize)
+	}
+	return nil
+}
+
+// Parallel reports whether an object of size bytes is downloaded in parts
+func (o Options) Parallel(size int64) bool {
+	return o.concurrency() > 1 && size > o.partSize()
+}
+
+func (o Options) concurrency() int {
+	if o.Concurrency > 0 {
+		return o.Concurrency
+	}
+	return DefaultConcurrency
+}
+
+func (o Options) partSize() int64 {
+	if o.PartSize > 0 {
+		return o.PartSize
+	}
+	return DefaultPartSize
+}
+
+// Download writes the size bytes of an object to w, requesting its parts
+// concurrently. A part that fails is requested again from the first byte it
+// did not receive, so transient failures do not restart the download.
+func Download(ctx context.Context, w io.WriterAt, size int64, open OpenRange, opts Options) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	parts := make(chan int64)
+	for i := 0; i < opts.concurrency(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range parts {
+				length := min(opts.partSize(), size-offset)
+				if err := downloadPart(ctx, w, offset, length, open, opts.Progress); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+send:
+	for offset := int64(0); offset < size; offset += opts.partSize() {
+		select {
+		case parts <- offset:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(parts)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// DownloadFile downloads an object of size bytes to a new file at path
+func DownloadFile(ctx context.Context, path string, size int64, open OpenRange, opts Options) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+	err = Download(ctx, file, size, open, opts)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// HTTPRange returns the Range header value of length bytes from offset
+func HTTPRange(offset, length int64) string {
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+}
+
+// downloadPart writes the length bytes from offset to w, retrying from the
+// bytes already written
+func downloadPart(ctx context.Context, w io.WriterAt, offset, length int64, open OpenRange, progress io.Writer) error {
+	var written int64
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		n, err := copyRange(ctx, w, offset+written, length-written, open, progress)
+		written += n
+		if err == nil {
+			return nil
+		}
+		if attempt == maxAttempts || ctx.Err() != nil {
+			return fmt.Errorf("failed to download bytes %d-%d: %w", offset, offset+length-1, err)
+		}
+
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// copyRange writes the length bytes from offset to w and returns how many
+// were written, also when the body ends early
+func copyRange(ctx context.Context, w io.WriterAt, offset, length int64, open OpenRange, progress io.Writer) (int64, error) {
+	body, err := open(ctx, offset, length)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	var reader io.Reader = io.LimitReader(body, length)
+	if progress != nil {
+		reader = io.TeeReader(reader, progress)
+	}
+	n, err := io.Copy(io.NewOffsetWriter(w, offset), reader)
+	if err == nil && n < length {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
@@ -0,0 +1,102 @@
+package rangedownload_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/rangedownload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyBody returns the first bytes of a range and then fails
+type flakyBody struct {
+	io.Reader
+}
+
+func (b flakyBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if errors.Is(err, io.EOF) {
+		return n, errors.New("connection reset by peer")
+	}
+	return n, err
+}
+
+func (flakyBody) Close() error { return nil }
+
+// countingWriter counts the bytes reported as progress
+type countingWriter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n += len(p)
+	return len(p), nil
+}
+
+func TestDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+
+	var mu sync.Mutex
+	requests := map[int64]int{}
+	open := func(_ context.Context, offset, length int64) (io.ReadCloser, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests[offset]++
+
+		// The first request of each part is cut after 100 bytes
+		if offset%1024 == 0 && length > 100 {
+			return flakyBody{bytes.NewReader(content[offset : offset+100])}, nil
+		}
+		return io.NopCloser(bytes.NewReader(content[offset : offset+length])), nil
+	}
+
+	path := filepath.Join(t.TempDir(), "object")
+	progress := &countingWriter{}
+	opts := rangedownload.Options{Concurrency: 4, PartSize: 1024, Progress: progress}
+	require.True(t, opts.Parallel(int64(len(content))))
+	require.NoError(t, rangedownload.DownloadFile(context.Background(), path, int64(len(content)), open, opts))
+
+	downloaded, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+
+	// Failed parts resumed from the bytes they received
+	assert.Equal(t, 1, requests[100])
+	assert.Equal(t, 1, requests[1024+100])
+
+	// Resumed parts report each byte once
+	assert.Equal(t, len(content), progress.n)
+}
+
+func TestDownloadFails(t *testing.T) {
+	open := func(_ context.Context, offset, length int64) (io.ReadCloser, error) {
+		if offset == 2048 {
+			return nil, errors.New("403 Forbidden")
+		}
+		return io.NopCloser(bytes.NewReader(make([]byte, length))), nil
+	}
+
+	path := filepath.Join(t.TempDir(), "object")
+	err := rangedownload.DownloadFile(context.Background(), path, 4096, open, rangedownload.Options{Concurrency: 2, PartSize: 1024})
+	assert.ErrorContains(t, err, "failed to download bytes 2048-3071: 403 Forbidden")
+}
+
+func TestOptions(t *testing.T) {
+	assert.False(t, rangedownload.Options{}.Parallel(rangedownload.DefaultPartSize))
+	assert.True(t, rangedownload.Options{}.Parallel(rangedownload.DefaultPartSize+1))
+	assert.False(t, rangedownload.Options{Concurrency: 1}.Parallel(1<<40))
+
+	assert.NoError(t, rangedownload.Options{}.Check())
+	assert.ErrorContains(t, rangedownload.Options{Concurrency: -1}.Check(), "invalid --download-parts")
+	assert.Equal(t, "bytes=1024-2047", rangedownload.HTTPRange(1024, 1024))
+}
@@ -1,7 +1,6 @@
 package repl
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/adrianolaselva/dataql/pkg/storage"
@@ -24,92 +23,118 @@ var sqlKeywords = []string{
 
 // REPL commands for autocomplete
 var replCommands = []string{
-	"\\d", "\\dt", "\\c", "\\q", "\\h", "\\?",
-	".tables", ".schema", ".count", ".quit", ".exit", ".help", ".clear", ".version",
-	".paging", ".pagesize", ".timing",
+	"\\d", "\\dt", "\\dt+", "\\ds", "\\c", "\\watch", "\\q", "\\h", "\\?",
+	".tables", ".schema", ".count", ".quit", ".exit", ".help", ".clear", ".version", ".aliases",
+	".paging", ".pagesize", ".pager", ".timing", ".mode", ".autocommit", ".save", ".connect", ".diff", ".sample", ".describe",
+}
+
+// SQL functions used for autocomplete when the function catalog cannot be read
+var sqlFunctions = []string{
+	"COUNT", "SUM", "AVG", "MIN", "MAX", "STDDEV", "MEDIAN", "MODE", "STRING_AGG",
+	"UPPER", "LOWER", "LENGTH", "SUBSTRING", "TRIM", "REPLACE", "CONCAT", "SPLIT_PART",
+	"REGEXP_MATCHES", "REGEXP_REPLACE", "COALESCE", "NULLIF", "CAST", "TRY_CAST",
+	"ABS", "ROUND", "CEIL", "FLOOR", "NOW", "CURRENT_DATE", "DATE_TRUNC", "DATE_PART",
+	"STRFTIME", "STRPTIME", "ROW_NUMBER", "RANK", "DENSE_RANK", "LAG", "LEAD",
 }
 
 // SQLCompleter provides SQL autocomplete functionality
 type SQLCompleter struct {
-	storage storage.Storage
-	tables  []string
-	columns map[string][]string
+	storage   storage.Storage
+	engine    storage.Engine // Engine of storage, whose catalog lists the tables
+	tables    []string
+	columns   map[string][]string
+	functions []string
+	commands  []string // Extra REPL commands such as user-defined aliases
 }
 
-// NewSQLCompleter creates a new SQL completer
-func NewSQLCompleter(storage storage.Storage) *SQLCompleter {
+// NewSQLCompleter creates a new SQL completer of the tables of storage,
+// run by engine
+func NewSQLCompleter(storage storage.Storage, engine storage.Engine) *SQLCompleter {
 	return &SQLCompleter{
 		storage: storage,
+		engine:  engine,
 		columns: make(map[string][]string),
 	}
 }
 
-// RefreshSchema updates the table and column information from storage
+// AddCommands registers extra REPL commands (e.g. user-defined aliases) for completion
+func (c *SQLCompleter) AddCommands(commands ...string) {
+	c.commands = append(c.commands, commands...)
+}
+
+// RefreshSchema updates the table, column and function information from storage.
+// It should be called again whenever tables are created or dropped during a session.
 func (c *SQLCompleter) RefreshSchema() error {
-	// Get tables from storage
-	rows, err := c.storage.ShowTables()
+	rows, err := c.storage.Query(storage.ColumnsQuery(c.engine, ""))
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
 
-	c.tables = nil
+	var tables []string
+	columns := make(map[string][]string)
 	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
+		var tableName, column, dataType string
+		if err := rows.Scan(&tableName, &column, &dataType); err != nil {
 			continue
 		}
-		c.tables = append(c.tables, tableName)
-
-		// Get columns for this table
-		cols, err := c.getTableColumns(tableName)
-		if err != nil {
-			continue
+		if _, ok := columns[tableName]; !ok {
+			tables = append(tables, tableName)
 		}
-		c.columns[tableName] = cols
+		columns[tableName] = append(columns[tableName], column)
+	}
+	rows.Close()
+	c.tables = tables
+	c.columns = columns
+
+	// Function names don't change during a session, so they are only loaded once
+	if len(c.functions) == 0 {
+		c.functions = c.loadFunctions()
 	}
 
 	return nil
 }
 
-// getTableColumns retrieves column names for a table
-func (c *SQLCompleter) getTableColumns(tableName string) ([]string, error) {
-	// Use DuckDB's information_schema to get column names
-	query := fmt.Sprintf(`SELECT column_name
-		FROM information_schema.columns
-		WHERE table_schema = 'main' AND table_name = '%s'
-		ORDER BY ordinal_position`, tableName)
-	rows, err := c.storage.Query(query)
+// loadFunctions retrieves the function names known by DuckDB,
+// falling back to a static list if the catalog is unavailable
+func (c *SQLCompleter) loadFunctions() []string {
+	rows, err := c.storage.Query(`SELECT DISTINCT upper(function_name)
+		FROM duckdb_functions()
+		WHERE function_type IN ('scalar', 'aggregate', 'table')
+			AND NOT starts_with(function_name, '__')
+		ORDER BY 1`)
 	if err != nil {
-		return nil, err
+		return sqlFunctions
 	}
 	defer rows.Close()
 
-	var columns []string
+	var functions []string
 	for rows.Next() {
 		var name string
 		if err := rows.Scan(&name); err != nil {
 			continue
 		}
-		columns = append(columns, name)
+		functions = append(functions, name)
 	}
 
-	return columns, nil
+	if len(functions) == 0 {
+		return sqlFunctions
+	}
+	return functions
 }
 
-// Complete implements the readline.AutoCompleter interface
+// Do implements the readline.AutoCompleter interface
 func (c *SQLCompleter) Do(line []rune, pos int) (newLine [][]rune, length int) {
 	lineStr := string(line[:pos])
-	words := strings.Fields(lineStr)
 
-	var prefix string
-	if len(lineStr) > 0 && lineStr[len(lineStr)-1] != ' ' && len(words) > 0 {
-		prefix = words[len(words)-1]
-	}
+	// The word being completed starts after the last separator
+	start := strings.LastIndexAny(lineStr, wordSeparators) + 1
+	word := lineStr[start:]
+	words := strings.FieldsFunc(lineStr[:start], isWordSeparator)
 
 	// Determine context for completion
-	context := c.detectContext(words)
+	context := c.detectContext(words, word)
 
+	prefix := word
 	var candidates []string
 	switch context {
 	case contextKeyword:
@@ -117,17 +142,22 @@ func (c *SQLCompleter) Do(line []rune, pos int) (newLine [][]rune, length int) {
 	case contextTable:
 		candidates = c.filterByPrefix(c.tables, prefix)
 	case contextColumn:
-		candidates = c.getAllColumns(prefix)
+		candidates = append(candidates, c.getAllColumns(prefix)...)
+		candidates = append(candidates, c.filterByPrefix(c.functions, prefix)...)
+		candidates = append(candidates, c.filterByPrefix(sqlKeywords, prefix)...)
 	case contextTableColumn:
-		tableName := c.extractTableName(words)
-		if cols, ok := c.columns[tableName]; ok {
-			candidates = c.filterByPrefix(cols, prefix)
-		}
+		// Complete "table.col" or "alias.col" using only the part after the dot
+		idx := strings.LastIndex(word, ".")
+		prefix = word[idx+1:]
+		tableName := c.resolveTable(word[:idx], string(line))
+		candidates = c.filterByPrefix(c.lookupColumns(tableName), prefix)
 	default:
-		// Default: suggest keywords, tables, and REPL commands
+		// Default: suggest keywords, functions, tables, and REPL commands
 		candidates = append(candidates, c.filterByPrefix(sqlKeywords, prefix)...)
+		candidates = append(candidates, c.filterByPrefix(c.functions, prefix)...)
 		candidates = append(candidates, c.filterByPrefix(c.tables, prefix)...)
 		candidates = append(candidates, c.filterByPrefix(replCommands, prefix)...)
+		candidates = append(candidates, c.filterByPrefix(c.commands, prefix)...)
 	}
 
 	// Remove duplicates
@@ -136,14 +166,18 @@ func (c *SQLCompleter) Do(line []rune, pos int) (newLine [][]rune, length int) {
 	// Convert to readline format
 	newLine = make([][]rune, len(candidates))
 	for i, cand := range candidates {
-		suffix := cand
-		if len(prefix) > 0 {
-			suffix = cand[len(prefix):]
-		}
-		newLine[i] = []rune(suffix)
+		newLine[i] = []rune(cand[len(prefix):])
 	}
 
-	return newLine, len(prefix)
+	return newLine, len([]rune(prefix))
+}
+
+// wordSeparators are the characters that delimit words when completing
+const wordSeparators = " \t\n,()=<>;"
+
+// isWordSeparator reports whether r delimits words when completing
+func isWordSeparator(r rune) bool {
+	return strings.ContainsRune(wordSeparators, r)
 }
 
 type completionContext int
@@ -156,49 +190,90 @@ const (
 	contextTableColumn
 )
 
-// detectContext determines what type of completion is needed
-func (c *SQLCompleter) detectContext(words []string) completionContext {
+// detectContext determines what type of completion is needed based on
+// the words preceding the one being completed
+func (c *SQLCompleter) detectContext(words []string, word string) completionContext {
+	// After dot, suggest columns for specific table or alias
+	if strings.Contains(word, ".") {
+		return contextTableColumn
+	}
+
 	if len(words) == 0 {
 		return contextKeyword
 	}
 
-	lastWord := strings.ToUpper(words[len(words)-1])
-
-	// After FROM or JOIN, suggest tables
-	for i := len(words) - 1; i >= 0; i-- {
-		w := strings.ToUpper(words[i])
-		if w == "FROM" || w == "JOIN" || w == "INTO" || w == "UPDATE" || w == "TABLE" {
-			if i == len(words)-1 {
-				return contextTable
-			}
-			break
-		}
+	switch strings.ToUpper(words[len(words)-1]) {
+	case "FROM", "JOIN", "INTO", "UPDATE", "TABLE", "DESCRIBE", "SUMMARIZE":
+		return contextTable
+	case "SELECT", "WHERE", "AND", "OR", "NOT", "BY", "ON", "SET", "HAVING", "DISTINCT", "CASE", "WHEN", "THEN", "ELSE":
+		return contextColumn
 	}
 
-	// After SELECT or WHERE, suggest columns
-	if lastWord == "SELECT" || lastWord == "WHERE" || lastWord == "AND" || lastWord == "OR" ||
-		lastWord == "BY" || lastWord == "ON" || lastWord == "SET" || lastWord == "," {
-		return contextColumn
+	return contextDefault
+}
+
+// resolveTable maps a qualifier (table name or alias) to a table name using the query text
+func (c *SQLCompleter) resolveTable(qualifier, query string) string {
+	if c.lookupColumns(qualifier) != nil {
+		return qualifier
 	}
 
-	// After dot, suggest columns for specific table
-	if strings.Contains(lastWord, ".") {
-		return contextTableColumn
+	if table, ok := extractAliases(query)[strings.ToLower(qualifier)]; ok {
+		return table
 	}
 
-	return contextDefault
+	return qualifier
 }
 
-// extractTableName extracts the table name from the last "table." prefix
-func (c *SQLCompleter) extractTableName(words []string) string {
-	if len(words) == 0 {
-		return ""
+// lookupColumns returns the columns of a table using a case-insensitive match
+func (c *SQLCompleter) lookupColumns(tableName string) []string {
+	if cols, ok := c.columns[tableName]; ok {
+		return cols
 	}
-	lastWord := words[len(words)-1]
-	if idx := strings.LastIndex(lastWord, "."); idx != -1 {
-		return strings.ToLower(lastWord[:idx])
+	for name, cols := range c.columns {
+		if strings.EqualFold(name, tableName) {
+			return cols
+		}
+	}
+	return nil
+}
+
+// extractAliases finds "table alias" and "table AS alias" pairs after FROM/JOIN
+// Returns a map of lowercase alias -> table name
+func extractAliases(query string) map[string]string {
+	aliases := make(map[string]string)
+	words := strings.FieldsFunc(query, isWordSeparator)
+
+	for i := 0; i < len(words)-1; i++ {
+		w := strings.ToUpper(words[i])
+		if w != "FROM" && w != "JOIN" {
+			continue
+		}
+
+		table := words[i+1]
+		j := i + 2
+		if j < len(words) && strings.EqualFold(words[j], "AS") {
+			j++
+		}
+		if j >= len(words) || isReservedWord(words[j]) {
+			continue
+		}
+
+		aliases[strings.ToLower(words[j])] = table
+	}
+
+	return aliases
+}
+
+// isReservedWord checks if a word is a SQL keyword that cannot be used as an alias
+func isReservedWord(word string) bool {
+	upper := strings.ToUpper(word)
+	for _, kw := range sqlKeywords {
+		if kw == upper {
+			return true
+		}
 	}
-	return ""
+	return upper == "USING" || upper == "NATURAL" || upper == "CROSS" || upper == "FULL"
 }
 
 // filterByPrefix filters items by prefix (case-insensitive)
@@ -0,0 +1,63 @@
+package repl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCompleter() *SQLCompleter {
+	return &SQLCompleter{
+		tables: []string{"users", "orders"},
+		columns: map[string][]string{
+			"users":  {"id", "name", "email"},
+			"orders": {"id", "user_id", "amount"},
+		},
+		functions: sqlFunctions,
+	}
+}
+
+func complete(c *SQLCompleter, line string) []string {
+	candidates, _ := c.Do([]rune(line), len([]rune(line)))
+	result := make([]string, len(candidates))
+	for i, cand := range candidates {
+		result[i] = string(cand)
+	}
+	return result
+}
+
+func TestCompleteTableAfterFrom(t *testing.T) {
+	c := newTestCompleter()
+	assert.Equal(t, []string{"ers"}, complete(c, "SELECT * FROM ord"))
+}
+
+func TestCompleteColumnAfterTableName(t *testing.T) {
+	c := newTestCompleter()
+	assert.Equal(t, []string{"ail"}, complete(c, "SELECT users.em"))
+}
+
+func TestCompleteColumnAfterAlias(t *testing.T) {
+	c := newTestCompleter()
+
+	// Cursor inside the SELECT list, with the alias defined later in the line
+	line := []rune("SELECT o.u FROM orders o")
+	candidates, length := c.Do(line, len("SELECT o.u"))
+	assert.Equal(t, 1, length)
+	assert.Equal(t, [][]rune{[]rune("ser_id")}, candidates)
+
+	assert.ElementsMatch(t, []string{"ser_id"}, complete(c, "SELECT * FROM orders AS o WHERE o.u"))
+	assert.ElementsMatch(t, []string{"ame"}, complete(c, "SELECT * FROM users u JOIN orders o ON u.id = o.user_id WHERE u.n"))
+}
+
+func TestCompleteFunctions(t *testing.T) {
+	c := newTestCompleter()
+	assert.Contains(t, complete(c, "SELECT COAL"), "ESCE")
+	assert.Contains(t, complete(c, "SELECT count(DISTINCT na"), "me")
+}
+
+func TestExtractAliases(t *testing.T) {
+	aliases := extractAliases("SELECT * FROM users u JOIN orders AS o ON u.id = o.user_id WHERE x")
+	assert.Equal(t, map[string]string{"u": "users", "o": "orders"}, aliases)
+
+	assert.Empty(t, extractAliases("SELECT * FROM users WHERE id = 1"))
+}
@@ -0,0 +1,275 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/chzyer/readline"
+)
+
+// HistorySearch provides a fuzzy incremental reverse search (Ctrl-R) over the
+// persistent history file. It replaces readline's built-in substring search:
+// typing narrows the matches, Ctrl-R cycles to older matches, Enter runs the
+// selected query, arrows and other editing keys accept the match and a lone
+// Esc or Ctrl-G restores the original line.
+type HistorySearch struct {
+	historyFile string
+	prompt      string
+	instance    *readline.Instance
+
+	active   bool
+	history  []string // History file entries, loaded when the search starts
+	query    []rune
+	matches  []string
+	index    int
+	original string
+	current  string
+	escape   []rune // Escape sequence being read (nil: none)
+}
+
+// NewHistorySearch creates a history search over the given history file
+func NewHistorySearch(historyFile string) *HistorySearch {
+	return &HistorySearch{historyFile: historyFile}
+}
+
+// Attach binds the search to a readline instance and its normal prompt
+func (h *HistorySearch) Attach(instance *readline.Instance, prompt string) {
+	h.instance = instance
+	h.prompt = prompt
+}
+
+// OnChange implements readline.Listener and keeps track of the current line
+func (h *HistorySearch) OnChange(line []rune, _ int, _ rune) ([]rune, int, bool) {
+	if !h.active {
+		h.current = string(line)
+	}
+	return nil, 0, false
+}
+
+// FilterInputRune is used as readline's FuncFilterInputRune. It intercepts
+// Ctrl-R and all keys typed while a search is in progress.
+func (h *HistorySearch) FilterInputRune(r rune) (rune, bool) {
+	if h.instance == nil {
+		return r, true
+	}
+
+	if !h.active {
+		if r != readline.CharBckSearch {
+			return r, true
+		}
+		h.start()
+		return r, false
+	}
+
+	if h.escape != nil {
+		return h.filterEscape(r)
+	}
+
+	switch r {
+	case readline.CharBckSearch:
+		// Cycle to the next (older) match
+		if len(h.matches) > 0 {
+			h.index = (h.index + 1) % len(h.matches)
+		}
+		h.update()
+		return r, false
+
+	case readline.CharBackspace, readline.CharCtrlH:
+		if len(h.query) > 0 {
+			h.query = h.query[:len(h.query)-1]
+		}
+		h.search()
+		return r, false
+
+	case readline.CharEsc:
+		// Either a lone Esc or the start of an escape sequence (arrows, Home, ...)
+		h.escape = []rune{}
+		return r, false
+
+	case readline.CharBell:
+		// Cancel the search and restore the original line
+		h.stop(h.original)
+		return r, false
+
+	case readline.CharEnter, readline.CharCtrlJ:
+		// Accept the match and let readline submit the line
+		h.stop(h.selected())
+		return r, true
+	}
+
+	if unicode.IsPrint(r) {
+		h.query = append(h.query, r)
+		h.search()
+		return r, false
+	}
+
+	// Any other control key (arrows, Ctrl-A, ...) accepts the match for editing
+	h.stop(h.selected())
+	return r, true
+}
+
+// filterEscape handles the keys following Esc. A CSI ("Esc [") or SS3
+// ("Esc O") sequence accepts the match and is passed on as the key it
+// encodes; any other key means Esc was pressed alone, which cancels the
+// search before the key is handled as usual.
+func (h *HistorySearch) filterEscape(r rune) (rune, bool) {
+	if len(h.escape) == 0 {
+		if r != '[' && r != 'O' {
+			h.escape = nil
+			h.stop(h.original)
+			return r, true
+		}
+		h.escape = append(h.escape, r)
+		return r, false
+	}
+
+	// CSI sequences end with a byte in the @..~ range, after parameter bytes
+	h.escape = append(h.escape, r)
+	if h.escape[0] == '[' && (r < '@' || r > '~') {
+		return r, false
+	}
+
+	sequence := string(h.escape)
+	h.escape = nil
+	h.stop(h.selected())
+	if key, ok := escapeKeys[sequence]; ok {
+		return key, true
+	}
+	return r, false
+}
+
+// escapeKeys maps the escape sequences of editing keys to the readline keys
+var escapeKeys = map[string]rune{
+	"[A":  readline.CharPrev,
+	"[B":  readline.CharNext,
+	"[C":  readline.CharForward,
+	"[D":  readline.CharBackward,
+	"[H":  readline.CharLineStart,
+	"[F":  readline.CharLineEnd,
+	"[3~": readline.CharDelete,
+	"OA":  readline.CharPrev,
+	"OB":  readline.CharNext,
+	"OC":  readline.CharForward,
+	"OD":  readline.CharBackward,
+	"OH":  readline.CharLineStart,
+	"OF":  readline.CharLineEnd,
+}
+
+// start enters search mode
+func (h *HistorySearch) start() {
+	h.active = true
+	h.query = nil
+	h.index = 0
+	h.original = h.current
+	h.history = h.loadHistory()
+	h.search()
+}
+
+// stop leaves search mode, restoring the normal prompt and the given line
+func (h *HistorySearch) stop(line string) {
+	h.active = false
+	h.history = nil
+	h.query = nil
+	h.matches = nil
+	h.current = line
+	h.instance.SetPrompt(h.prompt)
+	h.instance.Operation.SetBuffer(line)
+}
+
+// search recomputes the matches for the current query
+func (h *HistorySearch) search() {
+	h.matches = FuzzyMatches(h.history, string(h.query))
+	h.index = 0
+	h.update()
+}
+
+// update shows the selected match and the search status in the prompt
+func (h *HistorySearch) update() {
+	status := "reverse-i-search"
+	if len(h.query) > 0 && len(h.matches) == 0 {
+		status = "failing reverse-i-search"
+	}
+	h.instance.SetPrompt(fmt.Sprintf("(%s)`%s': ", status, string(h.query)))
+	h.instance.Operation.SetBuffer(h.selected())
+}
+
+// selected returns the currently selected match, or the original line if there is none
+func (h *HistorySearch) selected() string {
+	if len(h.matches) == 0 {
+		if len(h.query) == 0 {
+			return h.original
+		}
+		return ""
+	}
+	return h.matches[h.index]
+}
+
+// loadHistory reads the history file, oldest entry first
+func (h *HistorySearch) loadHistory() []string {
+	file, err := os.Open(h.historyFile)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			entries = append(entries, line)
+		}
+	}
+
+	return entries
+}
+
+// FuzzyMatches returns the history entries matching query, most recent first.
+// Entries containing query as a substring come before entries that only
+// contain its characters in order (fuzzy match). Matching is case-insensitive
+// and duplicate entries are returned once.
+func FuzzyMatches(history []string, query string) []string {
+	query = strings.ToLower(query)
+	seen := make(map[string]bool)
+
+	var exact, fuzzy []string
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := history[i]
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+
+		lower := strings.ToLower(entry)
+		switch {
+		case strings.Contains(lower, query):
+			exact = append(exact, entry)
+		case isSubsequence(query, lower):
+			fuzzy = append(fuzzy, entry)
+		}
+	}
+
+	return append(exact, fuzzy...)
+}
+
+// isSubsequence reports whether all runes of sub appear in s in the same order
+func isSubsequence(sub, s string) bool {
+	subRunes := []rune(sub)
+	if len(subRunes) == 0 {
+		return true
+	}
+
+	i := 0
+	for _, r := range s {
+		if r == subRunes[i] {
+			i++
+			if i == len(subRunes) {
+				return true
+			}
+		}
+	}
+	return false
+}
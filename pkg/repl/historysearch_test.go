@@ -0,0 +1,106 @@
+package repl
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chzyer/readline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzyMatches(t *testing.T) {
+	history := []string{
+		"SELECT * FROM users",
+		"SELECT name FROM orders",
+		"SELECT COUNT(*) FROM users WHERE active",
+		"SELECT * FROM users",
+	}
+
+	// Most recent first, duplicates removed
+	assert.Equal(t, []string{
+		"SELECT * FROM users",
+		"SELECT COUNT(*) FROM users WHERE active",
+	}, FuzzyMatches(history, "from users"))
+
+	// Substring matches come before fuzzy (subsequence) matches, even if older
+	assert.Equal(t, []string{
+		"SELECT total FROM sales",
+		"SELECT * FROM t WHERE o = 1",
+	}, FuzzyMatches([]string{"SELECT total FROM sales", "SELECT * FROM t WHERE o = 1"}, "tot"))
+
+	assert.Equal(t, []string{"SELECT COUNT(*) FROM users WHERE active"}, FuzzyMatches(history, "cntwhr"))
+	assert.Empty(t, FuzzyMatches(history, "xyz"))
+	assert.Len(t, FuzzyMatches(history, ""), 3)
+}
+
+// newSearch returns a history search over entries, attached to a readline
+// instance that reads nothing
+func newSearch(t *testing.T, entries ...string) (*HistorySearch, *readline.Instance) {
+	t.Helper()
+
+	historyFile := filepath.Join(t.TempDir(), "history")
+	require.NoError(t, os.WriteFile(historyFile, []byte(strings.Join(entries, "\n")+"\n"), 0o600))
+
+	search := NewHistorySearch(historyFile)
+	instance, err := readline.NewEx(&readline.Config{
+		Prompt: "> ",
+		Stdin:  io.NopCloser(strings.NewReader("")),
+		Stdout: io.Discard,
+		Stderr: io.Discard,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = instance.Close() })
+	search.Attach(instance, "> ")
+	return search, instance
+}
+
+// typeKeys feeds keys (\x12 is Ctrl-R, \x07 Ctrl-G) to the search and returns
+// those passed on to readline
+func typeKeys(search *HistorySearch, keys string) []rune {
+	var passed []rune
+	for _, r := range keys {
+		if key, ok := search.FilterInputRune(r); ok {
+			passed = append(passed, key)
+		}
+	}
+	return passed
+}
+
+func TestHistorySearch_EscapeSequences(t *testing.T) {
+	search, _ := newSearch(t, "SELECT 1", "SELECT name FROM users")
+
+	// An arrow key accepts the match and is passed on as the readline key
+	passed := typeKeys(search, "\x12users\x1b[D")
+	assert.Equal(t, []rune{readline.CharBackward}, passed)
+	assert.False(t, search.active)
+	assert.Equal(t, "SELECT name FROM users", search.current)
+
+	passed = typeKeys(search, "\x12sel\x1bOA")
+	assert.Equal(t, []rune{readline.CharPrev}, passed)
+	assert.False(t, search.active)
+
+	// A lone Esc cancels the search; the next key is handled as usual
+	passed = typeKeys(search, "\x12users\x1bx")
+	assert.Equal(t, []rune{'x'}, passed)
+	assert.False(t, search.active)
+	assert.Equal(t, "SELECT name FROM users", search.current)
+
+	passed = typeKeys(search, "\x12xyz\x07")
+	assert.Empty(t, passed)
+	assert.False(t, search.active)
+}
+
+func TestHistorySearch_LoadsHistoryOnce(t *testing.T) {
+	search, _ := newSearch(t, "SELECT 1", "SELECT name FROM users")
+
+	typeKeys(search, "\x12")
+	require.NoError(t, os.Remove(search.historyFile))
+
+	// Typing narrows the entries loaded when the search started
+	typeKeys(search, "name")
+	assert.Equal(t, []string{"SELECT name FROM users"}, search.matches)
+}
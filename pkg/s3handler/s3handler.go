@@ -4,22 +4,62 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/adrianolaselva/dataql/pkg/cachehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
+	"github.com/adrianolaselva/dataql/pkg/rangedownload"
+	"github.com/adrianolaselva/dataql/pkg/tlsconfig"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
+// defaultRegion is the region of S3-compatible endpoints and of role
+// assumption when none is configured
+const defaultRegion = "us-east-1"
+
 // S3Handler handles downloading files from S3
 type S3Handler struct {
 	tempDir   string
 	tempFiles []string
 	client    *s3.Client
+	cache     *cachehandler.DownloadCache // Keeps downloads between runs (nil: disabled)
+	download  rangedownload.Options       // Parts of large objects
+	tls       tlsconfig.Options           // CAs of --ca-cert, --insecure-skip-verify
+	progress  *progress.Reporter          // Shows the downloads (nil: not shown)
+	options   Options
+}
+
+// Options selects the endpoint, credentials and billing of S3 requests
+type Options struct {
+	Endpoint      string // S3-compatible endpoint such as MinIO or Ceph (default: $AWS_ENDPOINT_URL_S3, $AWS_ENDPOINT_URL)
+	Profile       string // Named profile of the shared AWS config files
+	AssumeRole    string // ARN of a role assumed with the resolved credentials
+	RequesterPays bool   // Bill the requests to the caller, as requester-pays buckets require
+}
+
+// Check validates the endpoint and the role ARN
+func (o Options) Check() error {
+	if o.Endpoint != "" {
+		u, err := url.Parse(o.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid --s3-endpoint %q (expected http(s)://host[:port])", o.Endpoint)
+		}
+	}
+	if o.AssumeRole != "" && !strings.HasPrefix(o.AssumeRole, "arn:") {
+		return fmt.Errorf("invalid --s3-assume-role %q (expected a role ARN, e.g. arn:aws:iam::123456789012:role/reader)", o.AssumeRole)
+	}
+	return nil
 }
 
 // S3Location represents a parsed S3 URL
@@ -37,6 +77,34 @@ func NewS3Handler() *S3Handler {
 	return &S3Handler{}
 }
 
+// SetCache keeps downloads in a cache, reused while the object keeps its
+// version id or ETag
+func (h *S3Handler) SetCache(cache *cachehandler.DownloadCache) {
+	h.cache = cache
+}
+
+// SetDownload sets how many parts of large objects are downloaded at the
+// same time and their size
+func (h *S3Handler) SetDownload(opts rangedownload.Options) {
+	h.download = opts
+}
+
+// SetTLS sets the certificate authorities trusted by the client, or turns
+// off certificate verification
+func (h *S3Handler) SetTLS(opts tlsconfig.Options) {
+	h.tls = opts
+}
+
+// SetProgress shows the progress of the downloads with reporter
+func (h *S3Handler) SetProgress(reporter *progress.Reporter) {
+	h.progress = reporter
+}
+
+// SetOptions sets the endpoint, profile, role and billing of the requests
+func (h *S3Handler) SetOptions(opts Options) {
+	h.options = opts
+}
+
 // IsS3URL checks if a string is an S3 URL
 func IsS3URL(path string) bool {
 	return strings.HasPrefix(path, "s3://")
@@ -103,17 +171,50 @@ func (h *S3Handler) downloadS3File(s3URL string) (string, error) {
 	filename := filepath.Base(loc.Key)
 	localPath := filepath.Join(h.tempDir, filename)
 
-	// Download the file
 	ctx := context.Background()
+
+	head, headErr := h.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       &loc.Bucket,
+		Key:          &loc.Key,
+		RequestPayer: h.requestPayer(),
+	})
+
+	// Reuse a cached download of the same version of the object
+	if h.cache != nil && headErr == nil {
+		if cached, ok := h.cache.Lookup(s3URL); ok && cached.Matches(aws.ToString(head.ETag), aws.ToString(head.VersionId)) {
+			return h.cache.Use(cached), nil
+		}
+	}
+
+	// Download large objects in parts
+	if headErr == nil && h.download.Parallel(aws.ToInt64(head.ContentLength)) {
+		return h.downloadParts(ctx, s3URL, loc, head, localPath)
+	}
+
+	// Download the file
 	resp, err := h.client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: &loc.Bucket,
-		Key:    &loc.Key,
+		Bucket:       &loc.Bucket,
+		Key:          &loc.Key,
+		RequestPayer: h.requestPayer(),
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to get S3 object: %w", err)
 	}
 	defer resp.Body.Close()
 
+	phase := h.progress.Start(progress.Download, filename, aws.ToInt64(resp.ContentLength))
+	defer phase.Done()
+	body := phase.Reader(resp.Body)
+
+	if h.cache != nil {
+		return h.cache.Store(cachehandler.Download{
+			Source:    s3URL,
+			File:      filename,
+			ETag:      aws.ToString(resp.ETag),
+			VersionID: aws.ToString(resp.VersionId),
+		}, body)
+	}
+
 	// Create local file
 	file, err := os.Create(localPath)
 	if err != nil {
@@ -122,7 +223,7 @@ func (h *S3Handler) downloadS3File(s3URL string) (string, error) {
 	defer file.Close()
 
 	// Copy content
-	_, err = io.Copy(file, resp.Body)
+	_, err = io.Copy(file, body)
 	if err != nil {
 		return "", fmt.Errorf("failed to write file content: %w", err)
 	}
@@ -131,13 +232,60 @@ func (h *S3Handler) downloadS3File(s3URL string) (string, error) {
 	return localPath, nil
 }
 
+// downloadParts downloads the version of the object head describes with
+// ranged GETs
+func (h *S3Handler) downloadParts(ctx context.Context, s3URL string, loc *S3Location, head *s3.HeadObjectOutput, localPath string) (string, error) {
+	size := aws.ToInt64(head.ContentLength)
+	openRange := func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+		resp, err := h.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket:       &loc.Bucket,
+			Key:          &loc.Key,
+			Range:        aws.String(rangedownload.HTTPRange(offset, length)),
+			IfMatch:      head.ETag,
+			VersionId:    head.VersionId,
+			RequestPayer: h.requestPayer(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get S3 object: %w", err)
+		}
+		return resp.Body, nil
+	}
+
+	opts := h.download
+	if phase := h.progress.Start(progress.Download, filepath.Base(localPath), size); phase != nil {
+		defer phase.Done()
+		opts.Progress = phase
+	}
+
+	if h.cache != nil {
+		return h.cache.StoreFile(cachehandler.Download{
+			Source:    s3URL,
+			File:      filepath.Base(localPath),
+			ETag:      aws.ToString(head.ETag),
+			VersionID: aws.ToString(head.VersionId),
+		}, func(file *os.File) error {
+			return rangedownload.Download(ctx, file, size, openRange, opts)
+		})
+	}
+
+	if err := rangedownload.DownloadFile(ctx, localPath, size, openRange, opts); err != nil {
+		return "", fmt.Errorf("failed to write file content: %w", err)
+	}
+	h.tempFiles = append(h.tempFiles, localPath)
+	return localPath, nil
+}
+
 // initClient initializes the S3 client using default AWS credentials
-// Supports LocalStack via AWS_ENDPOINT_URL or AWS_ENDPOINT_URL_S3 environment variables
+// Supports LocalStack, MinIO and Ceph via --s3-endpoint, AWS_ENDPOINT_URL or
+// AWS_ENDPOINT_URL_S3 environment variables
 func (h *S3Handler) initClient() error {
 	ctx := context.Background()
 
 	// Check for custom endpoint (LocalStack support)
-	endpointURL := os.Getenv("AWS_ENDPOINT_URL_S3")
+	endpointURL := h.options.Endpoint
+	if endpointURL == "" {
+		endpointURL = os.Getenv("AWS_ENDPOINT_URL_S3")
+	}
 	if endpointURL == "" {
 		endpointURL = os.Getenv("AWS_ENDPOINT_URL")
 	}
@@ -145,13 +293,18 @@ func (h *S3Handler) initClient() error {
 	// Load AWS configuration from environment, shared config, etc.
 	var opts []func(*config.LoadOptions) error
 
-	// Check for explicit credentials (useful for LocalStack)
-	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
-	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
-	if accessKey != "" && secretKey != "" {
-		opts = append(opts, config.WithCredentialsProvider(
-			credentials.NewStaticCredentialsProvider(accessKey, secretKey, ""),
-		))
+	if h.options.Profile != "" {
+		// The credentials of the profile take precedence over the environment
+		opts = append(opts, config.WithSharedConfigProfile(h.options.Profile))
+	} else {
+		// Check for explicit credentials (useful for LocalStack)
+		accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+		secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+		if accessKey != "" && secretKey != "" {
+			opts = append(opts, config.WithCredentialsProvider(
+				credentials.NewStaticCredentialsProvider(accessKey, secretKey, ""),
+			))
+		}
 	}
 
 	// Set region if specified
@@ -163,10 +316,35 @@ func (h *S3Handler) initClient() error {
 		opts = append(opts, config.WithRegion(region))
 	}
 
+	// Trust the CAs of --ca-cert; the transport keeps the proxies of HTTPS_PROXY
+	if !h.tls.IsZero() {
+		transport, err := h.tls.Transport()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, config.WithHTTPClient(&http.Client{Transport: transport}))
+	}
+
 	cfg, err := config.LoadDefaultConfig(ctx, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
+	// S3-compatible servers accept any region, but requests must be signed with one
+	if cfg.Region == "" && endpointURL != "" {
+		cfg.Region = defaultRegion
+	}
+
+	// Assume the role with the resolved credentials, renewed before they expire
+	if h.options.AssumeRole != "" {
+		stsClient := sts.NewFromConfig(cfg, func(o *sts.Options) {
+			if o.Region == "" {
+				o.Region = defaultRegion
+			}
+		})
+		cfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClient, h.options.AssumeRole, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = "dataql"
+		}))
+	}
 
 	// Create S3 client with optional custom endpoint
 	var s3Opts []func(*s3.Options)
@@ -184,6 +362,14 @@ func (h *S3Handler) initClient() error {
 	return nil
 }
 
+// requestPayer returns the payer of requests to requester-pays buckets
+func (h *S3Handler) requestPayer() types.RequestPayer {
+	if h.options.RequesterPays {
+		return types.RequestPayerRequester
+	}
+	return ""
+}
+
 // Cleanup removes all downloaded temp files
 func (h *S3Handler) Cleanup() error {
 	if h.tempDir != "" {
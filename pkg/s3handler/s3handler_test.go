@@ -0,0 +1,110 @@
+package s3handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var content = []byte("id,name\n1,alice\n2,bob\n")
+
+// fakeS3 serves content at /bucket/data.csv with path-style addressing, as
+// MinIO and Ceph do, and records the headers of the requests
+type fakeS3 struct {
+	*httptest.Server
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+func newFakeS3(t *testing.T) *fakeS3 {
+	f := &fakeS3{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.headers = append(f.headers, r.Header.Clone())
+		f.mu.Unlock()
+		if r.URL.Path != "/bucket/data.csv" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "data.csv", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// isolateAWS keeps the configuration of the machine out of the test
+func isolateAWS(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION", "AWS_PROFILE", "AWS_ENDPOINT_URL", "AWS_ENDPOINT_URL_S3", "AWS_SESSION_TOKEN"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "envkey")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+}
+
+func TestEndpointAndRequesterPays(t *testing.T) {
+	isolateAWS(t)
+	server := newFakeS3(t)
+
+	h := NewS3Handler()
+	defer h.Cleanup()
+	h.SetOptions(Options{Endpoint: server.URL, RequesterPays: true})
+	paths, err := h.ResolveFiles([]string{"s3://bucket/data.csv"})
+	require.NoError(t, err)
+
+	downloaded, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+	for _, header := range server.headers {
+		assert.Equal(t, "requester", header.Get("X-Amz-Request-Payer"))
+		// Signed for the default region with the environment credentials
+		assert.Contains(t, header.Get("Authorization"), "Credential=envkey/")
+		assert.Contains(t, header.Get("Authorization"), "/"+defaultRegion+"/s3/")
+	}
+}
+
+func TestProfile(t *testing.T) {
+	isolateAWS(t)
+	server := newFakeS3(t)
+	config := "[profile minio]\naws_access_key_id = profilekey\naws_secret_access_key = profilesecret\nregion = eu-west-1\n"
+	require.NoError(t, os.WriteFile(os.Getenv("AWS_CONFIG_FILE"), []byte(config), 0600))
+
+	h := NewS3Handler()
+	defer h.Cleanup()
+	h.SetOptions(Options{Endpoint: server.URL, Profile: "minio"})
+	_, err := h.ResolveFiles([]string{"s3://bucket/data.csv"})
+	require.NoError(t, err)
+
+	authorization := server.headers[0].Get("Authorization")
+	assert.Contains(t, authorization, "Credential=profilekey/")
+	assert.Contains(t, authorization, "/eu-west-1/s3/")
+	assert.Empty(t, server.headers[0].Get("X-Amz-Request-Payer"))
+
+	h = NewS3Handler()
+	defer h.Cleanup()
+	h.SetOptions(Options{Endpoint: server.URL, Profile: "missing"})
+	_, err = h.ResolveFiles([]string{"s3://bucket/data.csv"})
+	assert.ErrorContains(t, err, "missing")
+}
+
+func TestOptionsCheck(t *testing.T) {
+	assert.NoError(t, Options{}.Check())
+	assert.NoError(t, Options{Endpoint: "http://localhost:9000", AssumeRole: "arn:aws:iam::123456789012:role/reader"}.Check())
+
+	for _, endpoint := range []string{"localhost:9000", "ftp://host", "http://"} {
+		assert.ErrorContains(t, Options{Endpoint: endpoint}.Check(), "invalid --s3-endpoint", endpoint)
+	}
+	assert.ErrorContains(t, Options{AssumeRole: "reader"}.Check(), "invalid --s3-assume-role")
+}
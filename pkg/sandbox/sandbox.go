@@ -0,0 +1,128 @@
+// Package sandbox restricts the sources and output files the servers open
+// for their clients (mcp serve and serve --allowed-paths/--allowed-schemes).
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/internal/dataql"
+)
+
+// SchemeFile designates local paths in --allowed-schemes
+const SchemeFile = "file"
+
+// Policy restricts the sources and output files clients may open. The zero
+// Policy allows any source.
+type Policy struct {
+	paths   []string // Absolute directories local files must be in (empty: any directory)
+	schemes []string // Allowed URL schemes; "file" allows local paths (empty: any scheme)
+}
+
+// NewPolicy resolves the allowed directories. When directories are given
+// without schemes, only local files are allowed.
+func NewPolicy(paths, schemes []string) (Policy, error) {
+	var policy Policy
+	for _, path := range paths {
+		abs, err := resolvePath(path)
+		if err != nil {
+			return Policy{}, fmt.Errorf("invalid allowed path %s: %w", path, err)
+		}
+		policy.paths = append(policy.paths, abs)
+	}
+	for _, scheme := range schemes {
+		policy.schemes = append(policy.schemes, strings.ToLower(strings.TrimSuffix(strings.TrimSpace(scheme), "://")))
+	}
+
+	if len(policy.paths) > 0 && len(policy.schemes) == 0 {
+		policy.schemes = []string{SchemeFile}
+	}
+	return policy, nil
+}
+
+// Enabled reports whether sources are restricted
+func (p Policy) Enabled() bool {
+	return len(p.paths) > 0 || len(p.schemes) > 0
+}
+
+// AllowsScheme reports whether sources with scheme may be opened
+func (p Policy) AllowsScheme(scheme string) bool {
+	if len(p.schemes) == 0 {
+		return true
+	}
+	for _, allowed := range p.schemes {
+		if allowed == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSource rejects sources outside the allowed schemes and directories
+func (p Policy) CheckSource(source string) error {
+	if !p.Enabled() {
+		return nil
+	}
+
+	path := dataql.ParseFileInput(source).Path
+	scheme := SchemeFile
+	if idx := strings.Index(path, "://"); idx > 0 {
+		scheme = strings.ToLower(path[:idx])
+		path = path[idx+3:]
+	} else if path == "-" {
+		return fmt.Errorf("source %s is not allowed: stdin is not available to clients", source)
+	}
+
+	if !p.AllowsScheme(scheme) {
+		return fmt.Errorf("source %s is not allowed: scheme %s is not in --allowed-schemes", source, scheme)
+	}
+
+	if scheme == SchemeFile {
+		return p.CheckPath(path)
+	}
+	return nil
+}
+
+// CheckPath rejects local files outside the allowed directories
+func (p Policy) CheckPath(path string) error {
+	if len(p.paths) == 0 {
+		return nil
+	}
+
+	abs, err := resolvePath(path)
+	if err != nil {
+		return fmt.Errorf("path %s is not allowed: %w", path, err)
+	}
+
+	for _, dir := range p.paths {
+		rel, err := filepath.Rel(dir, abs)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("path %s is not allowed: it is outside --allowed-paths", path)
+}
+
+// resolvePath returns the absolute path with symlinks resolved, so a link
+// cannot point outside an allowed directory. Paths that do not exist yet
+// (export targets, glob patterns) are resolved through their parent directory.
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved, nil
+	}
+
+	dir, file := filepath.Split(abs)
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		return filepath.Join(resolved, file), nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	return abs, nil
+}
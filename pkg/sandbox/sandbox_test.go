@@ -0,0 +1,51 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_CheckSource(t *testing.T) {
+	allowed := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(allowed, "data.csv"), []byte("id\n1\n"), 0o644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(allowed, "escape")))
+
+	policy, err := NewPolicy([]string{allowed}, nil)
+	require.NoError(t, err)
+
+	assert.NoError(t, policy.CheckSource(filepath.Join(allowed, "data.csv")))
+	assert.NoError(t, policy.CheckSource(filepath.Join(allowed, "data.csv")+":users"))
+	assert.NoError(t, policy.CheckSource(filepath.Join(allowed, "*.csv")))
+	assert.NoError(t, policy.CheckSource("file://"+filepath.Join(allowed, "data.csv")))
+
+	assert.ErrorContains(t, policy.CheckSource(filepath.Join(outside, "data.csv")), "outside --allowed-paths")
+	assert.ErrorContains(t, policy.CheckSource(filepath.Join(allowed, "..", "data.csv")), "outside --allowed-paths")
+	assert.ErrorContains(t, policy.CheckSource(filepath.Join(allowed, "escape", "data.csv")), "outside --allowed-paths")
+	assert.ErrorContains(t, policy.CheckSource("/etc/passwd"), "outside --allowed-paths")
+
+	// Only local files when no scheme is allowed explicitly
+	assert.ErrorContains(t, policy.CheckSource("https://example.com/data.csv"), "--allowed-schemes")
+	assert.ErrorContains(t, policy.CheckSource("-"), "stdin")
+}
+
+func TestPolicy_Schemes(t *testing.T) {
+	policy, err := NewPolicy(nil, []string{"S3", "postgres://"})
+	require.NoError(t, err)
+
+	assert.NoError(t, policy.CheckSource("s3://bucket/data.parquet"))
+	assert.NoError(t, policy.CheckSource("postgres://user@localhost/db?table=users"))
+	assert.Error(t, policy.CheckSource("mysql://user@localhost/db"))
+	assert.Error(t, policy.CheckSource("data.csv"))
+	assert.False(t, policy.AllowsScheme(SchemeFile))
+
+	disabled, err := NewPolicy(nil, nil)
+	require.NoError(t, err)
+	assert.False(t, disabled.Enabled())
+	assert.True(t, disabled.AllowsScheme(SchemeFile))
+	assert.NoError(t, disabled.CheckSource("/etc/passwd"))
+}
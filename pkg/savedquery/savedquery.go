@@ -0,0 +1,281 @@
+// Package savedquery keeps reusable named queries in YAML files: a personal
+// file under ~/.dataql and an optional team file checked into a repository.
+package savedquery
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvPath overrides the location of the personal queries file
+	EnvPath = "DATAQL_QUERIES_FILE"
+	// TeamFileName is the team file looked up from the working directory
+	// upwards, meant to be committed with the data it queries
+	TeamFileName = ".dataql-queries.yaml"
+
+	fileName = "queries.yaml"
+)
+
+// Scopes of a saved query
+const (
+	ScopePersonal = "personal"
+	ScopeTeam     = "team"
+)
+
+// ErrNotFound is returned for an unknown query name
+var ErrNotFound = errors.New("saved query not found")
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Query is a named query with the sources it reads. Placeholders such as
+// :region or $region are replaced by parameters when the query runs; Params
+// holds their default values.
+type Query struct {
+	Description string            `yaml:"description,omitempty"`
+	SQL         string            `yaml:"sql"`
+	Sources     []string          `yaml:"sources,omitempty"`
+	Delimiter   string            `yaml:"delimiter,omitempty"`
+	Params      map[string]string `yaml:"params,omitempty"`
+}
+
+// File is a queries file:
+//
+//	queries:
+//	  top_regions:
+//	    description: Best selling regions
+//	    sql: SELECT region, SUM(amount) AS total FROM sales GROUP BY region ORDER BY total DESC LIMIT :limit
+//	    sources: [data/sales.csv]
+//	    params:
+//	      limit: "10"
+//
+// Relative local sources are resolved against the directory of the file.
+type File struct {
+	Path    string           `yaml:"-"`
+	Queries map[string]Query `yaml:"queries"`
+}
+
+// Saved is a query found in one of the files
+type Saved struct {
+	Name  string
+	Scope string
+	Query
+}
+
+// DefaultPath returns $DATAQL_QUERIES_FILE or ~/.dataql/queries.yaml
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dataql", fileName), nil
+}
+
+// FindTeamFile returns the nearest team file in dir or its parents, or ""
+// when there is none
+func FindTeamFile(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, TeamFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load reads the queries file at path. A missing file yields no queries.
+func Load(path string) (*File, error) {
+	f := &File{Path: path, Queries: map[string]Query{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return f, nil
+		}
+		return nil, fmt.Errorf("failed to read queries file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse queries file %s: %w", path, err)
+	}
+	if f.Queries == nil {
+		f.Queries = map[string]Query{}
+	}
+	for name, query := range f.Queries {
+		if err := query.validate(name); err != nil {
+			return nil, fmt.Errorf("invalid queries file %s: %w", path, err)
+		}
+	}
+	return f, nil
+}
+
+// Dir returns the directory relative sources are resolved against
+func (f *File) Dir() string {
+	return filepath.Dir(f.Path)
+}
+
+// Set adds or replaces a query
+func (f *File) Set(name string, query Query) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if err := query.validate(name); err != nil {
+		return err
+	}
+	f.Queries[name] = query
+	return nil
+}
+
+// Remove deletes a query
+func (f *File) Remove(name string) error {
+	if _, ok := f.Queries[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(f.Queries, name)
+	return nil
+}
+
+// Save writes the file, creating its directory. Queries are written sorted
+// by name so the team file diffs cleanly.
+func (f *File) Save() error {
+	if err := os.MkdirAll(f.Dir(), 0755); err != nil {
+		return fmt.Errorf("failed to create queries directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to encode queries: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(f.Dir(), filepath.Base(f.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save queries: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save queries: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save queries: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to save queries: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.Path); err != nil {
+		return fmt.Errorf("failed to save queries: %w", err)
+	}
+	return nil
+}
+
+// Sources returns the sources of a query with relative local paths
+// resolved against the directory of the file
+func (f *File) Sources(query Query) []string {
+	sources := make([]string, len(query.Sources))
+	for i, source := range query.Sources {
+		sources[i] = resolvePath(f.Dir(), source)
+	}
+	return sources
+}
+
+// Catalog looks queries up in the personal file and the team file. The team
+// file is nil when there is none; its queries take precedence over personal
+// queries of the same name, like a repository's git config over the global one.
+type Catalog struct {
+	Personal *File
+	Team     *File
+}
+
+// Get returns the query with the given name, with its sources resolved
+func (c Catalog) Get(name string) (Saved, error) {
+	for _, f := range c.files() {
+		if query, ok := f.file.Queries[name]; ok {
+			query.Sources = f.file.Sources(query)
+			return Saved{Name: name, Scope: f.scope, Query: query}, nil
+		}
+	}
+	return Saved{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// List returns every query sorted by name. A personal query hidden by a
+// team query of the same name is not listed.
+func (c Catalog) List() []Saved {
+	seen := map[string]bool{}
+	var saved []Saved
+	for _, f := range c.files() {
+		for name, query := range f.file.Queries {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			query.Sources = f.file.Sources(query)
+			saved = append(saved, Saved{Name: name, Scope: f.scope, Query: query})
+		}
+	}
+	sort.Slice(saved, func(i, j int) bool { return saved[i].Name < saved[j].Name })
+	return saved
+}
+
+type scopedFile struct {
+	scope string
+	file  *File
+}
+
+// files returns the files in lookup order
+func (c Catalog) files() []scopedFile {
+	var files []scopedFile
+	if c.Team != nil {
+		files = append(files, scopedFile{ScopeTeam, c.Team})
+	}
+	if c.Personal != nil {
+		files = append(files, scopedFile{ScopePersonal, c.Personal})
+	}
+	return files
+}
+
+// ValidateName checks that a name can be typed on the command line
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid query name %q: use letters, digits, '_', '-' and '.'", name)
+	}
+	return nil
+}
+
+func (q Query) validate(name string) error {
+	if strings.TrimSpace(q.SQL) == "" {
+		return fmt.Errorf("query %q has no sql", name)
+	}
+	return nil
+}
+
+// resolvePath makes a relative local path absolute against dir
+func resolvePath(dir, path string) string {
+	if path == "-" || path == "" || filepath.IsAbs(path) || strings.Contains(path, "://") {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
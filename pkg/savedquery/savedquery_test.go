@@ -0,0 +1,100 @@
+package savedquery
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "queries.yaml")
+
+	file, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, file.Queries)
+
+	require.NoError(t, file.Set("top", Query{
+		Description: "Top regions",
+		SQL:         "SELECT * FROM sales LIMIT :limit",
+		Sources:     []string{"data/sales.csv", "s3://bucket/extra.csv"},
+		Params:      map[string]string{"limit": "5"},
+	}))
+	require.NoError(t, file.Set("all", Query{SQL: "SELECT * FROM sales"}))
+	require.NoError(t, file.Save())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Less(t, strings.Index(string(data), "all:"), strings.Index(string(data), "top:"), "queries are written sorted by name")
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.Queries, 2)
+	assert.Equal(t, "5", loaded.Queries["top"].Params["limit"])
+	assert.Equal(t, []string{filepath.Join(filepath.Dir(path), "data", "sales.csv"), "s3://bucket/extra.csv"},
+		loaded.Sources(loaded.Queries["top"]))
+
+	require.NoError(t, loaded.Remove("all"))
+	assert.True(t, errors.Is(loaded.Remove("all"), ErrNotFound))
+}
+
+func TestFile_Validation(t *testing.T) {
+	file, err := Load(filepath.Join(t.TempDir(), "queries.yaml"))
+	require.NoError(t, err)
+
+	assert.Error(t, file.Set("bad name", Query{SQL: "SELECT 1"}))
+	assert.Error(t, file.Set("empty", Query{SQL: "  "}))
+
+	path := filepath.Join(t.TempDir(), "queries.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("queries:\n  x:\n    sql: SELECT 1\n    unknown: 1\n"), 0644))
+	_, err = Load(path)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(""), 0644))
+	file, err = Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, file.Queries)
+}
+
+func TestFindTeamFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+
+	assert.Empty(t, FindTeamFile(nested))
+
+	team := filepath.Join(root, TeamFileName)
+	require.NoError(t, os.WriteFile(team, []byte("queries: {}\n"), 0644))
+	assert.Equal(t, team, FindTeamFile(nested))
+}
+
+func TestCatalog_TeamTakesPrecedence(t *testing.T) {
+	personal := &File{Path: "/home/me/.dataql/queries.yaml", Queries: map[string]Query{
+		"shared": {SQL: "SELECT 'personal'"},
+		"mine":   {SQL: "SELECT 'mine'"},
+	}}
+	team := &File{Path: "/repo/" + TeamFileName, Queries: map[string]Query{
+		"shared": {SQL: "SELECT 'team'", Sources: []string{"data.csv"}},
+	}}
+	catalog := Catalog{Personal: personal, Team: team}
+
+	saved, err := catalog.Get("shared")
+	require.NoError(t, err)
+	assert.Equal(t, ScopeTeam, saved.Scope)
+	assert.Equal(t, "SELECT 'team'", saved.SQL)
+	assert.Equal(t, []string{filepath.Join("/repo", "data.csv")}, saved.Sources)
+
+	list := catalog.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, "mine", list[0].Name)
+	assert.Equal(t, ScopePersonal, list[0].Scope)
+	assert.Equal(t, "shared", list[1].Name)
+	assert.Equal(t, ScopeTeam, list[1].Scope)
+
+	_, err = Catalog{Personal: personal}.Get("missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,193 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros are the shorthand schedules accepted in place of five fields
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+	dayNames = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+)
+
+// cronField describes the allowed values of one field of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	{name: "day of week", min: 0, max: 7, names: dayNames}, // 7 is Sunday too
+}
+
+// Cron is a parsed cron expression with the standard five fields: minute,
+// hour, day of month, month and day of week
+type Cron struct {
+	spec                      string
+	minutes, hours, days      uint64
+	months, weekdays          uint64
+	anyDayOfMonth, anyWeekday bool
+}
+
+// ParseCron parses a five-field cron expression such as "0 6 * * 1-5" or
+// one of the macros @hourly, @daily, @weekly, @monthly and @yearly. Fields
+// accept *, lists (1,15), ranges (1-5), steps (*/15, 0-30/10) and month and
+// weekday names (JAN, MON).
+func ParseCron(spec string) (*Cron, error) {
+	expr := strings.TrimSpace(spec)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields (minute hour day-of-month month day-of-week)", spec)
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", spec, err)
+		}
+		bits[i] = b
+	}
+
+	// Sunday can be written as 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &Cron{
+		spec:          strings.TrimSpace(spec),
+		minutes:       bits[0],
+		hours:         bits[1],
+		days:          bits[2],
+		months:        bits[3],
+		weekdays:      bits[4],
+		anyDayOfMonth: fields[2] == "*", // "*/2" restricts the days
+		anyWeekday:    fields[4] == "*",
+	}, nil
+}
+
+// parseCronField returns the set of values matched by a field as a bit mask
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepPart, f.name)
+			}
+			step = n
+		}
+
+		lower, upper := f.min, f.max
+		if rangePart != "*" {
+			lowText, highText, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lower, err = cronValue(lowText, f); err != nil {
+				return 0, err
+			}
+			upper = lower
+			if isRange {
+				if upper, err = cronValue(highText, f); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				upper = f.max // "5/15" means from 5 to the end every 15
+			}
+			if lower > upper {
+				return 0, fmt.Errorf("invalid range %q in %s", rangePart, f.name)
+			}
+		}
+
+		for v := lower; v <= upper; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// cronValue parses a number or name within the bounds of a field
+func cronValue(text string, f cronField) (int, error) {
+	if v, ok := f.names[strings.ToUpper(text)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q (allowed: %d-%d)", f.name, text, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String returns the expression as it was given
+func (c *Cron) String() string {
+	return c.spec
+}
+
+// Next returns the first time after t, truncated to the minute, that
+// matches the expression, or the zero time when none does within five years
+// (e.g. "0 0 30 2 *")
+func (c *Cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay applies the cron rule for days: when both the day of month and
+// the day of week are restricted, a day matching either one matches
+func (c *Cron) matchesDay(t time.Time) bool {
+	dayOfMonth := c.days&(1<<uint(t.Day())) != 0
+	weekday := c.weekdays&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.anyDayOfMonth && c.anyWeekday:
+		return true
+	case c.anyDayOfMonth:
+		return weekday
+	case c.anyWeekday:
+		return dayOfMonth
+	}
+	return dayOfMonth || weekday
+}
@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	// Friday 2026-01-02 10:30
+	from := time.Date(2026, 1, 2, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 2, 10, 31, 0, 0, time.UTC)},
+		{"0 6 * * *", time.Date(2026, 1, 3, 6, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 2, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"30 8 15 * 0", time.Date(2026, 1, 4, 8, 30, 0, 0, time.UTC)}, // day of month OR Sunday
+		{"0 0 * * 7", time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{"5,35 10-11 * JAN *", time.Date(2026, 1, 2, 10, 35, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 */2 * *", time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 12 */2 * *", time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC)}, // odd days only
+		{"0 0 * * */2", time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * */2", time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC)}, // Sun, Tue, Thu and Sat only
+		{"0 12 */2 * */2", time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			cron, err := ParseCron(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cron.Next(from))
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "@often"} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseCron(spec)
+			assert.Error(t, err)
+		})
+	}
+}
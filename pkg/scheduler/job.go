@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"gopkg.in/yaml.v3"
+)
+
+// Job is a saved query whose result is exported to a file. Jobs are read
+// from YAML files:
+//
+//	sources: [sales.csv, https://example.com/customers.json]
+//	query: SELECT region, SUM(amount) AS total FROM sales GROUP BY region
+//	export: reports/sales.parquet
+//	notify:
+//	  webhook: https://hooks.example.com/dataql
+type Job struct {
+	Sources   []string `yaml:"sources"`
+	Delimiter string   `yaml:"delimiter,omitempty"`
+	Query     string   `yaml:"query"`
+	Export    string   `yaml:"export"`
+	Type      string   `yaml:"type,omitempty"` // Export format (default: taken from the export extension)
+	Notify    Notify   `yaml:"notify,omitempty"`
+}
+
+// Notify configures where failures of a job are reported
+type Notify struct {
+	Webhook string `yaml:"webhook,omitempty"` // URL receiving a POST with the failed run as JSON
+}
+
+// LoadJob reads and validates the job file at path. Relative local sources
+// and the export path are resolved against the directory of the file, so the
+// job runs the same whatever the working directory of the scheduler.
+func LoadJob(path string) (*Job, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job file: %w", err)
+	}
+
+	var job Job
+	if err := yaml.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse job file %s: %w", path, err)
+	}
+	if err := job.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid job file %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for i, source := range job.Sources {
+		job.Sources[i] = resolvePath(dir, source)
+	}
+	job.Export = resolvePath(dir, job.Export)
+
+	return &job, nil
+}
+
+// Validate checks that the job has sources, a query and an export path
+func (j *Job) Validate() error {
+	switch {
+	case len(j.Sources) == 0:
+		return fmt.Errorf("at least one source is required")
+	case strings.TrimSpace(j.Query) == "":
+		return fmt.Errorf("query is required")
+	case j.Export == "":
+		return fmt.Errorf("export path is required")
+	}
+	return nil
+}
+
+// Run imports the sources of the job, runs its query and exports the result
+func (j *Job) Run(ctx context.Context) error {
+	db, err := dataql.OpenWithOptions(dataql.Options{Delimiter: j.Delimiter}, j.Sources...)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if dir := filepath.Dir(j.Export); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create export directory: %w", err)
+		}
+	}
+	_, err = db.Export(ctx, j.Query, j.Export, j.Type)
+	return err
+}
+
+// resolvePath makes a relative local path relative to dir, leaving URLs,
+// stdin and absolute paths untouched
+func resolvePath(dir, path string) string {
+	if path == "-" || path == "" || filepath.IsAbs(path) || strings.Contains(path, "://") {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,170 @@
+// Package scheduler runs saved query and export jobs on cron schedules and
+// records the outcome of every run.
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/metrics"
+)
+
+// notifyTimeout bounds how long a failure webhook may take to answer
+const notifyTimeout = 10 * time.Second
+
+// Scheduler executes the schedules of a store when their cron expressions
+// match. The schedules file is read again every minute, so schedules added
+// or removed while the scheduler runs take effect without a restart.
+type Scheduler struct {
+	store  *Store
+	log    io.Writer
+	client *http.Client
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+// New creates a scheduler for the schedules of store, logging runs to log
+func New(store *Store, log io.Writer) *Scheduler {
+	return &Scheduler{
+		store:   store,
+		log:     log,
+		client:  &http.Client{Timeout: notifyTimeout},
+		running: make(map[string]bool),
+	}
+}
+
+// Run executes due schedules until ctx is cancelled, then waits for the
+// runs in progress to finish
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		timer := time.NewTimer(next.Sub(now))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		schedules, err := s.store.List()
+		if err != nil {
+			fmt.Fprintf(s.log, "%s error: %v\n", next.Format(time.RFC3339), err)
+			continue
+		}
+
+		for _, schedule := range Due(schedules, next) {
+			if !s.start(schedule.Name) {
+				fmt.Fprintf(s.log, "%s skipped %s: previous run still in progress\n", next.Format(time.RFC3339), schedule.Name)
+				continue
+			}
+
+			wg.Add(1)
+			go func(schedule Schedule) {
+				defer wg.Done()
+				defer s.finish(schedule.Name)
+				s.Execute(ctx, schedule)
+			}(schedule)
+		}
+	}
+}
+
+// Due returns the schedules whose cron expression matches t. Schedules with
+// an invalid expression are never due.
+func Due(schedules []Schedule, t time.Time) []Schedule {
+	t = t.Truncate(time.Minute)
+
+	var due []Schedule
+	for _, schedule := range schedules {
+		cron, err := ParseCron(schedule.Cron)
+		if err != nil {
+			continue
+		}
+		if cron.Next(t.Add(-time.Minute)).Equal(t) {
+			due = append(due, schedule)
+		}
+	}
+	return due
+}
+
+// Execute runs the job of a schedule once, records the run in the history
+// and notifies the webhook of the job when it fails
+func (s *Scheduler) Execute(ctx context.Context, schedule Schedule) Run {
+	run := Run{Schedule: schedule.Name, Job: schedule.Job, Started: time.Now().UTC(), Status: StatusSuccess}
+
+	job, err := LoadJob(schedule.Job)
+	if err == nil {
+		err = job.Run(ctx)
+	}
+	run.DurationMs = float64(time.Since(run.Started).Microseconds()) / 1000
+	if err != nil {
+		run.Status = StatusFailed
+		run.Error = err.Error()
+	}
+	metrics.ObserveScheduleRun(run.Schedule, time.Since(run.Started), err)
+
+	if run.Status == StatusFailed {
+		fmt.Fprintf(s.log, "%s %s failed after %.0fms: %s\n", run.Started.Format(time.RFC3339), run.Schedule, run.DurationMs, run.Error)
+	} else {
+		fmt.Fprintf(s.log, "%s %s succeeded in %.0fms\n", run.Started.Format(time.RFC3339), run.Schedule, run.DurationMs)
+	}
+
+	if err := s.store.Record(run); err != nil {
+		fmt.Fprintf(s.log, "%s %s: %v\n", run.Started.Format(time.RFC3339), run.Schedule, err)
+	}
+
+	if run.Status == StatusFailed && job != nil && job.Notify.Webhook != "" {
+		if err := s.notify(job.Notify.Webhook, run); err != nil {
+			fmt.Fprintf(s.log, "%s %s: failed to notify %s: %v\n", run.Started.Format(time.RFC3339), run.Schedule, job.Notify.Webhook, err)
+		}
+	}
+	return run
+}
+
+// notify posts a failed run as JSON to a webhook
+func (s *Scheduler) notify(webhook string, run Run) error {
+	body, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// start marks a schedule as running, reporting false when it already is
+func (s *Scheduler) start(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running[name] {
+		return false
+	}
+	s.running[name] = true
+	return true
+}
+
+func (s *Scheduler) finish(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, name)
+}
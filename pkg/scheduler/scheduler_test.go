@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeJob writes a job file next to a users CSV and returns its path
+func writeJob(t *testing.T, dir, query, webhook string) string {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "users.csv"), []byte("id,name\n1,Alice\n2,Bob\n"), 0644))
+
+	job := "sources: [users.csv]\nquery: " + query + "\nexport: out/users.csv\n"
+	if webhook != "" {
+		job += "notify:\n  webhook: " + webhook + "\n"
+	}
+	path := filepath.Join(dir, "users-job.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(job), 0644))
+	return path
+}
+
+func TestStore_AddListRemove(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(filepath.Join(dir, "state"))
+	require.NoError(t, err)
+
+	jobPath := writeJob(t, dir, "SELECT * FROM users", "")
+
+	added, err := store.Add(Schedule{Cron: "0 6 * * *", Job: jobPath})
+	require.NoError(t, err)
+	assert.Equal(t, "users-job", added.Name)
+
+	_, err = store.Add(Schedule{Cron: "0 6 * * *", Job: jobPath})
+	assert.ErrorContains(t, err, "already exists")
+
+	_, err = store.Add(Schedule{Name: "bad", Cron: "0 25 * * *", Job: jobPath})
+	assert.Error(t, err)
+
+	_, err = store.Add(Schedule{Name: "missing", Cron: "@daily", Job: filepath.Join(dir, "missing.yaml")})
+	assert.Error(t, err)
+
+	schedules, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	assert.Equal(t, jobPath, schedules[0].Job)
+
+	require.NoError(t, store.Remove("users-job"))
+	assert.Error(t, store.Remove("users-job"))
+
+	schedules, err = store.List()
+	require.NoError(t, err)
+	assert.Empty(t, schedules)
+}
+
+func TestDue(t *testing.T) {
+	schedules := []Schedule{
+		{Name: "every-minute", Cron: "* * * * *"},
+		{Name: "six", Cron: "0 6 * * *"},
+		{Name: "invalid", Cron: "nope"},
+	}
+
+	at := time.Date(2026, 3, 1, 6, 0, 30, 0, time.Local)
+	due := Due(schedules, at)
+	require.Len(t, due, 2)
+	assert.Equal(t, "every-minute", due[0].Name)
+	assert.Equal(t, "six", due[1].Name)
+
+	assert.Len(t, Due(schedules, at.Add(time.Minute)), 1)
+}
+
+func TestExecute(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+
+	jobPath := writeJob(t, dir, "SELECT name FROM users ORDER BY id", "")
+	s := New(store, io.Discard)
+
+	run := s.Execute(context.Background(), Schedule{Name: "users", Job: jobPath})
+	assert.Equal(t, StatusSuccess, run.Status, run.Error)
+
+	data, err := os.ReadFile(filepath.Join(dir, "out", "users.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "name\nAlice\nBob\n", string(data))
+
+	runs, err := store.History("users", 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, StatusSuccess, runs[0].Status)
+}
+
+func TestExecute_FailureNotifiesWebhook(t *testing.T) {
+	notified := make(chan Run, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var run Run
+		_ = json.NewDecoder(r.Body).Decode(&run)
+		notified <- run
+	}))
+	defer webhook.Close()
+
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+
+	jobPath := writeJob(t, dir, "SELECT * FROM missing", webhook.URL)
+	s := New(store, io.Discard)
+
+	run := s.Execute(context.Background(), Schedule{Name: "users", Job: jobPath})
+	assert.Equal(t, StatusFailed, run.Status)
+	assert.NotEmpty(t, run.Error)
+
+	select {
+	case sent := <-notified:
+		assert.Equal(t, "users", sent.Schedule)
+		assert.Equal(t, StatusFailed, sent.Status)
+	default:
+		t.Fatal("webhook was not notified")
+	}
+
+	runs, err := store.History("", 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, StatusFailed, runs[0].Status)
+}
@@ -0,0 +1,240 @@
+package scheduler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvDir overrides the directory holding the schedules and run history
+	EnvDir = "DATAQL_SCHEDULE_DIR"
+
+	schedulesFileName = "schedules.yaml"
+	historyFileName   = "schedule-history.jsonl"
+)
+
+// Run statuses recorded in the history
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
+// Schedule runs the job file Job whenever Cron matches
+type Schedule struct {
+	Name    string    `yaml:"name"`
+	Cron    string    `yaml:"cron"`
+	Job     string    `yaml:"job"` // Absolute path of the job file
+	Created time.Time `yaml:"created"`
+}
+
+// Run is one execution of a schedule, written to the history as a JSON line
+type Run struct {
+	Schedule   string    `json:"schedule"`
+	Job        string    `json:"job"`
+	Started    time.Time `json:"started"`
+	DurationMs float64   `json:"duration_ms"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Store keeps the schedules and their run history in a directory
+type Store struct {
+	dir string
+	mu  sync.Mutex // Serializes history appends of concurrent runs
+}
+
+// DefaultDir returns $DATAQL_SCHEDULE_DIR or ~/.dataql
+func DefaultDir() (string, error) {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".dataql"), nil
+}
+
+// NewStore creates a store in dir, or in DefaultDir when dir is empty
+func NewStore(dir string) (*Store, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create schedule directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Dir returns the directory of the store
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// List returns the schedules sorted by name. A missing file yields none.
+func (s *Store) List() ([]Schedule, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, schedulesFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+
+	var schedules []Schedule
+	if err := yaml.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", schedulesFileName, err)
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].Name < schedules[j].Name })
+	return schedules, nil
+}
+
+// Add validates and saves a schedule. The job file is checked now so a
+// broken job is reported when it is scheduled rather than at its first run.
+func (s *Store) Add(schedule Schedule) (Schedule, error) {
+	if _, err := ParseCron(schedule.Cron); err != nil {
+		return Schedule{}, err
+	}
+
+	jobPath, err := filepath.Abs(schedule.Job)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("failed to resolve job path: %w", err)
+	}
+	if _, err := LoadJob(jobPath); err != nil {
+		return Schedule{}, err
+	}
+	schedule.Job = jobPath
+
+	if schedule.Name == "" {
+		base := filepath.Base(jobPath)
+		schedule.Name = base[:len(base)-len(filepath.Ext(base))]
+	}
+	if schedule.Created.IsZero() {
+		schedule.Created = time.Now().UTC()
+	}
+
+	schedules, err := s.List()
+	if err != nil {
+		return Schedule{}, err
+	}
+	for _, existing := range schedules {
+		if existing.Name == schedule.Name {
+			return Schedule{}, fmt.Errorf("schedule %q already exists", schedule.Name)
+		}
+	}
+
+	return schedule, s.save(append(schedules, schedule))
+}
+
+// Remove deletes the schedule with the given name
+func (s *Store) Remove(name string) error {
+	schedules, err := s.List()
+	if err != nil {
+		return err
+	}
+
+	for i, schedule := range schedules {
+		if schedule.Name == name {
+			return s.save(append(schedules[:i], schedules[i+1:]...))
+		}
+	}
+	return fmt.Errorf("schedule %q not found", name)
+}
+
+// save replaces the schedules file atomically, so a running scheduler never
+// reads a partially written file
+func (s *Store) save(schedules []Schedule) error {
+	data, err := yaml.Marshal(schedules)
+	if err != nil {
+		return fmt.Errorf("failed to encode schedules: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, schedulesFileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, schedulesFileName)); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	return nil
+}
+
+// Record appends a run to the history
+func (s *Store) Record(run Run) error {
+	line, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode run: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(filepath.Join(s.dir, historyFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open run history: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write run history: %w", err)
+	}
+	return nil
+}
+
+// History returns the most recent runs first, limited to the last limit runs
+// (0: all) of the named schedule, or of every schedule when name is empty
+func (s *Store) History(name string, limit int) ([]Run, error) {
+	file, err := os.Open(filepath.Join(s.dir, historyFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open run history: %w", err)
+	}
+	defer file.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var run Run
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			continue // Skip lines truncated by a crash
+		}
+		if name == "" || run.Schedule == name {
+			runs = append(runs, run)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read run history: %w", err)
+	}
+
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
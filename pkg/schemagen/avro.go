@@ -0,0 +1,100 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// invalidAvroName matches the characters Avro does not accept in names
+var invalidAvroName = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// avroSchema writes a record schema per table; several tables are written
+// as a JSON array of records
+func avroSchema(tables []Table) ([]byte, error) {
+	records := make([]any, len(tables))
+	for i, table := range tables {
+		fields := make([]map[string]any, len(table.Columns))
+		for j, column := range table.Columns {
+			fields[j] = avroField(column.Name, ParseType(column.Type), column.Nullable, table.Name)
+		}
+		records[i] = map[string]any{"type": "record", "name": avroName(table.Name), "fields": fields}
+	}
+
+	var doc any = records
+	if len(records) == 1 {
+		doc = records[0]
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// avroField describes a field; nullable fields are a union with null that
+// defaults to null. Names that are not valid Avro names are sanitized and
+// keep the original name as an alias.
+func avroField(name string, t *Type, nullable bool, parent string) map[string]any {
+	field := map[string]any{"name": avroName(name), "type": avroType(t, parent+"_"+name)}
+	if nullable {
+		field["type"] = []any{"null", field["type"]}
+		field["default"] = nil
+	}
+	if field["name"] != name {
+		field["aliases"] = []string{name}
+	}
+	return field
+}
+
+// avroType maps a DuckDB type to an Avro type. recordName names the records
+// generated for STRUCT types.
+func avroType(t *Type, recordName string) any {
+	switch t.Name {
+	case "TINYINT", "SMALLINT", "INTEGER", "UTINYINT", "USMALLINT":
+		return "int"
+	case "BIGINT", "UINTEGER":
+		return "long"
+	case "UBIGINT", "HUGEINT", "UHUGEINT":
+		return map[string]any{"type": "bytes", "logicalType": "decimal", "precision": 39, "scale": 0}
+	case "FLOAT":
+		return "float"
+	case "DOUBLE":
+		return "double"
+	case "DECIMAL":
+		return map[string]any{"type": "bytes", "logicalType": "decimal", "precision": t.Precision, "scale": t.Scale}
+	case "BOOLEAN":
+		return "boolean"
+	case "DATE":
+		return map[string]any{"type": "int", "logicalType": "date"}
+	case "TIMESTAMP":
+		return map[string]any{"type": "long", "logicalType": "local-timestamp-micros"}
+	case "TIMESTAMP WITH TIME ZONE":
+		return map[string]any{"type": "long", "logicalType": "timestamp-micros"}
+	case "TIME":
+		return map[string]any{"type": "long", "logicalType": "time-micros"}
+	case "UUID":
+		return map[string]any{"type": "string", "logicalType": "uuid"}
+	case "BLOB":
+		return "bytes"
+	case typeList:
+		return map[string]any{"type": "array", "items": []any{"null", avroType(t.Elem, recordName+"_item")}}
+	case typeMap:
+		return map[string]any{"type": "map", "values": []any{"null", avroType(t.Elem, recordName+"_value")}}
+	case typeStruct:
+		fields := make([]map[string]any, len(t.Fields))
+		for i, f := range t.Fields {
+			fields[i] = avroField(f.Name, f.Type, true, recordName)
+		}
+		return map[string]any{"type": "record", "name": avroName(recordName), "fields": fields}
+	}
+	return "string"
+}
+
+// avroName turns a column or table name into a valid Avro name
+func avroName(name string) string {
+	name = invalidAvroName.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
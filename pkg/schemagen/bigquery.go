@@ -0,0 +1,92 @@
+package schemagen
+
+import (
+	"encoding/json"
+)
+
+// bigQuerySchema writes the JSON schema file used by `bq mk` and `bq load`.
+// Several tables are written as an object keyed by table name.
+func bigQuerySchema(tables []Table) ([]byte, error) {
+	schemas := make(map[string]any, len(tables))
+	for _, table := range tables {
+		fields := make([]map[string]any, len(table.Columns))
+		for i, column := range table.Columns {
+			fields[i] = bigQueryField(column.Name, ParseType(column.Type), column.Nullable)
+		}
+		schemas[table.Name] = fields
+	}
+
+	var doc any = schemas
+	if len(tables) == 1 {
+		doc = schemas[tables[0].Name]
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// bigQueryField describes a column. Lists become REPEATED fields; lists of
+// lists and maps, which BigQuery cannot represent, become JSON.
+func bigQueryField(name string, t *Type, nullable bool) map[string]any {
+	mode := "REQUIRED"
+	if nullable {
+		mode = "NULLABLE"
+	}
+	if t.Name == typeList && t.Elem.Name != typeList {
+		mode = "REPEATED"
+		t = t.Elem
+	}
+
+	field := map[string]any{"name": name, "mode": mode}
+	switch t.Name {
+	case typeStruct:
+		fields := make([]map[string]any, len(t.Fields))
+		for i, f := range t.Fields {
+			fields[i] = bigQueryField(f.Name, f.Type, true)
+		}
+		field["type"] = "RECORD"
+		field["fields"] = fields
+	default:
+		field["type"] = bigQueryType(t)
+	}
+	return field
+}
+
+// bigQueryType maps a scalar DuckDB type to a BigQuery type
+func bigQueryType(t *Type) string {
+	if t.isInteger() {
+		return "INTEGER"
+	}
+
+	switch t.Name {
+	case "UBIGINT", "HUGEINT", "UHUGEINT":
+		return "BIGNUMERIC"
+	case "FLOAT", "DOUBLE":
+		return "FLOAT"
+	case "DECIMAL":
+		// NUMERIC holds 38 digits with up to 9 after the point
+		if t.Precision-t.Scale > 29 || t.Scale > 9 {
+			return "BIGNUMERIC"
+		}
+		return "NUMERIC"
+	case "BOOLEAN":
+		return "BOOLEAN"
+	case "DATE":
+		return "DATE"
+	case "TIMESTAMP":
+		return "DATETIME"
+	case "TIMESTAMP WITH TIME ZONE":
+		return "TIMESTAMP"
+	case "TIME":
+		return "TIME"
+	case "INTERVAL":
+		return "INTERVAL"
+	case "BLOB":
+		return "BYTES"
+	case "JSON", typeList, typeMap:
+		return "JSON"
+	}
+	return "STRING"
+}
@@ -0,0 +1,98 @@
+package schemagen
+
+import (
+	"encoding/json"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema describes the rows of the tables. A single table becomes the
+// root schema; several tables are listed under $defs.
+func jsonSchema(tables []Table) ([]byte, error) {
+	var doc map[string]any
+	if len(tables) == 1 {
+		doc = tableJSONSchema(tables[0])
+	} else {
+		defs := make(map[string]any, len(tables))
+		for _, table := range tables {
+			defs[table.Name] = tableJSONSchema(table)
+		}
+		doc = map[string]any{"$defs": defs}
+	}
+	doc["$schema"] = jsonSchemaDraft
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// tableJSONSchema describes one row of a table as an object
+func tableJSONSchema(table Table) map[string]any {
+	properties := make(map[string]any, len(table.Columns))
+	required := []string{}
+	for _, column := range table.Columns {
+		properties[column.Name] = nullableJSONType(jsonType(ParseType(column.Type)), column.Nullable)
+		if !column.Nullable {
+			required = append(required, column.Name)
+		}
+	}
+
+	return map[string]any{
+		"title":                table.Name,
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// jsonType maps a DuckDB type to a JSON Schema
+func jsonType(t *Type) map[string]any {
+	if t.isInteger() || t.isWideInteger() {
+		return map[string]any{"type": "integer"}
+	}
+
+	switch t.Name {
+	case "FLOAT", "DOUBLE", "DECIMAL":
+		return map[string]any{"type": "number"}
+	case "BOOLEAN":
+		return map[string]any{"type": "boolean"}
+	case "DATE":
+		return map[string]any{"type": "string", "format": "date"}
+	case "TIMESTAMP", "TIMESTAMP WITH TIME ZONE":
+		return map[string]any{"type": "string", "format": "date-time"}
+	case "TIME", "TIME WITH TIME ZONE":
+		return map[string]any{"type": "string", "format": "time"}
+	case "INTERVAL":
+		return map[string]any{"type": "string", "format": "duration"}
+	case "UUID":
+		return map[string]any{"type": "string", "format": "uuid"}
+	case "BLOB":
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case "JSON":
+		return map[string]any{}
+	case typeList:
+		return map[string]any{"type": "array", "items": nullableJSONType(jsonType(t.Elem), true)}
+	case typeMap:
+		return map[string]any{"type": "object", "additionalProperties": nullableJSONType(jsonType(t.Elem), true)}
+	case typeStruct:
+		properties := make(map[string]any, len(t.Fields))
+		for _, field := range t.Fields {
+			properties[field.Name] = nullableJSONType(jsonType(field.Type), true)
+		}
+		return map[string]any{"type": "object", "properties": properties}
+	}
+	return map[string]any{"type": "string"}
+}
+
+// nullableJSONType allows null in addition to the type of schema
+func nullableJSONType(schema map[string]any, nullable bool) map[string]any {
+	typ, ok := schema["type"].(string)
+	if !nullable || !ok {
+		return schema
+	}
+	schema["type"] = []string{typ, "null"}
+	return schema
+}
@@ -0,0 +1,122 @@
+// Package schemagen infers the schema of imported data and writes it as a
+// JSON Schema, SQL DDL, an Avro schema or a BigQuery table schema.
+package schemagen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// Supported output formats
+const (
+	FormatJSONSchema = "jsonschema"
+	FormatSQL        = "sql"
+	FormatAvro       = "avro"
+	FormatBigQuery   = "bigquery"
+)
+
+// Table is the inferred schema of a table
+type Table struct {
+	Name    string
+	Columns []Column
+}
+
+// Column is a column with its DuckDB type and whether it holds nulls
+type Column struct {
+	Name     string
+	Type     string // DuckDB type, e.g. "BIGINT" or "STRUCT(a VARCHAR)"
+	Nullable bool   // The column has null values (or the table is empty)
+}
+
+var generators = map[string]func([]Table) ([]byte, error){
+	FormatJSONSchema: jsonSchema,
+	FormatSQL:        sqlDDL,
+	FormatAvro:       avroSchema,
+	FormatBigQuery:   bigQuerySchema,
+}
+
+// Formats returns the supported output formats
+func Formats() []string {
+	formats := make([]string, 0, len(generators))
+	for format := range generators {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Infer returns the schema of every table of db. A column is nullable when
+// it has null values in the imported rows, so schemas inferred from a sample
+// (e.g. the first lines of a file) may mark columns as required too eagerly.
+func Infer(ctx context.Context, db *dataql.DB) ([]Table, error) {
+	schemas, err := db.Tables()
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]Table, 0, len(schemas))
+	for _, schema := range schemas {
+		table := Table{Name: schema.Name}
+		nulls, err := countNulls(ctx, db, schema)
+		if err != nil {
+			return nil, err
+		}
+		for i, column := range schema.Columns {
+			table.Columns = append(table.Columns, Column{Name: column.Name, Type: column.Type, Nullable: nulls[i]})
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// countNulls reports, for each column of a table, whether it has nulls.
+// Every column of an empty table is nullable.
+func countNulls(ctx context.Context, db *dataql.DB, schema dataql.Table) ([]bool, error) {
+	selects := []string{"COUNT(*)"}
+	for _, column := range schema.Columns {
+		selects = append(selects, "COUNT(*) - COUNT("+storage.QuoteIdentifier(column.Name)+")")
+	}
+
+	rows, err := db.Query(ctx, "SELECT "+strings.Join(selects, ", ")+" FROM "+storage.QuoteIdentifier(schema.Name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count nulls of %s: %w", schema.Name, err)
+	}
+	defer rows.Close()
+
+	counts := make([]int64, len(selects))
+	if rows.Next() {
+		dest := make([]any, len(counts))
+		for i := range counts {
+			dest[i] = &counts[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to count nulls of %s: %w", schema.Name, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	nullable := make([]bool, len(schema.Columns))
+	for i := range nullable {
+		nullable[i] = counts[0] == 0 || counts[i+1] > 0
+	}
+	return nullable, nil
+}
+
+// Generate writes the tables in the given format
+func Generate(format string, tables []Table) ([]byte, error) {
+	generator, ok := generators[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported schema format %q (supported: %s)", format, strings.Join(Formats(), ", "))
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no tables to describe")
+	}
+	return generator(tables)
+}
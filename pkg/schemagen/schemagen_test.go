@@ -0,0 +1,134 @@
+package schemagen
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/dataql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseType(t *testing.T) {
+	typ := ParseType(`STRUCT(id BIGINT, "first name" VARCHAR, tags VARCHAR[], price DECIMAL(10,2), attrs MAP(VARCHAR, INTEGER))`)
+	require.Equal(t, typeStruct, typ.Name)
+	require.Len(t, typ.Fields, 5)
+	assert.Equal(t, "first name", typ.Fields[1].Name)
+	assert.Equal(t, typeList, typ.Fields[2].Type.Name)
+	assert.Equal(t, "VARCHAR", typ.Fields[2].Type.Elem.Name)
+	assert.Equal(t, &Type{Name: "DECIMAL", Precision: 10, Scale: 2}, typ.Fields[3].Type)
+	assert.Equal(t, "VARCHAR", typ.Fields[4].Type.Key.Name)
+	assert.Equal(t, "INTEGER", typ.Fields[4].Type.Elem.Name)
+
+	assert.Equal(t, "TIMESTAMP WITH TIME ZONE", ParseType("timestamptz").Name)
+	assert.Equal(t, typeList, ParseType("STRUCT(a INTEGER)[3]").Name)
+	assert.Equal(t, typeStruct, ParseType("STRUCT(a INTEGER)[3]").Elem.Name)
+}
+
+var testTables = []Table{{
+	Name: "orders",
+	Columns: []Column{
+		{Name: "id", Type: "BIGINT"},
+		{Name: "amount", Type: "DECIMAL(12,2)", Nullable: true},
+		{Name: "created at", Type: "TIMESTAMP"},
+		{Name: "tags", Type: "VARCHAR[]", Nullable: true},
+		{Name: "customer", Type: "STRUCT(name VARCHAR, age INTEGER)"},
+	},
+}}
+
+func TestGenerate_SQL(t *testing.T) {
+	out, err := Generate(FormatSQL, testTables)
+	require.NoError(t, err)
+	assert.Equal(t, `CREATE TABLE "orders" (
+  "id" BIGINT NOT NULL,
+  "amount" DECIMAL(12,2),
+  "created at" TIMESTAMP NOT NULL,
+  "tags" VARCHAR[],
+  "customer" STRUCT(name VARCHAR, age INTEGER) NOT NULL
+);
+`, string(out))
+}
+
+func TestGenerate_JSONSchema(t *testing.T) {
+	out, err := Generate(FormatJSONSchema, testTables)
+	require.NoError(t, err)
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(out, &schema))
+	assert.Equal(t, jsonSchemaDraft, schema["$schema"])
+	assert.Equal(t, []any{"id", "created at", "customer"}, schema["required"])
+
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "integer"}, properties["id"])
+	assert.Equal(t, map[string]any{"type": []any{"number", "null"}}, properties["amount"])
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, properties["created at"])
+	assert.Equal(t, "array", properties["tags"].(map[string]any)["type"].([]any)[0])
+	assert.Contains(t, properties["customer"].(map[string]any)["properties"], "age")
+
+	out, err = Generate(FormatJSONSchema, append(testTables, Table{Name: "empty", Columns: []Column{{Name: "x", Type: "VARCHAR", Nullable: true}}}))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(out, &schema))
+	assert.Contains(t, schema["$defs"], "orders")
+	assert.Contains(t, schema["$defs"], "empty")
+}
+
+func TestGenerate_Avro(t *testing.T) {
+	out, err := Generate(FormatAvro, testTables)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "type": "record",
+  "name": "orders",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "amount", "type": ["null", {"type": "bytes", "logicalType": "decimal", "precision": 12, "scale": 2}], "default": null},
+    {"name": "created_at", "aliases": ["created at"], "type": {"type": "long", "logicalType": "local-timestamp-micros"}},
+    {"name": "tags", "type": ["null", {"type": "array", "items": ["null", "string"]}], "default": null},
+    {"name": "customer", "type": {"type": "record", "name": "orders_customer", "fields": [
+      {"name": "name", "type": ["null", "string"], "default": null},
+      {"name": "age", "type": ["null", "int"], "default": null}
+    ]}}
+  ]
+}`, string(out))
+}
+
+func TestGenerate_BigQuery(t *testing.T) {
+	out, err := Generate(FormatBigQuery, testTables)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+  {"name": "id", "type": "INTEGER", "mode": "REQUIRED"},
+  {"name": "amount", "type": "NUMERIC", "mode": "NULLABLE"},
+  {"name": "created at", "type": "DATETIME", "mode": "REQUIRED"},
+  {"name": "tags", "type": "STRING", "mode": "REPEATED"},
+  {"name": "customer", "type": "RECORD", "mode": "REQUIRED", "fields": [
+    {"name": "name", "type": "STRING", "mode": "NULLABLE"},
+    {"name": "age", "type": "INTEGER", "mode": "NULLABLE"}
+  ]}
+]`, string(out))
+}
+
+func TestGenerate_UnsupportedFormat(t *testing.T) {
+	_, err := Generate("protobuf", testTables)
+	assert.ErrorContains(t, err, "unsupported schema format")
+}
+
+func TestInfer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.csv")
+	require.NoError(t, os.WriteFile(path, []byte("id,name,score\n1,Ann,\n2,Bob,3.5\n"), 0644))
+
+	db, err := dataql.Open(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	tables, err := Infer(context.Background(), db)
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+	assert.Equal(t, "people", tables[0].Name)
+	assert.Equal(t, []Column{
+		{Name: "id", Type: "BIGINT"},
+		{Name: "name", Type: "VARCHAR"},
+		{Name: "score", Type: "DOUBLE", Nullable: true},
+	}, tables[0].Columns)
+}
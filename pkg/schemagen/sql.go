@@ -0,0 +1,31 @@
+package schemagen
+
+import (
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+// sqlDDL writes a CREATE TABLE statement per table with the DuckDB column
+// types and NOT NULL on columns without nulls
+func sqlDDL(tables []Table) ([]byte, error) {
+	var b strings.Builder
+	for i, table := range tables {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("CREATE TABLE " + storage.QuoteIdentifier(table.Name) + " (\n")
+		for j, column := range table.Columns {
+			b.WriteString("  " + storage.QuoteIdentifier(column.Name) + " " + column.Type)
+			if !column.Nullable {
+				b.WriteString(" NOT NULL")
+			}
+			if j < len(table.Columns)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(");\n")
+	}
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,193 @@
+package schemagen
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Type is a parsed DuckDB column type
+type Type struct {
+	Name      string  // Upper-case base name: BIGINT, DECIMAL, LIST, STRUCT, MAP, ...
+	Precision int     // DECIMAL precision
+	Scale     int     // DECIMAL scale
+	Elem      *Type   // Element of a LIST, value of a MAP
+	Key       *Type   // Key of a MAP
+	Fields    []Field // Fields of a STRUCT
+}
+
+// Field is a named member of a STRUCT
+type Field struct {
+	Name string
+	Type *Type
+}
+
+// Type names of the composite types
+const (
+	typeList   = "LIST"
+	typeStruct = "STRUCT"
+	typeMap    = "MAP"
+)
+
+// ParseType parses a type as reported by DuckDB, such as "DECIMAL(18,3)",
+// "VARCHAR[]" or "STRUCT(id BIGINT, tags VARCHAR[])"
+func ParseType(text string) *Type {
+	text = strings.TrimSpace(text)
+
+	// LIST and ARRAY types: "T[]" or "T[3]"
+	if strings.HasSuffix(text, "]") {
+		if open := topLevelIndex(text, '['); open > 0 {
+			return &Type{Name: typeList, Elem: ParseType(text[:open])}
+		}
+	}
+
+	open := strings.IndexByte(text, '(')
+	if open < 0 || !strings.HasSuffix(text, ")") {
+		return &Type{Name: normalizeName(text)}
+	}
+
+	name := normalizeName(text[:open])
+	args := splitTopLevel(text[open+1 : len(text)-1])
+	t := &Type{Name: name}
+
+	switch name {
+	case "DECIMAL":
+		if len(args) > 0 {
+			t.Precision, _ = strconv.Atoi(strings.TrimSpace(args[0]))
+		}
+		if len(args) > 1 {
+			t.Scale, _ = strconv.Atoi(strings.TrimSpace(args[1]))
+		}
+	case typeStruct:
+		for _, arg := range args {
+			fieldName, fieldType := splitField(arg)
+			t.Fields = append(t.Fields, Field{Name: fieldName, Type: ParseType(fieldType)})
+		}
+	case typeMap:
+		if len(args) == 2 {
+			t.Key = ParseType(args[0])
+			t.Elem = ParseType(args[1])
+		}
+	case "VARCHAR", "ENUM":
+		t.Name = "VARCHAR" // Length limits and enum members are not kept
+	}
+	return t
+}
+
+// normalizeName maps the aliases DuckDB accepts to a single name
+func normalizeName(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	switch name {
+	case "INT", "INT4", "SIGNED":
+		return "INTEGER"
+	case "INT8", "LONG":
+		return "BIGINT"
+	case "INT2", "SHORT":
+		return "SMALLINT"
+	case "INT1":
+		return "TINYINT"
+	case "FLOAT4", "REAL":
+		return "FLOAT"
+	case "FLOAT8":
+		return "DOUBLE"
+	case "NUMERIC":
+		return "DECIMAL"
+	case "TEXT", "STRING", "CHAR", "BPCHAR":
+		return "VARCHAR"
+	case "BOOL", "LOGICAL":
+		return "BOOLEAN"
+	case "TIMESTAMPTZ":
+		return "TIMESTAMP WITH TIME ZONE"
+	case "DATETIME", "TIMESTAMP_S", "TIMESTAMP_MS", "TIMESTAMP_NS", "TIMESTAMP_US":
+		return "TIMESTAMP"
+	case "TIMETZ":
+		return "TIME WITH TIME ZONE"
+	case "BYTEA", "BINARY", "VARBINARY":
+		return "BLOB"
+	}
+	return name
+}
+
+// splitField splits a STRUCT member into its (possibly quoted) name and type
+func splitField(member string) (string, string) {
+	member = strings.TrimSpace(member)
+	if strings.HasPrefix(member, `"`) {
+		for i := 1; i < len(member); i++ {
+			if member[i] != '"' {
+				continue
+			}
+			if i+1 < len(member) && member[i+1] == '"' {
+				i++ // Escaped quote
+				continue
+			}
+			return strings.ReplaceAll(member[1:i], `""`, `"`), member[i+1:]
+		}
+	}
+	name, typ, _ := strings.Cut(member, " ")
+	return name, typ
+}
+
+// splitTopLevel splits s on commas outside parentheses, brackets and quotes
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts
+}
+
+// topLevelIndex returns the index of the last c outside parentheses and
+// quotes, or -1
+func topLevelIndex(s string, c byte) int {
+	depth := 0
+	quoted := false
+	index := -1
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '"':
+			quoted = !quoted
+		case quoted:
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == c && depth == 0:
+			index = i
+		}
+	}
+	return index
+}
+
+// isInteger reports whether t is an integer type that fits in 64 bits
+// signed (UBIGINT, HUGEINT and UHUGEINT do not)
+func (t *Type) isInteger() bool {
+	switch t.Name {
+	case "TINYINT", "SMALLINT", "INTEGER", "BIGINT", "UTINYINT", "USMALLINT", "UINTEGER":
+		return true
+	}
+	return false
+}
+
+// isWideInteger reports whether t is an integer type wider than 64 bits signed
+func (t *Type) isWideInteger() bool {
+	switch t.Name {
+	case "UBIGINT", "HUGEINT", "UHUGEINT":
+		return true
+	}
+	return false
+}
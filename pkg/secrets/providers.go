@@ -0,0 +1,140 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+
+	"github.com/adrianolaselva/dataql/pkg/keyring"
+)
+
+// vaultTimeout bounds a request to the Vault server
+const vaultTimeout = 30 * time.Second
+
+// awsSecret reads a secret from AWS Secrets Manager, with the credentials
+// and region of the default AWS configuration. AWS_ENDPOINT_URL_SECRETS_MANAGER
+// or AWS_ENDPOINT_URL point it to LocalStack.
+func awsSecret(ctx context.Context, name string) (string, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	endpointURL := os.Getenv("AWS_ENDPOINT_URL_SECRETS_MANAGER")
+	if endpointURL == "" {
+		endpointURL = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	client := secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+		if endpointURL != "" {
+			o.BaseEndpoint = aws.String(endpointURL)
+		}
+	})
+
+	output, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+	if err != nil {
+		return "", err
+	}
+	if output.SecretString != nil {
+		return *output.SecretString, nil
+	}
+	return string(output.SecretBinary), nil
+}
+
+// vaultSecret reads a secret from HashiCorp Vault at $VAULT_ADDR, with the
+// token of $VAULT_TOKEN or ~/.vault-token. The secret is the JSON object of
+// its fields, for both KV version 1 and 2 engines.
+func vaultSecret(ctx context.Context, path string) (string, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	token, err := vaultToken()
+	if err != nil {
+		return "", err
+	}
+
+	endpoint, err := url.JoinPath(addr, "v1", strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return "", fmt.Errorf("invalid VAULT_ADDR: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, vaultTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach Vault: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return "", fmt.Errorf("vault returned %s for %s", resp.Status, path)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode Vault response: %w", err)
+	}
+	// KV version 2 nests the fields under data.data, next to data.metadata
+	fields := body.Data
+	if nested, ok := body.Data["data"]; ok && len(body.Data) == 2 && body.Data["metadata"] != nil {
+		if err := json.Unmarshal(nested, &fields); err != nil {
+			return "", fmt.Errorf("failed to decode Vault response: %w", err)
+		}
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode Vault secret: %w", err)
+	}
+	return string(data), nil
+}
+
+// vaultToken returns $VAULT_TOKEN, or the token saved by vault login
+func vaultToken() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("VAULT_TOKEN is not set")
+	}
+	data, err := os.ReadFile(filepath.Join(homeDir, ".vault-token"))
+	if err != nil {
+		return "", fmt.Errorf("VAULT_TOKEN is not set and ~/.vault-token cannot be read")
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// keyringSecret reads a secret stored in the OS keyring under the dataql service
+func keyringSecret(_ context.Context, account string) (string, error) {
+	return keyring.Get(account)
+}
+
+// envSecret reads a secret from an environment variable
+func envSecret(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
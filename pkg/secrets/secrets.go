@@ -0,0 +1,107 @@
+// Package secrets resolves placeholders such as {{aws-sm:prod/pg-password}}
+// or {{vault:secret/data/db#pass}} into secrets fetched from a secrets
+// manager, so credentials are not written in connection URLs.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Provider fetches the secret stored under a name
+type Provider func(ctx context.Context, name string) (string, error)
+
+// providers maps the prefix of a placeholder to the provider it reads from
+var providers = map[string]Provider{
+	"aws-sm":  awsSecret,
+	"vault":   vaultSecret,
+	"keyring": keyringSecret,
+	"env":     envSecret,
+}
+
+// placeholderPattern matches {{provider:name}} and {{provider:name#field}}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z][a-z0-9-]*):([^{}]+?)\s*\}\}`)
+
+// HasPlaceholders reports whether s holds secret placeholders
+func HasPlaceholders(s string) bool {
+	return placeholderPattern.MatchString(s)
+}
+
+// Resolve replaces the placeholders of s with their secrets
+func Resolve(ctx context.Context, s string) (string, error) {
+	return resolve(ctx, s, func(secret string) string { return secret })
+}
+
+// ResolveURL replaces the placeholders of a URL with their secrets, escaped
+// so characters such as @, / or : in a password do not change the URL
+func ResolveURL(ctx context.Context, rawURL string) (string, error) {
+	return resolve(ctx, rawURL, func(secret string) string {
+		return strings.ReplaceAll(url.QueryEscape(secret), "+", "%20")
+	})
+}
+
+// resolve replaces each placeholder with its escaped secret, fetching each
+// secret once
+func resolve(ctx context.Context, s string, escape func(string) string) (string, error) {
+	fetched := make(map[string]string)
+	var resolveErr error
+	resolved := placeholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		if resolveErr != nil {
+			return placeholder
+		}
+		match := placeholderPattern.FindStringSubmatch(placeholder)
+		secret, err := lookup(ctx, match[1], strings.TrimSpace(match[2]), fetched)
+		if err != nil {
+			resolveErr = err
+			return placeholder
+		}
+		return escape(secret)
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// lookup fetches a secret, unless it is in fetched, and for a name#field
+// reference returns the field of the JSON object it holds
+func lookup(ctx context.Context, provider, ref string, fetched map[string]string) (string, error) {
+	fetch, ok := providers[provider]
+	if !ok {
+		return "", fmt.Errorf("unknown secrets provider %q in {{%s:%s}} (use aws-sm, vault, keyring or env)", provider, provider, ref)
+	}
+
+	name, field, hasField := strings.Cut(ref, "#")
+	secret, ok := fetched[provider+":"+name]
+	if !ok {
+		var err error
+		if secret, err = fetch(ctx, name); err != nil {
+			return "", fmt.Errorf("failed to resolve {{%s:%s}}: %w", provider, ref, err)
+		}
+		fetched[provider+":"+name] = secret
+	}
+	if !hasField {
+		return secret, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("failed to resolve {{%s:%s}}: secret is not a JSON object", provider, ref)
+	}
+	value, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("failed to resolve {{%s:%s}}: secret has no field %s", provider, ref, field)
+	}
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve {{%s:%s}}: %w", provider, ref, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,136 @@
+// Package sqlguard tells statements that only read data from statements
+// that modify data, settings or the environment, for sessions that must not
+// write (the MCP server, run --read-only).
+package sqlguard
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// readStatements are the leading keywords of statements that only read data
+var readStatements = map[string]bool{
+	"SELECT":    true,
+	"WITH":      true,
+	"FROM":      true, // DuckDB FROM-first syntax
+	"VALUES":    true,
+	"TABLE":     true,
+	"SHOW":      true,
+	"DESCRIBE":  true,
+	"SUMMARIZE": true,
+	"EXPLAIN":   true,
+}
+
+// deniedKeywords modify data, settings or the environment and are rejected
+// anywhere in a statement (e.g. EXPLAIN ANALYZE DELETE or WITH ... INSERT).
+// Identifiers with these names must be double-quoted.
+var deniedKeywords = map[string]bool{
+	"INSERT":     true,
+	"UPDATE":     true,
+	"DELETE":     true,
+	"MERGE":      true,
+	"CREATE":     true,
+	"DROP":       true,
+	"ALTER":      true,
+	"TRUNCATE":   true,
+	"COPY":       true,
+	"EXPORT":     true,
+	"IMPORT":     true,
+	"INSTALL":    true,
+	"LOAD":       true,
+	"ATTACH":     true,
+	"DETACH":     true,
+	"PRAGMA":     true,
+	"CALL":       true,
+	"CHECKPOINT": true,
+	"VACUUM":     true,
+	"GRANT":      true,
+	"REVOKE":     true,
+}
+
+// CheckReadOnly returns an error naming the first statement or keyword of
+// query that is not read-only
+func CheckReadOnly(query string) error {
+	for _, statement := range Statements(query) {
+		if !readStatements[statement[0]] {
+			return fmt.Errorf("%s statements are not allowed", statement[0])
+		}
+		for _, keyword := range statement[1:] {
+			if deniedKeywords[keyword] {
+				return fmt.Errorf("%s is not allowed", keyword)
+			}
+		}
+	}
+	return nil
+}
+
+// Statements splits SQL into statements and returns the upper-cased words
+// of each one. String literals, quoted identifiers and comments are skipped so
+// their content is never mistaken for a keyword.
+func Statements(query string) [][]string {
+	var statements [][]string
+	var words []string
+
+	flush := func() {
+		if len(words) > 0 {
+			statements = append(statements, words)
+			words = nil
+		}
+	}
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'' || r == '"':
+			i = skipQuoted(runes, i, r)
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i = skipUntil(runes, i+2, '*', '/')
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '$':
+			i = skipUntil(runes, i+2, '$', '$')
+		case r == ';':
+			flush()
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1]) || runes[i+1] == '_') {
+				i++
+			}
+			words = append(words, strings.ToUpper(string(runes[start:i+1])))
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// skipUntil returns the index of the end of the first "first second" pair at
+// or after from (e.g. the end of a block comment)
+func skipUntil(runes []rune, from int, first, second rune) int {
+	for i := from; i+1 < len(runes); i++ {
+		if runes[i] == first && runes[i+1] == second {
+			return i + 1
+		}
+	}
+	return len(runes)
+}
+
+// skipQuoted returns the index of the quote closing the literal opened at
+// start; a doubled quote is an escaped quote
+func skipQuoted(runes []rune, start int, quote rune) int {
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] != quote {
+			continue
+		}
+		if i+1 < len(runes) && runes[i+1] == quote {
+			i++
+			continue
+		}
+		return i
+	}
+	return len(runes)
+}
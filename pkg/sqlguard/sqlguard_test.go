@@ -0,0 +1,24 @@
+package sqlguard_test
+
+import (
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/sqlguard"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatements(t *testing.T) {
+	statements := sqlguard.Statements(`SELECT 'a;b', "drop" FROM t -- ; DELETE
+; /* INSERT */ with x AS (SELECT 1) SELECT * FROM x;`)
+	assert.Equal(t, [][]string{
+		{"SELECT", "FROM", "T"},
+		{"WITH", "X", "AS", "SELECT", "SELECT", "FROM", "X"},
+	}, statements)
+}
+
+func TestCheckReadOnly(t *testing.T) {
+	assert.NoError(t, sqlguard.CheckReadOnly("SELECT * FROM users; SHOW TABLES"))
+	assert.NoError(t, sqlguard.CheckReadOnly(""))
+	assert.EqualError(t, sqlguard.CheckReadOnly("UPDATE users SET a = 1"), "UPDATE statements are not allowed")
+	assert.EqualError(t, sqlguard.CheckReadOnly("EXPLAIN ANALYZE DELETE FROM users"), "DELETE is not allowed")
+}
@@ -0,0 +1,39 @@
+package storage
+
+import "fmt"
+
+// Defaults of the import batches, set with --batch-size and --commit-interval
+const (
+	DefaultBatchSize      = 100_000 // Rows buffered before they are written
+	DefaultCommitInterval = 10_000  // Rows inserted one by one per transaction
+)
+
+// BatchStorage is an optional interface for storage implementations that
+// write imported rows in batches
+type BatchStorage interface {
+	Storage
+	// SetBatch sets how many imported rows are buffered in memory before
+	// they are written (batchSize) and how many rows inserted with INSERT
+	// are committed together (commitInterval). Values below 1 keep the
+	// defaults.
+	SetBatch(batchSize, commitInterval int)
+}
+
+// SetBatch sets the import batches of st when it writes rows in batches
+func SetBatch(st Storage, batchSize, commitInterval int) {
+	if batchStorage, ok := st.(BatchStorage); ok {
+		batchStorage.SetBatch(batchSize, commitInterval)
+	}
+}
+
+// CheckBatch validates --batch-size and --commit-interval; 0 selects the
+// default
+func CheckBatch(batchSize, commitInterval int) error {
+	if batchSize < 0 {
+		return fmt.Errorf("invalid --batch-size %d (expected a number of rows, 0 for %d)", batchSize, DefaultBatchSize)
+	}
+	if commitInterval < 0 {
+		return fmt.Errorf("invalid --commit-interval %d (expected a number of rows, 0 for %d)", commitInterval, DefaultCommitInterval)
+	}
+	return nil
+}
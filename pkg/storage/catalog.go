@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QuoteIdentifier quotes a table or column name for DuckDB and SQLite
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// ColumnsQuery returns the query listing the table_name, column_name and
+// data_type of the columns of the tables and views of engine, in column
+// order, leaving out the "schemas" metadata table. When table is set, only
+// its columns are listed.
+func ColumnsQuery(engine Engine, table string) string {
+	escaped := strings.ReplaceAll(table, "'", "''")
+	if engine == EngineSQLite {
+		filter := "m.name <> 'schemas'"
+		if table != "" {
+			filter = fmt.Sprintf("m.name = '%s'", escaped)
+		}
+		return fmt.Sprintf(`SELECT m.name AS table_name, p.name AS column_name, p.type AS data_type
+		FROM sqlite_master m JOIN pragma_table_info(m.name) p
+		WHERE m.type IN ('table', 'view') AND m.name NOT LIKE 'sqlite_%%' AND %s
+		ORDER BY m.name, p.cid`, filter)
+	}
+
+	filter := "table_name <> 'schemas'"
+	if table != "" {
+		filter = fmt.Sprintf("table_name = '%s'", escaped)
+	}
+	return fmt.Sprintf(`SELECT table_name, column_name, data_type
+		FROM information_schema.columns
+		WHERE table_schema = 'main' AND %s
+		ORDER BY table_name, ordinal_position`, filter)
+}
+
+// SampleQuery returns the query reading n random rows of table
+func SampleQuery(engine Engine, table string, n int) string {
+	if engine == EngineSQLite {
+		return fmt.Sprintf("SELECT * FROM %s ORDER BY random() LIMIT %d", table, n)
+	}
+	return fmt.Sprintf("SELECT * FROM %s USING SAMPLE %d ROWS", table, n)
+}
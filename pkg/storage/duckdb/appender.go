@@ -0,0 +1,291 @@
+//go:build !noduckdb
+
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/marcboeker/go-duckdb"
+)
+
+const sqlTableColumnsTemplate = `SELECT column_name, data_type FROM information_schema.columns
+WHERE table_catalog = current_database() AND table_schema = current_schema() AND lower(table_name) = lower($1)
+ORDER BY ordinal_position;`
+
+// tableAppender loads the rows of one table through the DuckDB Appender,
+// which writes typed values straight into the table instead of running an
+// INSERT per row
+type tableAppender struct {
+	conn     *sql.Conn
+	appender *duckdb.Appender
+	types    []string       // Column types of the table, in table order
+	index    map[string]int // Lower-cased column name -> table position
+	pending  int            // Rows appended since the last flush
+
+	// Table positions of the columns of the last InsertRow, which are the
+	// same for every row of an import
+	columns   []string
+	positions []int
+}
+
+// appendRow appends a row through the appender of tableName. It returns
+// false, appending nothing, when the row needs the casts of INSERT: a value
+// of another Go type than the column, a column the table lacks, or a table
+// with types the appender does not write (DECIMAL, STRUCT, ...).
+// The caller holds importMu.
+func (s *duckDBStorage) appendRow(tableName string, columns []string, values []any) (bool, error) {
+	a, ok := s.appenders[tableName]
+	if !ok {
+		var err error
+		if a, err = s.newTableAppender(tableName); err != nil {
+			return false, err
+		}
+		if s.appenders == nil {
+			s.appenders = make(map[string]*tableAppender)
+		}
+		s.appenders[tableName] = a
+	}
+	if a == nil {
+		return false, nil
+	}
+
+	if !sameColumns(a.columns, columns) {
+		positions := make([]int, len(columns))
+		for i, column := range columns {
+			position, found := a.index[strings.ToLower(column)]
+			if !found {
+				return false, a.flush(tableName)
+			}
+			positions[i] = position
+		}
+		a.columns, a.positions = append([]string(nil), columns...), positions
+	}
+
+	row := make([]driver.Value, len(a.types))
+	for i, value := range values {
+		if i >= len(a.positions) {
+			break
+		}
+		position := a.positions[i]
+		converted, ok := appendValue(value, a.types[position])
+		if !ok {
+			// INSERT runs after the buffered rows, keeping the row order
+			return false, a.flush(tableName)
+		}
+		row[position] = converted
+	}
+
+	// Rows inserted before this one are committed first, keeping the order
+	if err := s.commitImport(); err != nil {
+		return false, err
+	}
+	if err := a.appender.AppendRow(row...); err != nil {
+		return false, fmt.Errorf("failed to append row to %s: %w", tableName, err)
+	}
+	a.pending++
+	if a.pending >= s.batchSize() {
+		return true, a.flush(tableName)
+	}
+	return true, nil
+}
+
+// newTableAppender opens an appender on tableName, or returns nil when its
+// columns cannot be appended and rows must be inserted
+func (s *duckDBStorage) newTableAppender(tableName string) (*tableAppender, error) {
+	rows, err := s.db.Query(sqlTableColumnsTemplate, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	a := &tableAppender{index: make(map[string]int)}
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", tableName, err)
+		}
+		if !appendableType(dataType) {
+			return nil, nil
+		}
+		a.index[strings.ToLower(name)] = len(a.types)
+		a.types = append(a.types, dataType)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", tableName, err)
+	}
+	if len(a.types) == 0 {
+		return nil, nil
+	}
+
+	if a.conn, err = s.db.Conn(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to open appender on %s: %w", tableName, err)
+	}
+	err = a.conn.Raw(func(driverConn any) error {
+		conn, ok := driverConn.(driver.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		a.appender, err = duckdb.NewAppenderFromConn(conn, "", tableName)
+		return err
+	})
+	if err != nil {
+		_ = a.conn.Close()
+		return nil, fmt.Errorf("failed to open appender on %s: %w", tableName, err)
+	}
+	return a, nil
+}
+
+// flush writes the buffered rows to the table
+func (a *tableAppender) flush(tableName string) error {
+	if a.pending == 0 {
+		return nil
+	}
+	a.pending = 0
+	if err := a.appender.Flush(); err != nil {
+		return fmt.Errorf("failed to append rows to %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// insertRow inserts a row with INSERT. Outside of explicit transactions the
+// rows are committed every commitInterval rows. The caller holds importMu.
+func (s *duckDBStorage) insertRow(query string, values []any) error {
+	if s.tx != nil {
+		_, err := s.tx.Exec(query, values...)
+		return err
+	}
+
+	if s.importTx == nil {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin import transaction: %w", err)
+		}
+		s.importTx = tx
+	}
+	if _, err := s.importTx.Exec(query, values...); err != nil {
+		_ = s.importTx.Rollback()
+		s.importTx, s.importRows = nil, 0
+		return err
+	}
+
+	s.importRows++
+	if s.importRows >= s.commitInterval() {
+		return s.commitImport()
+	}
+	return nil
+}
+
+// commitImport commits the rows inserted since the last commit. The caller
+// holds importMu.
+func (s *duckDBStorage) commitImport() error {
+	if s.importTx == nil {
+		return nil
+	}
+	err := s.importTx.Commit()
+	s.importTx, s.importRows = nil, 0
+	if err != nil {
+		return fmt.Errorf("failed to commit imported rows: %w", err)
+	}
+	return nil
+}
+
+// SetBatch sets how many rows an appender buffers before writing them and
+// how many rows inserted with INSERT are committed together
+func (s *duckDBStorage) SetBatch(batchSize, commitInterval int) {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+	s.batchRows, s.commitRows = batchSize, commitInterval
+}
+
+func (s *duckDBStorage) batchSize() int {
+	if s.batchRows < 1 {
+		return storage.DefaultBatchSize
+	}
+	return s.batchRows
+}
+
+func (s *duckDBStorage) commitInterval() int {
+	if s.commitRows < 1 {
+		return storage.DefaultCommitInterval
+	}
+	return s.commitRows
+}
+
+// flushImport writes the buffered rows of every table, commits the inserted
+// ones and closes the appenders. It runs before any other statement, so
+// statements always see every imported row.
+func (s *duckDBStorage) flushImport() error {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+
+	errs := []error{s.commitImport()}
+	for tableName, a := range s.appenders {
+		if a == nil {
+			continue
+		}
+		if err := a.appender.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to append rows to %s: %w", tableName, err))
+		}
+		_ = a.conn.Close()
+	}
+	s.appenders = nil
+	return errors.Join(errs...)
+}
+
+// appendableType reports whether the appender writes columns of dataType
+// from the values appendValue accepts
+func appendableType(dataType string) bool {
+	switch dataType {
+	case "VARCHAR", "BIGINT", "DOUBLE", "BOOLEAN":
+		return true
+	}
+	return false
+}
+
+// appendValue returns value as written to a column of dataType, or false
+// when the value needs the cast of INSERT to keep its result (e.g. a string
+// into a BIGINT column, or a number into a VARCHAR one)
+func appendValue(value any, dataType string) (driver.Value, bool) {
+	if value == nil {
+		return nil, true
+	}
+
+	switch dataType {
+	case "VARCHAR":
+		v, ok := value.(string)
+		return v, ok
+	case "BIGINT":
+		switch v := value.(type) {
+		case int64, int32, int16, int8, int, uint32, uint16, uint8:
+			return v, true
+		}
+	case "DOUBLE":
+		switch v := value.(type) {
+		case float64, float32, int64, int32, int:
+			return v, true
+		}
+	case "BOOLEAN":
+		v, ok := value.(bool)
+		return v, ok
+	}
+	return nil, false
+}
+
+// sameColumns reports whether a and b hold the same column names in order
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+//go:build !noduckdb
+
+package duckdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/marcboeker/go-duckdb"
+)
+
+// scalarFunction adapts a storage.ScalarFunction to the DuckDB driver
+type scalarFunction struct {
+	config duckdb.ScalarFuncConfig
+	call   func(args []any) (any, error)
+}
+
+func (f *scalarFunction) Config() duckdb.ScalarFuncConfig {
+	return f.config
+}
+
+func (f *scalarFunction) Executor() duckdb.ScalarFuncExecutor {
+	return duckdb.ScalarFuncExecutor{RowExecutor: func(values []driver.Value) (result any, err error) {
+		// A panicking function fails the query instead of the process
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+
+		args := make([]any, len(values))
+		for i, value := range values {
+			args[i] = value
+		}
+		return f.call(args)
+	}}
+}
+
+// RegisterFunction makes fn callable from SQL, with any number of arguments
+// of any type. Functions are volatile: DuckDB never folds their calls.
+func (s *duckDBStorage) RegisterFunction(fn storage.ScalarFunction) error {
+	result, err := duckdbType(fn.Result)
+	if err != nil {
+		return fmt.Errorf("function %s: %w", fn.Name, err)
+	}
+	resultInfo, err := duckdb.NewTypeInfo(result)
+	if err != nil {
+		return fmt.Errorf("function %s: %w", fn.Name, err)
+	}
+	anyInfo, err := duckdb.NewTypeInfo(duckdb.TYPE_ANY)
+	if err != nil {
+		return fmt.Errorf("function %s: %w", fn.Name, err)
+	}
+
+	conn, err := s.db.Conn(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to register function %s: %w", fn.Name, err)
+	}
+	defer conn.Close()
+
+	err = duckdb.RegisterScalarUDF(conn, fn.Name, &scalarFunction{
+		config: duckdb.ScalarFuncConfig{
+			ResultTypeInfo:   resultInfo,
+			VariadicTypeInfo: anyInfo,
+			Volatile:         true,
+		},
+		call: fn.Call,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register function %s: %w", fn.Name, err)
+	}
+	return nil
+}
+
+// duckdbType maps a storage type to a DuckDB type
+func duckdbType(t storage.DataType) (duckdb.Type, error) {
+	switch t {
+	case storage.TypeVarchar:
+		return duckdb.TYPE_VARCHAR, nil
+	case storage.TypeBigInt:
+		return duckdb.TYPE_BIGINT, nil
+	case storage.TypeDouble:
+		return duckdb.TYPE_DOUBLE, nil
+	case storage.TypeBoolean:
+		return duckdb.TYPE_BOOLEAN, nil
+	case storage.TypeTimestampTZ:
+		return duckdb.TYPE_TIMESTAMP_TZ, nil
+	}
+	return duckdb.TYPE_INVALID, fmt.Errorf("unsupported result type %q", t)
+}
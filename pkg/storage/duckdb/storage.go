@@ -3,9 +3,12 @@
 package duckdb
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 
 	"github.com/adrianolaselva/dataql/pkg/storage"
 	_ "github.com/marcboeker/go-duckdb"
@@ -15,13 +18,66 @@ const (
 	sqlCreateTableTemplate        = "CREATE TABLE IF NOT EXISTS %s (%s\n);"
 	sqlInsertTemplate             = "INSERT INTO %s (%s) VALUES (%s);"
 	sqlInsertDefaultTableTemplate = `INSERT INTO "schemas" ("id", "name", "columns", "total_columns") VALUES ((SELECT COALESCE(MAX(id), 0)+1 FROM "schemas"), $1, $2, $3);`
-	sqlShowTablesTemplate         = `SELECT * FROM "schemas";`
 	sqlDefaultTableTemplate       = `CREATE TABLE IF NOT EXISTS "schemas" ("id" INTEGER, "name" VARCHAR, "columns" VARCHAR, "total_columns" INTEGER);`
+	sqlCurrentDatabaseTemplate    = "SELECT current_database();"
+	sqlAttachTemplate             = "ATTACH '%s' AS %s;"
+	sqlDetachTemplate             = "DETACH %s;"
+	sqlCopyDatabaseTemplate       = "COPY FROM DATABASE %s TO %s;"
+	sqlTableExistsTemplate        = "SELECT COUNT(*) FROM information_schema.tables WHERE table_catalog = current_database() AND table_schema = 'main' AND table_type = 'BASE TABLE' AND lower(table_name) = lower($1);"
+	sqlDropTableTemplate          = "DROP TABLE %s;"
+	sqlDropTableIfExistsTemplate  = "DROP TABLE IF EXISTS %s;"
+	sqlRenameTableTemplate        = "ALTER TABLE %s RENAME TO %s;"
+	sqlRenameDefaultTableTemplate = `UPDATE "schemas" SET "name" = $1 WHERE "name" = $2;`
+	sqlDeleteDefaultTableTemplate = `DELETE FROM "schemas" WHERE "name" = $1;`
+	snapshotAlias                 = "dataql_snapshot"
 	dataSourceNameDefault         = ""
 )
 
+// sqlShowTablesTemplate lists the imported tables of "schemas", then the
+// tables and views created with SQL (CREATE TABLE ... AS) and left out of it.
+// Tables dropped since they were imported are not listed.
+const sqlShowTablesTemplate = `SELECT "id", "name", "columns", "total_columns" FROM "schemas"
+WHERE "name" IN (SELECT table_name FROM information_schema.tables WHERE table_catalog = current_database() AND table_schema = 'main')
+UNION ALL
+SELECT (SELECT COALESCE(MAX(id), 0) FROM "schemas") + row_number() OVER (ORDER BY table_name), table_name,
+	'[' || string_agg('"' || column_name || '"', ',' ORDER BY ordinal_position) || ']', COUNT(*)
+FROM information_schema.columns
+WHERE table_catalog = current_database() AND table_schema = 'main'
+	AND table_name <> 'schemas' AND table_name NOT IN (SELECT "name" FROM "schemas")
+GROUP BY table_name
+ORDER BY 1;`
+
 type duckDBStorage struct {
 	db *sql.DB
+	tx *sql.Tx // Open explicit transaction, nil in autocommit mode
+
+	// Imported rows: the appenders of the tables, nil for tables whose rows
+	// are inserted (see appendRow), and the transaction of inserted rows
+	appenders  map[string]*tableAppender
+	importTx   *sql.Tx
+	importRows int
+	batchRows  int // Rows an appender buffers, see SetBatch
+	commitRows int // Inserted rows per import transaction, see SetBatch
+	importMu   sync.Mutex
+
+	ifExists storage.IfExists // See SetIfExists
+	built    map[string]bool  // Tables created or prepared by the imports of the session
+	staged   map[string]stagedTable
+}
+
+// stagedTable is a table imported with --if-exists replace: its rows go to
+// the staging table until ReplaceTables swaps it in
+type stagedTable struct {
+	name    string
+	staging string
+}
+
+// executor is the subset of *sql.DB and *sql.Tx used to run statements
+type executor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
 // NewDuckDBStorage creates a new DuckDB storage instance.
@@ -61,7 +117,7 @@ func (s *duckDBStorage) BuildStructureWithTypes(tableName string, columns []stor
 	// Create quoted column names for SQL
 	quotedColumns := make([]string, len(columns))
 	for i, col := range columns {
-		quotedColumns[i] = quoteIdentifier(col.Name)
+		quotedColumns[i] = storage.QuoteIdentifier(col.Name)
 	}
 
 	for i, col := range columns {
@@ -75,29 +131,186 @@ func (s *duckDBStorage) BuildStructureWithTypes(tableName string, columns []stor
 		}
 	}
 
-	query := fmt.Sprintf(sqlCreateTableTemplate, quoteIdentifier(tableName), tableAttrsRaw.String())
-	if _, err := s.db.Exec(query); err != nil {
+	// The import transaction would not see the new table
+	s.importMu.Lock()
+	err := s.commitImport()
+	if err == nil {
+		err = s.prepareTable(tableName)
+	}
+	table := s.target(tableName)
+	s.importMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(sqlCreateTableTemplate, storage.QuoteIdentifier(table), tableAttrsRaw.String())
+	if _, err := s.conn().Exec(query); err != nil {
 		return fmt.Errorf("failed to create structure: %w (sql: %s)", err, query)
 	}
 
-	if _, err := s.db.Exec(sqlDefaultTableTemplate); err != nil {
+	if _, err := s.conn().Exec(sqlDefaultTableTemplate); err != nil {
 		return fmt.Errorf("failed to create tables schemas structure: %w", err)
 	}
 
 	columnsRaw := fmt.Sprintf("[%v]", strings.Join(quotedColumns, ","))
-	if _, err := s.db.Exec(sqlInsertDefaultTableTemplate, tableName, columnsRaw, len(columns)); err != nil {
+	if _, err := s.conn().Exec(sqlInsertDefaultTableTemplate, table, columnsRaw, len(columns)); err != nil {
 		return fmt.Errorf("failed to execute insert: %w", err)
 	}
 
 	return nil
 }
 
-// InsertRow inserts a row into the specified table.
+// SetIfExists sets what imports do with the tables that were in the
+// storage before: append to them (the default), replace or fail.
+func (s *duckDBStorage) SetIfExists(ifExists storage.IfExists) {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+	s.ifExists = ifExists
+}
+
+// prepareTable applies --if-exists to tableName the first time an import of
+// the session creates it, when it was in the storage before. The caller
+// holds importMu.
+func (s *duckDBStorage) prepareTable(tableName string) error {
+	key := strings.ToLower(tableName)
+	if s.built[key] {
+		return nil
+	}
+	if s.built == nil {
+		s.built = make(map[string]bool)
+	}
+	s.built[key] = true
+	if s.ifExists != storage.IfExistsReplace && s.ifExists != storage.IfExistsFail {
+		return nil
+	}
+
+	rows, err := s.conn().Query(sqlTableExistsTemplate, tableName)
+	if err != nil {
+		return fmt.Errorf("failed to check table %s: %w", tableName, err)
+	}
+	var count int
+	if rows.Next() {
+		err = rows.Scan(&count)
+	}
+	_ = rows.Close()
+	if err != nil {
+		return fmt.Errorf("failed to check table %s: %w", tableName, err)
+	}
+	if count == 0 {
+		return nil
+	}
+	if s.ifExists == storage.IfExistsFail {
+		return storage.TableExistsError(tableName)
+	}
+
+	// The table is replaced once the import succeeded, see ReplaceTables
+	staging := storage.ReplaceStagingTable(tableName)
+	if _, err := s.conn().Exec(fmt.Sprintf(sqlDropTableIfExistsTemplate, storage.QuoteIdentifier(staging))); err != nil {
+		return fmt.Errorf("failed to replace table %s: %w", tableName, err)
+	}
+	if s.staged == nil {
+		s.staged = make(map[string]stagedTable)
+	}
+	s.staged[key] = stagedTable{name: tableName, staging: staging}
+	return nil
+}
+
+// target returns the table the rows imported into tableName are written
+// to: its staging table while it is being replaced. The caller holds
+// importMu.
+func (s *duckDBStorage) target(tableName string) string {
+	if staged, ok := s.staged[strings.ToLower(tableName)]; ok {
+		return staged.staging
+	}
+	return tableName
+}
+
+// ReplaceTables drops the tables imported with --if-exists replace and
+// renames their staging tables, in a single transaction.
+func (s *duckDBStorage) ReplaceTables() error {
+	if err := s.flushImport(); err != nil {
+		return err
+	}
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+	if len(s.staged) == 0 {
+		return nil
+	}
+
+	tx := s.tx
+	if tx == nil {
+		var err error
+		if tx, err = s.db.Begin(); err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+	}
+	for _, staged := range s.staged {
+		if err := replaceTable(tx, staged); err != nil {
+			if s.tx == nil {
+				_ = tx.Rollback()
+			}
+			return err
+		}
+	}
+	if s.tx == nil {
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to replace tables: %w", err)
+		}
+	}
+	s.staged = nil
+	return nil
+}
+
+// replaceTable swaps staged.staging in for staged.name
+func replaceTable(exec executor, staged stagedTable) error {
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{query: fmt.Sprintf(sqlDropTableTemplate, storage.QuoteIdentifier(staged.name))},
+		{query: fmt.Sprintf(sqlRenameTableTemplate, storage.QuoteIdentifier(staged.staging), storage.QuoteIdentifier(staged.name))},
+		// The columns recorded for the dropped table no longer apply
+		{query: sqlDefaultTableTemplate},
+		{query: sqlDeleteDefaultTableTemplate, args: []any{staged.name}},
+		{query: sqlRenameDefaultTableTemplate, args: []any{staged.name, staged.staging}},
+	}
+	for _, statement := range statements {
+		if _, err := exec.Exec(statement.query, statement.args...); err != nil {
+			return fmt.Errorf("failed to replace table %s: %w", staged.name, err)
+		}
+	}
+	return nil
+}
+
+// dropStaged drops the staging tables of an import that did not succeed,
+// keeping the tables they were to replace
+func (s *duckDBStorage) dropStaged() {
+	for _, staged := range s.staged {
+		_, _ = s.db.Exec(fmt.Sprintf(sqlDropTableIfExistsTemplate, storage.QuoteIdentifier(staged.staging)))
+		_, _ = s.db.Exec(sqlDeleteDefaultTableTemplate, staged.staging)
+	}
+	s.staged = nil
+}
+
+// InsertRow inserts a row into the specified table. Outside of explicit
+// transactions rows are loaded through the DuckDB Appender when their values
+// match the column types, and written before the next statement runs.
 func (s *duckDBStorage) InsertRow(tableName string, columns []string, values []any) error {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+
+	tableName = s.target(tableName)
+	if s.tx == nil {
+		appended, err := s.appendRow(tableName, columns, values)
+		if appended || err != nil {
+			return err
+		}
+	}
+
 	// Quote column names for SQL
 	quotedColumns := make([]string, len(columns))
 	for i, col := range columns {
-		quotedColumns[i] = quoteIdentifier(col)
+		quotedColumns[i] = storage.QuoteIdentifier(col)
 	}
 	columnsRaw := strings.Join(quotedColumns, ", ")
 
@@ -108,9 +321,9 @@ func (s *duckDBStorage) InsertRow(tableName string, columns []string, values []a
 	}
 	paramsRaw := strings.Join(placeholders, ", ")
 
-	query := fmt.Sprintf(sqlInsertTemplate, quoteIdentifier(tableName), columnsRaw, paramsRaw)
+	query := fmt.Sprintf(sqlInsertTemplate, storage.QuoteIdentifier(tableName), columnsRaw, paramsRaw)
 
-	if _, err := s.db.Exec(query, values...); err != nil {
+	if err := s.insertRow(query, values); err != nil {
 		return fmt.Errorf("failed to execute insert: %w (sql: %s)", err, query)
 	}
 
@@ -142,7 +355,10 @@ func (s *duckDBStorage) InsertRowWithCoercion(tableName string, columns []string
 
 // Query executes the given SQL query and returns the result rows.
 func (s *duckDBStorage) Query(cmd string) (*sql.Rows, error) {
-	rows, err := s.db.Query(cmd)
+	if err := s.flushImport(); err != nil {
+		return nil, err
+	}
+	rows, err := s.conn().Query(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -150,9 +366,37 @@ func (s *duckDBStorage) Query(cmd string) (*sql.Rows, error) {
 	return rows, nil
 }
 
+// QueryContext executes the given SQL query, interrupting it when ctx is done.
+func (s *duckDBStorage) QueryContext(ctx context.Context, cmd string) (*sql.Rows, error) {
+	if err := s.flushImport(); err != nil {
+		return nil, err
+	}
+	rows, err := s.conn().QueryContext(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return rows, nil
+}
+
+// ExecContext executes a statement that returns no rows, interrupting it when ctx is done.
+func (s *duckDBStorage) ExecContext(ctx context.Context, cmd string) error {
+	if err := s.flushImport(); err != nil {
+		return err
+	}
+	if _, err := s.conn().ExecContext(ctx, cmd); err != nil {
+		return fmt.Errorf("failed to execute statement: %w", err)
+	}
+
+	return nil
+}
+
 // ShowTables returns the metadata about all loaded tables.
 func (s *duckDBStorage) ShowTables() (*sql.Rows, error) {
-	rows, err := s.db.Query(sqlShowTablesTemplate)
+	if err := s.flushImport(); err != nil {
+		return nil, err
+	}
+	rows, err := s.conn().Query(sqlShowTablesTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -160,18 +404,119 @@ func (s *duckDBStorage) ShowTables() (*sql.Rows, error) {
 	return rows, nil
 }
 
-// Close closes the database connection.
-func (s *duckDBStorage) Close() error {
-	err := s.db.Close()
+// SaveTo copies every table of the current database (including the schemas
+// metadata table) into a new DuckDB file at path. The resulting file can be
+// reopened later in storage-only mode.
+func (s *duckDBStorage) SaveTo(path string) error {
+	if s.tx != nil {
+		return fmt.Errorf("a transaction is open: commit or roll back before saving")
+	}
+	if err := s.flushImport(); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("file already exists: %s", path)
+	}
+
+	var current string
+	if err := s.db.QueryRow(sqlCurrentDatabaseTemplate).Scan(&current); err != nil {
+		return fmt.Errorf("failed to resolve current database: %w", err)
+	}
+
+	if _, err := s.db.Exec(fmt.Sprintf(sqlAttachTemplate, strings.ReplaceAll(path, "'", "''"), snapshotAlias)); err != nil {
+		return fmt.Errorf("failed to attach %s: %w", path, err)
+	}
+
+	_, copyErr := s.db.Exec(fmt.Sprintf(sqlCopyDatabaseTemplate, storage.QuoteIdentifier(current), snapshotAlias))
+
+	if _, err := s.db.Exec(fmt.Sprintf(sqlDetachTemplate, snapshotAlias)); err != nil && copyErr == nil {
+		return fmt.Errorf("failed to detach %s: %w", path, err)
+	}
+
+	if copyErr != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to copy tables to %s: %w", path, copyErr)
+	}
+
+	return nil
+}
+
+// Begin starts an explicit transaction. Every statement runs inside it until
+// Commit or Rollback is called.
+func (s *duckDBStorage) Begin() error {
+	if s.tx != nil {
+		return fmt.Errorf("a transaction is already open")
+	}
+	if err := s.flushImport(); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to close duckdb connection: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	s.tx = tx
+
+	return nil
+}
+
+// Commit commits the open transaction.
+func (s *duckDBStorage) Commit() error {
+	if s.tx == nil {
+		return fmt.Errorf("no transaction is open")
+	}
+
+	err := s.tx.Commit()
+	s.tx = nil
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
-// quoteIdentifier quotes an identifier (table or column name) for safe use in DuckDB.
-// DuckDB uses double quotes for identifiers.
-func quoteIdentifier(name string) string {
-	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+// Rollback discards the changes made in the open transaction.
+func (s *duckDBStorage) Rollback() error {
+	if s.tx == nil {
+		return fmt.Errorf("no transaction is open")
+	}
+
+	err := s.tx.Rollback()
+	s.tx = nil
+	if err != nil {
+		return fmt.Errorf("failed to roll back transaction: %w", err)
+	}
+
+	return nil
+}
+
+// InTransaction reports whether an explicit transaction is open.
+func (s *duckDBStorage) InTransaction() bool {
+	return s.tx != nil
+}
+
+// conn returns the open transaction, or the database in autocommit mode.
+func (s *duckDBStorage) conn() executor {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.db
+}
+
+// Close closes the database connection, rolling back any open transaction.
+func (s *duckDBStorage) Close() error {
+	appendErr := s.flushImport()
+	if s.tx != nil {
+		_ = s.tx.Rollback()
+		s.tx = nil
+	}
+	s.dropStaged()
+
+	err := s.db.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close duckdb connection: %w", err)
+	}
+
+	return appendErr
 }
@@ -3,8 +3,12 @@
 package duckdb_test
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
 	"testing"
 
+	"github.com/adrianolaselva/dataql/pkg/storage"
 	"github.com/adrianolaselva/dataql/pkg/storage/duckdb"
 	"github.com/stretchr/testify/assert"
 )
@@ -254,6 +258,35 @@ func TestShowTables(t *testing.T) {
 	assert.Equal(t, int64(2), totalColumns)
 }
 
+func TestShowTablesCreatedWithSQL(t *testing.T) {
+	storage, err := duckdb.NewDuckDBStorage("")
+	assert.NoError(t, err)
+	defer storage.Close()
+
+	assert.NoError(t, storage.BuildStructure("imported", []string{"a"}))
+	assert.NoError(t, storage.BuildStructure("dropped", []string{"a"}))
+	for _, statement := range []string{"CREATE TABLE clean AS SELECT a, a AS b FROM imported", "DROP TABLE dropped"} {
+		rows, err := storage.Query(statement)
+		assert.NoError(t, err)
+		rows.Close()
+	}
+
+	rows, err := storage.ShowTables()
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	var names, columnLists []string
+	for rows.Next() {
+		var id, totalColumns int64
+		var name, columns string
+		assert.NoError(t, rows.Scan(&id, &name, &columns, &totalColumns))
+		names = append(names, name)
+		columnLists = append(columnLists, columns)
+	}
+	assert.Equal(t, []string{"imported", "clean"}, names)
+	assert.Equal(t, []string{`["a"]`, `["a","b"]`}, columnLists)
+}
+
 func TestSpecialCharactersInColumnNames(t *testing.T) {
 	storage, err := duckdb.NewDuckDBStorage("")
 	assert.NoError(t, err)
@@ -314,3 +347,239 @@ func TestMultipleTables(t *testing.T) {
 	assert.Equal(t, "Alice", name)
 	assert.Equal(t, "Laptop", product)
 }
+
+func TestSaveTo(t *testing.T) {
+	store, err := duckdb.NewDuckDBStorage("")
+	assert.NoError(t, err)
+	defer store.Close()
+
+	err = store.BuildStructure("users", []string{"id", "name"})
+	assert.NoError(t, err)
+	err = store.InsertRow("users", []string{"id", "name"}, []any{"1", "Alice"})
+	assert.NoError(t, err)
+
+	snapshot, ok := store.(storage.SnapshotStorage)
+	assert.True(t, ok)
+
+	path := filepath.Join(t.TempDir(), "session.duckdb")
+	assert.NoError(t, snapshot.SaveTo(path))
+
+	// Saving again to the same path must not overwrite the file
+	assert.Error(t, snapshot.SaveTo(path))
+
+	saved, err := duckdb.NewDuckDBStorage(path)
+	assert.NoError(t, err)
+	defer saved.Close()
+
+	rows, err := saved.Query("SELECT name FROM users;")
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	assert.True(t, rows.Next())
+	var name string
+	assert.NoError(t, rows.Scan(&name))
+	assert.Equal(t, "Alice", name)
+
+	tables, err := saved.ShowTables()
+	assert.NoError(t, err)
+	defer tables.Close()
+	assert.True(t, tables.Next())
+}
+
+func TestTransactions(t *testing.T) {
+	store, err := duckdb.NewDuckDBStorage("")
+	assert.NoError(t, err)
+	defer store.Close()
+
+	err = store.BuildStructure("users", []string{"id", "name"})
+	assert.NoError(t, err)
+
+	tx, ok := store.(storage.TransactionStorage)
+	assert.True(t, ok)
+	assert.False(t, tx.InTransaction())
+	assert.Error(t, tx.Commit())
+
+	// Rolled back rows must not be visible
+	assert.NoError(t, tx.Begin())
+	assert.True(t, tx.InTransaction())
+	assert.Error(t, tx.Begin())
+	assert.NoError(t, store.InsertRow("users", []string{"id", "name"}, []any{"1", "Alice"}))
+	assert.NoError(t, tx.Rollback())
+	assert.False(t, tx.InTransaction())
+	assert.Equal(t, 0, countRows(t, store, "users"))
+
+	// Committed rows are kept
+	assert.NoError(t, tx.Begin())
+	assert.NoError(t, store.InsertRow("users", []string{"id", "name"}, []any{"2", "Bob"}))
+	assert.Equal(t, 1, countRows(t, store, "users"))
+	assert.NoError(t, tx.Commit())
+	assert.Equal(t, 1, countRows(t, store, "users"))
+}
+
+func TestContextStorage(t *testing.T) {
+	store, err := duckdb.NewDuckDBStorage("")
+	assert.NoError(t, err)
+	defer store.Close()
+
+	ctxStore, ok := store.(storage.ContextStorage)
+	assert.True(t, ok)
+
+	assert.NoError(t, ctxStore.ExecContext(context.Background(), "CREATE TABLE users (id INTEGER)"))
+	assert.NoError(t, ctxStore.ExecContext(context.Background(), "INSERT INTO users VALUES (1), (2)"))
+
+	rows, err := ctxStore.QueryContext(context.Background(), "SELECT COUNT(*) FROM users")
+	assert.NoError(t, err)
+	var count int
+	assert.True(t, rows.Next())
+	assert.NoError(t, rows.Scan(&count))
+	assert.Equal(t, 2, count)
+	assert.NoError(t, rows.Close())
+
+	// A cancelled context stops the statement before it runs
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ctxStore.QueryContext(ctx, "SELECT COUNT(*) FROM users")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Error(t, ctxStore.ExecContext(ctx, "DROP TABLE users"))
+	assert.Equal(t, 2, countRows(t, store, "users"))
+}
+
+func TestInsertRowAppender(t *testing.T) {
+	store, err := duckdb.NewDuckDBStorage("")
+	assert.NoError(t, err)
+	defer store.Close()
+
+	typed := store.(storage.TypedStorage)
+	assert.NoError(t, typed.BuildStructureWithTypes("events", []storage.ColumnDef{
+		{Name: "id", Type: storage.TypeBigInt},
+		{Name: "note", Type: storage.TypeVarchar},
+		{Name: "score", Type: storage.TypeDouble},
+		{Name: "ok", Type: storage.TypeBoolean},
+	}))
+
+	columns := []string{"id", "note", "score", "ok"}
+	assert.NoError(t, store.InsertRow("events", columns, []any{int64(1), "said \"hi\"\nand 'left'", 1.5, true}))
+	// A string in a BIGINT column is cast by INSERT, after the appended rows
+	assert.NoError(t, store.InsertRow("events", columns, []any{"2", nil, int64(3), false}))
+	assert.NoError(t, store.InsertRow("events", []string{"ok", "id"}, []any{nil, int64(3)}))
+	assert.Error(t, store.InsertRow("events", columns, []any{"x", "", 0.0, false}))
+
+	rows, err := store.Query("SELECT id, note, score, ok FROM events ORDER BY rowid")
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	var got [][]any
+	for rows.Next() {
+		var id int64
+		var note *string
+		var score *float64
+		var ok *bool
+		assert.NoError(t, rows.Scan(&id, &note, &score, &ok))
+		got = append(got, []any{id, note != nil, score != nil, ok != nil})
+		if id == 1 {
+			assert.Equal(t, "said \"hi\"\nand 'left'", *note)
+		}
+	}
+	assert.Equal(t, [][]any{{int64(1), true, true, true}, {int64(2), false, true, true}, {int64(3), false, false, false}}, got)
+}
+
+func TestInsertRowBatches(t *testing.T) {
+	store, err := duckdb.NewDuckDBStorage(filepath.Join(t.TempDir(), "store.duckdb"))
+	assert.NoError(t, err)
+	defer store.Close()
+	storage.SetBatch(store, 2, 2)
+
+	typed := store.(storage.TypedStorage)
+	columns := []storage.ColumnDef{{Name: "id", Type: storage.TypeBigInt}, {Name: "amount", Type: storage.DecimalType(20, 2)}}
+	assert.NoError(t, typed.BuildStructureWithTypes("appended", columns[:1]))
+	assert.NoError(t, typed.BuildStructureWithTypes("inserted", columns))
+
+	// Appended and inserted rows are written in batches and all visible to
+	// the next statement
+	for i := 1; i <= 5; i++ {
+		assert.NoError(t, typed.InsertRowWithCoercion("appended", []string{"id"}, []any{fmt.Sprint(i)}, columns[:1]))
+		assert.NoError(t, typed.InsertRowWithCoercion("inserted", []string{"id", "amount"}, []any{fmt.Sprint(i), "1.25"}, columns))
+	}
+	assert.Equal(t, 5, countRows(t, store, "appended"))
+	assert.Equal(t, 5, countRows(t, store, "inserted"))
+
+	// A table created while inserted rows are pending accepts rows too
+	assert.NoError(t, typed.InsertRowWithCoercion("inserted", []string{"id", "amount"}, []any{"6", "1.25"}, columns))
+	assert.NoError(t, typed.BuildStructureWithTypes("later", columns))
+	assert.NoError(t, typed.InsertRowWithCoercion("later", []string{"id", "amount"}, []any{"1", "1.25"}, columns))
+	assert.Equal(t, 1, countRows(t, store, "later"))
+}
+
+func countRows(t *testing.T, store storage.Storage, table string) int {
+	t.Helper()
+
+	rows, err := store.Query("SELECT COUNT(*) FROM " + table)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	var count int
+	assert.True(t, rows.Next())
+	assert.NoError(t, rows.Scan(&count))
+	return count
+}
+
+func TestIfExists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.duckdb")
+	load := func(ifExists storage.IfExists, table string, ids ...string) error {
+		store, err := duckdb.NewDuckDBStorage(path)
+		assert.NoError(t, err)
+		defer store.Close()
+		storage.SetIfExists(store, ifExists)
+
+		// Every id is imported from its own file into the same table
+		for _, id := range ids {
+			if err := store.BuildStructure(table, []string{"id"}); err != nil {
+				return err
+			}
+			assert.NoError(t, store.InsertRow(table, []string{"id"}, []any{id}))
+		}
+		return storage.ReplaceTables(store)
+	}
+	count := func() int {
+		store, err := duckdb.NewDuckDBStorage(path)
+		assert.NoError(t, err)
+		defer store.Close()
+		return countRows(t, store, "sales")
+	}
+
+	assert.NoError(t, load(storage.IfExistsAppend, "sales", "1"))
+	assert.NoError(t, load(storage.IfExistsAppend, "sales", "2", "3"))
+	assert.Equal(t, 3, count())
+
+	assert.NoError(t, load(storage.IfExistsReplace, "Sales", "4", "5"))
+	assert.Equal(t, 2, count())
+
+	assert.ErrorIs(t, load(storage.IfExistsFail, "sales", "6"), storage.ErrTableExists)
+	assert.NoError(t, load(storage.IfExistsFail, "other", "1", "2"))
+	assert.Equal(t, 2, count())
+
+	// An import that fails before ReplaceTables keeps the table it replaces
+	store, err := duckdb.NewDuckDBStorage(path)
+	assert.NoError(t, err)
+	storage.SetIfExists(store, storage.IfExistsReplace)
+	assert.NoError(t, store.BuildStructure("sales", []string{"id"}))
+	assert.NoError(t, store.InsertRow("sales", []string{"id"}, []any{"7"}))
+	assert.NoError(t, store.Close())
+	assert.Equal(t, 2, count())
+
+	store, err = duckdb.NewDuckDBStorage(path)
+	assert.NoError(t, err)
+	defer store.Close()
+	rows, err := store.ShowTables()
+	assert.NoError(t, err)
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var id, columns int
+		var name string
+		var raw string
+		assert.NoError(t, rows.Scan(&id, &name, &raw, &columns))
+		tables = append(tables, name)
+	}
+	assert.NotContains(t, tables, storage.ReplaceStagingTable("sales"))
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Engine is the database engine that stores the imported sources and runs
+// the queries, chosen with --engine
+type Engine string
+
+const (
+	EngineDuckDB Engine = "duckdb" // Default: DuckDB SQL, extensions, typed imports and exports
+	EngineSQLite Engine = "sqlite" // SQLite SQL, for tools and queries written for SQLite
+)
+
+// Engines lists the accepted values of --engine
+var Engines = []Engine{EngineDuckDB, EngineSQLite}
+
+// ParseEngine parses an --engine value, duckdb when empty
+func ParseEngine(value string) (Engine, error) {
+	if value == "" {
+		return EngineDuckDB, nil
+	}
+	for _, engine := range Engines {
+		if Engine(strings.ToLower(value)) == engine {
+			return engine, nil
+		}
+	}
+	return "", fmt.Errorf("invalid --engine value %q (expected duckdb or sqlite)", value)
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEngine(t *testing.T) {
+	engine, err := ParseEngine("")
+	assert.NoError(t, err)
+	assert.Equal(t, EngineDuckDB, engine)
+
+	engine, err = ParseEngine("SQLite")
+	assert.NoError(t, err)
+	assert.Equal(t, EngineSQLite, engine)
+
+	_, err = ParseEngine("chdb")
+	assert.ErrorContains(t, err, "invalid --engine")
+}
+
+func TestCheckBatch(t *testing.T) {
+	assert.NoError(t, CheckBatch(0, 0))
+	assert.NoError(t, CheckBatch(500, 1))
+	assert.ErrorContains(t, CheckBatch(-1, 0), "invalid --batch-size")
+	assert.ErrorContains(t, CheckBatch(0, -5), "invalid --commit-interval")
+}
+
+func TestParseIfExists(t *testing.T) {
+	ifExists, err := ParseIfExists("")
+	assert.NoError(t, err)
+	assert.Equal(t, IfExistsAppend, ifExists)
+
+	ifExists, err = ParseIfExists("Replace")
+	assert.NoError(t, err)
+	assert.Equal(t, IfExistsReplace, ifExists)
+
+	_, err = ParseIfExists("merge")
+	assert.ErrorContains(t, err, "invalid --if-exists")
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	assert.Equal(t, `"users"`, QuoteIdentifier("users"))
+	assert.Equal(t, `"order id"`, QuoteIdentifier("order id"))
+	assert.Equal(t, `"a""b"`, QuoteIdentifier(`a"b`))
+}
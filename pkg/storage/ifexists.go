@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// IfExists is what an import does with a table that was in the storage
+// before the session, chosen with --if-exists. Tables the session created
+// are always appended to, so several files can be imported into one table.
+type IfExists string
+
+const (
+	IfExistsAppend  IfExists = "append"  // Default: insert the rows after the existing ones
+	IfExistsReplace IfExists = "replace" // Import into a staging table, then swap it in for the table
+	IfExistsFail    IfExists = "fail"    // Stop the import with ErrTableExists
+)
+
+// IfExistsValues lists the accepted values of --if-exists
+var IfExistsValues = []IfExists{IfExistsAppend, IfExistsReplace, IfExistsFail}
+
+// ErrTableExists is returned by imports into a table already in the storage
+// with --if-exists fail
+var ErrTableExists = errors.New("table already exists")
+
+// ParseIfExists parses an --if-exists value, append when empty
+func ParseIfExists(value string) (IfExists, error) {
+	if value == "" {
+		return IfExistsAppend, nil
+	}
+	for _, ifExists := range IfExistsValues {
+		if IfExists(strings.ToLower(value)) == ifExists {
+			return ifExists, nil
+		}
+	}
+	return "", fmt.Errorf("invalid --if-exists value %q (expected append, replace or fail)", value)
+}
+
+// IfExistsStorage is an optional interface for storage implementations
+// that import into tables already in the storage as --if-exists says
+type IfExistsStorage interface {
+	Storage
+	// SetIfExists sets what imports do with the tables that were in the
+	// storage before
+	SetIfExists(ifExists IfExists)
+	// ReplaceTables replaces the tables imported with --if-exists replace
+	// by their staging tables, once the import succeeded. The staging
+	// tables left when it is not called are dropped on Close, so a failed
+	// import keeps the tables it was replacing.
+	ReplaceTables() error
+}
+
+// SetIfExists sets what the imports into st do with existing tables when
+// it supports it
+func SetIfExists(st Storage, ifExists IfExists) {
+	if ifExistsStorage, ok := st.(IfExistsStorage); ok {
+		ifExistsStorage.SetIfExists(ifExists)
+	}
+}
+
+// ReplaceTables swaps the tables imported into st with --if-exists replace
+// in for the tables they replace when st supports it
+func ReplaceTables(st Storage) error {
+	if ifExistsStorage, ok := st.(IfExistsStorage); ok {
+		return ifExistsStorage.ReplaceTables()
+	}
+	return nil
+}
+
+// ReplaceStagingTable returns the name of the table an import with
+// --if-exists replace writes the rows of table into
+func ReplaceStagingTable(table string) string {
+	return "dataql_replace_" + strings.ToLower(table)
+}
+
+// TableExistsError returns the error of an import into table with
+// --if-exists fail
+func TableExistsError(table string) error {
+	return fmt.Errorf("%w: %s is already in the storage file (--if-exists fail; use append or replace to import into it)", ErrTableExists, table)
+}
@@ -1,26 +1,66 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"sync"
+
 	"github.com/adrianolaselva/dataql/pkg/storage"
 	_ "github.com/mattn/go-sqlite3"
-	"strings"
 )
 
 const (
 	sqlCreateTableTemplate        = "CREATE TABLE IF NOT EXISTS %s (%s\n);"
 	sqlInsertTemplate             = "INSERT INTO %s (%s) VALUES (%s);"
-	sqlInsertDefaultTableTemplate = "INSERT INTO `schemas` (`id`, `name`, `columns`, `total_columns`) VALUES ((select count(1)+1 FROM `schemas`),?,?,?);"
-	sqlShowTablesTemplate         = "select * from `schemas`;"
-	sqlDefaultTableTemplate       = "CREATE TABLE IF NOT EXISTS `schemas` (`id` INTEGER, `name` text, `columns` text, `total_columns` INTEGER);"
+	sqlInsertDefaultTableTemplate = `INSERT INTO "schemas" ("id", "name", "columns", "total_columns") VALUES ((SELECT COALESCE(MAX(id), 0)+1 FROM "schemas"),?,?,?);`
+	sqlDefaultTableTemplate       = `CREATE TABLE IF NOT EXISTS "schemas" ("id" INTEGER, "name" TEXT, "columns" TEXT, "total_columns" INTEGER);`
+	sqlTableExistsTemplate        = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ? COLLATE NOCASE;"
+	sqlDropTableTemplate          = "DROP TABLE %s;"
+	sqlDropTableIfExistsTemplate  = "DROP TABLE IF EXISTS %s;"
+	sqlRenameTableTemplate        = "ALTER TABLE %s RENAME TO %s;"
+	sqlRenameDefaultTableTemplate = `UPDATE "schemas" SET "name" = ? WHERE "name" = ?;`
+	sqlDeleteDefaultTableTemplate = `DELETE FROM "schemas" WHERE "name" = ?;`
 	dataSourceNameDefault         = ":memory:"
 )
 
+// sqlShowTablesTemplate lists the tables of "schemas" that still exist, then
+// the tables and views of sqlite_master created with SQL
+const sqlShowTablesTemplate = `SELECT "id", "name", "columns", "total_columns" FROM "schemas"
+WHERE "name" IN (SELECT name FROM sqlite_master WHERE type IN ('table', 'view'))
+UNION ALL
+SELECT (SELECT COALESCE(MAX(id), 0) FROM "schemas") + row_number() OVER (ORDER BY m.name), m.name,
+	'[' || group_concat('"' || p.name || '"', ',') || ']', COUNT(*)
+FROM sqlite_master m JOIN pragma_table_info(m.name) p
+WHERE m.type IN ('table', 'view') AND m.name NOT LIKE 'sqlite_%'
+	AND m.name <> 'schemas' AND m.name NOT IN (SELECT "name" FROM "schemas")
+GROUP BY m.name
+ORDER BY 1;`
+
 type sqLiteStorage struct {
 	db *sql.DB
+
+	// Transaction of the imported rows, committed every commitRows rows
+	importTx   *sql.Tx
+	importRows int
+	commitRows int
+	importMu   sync.Mutex
+
+	ifExists storage.IfExists // See SetIfExists
+	built    map[string]bool  // Tables created or prepared by the imports of the session
+	staged   map[string]stagedTable
 }
 
+// stagedTable is a table imported with --if-exists replace: its rows go to
+// the staging table until ReplaceTables swaps it in
+type stagedTable struct {
+	name    string
+	staging string
+}
+
+// NewSqLiteStorage creates a SQLite storage, in memory when datasource is
+// empty and in the database file at datasource otherwise
 func NewSqLiteStorage(datasource string) (storage.Storage, error) {
 	if datasource == "" {
 		datasource = dataSourceNameDefault
@@ -30,28 +70,48 @@ func NewSqLiteStorage(datasource string) (storage.Storage, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open connection with sqlite3: %w", err)
 	}
+	// Each connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
 
 	return &sqLiteStorage{db: db}, nil
 }
 
-// BuildStructure build table creation statement
+// BuildStructure build table creation statement with TEXT columns
 func (s *sqLiteStorage) BuildStructure(tableName string, columns []string) error {
+	columnDefs := make([]storage.ColumnDef, len(columns))
+	for i, col := range columns {
+		columnDefs[i] = storage.ColumnDef{Name: col, Type: storage.TypeVarchar}
+	}
+	return s.BuildStructureWithTypes(tableName, columnDefs)
+}
+
+// BuildStructureWithTypes creates a table with the SQLite type affinities
+// of the inferred column types, so numbers compare as numbers
+func (s *sqLiteStorage) BuildStructureWithTypes(tableName string, columns []storage.ColumnDef) error {
 	var tableAttrsRaw strings.Builder
 
 	// Create quoted column names for SQL but don't modify the original slice
 	quotedColumns := make([]string, len(columns))
-	for i, v := range columns {
-		quotedColumns[i] = fmt.Sprintf("`%v`", v)
+	for i, col := range columns {
+		quotedColumns[i] = storage.QuoteIdentifier(col.Name)
 	}
 
-	for i, v := range quotedColumns {
-		tableAttrsRaw.WriteString(fmt.Sprintf("\n\t%s text", v))
-		if len(quotedColumns)-1 > i {
+	for i, col := range columns {
+		tableAttrsRaw.WriteString(fmt.Sprintf("\n\t%s %s", quotedColumns[i], affinity(col.Type)))
+		if len(columns)-1 > i {
 			tableAttrsRaw.WriteString(",")
 		}
 	}
 
-	query := fmt.Sprintf(sqlCreateTableTemplate, tableName, tableAttrsRaw.String())
+	if err := s.flushImport(); err != nil {
+		return err
+	}
+	if err := s.prepareTable(tableName); err != nil {
+		return err
+	}
+	table := s.target(tableName)
+
+	query := fmt.Sprintf(sqlCreateTableTemplate, storage.QuoteIdentifier(table), tableAttrsRaw.String())
 	if _, err := s.db.Exec(query); err != nil {
 		return fmt.Errorf("failed to create structure: %w (sql: %s)", err, query)
 	}
@@ -61,33 +121,235 @@ func (s *sqLiteStorage) BuildStructure(tableName string, columns []string) error
 	}
 
 	columnsRaw := fmt.Sprintf("[%v]", strings.Join(quotedColumns, ","))
-	if _, err := s.db.Exec(sqlInsertDefaultTableTemplate, []any{tableName, columnsRaw, len(columns)}...); err != nil {
+	if _, err := s.db.Exec(sqlInsertDefaultTableTemplate, table, columnsRaw, len(columns)); err != nil {
 		return fmt.Errorf("failed to execute insert: %w", err)
 	}
 
 	return nil
 }
 
-// InsertRow build insert create statement
+// SetIfExists sets what imports do with the tables that were in the
+// storage before: append to them (the default), replace or fail
+func (s *sqLiteStorage) SetIfExists(ifExists storage.IfExists) {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+	s.ifExists = ifExists
+}
+
+// prepareTable applies --if-exists to tableName the first time an import of
+// the session creates it, when it was in the storage before
+func (s *sqLiteStorage) prepareTable(tableName string) error {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+
+	key := strings.ToLower(tableName)
+	if s.built[key] {
+		return nil
+	}
+	if s.built == nil {
+		s.built = make(map[string]bool)
+	}
+	s.built[key] = true
+	if s.ifExists != storage.IfExistsReplace && s.ifExists != storage.IfExistsFail {
+		return nil
+	}
+
+	var count int
+	if err := s.db.QueryRow(sqlTableExistsTemplate, tableName).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check table %s: %w", tableName, err)
+	}
+	if count == 0 {
+		return nil
+	}
+	if s.ifExists == storage.IfExistsFail {
+		return storage.TableExistsError(tableName)
+	}
+
+	// The table is replaced once the import succeeded, see ReplaceTables
+	staging := storage.ReplaceStagingTable(tableName)
+	if _, err := s.db.Exec(fmt.Sprintf(sqlDropTableIfExistsTemplate, storage.QuoteIdentifier(staging))); err != nil {
+		return fmt.Errorf("failed to replace table %s: %w", tableName, err)
+	}
+	if s.staged == nil {
+		s.staged = make(map[string]stagedTable)
+	}
+	s.staged[key] = stagedTable{name: tableName, staging: staging}
+	return nil
+}
+
+// target returns the table the rows imported into tableName are written
+// to: its staging table while it is being replaced
+func (s *sqLiteStorage) target(tableName string) string {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+	if staged, ok := s.staged[strings.ToLower(tableName)]; ok {
+		return staged.staging
+	}
+	return tableName
+}
+
+// ReplaceTables drops the tables imported with --if-exists replace and
+// renames their staging tables, in a single transaction
+func (s *sqLiteStorage) ReplaceTables() error {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+	if err := s.commitImport(); err != nil {
+		return err
+	}
+	if len(s.staged) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	for _, staged := range s.staged {
+		if err := replaceTable(tx, staged); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to replace tables: %w", err)
+	}
+	s.staged = nil
+	return nil
+}
+
+// replaceTable swaps staged.staging in for staged.name
+func replaceTable(tx *sql.Tx, staged stagedTable) error {
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{query: fmt.Sprintf(sqlDropTableTemplate, storage.QuoteIdentifier(staged.name))},
+		{query: fmt.Sprintf(sqlRenameTableTemplate, storage.QuoteIdentifier(staged.staging), storage.QuoteIdentifier(staged.name))},
+		// The columns recorded for the dropped table no longer apply
+		{query: sqlDefaultTableTemplate},
+		{query: sqlDeleteDefaultTableTemplate, args: []any{staged.name}},
+		{query: sqlRenameDefaultTableTemplate, args: []any{staged.name, staged.staging}},
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement.query, statement.args...); err != nil {
+			return fmt.Errorf("failed to replace table %s: %w", staged.name, err)
+		}
+	}
+	return nil
+}
+
+// dropStaged drops the staging tables of an import that did not succeed,
+// keeping the tables they were to replace
+func (s *sqLiteStorage) dropStaged() {
+	for _, staged := range s.staged {
+		_, _ = s.db.Exec(fmt.Sprintf(sqlDropTableIfExistsTemplate, storage.QuoteIdentifier(staged.staging)))
+		_, _ = s.db.Exec(sqlDeleteDefaultTableTemplate, staged.staging)
+	}
+	s.staged = nil
+}
+
+// InsertRow inserts a row; rows are committed together every commit
+// interval rows (see SetBatch) and before the next statement runs
 func (s *sqLiteStorage) InsertRow(tableName string, columns []string, values []any) error {
+	tableName = s.target(tableName)
+
 	// Quote column names for SQL
 	quotedColumns := make([]string, len(columns))
 	for i, col := range columns {
-		quotedColumns[i] = fmt.Sprintf("`%s`", col)
+		quotedColumns[i] = storage.QuoteIdentifier(col)
 	}
 	columnsRaw := strings.Join(quotedColumns, ", ")
 	paramsRaw := strings.Repeat("?, ", len(columns))
-	query := fmt.Sprintf(sqlInsertTemplate, tableName, columnsRaw, paramsRaw[:len(paramsRaw)-2])
+	query := fmt.Sprintf(sqlInsertTemplate, storage.QuoteIdentifier(tableName), columnsRaw, paramsRaw[:len(paramsRaw)-2])
 
-	if _, err := s.db.Exec(query, values...); err != nil {
+	if err := s.insertRow(query, values); err != nil {
 		return fmt.Errorf("failed to execute insert: %w (sql: %s)", err, query)
 	}
 
 	return nil
 }
 
+// insertRow runs an INSERT in the import transaction
+func (s *sqLiteStorage) insertRow(query string, values []any) error {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+
+	if s.importTx == nil {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin import transaction: %w", err)
+		}
+		s.importTx = tx
+	}
+	if _, err := s.importTx.Exec(query, values...); err != nil {
+		_ = s.importTx.Rollback()
+		s.importTx, s.importRows = nil, 0
+		return err
+	}
+
+	s.importRows++
+	commitInterval := s.commitRows
+	if commitInterval < 1 {
+		commitInterval = storage.DefaultCommitInterval
+	}
+	if s.importRows >= commitInterval {
+		return s.commitImport()
+	}
+	return nil
+}
+
+// commitImport commits the imported rows. The caller holds importMu.
+func (s *sqLiteStorage) commitImport() error {
+	if s.importTx == nil {
+		return nil
+	}
+	err := s.importTx.Commit()
+	s.importTx, s.importRows = nil, 0
+	if err != nil {
+		return fmt.Errorf("failed to commit imported rows: %w", err)
+	}
+	return nil
+}
+
+// flushImport commits the imported rows before another statement runs; the
+// import transaction holds the only connection
+func (s *sqLiteStorage) flushImport() error {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+	return s.commitImport()
+}
+
+// SetBatch sets how many imported rows are committed together. SQLite
+// writes every row when it is inserted, so batchSize does not apply.
+func (s *sqLiteStorage) SetBatch(_, commitInterval int) {
+	s.importMu.Lock()
+	defer s.importMu.Unlock()
+	s.commitRows = commitInterval
+}
+
+// InsertRowWithCoercion inserts a row, converting the values to the column
+// types; values that do not convert become NULL
+func (s *sqLiteStorage) InsertRowWithCoercion(tableName string, columns []string, values []any, columnDefs []storage.ColumnDef) error {
+	coercedValues := make([]any, len(values))
+	for i, val := range values {
+		coercedValues[i] = val
+		if i < len(columnDefs) {
+			converted, ok := storage.TryConvertValue(val, columnDefs[i].Type)
+			if !ok {
+				converted = nil
+			}
+			coercedValues[i] = converted
+		}
+	}
+
+	return s.InsertRow(tableName, columns, coercedValues)
+}
+
 // Query execute statements
 func (s *sqLiteStorage) Query(cmd string) (*sql.Rows, error) {
+	if err := s.flushImport(); err != nil {
+		return nil, err
+	}
 	rows, err := s.db.Query(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -96,7 +358,36 @@ func (s *sqLiteStorage) Query(cmd string) (*sql.Rows, error) {
 	return rows, nil
 }
 
+// QueryContext executes the given SQL query, interrupting it when ctx is done
+func (s *sqLiteStorage) QueryContext(ctx context.Context, cmd string) (*sql.Rows, error) {
+	if err := s.flushImport(); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return rows, nil
+}
+
+// ExecContext executes a statement that returns no rows, interrupting it when ctx is done
+func (s *sqLiteStorage) ExecContext(ctx context.Context, cmd string) error {
+	if err := s.flushImport(); err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, cmd); err != nil {
+		return fmt.Errorf("failed to execute statement: %w", err)
+	}
+
+	return nil
+}
+
+// ShowTables returns the metadata about all loaded tables
 func (s *sqLiteStorage) ShowTables() (*sql.Rows, error) {
+	if err := s.flushImport(); err != nil {
+		return nil, err
+	}
 	rows, err := s.db.Query(sqlShowTablesTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
@@ -105,12 +396,29 @@ func (s *sqLiteStorage) ShowTables() (*sql.Rows, error) {
 	return rows, nil
 }
 
-// Close execute in defer
+// Close commits the imported rows and closes the database
 func (s *sqLiteStorage) Close() error {
+	importErr := s.flushImport()
+	s.dropStaged()
+
 	err := s.db.Close()
 	if err != nil {
 		return fmt.Errorf("failed to close sqlite3 connection: %w", err)
 	}
 
-	return nil
+	return importErr
+}
+
+// affinity returns the SQLite column type of an inferred type. HUGEINT
+// and DECIMAL columns, inferred for numbers a double would round, are TEXT
+// so their digits are kept.
+func affinity(t storage.DataType) string {
+	switch t {
+	case storage.TypeBigInt, storage.TypeBoolean:
+		return "INTEGER"
+	case storage.TypeDouble:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
 }
@@ -1,9 +1,12 @@
 package sqlite_test
 
 import (
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
 	"github.com/adrianolaselva/dataql/pkg/storage/sqlite"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 func TestShouldBuildStructureWithSuccess(t *testing.T) {
@@ -139,3 +142,130 @@ func TestShouldBuildStructureWithSuccess(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestShouldBuildStructureWithTypes(t *testing.T) {
+	st, err := sqlite.NewSqLiteStorage("")
+	assert.NoError(t, err)
+	defer st.Close()
+
+	typed, ok := st.(storage.TypedStorage)
+	assert.True(t, ok)
+
+	columns := []storage.ColumnDef{
+		{Name: "id", Type: storage.TypeBigInt},
+		{Name: "Unit Price", Type: storage.TypeDouble},
+		{Name: "amount", Type: storage.DecimalType(20, 2)},
+		{Name: "name", Type: storage.TypeVarchar},
+	}
+	assert.NoError(t, typed.BuildStructureWithTypes("order items", columns))
+
+	names := []string{"id", "Unit Price", "amount", "name"}
+	assert.NoError(t, typed.InsertRowWithCoercion("order items", names, []any{"10", "2.5", "123456789012345678.25", "a"}, columns))
+	assert.NoError(t, typed.InsertRowWithCoercion("order items", names, []any{"9", "x", "1", "b"}, columns))
+
+	rows, err := st.Query(`SELECT name, typeof("Unit Price"), typeof(amount) FROM "order items" WHERE id > 9`)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	assert.True(t, rows.Next())
+	var name, priceType, amountType string
+	assert.NoError(t, rows.Scan(&name, &priceType, &amountType))
+	assert.Equal(t, "a", name)
+	assert.Equal(t, "real", priceType)
+	assert.Equal(t, "text", amountType, "decimals a double would round are kept as text")
+	assert.False(t, rows.Next())
+}
+
+func TestImportCommitInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	st, err := sqlite.NewSqLiteStorage(path)
+	assert.NoError(t, err)
+	storage.SetBatch(st, 0, 2)
+
+	assert.NoError(t, st.BuildStructure("users", []string{"id"}))
+	for _, id := range []string{"1", "2", "3"} {
+		assert.NoError(t, st.InsertRow("users", []string{"id"}, []any{id}))
+	}
+	// The third row is committed before a new table is created or a query runs
+	assert.NoError(t, st.BuildStructure("other", []string{"id"}))
+	assert.NoError(t, st.InsertRow("users", []string{"id"}, []any{"4"}))
+	assert.NoError(t, st.Close())
+
+	st, err = sqlite.NewSqLiteStorage(path)
+	assert.NoError(t, err)
+	defer st.Close()
+	rows, err := st.Query(`SELECT COUNT(*) FROM users`)
+	assert.NoError(t, err)
+	defer rows.Close()
+	var count int
+	assert.True(t, rows.Next())
+	assert.NoError(t, rows.Scan(&count))
+	assert.Equal(t, 4, count)
+}
+
+func TestIfExists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	load := func(ifExists storage.IfExists, table string, ids ...string) error {
+		st, err := sqlite.NewSqLiteStorage(path)
+		assert.NoError(t, err)
+		defer st.Close()
+		storage.SetIfExists(st, ifExists)
+
+		// Every id is imported from its own file into the same table
+		for _, id := range ids {
+			if err := st.BuildStructure(table, []string{"id"}); err != nil {
+				return err
+			}
+			assert.NoError(t, st.InsertRow(table, []string{"id"}, []any{id}))
+		}
+		return storage.ReplaceTables(st)
+	}
+	count := func() int {
+		st, err := sqlite.NewSqLiteStorage(path)
+		assert.NoError(t, err)
+		defer st.Close()
+		rows, err := st.Query(`SELECT COUNT(*) FROM sales`)
+		assert.NoError(t, err)
+		defer rows.Close()
+		var count int
+		assert.True(t, rows.Next())
+		assert.NoError(t, rows.Scan(&count))
+		return count
+	}
+
+	assert.NoError(t, load(storage.IfExistsAppend, "sales", "1"))
+	assert.NoError(t, load(storage.IfExistsAppend, "sales", "2", "3"))
+	assert.Equal(t, 3, count())
+
+	assert.NoError(t, load(storage.IfExistsReplace, "Sales", "4", "5"))
+	assert.Equal(t, 2, count())
+
+	assert.ErrorIs(t, load(storage.IfExistsFail, "sales", "6"), storage.ErrTableExists)
+	assert.NoError(t, load(storage.IfExistsFail, "other", "1", "2"))
+	assert.Equal(t, 2, count())
+
+	// An import that fails before ReplaceTables keeps the table it replaces
+	st, err := sqlite.NewSqLiteStorage(path)
+	assert.NoError(t, err)
+	storage.SetIfExists(st, storage.IfExistsReplace)
+	assert.NoError(t, st.BuildStructure("sales", []string{"id"}))
+	assert.NoError(t, st.InsertRow("sales", []string{"id"}, []any{"7"}))
+	assert.NoError(t, st.Close())
+	assert.Equal(t, 2, count())
+
+	st, err = sqlite.NewSqLiteStorage(path)
+	assert.NoError(t, err)
+	defer st.Close()
+	rows, err := st.ShowTables()
+	assert.NoError(t, err)
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var id, columns int
+		var name string
+		var raw string
+		assert.NoError(t, rows.Scan(&id, &name, &raw, &columns))
+		tables = append(tables, name)
+	}
+	assert.NotContains(t, tables, storage.ReplaceStagingTable("sales"))
+}
@@ -1,8 +1,13 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -11,12 +16,64 @@ import (
 type DataType string
 
 const (
-	TypeVarchar DataType = "VARCHAR"
-	TypeBigInt  DataType = "BIGINT"
-	TypeDouble  DataType = "DOUBLE"
-	TypeBoolean DataType = "BOOLEAN"
+	TypeVarchar     DataType = "VARCHAR"
+	TypeBigInt      DataType = "BIGINT"
+	TypeHugeInt     DataType = "HUGEINT" // Integers beyond int64, up to 38 digits
+	TypeDouble      DataType = "DOUBLE"
+	TypeBoolean     DataType = "BOOLEAN"
+	TypeTimestampTZ DataType = "TIMESTAMPTZ" // Instants, e.g. timestamps read in --input-tz
 )
 
+// maxDecimalDigits is the largest precision of DECIMAL and HUGEINT values
+const maxDecimalDigits = 38
+
+// decimalPattern matches numbers in plain notation, capturing the integer
+// and fractional digits
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d*)(?:\.(\d*))?$`)
+
+// DecimalType returns the DECIMAL type with the given precision and scale
+func DecimalType(precision, scale int) DataType {
+	return DataType(fmt.Sprintf("DECIMAL(%d,%d)", precision, scale))
+}
+
+// IsDecimal reports whether t is a DECIMAL type
+func (t DataType) IsDecimal() bool {
+	return strings.HasPrefix(string(t), "DECIMAL(")
+}
+
+// IsNumeric reports whether t is a numeric type, whose empty values are NULL
+func (t DataType) IsNumeric() bool {
+	return t == TypeBigInt || t == TypeHugeInt || t == TypeDouble || t.IsDecimal()
+}
+
+// decimalDigits returns the number of integer and fractional digits of a
+// number in plain notation, e.g. 2 and 3 for -12.500
+func decimalDigits(s string) (integer, scale int, ok bool) {
+	m := decimalPattern.FindStringSubmatch(s)
+	if m == nil || m[1]+m[2] == "" {
+		return 0, 0, false
+	}
+	integer = len(strings.TrimLeft(m[1], "0"))
+	if integer == 0 {
+		integer = 1
+	}
+	return integer, len(m[2]), true
+}
+
+// exactAsDouble reports whether a number survives the conversion to float64,
+// i.e. the shortest decimal form of the float64 has the same value
+func exactAsDouble(s string, f float64) bool {
+	if math.IsInf(f, 0) {
+		return false
+	}
+	want, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return true
+	}
+	got, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	return ok && want.Cmp(got) == 0
+}
+
 // ColumnDef defines a column with its name and inferred type
 type ColumnDef struct {
 	Name string
@@ -40,14 +97,63 @@ type TypedStorage interface {
 	InsertRowWithCoercion(tableName string, columns []string, values []any, columnDefs []ColumnDef) error
 }
 
-// InferType detects the most appropriate data type for a value
+// SnapshotStorage is an optional interface for storage implementations
+// that can persist all of their tables into a standalone database file
+type SnapshotStorage interface {
+	Storage
+	SaveTo(path string) error
+}
+
+// TransactionStorage is an optional interface for storage implementations
+// that support explicit transactions (BEGIN/COMMIT/ROLLBACK)
+type TransactionStorage interface {
+	Storage
+	Begin() error
+	Commit() error
+	Rollback() error
+	InTransaction() bool
+}
+
+// ContextStorage is an optional interface for storage implementations
+// that can cancel a running statement when its context is done
+type ContextStorage interface {
+	Storage
+	QueryContext(ctx context.Context, cmd string) (*sql.Rows, error)
+	ExecContext(ctx context.Context, cmd string) error
+}
+
+// ScalarFunction is a Go function callable from SQL. It accepts any number
+// of arguments of any type; a NULL argument gives a NULL result.
+type ScalarFunction struct {
+	Name   string
+	Result DataType
+	Call   func(args []any) (any, error)
+}
+
+// FunctionStorage is an optional interface for storage implementations
+// that can register scalar functions implemented in Go
+type FunctionStorage interface {
+	Storage
+	RegisterFunction(fn ScalarFunction) error
+}
+
+// InferType detects the most appropriate data type for a value. Numbers
+// that float64 cannot hold exactly, such as IDs beyond int64 or decimals
+// with more than 15 significant digits, get HUGEINT or DECIMAL types.
 func InferType(value any) DataType {
 	if value == nil {
 		return TypeVarchar
 	}
 
 	switch v := value.(type) {
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+	case uint64:
+		if v > math.MaxInt64 {
+			return TypeHugeInt
+		}
+		return TypeBigInt
+	case uint:
+		return InferType(uint64(v))
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
 		return TypeBigInt
 	case float32, float64:
 		return TypeDouble
@@ -79,17 +185,85 @@ func inferTypeFromString(s string) DataType {
 		return TypeBigInt
 	}
 
+	integer, scale, plain := decimalDigits(s)
+	if plain && scale == 0 && !strings.Contains(s, ".") {
+		// Integers beyond int64
+		if integer <= maxDecimalDigits {
+			return TypeHugeInt
+		}
+		return TypeVarchar
+	}
+
 	// Check for float
-	if _, err := strconv.ParseFloat(s, 64); err == nil {
-		return TypeDouble
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil && !plain {
+		return TypeVarchar
+	}
+	if plain && (err != nil || !exactAsDouble(s, f)) {
+		// Decimals that float64 would round
+		if integer+scale <= maxDecimalDigits {
+			return DecimalType(integer+scale, scale)
+		}
+		return TypeVarchar
+	}
+	return TypeDouble
+}
+
+// numericColumn tracks the numbers of a column to pick a type holding all
+// of them exactly
+type numericColumn struct {
+	hugeInt  bool // Integers beyond int64
+	fraction bool // Numbers with a fractional part or exponent
+	exact    bool // Numbers that float64 would round
+	notation bool // Numbers in exponent notation, which DECIMAL cannot take
+	integer  int  // Most integer digits
+	scale    int  // Most fractional digits
+}
+
+// add records a number of the column
+func (n *numericColumn) add(value any, t DataType) {
+	switch {
+	case t == TypeHugeInt:
+		n.hugeInt = true
+	case t.IsDecimal():
+		n.fraction, n.exact = true, true
+	case t == TypeDouble:
+		n.fraction = true
 	}
 
+	s, ok := value.(string)
+	if !ok {
+		s = fmt.Sprint(value)
+	}
+	integer, scale, plain := decimalDigits(strings.TrimSpace(s))
+	if !plain {
+		n.notation = n.notation || t == TypeDouble
+		return
+	}
+	n.integer = max(n.integer, integer)
+	n.scale = max(n.scale, scale)
+}
+
+// dataType returns the type of the column: BIGINT or HUGEINT for integers,
+// DOUBLE for numbers float64 holds, DECIMAL otherwise, or VARCHAR when the
+// numbers fit no DECIMAL
+func (n *numericColumn) dataType() DataType {
+	switch {
+	case !n.fraction && n.hugeInt:
+		return TypeHugeInt
+	case !n.fraction:
+		return TypeBigInt
+	case !n.hugeInt && !n.exact:
+		return TypeDouble
+	case !n.notation && n.integer+n.scale <= maxDecimalDigits:
+		return DecimalType(n.integer+n.scale, n.scale)
+	}
 	return TypeVarchar
 }
 
 // InferColumnTypes analyzes sample data to infer the best type for each column
 // It uses the most restrictive type that can represent all values:
-// BIGINT -> DOUBLE -> VARCHAR (BOOLEAN is special-cased)
+// BIGINT -> HUGEINT -> DOUBLE or DECIMAL -> VARCHAR (BOOLEAN is special-cased)
 func InferColumnTypes(columns []string, sampleRows [][]any) []ColumnDef {
 	if len(sampleRows) == 0 {
 		// No data to analyze, default to VARCHAR
@@ -106,6 +280,7 @@ func InferColumnTypes(columns []string, sampleRows [][]any) []ColumnDef {
 	for i := range colTypes {
 		colTypes[i] = TypeBigInt // Start with most restrictive numeric type
 	}
+	numbers := make([]numericColumn, len(columns))
 
 	// Track if we've seen any non-null values
 	hasValues := make([]bool, len(columns))
@@ -141,22 +316,17 @@ func InferColumnTypes(columns []string, sampleRows [][]any) []ColumnDef {
 				continue
 			}
 
-			// Type precedence: BIGINT -> DOUBLE -> VARCHAR
+			// Numbers keep the column numeric; the numeric type is picked
+			// once all the numbers are seen
 			switch colTypes[i] {
 			case TypeBigInt:
-				if inferredType == TypeDouble {
-					colTypes[i] = TypeDouble
-				} else if inferredType == TypeVarchar {
-					colTypes[i] = TypeVarchar
-				}
-			case TypeDouble:
-				if inferredType == TypeVarchar {
+				if inferredType.IsNumeric() {
+					numbers[i].add(val, inferredType)
+				} else {
 					colTypes[i] = TypeVarchar
 				}
 			case TypeBoolean:
-				if inferredType != TypeBoolean {
-					colTypes[i] = TypeVarchar
-				}
+				colTypes[i] = TypeVarchar
 			}
 			// VARCHAR stays VARCHAR
 		}
@@ -166,6 +336,9 @@ func InferColumnTypes(columns []string, sampleRows [][]any) []ColumnDef {
 	result := make([]ColumnDef, len(columns))
 	for i, col := range columns {
 		t := colTypes[i]
+		if t == TypeBigInt {
+			t = numbers[i].dataType()
+		}
 		if !hasValues[i] {
 			t = TypeVarchar // No data seen, default to VARCHAR
 		}
@@ -184,15 +357,21 @@ func TryConvertValue(value any, expectedType DataType) (any, bool) {
 
 	// Handle empty strings as NULL for numeric/boolean types
 	if str, ok := value.(string); ok && strings.TrimSpace(str) == "" {
-		if expectedType == TypeBigInt || expectedType == TypeDouble || expectedType == TypeBoolean {
+		if expectedType.IsNumeric() || expectedType == TypeBoolean {
 			return nil, true
 		}
 		return str, true
 	}
 
+	if expectedType.IsDecimal() {
+		return tryConvertToDecimal(value)
+	}
+
 	switch expectedType {
 	case TypeBigInt:
 		return tryConvertToBigInt(value)
+	case TypeHugeInt:
+		return tryConvertToHugeInt(value)
 	case TypeDouble:
 		return tryConvertToDouble(value)
 	case TypeBoolean:
@@ -236,11 +415,16 @@ func tryConvertToBigInt(value any) (any, bool) {
 	case string:
 		s := strings.TrimSpace(v)
 		// Try parsing as integer first
-		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err == nil {
 			return i, true
 		}
+		if errors.Is(err, strconv.ErrRange) {
+			// An integer beyond int64 would wrap around through float64
+			return nil, false
+		}
 		// Try parsing as float and truncate
-		if f, err := strconv.ParseFloat(s, 64); err == nil {
+		if f, err := strconv.ParseFloat(s, 64); err == nil && f >= math.MinInt64 && f < math.MaxInt64 {
 			return int64(f), true
 		}
 		return nil, false
@@ -253,6 +437,59 @@ func tryConvertToBigInt(value any) (any, bool) {
 	return nil, false
 }
 
+// tryConvertToHugeInt attempts to convert a value to an integer of up to 38
+// digits. Integers beyond int64 are returned as strings, cast by DuckDB.
+func tryConvertToHugeInt(value any) (any, bool) {
+	switch v := value.(type) {
+	case uint64:
+		if v > math.MaxInt64 {
+			return strconv.FormatUint(v, 10), true
+		}
+		return int64(v), true
+	case string:
+		s := strings.TrimSpace(v)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, true
+		}
+		if integer, scale, ok := decimalDigits(s); ok && scale == 0 && !strings.Contains(s, ".") && integer <= maxDecimalDigits {
+			return strings.TrimPrefix(s, "+"), true
+		}
+		return nil, false
+	}
+	return tryConvertToBigInt(value)
+}
+
+// tryConvertToDecimal attempts to convert a value to a number in plain
+// notation, cast exactly to the DECIMAL column by DuckDB
+func tryConvertToDecimal(value any) (any, bool) {
+	switch v := value.(type) {
+	case string:
+		s := strings.TrimSpace(v)
+		if _, _, ok := decimalDigits(s); ok {
+			return strings.TrimPrefix(s, "+"), true
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return strconv.FormatFloat(f, 'f', -1, 64), true
+		}
+		return nil, false
+	case float32:
+		return tryConvertToDecimal(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, false
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v), true
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "0", true
+	}
+	return nil, false
+}
+
 // tryConvertToDouble attempts to convert a value to float64
 func tryConvertToDouble(value any) (any, bool) {
 	switch v := value.(type) {
@@ -368,3 +368,65 @@ func TestInferColumnTypes_MultipleColumns(t *testing.T) {
 	assert.Equal(t, TypeBoolean, result[2].Type) // active - all boolean
 	assert.Equal(t, TypeVarchar, result[3].Type) // name - strings
 }
+
+// ============================================
+// Tests for exact numbers
+// ============================================
+
+func TestInferType_ExactNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected DataType
+	}{
+		{"int64 beyond float64 precision", "9007199254740993", TypeBigInt},
+		{"integer beyond int64", "12345678901234567890", TypeHugeInt},
+		{"uint64 beyond int64", uint64(math.MaxUint64), TypeHugeInt},
+		{"decimal float64 keeps", "19.99", TypeDouble},
+		{"decimal float64 rounds", "12345678901234567.891", DecimalType(20, 3)},
+		{"integer beyond DECIMAL stays text", "123456789012345678901234567890123456789", TypeVarchar},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, InferType(tt.value))
+		})
+	}
+}
+
+func TestInferColumnTypes_ExactNumbers(t *testing.T) {
+	columns := []string{"id", "amount", "price"}
+	sampleRows := [][]any{
+		{"1", "0.10", "9.5"},
+		{"12345678901234567890", "12345678901234567.891", "10"},
+		{"", "2", ""},
+	}
+
+	result := InferColumnTypes(columns, sampleRows)
+	assert.Equal(t, TypeHugeInt, result[0].Type)
+	assert.Equal(t, DecimalType(20, 3), result[1].Type)
+	assert.Equal(t, TypeDouble, result[2].Type)
+}
+
+func TestTryConvertValue_ExactNumbers(t *testing.T) {
+	got, ok := TryConvertValue("12345678901234567890", TypeHugeInt)
+	assert.True(t, ok)
+	assert.Equal(t, "12345678901234567890", got)
+
+	got, ok = TryConvertValue("42", TypeHugeInt)
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), got)
+
+	got, ok = TryConvertValue("12345678901234567.891", DecimalType(20, 3))
+	assert.True(t, ok)
+	assert.Equal(t, "12345678901234567.891", got)
+
+	got, ok = TryConvertValue("", DecimalType(20, 3))
+	assert.True(t, ok)
+	assert.Nil(t, got)
+
+	// A BIGINT column does not silently round values beyond int64
+	got, ok = TryConvertValue("12345678901234567890", TypeBigInt)
+	assert.False(t, ok)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,88 @@
+// Package timezone resolves the time zones given to --input-tz and
+// --output-tz and reads text timestamps in them, so naive local times and
+// UTC logs can be compared as instants.
+package timezone
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	// Zone names resolve on machines without a time zone database
+	_ "time/tzdata"
+)
+
+// offsetPattern matches fixed UTC offsets such as +02:00, -0300 or UTC+5
+var offsetPattern = regexp.MustCompile(`^(?:UTC|GMT)?([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+// layouts are the timestamp layouts read by Parse. Fractional seconds are
+// accepted after the seconds of any of them.
+var layouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05Z07",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04Z0700",
+	"2006-01-02T15:04Z07",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+}
+
+// Load returns the location named by an IANA zone (America/Sao_Paulo), UTC,
+// Local for the zone of the machine, or a fixed offset (+02:00, -0300,
+// UTC+5). An empty name is UTC.
+func Load(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "UTC") || strings.EqualFold(name, "Z") {
+		return time.UTC, nil
+	}
+	if strings.EqualFold(name, "Local") {
+		return time.Local, nil
+	}
+
+	if match := offsetPattern.FindStringSubmatch(name); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes := 0
+		if match[3] != "" {
+			minutes, _ = strconv.Atoi(match[3])
+		}
+		if hours > 14 || minutes > 59 {
+			return nil, fmt.Errorf("invalid time zone %q: offset out of range", name)
+		}
+		offset := hours*3600 + minutes*60
+		if match[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(fmt.Sprintf("%s%02d:%02d", match[1], hours, minutes), offset), nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q (expected a zone like America/New_York, UTC, Local or an offset like +02:00)", name)
+	}
+	return location, nil
+}
+
+// Parse reads an ISO 8601 timestamp such as 2024-03-10 08:30:00 or
+// 2024-03-10T08:30:00-03:00. Timestamps with a UTC offset or Z keep it;
+// naive ones are wall clock times in location.
+func Parse(value string, location *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > 10 && value[10] == ' ' {
+		value = value[:10] + "T" + value[11:]
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+}
+
+// Localize reads the wall clock of a naive timestamp, which the DuckDB
+// driver returns as UTC, as a time in location
+func Localize(t time.Time, location *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), location)
+}
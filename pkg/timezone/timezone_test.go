@@ -0,0 +1,71 @@
+package timezone_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/timezone"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	for name, offset := range map[string]int{
+		"":        0,
+		"UTC":     0,
+		"+02:00":  2 * 3600,
+		"-0330":   -(3*3600 + 30*60),
+		"UTC+5":   5 * 3600,
+		"GMT-3":   -3 * 3600,
+		"Etc/UTC": 0,
+	} {
+		location, err := timezone.Load(name)
+		require.NoError(t, err, name)
+		_, got := time.Date(2024, 1, 1, 0, 0, 0, 0, location).Zone()
+		assert.Equal(t, offset, got, name)
+	}
+
+	location, err := timezone.Load("America/Sao_Paulo")
+	require.NoError(t, err)
+	assert.Equal(t, "America/Sao_Paulo", location.String())
+
+	for _, name := range []string{"Mars/Olympus", "+25:00", "+02:75"} {
+		_, err := timezone.Load(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParse(t *testing.T) {
+	saoPaulo, err := timezone.Load("America/Sao_Paulo")
+	require.NoError(t, err)
+	instant := time.Date(2024, 3, 10, 11, 30, 0, 0, time.UTC)
+
+	for _, value := range []string{
+		"2024-03-10 08:30:00",
+		"2024-03-10T08:30",
+		"2024-03-10T11:30:00Z",
+		"2024-03-10 12:30:00+01:00",
+		"2024-03-10T06:30:00-0500",
+		"2024-03-10 11:30:00+00",
+	} {
+		got, err := timezone.Parse(value, saoPaulo)
+		require.NoError(t, err, value)
+		assert.True(t, instant.Equal(got), "%s gave %s", value, got)
+	}
+
+	got, err := timezone.Parse("2024-03-10 08:30:00.250", saoPaulo)
+	require.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, got.Sub(instant))
+
+	_, err = timezone.Parse("10/03/2024 08:30", saoPaulo)
+	assert.Error(t, err)
+}
+
+func TestLocalize(t *testing.T) {
+	newYork, err := timezone.Load("America/New_York")
+	require.NoError(t, err)
+
+	// The driver returns naive timestamps as UTC wall clock times
+	naive := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	assert.True(t, time.Date(2024, 7, 1, 13, 0, 0, 0, time.UTC).Equal(timezone.Localize(naive, newYork)))
+}
@@ -0,0 +1,68 @@
+// Package tlsconfig builds the TLS settings of --ca-cert and
+// --insecure-skip-verify for the handlers that connect to remote sources.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Options are the TLS options of remote sources
+type Options struct {
+	CACert             string // PEM file of certificate authorities trusted besides the system ones
+	InsecureSkipVerify bool   // Accept any server certificate
+}
+
+// IsZero reports whether the system defaults are used
+func (o Options) IsZero() bool {
+	return o.CACert == "" && !o.InsecureSkipVerify
+}
+
+// Config returns the TLS configuration of the options, nil when they are
+// the system defaults
+func (o Options) Config() (*tls.Config, error) {
+	if o.IsZero() {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+	if o.CACert != "" {
+		pem, err := os.ReadFile(o.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --ca-cert: %w", err)
+		}
+		// Corporate CAs are added to the system ones, which public
+		// endpoints still need
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("invalid --ca-cert %s: no PEM certificates found", o.CACert)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// Transport returns an HTTP transport using the options. Like the default
+// transport, it connects through the proxies of HTTPS_PROXY, HTTP_PROXY
+// and NO_PROXY.
+func (o Options) Transport() (*http.Transport, error) {
+	config, err := o.Config()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if config != nil {
+		transport.TLSClientConfig = config
+	}
+	return transport, nil
+}
@@ -0,0 +1,62 @@
+package tlsconfig
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCA writes the certificate of server as a PEM file
+func writeCA(t *testing.T, server *httptest.Server) string {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0600))
+	return path
+}
+
+func TestConfig(t *testing.T) {
+	config, err := Options{}.Config()
+	require.NoError(t, err)
+	assert.Nil(t, config)
+
+	config, err = Options{InsecureSkipVerify: true}.Config()
+	require.NoError(t, err)
+	assert.True(t, config.InsecureSkipVerify)
+	assert.Nil(t, config.RootCAs)
+
+	_, err = Options{CACert: filepath.Join(t.TempDir(), "missing.pem")}.Config()
+	assert.ErrorContains(t, err, "invalid --ca-cert")
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0600))
+	_, err = Options{CACert: notPEM}.Config()
+	assert.ErrorContains(t, err, "no PEM certificates found")
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	get := func(opts Options) error {
+		transport, err := opts.Transport()
+		require.NoError(t, err)
+		assert.NotNil(t, transport.Proxy)
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	assert.ErrorContains(t, get(Options{}), "certificate")
+	assert.NoError(t, get(Options{CACert: writeCA(t, server)}))
+	assert.NoError(t, get(Options{InsecureSkipVerify: true}))
+}
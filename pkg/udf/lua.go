@@ -0,0 +1,126 @@
+package udf
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// loadLua runs the script, which must define a global function named after
+// the UDF. Only the base, string, table and math libraries are available:
+// scripts cannot reach the file system, the network or other processes.
+func loadLua(spec Spec) (func(args []any) (any, error), func() error, error) {
+	state := lua.NewState(lua.Options{SkipOpenLibs: true})
+
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		if err := state.CallByParam(lua.P{Fn: state.NewFunction(lib.open), Protect: true}, lua.LString(lib.name)); err != nil {
+			state.Close()
+			return nil, nil, fmt.Errorf("failed to open Lua library: %w", err)
+		}
+	}
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring", "require", "module"} {
+		state.SetGlobal(name, lua.LNil)
+	}
+
+	if err := state.DoFile(spec.Path); err != nil {
+		state.Close()
+		return nil, nil, fmt.Errorf("failed to load %s: %w", spec.Path, err)
+	}
+
+	fn, ok := state.GetGlobal(spec.Name).(*lua.LFunction)
+	if !ok {
+		state.Close()
+		return nil, nil, fmt.Errorf("%s does not define a function named %s", spec.Path, spec.Name)
+	}
+
+	// A Lua state is not safe for concurrent use; DuckDB may call from several threads
+	var mu sync.Mutex
+	call := func(args []any) (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		luaArgs := make([]lua.LValue, len(args))
+		for i, arg := range args {
+			luaArgs[i] = toLua(arg)
+		}
+		if err := state.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, luaArgs...); err != nil {
+			return nil, err
+		}
+		ret := state.Get(-1)
+		state.Pop(1)
+		return fromLua(ret)
+	}
+
+	closeFn := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		state.Close()
+		return nil
+	}
+	return call, closeFn, nil
+}
+
+// toLua converts a SQL value to a Lua value: numbers and booleans keep their
+// type, everything else is passed as a string
+func toLua(value any) lua.LValue {
+	switch v := value.(type) {
+	case nil:
+		return lua.LNil
+	case string:
+		return lua.LString(v)
+	case []byte:
+		return lua.LString(v)
+	case bool:
+		return lua.LBool(v)
+	case int8:
+		return lua.LNumber(v)
+	case int16:
+		return lua.LNumber(v)
+	case int32:
+		return lua.LNumber(v)
+	case int64:
+		return lua.LNumber(v)
+	case int:
+		return lua.LNumber(v)
+	case uint8:
+		return lua.LNumber(v)
+	case uint16:
+		return lua.LNumber(v)
+	case uint32:
+		return lua.LNumber(v)
+	case uint64:
+		return lua.LNumber(v)
+	case float32:
+		return lua.LNumber(v)
+	case float64:
+		return lua.LNumber(v)
+	case time.Time:
+		return lua.LString(v.Format(time.RFC3339Nano))
+	}
+	return lua.LString(fmt.Sprint(value))
+}
+
+// fromLua converts the value returned by a script
+func fromLua(value lua.LValue) (any, error) {
+	switch v := value.(type) {
+	case *lua.LNilType:
+		return nil, nil
+	case lua.LBool:
+		return bool(v), nil
+	case lua.LNumber:
+		return float64(v), nil
+	case lua.LString:
+		return string(v), nil
+	}
+	return nil, fmt.Errorf("returned a Lua %s, expected a string, number, boolean or nil", value.Type())
+}
@@ -0,0 +1,174 @@
+// Package udf loads user-defined scalar functions from Lua scripts and
+// WebAssembly modules, so SQL can call transformations DuckDB lacks:
+//
+//	dataql run -f customers.csv --udf normalize_phone=./udfs/phone.lua \
+//	  -q "SELECT normalize_phone(phone) FROM customers"
+//
+// A function accepts any number of arguments; a NULL argument gives a NULL
+// result without calling it. Its result is VARCHAR unless another type is
+// given after the name (e.g. score:DOUBLE=./score.wasm).
+package udf
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Spec is a function to load, given as name[:TYPE]=path
+type Spec struct {
+	Name   string
+	Result storage.DataType
+	Path   string
+}
+
+// Function is a loaded function, callable until it is closed
+type Function struct {
+	storage.ScalarFunction
+	close func() error
+}
+
+// Close releases the interpreter or runtime of the function
+func (f *Function) Close() error {
+	if f.close == nil {
+		return nil
+	}
+	return f.close()
+}
+
+// ParseSpec parses name[:TYPE]=path
+func ParseSpec(text string) (Spec, error) {
+	idx := strings.Index(text, "=")
+	if idx <= 0 || idx == len(text)-1 {
+		return Spec{}, fmt.Errorf("invalid function %q: expected name=path or name:TYPE=path", text)
+	}
+
+	spec := Spec{Result: storage.TypeVarchar, Path: text[idx+1:]}
+	name := text[:idx]
+	if colon := strings.Index(name, ":"); colon >= 0 {
+		result, err := parseType(name[colon+1:])
+		if err != nil {
+			return Spec{}, fmt.Errorf("invalid function %q: %w", text, err)
+		}
+		spec.Result = result
+		name = name[:colon]
+	}
+	if !namePattern.MatchString(name) {
+		return Spec{}, fmt.Errorf("invalid function name %q: use letters, digits and '_'", name)
+	}
+	spec.Name = name
+	return spec, nil
+}
+
+// ParseSpecs parses several specs
+func ParseSpecs(texts []string) ([]Spec, error) {
+	specs := make([]Spec, 0, len(texts))
+	for _, text := range texts {
+		spec, err := ParseSpec(text)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// Load reads the function from a Lua script (.lua) or a WebAssembly module (.wasm)
+func Load(spec Spec) (*Function, error) {
+	var call func(args []any) (any, error)
+	var closeFn func() error
+	var err error
+
+	switch strings.ToLower(filepath.Ext(spec.Path)) {
+	case ".lua":
+		call, closeFn, err = loadLua(spec)
+	case ".wasm":
+		call, closeFn, err = loadWasm(spec)
+	default:
+		return nil, fmt.Errorf("function %s: unsupported file %s (use a .lua script or a .wasm module)", spec.Name, spec.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("function %s: %w", spec.Name, err)
+	}
+
+	return &Function{
+		ScalarFunction: storage.ScalarFunction{
+			Name:   spec.Name,
+			Result: spec.Result,
+			Call: func(args []any) (any, error) {
+				value, err := call(args)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", spec.Name, err)
+				}
+				value, err = convertResult(value, spec.Result)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", spec.Name, err)
+				}
+				return value, nil
+			},
+		},
+		close: closeFn,
+	}, nil
+}
+
+// parseType maps a SQL type name to a supported result type
+func parseType(name string) (storage.DataType, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "VARCHAR", "TEXT", "STRING":
+		return storage.TypeVarchar, nil
+	case "BIGINT", "INTEGER", "INT":
+		return storage.TypeBigInt, nil
+	case "DOUBLE", "FLOAT":
+		return storage.TypeDouble, nil
+	case "BOOLEAN", "BOOL":
+		return storage.TypeBoolean, nil
+	}
+	return "", fmt.Errorf("unsupported result type %q (use VARCHAR, BIGINT, DOUBLE or BOOLEAN)", name)
+}
+
+// convertResult converts the value returned by a function (nil, string,
+// float64, int64 or bool) to its result type
+func convertResult(value any, result storage.DataType) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch result {
+	case storage.TypeVarchar:
+		if f, ok := value.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1e15 {
+			return fmt.Sprintf("%.0f", f), nil
+		}
+		return fmt.Sprint(value), nil
+	case storage.TypeBigInt:
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case float64:
+			if v != math.Trunc(v) || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("returned %v, which is not an integer", v)
+			}
+			return int64(v), nil
+		}
+	case storage.TypeDouble:
+		switch v := value.(type) {
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+	case storage.TypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case int64:
+			return v != 0, nil
+		}
+	}
+	return nil, fmt.Errorf("returned %T, expected %s", value, result)
+}
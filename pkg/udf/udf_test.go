@@ -0,0 +1,146 @@
+package udf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addWasm exports add(i64, i64) i64
+var addWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	0x01, 0x07, 0x01, 0x60, 0x02, 0x7e, 0x7e, 0x01, 0x7e, // type (i64, i64) -> i64
+	0x03, 0x02, 0x01, 0x00, // function 0 has type 0
+	0x07, 0x07, 0x01, 0x03, 'a', 'd', 'd', 0x00, 0x00, // export "add"
+	0x0a, 0x09, 0x01, 0x07, 0x00, 0x20, 0x00, 0x20, 0x01, 0x7c, 0x0b, // local.get 0, local.get 1, i64.add
+}
+
+// echoWasm exports memory, a bump alloc(len) and echo(ptr, len) returning
+// its argument as ptr<<32 | len
+var echoWasm = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	0x01, 0x0c, 0x02, 0x60, 0x01, 0x7f, 0x01, 0x7f, 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e, // (i32) -> i32, (i32, i32) -> i64
+	0x03, 0x03, 0x02, 0x00, 0x01, // alloc, echo
+	0x05, 0x03, 0x01, 0x00, 0x01, // one page of memory
+	0x06, 0x07, 0x01, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b, // mutable i32 global = 1024
+	0x07, 0x19, 0x03,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x05, 'a', 'l', 'l', 'o', 'c', 0x00, 0x00,
+	0x04, 'e', 'c', 'h', 'o', 0x00, 0x01,
+	0x0a, 0x1a, 0x02,
+	0x0b, 0x00, 0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x0b, // return the global, advanced by len
+	0x0c, 0x00, 0x20, 0x00, 0xad, 0x42, 0x20, 0x86, 0x20, 0x01, 0xad, 0x84, 0x0b, // ptr<<32 | len
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func load(t *testing.T, spec string) *Function {
+	t.Helper()
+	parsed, err := ParseSpec(spec)
+	require.NoError(t, err)
+	fn, err := Load(parsed)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = fn.Close() })
+	return fn
+}
+
+func TestParseSpec(t *testing.T) {
+	spec, err := ParseSpec("normalize_phone=./udfs/phone.wasm")
+	require.NoError(t, err)
+	assert.Equal(t, Spec{Name: "normalize_phone", Result: storage.TypeVarchar, Path: "./udfs/phone.wasm"}, spec)
+
+	spec, err = ParseSpec("score:double=C:/udfs/score.lua")
+	require.NoError(t, err)
+	assert.Equal(t, Spec{Name: "score", Result: storage.TypeDouble, Path: "C:/udfs/score.lua"}, spec)
+
+	for _, text := range []string{"phone", "=phone.lua", "phone=", "1phone=phone.lua", "bad-name=x.lua", "phone:DATE=phone.lua"} {
+		_, err := ParseSpec(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestLoad_Lua(t *testing.T) {
+	script := writeFile(t, "phone.lua", []byte(`
+function normalize_phone(phone)
+  local digits = string.gsub(phone, "%D", "")
+  if #digits ~= 10 then
+    return nil
+  end
+  return "+1" .. digits
+end
+
+function double(n) return n * 2 end
+function is_even(n) return n % 2 == 0 end
+function read_file() return io.open("/etc/passwd") end
+`))
+
+	fn := load(t, "normalize_phone="+script)
+	value, err := fn.Call([]any{"(555) 123-4567"})
+	require.NoError(t, err)
+	assert.Equal(t, "+15551234567", value)
+
+	value, err = fn.Call([]any{"123"})
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	value, err = load(t, "double:BIGINT="+script).Call([]any{int32(21)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), value)
+
+	value, err = load(t, "double="+script).Call([]any{int64(21)})
+	require.NoError(t, err)
+	assert.Equal(t, "42", value)
+
+	value, err = load(t, "is_even:BOOLEAN="+script).Call([]any{"4"})
+	require.NoError(t, err)
+	assert.Equal(t, true, value)
+
+	_, err = load(t, "double:BIGINT="+script).Call([]any{1.25})
+	assert.ErrorContains(t, err, "not an integer")
+
+	_, err = load(t, "read_file="+script).Call(nil)
+	assert.Error(t, err, "the io library is not available")
+
+	_, err = Load(Spec{Name: "missing", Result: storage.TypeVarchar, Path: script})
+	assert.ErrorContains(t, err, "does not define a function named missing")
+}
+
+func TestLoad_Wasm(t *testing.T) {
+	add := load(t, "add:BIGINT="+writeFile(t, "add.wasm", addWasm))
+	value, err := add.Call([]any{int64(40), "2"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), value)
+
+	_, err = add.Call([]any{int64(1)})
+	assert.ErrorContains(t, err, "expects 2 arguments")
+
+	_, err = add.Call([]any{"one", int64(1)})
+	assert.ErrorContains(t, err, "expected a number")
+
+	echo := load(t, "echo="+writeFile(t, "echo.wasm", echoWasm))
+	for _, text := range []string{"olá mundo", "", "second call"} {
+		value, err = echo.Call([]any{text})
+		require.NoError(t, err)
+		assert.Equal(t, text, value)
+	}
+
+	_, err = Load(Spec{Name: "sub", Result: storage.TypeBigInt, Path: writeFile(t, "add.wasm", addWasm)})
+	assert.ErrorContains(t, err, "does not export a function named sub")
+
+	_, err = Load(Spec{Name: "add", Result: storage.TypeBigInt, Path: writeFile(t, "add.wasm", []byte("not wasm"))})
+	assert.ErrorContains(t, err, "failed to compile")
+}
+
+func TestLoad_UnsupportedFile(t *testing.T) {
+	_, err := Load(Spec{Name: "f", Result: storage.TypeVarchar, Path: "f.py"})
+	assert.ErrorContains(t, err, "unsupported file")
+}
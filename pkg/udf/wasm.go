@@ -0,0 +1,281 @@
+package udf
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/storage"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// loadWasm instantiates the module, which must export a function named after
+// the UDF returning one value. Its parameters are numbers (i32, i64, f32,
+// f64), one per argument. When the module also exports memory and
+// alloc(len i32) i32, i32 parameters taken in pairs are strings instead: each
+// argument is written as UTF-8 to memory from alloc and passed as (ptr, len),
+// and a VARCHAR result is an i64 holding ptr<<32 | len. A module exporting
+// dealloc(ptr i32, len i32) gets the arguments and the result back once read.
+//
+// Modules get WASI without file system, network or environment access.
+func loadWasm(spec Spec) (func(args []any) (any, error), func() error, error) {
+	code, err := os.ReadFile(spec.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", spec.Path, err)
+	}
+
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	fail := func(err error) (func(args []any) (any, error), func() error, error) {
+		_ = runtime.Close(ctx)
+		return nil, nil, err
+	}
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		return fail(fmt.Errorf("failed to start WASI: %w", err))
+	}
+	compiled, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		return fail(fmt.Errorf("failed to compile %s: %w", spec.Path, err))
+	}
+	// Reactor modules initialize with _initialize; _start would run and exit a command module
+	mod, err := runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithStartFunctions("_initialize"))
+	if err != nil {
+		return fail(fmt.Errorf("failed to instantiate %s: %w", spec.Path, err))
+	}
+
+	fn := mod.ExportedFunction(spec.Name)
+	if fn == nil {
+		return fail(fmt.Errorf("%s does not export a function named %s", spec.Path, spec.Name))
+	}
+	params := fn.Definition().ParamTypes()
+	results := fn.Definition().ResultTypes()
+	if len(results) != 1 {
+		return fail(fmt.Errorf("%s must return one value, it returns %d", spec.Name, len(results)))
+	}
+
+	w := &wasmFunction{
+		mod:     mod,
+		fn:      fn,
+		params:  params,
+		result:  results[0],
+		alloc:   mod.ExportedFunction("alloc"),
+		dealloc: mod.ExportedFunction("dealloc"),
+	}
+	w.strings = w.alloc != nil && mod.Memory() != nil && len(params)%2 == 0 && allI32(params)
+	if spec.Result == storage.TypeVarchar && w.strings && w.result != api.ValueTypeI64 {
+		return fail(fmt.Errorf("%s must return an i64 (ptr<<32 | len) for a VARCHAR result", spec.Name))
+	}
+
+	closeFn := func() error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return runtime.Close(ctx)
+	}
+	return w.call, closeFn, nil
+}
+
+// wasmFunction calls an exported function; a module instance runs one call at a time
+type wasmFunction struct {
+	mu      sync.Mutex
+	mod     api.Module
+	fn      api.Function
+	alloc   api.Function
+	dealloc api.Function
+	params  []api.ValueType
+	result  api.ValueType
+	strings bool
+}
+
+func (w *wasmFunction) call(args []any) (any, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ctx := context.Background()
+	if w.strings {
+		return w.callStrings(ctx, args)
+	}
+
+	if len(args) != len(w.params) {
+		return nil, fmt.Errorf("expects %d arguments, got %d", len(w.params), len(args))
+	}
+	stack := make([]uint64, len(args))
+	for i, arg := range args {
+		value, err := encodeNumber(arg, w.params[i])
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		stack[i] = value
+	}
+
+	ret, err := w.fn.Call(ctx, stack...)
+	if err != nil {
+		return nil, err
+	}
+	return decodeNumber(ret[0], w.result), nil
+}
+
+func (w *wasmFunction) callStrings(ctx context.Context, args []any) (any, error) {
+	if len(args) != len(w.params)/2 {
+		return nil, fmt.Errorf("expects %d arguments, got %d", len(w.params)/2, len(args))
+	}
+
+	stack := make([]uint64, 0, len(w.params))
+	for i, arg := range args {
+		text := toText(arg)
+		ptr, err := w.write(ctx, text)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		defer w.free(ctx, ptr, uint32(len(text)))
+		stack = append(stack, api.EncodeU32(ptr), api.EncodeU32(uint32(len(text))))
+	}
+
+	ret, err := w.fn.Call(ctx, stack...)
+	if err != nil {
+		return nil, err
+	}
+	if w.result != api.ValueTypeI64 {
+		return decodeNumber(ret[0], w.result), nil
+	}
+
+	ptr, size := uint32(ret[0]>>32), uint32(ret[0])
+	data, ok := w.mod.Memory().Read(ptr, size)
+	if !ok {
+		return nil, fmt.Errorf("returned a string outside memory (ptr %d, len %d)", ptr, size)
+	}
+	text := string(data)
+	w.free(ctx, ptr, size)
+	return text, nil
+}
+
+// write copies text to memory from alloc
+func (w *wasmFunction) write(ctx context.Context, text string) (uint32, error) {
+	ret, err := w.alloc.Call(ctx, api.EncodeU32(uint32(len(text))))
+	if err != nil {
+		return 0, fmt.Errorf("alloc failed: %w", err)
+	}
+	ptr := api.DecodeU32(ret[0])
+	if !w.mod.Memory().Write(ptr, []byte(text)) {
+		return 0, fmt.Errorf("alloc returned %d, outside memory", ptr)
+	}
+	return ptr, nil
+}
+
+// free returns memory to the module when it exports dealloc
+func (w *wasmFunction) free(ctx context.Context, ptr, size uint32) {
+	if w.dealloc != nil {
+		_, _ = w.dealloc.Call(ctx, api.EncodeU32(ptr), api.EncodeU32(size))
+	}
+}
+
+func allI32(types []api.ValueType) bool {
+	for _, t := range types {
+		if t != api.ValueTypeI32 {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeNumber converts a SQL value to a WebAssembly parameter. Numeric
+// text is accepted, since CSV columns are often imported as VARCHAR.
+func encodeNumber(value any, t api.ValueType) (uint64, error) {
+	var f float64
+	switch v := value.(type) {
+	case int8:
+		f = float64(v)
+	case int16:
+		f = float64(v)
+	case int32:
+		f = float64(v)
+	case int64:
+		if t == api.ValueTypeI64 {
+			return api.EncodeI64(v), nil
+		}
+		f = float64(v)
+	case int:
+		f = float64(v)
+	case uint8:
+		f = float64(v)
+	case uint16:
+		f = float64(v)
+	case uint32:
+		f = float64(v)
+	case uint64:
+		f = float64(v)
+	case float32:
+		f = float64(v)
+	case float64:
+		f = v
+	case bool:
+		if v {
+			f = 1
+		}
+	case string:
+		s := strings.TrimSpace(v)
+		if t == api.ValueTypeI64 {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return api.EncodeI64(i), nil
+			}
+		}
+		parsed, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("expected a number, got %q", v)
+		}
+		f = parsed
+	default:
+		return 0, fmt.Errorf("expected a number, got %T", value)
+	}
+
+	switch t {
+	case api.ValueTypeI32:
+		if f != math.Trunc(f) || f < math.MinInt32 || f > math.MaxInt32 {
+			return 0, fmt.Errorf("%v does not fit an i32", f)
+		}
+		return api.EncodeI32(int32(f)), nil
+	case api.ValueTypeI64:
+		if f != math.Trunc(f) || math.IsInf(f, 0) {
+			return 0, fmt.Errorf("%v is not an integer", f)
+		}
+		return api.EncodeI64(int64(f)), nil
+	case api.ValueTypeF32:
+		return api.EncodeF32(float32(f)), nil
+	case api.ValueTypeF64:
+		return api.EncodeF64(f), nil
+	}
+	return 0, fmt.Errorf("unsupported parameter type %s", api.ValueTypeName(t))
+}
+
+// decodeNumber converts a WebAssembly result to int64 or float64
+func decodeNumber(value uint64, t api.ValueType) any {
+	switch t {
+	case api.ValueTypeI32:
+		return int64(api.DecodeI32(value))
+	case api.ValueTypeF32:
+		return float64(api.DecodeF32(value))
+	case api.ValueTypeF64:
+		return api.DecodeF64(value)
+	}
+	return int64(value)
+}
+
+// toText formats a SQL value passed as a string
+func toText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value)
+}
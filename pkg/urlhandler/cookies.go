@@ -0,0 +1,234 @@
+package urlhandler
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	// cookieFileHeader starts the Netscape cookie files written by curl and
+	// browser extensions
+	cookieFileHeader = "# Netscape HTTP Cookie File"
+	// httpOnlyPrefix marks HttpOnly cookies in cookie files
+	httpOnlyPrefix = "#HttpOnly_"
+)
+
+// cookieJar sends the cookies of a Netscape cookie file and saves the
+// cookies servers set back to it, like curl --cookie-jar
+type cookieJar struct {
+	http.CookieJar
+	path    string
+	mu      sync.Mutex
+	entries map[string]cookieEntry // Keyed by domain, path and name
+	changed bool
+}
+
+// cookieEntry is a line of a cookie file
+type cookieEntry struct {
+	domain     string
+	subdomains bool // Sent to the subdomains of domain too
+	path       string
+	secure     bool
+	httpOnly   bool
+	expires    int64 // Unix time, 0 for session cookies
+	name       string
+	value      string
+}
+
+// loadCookieJar reads the cookie file at path, if it exists
+func loadCookieJar(path string) (*cookieJar, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, err
+	}
+	c := &cookieJar{CookieJar: jar, path: path, entries: make(map[string]cookieEntry)}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookie jar: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		httpOnly := strings.HasPrefix(text, httpOnlyPrefix)
+		text = strings.TrimPrefix(text, httpOnlyPrefix)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid cookie jar %s: line %d: expected 7 tab-separated fields", path, line)
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie jar %s: line %d: invalid expiry %q", path, line, fields[4])
+		}
+		entry := cookieEntry{
+			domain:     strings.TrimPrefix(fields[0], "."),
+			subdomains: strings.EqualFold(fields[1], "TRUE"),
+			path:       fields[2],
+			secure:     strings.EqualFold(fields[3], "TRUE"),
+			httpOnly:   httpOnly,
+			expires:    expires,
+			name:       fields[5],
+			value:      fields[6],
+		}
+		if entry.expires != 0 && time.Unix(entry.expires, 0).Before(time.Now()) {
+			continue
+		}
+		c.entries[entry.key()] = entry
+		c.CookieJar.SetCookies(entry.url(), []*http.Cookie{entry.cookie()})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cookie jar: %w", err)
+	}
+	return c, nil
+}
+
+// SetCookies keeps the cookies set by responses to u, to be saved
+func (c *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	c.CookieJar.SetCookies(u, cookies)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, cookie := range cookies {
+		entry := cookieEntry{
+			domain:   u.Hostname(),
+			path:     cookie.Path,
+			secure:   cookie.Secure,
+			httpOnly: cookie.HttpOnly,
+			name:     cookie.Name,
+			value:    cookie.Value,
+		}
+		if cookie.Domain != "" {
+			entry.domain, entry.subdomains = strings.ToLower(strings.TrimPrefix(cookie.Domain, ".")), true
+			// The jar ignores cookies for other sites, and so does the file
+			if !domainMatch(u.Hostname(), entry.domain) {
+				continue
+			}
+		}
+		if entry.path == "" || !strings.HasPrefix(entry.path, "/") {
+			entry.path = defaultCookiePath(u.Path)
+		}
+		switch {
+		case cookie.MaxAge < 0:
+			entry.expires = -1
+		case cookie.MaxAge > 0:
+			entry.expires = time.Now().Add(time.Duration(cookie.MaxAge) * time.Second).Unix()
+		case !cookie.Expires.IsZero():
+			entry.expires = cookie.Expires.Unix()
+		}
+
+		if entry.expires != 0 && time.Unix(entry.expires, 0).Before(time.Now()) {
+			delete(c.entries, entry.key())
+		} else {
+			c.entries[entry.key()] = entry
+		}
+		c.changed = true
+	}
+}
+
+// domainMatch reports whether a response from host may set a cookie for
+// domain and its subdomains: host is domain or one of its subdomains, and
+// domain is not a public suffix such as com or co.uk
+func domainMatch(host, domain string) bool {
+	host = strings.ToLower(host)
+	if host == domain {
+		return true
+	}
+	if !strings.HasSuffix(host, "."+domain) || net.ParseIP(host) != nil {
+		return false
+	}
+	suffix, _ := publicsuffix.PublicSuffix(domain)
+	return suffix != domain
+}
+
+// save writes the cookies back to the cookie file when servers set any
+func (c *cookieJar) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.changed {
+		return nil
+	}
+
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(cookieFileHeader + "\n\n")
+	for _, key := range keys {
+		entry := c.entries[key]
+		domain := entry.domain
+		if entry.subdomains {
+			domain = "." + domain
+		}
+		if entry.httpOnly {
+			domain = httpOnlyPrefix + domain
+		}
+		fmt.Fprintf(&b, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", domain, strings.ToUpper(strconv.FormatBool(entry.subdomains)),
+			entry.path, strings.ToUpper(strconv.FormatBool(entry.secure)), entry.expires, entry.name, entry.value)
+	}
+
+	// Cookies are credentials: only the user reads the file
+	if err := os.WriteFile(c.path, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("failed to save cookie jar: %w", err)
+	}
+	c.changed = false
+	return nil
+}
+
+func (e cookieEntry) key() string {
+	return e.domain + "\t" + e.path + "\t" + e.name
+}
+
+// url returns a URL the cookie is sent to, to load it in the jar
+func (e cookieEntry) url() *url.URL {
+	scheme := "http"
+	if e.secure {
+		scheme = "https"
+	}
+	return &url.URL{Scheme: scheme, Host: e.domain, Path: e.path}
+}
+
+func (e cookieEntry) cookie() *http.Cookie {
+	cookie := &http.Cookie{Name: e.name, Value: e.value, Path: e.path, Secure: e.secure, HttpOnly: e.httpOnly}
+	if e.subdomains {
+		cookie.Domain = e.domain
+	}
+	if e.expires != 0 {
+		cookie.Expires = time.Unix(e.expires, 0)
+	}
+	return cookie
+}
+
+// defaultCookiePath returns the path of cookies set without one: the
+// directory of the request path (RFC 6265 section 5.1.4)
+func defaultCookiePath(requestPath string) string {
+	dir := path.Dir(requestPath)
+	if !strings.HasPrefix(dir, "/") || !strings.HasPrefix(requestPath, "/") {
+		return "/"
+	}
+	return dir
+}
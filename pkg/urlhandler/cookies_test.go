@@ -0,0 +1,87 @@
+package urlhandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieJar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := r.Cookie("session")
+		if err != nil || session.Value != "abc" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "token", Value: "xyz", Path: "/", MaxAge: 3600})
+		http.SetCookie(w, &http.Cookie{Name: "old", Value: "", Path: "/", MaxAge: -1})
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	// curl writes host-only cookies with FALSE and the host without a port
+	jarPath := filepath.Join(t.TempDir(), "cookies.txt")
+	require.NoError(t, os.WriteFile(jarPath, []byte(cookieFileHeader+"\n\n"+
+		"127.0.0.1\tFALSE\t/\tFALSE\t0\tsession\tabc\n"+
+		"#HttpOnly_127.0.0.1\tFALSE\t/\tFALSE\t0\told\tgone\n"), 0600))
+
+	h := newTestHandler()
+	defer h.Cleanup()
+	require.NoError(t, h.SetCookieJar(jarPath))
+	_, err := h.ResolveFiles([]string{server.URL + "/data.csv"})
+	require.NoError(t, err)
+
+	// The cookies the server set are saved, the deleted one is removed
+	saved, err := os.ReadFile(jarPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(saved), "127.0.0.1\tFALSE\t/\tFALSE\t0\tsession\tabc\n")
+	assert.Contains(t, string(saved), "\ttoken\txyz\n")
+	assert.NotContains(t, string(saved), "\told\t")
+
+	info, err := os.Stat(jarPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestCookieJarOtherDomains(t *testing.T) {
+	jar, err := loadCookieJar(filepath.Join(t.TempDir(), "cookies.txt"))
+	require.NoError(t, err)
+	u, err := url.Parse("https://api.example.com/data.csv")
+	require.NoError(t, err)
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "host", Value: "1"},
+		{Name: "parent", Value: "2", Domain: ".example.com"},
+		{Name: "other", Value: "3", Domain: "attacker.com"},
+		{Name: "suffix", Value: "4", Domain: "com"},
+	})
+
+	names := make([]string, 0, len(jar.entries))
+	for _, entry := range jar.entries {
+		names = append(names, entry.name)
+	}
+	assert.ElementsMatch(t, []string{"host", "parent"}, names)
+
+	assert.True(t, domainMatch("example.co.uk", "example.co.uk"))
+	assert.False(t, domainMatch("example.co.uk", "co.uk"))
+	assert.False(t, domainMatch("10.0.0.1", "0.0.1"))
+}
+
+func TestCookieJarErrors(t *testing.T) {
+	jarPath := filepath.Join(t.TempDir(), "cookies.txt")
+	require.NoError(t, os.WriteFile(jarPath, []byte("example.com\tTRUE\t/\n"), 0600))
+	_, err := loadCookieJar(jarPath)
+	assert.ErrorContains(t, err, "line 1: expected 7 tab-separated fields")
+
+	// A missing file starts an empty jar, created once a server sets a cookie
+	jar, err := loadCookieJar(filepath.Join(t.TempDir(), "new.txt"))
+	require.NoError(t, err)
+	assert.NoError(t, jar.save())
+	_, err = os.Stat(jar.path)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -1,31 +1,120 @@
 package urlhandler
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
+
+	"github.com/adrianolaselva/dataql/pkg/auditlog"
+	"github.com/adrianolaselva/dataql/pkg/cachehandler"
+	"github.com/adrianolaselva/dataql/pkg/progress"
+	"github.com/adrianolaselva/dataql/pkg/tlsconfig"
+)
+
+const (
+	// DefaultTimeout is the time allowed for each request of a download
+	DefaultTimeout = 5 * time.Minute
+
+	// maxAttempts is how many requests in a row may fail before a download fails
+	maxAttempts = 4
+	// retryDelay is the wait before the first retry, doubled after each failure
+	retryDelay = 500 * time.Millisecond
 )
 
 // URLHandler handles downloading files from URLs
 type URLHandler struct {
-	client    *http.Client
-	tempDir   string
-	tempFiles []string
+	client     *http.Client
+	tempDir    string
+	tempFiles  []string
+	cache      *cachehandler.DownloadCache // Keeps downloads between runs (nil: disabled)
+	headers    http.Header                 // Sent with every request, e.g. Authorization
+	cookies    *cookieJar                  // Cookie file of --cookie-jar (nil: none)
+	progress   *progress.Reporter          // Shows the downloads (nil: not shown)
+	retryDelay time.Duration
 }
 
 // NewURLHandler creates a new URL handler
 func NewURLHandler() *URLHandler {
 	return &URLHandler{
 		client: &http.Client{
-			Timeout: 5 * time.Minute, // 5 minute timeout for large files
+			Timeout: DefaultTimeout,
 		},
-		tempFiles: make([]string, 0),
+		tempFiles:  make([]string, 0),
+		retryDelay: retryDelay,
+	}
+}
+
+// SetTimeout sets the time allowed for each request, 0 for no limit. A
+// download that times out continues where it stopped with a new request.
+func (h *URLHandler) SetTimeout(timeout time.Duration) {
+	h.client.Timeout = timeout
+}
+
+// SetTLS sets the certificate authorities trusted by the downloads, or turns
+// off certificate verification. Proxies of HTTPS_PROXY and NO_PROXY are used
+// either way.
+func (h *URLHandler) SetTLS(opts tlsconfig.Options) error {
+	if opts.IsZero() {
+		return nil
+	}
+	transport, err := opts.Transport()
+	if err != nil {
+		return err
 	}
+	h.client.Transport = transport
+	return nil
+}
+
+// SetCache keeps downloads in a cache, reused while the server reports the
+// same ETag or Last-Modified
+func (h *URLHandler) SetCache(cache *cachehandler.DownloadCache) {
+	h.cache = cache
+}
+
+// SetHeaders sets headers sent with every request, such as Authorization
+// for APIs and signed endpoints
+func (h *URLHandler) SetHeaders(headers http.Header) {
+	h.headers = headers
+}
+
+// SetCookieJar sends the cookies of a Netscape cookie file, as written by
+// curl and browser extensions, and saves the cookies servers set back to it
+func (h *URLHandler) SetCookieJar(path string) error {
+	jar, err := loadCookieJar(path)
+	if err != nil {
+		return err
+	}
+	h.cookies = jar
+	h.client.Jar = jar
+	return nil
+}
+
+// SetProgress shows the progress of the downloads with reporter
+func (h *URLHandler) SetProgress(reporter *progress.Reporter) {
+	h.progress = reporter
+}
+
+// ParseHeaders parses headers given as "Name: value"
+func ParseHeaders(values []string) (http.Header, error) {
+	headers := make(http.Header)
+	for _, value := range values {
+		name, content, found := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid --header %q (expected 'Name: value')", value)
+		}
+		headers.Add(name, strings.TrimSpace(content))
+	}
+	return headers, nil
 }
 
 // IsURL checks if a path is a URL
@@ -43,7 +132,12 @@ func (h *URLHandler) ResolveFiles(filePaths []string) ([]string, error) {
 		if IsURL(path) {
 			localPath, err := h.downloadURL(path)
 			if err != nil {
-				return nil, fmt.Errorf("failed to download %s: %w", path, err)
+				// Errors of the client quote the URL, with its signature
+				var urlErr *url.Error
+				if errors.As(err, &urlErr) {
+					urlErr.URL = redact(urlErr.URL)
+				}
+				return nil, fmt.Errorf("failed to download %s: %w", redact(path), err)
 			}
 			resolvedPaths = append(resolvedPaths, localPath)
 		} else {
@@ -51,6 +145,11 @@ func (h *URLHandler) ResolveFiles(filePaths []string) ([]string, error) {
 		}
 	}
 
+	if h.cookies != nil {
+		if err := h.cookies.save(); err != nil {
+			return nil, err
+		}
+	}
 	return resolvedPaths, nil
 }
 
@@ -80,17 +179,55 @@ func (h *URLHandler) downloadURL(urlStr string) (string, error) {
 	// Create the local file path
 	localPath := filepath.Join(h.tempDir, filename)
 
+	req, err := h.newRequest(urlStr)
+	if err != nil {
+		return "", err
+	}
+
+	// A cached download is revalidated with a conditional request. Cached
+	// downloads are named by the URL without its password.
+	source := redact(urlStr)
+	var cached *cachehandler.Download
+	if h.cache != nil {
+		cached, _ = h.cache.Lookup(source)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
 	// Download the file
-	resp, err := h.client.Get(urlStr)
+	resp, err := h.do(req)
 	if err != nil {
-		return "", fmt.Errorf("HTTP request failed: %w", err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		return h.cache.Use(cached), nil
+	}
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return "", fmt.Errorf("HTTP error: status %d: %w", resp.StatusCode, fs.ErrNotExist)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("HTTP error: status %d", resp.StatusCode)
 	}
 
+	phase := h.progress.Start(progress.Download, filename, resp.ContentLength)
+	defer phase.Done()
+
+	// Without a validator the download cannot be revalidated, so it is not cached
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if h.cache != nil && (etag != "" || lastModified != "") {
+		return h.cache.StoreFile(cachehandler.Download{Source: source, File: filename, ETag: etag, LastModified: lastModified}, func(file *os.File) error {
+			return h.receive(urlStr, resp, file, phase)
+		})
+	}
+
 	// Create the local file
 	outFile, err := os.Create(localPath)
 	if err != nil {
@@ -99,15 +236,137 @@ func (h *URLHandler) downloadURL(urlStr string) (string, error) {
 	defer outFile.Close()
 
 	// Copy the content
-	_, err = io.Copy(outFile, resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to download file content: %w", err)
+	if err := h.receive(urlStr, resp, outFile, phase); err != nil {
+		return "", err
 	}
 
 	h.tempFiles = append(h.tempFiles, localPath)
 	return localPath, nil
 }
 
+// newRequest returns a GET request of urlStr with the headers of
+// SetHeaders. Credentials in the URL are sent with basic authentication.
+func (h *URLHandler) newRequest(urlStr string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	for name, values := range h.headers {
+		req.Header[name] = values
+	}
+	return req, nil
+}
+
+// do sends req, retrying with backoff when the connection fails or the
+// server is overloaded or restarting
+func (h *URLHandler) do(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := h.client.Do(req)
+		if err == nil && (!retryStatus(resp.StatusCode) || attempt == maxAttempts) {
+			return resp, nil
+		}
+		if err != nil && (!retryError(err) || attempt == maxAttempts) {
+			return nil, fmt.Errorf("HTTP request failed: %w", err)
+		}
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		time.Sleep(h.retryDelay << (attempt - 1))
+	}
+}
+
+// receive writes the body of resp to file. When the connection drops, the
+// rest of the file is requested with a Range header, so the download
+// continues where it stopped; If-Range makes the server send the whole file
+// again if it changed meanwhile.
+func (h *URLHandler) receive(urlStr string, resp *http.Response, file *os.File, phase *progress.Phase) error {
+	validator := resp.Header.Get("ETag")
+	if validator == "" {
+		validator = resp.Header.Get("Last-Modified")
+	}
+
+	var written int64
+	failures := 0
+	for {
+		n, err := io.Copy(file, phase.Reader(resp.Body))
+		_ = resp.Body.Close()
+		written += n
+		if err == nil {
+			return nil
+		}
+
+		// Only failures in a row count, so a slow download keeps going
+		if n > 0 {
+			failures = 0
+		}
+		failures++
+		if failures == maxAttempts || resp.Header.Get("Accept-Ranges") == "none" {
+			return fmt.Errorf("failed to download file content: %w", err)
+		}
+		time.Sleep(h.retryDelay << (failures - 1))
+
+		req, err := h.newRequest(urlStr)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", written))
+		if validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+		if resp, err = h.do(req); err != nil {
+			return err
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusPartialContent && strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", written)):
+		case resp.StatusCode == http.StatusOK:
+			// The server sent the whole file: start over
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to download file content: %w", err)
+			}
+			if err := file.Truncate(0); err != nil {
+				return fmt.Errorf("failed to download file content: %w", err)
+			}
+			written = 0
+		default:
+			_ = resp.Body.Close()
+			return fmt.Errorf("HTTP error resuming download: status %d", resp.StatusCode)
+		}
+	}
+}
+
+// retryError reports whether a request that failed with err may succeed
+// when sent again: timeouts, reset connections and responses cut short, but
+// not unknown hosts, refused connections or certificate errors
+func retryError(err error) bool {
+	// Every *url.Error is a net.Error: look at what it wraps
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryStatus reports whether a request answered with status may succeed
+// when sent again
+func retryStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// redact hides the password and the signature of a URL
+func redact(urlStr string) string {
+	return auditlog.RedactSource(urlStr)
+}
+
 // Cleanup removes all downloaded temp files
 func (h *URLHandler) Cleanup() error {
 	if h.tempDir != "" {
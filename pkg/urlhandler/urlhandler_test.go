@@ -0,0 +1,213 @@
+package urlhandler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var content = bytes.Repeat([]byte("id,name\n1,alice\n"), 4096)
+
+// dropAfter writes the first n bytes of content and drops the connection
+func dropAfter(w http.ResponseWriter, n int) {
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("ETag", `"v1"`)
+	w.Header().Set("Accept-Ranges", "bytes")
+	_, _ = w.Write(content[:n])
+	w.(http.Flusher).Flush()
+	panic(http.ErrAbortHandler)
+}
+
+func newTestHandler() *URLHandler {
+	h := NewURLHandler()
+	h.retryDelay = time.Millisecond
+	return h
+}
+
+func TestDownloadResumes(t *testing.T) {
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		switch r.Header.Get("Range") {
+		case "":
+			dropAfter(w, 1000)
+		case "bytes=1000-":
+			// A second drop after some progress
+			w.Header().Set("Content-Range", "bytes 1000-"+strconv.Itoa(len(content)-1)+"/"+strconv.Itoa(len(content)))
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)-1000))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(content[1000:5000])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		default:
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "data.csv", time.Time{}, bytes.NewReader(content))
+		}
+	}))
+	defer server.Close()
+
+	h := newTestHandler()
+	defer h.Cleanup()
+	path, err := h.downloadURL(server.URL + "/data.csv")
+	require.NoError(t, err)
+
+	downloaded, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+	assert.Equal(t, []string{"", "bytes=1000-", "bytes=5000-"}, ranges)
+}
+
+func TestDownloadRestartsWhenRangeIgnored(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			dropAfter(w, 1000)
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	h := newTestHandler()
+	defer h.Cleanup()
+	path, err := h.downloadURL(server.URL + "/data.csv")
+	require.NoError(t, err)
+
+	downloaded, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+}
+
+func TestDownloadRetriesServerErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	h := newTestHandler()
+	defer h.Cleanup()
+	_, err := h.downloadURL(server.URL + "/data.csv")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestDownloadGivesUp(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	h := newTestHandler()
+	defer h.Cleanup()
+	_, err := h.downloadURL(server.URL + "/data.csv")
+	assert.ErrorContains(t, err, "status 502")
+	assert.Equal(t, int32(maxAttempts), requests.Load())
+
+	// Client errors are not retried
+	requests.Store(0)
+	notFound := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer notFound.Close()
+	_, err = h.downloadURL(notFound.URL + "/data.csv")
+	assert.ErrorContains(t, err, "status 404")
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestDownloadTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	h := newTestHandler()
+	defer h.Cleanup()
+	h.SetTimeout(20 * time.Millisecond)
+	_, err := h.downloadURL(server.URL + "/data.csv")
+	assert.ErrorContains(t, err, "Client.Timeout")
+}
+
+func TestDownloadRefusedIsNotRetried(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	h := NewURLHandler()
+	defer h.Cleanup()
+	start := time.Now()
+	_, err := h.downloadURL(server.URL + "/data.csv")
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Less(t, time.Since(start), retryDelay)
+}
+
+func TestDownloadUntrustedCertificateIsNotRetried(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	h := NewURLHandler()
+	defer h.Cleanup()
+	start := time.Now()
+	_, err := h.downloadURL(server.URL + "/data.csv")
+	assert.ErrorContains(t, err, "certificate")
+	assert.Less(t, time.Since(start), retryDelay)
+	assert.Zero(t, requests.Load())
+}
+
+func TestDownloadSendsHeadersAndBasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if r.Header.Get("X-Api-Key") != "secret" || !ok || user != "alice" || password != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	headers, err := ParseHeaders([]string{"X-Api-Key: secret"})
+	require.NoError(t, err)
+
+	h := newTestHandler()
+	defer h.Cleanup()
+	authURL := strings.Replace(server.URL, "http://", "http://alice:s3cret@", 1) + "/data.csv"
+	_, err = h.ResolveFiles([]string{authURL})
+	assert.ErrorContains(t, err, "status 401")
+	assert.NotContains(t, err.Error(), "s3cret")
+
+	h.SetHeaders(headers)
+	_, err = h.ResolveFiles([]string{authURL})
+	assert.NoError(t, err)
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders([]string{"Authorization: Bearer abc:def", "accept: text/csv", "X-Empty:"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer abc:def", headers.Get("Authorization"))
+	assert.Equal(t, "text/csv", headers.Get("Accept"))
+	assert.Equal(t, []string{""}, headers["X-Empty"])
+
+	for _, value := range []string{"Authorization", ": value", "Bad Name: value"} {
+		_, err := ParseHeaders([]string{value})
+		assert.ErrorContains(t, err, "invalid --header", value)
+	}
+}
@@ -176,3 +176,12 @@ func TestAzure_ParquetFile(t *testing.T) {
 
 	t.Logf("Azure Parquet output: %s", stdout)
 }
+
+func TestAzure_SASTokenWithoutAccount(t *testing.T) {
+	t.Setenv("AZURE_STORAGE_CONNECTION_STRING", "")
+	t.Setenv("AZURE_STORAGE_ACCOUNT", "")
+
+	_, stderr, err := runDataQL(t, "run", "-f", "az://container/data.csv", "--azure-sas-token", "sv=2022-11-02&sig=abc", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "--azure-account")
+}
@@ -1,10 +1,14 @@
 package e2e_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestCache_BasicUsage(t *testing.T) {
@@ -322,3 +326,124 @@ func TestCache_MultipleFiles(t *testing.T) {
 	assertNoError(t, err, stderr2)
 	assertContains(t, stdout2, "Using cached data")
 }
+
+func TestCache_RemoteSourceSkipsDownload(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	// Serve a CSV with an ETag, counting the full downloads
+	var mu sync.Mutex
+	content, etag, downloads := "id,name\n1,alice\n2,bob\n", `"v1"`, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	run := func() string {
+		stdout, stderr, err := runDataQL(t, "run",
+			"-f", server.URL+"/users.csv",
+			"-q", "SELECT COUNT(*) AS cnt FROM users",
+			"--cache",
+			"--cache-dir", cacheDir,
+			"-Q",
+			"-v",
+		)
+		assertNoError(t, err, stderr)
+		return stdout
+	}
+
+	stdout := run()
+	assertContains(t, stdout, "2")
+	assertContains(t, stdout, "Starting data import")
+
+	// Unchanged object: neither downloaded nor imported again
+	stdout = run()
+	assertContains(t, stdout, "2")
+	assertContains(t, stdout, "Using cached data")
+	if downloads != 1 {
+		t.Errorf("expected 1 download, got %d", downloads)
+	}
+
+	// New version of the object: downloaded and imported again
+	mu.Lock()
+	content, etag = "id,name\n1,alice\n2,bob\n3,carol\n", `"v2"`
+	mu.Unlock()
+
+	stdout = run()
+	assertContains(t, stdout, "3")
+	assertContains(t, stdout, "Starting data import")
+	if downloads != 2 {
+		t.Errorf("expected 2 downloads, got %d", downloads)
+	}
+}
+
+func TestCache_GrowingFileAppendsNewRows(t *testing.T) {
+	cacheDir := t.TempDir()
+	csvFile := filepath.Join(t.TempDir(), "events.csv")
+	if err := os.WriteFile(csvFile, []byte("id,level\n1,info\n2,warn\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	run := func() string {
+		stdout, stderr, err := runDataQL(t, "run",
+			"-f", csvFile,
+			"-q", "SELECT COUNT(*) AS cnt, SUM(id) AS total FROM events",
+			"--cache",
+			"--cache-dir", cacheDir,
+			"-Q",
+			"-v",
+		)
+		assertNoError(t, err, stderr)
+		return stdout
+	}
+	grow := func(content string, modTime time.Time) {
+		f, err := os.OpenFile(csvFile, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("failed to open test file: %v", err)
+		}
+		_, err = f.WriteString(content)
+		f.Close()
+		if err != nil {
+			t.Fatalf("failed to append to test file: %v", err)
+		}
+		if err := os.Chtimes(csvFile, modTime, modTime); err != nil {
+			t.Fatalf("failed to set modification time: %v", err)
+		}
+	}
+
+	stdout := run()
+	assertContains(t, stdout, "2    3")
+
+	// Appended rows are imported into the cached table
+	grow("3,error\n4,info\n", time.Now().Add(time.Minute))
+	stdout = run()
+	assertContains(t, stdout, "importing the appended data only")
+	assertContains(t, stdout, "4    10")
+
+	// New rows that do not fit the cached table trigger a full import
+	grow("5.5,info\n", time.Now().Add(2*time.Minute))
+	stdout = run()
+	assertContains(t, stdout, "importing again")
+	assertContains(t, stdout, "5    15.5")
+
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatalf("failed to read cache directory: %v", err)
+	}
+	var databases int
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".duckdb") {
+			databases++
+		}
+	}
+	if databases != 1 {
+		t.Errorf("expected the cache entry to be updated in place, found %d databases", databases)
+	}
+}
@@ -1,6 +1,7 @@
 package e2e_test
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -393,6 +394,34 @@ func TestCLI_InvalidExportType(t *testing.T) {
 	assertError(t, err)
 }
 
+// TestCLI_ExportCheckedBeforeImport tests that an invalid export fails before the sources are read
+func TestCLI_ExportCheckedBeforeImport(t *testing.T) {
+	outputFile := tempFile(t, "output.csv")
+
+	_, stderr, err := runDataQL(t, "run",
+		"-f", "missing.csv",
+		"-q", "SELECT * FROM missing",
+		"-e", outputFile,
+		"-t", "cvs")
+	assertError(t, err)
+	assertContains(t, stderr, "export type cvs not defined")
+
+	_, stderr, err = runDataQL(t, "run",
+		"-f", "missing.csv",
+		"-q", "SELECT * FROM missing",
+		"-e", outputFile)
+	assertError(t, err)
+	assertContains(t, stderr, "--type is required with --export (e.g. -t csv)")
+
+	_, stderr, err = runDataQL(t, "run",
+		"-f", "missing.csv",
+		"-q", "SELECT * FROM missing",
+		"-e", t.TempDir(),
+		"-t", "csv")
+	assertError(t, err)
+	assertContains(t, stderr, "is a directory")
+}
+
 func TestCLI_InvalidLinesValue(t *testing.T) {
 	_, _, err := runDataQL(t, "run",
 		"-f", fixture("csv/simple.csv"),
@@ -484,6 +513,21 @@ func TestCLI_TableAlias_MultipleFiles(t *testing.T) {
 	assertContains(t, stdout, "3")
 }
 
+func TestCLI_TableAlias_EqualsSyntax(t *testing.T) {
+	sales2024 := tempFileWithContent(t, "sales_2024.csv", "region,total\nnorth,300\nsouth,200")
+	sales2023 := tempFileWithContent(t, "sales_2023.csv", "region,total\nnorth,100\nsouth,250")
+
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", sales2024+"=sales",
+		"-f", sales2023+"=sales_prev",
+		"-c", "ignored",
+		"-q", "SELECT s.region || ':' || (s.total - p.total) AS growth FROM sales s JOIN sales_prev p ON s.region = p.region ORDER BY s.region")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "north:200")
+	assertContains(t, stdout, "south:-50")
+}
+
 func TestCLI_TableAlias_ShowTables(t *testing.T) {
 	commands := `.tables
 .quit`
@@ -539,3 +583,218 @@ func TestCLI_TableAlias_QueryJoinTwoAliases(t *testing.T) {
 	assertContains(t, stdout, "count_a")
 	assertContains(t, stdout, "count_b")
 }
+
+func TestCLI_FollowRequiresQuery(t *testing.T) {
+	_, _, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"--follow")
+
+	assertError(t, err)
+}
+
+func TestCLI_FollowRejectsExport(t *testing.T) {
+	_, _, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-q", "SELECT * FROM simple",
+		"-e", tempFile(t, "out.csv"),
+		"-t", "csv",
+		"--follow")
+
+	assertError(t, err)
+}
+
+func TestCLI_ExtensionBuiltInOffline(t *testing.T) {
+	t.Setenv("DATAQL_EXTENSION_DIR", t.TempDir())
+
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"--extension", "json",
+		"--offline-extensions",
+		"-q", "SELECT CAST(json_object('rows', COUNT(*)) AS VARCHAR) AS doc FROM simple")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, `{"rows":`)
+}
+
+func TestCLI_ExtensionMissingOffline(t *testing.T) {
+	t.Setenv("DATAQL_EXTENSION_DIR", t.TempDir())
+
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"--extension", "spatial",
+		"--offline-extensions",
+		"-q", "SELECT 1")
+
+	assertError(t, err)
+	assertContains(t, stderr, "dataql ext install spatial")
+}
+
+func TestCLI_ExtList(t *testing.T) {
+	t.Setenv("DATAQL_EXTENSION_DIR", t.TempDir())
+
+	stdout, stderr, err := runDataQL(t, "ext", "list")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "parquet")
+	assertContains(t, stdout, "built-in")
+}
+
+func TestCLI_EngineSQLite(t *testing.T) {
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"--engine", "sqlite",
+		"-q", "SELECT name, typeof(id) AS id_type FROM simple WHERE id > 2")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Bob")
+	assertContains(t, stdout, "integer")
+}
+
+func TestCLI_EngineSQLiteExport(t *testing.T) {
+	output := tempFile(t, "engine.csv")
+
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("json/nested.json"),
+		"--engine", "sqlite",
+		"-q", "SELECT id, user_name FROM nested ORDER BY id",
+		"-e", output, "-t", "csv")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, readFile(t, output), "2,Jane")
+}
+
+func TestCLI_EngineRejectsDuckDBOnlyFlags(t *testing.T) {
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"--engine", "sqlite",
+		"--input-tz", "UTC",
+		"-q", "SELECT 1")
+
+	assertError(t, err)
+	assertContains(t, stderr, "--input-tz requires --engine duckdb")
+}
+
+func TestCLI_InvalidEngine(t *testing.T) {
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"--engine", "oracle",
+		"-q", "SELECT 1")
+
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --engine")
+}
+
+func TestCLI_ReadOnly(t *testing.T) {
+	dbFile := tempFile(t, "readonly.duckdb")
+	_, stderr, err := runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "-s", dbFile, "-q", "SELECT 1")
+	assertNoError(t, err, stderr)
+
+	stdout, stderr, err := runDataQL(t, "run", "-s", dbFile, "--read-only", "-q", "SELECT COUNT(*) AS total FROM simple")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "total")
+
+	_, stderr, err = runDataQL(t, "run", "-s", dbFile, "--read-only", "-q", "DROP TABLE simple")
+	assertError(t, err)
+	assertContains(t, stderr, "DROP statements are not allowed")
+
+	// The table survived the rejected statement
+	_, stderr, err = runDataQL(t, "run", "-s", dbFile, "--read-only", "-q", "SELECT * FROM simple")
+	assertNoError(t, err, stderr)
+
+	// Files are imported in memory and joined with the saved tables
+	stdout, stderr, err = runDataQL(t, "run", "-f", fixture("csv/users.csv"), "-s", dbFile, "--read-only",
+		"-q", "SELECT simple.name AS saved, users.name AS imported FROM simple JOIN users ON simple.id = users.id ORDER BY simple.id LIMIT 1")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "John")
+	assertContains(t, stdout, "Alice")
+
+	_, stderr, err = runDataQL(t, "run", "-s", dbFile, "--read-only", "-q", "SELECT * FROM users")
+	assertError(t, err)
+	assertContains(t, stderr, "users")
+}
+
+func TestCLI_BatchSize(t *testing.T) {
+	for _, engine := range []string{"duckdb", "sqlite"} {
+		t.Run(engine, func(t *testing.T) {
+			stdout, stderr, err := runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--engine", engine,
+				"--batch-size", "2", "--commit-interval", "2", "-q", "SELECT COUNT(*) AS total FROM simple")
+			assertNoError(t, err, stderr)
+			assertContains(t, stdout, "total")
+		})
+	}
+
+	_, stderr, err := runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--batch-size", "-1", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --batch-size")
+}
+
+func TestCLI_DownloadParts(t *testing.T) {
+	_, stderr, err := runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--download-parts", "-2", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --download-parts")
+
+	_, stderr, err = runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--download-part-size", "lots", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --download-part-size")
+
+	// Local files are not affected
+	stdout, stderr, err := runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--download-parts", "1", "--download-part-size", "64MB", "-q", "SELECT COUNT(*) AS total FROM simple")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "total")
+}
+
+func TestCLI_MaxResultMemory(t *testing.T) {
+	// Past the limit, the table is aligned on the rows read so far and
+	// streams the rest under the same header
+	stdout, stderr, err := runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--max-result-memory", "100B",
+		"-q", "SELECT * FROM simple ORDER BY id")
+	assertNoError(t, err, stderr)
+	if count := strings.Count(stdout, "email"); count != 1 {
+		t.Errorf("expected one header, got:\n%s", stdout)
+	}
+	assertContains(t, stdout, "bob@example.com")
+
+	// JSON switches to writing one row at a time, in the same format
+	stdout, stderr, err = runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--max-result-memory", "100B",
+		"--mode", "json", "-q", "SELECT id FROM simple ORDER BY id")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "[\n  {\n    \"id\": 1\n  },\n  {\n    \"id\": 2\n  },")
+
+	_, stderr, err = runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--max-result-memory", "lots", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --max-result-memory")
+}
+
+func TestCLI_TempDir(t *testing.T) {
+	dir := t.TempDir()
+	stdout, stderr, err := runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--temp-dir", dir,
+		"-q", "SELECT current_setting('temp_directory') AS temp_directory")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, dir)
+
+	_, stderr, err = runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--engine", "sqlite", "--temp-dir", dir, "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "--temp-dir requires --engine duckdb")
+}
+
+func TestCLI_ProgressNotOnRedirectedStderr(t *testing.T) {
+	// Bars redraw with escape codes, which would clutter CI logs
+	stdout, stderr, err := runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "-q", "SELECT COUNT(*) AS total FROM simple")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "total")
+	if strings.Contains(stderr, "[import]") || strings.Contains(stderr, "\x1b[") {
+		t.Errorf("expected no progress on redirected stderr, got:\n%q", stderr)
+	}
+}
+
+func TestCLI_MaxRowsWarn(t *testing.T) {
+	// The guard only asks in the REPL on a terminal; -q prints everything
+	stdout, stderr, err := runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--max-rows-warn", "1",
+		"-q", "SELECT * FROM simple ORDER BY id")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "bob@example.com")
+
+	_, stderr, err = runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--max-rows-warn", "-1", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "--max-rows-warn must be 0 or greater")
+}
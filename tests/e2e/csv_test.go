@@ -89,3 +89,44 @@ func TestCSV_SelectSpecificColumns(t *testing.T) {
 	assertContains(t, stdout, "name")
 	assertContains(t, stdout, "John")
 }
+
+func TestCSV_DuplicateHeadersAreSuffixed(t *testing.T) {
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/messy_headers.csv"),
+		"-q", `SELECT "First Name", ID_2 FROM messy_headers WHERE id = 2`)
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Bruno")
+	assertContains(t, stdout, "20")
+}
+
+func TestCSV_ColumnNamesSnake(t *testing.T) {
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/messy_headers.csv"),
+		"--column-names", "snake",
+		"-q", "SELECT first_name, last_name, e_mail_address, price FROM messy_headers WHERE id_2 = 10")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "ana@example.com")
+	assertContains(t, stdout, "9.5")
+}
+
+func TestCSV_ColumnDedupeError(t *testing.T) {
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/messy_headers.csv"),
+		"--column-dedupe", "error",
+		"-q", "SELECT * FROM messy_headers")
+
+	assertError(t, err)
+	assertContains(t, stderr, `duplicate column name "ID"`)
+}
+
+func TestCSV_InvalidColumnNames(t *testing.T) {
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/messy_headers.csv"),
+		"--column-names", "camel",
+		"-q", "SELECT * FROM messy_headers")
+
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --column-names")
+}
@@ -256,3 +256,15 @@ func TestDatabase_MySQLNoTable(t *testing.T) {
 		t.Errorf("Expected table name required error, got: %s", stderr)
 	}
 }
+
+func TestDatabase_UnresolvedSecretPlaceholder(t *testing.T) {
+	_, stderr, err := runDataQL(t, "run",
+		"-f", "postgres://app:{{env:DATAQL_TEST_UNSET_PASSWORD}}@localhost:5432/testdb/testtable",
+		"-q", "SELECT * FROM testtable")
+	if err == nil {
+		t.Error("Expected error for an unresolved secret placeholder")
+	}
+	if !strings.Contains(stderr, "DATAQL_TEST_UNSET_PASSWORD is not set") {
+		t.Errorf("Expected unresolved secret error, got: %s", stderr)
+	}
+}
@@ -231,3 +231,58 @@ func TestDateTime_MonthName(t *testing.T) {
 	assertNoError(t, err, stderr)
 	assertContains(t, stdout, "month_name")
 }
+
+// ============================================
+// Time Zone Tests
+// ============================================
+
+func TestDateTime_InputTZ(t *testing.T) {
+	// Naive local times and UTC logs compare as the same instants
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/local_events.csv"),
+		"--input-tz", "America/Sao_Paulo",
+		"-q", "SELECT COUNT(*) FILTER (WHERE local_time = utc_time) AS same_instant, typeof(ANY_VALUE(local_time)) AS kind FROM local_events")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "2")
+	assertContains(t, stdout, "TIMESTAMP WITH TIME ZONE")
+}
+
+func TestDateTime_OutputTZExport(t *testing.T) {
+	outputFile := tempFile(t, "events.csv")
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/local_events.csv"),
+		"--input-tz", "UTC",
+		"--output-tz", "America/New_York",
+		"-q", "SELECT id, utc_time FROM local_events ORDER BY id",
+		"-e", outputFile,
+		"-t", "csv")
+
+	assertNoError(t, err, stderr)
+	content := readFile(t, outputFile)
+	assertContains(t, content, "2024-03-10T07:30:00-04:00")
+	assertContains(t, content, "2024-07-01T08:00:00-04:00")
+}
+
+func TestDateTime_NaiveTimestampExportHasNoOffset(t *testing.T) {
+	outputFile := tempFile(t, "events.jsonl")
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/local_events.csv"),
+		"-q", "SELECT CAST(local_time AS TIMESTAMP) AS local_time FROM local_events ORDER BY id",
+		"-e", outputFile,
+		"-t", "jsonl")
+
+	assertNoError(t, err, stderr)
+	content := readFile(t, outputFile)
+	assertContains(t, content, `"local_time":"2024-03-10T08:30:00"`)
+}
+
+func TestDateTime_InvalidTimeZone(t *testing.T) {
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/local_events.csv"),
+		"--output-tz", "Mars/Olympus",
+		"-q", "SELECT 1")
+
+	assertError(t, err)
+	assertContains(t, stderr, "invalid time zone")
+}
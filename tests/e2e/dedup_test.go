@@ -0,0 +1,72 @@
+package e2e_test
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const accountsCSV = `id,name,updated_at
+1,ana,2024-01-03
+2,bob,2024-01-01
+1,ana b,2024-01-05
+3,cid,2024-01-02
+1,ana c,2024-01-04
+2,bob,2024-01-01
+`
+
+func TestDedup_KeepPolicies(t *testing.T) {
+	input := tempFileWithContent(t, "accounts.csv", accountsCSV)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"whole rows", nil,
+			"id,name,updated_at\n1,ana,2024-01-03\n2,bob,2024-01-01\n1,ana b,2024-01-05\n3,cid,2024-01-02\n1,ana c,2024-01-04\n"},
+		{"first", []string{"--key", "id"},
+			"id,name,updated_at\n1,ana,2024-01-03\n2,bob,2024-01-01\n3,cid,2024-01-02\n"},
+		{"last", []string{"--key", "id", "--keep", "last"},
+			"id,name,updated_at\n3,cid,2024-01-02\n1,ana c,2024-01-04\n2,bob,2024-01-01\n"},
+		{"newest", []string{"--key", "id", "--keep", "newest", "--by", "updated_at"},
+			"id,name,updated_at\n2,bob,2024-01-01\n1,ana b,2024-01-05\n3,cid,2024-01-02\n"},
+		{"oldest", []string{"-k", "id", "--keep", "oldest", "--by", "updated_at"},
+			"id,name,updated_at\n1,ana,2024-01-03\n2,bob,2024-01-01\n3,cid,2024-01-02\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := tempFile(t, "clean.csv")
+			_, stderr, err := runDataQL(t, append([]string{"dedup", input, "-e", output}, tt.args...)...)
+			assertNoError(t, err, stderr)
+
+			if got := readFile(t, output); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDedup_Errors(t *testing.T) {
+	input := tempFileWithContent(t, "accounts.csv", accountsCSV)
+	output := filepath.Join(t.TempDir(), "clean.csv")
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no export", []string{input, "-k", "id"}},
+		{"unknown key", []string{input, "-e", output, "-k", "email"}},
+		{"invalid policy", []string{input, "-e", output, "-k", "id", "--keep", "random"}},
+		{"newest without column", []string{input, "-e", output, "-k", "id", "--keep", "newest"}},
+		{"column without newest", []string{input, "-e", output, "-k", "id", "--by", "updated_at"}},
+		{"unknown order column", []string{input, "-e", output, "-k", "id", "--keep", "newest", "--by", "created_at"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runDataQL(t, append([]string{"dedup"}, tt.args...)...)
+			assertError(t, err)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package e2e_test
 
 import (
+	"bytes"
+	"compress/gzip"
 	"os"
 	"path/filepath"
 	"testing"
@@ -120,6 +122,288 @@ func TestDescribe_WithNulls(t *testing.T) {
 	assertContains(t, stdout, "Total rows: 4")
 }
 
+func TestDescribe_TopValues(t *testing.T) {
+	stdout, stderr, err := runDataQL(t, "describe",
+		"-f", "tests/fixtures/csv/null_values.csv",
+		"--top-values", "3",
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Top values:")
+	assertContains(t, stdout, "status (VARCHAR)")
+	assertContains(t, stdout, "inactive")
+	assertContains(t, stdout, "28.6%")
+}
+
+func TestDescribe_Histogram(t *testing.T) {
+	stdout, stderr, err := runDataQL(t, "describe",
+		"-f", "tests/fixtures/csv/null_values.csv",
+		"--histogram",
+		"--bins", "5",
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Histograms:")
+	assertContains(t, stdout, "value (BIGINT)")
+	assertContains(t, stdout, "0 - 60")
+	assertContains(t, stdout, "240 - 300")
+	assertContains(t, stdout, "█")
+}
+
+func TestDescribe_Outliers(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "readings.csv")
+
+	content := "id,sensor,reading\n1,a,20\n2,a,21\n3,b,19\n4,b,22\n5,a,20\n6,b,950\n7,a,21\n8,b,-40\n9,a,20\n10,b,19\n"
+	if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write CSV file: %v", err)
+	}
+
+	stdout, stderr, err := runDataQL(t, "describe",
+		"-f", csvPath,
+		"--outliers",
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Outliers")
+	assertContains(t, stdout, "reading (BIGINT): 2 outside")
+	assertContains(t, stdout, "950")
+	assertNotContains(t, stdout, "id (BIGINT)")
+}
+
+func TestDescribe_Completeness(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "blank.csv")
+
+	content := "id,name,score\n1,Alice,2\n,,\n3,,\n"
+	if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write CSV file: %v", err)
+	}
+
+	stdout, stderr, err := runDataQL(t, "describe",
+		"-f", csvPath,
+		"--completeness",
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Completeness:")
+	assertContains(t, stdout, "66.7%")
+	assertContains(t, stdout, "Blank rows (every column NULL or empty): 1")
+}
+
+func TestDescribe_Cardinality(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "orders.csv")
+
+	content := "id,region,status\n1,eu,open\n2,us,open\n3,eu,open\n"
+	if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write CSV file: %v", err)
+	}
+
+	stdout, stderr, err := runDataQL(t, "describe",
+		"-f", csvPath,
+		"--cardinality",
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Cardinality:")
+	assertContains(t, stdout, "key candidate")
+	assertContains(t, stdout, "constant")
+	assertContains(t, stdout, "Key candidates: id")
+}
+
+func TestDescribe_Temporal(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "events.csv")
+
+	content := "id,created\n1,2024-01-01T10:00:00Z\n2,2024-01-02 11:00:00+02:00\n3,2024-01-05\n"
+	if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write CSV file: %v", err)
+	}
+
+	stdout, stderr, err := runDataQL(t, "describe",
+		"-f", csvPath,
+		"--temporal",
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Temporal:")
+	assertContains(t, stdout, "Records per day:")
+	assertContains(t, stdout, "Gaps: 2 days without records, longest 2024-01-03 .. 2024-01-04 (2 days)")
+	assertContains(t, stdout, "values mix formats or UTC offsets")
+}
+
+func TestDescribe_CompressedSample(t *testing.T) {
+	tmpDir := t.TempDir()
+	gzPath := filepath.Join(tmpDir, "events.csv.gz")
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte("id,kind\n1,a\n2,b\n3,a\n4,c\n"))
+	_ = gz.Close()
+	if err := os.WriteFile(gzPath, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write compressed file: %v", err)
+	}
+
+	stdout, stderr, err := runDataQL(t, "describe",
+		"-f", gzPath,
+		"--sample", "2",
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "=== Table: events ===")
+	assertContains(t, stdout, "Total rows: 2 (sample: first 2 rows of each source)")
+}
+
+func TestDescribe_SampleRejectsRemoteDownloads(t *testing.T) {
+	_, stderr, err := runDataQL(t, "describe",
+		"-f", "https://example.invalid/users.json",
+		"--sample", "10",
+		"-Q")
+
+	assertError(t, err)
+	assertContains(t, stderr, "only remote Parquet and CSV files are read in place")
+}
+
+func TestDescribe_By(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "sales.csv")
+
+	content := "region,amount\neu,10\neu,30\nus,5\n"
+	if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write CSV file: %v", err)
+	}
+
+	stdout, stderr, err := runDataQL(t, "describe",
+		"-f", csvPath,
+		"--by", "region",
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Grouped by region (2 groups):")
+	assertContains(t, stdout, "amount (BIGINT)")
+	assertContains(t, stdout, "eu")
+	assertContains(t, stdout, "us")
+}
+
+func TestDescribe_ByUnknownColumn(t *testing.T) {
+	_, _, err := runDataQL(t, "describe",
+		"-f", "tests/fixtures/csv/users.csv",
+		"--by", "region",
+		"-Q")
+
+	assertError(t, err)
+}
+
+func TestDescribe_Score(t *testing.T) {
+	tmpDir := t.TempDir()
+	csvPath := filepath.Join(tmpDir, "sales.csv")
+	outputPath := filepath.Join(tmpDir, "quality.json")
+
+	content := "id,region,amount\n1,eu,10\n2,EU,12\n3,eu,n/a\n4,,14\n4,,14\n"
+	if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write CSV file: %v", err)
+	}
+
+	stdout, stderr, err := runDataQL(t, "describe",
+		"-f", csvPath,
+		"--score",
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Quality score: 87.2 / 100")
+	assertContains(t, stdout, "1 duplicate rows")
+	assertContains(t, stdout, "as DOUBLE")
+
+	_, stderr, err = runDataQL(t, "describe",
+		"-f", csvPath,
+		"--score-weights", "uniqueness=0",
+		"-e", outputPath,
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read profile: %v", err)
+	}
+	assertContains(t, string(data), `"quality": {`)
+	assertContains(t, string(data), `"uniqueness": 0`)
+}
+
+func TestDescribe_Compare(t *testing.T) {
+	tmpDir := t.TempDir()
+	baselinePath := filepath.Join(tmpDir, "sales_2024_01.csv")
+	currentPath := filepath.Join(tmpDir, "sales_2024_02.csv")
+
+	if err := os.WriteFile(baselinePath, []byte("id,amount,status\n1,10,open\n2,12,open\n3,11,closed\n4,13,open\n"), 0644); err != nil {
+		t.Fatalf("Failed to write CSV file: %v", err)
+	}
+	if err := os.WriteFile(currentPath, []byte("id,amount,status,channel\n1,100,open,web\n2,120,open,web\n3,110,closed,app\n4,130,open,web\n5,,open,web\n"), 0644); err != nil {
+		t.Fatalf("Failed to write CSV file: %v", err)
+	}
+
+	stdout, stderr, err := runDataQL(t, "describe",
+		"-f", currentPath,
+		"--compare", baselinePath,
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Drift against "+baselinePath+" (table sales_2024_01, 4 rows -> 5 rows)")
+	assertContains(t, stdout, "Added columns: channel")
+	assertContains(t, stdout, "distribution shifted")
+	assertContains(t, stdout, "1 columns with a significant distribution shift")
+}
+
+func TestDescribe_ExportJSON(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "profile.json")
+
+	stdout, stderr, err := runDataQL(t, "describe",
+		"-f", "tests/fixtures/csv/users.csv",
+		"-e", outputPath,
+		"-t", "json",
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "profile successfully exported")
+	assertNotContains(t, stdout, "Total rows")
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read profile: %v", err)
+	}
+	assertContains(t, string(content), `"name": "users"`)
+	assertContains(t, string(content), `"rows": 3`)
+	assertContains(t, string(content), `"distinct": 3`)
+}
+
+func TestDescribe_ExportCSVFromExtension(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "profile.csv")
+
+	_, stderr, err := runDataQL(t, "describe",
+		"-f", "tests/fixtures/csv/users.csv",
+		"-e", outputPath,
+		"-Q")
+
+	assertNoError(t, err, stderr)
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read profile: %v", err)
+	}
+	assertContains(t, string(content), "table,rows,column,type,nulls,null_pct,empty,distinct")
+	assertContains(t, string(content), "users,3,id,BIGINT,0,0,,3,1,3,2")
+}
+
+func TestDescribe_ExportUnsupportedType(t *testing.T) {
+	_, stderr, err := runDataQL(t, "describe",
+		"-f", "tests/fixtures/csv/users.csv",
+		"-e", filepath.Join(t.TempDir(), "profile.parquet"),
+		"-Q")
+
+	assertError(t, err)
+	assertContains(t, stderr, "not supported")
+}
+
 func TestDescribe_CompressedFile(t *testing.T) {
 	// Create a temp gzip file
 	tmpDir := t.TempDir()
@@ -65,3 +65,27 @@ func TestDuckDB_MemoryURL(t *testing.T) {
 
 	t.Log("In-memory DuckDB test - would use duckdb://:memory:/tablename")
 }
+
+func TestDuckDB_AliasJoinsDatabaseSources(t *testing.T) {
+	dbFile := tempFile(t, "crm.duckdb")
+	_, stderr, err := runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "-c", "users", "-s", dbFile, "-q", "SELECT 1")
+	assertNoError(t, err, stderr)
+	source := "duckdb://" + dbFile + "/users"
+
+	// The database source is imported next to the file, under its alias
+	stdout, stderr, err := runDataQL(t, "run", "-f", source+"::customers", "-f", fixture("csv/users.csv"),
+		"-q", "SELECT c.email FROM customers c JOIN users u ON c.id = u.id WHERE u.name = 'Bob'")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "jane@example.com")
+
+	_, stderr, err = runDataQL(t, "run", "-f", fixture("csv/users.csv"), "-c", "staff", "-s", dbFile, "-q", "SELECT 1")
+	assertNoError(t, err, stderr)
+	stdout, stderr, err = runDataQL(t, "run", "--alias", "a="+source, "--alias", "b=duckdb://"+dbFile+"/staff",
+		"-q", "SELECT b.name || '@' || a.email AS pair FROM a JOIN b ON a.id = b.id WHERE a.name = 'Jane'")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Bob@jane@example.com")
+
+	_, stderr, err = runDataQL(t, "run", "--alias", source, "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --alias value")
+}
@@ -8,6 +8,8 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/adrianolaselva/dataql/pkg/history"
 )
 
 var binaryPath string
@@ -55,11 +57,20 @@ func getProjectRoot() string {
 	return filepath.Join(dir, "..", "..")
 }
 
+// dataqlCommand returns the command running the dataql binary with args.
+// The query history is off, so the suite does not write to the user's
+// ~/.dataql/history.db.
+func dataqlCommand(args ...string) *exec.Cmd {
+	cmd := exec.Command(binaryPath, args...)
+	cmd.Dir = projectRoot
+	cmd.Env = append(os.Environ(), history.EnvDisable+"=1")
+	return cmd
+}
+
 // runDataQL executes the dataql binary with the given arguments
 func runDataQL(t *testing.T, args ...string) (stdout string, stderr string, err error) {
 	t.Helper()
-	cmd := exec.Command(binaryPath, args...)
-	cmd.Dir = projectRoot
+	cmd := dataqlCommand(args...)
 
 	var stdoutBuf, stderrBuf bytes.Buffer
 	cmd.Stdout = &stdoutBuf
@@ -143,8 +154,7 @@ func countLines(s string) int {
 // runDataQLWithStdin executes the dataql binary with the given stdin input
 func runDataQLWithStdin(t *testing.T, stdinData string, args ...string) (stdout string, stderr string, err error) {
 	t.Helper()
-	cmd := exec.Command(binaryPath, args...)
-	cmd.Dir = projectRoot
+	cmd := dataqlCommand(args...)
 	cmd.Stdin = strings.NewReader(stdinData)
 
 	var stdoutBuf, stderrBuf bytes.Buffer
@@ -408,12 +408,12 @@ func TestRecovery_AfterError_ContinueREPL(t *testing.T) {
 SELECT * FROM simple
 .quit`
 	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
-		"-f", fixture("csv/simple.csv"))
+		"-f", fixture("csv/simple.csv"), "--continue-on-error")
 
-	// First query errors, but REPL should continue
+	// First query errors, but REPL should continue and still report the failure
 	assertContains(t, stdout, "rows)")
 	_ = stderr
-	_ = err
+	assertError(t, err)
 }
 
 func TestRecovery_InvalidThenValid_Query(t *testing.T) {
@@ -421,10 +421,10 @@ func TestRecovery_InvalidThenValid_Query(t *testing.T) {
 SELECT * FROM simple LIMIT 1
 .quit`
 	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
-		"-f", fixture("csv/simple.csv"))
+		"-f", fixture("csv/simple.csv"), "--continue-on-error")
 
 	// Should recover and execute valid query
 	assertContains(t, stdout, "(1 rows)")
 	_ = stderr
-	_ = err
+	assertError(t, err)
 }
@@ -1,6 +1,9 @@
 package e2e_test
 
 import (
+	"encoding/json"
+	"errors"
+	"os/exec"
 	"strings"
 	"testing"
 )
@@ -164,3 +167,82 @@ func TestEnhancedError_DateFunctions_WorkCorrectly(t *testing.T) {
 		})
 	}
 }
+
+// TestErrorFormat_JSON tests that --error-format json reports the error category on stderr
+func TestErrorFormat_JSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		code     string
+		category string
+		source   string
+	}{
+		{
+			name:     "missing source",
+			args:     []string{"run", "-f", "missing.csv", "-q", "SELECT 1", "-Q"},
+			code:     "source_not_found",
+			category: "source",
+			source:   "missing.csv",
+		},
+		{
+			name:     "SQL syntax error",
+			args:     []string{"run", "-f", fixture("csv/simple.csv"), "-q", "SELEC * FROM simple", "-Q"},
+			code:     "sql_syntax_error",
+			category: "query",
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"run", "--bogus"},
+			code:     "invalid_arguments",
+			category: "parse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr, err := runDataQL(t, append([]string{"--error-format", "json"}, tt.args...)...)
+			assertError(t, err)
+
+			var report map[string]any
+			line := stderr[strings.LastIndex(strings.TrimSpace(stderr), "\n")+1:]
+			if err := json.Unmarshal([]byte(line), &report); err != nil {
+				t.Fatalf("stderr is not a JSON object: %v\n%s", err, stderr)
+			}
+			source, _ := report["source"].(string)
+			if report["code"] != tt.code || report["category"] != tt.category || source != tt.source {
+				t.Errorf("unexpected report: %v", report)
+			}
+		})
+	}
+}
+
+// TestExitCodes tests that each kind of failure exits with its own code
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"invalid arguments", []string{"run", "--bogus"}, 2},
+		{"source not found", []string{"run", "-f", "missing.csv", "-q", "SELECT 1", "-Q"}, 3},
+		{"SQL error", []string{"run", "-f", fixture("csv/simple.csv"), "-q", "SELECT missing FROM simple", "-Q"}, 5},
+		{"export error", []string{"run", "-f", fixture("csv/simple.csv"), "-q", "SELECT * FROM simple", "-e", "/proc/dataql/out.csv", "-t", "csv", "-Q"}, 6},
+		{"empty result", []string{"run", "-f", fixture("csv/simple.csv"), "-q", "SELECT * FROM simple WHERE 1 = 0", "--fail-on-empty", "-Q"}, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr, err := runDataQL(t, tt.args...)
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				t.Fatalf("expected an exit error, got %v\n%s", err, stderr)
+			}
+			if exitErr.ExitCode() != tt.code {
+				t.Errorf("expected exit code %d, got %d\n%s", tt.code, exitErr.ExitCode(), stderr)
+			}
+		})
+	}
+
+	_, stderr, err := runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "-q", "SELECT * FROM simple", "--fail-on-empty", "-Q")
+	assertNoError(t, err, stderr)
+}
@@ -127,3 +127,56 @@ func TestExport_CSVToJSONL(t *testing.T) {
 		t.Errorf("Expected 3 lines, got %d", len(lines))
 	}
 }
+
+func TestExport_LineageSidecar(t *testing.T) {
+	outputFile := tempFile(t, "lineage.csv")
+
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-q", "SELECT * FROM simple WHERE id > 1",
+		"-e", outputFile,
+		"-t", "csv",
+		"--lineage")
+
+	assertNoError(t, err, stderr)
+
+	content := readFile(t, outputFile+".lineage.json")
+	assertContains(t, content, "simple.csv")
+	assertContains(t, content, `"sha256"`)
+	assertContains(t, content, "SELECT * FROM simple WHERE id > 1")
+	assertContains(t, content, `"dataql_version"`)
+}
+
+func TestExport_LineageInParquetMetadata(t *testing.T) {
+	outputFile := tempFile(t, "lineage.parquet")
+
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-q", "SELECT * FROM simple",
+		"-e", outputFile,
+		"-t", "parquet",
+		"--lineage")
+
+	assertNoError(t, err, stderr)
+	if fileExists(outputFile + ".lineage.json") {
+		t.Error("Expected the lineage to be embedded instead of a sidecar file")
+	}
+
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-q", "SELECT CAST(value AS VARCHAR) AS lineage FROM parquet_kv_metadata('"+outputFile+"') WHERE CAST(key AS VARCHAR) = 'dataql.lineage'",
+		"-Q")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "simple.csv")
+	assertContains(t, stdout, "SELECT * FROM simple")
+}
+
+func TestExport_LineageRequiresExport(t *testing.T) {
+	_, _, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-q", "SELECT * FROM simple",
+		"--lineage")
+
+	assertError(t, err)
+}
@@ -159,3 +159,9 @@ func TestGCS_ParquetFile(t *testing.T) {
 
 	t.Logf("GCS Parquet output: %s", stdout)
 }
+
+func TestGCS_MissingCredentialsFile(t *testing.T) {
+	_, stderr, err := runDataQL(t, "run", "-f", "gs://bucket/data.csv", "--gcs-credentials", "/nonexistent/key.json", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --gcs-credentials")
+}
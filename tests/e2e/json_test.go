@@ -118,3 +118,50 @@ func TestJSON_ExportDataIntegrity_RegressionBug(t *testing.T) {
 	assertContains(t, content, "Bob")
 	assertContains(t, content, "Charlie")
 }
+
+func TestJSON_NestedStructExportKeepsShape(t *testing.T) {
+	outputFile := tempFile(t, "nested_output.json")
+
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("json/nested.json"),
+		"--json-nested", "struct",
+		"-q", "SELECT id, user.name AS name FROM nested WHERE user.email LIKE 'jane%'")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Jane")
+	assertNotContains(t, stdout, "John")
+
+	_, stderr, err = runDataQL(t, "run",
+		"-f", fixture("json/nested.json"),
+		"--json-nested", "struct",
+		"-q", "SELECT * FROM nested",
+		"-e", outputFile,
+		"-t", "json")
+
+	assertNoError(t, err, stderr)
+	content := readFile(t, outputFile)
+	assertContains(t, content, `"user": {`)
+	assertContains(t, content, `"email": "john@test.com"`)
+}
+
+func TestJSON_ColumnNamesSnake(t *testing.T) {
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("json/camel_keys.json"),
+		"--column-names", "snake",
+		"-q", "SELECT customer_name, shipping_address_zip_code FROM camel_keys WHERE order_id = 2")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Bruno")
+	assertContains(t, stdout, "20040-002")
+}
+
+func TestJSON_ColumnNamesKeep(t *testing.T) {
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("json/camel_keys.json"),
+		"--column-names", "keep",
+		"-q", `SELECT "Customer Name", "shippingAddress_zipCode" FROM camel_keys WHERE "orderId" = 1`)
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Ana")
+	assertContains(t, stdout, "01001-000")
+}
@@ -0,0 +1,67 @@
+package e2e_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const customersCSV = `id,firstName,contact,birth_date,server,note
+1,Ana,ana@example.org,1990-05-01,10.0.0.1,likes tea
+2,Bob,bob@example.net,1985-11-23,10.0.0.2,
+`
+
+func TestMask_DetectedColumns(t *testing.T) {
+	input := tempFileWithContent(t, "customers.csv", customersCSV)
+	output := tempFile(t, "masked.csv")
+
+	_, stderr, err := runDataQL(t, "mask", input, "-e", output, "--salt", "s", "--shift-days", "1")
+	assertNoError(t, err, stderr)
+
+	text := readFile(t, output)
+	if !strings.HasPrefix(text, "id,firstName,contact,birth_date,server,note\n") {
+		t.Errorf("Expected the header to be kept, got:\n%s", text)
+	}
+	assertContains(t, text, ",REDACTED,user_")
+	assertContains(t, text, "@example.com,1990-05-02,")
+	assertContains(t, text, "likes tea")
+	for _, secret := range []string{"Ana", "ana@example.org", "1990-05-01", "10.0.0.1"} {
+		assertNotContains(t, text, secret)
+	}
+}
+
+func TestMask_Rules(t *testing.T) {
+	input := tempFileWithContent(t, "customers.csv", customersCSV)
+	output := tempFile(t, "masked.csv")
+
+	_, stderr, err := runDataQL(t, "mask", input, "-e", output, "-m", "note=null")
+	assertNoError(t, err, stderr)
+
+	// Only the given columns are masked
+	expected := strings.ReplaceAll(customersCSV, ",likes tea", ",")
+	if got := readFile(t, output); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestMask_Errors(t *testing.T) {
+	input := tempFileWithContent(t, "customers.csv", customersCSV)
+	output := filepath.Join(t.TempDir(), "masked.csv")
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no export", []string{input}},
+		{"invalid rule", []string{input, "-e", output, "-m", "contact"}},
+		{"unknown method", []string{input, "-e", output, "-m", "contact=scramble"}},
+		{"unknown column", []string{input, "-e", output, "-m", "phone=redact"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runDataQL(t, append([]string{"mask"}, tt.args...)...)
+			assertError(t, err)
+		})
+	}
+}
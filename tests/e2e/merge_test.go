@@ -0,0 +1,72 @@
+package e2e_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeDailyParts writes two daily partitions with different columns and
+// returns their directory
+func writeDailyParts(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "day1.csv"), []byte("id,amount\n1,10\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "day2.csv"), []byte("amount,id,note\n20,2,late\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	return dir
+}
+
+func TestMerge_Schemas(t *testing.T) {
+	dir := writeDailyParts(t)
+	pattern := filepath.Join(dir, "day*.csv")
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"union", nil, "id,amount,note\n1,10,\n2,20,late\n"},
+		{"intersection", []string{"--schema", "intersection"}, "id,amount\n1,10\n2,20\n"},
+		{"source column", []string{"--schema", "intersection", "--source-column", "file"},
+			"id,amount,file\n1,10," + filepath.Join(dir, "day1.csv") + "\n2,20," + filepath.Join(dir, "day2.csv") + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := tempFile(t, "merged.csv")
+			_, stderr, err := runDataQL(t, append([]string{"merge", pattern, "-e", output}, tt.args...)...)
+			assertNoError(t, err, stderr)
+
+			if got := readFile(t, output); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMerge_Errors(t *testing.T) {
+	dir := writeDailyParts(t)
+	output := filepath.Join(dir, "out.csv")
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"strict schema", []string{filepath.Join(dir, "day*.csv"), "--schema", "strict"}, "missing: [], extra: [note]"},
+		{"no match", []string{filepath.Join(dir, "week*.csv")}, "no files match"},
+		{"invalid schema", []string{filepath.Join(dir, "day1.csv"), "--schema", "loose"}, "invalid --schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr, err := runDataQL(t, append([]string{"merge", "-e", output}, tt.args...)...)
+			assertError(t, err)
+			assertContains(t, stderr, tt.expected)
+		})
+	}
+}
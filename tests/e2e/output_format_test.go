@@ -229,3 +229,63 @@ func TestOutput_VerticalFlag_ManyColumns(t *testing.T) {
 	assertContains(t, stdout, "event_name:")
 	assertContains(t, stdout, "event_date:")
 }
+
+// ============================================
+// Plain Output and --mode Tests
+// ============================================
+
+func TestOutput_Plain_CSV(t *testing.T) {
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-q", "SELECT id, name FROM simple ORDER BY id LIMIT 2",
+		"--plain", "--mode", "csv")
+
+	assertNoError(t, err, stderr)
+	if stdout != "id,name\n1,John\n2,Jane\n" {
+		t.Errorf("expected only the CSV data, got:\n%q", stdout)
+	}
+}
+
+func TestOutput_Plain_Table(t *testing.T) {
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-q", "SELECT id, name FROM simple ORDER BY id",
+		"--plain")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "John")
+	if strings.Contains(stdout, "rows)") || strings.Contains(stdout, "\x1b[") {
+		t.Errorf("expected no row count or colors, got:\n%q", stdout)
+	}
+}
+
+func TestOutput_Plain_Export(t *testing.T) {
+	output := tempFile(t, "plain.csv")
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-q", "SELECT * FROM simple",
+		"-e", output, "-t", "csv", "--plain")
+
+	assertNoError(t, err, stderr)
+	if stdout != "" {
+		t.Errorf("expected no confirmation, got:\n%q", stdout)
+	}
+}
+
+func TestOutput_ModeFlag(t *testing.T) {
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-q", "SELECT id FROM simple ORDER BY id LIMIT 1",
+		"--mode", "JSONL")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, `{"id":1}`)
+	assertContains(t, stdout, "(1 rows)")
+
+	_, stderr, err = runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-q", "SELECT 1",
+		"--mode", "xml")
+	assertError(t, err)
+	assertContains(t, stderr, "invalid mode: xml")
+}
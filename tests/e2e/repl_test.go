@@ -1,6 +1,8 @@
 package e2e_test
 
 import (
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -368,3 +370,305 @@ func TestREPL_WithMultipleFiles(t *testing.T) {
 	assertContains(t, stdout, "simple")
 	assertContains(t, stdout, "users")
 }
+
+// TestREPL_ModeCommand tests switching the result display format with .mode
+func TestREPL_ModeCommand(t *testing.T) {
+	commands := `.mode json
+SELECT name FROM simple WHERE id = 1
+.mode csv
+SELECT name FROM simple WHERE id = 1
+.mode
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, `"name": "John"`)
+	assertContains(t, stdout, "name\nJohn")
+	assertContains(t, stdout, "Current mode: csv")
+}
+
+func TestREPL_ModeCommand_Invalid(t *testing.T) {
+	commands := `.mode xml
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	_ = err
+	assertContains(t, stdout+stderr, "invalid mode")
+}
+
+// TestREPL_SaveCommand tests persisting the session and reopening it in storage-only mode
+func TestREPL_SaveCommand(t *testing.T) {
+	sessionFile := tempFile(t, "Session.duckdb")
+	commands := ".save " + sessionFile + "\n.quit"
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Session saved")
+
+	stdout, stderr, err = runDataQL(t, "run",
+		"-s", sessionFile,
+		"-q", "SELECT COUNT(*) AS total FROM simple")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "3")
+}
+
+// TestREPL_SampleCommand tests showing random rows with .sample
+func TestREPL_SampleCommand(t *testing.T) {
+	commands := `.sample simple 2
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "email")
+	if n := strings.Count(stdout, "@example.com"); n != 2 {
+		t.Errorf("Expected 2 sampled rows, got %d:\n%s", n, stdout)
+	}
+}
+
+func TestREPL_SampleCommand_InvalidSize(t *testing.T) {
+	commands := `.sample simple zero
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	_ = err
+	assertContains(t, stdout+stderr, "invalid sample size")
+}
+
+// TestREPL_Transactions tests BEGIN/ROLLBACK/COMMIT and .autocommit against persistent storage
+func TestREPL_Transactions(t *testing.T) {
+	dbFile := tempFile(t, "tx.duckdb")
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-s", dbFile,
+		"-q", "SELECT COUNT(*) FROM simple")
+	assertNoError(t, err, stderr)
+
+	commands := `BEGIN;
+DELETE FROM simple;
+ROLLBACK;
+.autocommit off
+UPDATE simple SET name = 'Johnny' WHERE id = 1;
+COMMIT;
+.autocommit off
+DELETE FROM simple WHERE id = 2;
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run", "-s", dbFile)
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "ROLLBACK")
+	assertContains(t, stdout, "COMMIT")
+	assertContains(t, stderr, "uncommitted transaction was rolled back")
+
+	stdout, stderr, err = runDataQL(t, "run", "-s", dbFile,
+		"-q", "SELECT COUNT(*) AS total, MAX(name) AS top FROM simple")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "3")
+	assertContains(t, stdout, "Johnny")
+}
+
+func TestRun_NoAutoCommitRollsBackOnError(t *testing.T) {
+	dbFile := tempFile(t, "tx.duckdb")
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-s", dbFile,
+		"-q", "SELECT COUNT(*) FROM simple")
+	assertNoError(t, err, stderr)
+
+	_, _, err = runDataQL(t, "run", "-s", dbFile, "--no-autocommit",
+		"-q", "DELETE FROM simple; SELECT * FROM missing_table")
+	assertError(t, err)
+
+	stdout, stderr, err := runDataQL(t, "run", "-s", dbFile,
+		"-q", "SELECT COUNT(*) AS total FROM simple")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "3")
+}
+
+// TestREPL_ConfigAliases tests user-defined aliases from the [aliases] config section
+func TestREPL_ConfigAliases(t *testing.T) {
+	configFile := tempFile(t, "config")
+	content := "[aliases]\nfirst = SELECT name FROM simple ORDER BY id LIMIT :1\n"
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	t.Setenv("DATAQL_CONFIG", configFile)
+
+	commands := `.first 1
+.aliases
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "John")
+	assertNotContains(t, stdout, "Jane")
+	assertContains(t, stdout, ".first")
+
+	_, stderr, err = runDataQLWithStdin(t, ".first", "run",
+		"-f", fixture("csv/simple.csv"))
+	assertError(t, err)
+	assertContains(t, stderr, "expected 1 argument(s), got 0")
+}
+
+// TestREPL_StartupFile tests that ~/.dataqlrc (or $DATAQLRC) runs before the first prompt
+func TestREPL_StartupFile(t *testing.T) {
+	rcFile := tempFile(t, "dataqlrc")
+	content := "-- preferred settings\n.mode csv\nCREATE VIEW first_user AS SELECT name FROM simple WHERE id = 1;\n"
+	if err := os.WriteFile(rcFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write startup file: %v", err)
+	}
+	t.Setenv("DATAQLRC", rcFile)
+
+	commands := `SELECT * FROM first_user;
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"), "--no-schema")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "name\nJohn\n")
+	assertNotContains(t, stdout, "Output mode set")
+
+	// --no-rc skips the startup file
+	_, stderr, _ = runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"), "--no-rc")
+	assertContains(t, stderr, "first_user")
+}
+
+// TestREPL_Script_StopsOnFirstError tests that piped input stops at the first failing line
+func TestREPL_Script_StopsOnFirstError(t *testing.T) {
+	commands := `SELECT name FROM simple WHERE id = 1;
+SELECT * FROM missing_table;
+SELECT name FROM simple WHERE id = 2;`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	assertError(t, err)
+	assertContains(t, stdout, "John")
+	assertNotContains(t, stdout, "Jane")
+	assertContains(t, stderr, "line 2")
+	assertNotContains(t, stdout, "dataql>")
+}
+
+func TestREPL_Script_ContinueOnError(t *testing.T) {
+	commands := `-- comments are skipped
+SELECT * FROM missing_table;
+SELECT name FROM simple WHERE id = 2;`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"), "--continue-on-error")
+
+	assertError(t, err)
+	assertContains(t, stdout, "Jane")
+	assertContains(t, stderr, "1 statement(s) failed")
+}
+
+// TestREPL_DiffCommand tests comparing two tables with .diff
+func TestREPL_DiffCommand(t *testing.T) {
+	commands := `CREATE TABLE changed AS SELECT * FROM simple;
+UPDATE changed SET email = 'jane@new.com' WHERE id = 2;
+DELETE FROM changed WHERE id = 3;
+INSERT INTO changed VALUES (4, 'Ann', 'ann@example.com');
+.diff simple changed --key id
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Diff: simple (3 rows) -> changed (3 rows)")
+	assertContains(t, stdout, "Added")
+	assertContains(t, stdout, "Removed")
+	assertContains(t, stdout, "Changed")
+	assertContains(t, stdout, "email")
+}
+
+func TestREPL_DiffCommand_InvalidKey(t *testing.T) {
+	commands := `.diff simple simple --key missing
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	_ = err
+	assertContains(t, stdout+stderr, `key column "missing" not found`)
+}
+
+// TestREPL_ConnectCommand tests importing a database table mid-session with .connect
+func TestREPL_ConnectCommand(t *testing.T) {
+	dbFile := tempFile(t, "lookup.duckdb")
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("csv/simple.csv"),
+		"-c", "users",
+		"-s", dbFile,
+		"-q", "SELECT COUNT(*) FROM users")
+	assertNoError(t, err, stderr)
+
+	commands := ".connect duckdb://" + dbFile + "/users lookup\nSELECT s.name, l.email FROM simple s JOIN lookup l ON s.id = l.id WHERE s.id = 2;\n.quit"
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "imported 3 rows")
+	assertContains(t, stdout, "jane@example.com")
+}
+
+func TestREPL_ConnectCommand_InvalidURL(t *testing.T) {
+	commands := `.connect data.csv
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	_ = err
+	assertContains(t, stdout+stderr, "not a database URL")
+}
+
+// TestREPL_DescribeTable tests the per-column profile shown by .describe and \dt+
+func TestREPL_DescribeTable(t *testing.T) {
+	commands := `.describe simple
+\dt+ simple
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Null %")
+	assertContains(t, stdout, "Distinct")
+	assertContains(t, stdout, "Samples")
+	assertContains(t, stdout, "John")
+}
+
+func TestREPL_DescribeTable_NotFound(t *testing.T) {
+	commands := `.describe missing_table
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	_ = err
+	assertContains(t, stdout+stderr, "table not found")
+}
+
+// TestREPL_WatchCommand tests re-running the previous query with \watch
+func TestREPL_WatchCommand(t *testing.T) {
+	commands := `SELECT COUNT(*) AS total FROM simple
+\watch 0.1 2
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	assertNoError(t, err, stderr)
+	if strings.Count(stdout, "Every 100ms") != 2 {
+		t.Errorf("Expected query to be re-run twice, got:\n%s", stdout)
+	}
+}
+
+func TestREPL_WatchCommand_NoPreviousQuery(t *testing.T) {
+	commands := `\watch 1 1
+.quit`
+	stdout, stderr, err := runDataQLWithStdin(t, commands, "run",
+		"-f", fixture("csv/simple.csv"))
+
+	_ = err
+	assertContains(t, stdout+stderr, "no previous query")
+}
@@ -1,7 +1,10 @@
 package e2e_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -173,3 +176,55 @@ func TestS3_InvalidBucket(t *testing.T) {
 		t.Errorf("Expected S3 error, got: %s", stderr)
 	}
 }
+
+func TestS3_CSVDownloadParts(t *testing.T) {
+	s3URL := getS3CSVURL(t)
+
+	stdout, stderr, err := runDataQL(t, "run", "-f", s3URL, "--download-parts", "4", "--download-part-size", "1KB", "-q", "SELECT COUNT(*) FROM data")
+	if err != nil {
+		t.Fatalf("Failed to run dataql: %v\nstderr: %s", err, stderr)
+	}
+
+	if !strings.Contains(stdout, "COUNT") {
+		t.Errorf("Expected COUNT in output, got: %s", stdout)
+	}
+}
+
+func TestS3_CustomEndpoint(t *testing.T) {
+	// An S3-compatible server (MinIO, Ceph) with path-style addressing
+	var payer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payer = r.Header.Get("X-Amz-Request-Payer")
+		if r.URL.Path != "/bucket/simple.csv" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join(fixturesPath, "csv/simple.csv"))
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "minioadmin")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "minioadmin")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", "s3://bucket/simple.csv",
+		"--s3-endpoint", server.URL,
+		"--s3-requester-pays",
+		"-q", "SELECT COUNT(*) as count FROM simple")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "3")
+	if payer != "requester" {
+		t.Errorf("expected requester-pays requests, got x-amz-request-payer %q", payer)
+	}
+}
+
+func TestS3_InvalidOptions(t *testing.T) {
+	_, stderr, err := runDataQL(t, "run", "-f", "s3://bucket/data.csv", "--s3-endpoint", "localhost:9000", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --s3-endpoint")
+
+	_, stderr, err = runDataQL(t, "run", "-f", "s3://bucket/data.csv", "--s3-assume-role", "reader", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --s3-assume-role")
+}
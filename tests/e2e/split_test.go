@@ -0,0 +1,131 @@
+package e2e_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const salesCSV = "id,region\n1,north\n2,south\n3,north\n4,\n5,south\n"
+
+func TestSplit_Modes(t *testing.T) {
+	input := tempFileWithContent(t, "sales.csv", salesCSV)
+
+	tests := []struct {
+		name     string
+		args     []string
+		out      string
+		expected map[string]string // Content of each file written, by path relative to the output directory
+	}{
+		{"rows", []string{"--rows", "2"}, "chunk-%02d.csv", map[string]string{
+			"chunk-01.csv": "id,region\n1,north\n2,south\n",
+			"chunk-02.csv": "id,region\n3,north\n4,\n",
+			"chunk-03.csv": "id,region\n5,south\n",
+		}},
+		{"by column", []string{"--by-column", "region"}, filepath.Join("region=%s", "sales.csv"), map[string]string{
+			filepath.Join("region=north", "sales.csv"): "id,region\n1,north\n3,north\n",
+			filepath.Join("region=south", "sales.csv"): "id,region\n2,south\n5,south\n",
+			filepath.Join("region=empty", "sales.csv"): "id,region\n4,\n",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			_, stderr, err := runDataQL(t, append([]string{"split", input, "--out", filepath.Join(dir, tt.out)}, tt.args...)...)
+			assertNoError(t, err, stderr)
+
+			for name, expected := range tt.expected {
+				if got := readFile(t, filepath.Join(dir, name)); got != expected {
+					t.Errorf("%s: expected %q, got %q", name, expected, got)
+				}
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("Failed to read output directory: %v", err)
+			}
+			if len(entries) != len(tt.expected) {
+				t.Errorf("Expected %d files, got %d", len(tt.expected), len(entries))
+			}
+		})
+	}
+}
+
+func TestSplit_Size(t *testing.T) {
+	input := tempFileWithContent(t, "sales.csv", salesCSV)
+	dir := t.TempDir()
+
+	// Each row takes a few bytes, so 20 bytes hold fewer rows than the input
+	_, stderr, err := runDataQL(t, "split", input, "--size", "20B", "--out", filepath.Join(dir, "part-%d.csv"))
+	assertNoError(t, err, stderr)
+
+	for _, name := range []string{"part-1.csv", "part-2.csv"} {
+		if !fileExists(filepath.Join(dir, name)) {
+			t.Errorf("Expected %s to be written", name)
+		}
+	}
+}
+
+func TestSplit_Gzip(t *testing.T) {
+	input := tempFileWithContent(t, "sales.csv", salesCSV)
+	dir := t.TempDir()
+
+	_, stderr, err := runDataQL(t, "split", input, "--rows", "10", "--out", filepath.Join(dir, "chunk-%d.csv.gz"))
+	assertNoError(t, err, stderr)
+
+	file, err := os.Open(filepath.Join(dir, "chunk-1.csv.gz"))
+	if err != nil {
+		t.Fatalf("Failed to open chunk: %v", err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Failed to read gzip chunk: %v", err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to read gzip chunk: %v", err)
+	}
+	if string(data) != salesCSV {
+		t.Errorf("Expected %q, got %q", salesCSV, string(data))
+	}
+
+	// Temporary files are removed
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read output directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected 1 file, got %d", len(entries))
+	}
+}
+
+func TestSplit_Errors(t *testing.T) {
+	input := tempFileWithContent(t, "sales.csv", salesCSV)
+	dir := t.TempDir()
+	out := filepath.Join(dir, "chunk-%d.csv")
+	byValue := filepath.Join(dir, "%s.csv")
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no mode", []string{input, "--out", out}},
+		{"two modes", []string{input, "--rows", "2", "--by-column", "region", "--out", out}},
+		{"no chunk number", []string{input, "--rows", "2", "--out", filepath.Join(dir, "chunk.csv")}},
+		{"no value verb", []string{input, "--by-column", "region", "--out", out}},
+		{"invalid size", []string{input, "--size", "lots", "--out", out}},
+		{"unknown column", []string{input, "--by-column", "country", "--out", byValue}},
+		{"too many files", []string{input, "--by-column", "id", "--max-files", "2", "--out", byValue}},
+		{"unknown table", []string{input, "--rows", "2", "--table", "orders", "--out", out}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runDataQL(t, append([]string{"split"}, tt.args...)...)
+			assertError(t, err)
+		})
+	}
+}
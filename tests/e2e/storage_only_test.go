@@ -167,3 +167,131 @@ func randomString(n int) string {
 	}
 	return string(b)
 }
+
+func TestStorageOnly_AttachJoinsStorageFiles(t *testing.T) {
+	usersFile := tempFileWithContent(t, "users.csv", "id,name\n1,Alice\n2,Bob")
+	ordersFile := tempFileWithContent(t, "orders.csv", "id,user_id,total\n1,1,100\n2,1,200\n3,2,150")
+	usersDB := tempFile(t, "users.duckdb")
+	ordersDB := tempFile(t, "sales-2023.duckdb")
+
+	_, stderr, err := runDataQL(t, "run", "-f", usersFile, "-s", usersDB, "-q", "SELECT 1")
+	assertNoError(t, err, stderr)
+	_, stderr, err = runDataQL(t, "run", "-f", ordersFile, "-s", ordersDB, "-q", "SELECT 1")
+	assertNoError(t, err, stderr)
+
+	// Attach both files without a main storage file; the second alias comes from the file name
+	stdout, stderr, err := runDataQL(t, "run",
+		"--attach", usersDB+":u",
+		"--attach", ordersDB,
+		"-q", "SELECT u.name, SUM(o.total) AS spent FROM u.users u JOIN sales_2023.orders o ON u.id = o.user_id GROUP BY u.name ORDER BY u.name")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Alice")
+	assertContains(t, stdout, "300")
+	assertContains(t, stdout, "150")
+
+	// Attached tables can also be joined with imported files
+	stdout, stderr, err = runDataQL(t, "run",
+		"-f", ordersFile,
+		"--attach", usersDB+":u",
+		"-q", "SELECT COUNT(*) AS matched FROM orders JOIN u.users ON users.id = orders.user_id")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "3")
+}
+
+func TestStorageOnly_AttachErrors(t *testing.T) {
+	missing := filepath.Join(os.TempDir(), "nonexistent_db_"+randomString(8)+".duckdb")
+
+	_, stderr, err := runDataQL(t, "run", "--attach", missing, "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "attached storage file does not exist")
+
+	_, stderr, err = runDataQL(t, "run", "--attach", missing+":main", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "is reserved")
+}
+
+func TestStorageOnly_JoinsImportedFiles(t *testing.T) {
+	usersFile := tempFileWithContent(t, "users.csv", "id,name\n1,Alice\n2,Bob")
+	ordersFile := tempFileWithContent(t, "orders.csv", "id,user_id,total\n1,1,100\n2,1,200\n3,2,150")
+	dbFile := tempFile(t, "warehouse.duckdb")
+
+	_, stderr, err := runDataQL(t, "run", "-f", usersFile, "-s", dbFile, "-q", "SELECT 1")
+	assertNoError(t, err, stderr)
+
+	query := "SELECT u.name || '=' || SUM(o.total) AS spent FROM users u JOIN orders o ON u.id = o.user_id GROUP BY u.name ORDER BY u.name"
+	stdout, stderr, err := runDataQL(t, "run", "-f", ordersFile, "-s", dbFile, "-q", query)
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Alice=300")
+	assertContains(t, stdout, "Bob=150")
+
+	// --storage keeps the imported files, so it takes precedence over --cache
+	cachedFile := tempFile(t, "cached.duckdb")
+	_, stderr, err = runDataQL(t, "run", "-f", usersFile, "-s", cachedFile, "--cache", "--cache-dir", t.TempDir(), "-q", "SELECT 1")
+	assertNoError(t, err, stderr)
+
+	stdout, stderr, err = runDataQL(t, "run", "-f", ordersFile, "-s", cachedFile, "--cache", "--cache-dir", t.TempDir(), "-q", query)
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "Alice=300")
+}
+
+func TestStorageOnly_IfExists(t *testing.T) {
+	day1 := tempFileWithContent(t, "day1.csv", "id,total\n1,100\n2,200")
+	day2 := tempFileWithContent(t, "day2.csv", "id,total\n3,300\n4,400")
+	dbFile := tempFile(t, "daily.duckdb")
+	query := "SELECT COUNT(*) || ' rows' AS loaded FROM sales"
+
+	_, stderr, err := runDataQL(t, "run", "-f", day1+"=sales", "-s", dbFile, "-q", "SELECT 1")
+	assertNoError(t, err, stderr)
+
+	// Daily loads accumulate by default
+	stdout, stderr, err := runDataQL(t, "run", "-f", day2+"=sales", "-s", dbFile, "--if-exists", "append", "-q", query)
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "4 rows")
+
+	stdout, stderr, err = runDataQL(t, "run", "-f", day2+"=sales", "-f", day1+"=sales", "-s", dbFile, "--if-exists", "replace", "-q", query)
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "4 rows")
+
+	_, stderr, err = runDataQL(t, "run", "-f", day1+"=sales", "-s", dbFile, "--if-exists", "fail", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "sales is already in the storage file")
+
+	stdout, stderr, err = runDataQL(t, "run", "-s", dbFile, "-q", query)
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "4 rows")
+
+	_, stderr, err = runDataQL(t, "run", "-f", day1, "-s", dbFile, "--if-exists", "merge", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --if-exists")
+}
+
+func TestStorageOnly_CreateTableAsPersists(t *testing.T) {
+	ordersFile := tempFileWithContent(t, "orders.csv", "id,user_id,total\n1,1,100\n2,1,200\n3,2,150")
+	dbFile := tempFile(t, "materialized.duckdb")
+
+	_, stderr, err := runDataQL(t, "run",
+		"-f", ordersFile,
+		"-s", dbFile,
+		"-q", "CREATE TABLE spent AS SELECT user_id, SUM(total) AS total FROM orders GROUP BY user_id")
+	assertNoError(t, err, stderr)
+
+	_, stderr, err = runDataQL(t, "run",
+		"-s", dbFile,
+		"-q", "INSERT INTO spent VALUES (3, 50)")
+	assertNoError(t, err, stderr)
+
+	stdout, stderr, err := runDataQL(t, "run",
+		"-s", dbFile,
+		"-q", "SELECT COUNT(*) AS users, SUM(total) AS spent FROM spent")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "3")
+	assertContains(t, stdout, "500")
+
+	// Materialized tables are listed with the imported ones
+	stdout, stderr, err = runDataQLWithStdin(t, "", "run", "-s", dbFile)
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "orders")
+	assertContains(t, stdout, `["user_id","total"]`)
+}
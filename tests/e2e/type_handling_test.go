@@ -334,3 +334,49 @@ func TestType_Query_Distinct_Boolean(t *testing.T) {
 	assertContains(t, stdout, "false")
 	assertContains(t, stdout, "invalid")
 }
+
+// ============================================
+// Exact Number Tests
+// ============================================
+
+func TestType_JSON_ExactNumbersExport(t *testing.T) {
+	// Integers beyond float64 precision and decimals keep their digits
+	outputFile := tempFile(t, "exact.jsonl")
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("json/exact_numbers.json"),
+		"-q", "SELECT id, ref, amount FROM exact_numbers ORDER BY ref DESC",
+		"-e", outputFile,
+		"-t", "jsonl")
+
+	assertNoError(t, err, stderr)
+	content := readFile(t, outputFile)
+	assertContains(t, content, `"id":12345678901234567890`)
+	assertContains(t, content, `"ref":9007199254740993`)
+	assertContains(t, content, `"amount":12345678901234567.891`)
+}
+
+func TestType_JSON_DecimalAsString(t *testing.T) {
+	outputFile := tempFile(t, "exact.json")
+	_, stderr, err := runDataQL(t, "run",
+		"-f", fixture("json/exact_numbers.json"),
+		"-q", "SELECT id, amount FROM exact_numbers ORDER BY amount DESC LIMIT 1",
+		"-e", outputFile,
+		"-t", "json",
+		"--decimal-as-string")
+
+	assertNoError(t, err, stderr)
+	content := readFile(t, outputFile)
+	assertContains(t, content, `"12345678901234567890"`)
+	assertContains(t, content, `"12345678901234567.891"`)
+}
+
+func TestType_JSON_ExactNumbersTypes(t *testing.T) {
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", fixture("json/exact_numbers.json"),
+		"-q", "SELECT typeof(id) AS id_type, typeof(ref) AS ref_type, typeof(amount) AS amount_type FROM exact_numbers LIMIT 1")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "HUGEINT")
+	assertContains(t, stdout, "BIGINT")
+	assertContains(t, stdout, "DECIMAL(20,3)")
+}
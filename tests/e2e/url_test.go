@@ -1,9 +1,16 @@
 package e2e_test
 
 import (
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // startTestServer starts a local HTTP server serving test files
@@ -108,19 +115,65 @@ func TestURL_XMLFromHTTP(t *testing.T) {
 }
 
 func TestURL_HTTPSSimulation(t *testing.T) {
-	// Create a TLS test server
+	// Create a TLS test server, with a self-signed certificate
 	server := httptest.NewTLSServer(http.FileServer(http.Dir(fixturesPath)))
 	defer server.Close()
 
-	// Skip certificate verification for test
-	// Note: In production, HTTPS works normally with valid certificates
-	// This test verifies that HTTPS URLs are recognized
+	_, stderr, err := runDataQL(t, "run", "-f", server.URL+"/csv/simple.csv", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "certificate")
+
+	// Trusting the certificate as a CA
+	caFile := tempFile(t, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", server.URL+"/csv/simple.csv",
+		"--ca-cert", caFile,
+		"-q", "SELECT COUNT(*) as count FROM simple")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "3")
+
+	stdout, stderr, err = runDataQL(t, "run",
+		"-f", server.URL+"/csv/simple.csv",
+		"--insecure-skip-verify",
+		"-q", "SELECT COUNT(*) as count FROM simple")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "3")
+
+	_, stderr, err = runDataQL(t, "run", "-f", server.URL+"/csv/simple.csv", "--ca-cert", fixture("csv/simple.csv"), "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --ca-cert")
+}
+
+func TestURL_InPlace(t *testing.T) {
+	// httpfs is downloaded on first use, which needs internet access
+	if _, _, err := runDataQL(t, "run", "-f", fixture("csv/simple.csv"), "--extension", "httpfs", "-q", "SELECT 1"); err != nil {
+		t.Skip("httpfs extension not available")
+	}
 
-	// The TLS server will use a self-signed certificate which won't work
-	// without custom TLS config, so we just test URL detection
-	// The actual HTTPS download is tested with real servers
+	server := startTestServer(t)
+	defer server.Close()
 
-	t.Skip("HTTPS test requires valid certificate or custom TLS config")
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", server.URL+"/parquet/users.parquet",
+		"-f", server.URL+"/csv/simple.csv:people",
+		"--in-place",
+		"-q", "SELECT (SELECT COUNT(*) FROM users) + (SELECT COUNT(*) FROM people) AS total")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "total")
+}
+
+func TestURL_InPlaceInvalidOptions(t *testing.T) {
+	_, stderr, err := runDataQL(t, "run", "-f", "https://example.com/data.parquet", "--in-place", "--engine", "sqlite", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "--in-place requires --engine duckdb")
+
+	_, stderr, err = runDataQL(t, "run", "-f", "s3://bucket/data.parquet", "--in-place", "--s3-profile", "dev", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "--in-place cannot be combined with --s3-profile")
 }
 
 func TestURL_WithCollection(t *testing.T) {
@@ -148,3 +201,94 @@ func TestURL_WithLineLimit(t *testing.T) {
 	assertNoError(t, err, stderr)
 	assertContains(t, stdout, "5")
 }
+
+func TestURL_ResumesDroppedDownload(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := filepath.Join(fixturesPath, "csv/simple.csv")
+		if requests.Add(1) == 1 {
+			// Send the first bytes, then drop the connection
+			content, _ := os.ReadFile(path)
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = w.Write(content[:10])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeFile(w, r, path)
+	}))
+	defer server.Close()
+
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", server.URL+"/simple.csv",
+		"-q", "SELECT COUNT(*) as count FROM simple")
+
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "3")
+	if requests.Load() != 2 {
+		t.Errorf("expected the download to be resumed once, got %d requests", requests.Load())
+	}
+}
+
+func TestURL_DownloadTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	_, stderr, err := runDataQL(t, "run",
+		"-f", server.URL+"/simple.csv",
+		"--download-timeout", "50ms",
+		"-q", "SELECT 1")
+
+	assertError(t, err)
+	assertContains(t, stderr, "Client.Timeout")
+}
+
+func TestURL_Headers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join(fixturesPath, "csv/simple.csv"))
+	}))
+	defer server.Close()
+
+	_, stderr, err := runDataQL(t, "run", "-f", server.URL+"/simple.csv", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "status 401")
+
+	stdout, stderr, err := runDataQL(t, "run",
+		"-f", server.URL+"/simple.csv",
+		"--header", "Authorization: Bearer token123",
+		"-q", "SELECT COUNT(*) as count FROM simple")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "3")
+
+	_, stderr, err = runDataQL(t, "run", "-f", server.URL+"/simple.csv", "--header", "Authorization", "-q", "SELECT 1")
+	assertError(t, err)
+	assertContains(t, stderr, "invalid --header")
+}
+
+func TestURL_BasicAuthIsRedacted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "alice" || password != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join(fixturesPath, "csv/simple.csv"))
+	}))
+	defer server.Close()
+
+	authURL := strings.Replace(server.URL, "http://", "http://alice:s3cret@", 1)
+	stdout, stderr, err := runDataQL(t, "run", "-f", authURL+"/simple.csv", "-q", "SELECT COUNT(*) as count FROM simple")
+	assertNoError(t, err, stderr)
+	assertContains(t, stdout, "3")
+
+	wrongURL := strings.Replace(server.URL, "http://", "http://alice:wrong@", 1)
+	_, stderr, err = runDataQL(t, "run", "-f", wrongURL+"/simple.csv", "-q", "SELECT 1")
+	assertError(t, err)
+	if strings.Contains(stderr, "wrong") {
+		t.Errorf("expected the password to be redacted, got: %s", stderr)
+	}
+}